@@ -0,0 +1,251 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"log/slog"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/hutstep/entra-id-api-tester/internal/config"
+	"github.com/hutstep/entra-id-api-tester/internal/history"
+	"github.com/hutstep/entra-id-api-tester/internal/logging"
+	"github.com/hutstep/entra-id-api-tester/internal/notify"
+	"github.com/hutstep/entra-id-api-tester/internal/probe"
+	"github.com/hutstep/entra-id-api-tester/internal/redact"
+	"github.com/hutstep/entra-id-api-tester/internal/report"
+	"github.com/hutstep/entra-id-api-tester/pkg/tester"
+)
+
+// daemon runs the schedule groups of a configuration
+type daemon struct {
+	cfg     *config.Config
+	logger  *slog.Logger
+	webhook *notify.Webhook
+	// providers are shared by all groups, so that groups running at the
+	// same time share their token acquisitions
+	providers map[string]tester.TokenProvider
+	// health records the latest run of every group in probe mode
+	health      *probe.Tracker
+	historyPath string
+	jitter      time.Duration
+	// mu serializes output and history writes of concurrent groups
+	mu sync.Mutex
+}
+
+// runDaemon implements the "daemon" subcommand: it runs the endpoints on
+// the cron schedules of the configuration until the process is
+// interrupted. It returns the process exit code.
+func runDaemon(args []string) int {
+	flags := flag.NewFlagSet("daemon", flag.ContinueOnError)
+	configPath := flags.String("config", defaultConfigPath, "Path to configuration file")
+	historyPath := flags.String("history", "", "Append the outcome of every scheduled run to this SQLite history database")
+	logLevel := flags.String("log-level", "info", "Minimum log level: debug, info, warn or error")
+	logFormat := flags.String("log-format", logging.FormatText, "Log format: text or json")
+	probeMode := flags.Bool("probe", false, "Run only the endpoints marked with probe, once at startup and then on their schedules, and serve the outcome of their latest runs on /healthz")
+	probeAddr := flags.String("probe-addr", ":8081", "Address the -probe health endpoint listens on")
+	if err := flags.Parse(args); err != nil {
+		return flagsExitCode(err)
+	}
+
+	logger, logCloser, err := logging.New(logging.Options{Level: *logLevel, Format: *logFormat}, os.Stderr)
+	if err != nil {
+		log.Printf("Failed to set up logging: %v", err)
+		return exitConfigError
+	}
+	defer func() { _ = logCloser.Close() }()
+
+	cfg, err := tester.LoadConfig(*configPath)
+	if err != nil {
+		log.Printf("Failed to load configuration: %v", err)
+		return exitConfigError
+	}
+	if *probeMode {
+		if cfg, err = cfg.ProbeConfig(); err != nil {
+			log.Printf("Invalid -probe: %v", err)
+			return exitConfigError
+		}
+	}
+	groups, err := cfg.ScheduleGroups()
+	if err != nil {
+		log.Printf("Invalid schedule: %v", err)
+		return exitConfigError
+	}
+	if len(groups) == 0 {
+		log.Print("No schedule: set schedule in the configuration or on endpoints")
+		return exitConfigError
+	}
+	webhook, err := notify.New(cfg.Notifications, nil)
+	if err != nil {
+		log.Printf("Invalid webhook notification: %v", err)
+		return exitConfigError
+	}
+	providers, err := tester.TokenProviders(cfg)
+	if err != nil {
+		log.Printf("Failed to configure token acquisition: %v", err)
+		return exitConfigError
+	}
+
+	d := &daemon{
+		cfg:         cfg,
+		logger:      logger,
+		webhook:     webhook,
+		providers:   providers,
+		historyPath: *historyPath,
+		jitter:      time.Duration(cfg.ScheduleJitterMs) * time.Millisecond,
+	}
+	scheduled := 0
+	for _, group := range groups {
+		scheduled += len(group.Endpoints)
+		fmt.Printf("Scheduled %d endpoint(s) on %q\n", len(group.Endpoints), group.Expression)
+	}
+	if unscheduled := len(cfg.Endpoints) - scheduled; unscheduled > 0 {
+		fmt.Printf("⚠️  WARNING: %d endpoint(s) have no schedule and are not run\n", unscheduled)
+	}
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	defer cancel(nil)
+	stopSignals := cancelOnSignal(cancel)
+	defer stopSignals()
+
+	if *probeMode {
+		expressions := make([]string, len(groups))
+		for i := range groups {
+			expressions[i] = groups[i].Expression
+		}
+		d.health = probe.NewTracker(expressions)
+		stopProbe, err := serveProbe(*probeAddr, d.health, cancel)
+		if err != nil {
+			log.Printf("Failed to serve -probe: %v", err)
+			return exitError
+		}
+		defer stopProbe()
+		fmt.Printf("Serving probe health on %s/healthz\n", *probeAddr)
+	}
+
+	var wg sync.WaitGroup
+	for _, group := range groups {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if d.health != nil {
+				d.run(ctx, group)
+			}
+			d.loop(ctx, group)
+		}()
+	}
+	wg.Wait()
+	switch cause := context.Cause(ctx); {
+	case cause == nil:
+		log.Print("Stopped: no schedule activates again")
+		return exitConfigError
+	case !errors.Is(cause, errInterrupted):
+		log.Printf("Stopped: %v", cause)
+		return exitError
+	}
+	return exitOK
+}
+
+// serveProbe serves the health of the latest runs on /healthz at address.
+// When the listener fails, it cancels the daemon. The returned function
+// shuts the server down.
+func serveProbe(address string, health http.Handler, cancel context.CancelCauseFunc) (func(), error) {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	mux := http.NewServeMux()
+	mux.Handle("GET /healthz", health)
+	httpServer := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := httpServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			cancel(fmt.Errorf("probe server failed: %w", err))
+		}
+	}()
+	return func() {
+		shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancelShutdown()
+		_ = httpServer.Shutdown(shutdownCtx)
+	}, nil
+}
+
+// loop runs a group at each activation of its schedule, delayed by a
+// random jitter, until ctx is cancelled. A run that overlaps activations
+// skips them.
+func (d *daemon) loop(ctx context.Context, group config.ScheduleGroup) {
+	for {
+		next := group.Schedule.Next(time.Now())
+		if next.IsZero() {
+			d.logger.Warn("schedule never activates", "schedule", group.Expression)
+			return
+		}
+		var jitter time.Duration
+		if d.jitter > 0 {
+			jitter = rand.N(d.jitter) // #nosec G404 - jitter needs no cryptographic randomness
+		}
+		d.logger.Debug("next scheduled run", "schedule", group.Expression, "at", next, "jitter", jitter)
+
+		timer := time.NewTimer(time.Until(next) + jitter)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		d.run(ctx, group)
+	}
+}
+
+// run tests the endpoints of a group once and reports the outcome
+func (d *daemon) run(ctx context.Context, group config.ScheduleGroup) {
+	groupCfg := *d.cfg
+	groupCfg.Endpoints = make([]config.Endpoint, len(group.Endpoints))
+	for i, index := range group.Endpoints {
+		groupCfg.Endpoints[i] = d.cfg.Endpoints[index]
+	}
+
+	runReport, err := tester.Run(ctx, &groupCfg, tester.Options{Version: version, Logger: d.logger, TokenProviders: d.providers})
+	if ctx.Err() != nil {
+		return
+	}
+	if d.health != nil {
+		if err != nil {
+			d.health.Record(group.Expression, nil, err)
+		} else {
+			d.health.Record(group.Expression, &runReport, nil)
+		}
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err != nil {
+		fmt.Printf("[%s] %s: ✗ run failed: %s\n", time.Now().Format(time.RFC3339), group.Expression, redact.String(err.Error()))
+		return
+	}
+	summary := runReport.Summary
+	fmt.Printf("[%s] %s: %d/%d passed\n", runReport.StartedAt.Local().Format(time.RFC3339), group.Expression, summary.Passed, summary.Total)
+	for _, endpoint := range runReport.Endpoints {
+		if endpoint.Status == report.StatusFailed {
+			fmt.Printf("  ✗ %s: %s\n", endpoint.Name, endpoint.Error)
+		}
+	}
+	d.logger.Info("scheduled run finished", "schedule", group.Expression, "endpoints", summary.Total, "failed", summary.Failed)
+
+	if d.historyPath != "" {
+		if err := history.Append(d.historyPath, history.FromReport(&runReport)); err != nil {
+			d.logger.Error("failed to update run history", "error", err)
+		}
+	}
+	if d.webhook != nil && summary.Failed > 0 {
+		if err := d.webhook.Notify(ctx, &runReport); err != nil {
+			d.logger.Error("failed to send webhook notification", "error", err)
+		}
+	}
+}
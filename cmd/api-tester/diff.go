@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/hutstep/entra-id-api-tester/internal/envdiff"
+	"github.com/hutstep/entra-id-api-tester/internal/redact"
+	"github.com/hutstep/entra-id-api-tester/internal/runner"
+	"github.com/hutstep/entra-id-api-tester/pkg/tester"
+)
+
+// stringList is a flag that may be given several times
+type stringList []string
+
+// String joins the values with ", "
+func (l *stringList) String() string {
+	return strings.Join(*l, ", ")
+}
+
+// Set appends a value
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// runDiff implements the "diff" subcommand: it tests the same endpoints in
+// two environments and reports their differences side by side. It returns
+// the process exit code.
+func runDiff(args []string) int {
+	flags := flag.NewFlagSet("diff", flag.ContinueOnError)
+	configPath := flags.String("config", defaultConfigPath, "Configuration file that environment names are resolved against: -env staging reads config.staging.json next to it")
+	latencyThreshold := flags.Float64("latency-threshold", 50, "Percentage by which one environment may be slower than the other before it counts as a difference")
+	var environments stringList
+	flags.Var(&environments, "env", "Environment to compare, given twice: a name such as staging, or the path of a .json configuration file")
+	if err := flags.Parse(args); err != nil {
+		return flagsExitCode(err)
+	}
+
+	if len(environments) != 2 {
+		log.Printf("Invalid -env: expected exactly two environments, got %d", len(environments))
+		return exitConfigError
+	}
+	if *latencyThreshold < 0 {
+		log.Printf("Invalid -latency-threshold: must not be negative: %g", *latencyThreshold)
+		return exitConfigError
+	}
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	defer cancel(nil)
+	stopSignals := cancelOnSignal(cancel)
+	defer stopSignals()
+
+	results := make([][]*runner.Result, len(environments))
+	for i, environment := range environments {
+		path := environmentConfig(*configPath, environment)
+		cfg, err := tester.LoadConfig(path)
+		if err != nil {
+			log.Printf("Failed to load configuration of %s: %v", environment, err)
+			return exitConfigError
+		}
+		fmt.Printf("Testing %d endpoint(s) in %s (%s)\n", len(cfg.Endpoints), environment, path)
+		_, err = tester.Run(ctx, cfg, tester.Options{
+			Version:    version,
+			KeepBodies: true,
+			OnResult:   func(result *tester.Result) { results[i] = append(results[i], result) },
+		})
+		if err != nil {
+			log.Printf("Failed to test %s: %v", environment, err)
+			if errors.Is(err, errInterrupted) {
+				return exitInterrupted
+			}
+			return exitError
+		}
+	}
+
+	rows := envdiff.Compare(results[0], results[1], envdiff.Options{
+		Left:             environments[0],
+		Right:            environments[1],
+		LatencyThreshold: *latencyThreshold,
+	})
+	fmt.Println()
+	if err := printDiff(rows, environments[0], environments[1]); err != nil {
+		log.Printf("Failed to write differences: %v", err)
+		return exitError
+	}
+
+	differing := envdiff.Differing(rows)
+	fmt.Printf("\n%d of %d endpoint(s) differ between %s and %s\n", differing, len(rows), environments[0], environments[1])
+	if differing > 0 {
+		return exitResponseFailure
+	}
+	return exitOK
+}
+
+// environmentConfig returns the configuration file of an environment: a
+// path ending in .json as it is, or else the -config file with the
+// environment name before its extension
+func environmentConfig(configPath, environment string) string {
+	if strings.EqualFold(filepath.Ext(environment), ".json") {
+		return environment
+	}
+	extension := filepath.Ext(configPath)
+	return strings.TrimSuffix(configPath, extension) + "." + environment + extension
+}
+
+// printDiff prints one line per endpoint with the outcome in each
+// environment and their differences
+func printDiff(rows []envdiff.Row, left, right string) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "ENDPOINT\t%s\t%s\tDIFFERENCES\n", strings.ToUpper(left), strings.ToUpper(right))
+	for i := range rows {
+		row := &rows[i]
+		differences := "-"
+		if len(row.Differences) > 0 {
+			differences = strings.Join(row.Differences, "; ")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", row.Endpoint, row.Left.Summary(), row.Right.Summary(), redact.String(differences))
+	}
+	return w.Flush()
+}
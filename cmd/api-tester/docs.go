@@ -0,0 +1,56 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/hutstep/entra-id-api-tester/internal/config"
+	"github.com/hutstep/entra-id-api-tester/internal/docs"
+)
+
+// runDocs implements the "docs" subcommand: it renders the configuration as
+// a Markdown catalog of the tested endpoints. It returns the process exit
+// code.
+func runDocs(args []string) int {
+	flags := flag.NewFlagSet("docs", flag.ContinueOnError)
+	configPath := flags.String("config", defaultConfigPath, "Path to configuration file")
+	outputPath := flags.String("o", "", "Write the documentation to this file instead of stdout")
+	if err := flags.Parse(args); err != nil {
+		return flagsExitCode(err)
+	}
+
+	cfg, err := config.LoadConfig(*configPath)
+	if err != nil {
+		log.Printf("Failed to load configuration: %v", err)
+		return exitConfigError
+	}
+
+	if *outputPath == "" {
+		if err := docs.Render(os.Stdout, cfg, *configPath); err != nil {
+			log.Print(err)
+			return exitError
+		}
+		return exitOK
+	}
+
+	file, err := os.Create(*outputPath) // #nosec G304 - path is provided by the user
+	if err != nil {
+		log.Printf("Failed to create documentation file: %v", err)
+		return exitError
+	}
+	if err := docs.Render(file, cfg, *configPath); err != nil {
+		log.Print(err)
+		if closeErr := file.Close(); closeErr != nil {
+			_ = closeErr
+		}
+		return exitError
+	}
+	if err := file.Close(); err != nil {
+		log.Printf("Failed to write documentation file: %v", err)
+		return exitError
+	}
+	fmt.Printf("Documentation for %d endpoint(s) written to %s\n", len(cfg.Endpoints), *outputPath)
+	return exitOK
+}
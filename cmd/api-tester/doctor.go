@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+
+	"github.com/hutstep/entra-id-api-tester/internal/config"
+	"github.com/hutstep/entra-id-api-tester/internal/doctor"
+	"github.com/hutstep/entra-id-api-tester/internal/runner"
+)
+
+const (
+	defaultEchoURL = "https://api.ipify.org"
+)
+
+// runDoctor implements the "doctor" subcommand: a one-shot environment sanity
+// check. It returns the process exit code.
+func runDoctor(args []string) int {
+	flags := flag.NewFlagSet("doctor", flag.ContinueOnError)
+	configPath := flags.String("config", "", "Path to configuration file; its first endpoint (or -endpoint) is used for the test token")
+	endpointName := flags.String("endpoint", "", "Name of the endpoint whose credentials are used for the test token")
+	echoURL := flags.String("echo-url", defaultEchoURL, "Service that echoes the caller's public IP (empty to skip)")
+	loginHost := flags.String("login-host", doctor.DefaultLoginHost, "Entra ID authority host to check")
+	if err := flags.Parse(args); err != nil {
+		return flagsExitCode(err)
+	}
+
+	opts := doctor.Options{
+		EchoURL:   *echoURL,
+		LoginHost: *loginHost,
+	}
+
+	if *configPath != "" {
+		cfg, err := config.LoadConfig(*configPath)
+		if err != nil {
+			log.Printf("Failed to load configuration: %v", err)
+			return exitConfigError
+		}
+		endpoint, err := selectEndpoint(cfg, *endpointName)
+		if err != nil {
+			log.Print(err)
+			return exitConfigError
+		}
+		providers, err := runner.TokenProviders([]config.Endpoint{*endpoint})
+		if err != nil {
+			log.Printf("Failed to configure token acquisition: %v", err)
+			return exitConfigError
+		}
+		opts.Endpoint = endpoint
+		opts.TokenProvider = providers[endpoint.TokenProxyURL]
+	}
+
+	fmt.Println("API Tester Doctor")
+	fmt.Println(repeat("=", 80))
+
+	failed := false
+	for _, check := range doctor.Run(context.Background(), opts) {
+		fmt.Printf("[%-4s] %s: %s\n", check.Status, check.Name, check.Detail)
+		if check.Status == doctor.StatusFail {
+			failed = true
+		}
+	}
+	fmt.Println(repeat("=", 80))
+
+	if failed {
+		return 1
+	}
+	return 0
+}
+
+// selectEndpoint returns the named endpoint, or the first one when name is empty
+func selectEndpoint(cfg *config.Config, name string) (*config.Endpoint, error) {
+	if name == "" {
+		return &cfg.Endpoints[0], nil
+	}
+	for i := range cfg.Endpoints {
+		if cfg.Endpoints[i].Name == name {
+			return &cfg.Endpoints[i], nil
+		}
+	}
+	return nil, fmt.Errorf("endpoint %q not found in configuration", name)
+}
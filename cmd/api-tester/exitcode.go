@@ -0,0 +1,61 @@
+package main
+
+import (
+	"github.com/hutstep/entra-id-api-tester/internal/report"
+)
+
+// Exit codes let pipelines branch on why a run failed without parsing the
+// output. When endpoints fail for different reasons, the earliest phase
+// wins: authentication, then connectivity, then the response, then
+// security, then the contract, then performance.
+const (
+	exitOK = 0
+	// exitError is a setup failure (e.g. the HAR or log file could not be
+	// created), a run aborted by -max-memory-mb, or a report write failure
+	exitError = 1
+	// exitAuthFailure means at least one endpoint could not acquire a token
+	exitAuthFailure = 2
+	// exitConnectFailure means at least one request got no response,
+	// including TLS policy violations
+	exitConnectFailure = 3
+	// exitResponseFailure means at least one response failed its status
+	// code, assertion or capture checks
+	exitResponseFailure = 4
+	// exitPerformanceFailure means every failing endpoint passed its checks
+	// but exceeded its maximum duration
+	exitPerformanceFailure = 6
+	// exitConfigError means the configuration or command line is invalid,
+	// including variable references that could not be resolved
+	exitConfigError = 5
+	// exitContractFailure means at least one response passed its checks
+	// but violated its OpenAPI contract
+	exitContractFailure = 7
+	// exitSecurityFailure means at least one endpoint accepted a request
+	// with missing or invalid credentials
+	exitSecurityFailure = 8
+	// exitInterrupted is the exit code of a run stopped by SIGINT or
+	// SIGTERM, following the shell convention of 128 + SIGINT
+	exitInterrupted = 130
+)
+
+// failureExitCode returns the exit code for the endpoint failures counted
+// in summary, or exitOK when there were none
+func failureExitCode(summary report.Summary) int {
+	switch {
+	case summary.AuthFailures > 0:
+		return exitAuthFailure
+	case summary.PrepareFailures > 0:
+		return exitConfigError
+	case summary.ConnectFailures > 0:
+		return exitConnectFailure
+	case summary.ResponseFailures > 0:
+		return exitResponseFailure
+	case summary.SecurityFailures > 0:
+		return exitSecurityFailure
+	case summary.ContractFailures > 0:
+		return exitContractFailure
+	case summary.PerformanceFailures > 0:
+		return exitPerformanceFailure
+	}
+	return exitOK
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/hutstep/entra-id-api-tester/internal/ghactions"
+	"github.com/hutstep/entra-id-api-tester/internal/redact"
+	"github.com/hutstep/entra-id-api-tester/internal/report"
+	"github.com/hutstep/entra-id-api-tester/internal/runner"
+)
+
+// Output formats of the run command
+const (
+	outputText = "text"
+	// outputGitHub adds annotations, step outputs and a job summary to the
+	// text output
+	outputGitHub = "github"
+)
+
+// printAnnotations surfaces a failed endpoint and its warnings as GitHub
+// Actions annotations
+func printAnnotations(result *runner.Result) {
+	switch {
+	case result.Flaky():
+		message := fmt.Sprintf("Passed %d of %d runs; first failure: %s", result.PassedRuns, result.Runs, redact.String(result.Err.Error()))
+		fmt.Println(ghactions.Error("API test flaky: "+result.EndpointName, message))
+	case !result.Success() && !result.Skipped:
+		fmt.Println(ghactions.Error("API test failed: "+result.EndpointName, redact.String(result.Err.Error())))
+	}
+	for _, warning := range result.Warnings {
+		fmt.Println(ghactions.Warning("API test warning: "+result.EndpointName, redact.String(warning)))
+	}
+}
+
+// writeGitHubOutputs writes the run's counts, result and report path as
+// step outputs, when GitHub Actions provides an output file
+func writeGitHubOutputs(summary report.Summary, exitCode int, reportPath string) error {
+	path := os.Getenv("GITHUB_OUTPUT")
+	if path == "" {
+		return nil
+	}
+
+	result := "passed"
+	if exitCode != exitOK {
+		result = "failed"
+	}
+	return ghactions.WriteOutputs(path, []ghactions.Output{
+		{Name: "result", Value: result},
+		{Name: "exit-code", Value: strconv.Itoa(exitCode)},
+		{Name: "total", Value: strconv.Itoa(summary.Total)},
+		{Name: "passed", Value: strconv.Itoa(summary.Passed)},
+		{Name: "failed", Value: strconv.Itoa(summary.Failed)},
+		{Name: "skipped", Value: strconv.Itoa(summary.Skipped)},
+		{Name: "report-path", Value: reportPath},
+	})
+}
+
+// writeStepSummary appends the Markdown summary of the run to the job
+// summary, when GitHub Actions provides a summary file
+func writeStepSummary(runReport *report.Report) error {
+	path := os.Getenv("GITHUB_STEP_SUMMARY")
+	if path == "" {
+		return nil
+	}
+	return ghactions.AppendSummary(path, ghactions.Summary(runReport))
+}
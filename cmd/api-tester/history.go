@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/hutstep/entra-id-api-tester/internal/console"
+	"github.com/hutstep/entra-id-api-tester/internal/ghactions"
+	"github.com/hutstep/entra-id-api-tester/internal/history"
+	"github.com/hutstep/entra-id-api-tester/internal/report"
+)
+
+// recordHistory appends the run to the history file and, when compare is
+// set, first prints the endpoints that regressed against previous runs
+func recordHistory(out console.Formatter, runReport *report.Report, path string, compare bool, slowerPercent float64, annotate bool) error {
+	current := history.FromReport(runReport)
+
+	if compare {
+		previous, err := history.Load(path, history.CompareRuns)
+		if err != nil {
+			return err
+		}
+		regressions := history.Compare(previous, current, history.DefaultWindow, slowerPercent)
+		out.Regressions(regressions, len(previous))
+		if annotate {
+			for _, regression := range regressions {
+				fmt.Println(ghactions.Warning("API test regression: "+regression.Endpoint, regression.Reason))
+			}
+		}
+	}
+
+	return history.Append(path, current)
+}
+
+// compareBaseline prints how the run differs from the baseline at path
+func compareBaseline(out console.Formatter, runReport *report.Report, path string, slowerPercent float64, annotate bool) error {
+	baseline, err := history.LoadBaseline(path)
+	if err != nil {
+		return err
+	}
+	diff := history.CompareBaseline(baseline, history.FromReport(runReport), slowerPercent)
+
+	out.Baseline(&diff, path)
+	if annotate {
+		for _, name := range diff.NewFailures {
+			fmt.Println(ghactions.Warning("API test regression: "+name, "newly failing compared to the baseline"))
+		}
+		for _, regression := range diff.Slower {
+			fmt.Println(ghactions.Warning("API test regression: "+regression.Endpoint, regression.Reason))
+		}
+	}
+	return nil
+}
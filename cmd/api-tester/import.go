@@ -0,0 +1,116 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+
+	"github.com/hutstep/entra-id-api-tester/internal/openapi"
+	"github.com/hutstep/entra-id-api-tester/internal/scaffold"
+)
+
+const importUsage = "Usage: api-tester import openapi <spec.yaml|spec.json> [-output config.json] [-server URL] [-tenant-id ID] [-client-id ID] [-scope SCOPE]"
+
+// runImport implements the "import" subcommand group. It returns the
+// process exit code.
+func runImport(args []string) int {
+	if len(args) == 0 || args[0] != "openapi" {
+		log.Print(importUsage)
+		return exitConfigError
+	}
+	return runImportOpenAPI(args[1:])
+}
+
+// runImportOpenAPI implements "import openapi": it generates an endpoint
+// for each operation of an OpenAPI or Swagger description. It returns the
+// process exit code.
+func runImportOpenAPI(args []string) int {
+	flags := flag.NewFlagSet("import openapi", flag.ContinueOnError)
+	output := flags.String("output", "", "Write the configuration to this file instead of stdout")
+	force := flags.Bool("force", false, "Overwrite an existing output file")
+	server := flags.String("server", "", "Base URL of the API (default: the first server in the description)")
+	tenantID := flags.String("tenant-id", "your-tenant-id", "Tenant ID for every endpoint")
+	clientID := flags.String("client-id", "your-client-id", "Client ID for every endpoint")
+	scope := flags.String("scope", "api://your-api-app-id/.default", "Scope for every endpoint")
+	if err := flags.Parse(args); err != nil {
+		return flagsExitCode(err)
+	}
+	// Flags may also follow the spec path
+	specPath := flags.Arg(0)
+	if flags.NArg() > 1 {
+		_ = flags.Parse(flags.Args()[1:])
+		if flags.NArg() > 0 {
+			log.Print(importUsage)
+			return exitConfigError
+		}
+	}
+	if specPath == "" {
+		log.Print(importUsage)
+		return exitConfigError
+	}
+
+	spec, err := openapi.Load(specPath)
+	if err != nil {
+		log.Printf("Failed to load %s: %v", specPath, err)
+		return exitConfigError
+	}
+	baseURL := *server
+	if baseURL == "" {
+		baseURL = spec.BaseURL()
+	}
+	if baseURL == "" {
+		log.Printf("%s does not declare a server; set the API's base URL with -server", specPath)
+		return exitConfigError
+	}
+
+	operations, notes := spec.Import(baseURL)
+	if len(operations) == 0 {
+		log.Printf("%s has no operations to import", specPath)
+		return exitConfigError
+	}
+	endpoints := make([]scaffold.Endpoint, 0, len(operations))
+	for _, operation := range operations {
+		endpoints = append(endpoints, scaffold.Endpoint{
+			RequestBody:  operation.Body,
+			Name:         operation.Name,
+			URL:          operation.URL,
+			Method:       operation.Method,
+			ClientID:     *clientID,
+			ClientSecret: scaffold.SecretPlaceholder,
+			TenantID:     *tenantID,
+			Scope:        *scope,
+		})
+	}
+	data, err := scaffold.RenderEndpoints(endpoints)
+	if err != nil {
+		log.Printf("Failed to create configuration: %v", err)
+		return exitError
+	}
+
+	// Notes go to stderr so that stdout stays a valid configuration
+	for _, note := range notes {
+		fmt.Fprintf(os.Stderr, "⚠ %s\n", note)
+	}
+	if *output == "" {
+		fmt.Print(string(data))
+		return exitOK
+	}
+
+	if _, err := os.Stat(*output); err == nil && !*force {
+		log.Printf("%s already exists; use -force to overwrite it", *output)
+		return exitConfigError
+	} else if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		log.Printf("Failed to check %s: %v", *output, err)
+		return exitError
+	}
+	// The file will hold client secrets once the placeholders are replaced
+	if err := os.WriteFile(*output, data, 0600); err != nil {
+		log.Printf("Failed to write %s: %v", *output, err)
+		return exitError
+	}
+	fmt.Fprintf(os.Stderr, "✓ Wrote %d endpoint(s) to %s; replace the credential placeholders before running it\n", len(endpoints), *output)
+	return exitOK
+}
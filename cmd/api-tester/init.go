@@ -0,0 +1,56 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+
+	"github.com/hutstep/entra-id-api-tester/internal/scaffold"
+)
+
+// runInit implements the "init" subcommand: it asks for the details of a
+// first endpoint and writes a starter configuration. It returns the process
+// exit code.
+func runInit(args []string) int {
+	flags := flag.NewFlagSet("init", flag.ContinueOnError)
+	configPath := flags.String("config", defaultConfigPath, "Path of the configuration file to create")
+	force := flags.Bool("force", false, "Overwrite an existing configuration file")
+	if err := flags.Parse(args); err != nil {
+		return flagsExitCode(err)
+	}
+
+	if _, err := os.Stat(*configPath); err == nil && !*force {
+		log.Printf("%s already exists; use -force to overwrite it", *configPath)
+		return exitConfigError
+	} else if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		log.Printf("Failed to check %s: %v", *configPath, err)
+		return exitError
+	}
+
+	fmt.Printf("Creating %s. Press Enter to accept a default in brackets.\n\n", *configPath)
+	answers, err := scaffold.Prompt(os.Stdin, os.Stdout)
+	if err != nil {
+		log.Printf("Failed to read answers: %v", err)
+		return exitConfigError
+	}
+	data, err := scaffold.Render(answers)
+	if err != nil {
+		log.Printf("Failed to create configuration: %v", err)
+		return exitError
+	}
+	// The file will hold a client secret once the placeholder is replaced
+	if err := os.WriteFile(*configPath, data, 0600); err != nil {
+		log.Printf("Failed to write %s: %v", *configPath, err)
+		return exitError
+	}
+
+	fmt.Printf("\n✓ Wrote %s\n\n", *configPath)
+	fmt.Println("Next steps:")
+	fmt.Printf("  1. Replace %q with the app registration's client secret\n", scaffold.SecretPlaceholder)
+	fmt.Printf("  2. Check the configuration: api-tester validate -config %s\n", *configPath)
+	fmt.Printf("  3. Run the tests:           api-tester run -config %s\n", *configPath)
+	return exitOK
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/hutstep/entra-id-api-tester/internal/config"
+)
+
+// runList implements the "list" subcommand: it prints the configured
+// endpoints in the order a run tests them. It returns the process exit code.
+func runList(args []string) int {
+	flags := flag.NewFlagSet("list", flag.ContinueOnError)
+	configPath := flags.String("config", defaultConfigPath, "Path to configuration file")
+	if err := flags.Parse(args); err != nil {
+		return flagsExitCode(err)
+	}
+
+	cfg, err := config.LoadConfig(*configPath)
+	if err != nil {
+		log.Printf("Failed to load configuration: %v", err)
+		return exitConfigError
+	}
+	order, err := cfg.ExecutionOrder()
+	if err != nil {
+		log.Printf("Failed to order endpoints: %v", err)
+		return exitConfigError
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "#\tNAME\tMETHOD\tURL\tDEPENDS ON")
+	for position, i := range order {
+		endpoint := &cfg.Endpoints[i]
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", position+1, endpoint.Name, endpoint.Method, endpoint.URL, strings.Join(endpoint.DependsOn, ", "))
+	}
+	if err := w.Flush(); err != nil {
+		log.Printf("Failed to write endpoint list: %v", err)
+		return exitError
+	}
+	return exitOK
+}
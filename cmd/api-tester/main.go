@@ -1,20 +1,19 @@
 package main
 
 import (
-	"context"
+	"errors"
 	"flag"
 	"fmt"
-	"log"
+	"io"
+	"log/slog"
 	"os"
-	"time"
-
-	"github.com/hutstep/entra-id-api-tester/internal/auth"
-	"github.com/hutstep/entra-id-api-tester/internal/client"
-	"github.com/hutstep/entra-id-api-tester/internal/config"
+	"strings"
 )
 
 const (
 	defaultConfigPath = "config.json"
+	// staticTokenEnv is the default of the run command's -token flag
+	staticTokenEnv = "API_TESTER_TOKEN"
 )
 
 // Version information (set by GoReleaser)
@@ -25,193 +24,106 @@ var (
 	builtBy = "unknown"
 )
 
-// TestResult represents the result of testing an endpoint
-type TestResult struct {
-	EndpointName    string
-	ErrorMessage    string
-	Duration        time.Duration
-	StatusCode      int
-	Success         bool
-	AuthSuccess     bool
-	ConnectSuccess  bool
-	ResponseSuccess bool
-}
-
 func main() {
-	// Parse command-line flags
-	configPath := flag.String("config", defaultConfigPath, "Path to configuration file")
-	verbose := flag.Bool("verbose", false, "Enable verbose output")
-	versionFlag := flag.Bool("version", false, "Print version information")
-	flag.Parse()
-
-	// Print version and exit if requested
-	if *versionFlag {
-		fmt.Printf("api-tester %s\n", version)
-		fmt.Printf("  commit: %s\n", commit)
-		fmt.Printf("  built at: %s\n", date)
-		fmt.Printf("  built by: %s\n", builtBy)
-		os.Exit(0)
-	}
-
-	// Load configuration
-	cfg, err := config.LoadConfig(*configPath)
-	if err != nil {
-		log.Fatalf("Failed to load configuration: %v", err)
-	}
-
-	fmt.Printf("Loaded configuration with %d endpoint(s)\n", len(cfg.Endpoints))
-	fmt.Println("=" + repeat("=", 78))
-
-	// Initialize auth and API clients
-	tokenProvider := auth.NewEntraIDTokenProvider()
-	apiClient := client.NewAPIClient()
-
-	// Test each endpoint
-	results := make([]TestResult, 0, len(cfg.Endpoints))
-	for i := range cfg.Endpoints {
-		endpoint := &cfg.Endpoints[i]
-		fmt.Printf("\n[%d/%d] Testing: %s\n", i+1, len(cfg.Endpoints), endpoint.Name)
-		fmt.Printf("    URL: %s\n", endpoint.URL)
-		fmt.Printf("    Method: %s\n", endpoint.Method)
-
-		result := testEndpoint(context.Background(), endpoint, tokenProvider, apiClient, *verbose)
-		results = append(results, result)
-
-		printTestResult(result)
-	}
-
-	// Print summary
-	fmt.Println("\n" + repeat("=", 80))
-	printSummary(results)
-
-	// Exit with appropriate code
-	if hasFailures(results) {
-		os.Exit(1)
-	}
+	os.Exit(dispatch(os.Args[1:]))
 }
 
-// testEndpoint tests a single endpoint
-func testEndpoint(ctx context.Context, endpoint *config.Endpoint, tokenProvider auth.TokenProvider, apiClient *client.APIClient, verbose bool) TestResult {
-	result := TestResult{
-		EndpointName: endpoint.Name,
-	}
-
-	startTime := time.Now()
+// command is an api-tester subcommand
+type command struct {
+	// run parses the subcommand's arguments and returns the exit code
+	run     func(args []string) int
+	name    string
+	summary string
+}
 
-	// Step 1: Authenticate
-	if verbose {
-		fmt.Println("    → Authenticating...")
+// commands returns the subcommands in the order usage lists them
+func commands() []command {
+	return []command{
+		{run: runTests, name: "run", summary: "Test the configured endpoints (the default command)"},
+		{run: runDiff, name: "diff", summary: "Compare status codes, response shapes and latency between two environments"},
+		{run: runServe, name: "serve", summary: "Serve an HTTP API and dashboard that trigger runs and show their results"},
+		{run: runDaemon, name: "daemon", summary: "Run the endpoints on their cron schedules until interrupted"},
+		{run: runInit, name: "init", summary: "Create a starter configuration interactively"},
+		{run: runImport, name: "import", summary: "Generate endpoints from an OpenAPI or Swagger description"},
+		{run: runValidate, name: "validate", summary: "Check a configuration without making network calls"},
+		{run: runList, name: "list", summary: "List the configured endpoints in execution order"},
+		{run: runToken, name: "token", summary: "Acquire an access token for an endpoint and print it or its claims"},
+		{run: runReport, name: "report", summary: "Work with run reports, e.g. verify a signed report"},
+		{run: runDocs, name: "docs", summary: "Render the configuration as a Markdown endpoint catalog"},
+		{run: runDoctor, name: "doctor", summary: "Check the environment: DNS, proxy, clock and token acquisition"},
+		{run: runVersion, name: "version", summary: "Print version information"},
 	}
+}
 
-	token, err := tokenProvider.GetAccessToken(ctx, endpoint.ClientID, endpoint.ClientSecret, endpoint.TenantID, endpoint.Scope)
-	if err != nil {
-		result.ErrorMessage = fmt.Sprintf("Authentication failed: %v", err)
-		result.Duration = time.Since(startTime)
-		return result
+// flagsExitCode returns the exit code of a subcommand whose flags failed to
+// parse, after the flag set reported the error: exitOK when -h asked for
+// the usage, and exitConfigError for an unknown or invalid flag
+func flagsExitCode(err error) int {
+	if errors.Is(err, flag.ErrHelp) {
+		return exitOK
 	}
+	return exitConfigError
+}
 
-	result.AuthSuccess = true
-	if verbose {
-		fmt.Println("    ✓ Authentication successful")
+// dispatch runs the subcommand named by the first argument. Without one, or
+// when the arguments start with a flag, the tests are run, so invocations
+// such as "api-tester -config config.json" keep working.
+func dispatch(args []string) int {
+	if len(args) == 0 {
+		return runTests(args)
 	}
-
-	// Step 2: Make API call
-	if verbose {
-		fmt.Println("    → Making API request...")
+	switch args[0] {
+	case "help", "-h", "-help", "--help":
+		printUsage(os.Stdout)
+		return exitOK
+	case "verify-report":
+		// Kept for compatibility; now "report verify"
+		return runVerifyReport(args[1:])
 	}
-
-	response, err := apiClient.CallAPI(ctx, endpoint.Method, endpoint.URL, token, endpoint.RequestBody)
-	if err != nil {
-		result.ErrorMessage = fmt.Sprintf("Request failed: %v", err)
-		result.Duration = time.Since(startTime)
-		return result
+	if strings.HasPrefix(args[0], "-") {
+		return runTests(args)
 	}
 
-	result.ConnectSuccess = true
-	result.StatusCode = response.StatusCode
-	result.Duration = time.Since(startTime)
-
-	if verbose {
-		fmt.Printf("    ✓ Request completed (Status: %d)\n", response.StatusCode)
-	}
-
-	// Step 3: Check response
-	if response.IsSuccessStatusCode() {
-		result.ResponseSuccess = true
-		result.Success = true
-	} else {
-		result.ErrorMessage = fmt.Sprintf("Unexpected status code: %d", response.StatusCode)
-		if verbose && len(response.Body) > 0 {
-			fmt.Printf("    Response body: %s\n", response.GetBodyAsString())
+	for _, cmd := range commands() {
+		if cmd.name == args[0] {
+			return cmd.run(args[1:])
 		}
 	}
-
-	return result
+	fmt.Fprintf(os.Stderr, "Unknown command %q\n\n", args[0])
+	printUsage(os.Stderr)
+	return exitConfigError
 }
 
-// printTestResult prints the result of a single test
-func printTestResult(result TestResult) {
-	if result.Success {
-		fmt.Printf("    ✓ PASS - All checks passed (Duration: %v)\n", result.Duration)
-	} else {
-		fmt.Printf("    ✗ FAIL - %s (Duration: %v)\n", result.ErrorMessage, result.Duration)
-		if !result.AuthSuccess {
-			fmt.Println("      • Authentication: FAILED")
-		} else {
-			fmt.Println("      • Authentication: PASSED")
-		}
-		if result.ConnectSuccess {
-			fmt.Println("      • Connectivity: PASSED")
-			fmt.Printf("      • Response Status: FAILED (Status Code: %d)\n", result.StatusCode)
-		} else {
-			fmt.Println("      • Connectivity: FAILED")
-		}
+// printUsage lists the subcommands
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: api-tester [command] [flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, cmd := range commands() {
+		fmt.Fprintf(w, "  %-10s %s\n", cmd.name, cmd.summary)
 	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, `Run "api-tester <command> -h" for the flags of a command.`)
 }
 
-// printSummary prints a summary of all test results
-func printSummary(results []TestResult) {
-	total := len(results)
-	passed := 0
-	authFailed := 0
-	connectFailed := 0
-	responseFailed := 0
-
-	for _, result := range results {
-		if result.Success {
-			passed++
-		} else {
-			switch {
-			case !result.AuthSuccess:
-				authFailed++
-			case !result.ConnectSuccess:
-				connectFailed++
-			default:
-				responseFailed++
-			}
-		}
-	}
+// runVersion implements the "version" subcommand
+func runVersion(args []string) int {
+	printVersion()
+	return exitOK
+}
 
-	fmt.Println("SUMMARY")
-	fmt.Println(repeat("-", 80))
-	fmt.Printf("Total Endpoints:           %d\n", total)
-	fmt.Printf("Passed:                    %d (%.1f%%)\n", passed, float64(passed)/float64(total)*100)
-	fmt.Printf("Failed:                    %d (%.1f%%)\n", total-passed, float64(total-passed)/float64(total)*100)
-	fmt.Println()
-	fmt.Printf("  • Authentication Failures:  %d\n", authFailed)
-	fmt.Printf("  • Connectivity Failures:    %d\n", connectFailed)
-	fmt.Printf("  • Response Failures:        %d\n", responseFailed)
-	fmt.Println(repeat("=", 80))
+// printVersion prints the build information
+func printVersion() {
+	fmt.Printf("api-tester %s\n", version)
+	fmt.Printf("  commit: %s\n", commit)
+	fmt.Printf("  built at: %s\n", date)
+	fmt.Printf("  built by: %s\n", builtBy)
 }
 
-// hasFailures checks if any tests failed
-func hasFailures(results []TestResult) bool {
-	for _, result := range results {
-		if !result.Success {
-			return true
-		}
-	}
-	return false
+// fatal logs an error and exits with code
+func fatal(logger *slog.Logger, code int, msg string, err error) {
+	logger.Error(msg, "error", err)
+	os.Exit(code)
 }
 
 // repeat repeats a string n times
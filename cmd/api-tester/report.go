@@ -0,0 +1,85 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/hutstep/entra-id-api-tester/internal/console"
+	"github.com/hutstep/entra-id-api-tester/internal/report"
+)
+
+// writeReport writes the run report and, when signKey is set, a detached
+// signature over the exact bytes written
+func writeReport(out console.Formatter, runReport *report.Report, path, format, signKey string) error {
+	data, err := runReport.WriteFile(path, format)
+	if err != nil {
+		return err
+	}
+	out.Notef("Report written to %s", path)
+
+	if signKey == "" {
+		return nil
+	}
+	signaturePath, err := report.SignFile(path, data, signKey)
+	if err != nil {
+		return err
+	}
+	out.Notef("Report signature written to %s", signaturePath)
+	return nil
+}
+
+// runReport implements the "report" subcommand group. It returns the
+// process exit code.
+func runReport(args []string) int {
+	if len(args) == 0 || args[0] != "verify" {
+		log.Print("Usage: api-tester report verify -report <file> -key <public key>")
+		return exitConfigError
+	}
+	return runVerifyReport(args[1:])
+}
+
+// runVerifyReport implements "report verify" (formerly "verify-report"): it
+// checks a report against its detached signature. It returns the process
+// exit code.
+func runVerifyReport(args []string) int {
+	flags := flag.NewFlagSet("report verify", flag.ContinueOnError)
+	reportPath := flags.String("report", "", "Path to the report file")
+	signaturePath := flags.String("signature", "", "Path to the detached signature (default: <report>"+report.SignatureExtension+")")
+	keyPath := flags.String("key", "", "PEM public key or certificate of the signer")
+	if err := flags.Parse(args); err != nil {
+		return flagsExitCode(err)
+	}
+
+	if *reportPath == "" || *keyPath == "" {
+		log.Print("report verify requires -report and -key")
+		return 1
+	}
+	if *signaturePath == "" {
+		*signaturePath = *reportPath + report.SignatureExtension
+	}
+
+	data, err := os.ReadFile(*reportPath) // #nosec G304 - path is provided by the user
+	if err != nil {
+		log.Printf("Failed to read report: %v", err)
+		return 1
+	}
+	signature, err := os.ReadFile(*signaturePath) // #nosec G304 - path is provided by the user
+	if err != nil {
+		log.Printf("Failed to read signature: %v", err)
+		return 1
+	}
+	publicKey, err := os.ReadFile(*keyPath) // #nosec G304 - path is provided by the user
+	if err != nil {
+		log.Printf("Failed to read public key: %v", err)
+		return 1
+	}
+
+	if err := report.Verify(data, string(signature), publicKey); err != nil {
+		fmt.Printf("✗ %s: %v\n", *reportPath, err)
+		return 1
+	}
+	fmt.Printf("✓ %s: signature valid\n", *reportPath)
+	return 0
+}
@@ -0,0 +1,490 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hutstep/entra-id-api-tester/internal/client"
+	"github.com/hutstep/entra-id-api-tester/internal/config"
+	"github.com/hutstep/entra-id-api-tester/internal/console"
+	"github.com/hutstep/entra-id-api-tester/internal/dump"
+	"github.com/hutstep/entra-id-api-tester/internal/ghactions"
+	"github.com/hutstep/entra-id-api-tester/internal/har"
+	"github.com/hutstep/entra-id-api-tester/internal/history"
+	"github.com/hutstep/entra-id-api-tester/internal/logging"
+	"github.com/hutstep/entra-id-api-tester/internal/monitor"
+	"github.com/hutstep/entra-id-api-tester/internal/notify"
+	"github.com/hutstep/entra-id-api-tester/internal/redact"
+	"github.com/hutstep/entra-id-api-tester/internal/report"
+	"github.com/hutstep/entra-id-api-tester/internal/runner"
+	"github.com/hutstep/entra-id-api-tester/internal/vars"
+)
+
+// runTests implements the "run" subcommand, which is also the default: it
+// tests every configured endpoint and returns the process exit code.
+func runTests(args []string) int {
+	// Parse command-line flags
+	flags := flag.NewFlagSet("run", flag.ContinueOnError)
+	configPath := flags.String("config", defaultConfigPath, "Path to configuration file")
+	verbose := flags.Bool("verbose", false, "Enable verbose output (shorthand for -log-level debug)")
+	versionFlag := flags.Bool("version", false, "Print version information")
+	maxMemoryMB := flags.Int("max-memory-mb", 0, "Abort the run gracefully (with a partial summary) when heap usage exceeds this many MB (0 = no limit)")
+	harPath := flags.String("har", "", "Record all requests and responses (secrets redacted) to a HAR file")
+	dumpDir := flags.String("dump-on-failure", "", "Write the requests and responses (secrets redacted) of each failed endpoint to a file in this directory")
+	maxClockSkew := flags.Duration("max-clock-skew", 5*time.Minute, "Maximum time to wait for a token whose nbf lies in the future (clock skew)")
+	ipVersionFlag := flags.String("ip-version", ipVersionAuto, "Connect to APIs over IPv4 (4), IPv6 (6) or either (auto); per-endpoint ipVersion takes precedence")
+	proxyURL := flags.String("proxy", "", "Proxy URL for API requests and token acquisition (per-endpoint proxyUrl/tokenProxyUrl take precedence; default: HTTP_PROXY/HTTPS_PROXY/NO_PROXY)")
+	azureRegion := flags.String("azure-region", "", "Acquire tokens from the regional token endpoint (ESTS-R) of this Azure region, e.g. westeurope, or TryAutoDetect; per-endpoint azureRegion takes precedence")
+	staticToken := flags.String("token", "", "Send this bearer token instead of acquiring one from Entra ID, for endpoints without their own staticToken, personas, tenants or scopes (default: $"+staticTokenEnv+")")
+	slowThreshold := flags.Duration("slow-threshold", runner.DefaultSlowThreshold, "Warn live while a request has been in flight longer than this, repeating every interval (0 = off; per-endpoint slowThresholdMs takes precedence)")
+	maxDuration := flags.Duration("max-duration", 0, "Fail endpoints whose requests take longer than this, even when the response passes (0 = off; per-endpoint maxDurationMs takes precedence)")
+	maxBodyBytes := flags.Int64("max-body-bytes", runner.DefaultMaxBodyBytes, "Read at most this many bytes of each response body, failing checks that need the rest (0 = no limit; per-endpoint maxBodyBytes takes precedence)")
+	repeatRuns := flags.Int("repeat", 1, "Run each endpoint N times and report its pass rate, flagging endpoints that pass only intermittently as flaky")
+	rateLimit := flags.String("rate", "", "Pace requests to at most this rate, e.g. 5/s or 100/m, to stay below an API's throttling limit (default: unlimited)")
+	maxThrottleRetries := flags.Int("max-throttle-retries", 0, "Retry 429 responses up to N times, honoring Retry-After (per-endpoint maxThrottleRetries takes precedence)")
+	logLevel := flags.String("log-level", "info", "Minimum log level: debug, info, warn or error")
+	logFormat := flags.String("log-format", logging.FormatText, "Log format: text or json")
+	logFile := flags.String("log-file", "", "Append logs to this file instead of stderr")
+	reportFile := flags.String("report-file", "", "Write a machine-readable report of the run to this file")
+	reportFormat := flags.String("report", report.FormatJSON, "Report format for -report-file: json, csv or markdown")
+	signKey := flags.String("sign-report", "", "Sign the report with this PEM private key (RSA, ECDSA or Ed25519), writing a detached JWS next to it")
+	exportVars := flags.String("export-vars", "", "Write the captured variables to this file after the run: JSON for a .json file, dotenv otherwise")
+	historyPath := flags.String("history", "", "Append the outcome of each run to this SQLite history database")
+	compareLast := flags.Bool("compare-last", false, "Flag endpoints that regressed against the runs in -history: newly failing, or slower than their rolling average")
+	baselinePath := flags.String("baseline", "", "Compare the run against this known-good baseline: new failures, fixed endpoints and latency regressions")
+	securityScan := flags.Bool("security-scan", false, "Grade each endpoint's response against a security header checklist (HSTS, X-Content-Type-Options, Cache-Control, Server, X-Powered-By)")
+	preacquireTokens := flags.Bool("preacquire-tokens", false, "Acquire every distinct token in parallel before the endpoints run, reporting authentication failures together at the start")
+	minTokenLifetime := flags.Duration("min-token-lifetime", 0, "Warn about tokens issued for less than this duration, e.g. 1h (0 = off)")
+	verifySecrets := flags.Bool("verify-secrets", false, "Also acquire a token with the clientSecretSecondary of endpoints that have one, failing them unless both secrets work")
+	trace := flags.Bool("trace", false, "Send a W3C traceparent header with every request and record its trace ID in the output and reports")
+	updateGolden := flags.Bool("update-golden", false, "Record the response bodies of endpoints with a goldenFile as their golden files instead of comparing them")
+	writeBaseline := flags.Bool("write-baseline", false, "Record this run as the -baseline instead of comparing against it (the run must pass)")
+	regressionThreshold := flags.Float64("regression-threshold", 50, "Percentage above the rolling average (-compare-last) or baseline (-baseline) duration at which an endpoint is flagged as slower")
+	githubActions := flags.Bool("github-actions", ghactions.Enabled(os.Getenv), "Annotate failures and write step outputs to GITHUB_OUTPUT (default: on when GITHUB_ACTIONS=true)")
+	output := flags.String("output", outputText, "Output format: text, or github to also annotate failures, write step outputs and append a Markdown summary to GITHUB_STEP_SUMMARY")
+	quiet := flags.Bool("quiet", false, "Print only failed endpoints and the summary")
+	noColor := flags.Bool("no-color", os.Getenv("NO_COLOR") != "", "Print plain ASCII output without colors, emoji or other symbols (default: on when NO_COLOR is set)")
+	progressMode := flags.String("progress", progressAuto, "Show a progress bar instead of every endpoint: auto (on a terminal, for suites of at least 50 endpoints, without -verbose or -quiet), always or never")
+	if err := flags.Parse(args); err != nil {
+		return flagsExitCode(err)
+	}
+
+	// Print version and exit if requested
+	if *versionFlag {
+		printVersion()
+		return exitOK
+	}
+
+	// Set up structured logging; the report itself is printed to stdout
+	logOptions := logging.Options{Level: *logLevel, Format: *logFormat, File: *logFile}
+	if *verbose {
+		logOptions.Level = "debug"
+	}
+	logger, logCloser, err := logging.New(logOptions, os.Stderr)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to set up logging: %v\n", err)
+		return exitError
+	}
+	defer func() {
+		if closeErr := logCloser.Close(); closeErr != nil {
+			fmt.Fprintf(os.Stderr, "Failed to close log file: %v\n", closeErr)
+		}
+	}()
+	slog.SetDefault(logger)
+
+	// Load configuration
+	cfg, err := config.LoadConfig(*configPath)
+	if err != nil {
+		fatal(logger, exitConfigError, "failed to load configuration", err)
+	}
+	redact.Register(cfg.Secrets()...)
+	if *staticToken == "" {
+		*staticToken = os.Getenv(staticTokenEnv)
+	}
+	redact.Register(*staticToken)
+	logger.Info("starting run", "version", version, "config", *configPath, "endpoints", len(cfg.Endpoints))
+
+	ipVersion, err := parseIPVersion(*ipVersionFlag)
+	if err != nil {
+		fatal(logger, exitConfigError, "invalid -ip-version", err)
+	}
+	showProgress, err := progressEnabled(*progressMode, len(cfg.Endpoints), *verbose || *quiet)
+	if err != nil {
+		fatal(logger, exitConfigError, "invalid -progress", err)
+	}
+	out := console.New(os.Stdout, console.Options{
+		Quiet:    *quiet,
+		Color:    !*noColor && console.ColorEnabled(os.Stdout, os.Getenv),
+		Plain:    *noColor,
+		Progress: showProgress,
+	})
+	if progress, ok := out.(*console.Progress); ok && *logFile == "" {
+		// Log records on stderr would otherwise be written into the bar, and
+		// one info record per endpoint would bury it
+		if !flagSet(flags, "log-level") {
+			logOptions.Level = "warn"
+		}
+		progressLogger, _, err := logging.New(logOptions, progress.Bypass(os.Stderr))
+		if err != nil {
+			fatal(logger, exitError, "failed to set up logging", err)
+		}
+		logger = progressLogger
+		slog.SetDefault(logger)
+	}
+	out.Start(cfg)
+
+	// Apply command-line defaults to endpoints that do not set their own
+	for i := range cfg.Endpoints {
+		endpoint := &cfg.Endpoints[i]
+		if endpoint.MaxThrottleRetries == nil {
+			endpoint.MaxThrottleRetries = maxThrottleRetries
+		}
+		if endpoint.ProxyURL == "" {
+			endpoint.ProxyURL = *proxyURL
+		}
+		if endpoint.TokenProxyURL == "" {
+			endpoint.TokenProxyURL = *proxyURL
+		}
+		if endpoint.IPVersion == 0 {
+			endpoint.IPVersion = ipVersion
+		}
+		if endpoint.AzureRegion == "" && !endpoint.ManagedIdentity && endpoint.FederatedCredential == "" {
+			endpoint.AzureRegion = *azureRegion
+		}
+		if endpoint.StaticToken == "" && !endpoint.ManagedIdentity && endpoint.FederatedCredential == "" && len(endpoint.Personas) == 0 && len(endpoint.Tenants) == 0 && len(endpoint.Scopes) == 0 {
+			endpoint.StaticToken = *staticToken
+		}
+	}
+
+	// Initialize auth and API clients
+	tokenProviders, err := runner.TokenProviders(cfg.Endpoints)
+	if err != nil {
+		fatal(logger, exitConfigError, "failed to configure token acquisition", err)
+	}
+	apiClient := client.NewAPIClient()
+	variables := vars.NewStore()
+
+	var recorders []client.Recorder
+	var harRecorder *har.Recorder
+	if *harPath != "" {
+		harRecorder, err = har.Create(*harPath, version)
+		if err != nil {
+			fatal(logger, exitError, "failed to start HAR capture", err)
+		}
+		recorders = append(recorders, harRecorder)
+	}
+	var dumper *dump.Recorder
+	if *dumpDir != "" {
+		if dumper, err = dump.New(*dumpDir); err != nil {
+			fatal(logger, exitError, "failed to set up -dump-on-failure", err)
+		}
+		recorders = append(recorders, dumper)
+	}
+	if len(recorders) > 0 {
+		apiClient.SetRecorder(client.MultiRecorder(recorders...))
+	}
+
+	webhook, err := notify.New(cfg.Notifications, nil)
+	if err != nil {
+		fatal(logger, exitConfigError, "invalid webhook notification", err)
+	}
+
+	switch *reportFormat {
+	case report.FormatJSON, report.FormatCSV, report.FormatMarkdown:
+	default:
+		fatal(logger, exitConfigError, "invalid -report", fmt.Errorf("must be json, csv or markdown: %s", *reportFormat))
+	}
+	if *signKey != "" && *reportFile == "" {
+		fatal(logger, exitConfigError, "invalid -sign-report", errors.New("requires -report-file"))
+	}
+
+	if *compareLast && *historyPath == "" {
+		fatal(logger, exitConfigError, "invalid -compare-last", errors.New("requires -history"))
+	}
+	if *writeBaseline && *baselinePath == "" {
+		fatal(logger, exitConfigError, "invalid -write-baseline", errors.New("requires -baseline"))
+	}
+	if *regressionThreshold < 0 {
+		fatal(logger, exitConfigError, "invalid -regression-threshold", fmt.Errorf("must not be negative: %g", *regressionThreshold))
+	}
+
+	switch *output {
+	case outputText:
+	case outputGitHub:
+		*githubActions = true
+	default:
+		fatal(logger, exitConfigError, "invalid -output", fmt.Errorf("must be %s or %s: %s", outputText, outputGitHub, *output))
+	}
+
+	if *repeatRuns < 1 {
+		fatal(logger, exitConfigError, "invalid -repeat", fmt.Errorf("must be at least 1: %d", *repeatRuns))
+	}
+
+	var rate runner.Rate
+	if *rateLimit != "" {
+		if rate, err = runner.ParseRate(*rateLimit); err != nil {
+			fatal(logger, exitConfigError, "invalid -rate", err)
+		}
+	}
+
+	if *maxMemoryMB < 0 {
+		fatal(logger, exitConfigError, "invalid -max-memory-mb", fmt.Errorf("must not be negative: %d", *maxMemoryMB))
+	}
+	ctx, cancel := context.WithCancelCause(context.Background())
+	defer cancel(nil)
+	usageMonitor := monitor.New(*maxMemoryMB)
+	usageMonitor.Start(cancel, monitor.DefaultInterval)
+	stopSignals := cancelOnSignal(cancel)
+
+	// Determine execution order from endpoint dependencies
+	order, err := cfg.ExecutionOrder()
+	if err != nil {
+		fatal(logger, exitConfigError, "failed to order endpoints", err)
+	}
+
+	// Test each endpoint
+	testRunner := runner.New(apiClient, variables)
+	testRunner.MaxClockSkew = *maxClockSkew
+	testRunner.SlowThreshold = *slowThreshold
+	testRunner.MaxDuration = *maxDuration
+	testRunner.MaxBodyBytes = *maxBodyBytes
+	testRunner.UpdateGolden = *updateGolden
+	testRunner.SecurityScan = *securityScan
+	testRunner.Trace = *trace
+	testRunner.VerifySecondarySecrets = *verifySecrets
+	testRunner.MinTokenLifetime = *minTokenLifetime
+	testRunner.Rate = rate
+	testRunner.Logger = logger
+	testRunner.Personas = cfg.Personas
+
+	startedAt := time.Now()
+	var tokenAcquisitions []runner.TokenAcquisition
+	var tokensDuration time.Duration
+	if *preacquireTokens {
+		tokenProviders, tokenAcquisitions = runner.PreAcquireTokens(ctx, cfg.Endpoints, cfg.Personas, tokenProviders)
+		tokensDuration = time.Since(startedAt)
+		printTokenAcquisitions(out, tokenAcquisitions, tokensDuration)
+	}
+	writeFailed := false
+	results := make([]*runner.Result, 0, len(cfg.Endpoints))
+	passed := make(map[string]bool, len(cfg.Endpoints))
+	for position, i := range order {
+		if ctx.Err() != nil {
+			break
+		}
+
+		endpoint := &cfg.Endpoints[i]
+		out.Testing(position+1, len(cfg.Endpoints), endpoint)
+		if dumper != nil {
+			dumper.Reset()
+		}
+
+		var result *runner.Result
+		if failed := runner.FailedDependency(endpoint, passed); failed != "" {
+			result = runner.Skip(endpoint, failed)
+		} else {
+			result = testRunner.RunRepeated(ctx, endpoint, tokenProviders[endpoint.TokenProxyURL], *repeatRuns)
+		}
+		if ctx.Err() != nil && !result.Success() {
+			// The run was cancelled while this endpoint was in flight; it did
+			// not complete, so it is left out of the summary
+			out.Cancelled(context.Cause(ctx))
+			break
+		}
+		passed[endpoint.Name] = result.Success()
+		results = append(results, result)
+		logger.Info("endpoint finished", "result", result)
+
+		out.Result(result)
+		if dumper != nil && !result.Success() && !result.Skipped {
+			if path, err := dumper.Dump(result); err != nil {
+				logger.Error("failed to dump failed endpoint", "endpoint", endpoint.Name, "error", err)
+				writeFailed = true
+			} else {
+				out.Notef("    Request and response written to %s", path)
+			}
+		}
+		if *githubActions {
+			printAnnotations(result)
+		}
+	}
+
+	usageMonitor.Stop()
+	stopSignals()
+
+	// Print summary
+	aborted := ctx.Err() != nil
+	var abortCause error
+	if aborted {
+		abortCause = context.Cause(ctx)
+	}
+	out.Summary(results, len(cfg.Endpoints), abortCause)
+	out.Notef("Resource usage: %s", usageMonitor.Usage())
+
+	if harRecorder != nil {
+		if err := harRecorder.Close(); err != nil {
+			logger.Error("failed to write HAR file", "error", err)
+		} else {
+			out.Notef("HAR written to %s", *harPath)
+		}
+	}
+
+	runReport := report.New(version, startedAt, time.Now(), results)
+	if aborted {
+		runReport.Aborted = context.Cause(ctx).Error()
+	}
+	runReport.RecordPacing(rate.String(), testRunner.Requests())
+	if *preacquireTokens {
+		runReport.RecordTokens(tokensDuration, tokenAcquisitions)
+	}
+	if pacing := runReport.Pacing; pacing != nil {
+		out.Notef("Pacing: %d request(s) at %.2f/s, waited %v", pacing.Requests, pacing.EffectiveRate, time.Duration(pacing.WaitMs)*time.Millisecond)
+	}
+	summary := runReport.Summary
+	if *reportFile != "" {
+		if err := writeReport(out, runReport, *reportFile, *reportFormat, *signKey); err != nil {
+			logger.Error("failed to write report", "error", err)
+			writeFailed = true
+		}
+	}
+
+	if *exportVars != "" {
+		if err := variables.ExportFile(*exportVars); err != nil {
+			logger.Error("failed to export variables", "error", err)
+			writeFailed = true
+		} else {
+			out.Notef("Variables exported to %s", *exportVars)
+		}
+	}
+
+	if *historyPath != "" {
+		if err := recordHistory(out, runReport, *historyPath, *compareLast, *regressionThreshold, *githubActions); err != nil {
+			logger.Error("failed to update run history", "error", err)
+		}
+	}
+
+	if *baselinePath != "" {
+		if *writeBaseline {
+			if err := history.WriteBaseline(*baselinePath, runReport); err != nil {
+				logger.Error("failed to record baseline", "error", err)
+			} else {
+				out.Notef("Baseline written to %s", *baselinePath)
+			}
+		} else if err := compareBaseline(out, runReport, *baselinePath, *regressionThreshold, *githubActions); err != nil {
+			logger.Error("failed to compare against baseline", "error", err)
+		}
+	}
+
+	if webhook != nil && summary.Failed > 0 {
+		// The run context may already be cancelled; the webhook has its own timeout
+		if err := webhook.Notify(context.Background(), runReport); err != nil {
+			logger.Error("failed to send webhook notification", "error", err)
+		} else {
+			logger.Info("webhook notification sent")
+		}
+	}
+
+	// Exit with a code that tells why the run failed
+	exitCode := failureExitCode(summary)
+	switch {
+	case errors.Is(context.Cause(ctx), errInterrupted):
+		exitCode = exitInterrupted
+	case exitCode != exitOK:
+	case aborted || writeFailed:
+		exitCode = exitError
+	}
+	logger.Info("run finished", "completed", len(results), "endpoints", len(cfg.Endpoints), "failed", summary.Failed, "aborted", aborted, "exit_code", exitCode)
+
+	if *githubActions {
+		if err := writeGitHubOutputs(summary, exitCode, *reportFile); err != nil {
+			logger.Error("failed to write GitHub Actions outputs", "error", err)
+		}
+	}
+	if *output == outputGitHub {
+		if err := writeStepSummary(runReport); err != nil {
+			logger.Error("failed to write GitHub Actions job summary", "error", err)
+		}
+	}
+
+	return exitCode
+}
+
+// Values of -progress
+const (
+	progressAuto   = "auto"
+	progressAlways = "always"
+	progressNever  = "never"
+)
+
+// printTokenAcquisitions prints how many tokens -preacquire-tokens acquired
+// and why the others could not be
+func printTokenAcquisitions(out console.Formatter, acquisitions []runner.TokenAcquisition, duration time.Duration) {
+	acquired := 0
+	for _, acquisition := range acquisitions {
+		if acquisition.Err == nil {
+			acquired++
+		}
+	}
+	out.Notef("Acquired %d of %d token(s) in %v", acquired, len(acquisitions), duration.Truncate(time.Millisecond))
+	for _, acquisition := range acquisitions {
+		if acquisition.Err != nil {
+			out.Notef("    Token for %s (tenant %s, client %s) used by %s: %s", acquisition.Scope, acquisition.TenantID, acquisition.ClientID,
+				strings.Join(acquisition.Endpoints, ", "), redact.String(acquisition.Err.Error()))
+		}
+	}
+}
+
+// ipVersionAuto is the -ip-version that connects over either address family
+const ipVersionAuto = "auto"
+
+// parseIPVersion converts an -ip-version to the IP version connections are
+// limited to, or zero for auto
+func parseIPVersion(value string) (int, error) {
+	switch value {
+	case ipVersionAuto:
+		return 0, nil
+	case "4":
+		return client.IPv4, nil
+	case "6":
+		return client.IPv6, nil
+	default:
+		return 0, fmt.Errorf("must be 4, 6 or %s: %s", ipVersionAuto, value)
+	}
+}
+
+// progressMinEndpoints is the suite size from which -progress auto shows
+// a progress bar
+const progressMinEndpoints = 50
+
+// progressEnabled reports whether a run of the given number of endpoints
+// shows a progress bar. In auto mode, it needs a terminal, a large suite
+// and no verbose or quiet output.
+func progressEnabled(mode string, endpoints int, verboseOrQuiet bool) (bool, error) {
+	switch mode {
+	case progressAlways:
+		return true, nil
+	case progressNever:
+		return false, nil
+	case progressAuto:
+		return !verboseOrQuiet && endpoints >= progressMinEndpoints && console.IsTerminal(os.Stdout), nil
+	default:
+		return false, fmt.Errorf("must be %s, %s or %s: %s", progressAuto, progressAlways, progressNever, mode)
+	}
+}
+
+// flagSet reports whether the named flag was given on the command line
+func flagSet(flags *flag.FlagSet, name string) bool {
+	set := false
+	flags.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/hutstep/entra-id-api-tester/internal/logging"
+	"github.com/hutstep/entra-id-api-tester/internal/redact"
+	"github.com/hutstep/entra-id-api-tester/internal/report"
+	"github.com/hutstep/entra-id-api-tester/internal/server"
+	"github.com/hutstep/entra-id-api-tester/pkg/tester"
+)
+
+// serveTokenEnv is the default of the serve command's -auth-token flag
+const serveTokenEnv = "API_TESTER_SERVE_TOKEN"
+
+// shutdownTimeout bounds the wait for in-flight API requests on shutdown
+const shutdownTimeout = 10 * time.Second
+
+// runServe implements the "serve" subcommand: an HTTP API that triggers
+// runs and serves their results until the process is interrupted. It
+// returns the process exit code.
+func runServe(args []string) int {
+	flags := flag.NewFlagSet("serve", flag.ContinueOnError)
+	configPath := flags.String("config", defaultConfigPath, "Path to configuration file; it is reloaded for every run")
+	host := flags.String("host", "127.0.0.1", "Interface to listen on; any other than loopback, e.g. 0.0.0.0 for all interfaces, requires an auth token")
+	port := flags.Int("port", 8080, "Port to listen on")
+	authToken := flags.String("auth-token", "", "Require this bearer token on every API request (default: $"+serveTokenEnv+")")
+	historyPath := flags.String("history", "", "Load the endpoint history from this SQLite history database and append every run to it")
+	logLevel := flags.String("log-level", "info", "Minimum log level: debug, info, warn or error")
+	logFormat := flags.String("log-format", logging.FormatText, "Log format: text or json")
+	if err := flags.Parse(args); err != nil {
+		return flagsExitCode(err)
+	}
+
+	if *port < 1 || *port > 65535 {
+		log.Printf("Invalid -port: must be between 1 and 65535: %d", *port)
+		return exitConfigError
+	}
+	logger, logCloser, err := logging.New(logging.Options{Level: *logLevel, Format: *logFormat}, os.Stderr)
+	if err != nil {
+		log.Printf("Failed to set up logging: %v", err)
+		return exitConfigError
+	}
+	defer func() { _ = logCloser.Close() }()
+
+	// Fail fast on a configuration that cannot run; runs reload it
+	if _, err := tester.LoadConfig(*configPath); err != nil {
+		log.Printf("Failed to load configuration: %v", err)
+		return exitConfigError
+	}
+	if *authToken == "" {
+		*authToken = os.Getenv(serveTokenEnv)
+	}
+	redact.Register(*authToken)
+	if *authToken == "" && !isLoopbackHost(*host) {
+		log.Printf("Refusing to listen on %q without an auth token: set -auth-token or $%s, or listen on 127.0.0.1", *host, serveTokenEnv)
+		return exitConfigError
+	}
+
+	apiServer, err := server.New(func(ctx context.Context) (*report.Report, error) {
+		cfg, err := tester.LoadConfig(*configPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load configuration: %w", err)
+		}
+		runReport, err := tester.Run(ctx, cfg, tester.Options{Version: version, Logger: logger})
+		if err != nil {
+			return nil, err
+		}
+		return &runReport, nil
+	}, server.Options{Logger: logger, Token: *authToken, HistoryPath: *historyPath})
+	if err != nil {
+		log.Printf("Failed to load history: %v", err)
+		return exitConfigError
+	}
+
+	address := net.JoinHostPort(*host, strconv.Itoa(*port))
+	httpServer := &http.Server{
+		Addr:              address,
+		Handler:           apiServer.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	defer cancel(nil)
+	stopSignals := cancelOnSignal(cancel)
+	defer stopSignals()
+
+	serveErr := make(chan error, 1)
+	go func() { serveErr <- httpServer.ListenAndServe() }()
+	fmt.Printf("Serving the API tester on %s (configuration: %s)\n", address, *configPath)
+	if *authToken == "" {
+		fmt.Println("⚠️  WARNING: no -auth-token set; any local user or process can trigger runs")
+	}
+
+	select {
+	case err := <-serveErr:
+		apiServer.Close()
+		log.Printf("Failed to serve: %v", err)
+		return exitError
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancelShutdown()
+	apiServer.Close()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Printf("Failed to shut down: %v", err)
+		return exitError
+	}
+	return exitOK
+}
+
+// isLoopbackHost reports whether host, a -host value, only accepts
+// connections from the local machine
+func isLoopbackHost(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
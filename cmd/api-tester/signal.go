@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// errInterrupted is the cancellation cause of a run stopped by a signal
+var errInterrupted = errors.New("interrupted")
+
+// cancelOnSignal cancels the run on the first SIGINT or SIGTERM so in-flight
+// requests are aborted and the partial summary is still printed. A second
+// signal exits immediately. The returned function stops listening.
+func cancelOnSignal(cancel context.CancelCauseFunc) func() {
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	done := make(chan struct{})
+
+	go func() {
+		select {
+		case sig := <-signals:
+			fmt.Fprintf(os.Stderr, "\nReceived %s, cancelling run (press Ctrl+C again to exit immediately)...\n", signalName(sig))
+			cancel(fmt.Errorf("%w by %s", errInterrupted, signalName(sig)))
+		case <-done:
+			return
+		}
+		select {
+		case <-signals:
+			os.Exit(exitInterrupted)
+		case <-done:
+		}
+	}()
+
+	return func() {
+		signal.Stop(signals)
+		close(done)
+	}
+}
+
+// signalName returns the conventional name of a termination signal
+func signalName(sig os.Signal) string {
+	if sig == syscall.SIGTERM {
+		return "SIGTERM"
+	}
+	return "SIGINT"
+}
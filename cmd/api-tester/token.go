@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+
+	"github.com/hutstep/entra-id-api-tester/internal/auth"
+	"github.com/hutstep/entra-id-api-tester/internal/config"
+	"github.com/hutstep/entra-id-api-tester/internal/runner"
+)
+
+// runToken implements the "token" subcommand: it acquires an access token
+// with an endpoint's (or persona's) credentials and prints the token, e.g.
+// for use with curl, or its decoded claims. It returns the process exit
+// code.
+func runToken(args []string) int {
+	flags := flag.NewFlagSet("token", flag.ContinueOnError)
+	configPath := flags.String("config", defaultConfigPath, "Path to configuration file")
+	endpointName := flags.String("endpoint", "", "Name of the endpoint whose credentials and scope are used (default: the first endpoint)")
+	personaName := flags.String("persona", "", "Use this persona's credentials instead of the endpoint's")
+	showClaims := flags.Bool("claims", false, "Print the token's decoded claims instead of the token")
+	if err := flags.Parse(args); err != nil {
+		return flagsExitCode(err)
+	}
+
+	cfg, err := config.LoadConfig(*configPath)
+	if err != nil {
+		log.Printf("Failed to load configuration: %v", err)
+		return exitConfigError
+	}
+	endpoint, err := selectEndpoint(cfg, *endpointName)
+	if err != nil {
+		log.Print(err)
+		return exitConfigError
+	}
+
+	clientID, clientSecret, tenantID := endpoint.ClientID, endpoint.ClientSecret, endpoint.TokenTenantID()
+	if *personaName != "" {
+		persona := cfg.Persona(*personaName)
+		if persona == nil {
+			log.Printf("persona %q not found in configuration", *personaName)
+			return exitConfigError
+		}
+		clientID, clientSecret, tenantID = persona.ClientID, persona.ClientSecret, persona.TenantID
+	}
+	if clientID == "" && endpoint.StaticToken == "" && !endpoint.ManagedIdentity {
+		log.Printf("endpoint %q has no credentials of its own; select one of its personas with -persona", endpoint.Name)
+		return exitConfigError
+	}
+
+	providers, err := runner.TokenProviders([]config.Endpoint{*endpoint})
+	if err != nil {
+		log.Printf("Failed to configure token acquisition: %v", err)
+		return exitConfigError
+	}
+	var tokenProvider auth.TokenProvider = providers[endpoint.TokenProxyURL]
+	if endpoint.StaticToken != "" && *personaName == "" {
+		tokenProvider = &auth.StaticTokenProvider{Token: endpoint.StaticToken}
+	}
+	managedIdentity, gitHubActions, err := runner.HostTokenProviders(endpoint.TokenProxyURL)
+	if err != nil {
+		log.Printf("Failed to configure token acquisition: %v", err)
+		return exitConfigError
+	}
+	if endpoint.ManagedIdentity && *personaName == "" {
+		tokenProvider = managedIdentity
+	}
+	if endpoint.FederatedCredential == config.FederatedCredentialGitHub && *personaName == "" {
+		tokenProvider = gitHubActions
+	}
+	tokenCtx := auth.WithRegion(context.Background(), endpoint.AzureRegion)
+	if *personaName == "" {
+		tokenCtx = runner.TokenContext(context.Background(), endpoint)
+	}
+	token, err := tokenProvider.GetAccessToken(tokenCtx, clientID, clientSecret, tenantID, endpoint.TokenScope())
+	if err != nil {
+		log.Printf("Failed to acquire token: %v", err)
+		return exitAuthFailure
+	}
+
+	if !*showClaims {
+		fmt.Println(token)
+		return exitOK
+	}
+	payload, err := auth.DecodePayload(token)
+	if err != nil {
+		log.Printf("Failed to decode token: %v", err)
+		return exitError
+	}
+	claims, err := json.MarshalIndent(payload, "", "  ")
+	if err != nil {
+		log.Printf("Failed to format claims: %v", err)
+		return exitError
+	}
+	fmt.Println(string(claims))
+	return exitOK
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/hutstep/entra-id-api-tester/internal/config"
+)
+
+// runValidate implements the "validate" subcommand: it lints a configuration
+// without making network calls and lists every problem found, so it can run
+// as a pre-commit hook. It returns the process exit code.
+func runValidate(args []string) int {
+	flags := flag.NewFlagSet("validate", flag.ContinueOnError)
+	configPath := flags.String("config", defaultConfigPath, "Path to configuration file")
+	strict := flags.Bool("strict", false, "Treat warnings as errors")
+	if err := flags.Parse(args); err != nil {
+		return flagsExitCode(err)
+	}
+
+	var errors, warnings int
+	for _, problem := range config.Lint(*configPath) {
+		if problem.Severity == config.SeverityError {
+			errors++
+			fmt.Printf("✗ %s\n", problem)
+		} else {
+			warnings++
+			fmt.Printf("⚠ %s\n", problem)
+		}
+	}
+
+	if errors > 0 || (*strict && warnings > 0) {
+		fmt.Printf("\n%s: %d error(s), %d warning(s)\n", *configPath, errors, warnings)
+		return exitConfigError
+	}
+	if warnings > 0 {
+		fmt.Printf("\n✓ %s is valid with %d warning(s)\n", *configPath, warnings)
+		return exitOK
+	}
+	fmt.Printf("✓ %s is valid\n", *configPath)
+	return exitOK
+}
@@ -0,0 +1,198 @@
+// Package assert evaluates configured response assertions, including the
+// allOf/anyOf/not combinators used to express compound acceptance criteria.
+package assert
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/hutstep/entra-id-api-tester/internal/client"
+	"github.com/hutstep/entra-id-api-tester/internal/config"
+	"github.com/hutstep/entra-id-api-tester/internal/expr"
+	"github.com/hutstep/entra-id-api-tester/internal/jsonpath"
+	"github.com/hutstep/entra-id-api-tester/internal/redact"
+)
+
+// Evaluate checks every assertion against the response and returns one error
+// per failed assertion
+func Evaluate(assertions []config.Assertion, response *client.Response) []error {
+	var failures []error
+	for i := range assertions {
+		if err := Check(&assertions[i], response); err != nil {
+			failures = append(failures, err)
+		}
+	}
+	return failures
+}
+
+// Check evaluates a single assertion against the response
+func Check(assertion *config.Assertion, response *client.Response) error {
+	switch {
+	case assertion.AllOf != nil:
+		for i := range assertion.AllOf {
+			if err := Check(&assertion.AllOf[i], response); err != nil {
+				return fmt.Errorf("allOf: %w", err)
+			}
+		}
+		return nil
+
+	case assertion.AnyOf != nil:
+		failures := make([]string, 0, len(assertion.AnyOf))
+		for i := range assertion.AnyOf {
+			err := Check(&assertion.AnyOf[i], response)
+			if err == nil {
+				return nil
+			}
+			failures = append(failures, err.Error())
+		}
+		return fmt.Errorf("anyOf: no alternative matched (%s)", strings.Join(failures, " | "))
+
+	case assertion.Not != nil:
+		if err := Check(assertion.Not, response); err == nil {
+			return fmt.Errorf("not: expected %s to fail", Describe(assertion.Not))
+		}
+		return nil
+
+	case assertion.Status != 0:
+		if response.StatusCode != assertion.Status {
+			return fmt.Errorf("%s (got %d)", Describe(assertion), response.StatusCode)
+		}
+		return nil
+
+	case assertion.BodyContains != "":
+		if !bytes.Contains(response.Body, []byte(assertion.BodyContains)) {
+			return errors.New(Describe(assertion))
+		}
+		return nil
+
+	case assertion.BodyEmpty:
+		if len(bytes.TrimSpace(response.Body)) != 0 {
+			return fmt.Errorf("%s (got %d bytes)", Describe(assertion), len(response.Body))
+		}
+		return nil
+
+	case assertion.JSONPath != "":
+		return checkJSONPath(assertion, response)
+
+	case assertion.Header != "":
+		return checkHeader(assertion, response)
+
+	case assertion.CEL != "":
+		holds, err := expr.Evaluate(assertion.CEL, response)
+		if err != nil {
+			return fmt.Errorf("%s: %w", Describe(assertion), err)
+		}
+		if !holds {
+			return fmt.Errorf("%s (got false)", Describe(assertion))
+		}
+		return nil
+	}
+
+	return fmt.Errorf("empty assertion")
+}
+
+// checkJSONPath evaluates jsonPath assertions (exists / equals)
+func checkJSONPath(assertion *config.Assertion, response *client.Response) error {
+	value, err := jsonpath.LookupBytes(response.Body, assertion.JSONPath)
+
+	if assertion.Equals == nil {
+		wantExists := assertion.Exists == nil || *assertion.Exists
+		if (err == nil) != wantExists {
+			return errors.New(Describe(assertion))
+		}
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("%s (%w)", Describe(assertion), err)
+	}
+	if !reflect.DeepEqual(value, assertion.Equals) {
+		return fmt.Errorf("%s (got %s)", Describe(assertion), jsonpath.Stringify(value))
+	}
+	return nil
+}
+
+// checkHeader evaluates header assertions (exists / equals / matches). A
+// header with several values passes when any value does.
+func checkHeader(assertion *config.Assertion, response *client.Response) error {
+	values := response.Headers.Values(assertion.Header)
+	if assertion.Equals == nil && assertion.Matches == "" {
+		wantExists := assertion.Exists == nil || *assertion.Exists
+		if (len(values) > 0) != wantExists {
+			return errors.New(Describe(assertion))
+		}
+		return nil
+	}
+	if len(values) == 0 {
+		return fmt.Errorf("%s (header missing)", Describe(assertion))
+	}
+
+	var matches func(value string) bool
+	if want, ok := assertion.Equals.(string); ok {
+		matches = func(value string) bool { return value == want }
+	} else {
+		pattern, err := regexp.Compile(assertion.Matches)
+		if err != nil {
+			return fmt.Errorf("%s: %w", Describe(assertion), err)
+		}
+		matches = pattern.MatchString
+	}
+	if slices.ContainsFunc(values, matches) {
+		return nil
+	}
+	got := make([]string, len(values))
+	for i, value := range values {
+		got[i] = strconv.Quote(redact.Header(assertion.Header, value))
+	}
+	return fmt.Errorf("%s (got %s)", Describe(assertion), strings.Join(got, ", "))
+}
+
+// Describe renders an assertion as a short human-readable expectation
+func Describe(assertion *config.Assertion) string {
+	switch {
+	case assertion.AllOf != nil:
+		return "allOf(" + describeGroup(assertion.AllOf) + ")"
+	case assertion.AnyOf != nil:
+		return "anyOf(" + describeGroup(assertion.AnyOf) + ")"
+	case assertion.Not != nil:
+		return "not(" + Describe(assertion.Not) + ")"
+	case assertion.Status != 0:
+		return fmt.Sprintf("status == %d", assertion.Status)
+	case assertion.BodyContains != "":
+		return fmt.Sprintf("body contains %q", assertion.BodyContains)
+	case assertion.BodyEmpty:
+		return "body is empty"
+	case assertion.JSONPath != "" && assertion.Equals != nil:
+		return fmt.Sprintf("%s == %s", assertion.JSONPath, jsonpath.Stringify(assertion.Equals))
+	case assertion.JSONPath != "" && assertion.Exists != nil && !*assertion.Exists:
+		return assertion.JSONPath + " does not exist"
+	case assertion.JSONPath != "":
+		return assertion.JSONPath + " exists"
+	case assertion.Header != "" && assertion.Equals != nil:
+		return fmt.Sprintf("header %s == %q", assertion.Header, assertion.Equals)
+	case assertion.Header != "" && assertion.Matches != "":
+		return fmt.Sprintf("header %s matches %q", assertion.Header, assertion.Matches)
+	case assertion.Header != "" && assertion.Exists != nil && !*assertion.Exists:
+		return "header " + assertion.Header + " is absent"
+	case assertion.Header != "":
+		return "header " + assertion.Header + " is present"
+	case assertion.CEL != "":
+		return assertion.CEL
+	}
+	return "empty assertion"
+}
+
+// describeGroup renders a list of assertions separated by commas
+func describeGroup(assertions []config.Assertion) string {
+	parts := make([]string, len(assertions))
+	for i := range assertions {
+		parts[i] = Describe(&assertions[i])
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,189 @@
+package assert
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/hutstep/entra-id-api-tester/internal/client"
+	"github.com/hutstep/entra-id-api-tester/internal/config"
+)
+
+// parseAssertion decodes an assertion from its JSON configuration form
+func parseAssertion(t *testing.T, raw string) *config.Assertion {
+	t.Helper()
+	var assertion config.Assertion
+	if err := json.Unmarshal([]byte(raw), &assertion); err != nil {
+		t.Fatalf("Failed to parse assertion %s: %v", raw, err)
+	}
+	if err := assertion.Validate(); err != nil {
+		t.Fatalf("Invalid assertion %s: %v", raw, err)
+	}
+	return &assertion
+}
+
+func TestCheck_Leaves(t *testing.T) {
+	response := &client.Response{
+		StatusCode: 200,
+		Body:       []byte(`{"items": [{"id": 1}], "count": 1, "name": "contoso"}`),
+	}
+
+	tests := []struct {
+		assertion string
+		pass      bool
+	}{
+		{`{"status": 200}`, true},
+		{`{"status": 201}`, false},
+		{`{"bodyContains": "contoso"}`, true},
+		{`{"bodyContains": "fabrikam"}`, false},
+		{`{"bodyEmpty": true}`, false},
+		{`{"jsonPath": "$.items[0]"}`, true},
+		{`{"jsonPath": "$.items[0]", "exists": true}`, true},
+		{`{"jsonPath": "$.missing", "exists": false}`, true},
+		{`{"jsonPath": "$.missing"}`, false},
+		{`{"jsonPath": "$.count", "equals": 1}`, true},
+		{`{"jsonPath": "$.name", "equals": "contoso"}`, true},
+		{`{"jsonPath": "$.items", "equals": [{"id": 1}]}`, true},
+		{`{"jsonPath": "$.count", "equals": 2}`, false},
+		{`{"jsonPath": "$.missing", "equals": 2}`, false},
+		{`{"cel": "response.status == 200 && json.items.size() > 0"}`, true},
+		{`{"cel": "json.name.startsWith('fab')"}`, false},
+		{`{"cel": "json.missing == 1"}`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.assertion, func(t *testing.T) {
+			err := Check(parseAssertion(t, tt.assertion), response)
+			if tt.pass && err != nil {
+				t.Errorf("Expected pass, got %v", err)
+			}
+			if !tt.pass && err == nil {
+				t.Error("Expected failure, got nil")
+			}
+		})
+	}
+}
+
+func TestCheck_Combinators(t *testing.T) {
+	// "either 200 with items or 204 with empty body"
+	criteria := `{"anyOf": [
+		{"allOf": [{"status": 200}, {"jsonPath": "$.items[0]"}]},
+		{"allOf": [{"status": 204}, {"bodyEmpty": true}]}
+	]}`
+
+	tests := []struct {
+		name     string
+		response *client.Response
+		pass     bool
+	}{
+		{"200 with items", &client.Response{StatusCode: 200, Body: []byte(`{"items": [1]}`)}, true},
+		{"204 empty", &client.Response{StatusCode: 204}, true},
+		{"200 without items", &client.Response{StatusCode: 200, Body: []byte(`{"items": []}`)}, false},
+		{"204 with body", &client.Response{StatusCode: 204, Body: []byte(`{}`)}, false},
+		{"500", &client.Response{StatusCode: 500}, false},
+	}
+
+	assertion := parseAssertion(t, criteria)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Check(assertion, tt.response)
+			if tt.pass && err != nil {
+				t.Errorf("Expected pass, got %v", err)
+			}
+			if !tt.pass && err == nil {
+				t.Error("Expected failure, got nil")
+			}
+		})
+	}
+}
+
+func TestCheck_Header(t *testing.T) {
+	response := &client.Response{
+		StatusCode: 200,
+		Headers: http.Header{
+			"Content-Type":              {"application/json; charset=utf-8"},
+			"Strict-Transport-Security": {"max-age=31536000"},
+			"Vary":                      {"Accept", "Origin"},
+			"Set-Cookie":                {"session=secret-session-value"},
+		},
+	}
+
+	tests := []struct {
+		assertion string
+		err       string
+	}{
+		{`{"header": "Strict-Transport-Security"}`, ""},
+		{`{"header": "strict-transport-security", "exists": true}`, ""},
+		{`{"header": "X-Powered-By", "exists": false}`, ""},
+		{`{"header": "X-Frame-Options"}`, "header X-Frame-Options is present"},
+		{`{"header": "Content-Type", "exists": false}`, "header Content-Type is absent"},
+		{`{"header": "Content-Type", "equals": "application/json; charset=utf-8"}`, ""},
+		{`{"header": "Content-Type", "equals": "application/json"}`, `header Content-Type == "application/json" (got "application/json; charset=utf-8")`},
+		{`{"header": "Content-Type", "matches": "^application/json"}`, ""},
+		{`{"header": "Content-Type", "matches": "^text/"}`, `header Content-Type matches "^text/" (got "application/json; charset=utf-8")`},
+		{`{"header": "Vary", "equals": "Origin"}`, ""},
+		{`{"header": "X-Frame-Options", "equals": "DENY"}`, `header X-Frame-Options == "DENY" (header missing)`},
+		{`{"header": "Set-Cookie", "matches": "Secure"}`, `header Set-Cookie matches "Secure" (got "ses***alue")`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.assertion, func(t *testing.T) {
+			err := Check(parseAssertion(t, tt.assertion), response)
+			switch {
+			case tt.err == "" && err != nil:
+				t.Errorf("Expected pass, got %v", err)
+			case tt.err != "" && err == nil:
+				t.Error("Expected failure, got nil")
+			case tt.err != "" && err.Error() != tt.err:
+				t.Errorf("Expected %q, got %q", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestCheck_Not(t *testing.T) {
+	assertion := parseAssertion(t, `{"not": {"bodyContains": "error"}}`)
+
+	if err := Check(assertion, &client.Response{Body: []byte("all good")}); err != nil {
+		t.Errorf("Expected pass, got %v", err)
+	}
+	if err := Check(assertion, &client.Response{Body: []byte("an error occurred")}); err == nil {
+		t.Error("Expected failure, got nil")
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	assertions := []config.Assertion{
+		*parseAssertion(t, `{"status": 200}`),
+		*parseAssertion(t, `{"bodyContains": "ok"}`),
+		*parseAssertion(t, `{"jsonPath": "$.id"}`),
+	}
+
+	failures := Evaluate(assertions, &client.Response{StatusCode: 200, Body: []byte(`"ok"`)})
+	if len(failures) != 1 {
+		t.Fatalf("Expected 1 failure, got %d: %v", len(failures), failures)
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	tests := []struct {
+		assertion string
+		expected  string
+	}{
+		{`{"status": 200}`, "status == 200"},
+		{`{"jsonPath": "$.id", "exists": false}`, "$.id does not exist"},
+		{`{"jsonPath": "$.count", "equals": 3}`, "$.count == 3"},
+		{`{"not": {"bodyEmpty": true}}`, "not(body is empty)"},
+		{`{"header": "Strict-Transport-Security"}`, "header Strict-Transport-Security is present"},
+		{`{"header": "Content-Type", "matches": "json"}`, `header Content-Type matches "json"`},
+		{`{"anyOf": [{"status": 200}, {"status": 204}]}`, "anyOf(status == 200, status == 204)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			if got := Describe(parseAssertion(t, tt.assertion)); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package assert
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// CheckLocalized verifies the responses of an Accept-Language matrix. When
+// expected maps languages to text, each listed language's body must contain
+// its text; otherwise every language must produce a distinct body, proving
+// the API actually localizes.
+func CheckLocalized(languages []string, bodies [][]byte, expected map[string]string) error {
+	if len(expected) > 0 {
+		for i, language := range languages {
+			text, ok := expected[language]
+			if ok && !bytes.Contains(bodies[i], []byte(text)) {
+				return fmt.Errorf("%s response does not contain %q", language, text)
+			}
+		}
+		return nil
+	}
+
+	for i := range languages {
+		for j := i + 1; j < len(languages); j++ {
+			if bytes.Equal(bodies[i], bodies[j]) {
+				return fmt.Errorf("%s and %s responses are identical (not localized)", languages[i], languages[j])
+			}
+		}
+	}
+	return nil
+}
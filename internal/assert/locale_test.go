@@ -0,0 +1,34 @@
+package assert
+
+import (
+	"testing"
+)
+
+func TestCheckLocalized(t *testing.T) {
+	languages := []string{"en-US", "de-DE"}
+
+	tests := []struct {
+		name      string
+		bodies    [][]byte
+		expected  map[string]string
+		expectErr bool
+	}{
+		{"distinct bodies", [][]byte{[]byte("Welcome"), []byte("Willkommen")}, nil, false},
+		{"identical bodies", [][]byte{[]byte("Welcome"), []byte("Welcome")}, nil, true},
+		{"expected text present", [][]byte{[]byte("Welcome"), []byte("Willkommen")}, map[string]string{"de-DE": "Willkommen"}, false},
+		{"expected text missing", [][]byte{[]byte("Welcome"), []byte("Welcome")}, map[string]string{"de-DE": "Willkommen"}, true},
+		{"expectation allows identical bodies", [][]byte{[]byte("Hi"), []byte("Hi")}, map[string]string{"en-US": "Hi"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckLocalized(languages, tt.bodies, tt.expected)
+			if tt.expectErr && err == nil {
+				t.Error("Expected error, got nil")
+			}
+			if !tt.expectErr && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
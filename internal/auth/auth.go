@@ -6,8 +6,11 @@ package auth
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"sync"
 	"time"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
 	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 )
@@ -17,9 +20,27 @@ type TokenProvider interface {
 	GetAccessToken(ctx context.Context, clientID, clientSecret, tenantID, scope string) (string, error)
 }
 
+// ClaimsTokenProvider is a TokenProvider that can acquire a token with
+// additional claims, as requested by a Continuous Access Evaluation (CAE)
+// claims challenge
+type ClaimsTokenProvider interface {
+	TokenProvider
+	GetAccessTokenWithClaims(ctx context.Context, clientID, clientSecret, tenantID, scope, claims string) (string, error)
+}
+
 // EntraIDTokenProvider implements TokenProvider using Azure Identity SDK
 type EntraIDTokenProvider struct {
-	timeout time.Duration
+	// transport sends requests to Entra ID; nil uses the SDK default, which
+	// honors the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables
+	transport policy.Transporter
+	timeout   time.Duration
+
+	mu sync.Mutex
+	// vaultCredential reads Key Vault certificates; nil until the first is
+	// loaded, when it defaults to the host's Azure credential
+	vaultCredential azcore.TokenCredential
+	// certificates are the Key Vault certificates loaded so far
+	certificates map[KeyVaultCertificate]clientCertificate
 }
 
 // NewEntraIDTokenProvider creates a new EntraIDTokenProvider with default timeout
@@ -36,27 +57,77 @@ func NewEntraIDTokenProviderWithTimeout(timeout time.Duration) *EntraIDTokenProv
 	}
 }
 
+// NewEntraIDTokenProviderWithTransport creates a new EntraIDTokenProvider
+// that reaches Entra ID through the given transport, e.g. an *http.Client
+// configured with a proxy
+func NewEntraIDTokenProviderWithTransport(timeout time.Duration, transport policy.Transporter) *EntraIDTokenProvider {
+	return &EntraIDTokenProvider{
+		transport: transport,
+		timeout:   timeout,
+	}
+}
+
 // GetAccessToken acquires an access token using client credentials flow
 func (p *EntraIDTokenProvider) GetAccessToken(ctx context.Context, clientID, clientSecret, tenantID, scope string) (string, error) {
+	return p.getToken(ctx, clientID, clientSecret, tenantID, policy.TokenRequestOptions{
+		Scopes: []string{scope},
+	})
+}
+
+// GetAccessTokenWithClaims acquires a CAE-enabled access token that
+// satisfies the claims of a claims challenge. The token is acquired from
+// Entra ID rather than a cache, since a cached token lacks the claims.
+func (p *EntraIDTokenProvider) GetAccessTokenWithClaims(ctx context.Context, clientID, clientSecret, tenantID, scope, claims string) (string, error) {
+	return p.getToken(ctx, clientID, clientSecret, tenantID, policy.TokenRequestOptions{
+		Scopes:    []string{scope},
+		Claims:    claims,
+		EnableCAE: true,
+	})
+}
+
+// getToken acquires an access token using client credentials flow, with
+// the client secret or the Key Vault certificate of ctx
+func (p *EntraIDTokenProvider) getToken(ctx context.Context, clientID, clientSecret, tenantID string, options policy.TokenRequestOptions) (string, error) {
 	// Create context with timeout
 	ctx, cancel := context.WithTimeout(ctx, p.timeout)
 	defer cancel()
 
-	// Create client secret credential
-	credential, err := azidentity.NewClientSecretCredential(
-		tenantID,
-		clientID,
-		clientSecret,
-		nil, // Use default options
-	)
+	var certificate *clientCertificate
+	if vaultCertificate := Certificate(ctx); vaultCertificate.Name != "" {
+		loaded, err := p.loadCertificate(ctx, vaultCertificate)
+		if err != nil {
+			return "", err
+		}
+		certificate = &loaded
+	}
+
+	if region := Region(ctx); region != "" {
+		return p.getRegionalToken(ctx, clientID, clientSecret, certificate, tenantID, region, options)
+	}
+
+	// Create client secret or certificate credential
+	var credential azcore.TokenCredential
+	var err error
+	if certificate != nil {
+		certificateOptions := &azidentity.ClientCertificateCredentialOptions{}
+		if secretOptions := p.credentialOptions(ctx); secretOptions != nil {
+			certificateOptions.ClientOptions = secretOptions.ClientOptions
+		}
+		credential, err = azidentity.NewClientCertificateCredential(tenantID, clientID, certificate.certs, certificate.key, certificateOptions)
+	} else {
+		credential, err = azidentity.NewClientSecretCredential(
+			tenantID,
+			clientID,
+			clientSecret,
+			p.credentialOptions(ctx),
+		)
+	}
 	if err != nil {
 		return "", fmt.Errorf("failed to create credential: %w", err)
 	}
 
 	// Acquire token
-	token, err := credential.GetToken(ctx, policy.TokenRequestOptions{
-		Scopes: []string{scope},
-	})
+	token, err := credential.GetToken(ctx, options)
 	if err != nil {
 		return "", fmt.Errorf("failed to acquire token: %w", err)
 	}
@@ -67,3 +138,29 @@ func (p *EntraIDTokenProvider) GetAccessToken(ctx context.Context, clientID, cli
 
 	return token.Token, nil
 }
+
+// credentialOptions returns the credential options for the configured
+// transport, or nil to use the SDK defaults
+func (p *EntraIDTokenProvider) credentialOptions(ctx context.Context) *azidentity.ClientSecretCredentialOptions {
+	transport := p.tokenTransport(ctx)
+	if transport == nil {
+		return nil
+	}
+	return &azidentity.ClientSecretCredentialOptions{
+		ClientOptions: azcore.ClientOptions{Transport: transport},
+	}
+}
+
+// tokenTransport returns the configured transport, which reports token
+// servers to the recorder of ctx, if any, or nil to use the SDK defaults.
+// Both the global and the regional token endpoints are reached through it.
+func (p *EntraIDTokenProvider) tokenTransport(ctx context.Context) policy.Transporter {
+	transport := p.transport
+	if record := tokenServerRecorder(ctx); record != nil {
+		if transport == nil {
+			transport = http.DefaultClient
+		}
+		transport = tokenServerTransport{transport: transport, record: record}
+	}
+	return transport
+}
@@ -2,6 +2,7 @@ package auth
 
 import (
 	"context"
+	"net/http"
 	"testing"
 	"time"
 )
@@ -40,6 +41,22 @@ func TestNewEntraIDTokenProviderWithTimeout(t *testing.T) {
 	}
 }
 
+func TestNewEntraIDTokenProviderWithTransport(t *testing.T) {
+	transport := &http.Client{}
+	provider := NewEntraIDTokenProviderWithTransport(10*time.Second, transport)
+	if provider.timeout != 10*time.Second {
+		t.Errorf("Expected timeout to be 10s, got %v", provider.timeout)
+	}
+
+	opts := provider.credentialOptions(context.Background())
+	if opts == nil || opts.Transport != transport {
+		t.Error("Expected credential options to use the configured transport")
+	}
+	if NewEntraIDTokenProvider().credentialOptions(context.Background()) != nil {
+		t.Error("Expected default provider to use SDK default options")
+	}
+}
+
 func TestGetAccessToken_InvalidCredentials(t *testing.T) {
 	provider := NewEntraIDTokenProviderWithTimeout(5 * time.Second)
 	ctx := context.Background()
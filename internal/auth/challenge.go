@@ -0,0 +1,138 @@
+package auth
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Challenge is an authentication challenge from a WWW-Authenticate header,
+// e.g. Bearer authorization_uri="https://login.microsoftonline.com/...".
+// Parameter names are lowercase.
+type Challenge struct {
+	Params map[string]string
+	Scheme string
+}
+
+// ParseChallenges parses the challenges of one or more WWW-Authenticate
+// header values (RFC 9110, section 11.6.1). A header may list several
+// challenges, separated by commas like their parameters.
+func ParseChallenges(values []string) []Challenge {
+	var challenges []Challenge
+	for _, value := range values {
+		p := challengeParser{input: value}
+		for {
+			// Stray '=' is the padding of a token68 credential, which is
+			// not a parameter and is ignored
+			p.skip(" \t,=")
+			name := p.token()
+			if name == "" {
+				break
+			}
+			p.skip(" \t")
+			if p.peek() != '=' {
+				challenges = append(challenges, Challenge{Scheme: name, Params: map[string]string{}})
+				continue
+			}
+			p.pos++
+			p.skip(" \t")
+			if value := p.value(); len(challenges) > 0 {
+				challenges[len(challenges)-1].Params[strings.ToLower(name)] = value
+			}
+		}
+	}
+	return challenges
+}
+
+// Bearer returns the first Bearer challenge, if any
+func Bearer(challenges []Challenge) (Challenge, bool) {
+	for _, challenge := range challenges {
+		if strings.EqualFold(challenge.Scheme, "Bearer") {
+			return challenge, true
+		}
+	}
+	return Challenge{}, false
+}
+
+// ClaimsChallenge returns the claims requested by a Continuous Access
+// Evaluation claims challenge, i.e. a Bearer challenge with
+// error="insufficient_claims", decoded from base64. ok is false when there
+// is no such challenge; err reports a challenge without usable claims.
+func ClaimsChallenge(challenges []Challenge) (claims string, ok bool, err error) {
+	challenge, found := Bearer(challenges)
+	if !found || challenge.Params["error"] != "insufficient_claims" {
+		return "", false, nil
+	}
+	encoded := challenge.Params["claims"]
+	if encoded == "" {
+		return "", true, fmt.Errorf("claims challenge without claims")
+	}
+
+	var decoded []byte
+	for _, encoding := range []*base64.Encoding{base64.StdEncoding, base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding} {
+		if decoded, err = encoding.DecodeString(encoded); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return "", true, fmt.Errorf("claims challenge with invalid base64 claims: %w", err)
+	}
+	if !json.Valid(decoded) {
+		return "", true, fmt.Errorf("claims challenge with claims that are not JSON: %s", decoded)
+	}
+	return string(decoded), true, nil
+}
+
+// challengeParser reads a WWW-Authenticate header value
+type challengeParser struct {
+	input string
+	pos   int
+}
+
+// peek returns the next byte, or 0 at the end
+func (p *challengeParser) peek() byte {
+	if p.pos < len(p.input) {
+		return p.input[p.pos]
+	}
+	return 0
+}
+
+// skip advances past any of chars
+func (p *challengeParser) skip(chars string) {
+	for p.pos < len(p.input) && strings.IndexByte(chars, p.input[p.pos]) >= 0 {
+		p.pos++
+	}
+}
+
+// token reads a token, stopping at whitespace, '=', ',' or a quote
+func (p *challengeParser) token() string {
+	start := p.pos
+	for p.pos < len(p.input) && strings.IndexByte(" \t=,\"", p.input[p.pos]) < 0 {
+		p.pos++
+	}
+	return p.input[start:p.pos]
+}
+
+// value reads a parameter value, either a token or a quoted string
+func (p *challengeParser) value() string {
+	if p.peek() != '"' {
+		return p.token()
+	}
+	p.pos++
+	var b strings.Builder
+	for p.pos < len(p.input) {
+		c := p.input[p.pos]
+		p.pos++
+		switch {
+		case c == '\\' && p.pos < len(p.input):
+			b.WriteByte(p.input[p.pos])
+			p.pos++
+		case c == '"':
+			return b.String()
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,108 @@
+package auth
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseChallenges(t *testing.T) {
+	tests := []struct {
+		name     string
+		values   []string
+		expected []Challenge
+	}{
+		{
+			name:     "bare scheme",
+			values:   []string{"Bearer"},
+			expected: []Challenge{{Scheme: "Bearer", Params: map[string]string{}}},
+		},
+		{
+			name:   "Entra ID challenge",
+			values: []string{`Bearer authorization_uri="https://login.microsoftonline.com/tenant/oauth2/authorize", Error="invalid_token", error_description="The access token is missing"`},
+			expected: []Challenge{{Scheme: "Bearer", Params: map[string]string{
+				"authorization_uri": "https://login.microsoftonline.com/tenant/oauth2/authorize",
+				"error":             "invalid_token",
+				"error_description": "The access token is missing",
+			}}},
+		},
+		{
+			name:   "several challenges and headers",
+			values: []string{`Basic realm="api", charset=UTF-8`, `Negotiate, Bearer realm="a \"quoted\" realm"`},
+			expected: []Challenge{
+				{Scheme: "Basic", Params: map[string]string{"realm": "api", "charset": "UTF-8"}},
+				{Scheme: "Negotiate", Params: map[string]string{}},
+				{Scheme: "Bearer", Params: map[string]string{"realm": `a "quoted" realm`}},
+			},
+		},
+		{
+			name: "empty",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseChallenges(tt.values); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Expected %+v, got %+v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestBearer(t *testing.T) {
+	challenges := ParseChallenges([]string{`Basic realm="api", bearer error="invalid_token"`})
+	challenge, ok := Bearer(challenges)
+	if !ok || challenge.Params["error"] != "invalid_token" {
+		t.Errorf("Expected the bearer challenge, got %+v", challenge)
+	}
+	if _, ok := Bearer(ParseChallenges([]string{"Basic"})); ok {
+		t.Error("Expected no Bearer challenge")
+	}
+}
+
+func TestClaimsChallenge(t *testing.T) {
+	const claims = `{"access_token":{"nbf":{"essential":true,"value":"1700000000"}}}`
+	encoded := "eyJhY2Nlc3NfdG9rZW4iOnsibmJmIjp7ImVzc2VudGlhbCI6dHJ1ZSwidmFsdWUiOiIxNzAwMDAwMDAwIn19fQ=="
+
+	tests := []struct {
+		name      string
+		value     string
+		expected  string
+		expectOK  bool
+		expectErr bool
+	}{
+		{
+			name:     "CAE challenge",
+			value:    `Bearer realm="", authorization_uri="https://login.microsoftonline.com/common/oauth2/authorize", error="insufficient_claims", claims="` + encoded + `"`,
+			expected: claims,
+			expectOK: true,
+		},
+		{
+			name:  "other Bearer error",
+			value: `Bearer error="invalid_token"`,
+		},
+		{
+			name:      "without claims",
+			value:     `Bearer error="insufficient_claims"`,
+			expectOK:  true,
+			expectErr: true,
+		},
+		{
+			name:      "invalid claims",
+			value:     `Bearer error="insufficient_claims", claims="bm90IGpzb24="`,
+			expectOK:  true,
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok, err := ClaimsChallenge(ParseChallenges([]string{tt.value}))
+			if ok != tt.expectOK || (err != nil) != tt.expectErr {
+				t.Fatalf("ClaimsChallenge() ok = %v, err = %v, expected ok %v, expectErr %v", ok, err, tt.expectOK, tt.expectErr)
+			}
+			if got != tt.expected {
+				t.Errorf("ClaimsChallenge() = %s, expected %s", got, tt.expected)
+			}
+		})
+	}
+}
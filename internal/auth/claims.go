@@ -0,0 +1,147 @@
+package auth
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Claims holds the access token claims the tester inspects. Tokens are
+// decoded without signature verification; the API is responsible for that.
+type Claims struct {
+	NotBefore time.Time
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+	// Groups are the object IDs of the groups in the groups claim
+	Groups []string
+	// GroupsOverage reports that the caller is in more groups than fit in
+	// the token, so that Entra ID left out the groups claim
+	GroupsOverage bool
+}
+
+// rawClaims mirrors the JSON payload of a JWT access token
+type rawClaims struct {
+	NotBefore int64    `json:"nbf"`
+	IssuedAt  int64    `json:"iat"`
+	ExpiresAt int64    `json:"exp"`
+	Groups    []string `json:"groups"`
+	// ClaimNames names the claims left out of the token, with the source
+	// to request them from instead: "groups" in case of a groups overage
+	ClaimNames map[string]string `json:"_claim_names"`
+	// HasGroups is the groups overage indicator of implicit flow tokens
+	HasGroups bool `json:"hasgroups"`
+}
+
+// ParseClaims decodes the payload of a JWT access token
+func ParseClaims(token string) (*Claims, error) {
+	payload, err := payloadBytes(token)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw rawClaims
+	if err := json.Unmarshal(payload, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse token claims: %w", err)
+	}
+
+	return &Claims{
+		NotBefore:     unixTime(raw.NotBefore),
+		IssuedAt:      unixTime(raw.IssuedAt),
+		ExpiresAt:     unixTime(raw.ExpiresAt),
+		Groups:        raw.Groups,
+		GroupsOverage: raw.HasGroups || raw.ClaimNames["groups"] != "",
+	}, nil
+}
+
+// DecodePayload returns all claims of a JWT access token, e.g. to show
+// which audience, roles and scopes were issued
+func DecodePayload(token string) (map[string]interface{}, error) {
+	payload, err := payloadBytes(token)
+	if err != nil {
+		return nil, err
+	}
+
+	var claims map[string]interface{}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, fmt.Errorf("failed to parse token claims: %w", err)
+	}
+	return claims, nil
+}
+
+// payloadBytes extracts the decoded payload segment of a JWT
+func payloadBytes(token string) ([]byte, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("token is not a JWT")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode token payload: %w", err)
+	}
+	return payload, nil
+}
+
+// unixTime converts a NumericDate claim, leaving absent claims as zero time
+func unixTime(seconds int64) time.Time {
+	if seconds == 0 {
+		return time.Time{}
+	}
+	return time.Unix(seconds, 0)
+}
+
+// Lifetime returns how long the token was issued for, from iat, or else
+// nbf, to exp, or zero when the token lacks those claims
+func (c *Claims) Lifetime() time.Duration {
+	issued := c.IssuedAt
+	if issued.IsZero() {
+		issued = c.NotBefore
+	}
+	if issued.IsZero() || c.ExpiresAt.IsZero() {
+		return 0
+	}
+	return c.ExpiresAt.Sub(issued)
+}
+
+// ClockSkew estimates how far the local clock lags behind the token issuer,
+// based on issuance (iat) or validity start (nbf) lying in the future. It
+// returns zero when neither claim is ahead of now.
+func (c *Claims) ClockSkew(now time.Time) time.Duration {
+	skew := time.Duration(0)
+	for _, issued := range []time.Time{c.IssuedAt, c.NotBefore} {
+		if !issued.IsZero() && issued.Sub(now) > skew {
+			skew = issued.Sub(now)
+		}
+	}
+	return skew.Truncate(time.Second)
+}
+
+// WaitUntilValid blocks until the token's nbf claim has passed, so that a
+// token minted by an issuer whose clock runs ahead is not used too early.
+// It fails instead of waiting when nbf is more than tolerance in the future.
+func (c *Claims) WaitUntilValid(ctx context.Context, tolerance time.Duration) (time.Duration, error) {
+	if c.NotBefore.IsZero() {
+		return 0, nil
+	}
+
+	wait := time.Until(c.NotBefore)
+	if wait <= 0 {
+		return 0, nil
+	}
+	if wait > tolerance {
+		return 0, fmt.Errorf("token not valid until %s (%v ahead of local clock, tolerance %v)",
+			c.NotBefore.UTC().Format(time.RFC3339), wait.Truncate(time.Second), tolerance)
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return 0, fmt.Errorf("cancelled while waiting for token nbf: %w", ctx.Err())
+	case <-timer.C:
+		return wait, nil
+	}
+}
@@ -0,0 +1,191 @@
+package auth
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+// makeToken builds an unsigned JWT carrying the given claims
+func makeToken(t *testing.T, claims map[string]interface{}) string {
+	t.Helper()
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none","typ":"JWT"}`))
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatalf("Failed to marshal claims: %v", err)
+	}
+	return header + "." + base64.RawURLEncoding.EncodeToString(payload) + ".signature"
+}
+
+func TestParseClaims(t *testing.T) {
+	token := makeToken(t, map[string]interface{}{
+		"nbf": 1700000000,
+		"iat": 1700000000,
+		"exp": 1700003600,
+	})
+
+	claims, err := ParseClaims(token)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !claims.NotBefore.Equal(time.Unix(1700000000, 0)) {
+		t.Errorf("Unexpected nbf: %v", claims.NotBefore)
+	}
+	if !claims.ExpiresAt.Equal(time.Unix(1700003600, 0)) {
+		t.Errorf("Unexpected exp: %v", claims.ExpiresAt)
+	}
+}
+
+func TestClaimsLifetime(t *testing.T) {
+	tests := []struct {
+		name     string
+		claims   map[string]interface{}
+		expected time.Duration
+	}{
+		{name: "from iat", claims: map[string]interface{}{"iat": 1700000000, "nbf": 1700000300, "exp": 1700003600}, expected: time.Hour},
+		{name: "from nbf", claims: map[string]interface{}{"nbf": 1700000000, "exp": 1700001200}, expected: 20 * time.Minute},
+		{name: "no expiry", claims: map[string]interface{}{"iat": 1700000000}},
+		{name: "no issuance", claims: map[string]interface{}{"exp": 1700003600}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := ParseClaims(makeToken(t, tt.claims))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := claims.Lifetime(); got != tt.expected {
+				t.Errorf("Lifetime() = %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseClaims_Groups(t *testing.T) {
+	tests := []struct {
+		name    string
+		claims  map[string]interface{}
+		groups  []string
+		overage bool
+	}{
+		{name: "no groups", claims: map[string]interface{}{"aud": "api://orders"}},
+		{name: "groups claim", claims: map[string]interface{}{"groups": []string{"g1", "g2"}}, groups: []string{"g1", "g2"}},
+		{
+			name: "overage",
+			claims: map[string]interface{}{
+				"_claim_names":   map[string]string{"groups": "src1"},
+				"_claim_sources": map[string]interface{}{"src1": map[string]string{"endpoint": "https://graph.windows.net/tenant/users/oid/getMemberObjects"}},
+			},
+			overage: true,
+		},
+		{name: "implicit flow overage", claims: map[string]interface{}{"hasgroups": true}, overage: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := ParseClaims(makeToken(t, tt.claims))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if strings.Join(claims.Groups, ",") != strings.Join(tt.groups, ",") {
+				t.Errorf("Groups = %v, expected %v", claims.Groups, tt.groups)
+			}
+			if claims.GroupsOverage != tt.overage {
+				t.Errorf("GroupsOverage = %v, expected %v", claims.GroupsOverage, tt.overage)
+			}
+		})
+	}
+}
+
+func TestParseClaims_Invalid(t *testing.T) {
+	for _, token := range []string{"opaque-token", "a.!!!.c", "a." + base64.RawURLEncoding.EncodeToString([]byte("not json")) + ".c"} {
+		if _, err := ParseClaims(token); err == nil {
+			t.Errorf("Expected error for %q, got nil", token)
+		}
+	}
+}
+
+func TestDecodePayload(t *testing.T) {
+	token := makeToken(t, map[string]interface{}{
+		"aud":   "api://orders",
+		"roles": []string{"Orders.Read"},
+	})
+
+	claims, err := DecodePayload(token)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if claims["aud"] != "api://orders" {
+		t.Errorf("Unexpected aud: %v", claims["aud"])
+	}
+	if roles, ok := claims["roles"].([]interface{}); !ok || len(roles) != 1 || roles[0] != "Orders.Read" {
+		t.Errorf("Unexpected roles: %v", claims["roles"])
+	}
+
+	if _, err := DecodePayload("opaque-token"); err == nil {
+		t.Error("Expected error for a token that is not a JWT")
+	}
+}
+
+func TestClaims_ClockSkew(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	tests := []struct {
+		name     string
+		claims   Claims
+		expected time.Duration
+	}{
+		{"issued in the past", Claims{IssuedAt: now.Add(-5 * time.Minute), NotBefore: now.Add(-5 * time.Minute)}, 0},
+		{"issued in the future", Claims{IssuedAt: now.Add(90 * time.Second)}, 90 * time.Second},
+		{"nbf further ahead than iat", Claims{IssuedAt: now.Add(10 * time.Second), NotBefore: now.Add(30 * time.Second)}, 30 * time.Second},
+		{"no claims", Claims{}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.claims.ClockSkew(now); got != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestClaims_WaitUntilValid(t *testing.T) {
+	t.Run("already valid", func(t *testing.T) {
+		claims := Claims{NotBefore: time.Now().Add(-time.Minute)}
+		waited, err := claims.WaitUntilValid(context.Background(), time.Minute)
+		if err != nil || waited != 0 {
+			t.Errorf("Expected no wait, got %v (%v)", waited, err)
+		}
+	})
+
+	t.Run("waits within tolerance", func(t *testing.T) {
+		claims := Claims{NotBefore: time.Now().Add(50 * time.Millisecond)}
+		waited, err := claims.WaitUntilValid(context.Background(), time.Minute)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if waited <= 0 {
+			t.Error("Expected a positive wait")
+		}
+	})
+
+	t.Run("beyond tolerance", func(t *testing.T) {
+		claims := Claims{NotBefore: time.Now().Add(time.Hour)}
+		if _, err := claims.WaitUntilValid(context.Background(), time.Minute); err == nil {
+			t.Error("Expected error for nbf beyond tolerance, got nil")
+		}
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		claims := Claims{NotBefore: time.Now().Add(30 * time.Second)}
+		if _, err := claims.WaitUntilValid(ctx, time.Minute); err == nil {
+			t.Error("Expected error for cancelled context, got nil")
+		}
+	})
+}
@@ -0,0 +1,232 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// maxDiscoveryBytes caps the size of discovery documents and key sets
+const maxDiscoveryBytes = 1 << 20
+
+var (
+	// ErrUnknownKey means the token was signed with a key the JWKS does not
+	// publish, e.g. after a key rollover or with an app-specific signing key
+	ErrUnknownKey = errors.New("signing key not published")
+	// ErrNonceToken means the token carries a nonce header, as Microsoft
+	// Graph tokens do; only Graph can validate their signature
+	ErrNonceToken = errors.New("token has a nonce header and can only be validated by the resource that issued the nonce")
+)
+
+// OpenIDConfiguration holds the fields of a tenant's OpenID Connect
+// discovery document the tester checks
+type OpenIDConfiguration struct {
+	Issuer        string `json:"issuer"`
+	JWKSURI       string `json:"jwks_uri"`
+	TokenEndpoint string `json:"token_endpoint"`
+}
+
+// JSONWebKey is a public signing key published in a JWKS
+type JSONWebKey struct {
+	KeyID   string   `json:"kid"`
+	KeyType string   `json:"kty"`
+	Use     string   `json:"use"`
+	N       string   `json:"n"`
+	E       string   `json:"e"`
+	X5C     []string `json:"x5c"`
+}
+
+// JSONWebKeySet is the document served at an OpenID configuration's jwks_uri
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// jwtHeader mirrors the JOSE header of a JWT access token
+type jwtHeader struct {
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+	Nonce     string `json:"nonce"`
+}
+
+// DiscoveryURL returns the v2.0 OpenID configuration URL of a tenant
+func DiscoveryURL(authority, tenantID string) string {
+	return strings.TrimRight(authority, "/") + "/" + url.PathEscape(tenantID) + "/v2.0/.well-known/openid-configuration"
+}
+
+// FetchOpenIDConfiguration downloads a tenant's OpenID configuration
+func FetchOpenIDConfiguration(ctx context.Context, client *http.Client, authority, tenantID string) (*OpenIDConfiguration, error) {
+	var configuration OpenIDConfiguration
+	if err := getJSON(ctx, client, DiscoveryURL(authority, tenantID), &configuration); err != nil {
+		return nil, fmt.Errorf("failed to fetch OpenID configuration: %w", err)
+	}
+	return &configuration, nil
+}
+
+// Problems lists what is wrong with the configuration for tenantID: missing
+// endpoints, a key set not served over HTTPS, or an issuer for another tenant
+func (c *OpenIDConfiguration) Problems(tenantID string) []string {
+	var problems []string
+	if c.Issuer == "" {
+		problems = append(problems, "no issuer")
+	}
+	if c.TokenEndpoint == "" {
+		problems = append(problems, "no token_endpoint")
+	}
+	if c.JWKSURI == "" {
+		problems = append(problems, "no jwks_uri")
+	} else if parsed, err := url.Parse(c.JWKSURI); err != nil || parsed.Scheme != "https" {
+		problems = append(problems, fmt.Sprintf("jwks_uri %s is not an https URL", c.JWKSURI))
+	}
+	if c.Issuer != "" && guidPattern.MatchString(tenantID) &&
+		!strings.Contains(strings.ToLower(c.Issuer), strings.ToLower(tenantID)) && !strings.Contains(c.Issuer, "{tenantid}") {
+		problems = append(problems, fmt.Sprintf("issuer %s does not name tenant %s", c.Issuer, tenantID))
+	}
+	return problems
+}
+
+// FetchJWKS downloads the key set published at jwksURI
+func FetchJWKS(ctx context.Context, client *http.Client, jwksURI string) (*JSONWebKeySet, error) {
+	var keys JSONWebKeySet
+	if err := getJSON(ctx, client, jwksURI, &keys); err != nil {
+		return nil, fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+	return &keys, nil
+}
+
+// Key returns the key with the given key ID
+func (s *JSONWebKeySet) Key(kid string) (*JSONWebKey, bool) {
+	for i := range s.Keys {
+		if s.Keys[i].KeyID == kid {
+			return &s.Keys[i], true
+		}
+	}
+	return nil, false
+}
+
+// Certificate parses the first certificate of the key's x5c chain
+func (k *JSONWebKey) Certificate() (*x509.Certificate, error) {
+	if len(k.X5C) == 0 {
+		return nil, fmt.Errorf("key %s has no x5c certificate", k.KeyID)
+	}
+	der, err := base64.StdEncoding.DecodeString(k.X5C[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode x5c certificate of key %s: %w", k.KeyID, err)
+	}
+	return x509.ParseCertificate(der)
+}
+
+// publicKey returns the RSA public key from the modulus and exponent, or
+// from the x5c certificate when those are absent
+func (k *JSONWebKey) publicKey() (*rsa.PublicKey, error) {
+	if k.KeyType != "" && k.KeyType != "RSA" {
+		return nil, fmt.Errorf("key %s has unsupported type %s", k.KeyID, k.KeyType)
+	}
+	if k.N == "" || k.E == "" {
+		cert, err := k.Certificate()
+		if err != nil {
+			return nil, err
+		}
+		key, ok := cert.PublicKey.(*rsa.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("certificate of key %s has no RSA public key", k.KeyID)
+		}
+		return key, nil
+	}
+
+	n, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(k.N, "="))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode modulus of key %s: %w", k.KeyID, err)
+	}
+	e, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(k.E, "="))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode exponent of key %s: %w", k.KeyID, err)
+	}
+	exponent := new(big.Int).SetBytes(e)
+	if !exponent.IsInt64() || exponent.Int64() > 1<<31-1 {
+		return nil, fmt.Errorf("key %s has an invalid exponent", k.KeyID)
+	}
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}, nil
+}
+
+// VerifySignature checks an RS256 token's signature against the key set and
+// returns the key that signed it. A token signed with an unpublished key
+// fails with ErrUnknownKey, a token with a nonce header with ErrNonceToken.
+func VerifySignature(token string, keys *JSONWebKeySet) (*JSONWebKey, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("token is not a JWT")
+	}
+	headerBytes, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[0], "="))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode token header: %w", err)
+	}
+	var header jwtHeader
+	if err := json.Unmarshal(headerBytes, &header); err != nil {
+		return nil, fmt.Errorf("failed to parse token header: %w", err)
+	}
+
+	if header.Nonce != "" {
+		return nil, ErrNonceToken
+	}
+	if header.Algorithm != "RS256" {
+		return nil, fmt.Errorf("unsupported signing algorithm %q", header.Algorithm)
+	}
+	key, ok := keys.Key(header.KeyID)
+	if !ok {
+		return nil, fmt.Errorf("%w: kid %s", ErrUnknownKey, header.KeyID)
+	}
+	publicKey, err := key.publicKey()
+	if err != nil {
+		return key, err
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[2], "="))
+	if err != nil {
+		return key, fmt.Errorf("failed to decode token signature: %w", err)
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(publicKey, crypto.SHA256, digest[:], signature); err != nil {
+		return key, fmt.Errorf("invalid signature for kid %s: %w", header.KeyID, err)
+	}
+	return key, nil
+}
+
+// getJSON fetches rawURL and decodes its JSON body into v
+func getJSON(ctx context.Context, client *http.Client, rawURL string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, http.NoBody)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := resp.Body.Close(); closeErr != nil {
+			_ = closeErr
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned status %d", rawURL, resp.StatusCode)
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxDiscoveryBytes)).Decode(v); err != nil {
+		return fmt.Errorf("invalid JSON from %s: %w", rawURL, err)
+	}
+	return nil
+}
+
+// guidPattern matches a tenant ID, as opposed to a domain or one of the
+// common, organizations and consumers aliases
+var guidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
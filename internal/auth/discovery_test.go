@@ -0,0 +1,173 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// signedToken signs a JWT with key, using header for the JOSE header
+func signedToken(t *testing.T, key *rsa.PrivateKey, header map[string]string) string {
+	t.Helper()
+	headerJSON, err := json.Marshal(header)
+	if err != nil {
+		t.Fatalf("Failed to encode header: %v", err)
+	}
+	signingInput := base64.RawURLEncoding.EncodeToString(headerJSON) + "." + base64.RawURLEncoding.EncodeToString([]byte(`{"aud":"api://orders"}`))
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatalf("Failed to sign token: %v", err)
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+// publicJWK encodes the public half of key as a JWK
+func publicJWK(kid string, key *rsa.PrivateKey) JSONWebKey {
+	return JSONWebKey{
+		KeyID:   kid,
+		KeyType: "RSA",
+		Use:     "sig",
+		N:       base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		E:       base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+func TestVerifySignature(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	keys := &JSONWebKeySet{Keys: []JSONWebKey{publicJWK("current", key), publicJWK("next", other)}}
+
+	tests := []struct {
+		name      string
+		token     string
+		expectErr error
+		expectKid string
+	}{
+		{
+			name:      "valid signature",
+			token:     signedToken(t, key, map[string]string{"alg": "RS256", "kid": "current"}),
+			expectKid: "current",
+		},
+		{
+			name:      "rolled over key",
+			token:     signedToken(t, key, map[string]string{"alg": "RS256", "kid": "retired"}),
+			expectErr: ErrUnknownKey,
+		},
+		{
+			name:      "Graph token",
+			token:     signedToken(t, key, map[string]string{"alg": "RS256", "kid": "current", "nonce": "abc"}),
+			expectErr: ErrNonceToken,
+		},
+		{
+			name:  "signed by another key",
+			token: signedToken(t, key, map[string]string{"alg": "RS256", "kid": "next"}),
+		},
+		{
+			name:  "unsupported algorithm",
+			token: signedToken(t, key, map[string]string{"alg": "HS256", "kid": "current"}),
+		},
+		{
+			name:  "not a JWT",
+			token: "opaque",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := VerifySignature(tt.token, keys)
+			if tt.expectKid != "" {
+				if err != nil || got.KeyID != tt.expectKid {
+					t.Fatalf("Expected key %s, got %v, %v", tt.expectKid, got, err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("Expected an error")
+			}
+			if tt.expectErr != nil && !errors.Is(err, tt.expectErr) {
+				t.Errorf("Expected %v, got %v", tt.expectErr, err)
+			}
+		})
+	}
+}
+
+func TestOpenIDConfigurationProblems(t *testing.T) {
+	const tenant = "72f988bf-86f1-41af-91ab-2d7cd011db47"
+
+	tests := []struct {
+		name          string
+		configuration OpenIDConfiguration
+		expected      string
+	}{
+		{
+			name: "valid",
+			configuration: OpenIDConfiguration{
+				Issuer:        "https://login.microsoftonline.com/" + tenant + "/v2.0",
+				JWKSURI:       "https://login.microsoftonline.com/" + tenant + "/discovery/v2.0/keys",
+				TokenEndpoint: "https://login.microsoftonline.com/" + tenant + "/oauth2/v2.0/token",
+			},
+		},
+		{
+			name: "another tenant's issuer",
+			configuration: OpenIDConfiguration{
+				Issuer:        "https://login.microsoftonline.com/00000000-0000-0000-0000-000000000000/v2.0",
+				JWKSURI:       "https://login.microsoftonline.com/common/discovery/v2.0/keys",
+				TokenEndpoint: "https://login.microsoftonline.com/common/oauth2/v2.0/token",
+			},
+			expected: "issuer https://login.microsoftonline.com/00000000-0000-0000-0000-000000000000/v2.0 does not name tenant " + tenant,
+		},
+		{
+			name:          "incomplete",
+			configuration: OpenIDConfiguration{Issuer: "https://login.microsoftonline.com/{tenantid}/v2.0", JWKSURI: "http://keys.example.com"},
+			expected:      "no token_endpoint; jwks_uri http://keys.example.com is not an https URL",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := strings.Join(tt.configuration.Problems(tenant), "; "); got != tt.expected {
+				t.Errorf("Problems() = %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFetchOpenIDConfiguration(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/contoso.onmicrosoft.com/v2.0/.well-known/openid-configuration" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"issuer":"https://login.microsoftonline.com/tid/v2.0","jwks_uri":"https://keys"}`))
+	}))
+	defer server.Close()
+
+	configuration, err := FetchOpenIDConfiguration(context.Background(), server.Client(), server.URL+"/", "contoso.onmicrosoft.com")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if configuration.Issuer != "https://login.microsoftonline.com/tid/v2.0" || configuration.JWKSURI != "https://keys" {
+		t.Errorf("Unexpected configuration: %+v", configuration)
+	}
+
+	if _, err := FetchJWKS(context.Background(), server.Client(), server.URL+"/missing"); err == nil || !strings.Contains(err.Error(), "status 404") {
+		t.Errorf("Expected a 404 error, got %v", err)
+	}
+}
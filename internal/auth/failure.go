@@ -0,0 +1,58 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	msalerrors "github.com/AzureAD/microsoft-authentication-library-for-go/apps/errors"
+)
+
+// invalidSecretCodes are the AADSTS error codes Entra ID answers a wrong or
+// expired client secret with
+var invalidSecretCodes = []string{
+	"AADSTS7000215", // invalid client secret
+	"AADSTS7000222", // expired client secret
+}
+
+// throttledCodes are the AADSTS error codes of throttled token requests,
+// which Entra ID may send without a 429 status
+var throttledCodes = []string{
+	"AADSTS50196", // request loop detected
+	"AADSTS90055", // too many requests
+}
+
+// IsInvalidSecret reports whether a token acquisition failed because the
+// client secret is wrong or expired
+func IsInvalidSecret(err error) bool {
+	return containsCode(err, invalidSecretCodes)
+}
+
+// IsThrottled reports whether a token acquisition failed because Entra ID
+// throttled the client
+func IsThrottled(err error) bool {
+	var authErr *azidentity.AuthenticationFailedError
+	if errors.As(err, &authErr) && authErr.RawResponse != nil && authErr.RawResponse.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+	var callErr msalerrors.CallErr
+	if errors.As(err, &callErr) && callErr.Resp != nil && callErr.Resp.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+	return containsCode(err, throttledCodes)
+}
+
+// containsCode reports whether the message of err names one of codes
+func containsCode(err error, codes []string) bool {
+	if err == nil {
+		return false
+	}
+	message := err.Error()
+	for _, code := range codes {
+		if strings.Contains(message, code) {
+			return true
+		}
+	}
+	return false
+}
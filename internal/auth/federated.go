@@ -0,0 +1,153 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+)
+
+// FederatedAudience is the audience Entra ID federated credentials expect
+// of the tokens exchanged for their app's tokens
+const FederatedAudience = "api://AzureADTokenExchange"
+
+// ClientAssertionTokenProvider acquires tokens with a client assertion
+// instead of a client secret: a token another identity provider issued to
+// the caller, which the app registration trusts through a federated
+// credential. The client secret passed to GetAccessToken is ignored.
+type ClientAssertionTokenProvider struct {
+	// assertion returns the token exchanged for an Entra ID token
+	assertion func(context.Context) (string, error)
+	// transport sends requests to Entra ID; nil uses the SDK default
+	transport policy.Transporter
+	timeout   time.Duration
+
+	mu sync.Mutex
+	// credentials are kept by tenant and client ID, as each caches its
+	// tokens
+	credentials map[[2]string]*azidentity.ClientAssertionCredential
+}
+
+// NewClientAssertionTokenProvider creates a ClientAssertionTokenProvider
+// that exchanges the tokens assertion returns
+func NewClientAssertionTokenProvider(assertion func(context.Context) (string, error)) *ClientAssertionTokenProvider {
+	return &ClientAssertionTokenProvider{
+		assertion:   assertion,
+		timeout:     30 * time.Second,
+		credentials: map[[2]string]*azidentity.ClientAssertionCredential{},
+	}
+}
+
+// NewGitHubActionsTokenProvider creates a ClientAssertionTokenProvider that
+// exchanges the OIDC token of the GitHub Actions job it runs in, so that
+// workflows need no stored secret
+func NewGitHubActionsTokenProvider() *ClientAssertionTokenProvider {
+	return NewClientAssertionTokenProvider(func(ctx context.Context) (string, error) {
+		return GitHubActionsToken(ctx, http.DefaultClient, os.Getenv)
+	})
+}
+
+// NewGitHubActionsTokenProviderWithClient creates a GitHub Actions token
+// provider that requests the OIDC token from GitHub and exchanges it with
+// Entra ID through client, e.g. one configured with a proxy
+func NewGitHubActionsTokenProviderWithClient(client *http.Client) *ClientAssertionTokenProvider {
+	provider := NewClientAssertionTokenProvider(func(ctx context.Context) (string, error) {
+		return GitHubActionsToken(ctx, client, os.Getenv)
+	})
+	provider.transport = client
+	return provider
+}
+
+// GetAccessToken acquires a token for scope in exchange for the assertion
+func (p *ClientAssertionTokenProvider) GetAccessToken(ctx context.Context, clientID, clientSecret, tenantID, scope string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	credential, err := p.credential(tenantID, clientID)
+	if err != nil {
+		return "", fmt.Errorf("failed to create credential: %w", err)
+	}
+	token, err := credential.GetToken(ctx, policy.TokenRequestOptions{Scopes: []string{scope}})
+	if err != nil {
+		return "", fmt.Errorf("failed to acquire token: %w", err)
+	}
+	if token.Token == "" {
+		return "", fmt.Errorf("received empty token")
+	}
+	return token.Token, nil
+}
+
+// credential returns the credential of the app with clientID in tenantID
+func (p *ClientAssertionTokenProvider) credential(tenantID, clientID string) (*azidentity.ClientAssertionCredential, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	key := [2]string{tenantID, clientID}
+	if credential, ok := p.credentials[key]; ok {
+		return credential, nil
+	}
+	credential, err := azidentity.NewClientAssertionCredential(tenantID, clientID, p.assertion, &azidentity.ClientAssertionCredentialOptions{
+		ClientOptions: azcore.ClientOptions{Transport: p.transport},
+	})
+	if err != nil {
+		return nil, err
+	}
+	p.credentials[key] = credential
+	return credential, nil
+}
+
+// GitHubActionsToken requests an OIDC token for the running GitHub Actions
+// job with the FederatedAudience. GitHub only offers one to jobs with the
+// id-token: write permission, through the ACTIONS_ID_TOKEN_REQUEST_URL and
+// ACTIONS_ID_TOKEN_REQUEST_TOKEN environment variables.
+func GitHubActionsToken(ctx context.Context, client *http.Client, getenv func(string) string) (string, error) {
+	requestURL, requestToken := getenv("ACTIONS_ID_TOKEN_REQUEST_URL"), getenv("ACTIONS_ID_TOKEN_REQUEST_TOKEN")
+	if requestURL == "" || requestToken == "" {
+		return "", fmt.Errorf("no GitHub Actions OIDC token: ACTIONS_ID_TOKEN_REQUEST_URL is not set; run in a GitHub Actions job with the id-token: write permission")
+	}
+	u, err := url.Parse(requestURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid ACTIONS_ID_TOKEN_REQUEST_URL: %w", err)
+	}
+	query := u.Query()
+	query.Set("audience", FederatedAudience)
+	u.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), http.NoBody)
+	if err != nil {
+		return "", fmt.Errorf("failed to create GitHub Actions OIDC token request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+requestToken)
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to request GitHub Actions OIDC token: %w", err)
+	}
+	defer func() {
+		if closeErr := resp.Body.Close(); closeErr != nil {
+			_ = closeErr
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("GitHub Actions OIDC token request returned status %d", resp.StatusCode)
+	}
+	var token struct {
+		Value string `json:"value"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxDiscoveryBytes)).Decode(&token); err != nil {
+		return "", fmt.Errorf("invalid GitHub Actions OIDC token response: %w", err)
+	}
+	if token.Value == "" {
+		return "", fmt.Errorf("GitHub Actions returned an empty OIDC token")
+	}
+	return token.Value, nil
+}
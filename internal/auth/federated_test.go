@@ -0,0 +1,98 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestGitHubActionsToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer request-token" || r.URL.Query().Get("api-version") != "2.0" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		fmt.Fprintf(w, `{"count":1,"value":"oidc-token-for-%s"}`, r.URL.Query().Get("audience"))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name      string
+		env       map[string]string
+		expected  string
+		expectErr string
+	}{
+		{
+			name:     "issued",
+			env:      map[string]string{"ACTIONS_ID_TOKEN_REQUEST_URL": server.URL + "/token?api-version=2.0", "ACTIONS_ID_TOKEN_REQUEST_TOKEN": "request-token"},
+			expected: "oidc-token-for-" + FederatedAudience,
+		},
+		{
+			name:      "without id-token permission",
+			expectErr: "ACTIONS_ID_TOKEN_REQUEST_URL is not set",
+		},
+		{
+			name:      "rejected",
+			env:       map[string]string{"ACTIONS_ID_TOKEN_REQUEST_URL": server.URL + "/token?api-version=2.0", "ACTIONS_ID_TOKEN_REQUEST_TOKEN": "expired"},
+			expectErr: "returned status 403",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getenv := func(name string) string { return tt.env[name] }
+
+			token, err := GitHubActionsToken(context.Background(), server.Client(), getenv)
+			if tt.expectErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectErr) {
+					t.Fatalf("Expected error containing %q, got %v", tt.expectErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if token != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, token)
+			}
+		})
+	}
+}
+
+// githubTransport answers GitHub's OIDC token requests and Entra ID's token
+// requests, recording the hosts it was sent requests for
+type githubTransport struct {
+	hosts []string
+}
+
+func (t *githubTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.hosts = append(t.hosts, req.URL.Host)
+	if req.URL.Host == "token.actions.example.com" {
+		body := `{"count":1,"value":"oidc-token"}`
+		return &http.Response{StatusCode: http.StatusOK, Header: http.Header{"Content-Type": {"application/json"}}, Body: io.NopCloser(strings.NewReader(body)), Request: req}, nil
+	}
+	return fakeEntraID{}.Do(req)
+}
+
+func TestGitHubActionsTokenProviderWithClient(t *testing.T) {
+	t.Setenv("ACTIONS_ID_TOKEN_REQUEST_URL", "https://token.actions.example.com/token")
+	t.Setenv("ACTIONS_ID_TOKEN_REQUEST_TOKEN", "request-token")
+	transport := &githubTransport{}
+	provider := NewGitHubActionsTokenProviderWithClient(&http.Client{Transport: transport})
+
+	token, err := provider.GetAccessToken(context.Background(), "client", "", "tenant", "api://orders/.default")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if token != "token" {
+		t.Errorf("Expected the exchanged token, got %q", token)
+	}
+	if !slices.Contains(transport.hosts, "token.actions.example.com") || !slices.Contains(transport.hosts, "login.microsoftonline.com") {
+		t.Errorf("Expected both the OIDC token and the exchange to be requested through the client, got requests for %v", transport.hosts)
+	}
+}
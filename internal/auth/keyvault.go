@@ -0,0 +1,116 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/security/keyvault/azsecrets"
+)
+
+// pkcs12ContentType is the content type of a Key Vault certificate's secret
+// that holds a base64-encoded PFX rather than PEM
+const pkcs12ContentType = "application/x-pkcs12"
+
+// KeyVaultCertificate is a certificate in Azure Key Vault whose private key
+// signs the client assertions of certificate authentication
+type KeyVaultCertificate struct {
+	// Vault is the name of the vault, or its URL
+	Vault string
+	// Name is the name of the certificate in the vault
+	Name string
+}
+
+// VaultURL returns the URL of the vault: Vault itself when it is a URL, or
+// the URL of the vault of that name in the public cloud
+func (c KeyVaultCertificate) VaultURL() string {
+	if strings.Contains(c.Vault, "://") {
+		return c.Vault
+	}
+	return "https://" + c.Vault + ".vault.azure.net/"
+}
+
+// keyVaultCertificateKey is the context key of the Key Vault certificate to
+// authenticate with
+type keyVaultCertificateKey struct{}
+
+// WithKeyVaultCertificate returns a context in which an EntraIDTokenProvider
+// authenticates with certificate, pulled from Key Vault, instead of the
+// client secret passed to it. A certificate with an empty name keeps the
+// client secret.
+func WithKeyVaultCertificate(ctx context.Context, certificate KeyVaultCertificate) context.Context {
+	return context.WithValue(ctx, keyVaultCertificateKey{}, certificate)
+}
+
+// Certificate returns the Key Vault certificate of ctx, or the zero
+// KeyVaultCertificate when it names none
+func Certificate(ctx context.Context) KeyVaultCertificate {
+	certificate, _ := ctx.Value(keyVaultCertificateKey{}).(KeyVaultCertificate)
+	return certificate
+}
+
+// clientCertificate is a certificate chain and the private key of its leaf
+type clientCertificate struct {
+	certs []*x509.Certificate
+	key   crypto.PrivateKey
+}
+
+// loadCertificate returns the certificate and private key of certificate,
+// fetched from its vault on first use. A Key Vault certificate's private key
+// is only exported through the secret of the same name, which the vault
+// credential needs the "Get" secret permission, or the Key Vault Secrets
+// User role, to read.
+func (p *EntraIDTokenProvider) loadCertificate(ctx context.Context, certificate KeyVaultCertificate) (clientCertificate, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if loaded, ok := p.certificates[certificate]; ok {
+		return loaded, nil
+	}
+	if p.vaultCredential == nil {
+		credential, err := azidentity.NewDefaultAzureCredential(&azidentity.DefaultAzureCredentialOptions{
+			ClientOptions: azcore.ClientOptions{Transport: p.transport},
+		})
+		if err != nil {
+			return clientCertificate{}, fmt.Errorf("failed to create Key Vault credential: %w", err)
+		}
+		p.vaultCredential = credential
+	}
+
+	client, err := azsecrets.NewClient(certificate.VaultURL(), p.vaultCredential, &azsecrets.ClientOptions{
+		ClientOptions: azcore.ClientOptions{Transport: p.transport},
+	})
+	if err != nil {
+		return clientCertificate{}, fmt.Errorf("failed to create Key Vault client for %s: %w", certificate.VaultURL(), err)
+	}
+	secret, err := client.GetSecret(ctx, certificate.Name, "", nil)
+	if err != nil {
+		return clientCertificate{}, fmt.Errorf("failed to get certificate %q from Key Vault %s: %w", certificate.Name, certificate.VaultURL(), err)
+	}
+	if secret.Value == nil {
+		return clientCertificate{}, fmt.Errorf("certificate %q in Key Vault %s has no private key", certificate.Name, certificate.VaultURL())
+	}
+
+	data := []byte(*secret.Value)
+	if secret.ContentType != nil && *secret.ContentType == pkcs12ContentType {
+		if data, err = base64.StdEncoding.DecodeString(*secret.Value); err != nil {
+			return clientCertificate{}, fmt.Errorf("failed to decode certificate %q from Key Vault %s: %w", certificate.Name, certificate.VaultURL(), err)
+		}
+	}
+	certs, key, err := azidentity.ParseCertificates(data, nil)
+	if err != nil {
+		return clientCertificate{}, fmt.Errorf("failed to parse certificate %q from Key Vault %s: %w", certificate.Name, certificate.VaultURL(), err)
+	}
+
+	loaded := clientCertificate{certs: certs, key: key}
+	if p.certificates == nil {
+		p.certificates = map[KeyVaultCertificate]clientCertificate{}
+	}
+	p.certificates[certificate] = loaded
+	return loaded, nil
+}
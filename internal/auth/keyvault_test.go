@@ -0,0 +1,184 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+)
+
+// testCertificatePEM returns a self-signed certificate and its private key
+// in PEM, as Key Vault exports a certificate with a PEM content type
+func testCertificatePEM(t *testing.T) string {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "api-tester"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})) +
+		string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+}
+
+// fakeKeyVault answers the secret requests of a Key Vault client like Key
+// Vault does, and the token requests of the certificate credential like
+// Entra ID does, recording the requests it received
+type fakeKeyVault struct {
+	certificate string
+
+	mu            sync.Mutex
+	vaultRequests int
+	assertions    []string
+}
+
+func (f *fakeKeyVault) Do(req *http.Request) (*http.Response, error) {
+	if !strings.HasSuffix(req.URL.Host, ".vault.azure.net") {
+		if strings.HasSuffix(req.URL.Path, "/oauth2/v2.0/token") {
+			body, err := io.ReadAll(req.Body)
+			if err != nil {
+				return nil, err
+			}
+			form, err := url.ParseQuery(string(body))
+			if err != nil {
+				return nil, err
+			}
+			f.mu.Lock()
+			f.assertions = append(f.assertions, form.Get("client_assertion"))
+			f.mu.Unlock()
+		}
+		return fakeEntraID{}.Do(req)
+	}
+
+	f.mu.Lock()
+	f.vaultRequests++
+	f.mu.Unlock()
+	header := http.Header{"Content-Type": {"application/json"}}
+	if req.Header.Get("Authorization") == "" {
+		header.Set("WWW-Authenticate", `Bearer authorization="https://login.microsoftonline.com/tenant", resource="https://vault.azure.net"`)
+		return &http.Response{StatusCode: http.StatusUnauthorized, Header: header, Body: io.NopCloser(strings.NewReader(`{}`)), Request: req}, nil
+	}
+	if req.URL.Path != "/secrets/signing/" && req.URL.Path != "/secrets/signing" {
+		return &http.Response{StatusCode: http.StatusNotFound, Header: header, Body: io.NopCloser(strings.NewReader(`{"error":{"code":"SecretNotFound"}}`)), Request: req}, nil
+	}
+	body, err := json.Marshal(map[string]string{
+		"id":          "https://" + req.URL.Host + "/secrets/signing/1",
+		"value":       f.certificate,
+		"contentType": "application/x-pem-file",
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &http.Response{StatusCode: http.StatusOK, Header: header, Body: io.NopCloser(strings.NewReader(string(body))), Request: req}, nil
+}
+
+// staticCredential is a vault credential that always returns the same token
+type staticCredential struct{}
+
+func (staticCredential) GetToken(context.Context, policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	return azcore.AccessToken{Token: "vault-token", ExpiresOn: time.Now().Add(time.Hour)}, nil
+}
+
+func TestKeyVaultCertificateVaultURL(t *testing.T) {
+	tests := []struct {
+		vault    string
+		expected string
+	}{
+		{"signing-vault", "https://signing-vault.vault.azure.net/"},
+		{"https://signing-vault.vault.azure.net/", "https://signing-vault.vault.azure.net/"},
+		{"https://signing-vault.vault.azure.cn", "https://signing-vault.vault.azure.cn"},
+	}
+
+	for _, tt := range tests {
+		if got := (KeyVaultCertificate{Vault: tt.vault, Name: "signing"}).VaultURL(); got != tt.expected {
+			t.Errorf("VaultURL() of %q = %q, expected %q", tt.vault, got, tt.expected)
+		}
+	}
+}
+
+func TestGetAccessToken_KeyVaultCertificate(t *testing.T) {
+	tests := []struct {
+		name   string
+		region string
+	}{
+		{name: "global"},
+		{name: "regional", region: "westeurope"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(RegionEnv, "")
+			transport := &fakeKeyVault{certificate: testCertificatePEM(t)}
+			provider := NewEntraIDTokenProviderWithTransport(10*time.Second, transport)
+			provider.vaultCredential = staticCredential{}
+			ctx := WithKeyVaultCertificate(context.Background(), KeyVaultCertificate{Vault: "signing-vault", Name: "signing"})
+			if tt.region != "" {
+				ctx = WithRegion(ctx, tt.region)
+			}
+
+			for range 2 {
+				token, err := provider.GetAccessToken(ctx, "client", "", "tenant", "api://orders/.default")
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				if token != "token" {
+					t.Errorf("Expected the token, got %q", token)
+				}
+			}
+
+			// The challenge and the secret, fetched once for both tokens
+			if transport.vaultRequests != 2 {
+				t.Errorf("Expected the certificate to be fetched from Key Vault once, got %d requests", transport.vaultRequests)
+			}
+			if len(transport.assertions) == 0 {
+				t.Fatal("Expected a token request")
+			}
+			for _, assertion := range transport.assertions {
+				if strings.Count(assertion, ".") != 2 {
+					t.Errorf("Expected the token request to carry a signed client assertion, got %q", assertion)
+				}
+			}
+		})
+	}
+}
+
+func TestGetAccessToken_KeyVaultCertificateNotFound(t *testing.T) {
+	transport := &fakeKeyVault{certificate: testCertificatePEM(t)}
+	provider := NewEntraIDTokenProviderWithTransport(10*time.Second, transport)
+	provider.vaultCredential = staticCredential{}
+	ctx := WithKeyVaultCertificate(context.Background(), KeyVaultCertificate{Vault: "signing-vault", Name: "missing"})
+
+	_, err := provider.GetAccessToken(ctx, "client", "", "tenant", "api://orders/.default")
+	if err == nil || !strings.Contains(err.Error(), `failed to get certificate "missing" from Key Vault https://signing-vault.vault.azure.net/`) {
+		t.Errorf("Expected the Key Vault error, got %v", err)
+	}
+	if len(transport.assertions) != 0 {
+		t.Error("Expected no token request without the certificate")
+	}
+}
@@ -0,0 +1,116 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/AzureAD/microsoft-authentication-library-for-go/apps/managedidentity"
+)
+
+// ManagedIdentityTokenProvider acquires tokens for the managed identity of
+// the host the tester runs on, from whichever managed identity endpoint the
+// host offers: IMDS on Azure VMs and scale sets, the Arc agent on
+// Arc-enabled servers, or the local endpoint of App Service, Functions,
+// Cloud Shell, Service Fabric or Azure Machine Learning. The client ID
+// passed to GetAccessToken selects a user-assigned identity, and empty the
+// system-assigned one; the client secret and tenant are ignored.
+type ManagedIdentityTokenProvider struct {
+	// transport sends requests to the managed identity endpoint; nil uses
+	// the SDK default
+	transport policy.Transporter
+	timeout   time.Duration
+
+	mu sync.Mutex
+	// credentials are kept by client ID, as each caches its tokens
+	credentials map[string]*azidentity.ManagedIdentityCredential
+}
+
+// NewManagedIdentityTokenProvider creates a ManagedIdentityTokenProvider
+// with the default timeout
+func NewManagedIdentityTokenProvider() *ManagedIdentityTokenProvider {
+	return &ManagedIdentityTokenProvider{
+		timeout:     30 * time.Second,
+		credentials: map[string]*azidentity.ManagedIdentityCredential{},
+	}
+}
+
+// NewManagedIdentityTokenProviderWithTransport creates a
+// ManagedIdentityTokenProvider that reaches the managed identity endpoint
+// through the given transport, e.g. an *http.Client configured with a proxy
+func NewManagedIdentityTokenProviderWithTransport(timeout time.Duration, transport policy.Transporter) *ManagedIdentityTokenProvider {
+	return &ManagedIdentityTokenProvider{
+		transport:   transport,
+		timeout:     timeout,
+		credentials: map[string]*azidentity.ManagedIdentityCredential{},
+	}
+}
+
+// GetAccessToken acquires a token for scope as the managed identity
+func (p *ManagedIdentityTokenProvider) GetAccessToken(ctx context.Context, clientID, clientSecret, tenantID, scope string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	credential, err := p.credential(clientID)
+	if err != nil {
+		return "", fmt.Errorf("failed to create managed identity credential: %w", err)
+	}
+	token, err := credential.GetToken(ctx, policy.TokenRequestOptions{Scopes: []string{scope}})
+	if err != nil {
+		return "", fmt.Errorf("failed to acquire token from the %s managed identity endpoint: %w", ManagedIdentitySource(), err)
+	}
+	if token.Token == "" {
+		return "", fmt.Errorf("received empty token")
+	}
+	return token.Token, nil
+}
+
+// credential returns the credential of the identity with clientID
+func (p *ManagedIdentityTokenProvider) credential(clientID string) (*azidentity.ManagedIdentityCredential, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if credential, ok := p.credentials[clientID]; ok {
+		return credential, nil
+	}
+	options := &azidentity.ManagedIdentityCredentialOptions{
+		ClientOptions: azcore.ClientOptions{Transport: p.transport},
+	}
+	if clientID != "" {
+		options.ID = azidentity.ClientID(clientID)
+	}
+	credential, err := azidentity.NewManagedIdentityCredential(options)
+	if err != nil {
+		return nil, err
+	}
+	p.credentials[clientID] = credential
+	return credential, nil
+}
+
+// ManagedIdentitySource names the managed identity endpoint detected on
+// this host from its environment: "Azure Arc", "App Service" (which
+// includes Functions), "Cloud Shell", "Service Fabric", "Azure ML", or
+// "IMDS" on Azure VMs and wherever no other endpoint is detected
+func ManagedIdentitySource() string {
+	source, err := managedidentity.GetSource()
+	if err != nil {
+		return "IMDS"
+	}
+	switch source {
+	case managedidentity.AzureArc:
+		return "Azure Arc"
+	case managedidentity.AppService:
+		return "App Service"
+	case managedidentity.CloudShell:
+		return "Cloud Shell"
+	case managedidentity.ServiceFabric:
+		return "Service Fabric"
+	case managedidentity.AzureML:
+		return "Azure ML"
+	}
+	return "IMDS"
+}
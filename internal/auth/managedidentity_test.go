@@ -0,0 +1,86 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestManagedIdentitySource(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      map[string]string
+		expected string
+	}{
+		{"Arc", map[string]string{"IDENTITY_ENDPOINT": "http://localhost:40342/metadata/identity/oauth2/token", "IMDS_ENDPOINT": "http://localhost:40342"}, "Azure Arc"},
+		{"App Service", map[string]string{"IDENTITY_ENDPOINT": "http://127.0.0.1:41741/msi/token", "IDENTITY_HEADER": "header"}, "App Service"},
+		{"Cloud Shell", map[string]string{"MSI_ENDPOINT": "http://localhost:50342/oauth2/token"}, "Cloud Shell"},
+		{"VM", nil, "IMDS"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, name := range []string{"IDENTITY_ENDPOINT", "IDENTITY_HEADER", "IDENTITY_SERVER_THUMBPRINT", "IMDS_ENDPOINT", "MSI_ENDPOINT", "MSI_SECRET"} {
+				t.Setenv(name, tt.env[name])
+			}
+			if got := ManagedIdentitySource(); got != tt.expected {
+				t.Errorf("ManagedIdentitySource() = %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestManagedIdentityTokenProvider_AppService(t *testing.T) {
+	var resource, clientID string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-IDENTITY-HEADER") != "header" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		resource, clientID = r.URL.Query().Get("resource"), r.URL.Query().Get("client_id")
+		fmt.Fprintf(w, `{"access_token":"mi-token","expires_on":"%d","resource":%q,"token_type":"Bearer"}`, time.Now().Add(time.Hour).Unix(), resource)
+	}))
+	defer server.Close()
+	t.Setenv("IDENTITY_ENDPOINT", server.URL)
+	t.Setenv("IDENTITY_HEADER", "header")
+
+	token, err := NewManagedIdentityTokenProvider().GetAccessToken(context.Background(), "user-assigned", "", "", "api://orders/.default")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if token != "mi-token" || resource != "api://orders" || clientID != "user-assigned" {
+		t.Errorf("Expected a token for api://orders as the user-assigned identity, got %q for %q as %q", token, resource, clientID)
+	}
+}
+
+// countingTransport counts the requests it sends
+type countingTransport struct {
+	transport http.RoundTripper
+	requests  int
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.requests++
+	return t.transport.RoundTrip(req)
+}
+
+func TestManagedIdentityTokenProviderWithTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"access_token":"mi-token","expires_on":"%d","resource":"api://orders","token_type":"Bearer"}`, time.Now().Add(time.Hour).Unix())
+	}))
+	defer server.Close()
+	t.Setenv("IDENTITY_ENDPOINT", server.URL)
+	t.Setenv("IDENTITY_HEADER", "header")
+	transport := &countingTransport{transport: server.Client().Transport}
+
+	provider := NewManagedIdentityTokenProviderWithTransport(10*time.Second, &http.Client{Transport: transport})
+	if _, err := provider.GetAccessToken(context.Background(), "", "", "", "api://orders/.default"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if transport.requests == 0 {
+		t.Error("Expected the token to be requested through the transport")
+	}
+}
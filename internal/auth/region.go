@@ -0,0 +1,148 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/AzureAD/microsoft-authentication-library-for-go/apps/confidential"
+)
+
+// RegionEnv is the environment variable the Azure Identity SDK reads the
+// Azure region of a regional token endpoint (ESTS-R) from, e.g. "westeurope",
+// or "TryAutoDetect" to detect the region the process runs in. It applies
+// to acquisitions whose context names no region of its own.
+const RegionEnv = "AZURE_REGIONAL_AUTHORITY_NAME"
+
+// regionalHostSuffixes are the host suffixes of regional token endpoints
+var regionalHostSuffixes = []string{".login.microsoft.com", ".r.login.microsoftonline.com"}
+
+// TokenServer identifies the Entra ID server that issued a token
+type TokenServer struct {
+	// Host is the token endpoint's host: login.microsoftonline.com, or
+	// <region>.login.microsoft.com for a regional one
+	Host string
+	// Server is the x-ms-ests-server header of the token response, which
+	// names the build and scale unit of the server
+	Server string
+}
+
+// Region returns the Azure region of a regional token endpoint, or "" for
+// the global one
+func (s TokenServer) Region() string {
+	for _, suffix := range regionalHostSuffixes {
+		if region, ok := strings.CutSuffix(s.Host, suffix); ok && region != "" && !strings.Contains(region, ".") {
+			return region
+		}
+	}
+	return ""
+}
+
+// regionKey is the context key of the region to acquire tokens in
+type regionKey struct{}
+
+// WithRegion returns a context in which an EntraIDTokenProvider acquires
+// tokens from the regional token endpoint (ESTS-R) of region, e.g.
+// "westeurope", or of the region the process runs in for "TryAutoDetect".
+// An empty region keeps the default of RegionEnv.
+func WithRegion(ctx context.Context, region string) context.Context {
+	return context.WithValue(ctx, regionKey{}, region)
+}
+
+// Region returns the region of ctx, or "" when it names none
+func Region(ctx context.Context) string {
+	region, _ := ctx.Value(regionKey{}).(string)
+	return region
+}
+
+// tokenServerKey is the context key of a token server recorder
+type tokenServerKey struct{}
+
+// WithTokenServer returns a context in which tokens an EntraIDTokenProvider
+// acquires from Entra ID, rather than a cache, report the server that
+// issued them to record, like httptrace.WithClientTrace does for
+// connections
+func WithTokenServer(ctx context.Context, record func(TokenServer)) context.Context {
+	return context.WithValue(ctx, tokenServerKey{}, record)
+}
+
+// tokenServerRecorder returns the token server recorder of ctx, if any
+func tokenServerRecorder(ctx context.Context) func(TokenServer) {
+	record, _ := ctx.Value(tokenServerKey{}).(func(TokenServer))
+	return record
+}
+
+// tokenServerTransport reports the server of each token response it
+// receives to record
+type tokenServerTransport struct {
+	transport policy.Transporter
+	record    func(TokenServer)
+}
+
+// Do sends the request and records the server of a token response
+func (t tokenServerTransport) Do(req *http.Request) (*http.Response, error) {
+	resp, err := t.transport.Do(req)
+	if err == nil && strings.HasSuffix(req.URL.Path, "/oauth2/v2.0/token") {
+		t.record(TokenServer{Host: req.URL.Host, Server: resp.Header.Get("x-ms-ests-server")})
+	}
+	return resp, err
+}
+
+// transporterClient adapts a policy.Transporter to the HTTP client MSAL
+// sends its requests with
+type transporterClient struct {
+	policy.Transporter
+}
+
+// CloseIdleConnections closes the idle connections of the transport, if it
+// keeps any
+func (c transporterClient) CloseIdleConnections() {
+	if closer, ok := c.Transporter.(interface{ CloseIdleConnections() }); ok {
+		closer.CloseIdleConnections()
+	}
+}
+
+// getRegionalToken acquires a token from the regional token endpoint of
+// region, with certificate, if any, or else the client secret. The Azure
+// Identity SDK only reads the region from RegionEnv, for the whole process,
+// so a region of its own is requested from MSAL directly.
+func (p *EntraIDTokenProvider) getRegionalToken(ctx context.Context, clientID, clientSecret string, certificate *clientCertificate, tenantID, region string, options policy.TokenRequestOptions) (string, error) {
+	var credential confidential.Credential
+	var err error
+	if certificate != nil {
+		credential, err = confidential.NewCredFromCert(certificate.certs, certificate.key)
+	} else {
+		credential, err = confidential.NewCredFromSecret(clientSecret)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to create credential: %w", err)
+	}
+	clientOptions := []confidential.Option{confidential.WithAzureRegion(region)}
+	if transport := p.tokenTransport(ctx); transport != nil {
+		clientOptions = append(clientOptions, confidential.WithHTTPClient(transporterClient{transport}))
+	}
+	if options.EnableCAE {
+		clientOptions = append(clientOptions, confidential.WithClientCapabilities([]string{"cp1"}))
+	}
+	authority := strings.TrimSuffix(cloud.AzurePublic.ActiveDirectoryAuthorityHost, "/") + "/" + tenantID
+	client, err := confidential.New(authority, clientID, credential, clientOptions...)
+	if err != nil {
+		return "", fmt.Errorf("failed to create credential: %w", err)
+	}
+
+	var acquireOptions []confidential.AcquireByCredentialOption
+	if options.Claims != "" {
+		acquireOptions = append(acquireOptions, confidential.WithClaims(options.Claims))
+	}
+	result, err := client.AcquireTokenByCredential(ctx, options.Scopes, acquireOptions...)
+	if err != nil {
+		return "", fmt.Errorf("failed to acquire token: %w", err)
+	}
+	if result.AccessToken == "" {
+		return "", fmt.Errorf("received empty token")
+	}
+	return result.AccessToken, nil
+}
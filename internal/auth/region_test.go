@@ -0,0 +1,102 @@
+package auth
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTokenServerRegion(t *testing.T) {
+	tests := []struct {
+		host     string
+		expected string
+	}{
+		{"login.microsoftonline.com", ""},
+		{"login.microsoft.com", ""},
+		{"westeurope.login.microsoft.com", "westeurope"},
+		{"centralus.r.login.microsoftonline.com", "centralus"},
+	}
+
+	for _, tt := range tests {
+		if got := (TokenServer{Host: tt.host}).Region(); got != tt.expected {
+			t.Errorf("Region() of %s = %q, expected %q", tt.host, got, tt.expected)
+		}
+	}
+}
+
+// fakeEntraID answers the requests of a client credentials token
+// acquisition like the Entra ID host they are sent to does
+type fakeEntraID struct{}
+
+func (fakeEntraID) Do(req *http.Request) (*http.Response, error) {
+	authority := "https://" + req.URL.Host + "/tenant"
+	var body string
+	header := http.Header{"Content-Type": {"application/json"}}
+	switch {
+	case strings.Contains(req.URL.Path, "/discovery/instance"):
+		body = `{"tenant_discovery_endpoint":"` + authority + `/v2.0/.well-known/openid-configuration","api-version":"1.1","metadata":[]}`
+	case strings.HasSuffix(req.URL.Path, "/openid-configuration"):
+		body = `{"token_endpoint":"` + authority + `/oauth2/v2.0/token","authorization_endpoint":"` + authority + `/oauth2/v2.0/authorize","issuer":"https://login.microsoftonline.com/tenant/v2.0"}`
+	case strings.HasSuffix(req.URL.Path, "/oauth2/v2.0/token"):
+		header.Set("x-ms-ests-server", "2.1.19563.6 - WEULR1 ProdSlices")
+		body = `{"token_type":"Bearer","expires_in":3600,"access_token":"token"}`
+	default:
+		return &http.Response{StatusCode: http.StatusNotFound, Header: header, Body: io.NopCloser(strings.NewReader(`{}`)), Request: req}, nil
+	}
+	return &http.Response{StatusCode: http.StatusOK, Header: header, Body: io.NopCloser(strings.NewReader(body)), Request: req}, nil
+}
+
+func TestGetAccessToken_TokenServer(t *testing.T) {
+	tests := []struct {
+		name      string
+		envRegion string
+		region    string
+		host      string
+	}{
+		{name: "global", host: "login.microsoftonline.com"},
+		{name: "environment region", envRegion: "westeurope", host: "westeurope.login.microsoft.com"},
+		{name: "credential region", region: "northeurope", host: "northeurope.login.microsoft.com"},
+		{name: "credential region over environment", envRegion: "westeurope", region: "northeurope", host: "northeurope.login.microsoft.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(RegionEnv, tt.envRegion)
+			provider := NewEntraIDTokenProviderWithTransport(10*time.Second, fakeEntraID{})
+			var servers []TokenServer
+			ctx := WithTokenServer(context.Background(), func(server TokenServer) { servers = append(servers, server) })
+			if tt.region != "" {
+				ctx = WithRegion(ctx, tt.region)
+			}
+
+			token, err := provider.GetAccessToken(ctx, "client", "secret", "tenant", "api://orders/.default")
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if token != "token" {
+				t.Errorf("Expected the token, got %q", token)
+			}
+			if len(servers) != 1 || servers[0].Host != tt.host || servers[0].Server != "2.1.19563.6 - WEULR1 ProdSlices" {
+				t.Errorf("Expected the token server %s to be recorded, got %+v", tt.host, servers)
+			}
+		})
+	}
+}
+
+func TestGetAccessToken_RegionalTransport(t *testing.T) {
+	t.Setenv(RegionEnv, "")
+	provider := NewEntraIDTokenProviderWithTransport(10*time.Second, fakeEntraID{})
+
+	// Without a token server recorder, the regional endpoint is still
+	// reached through the provider's transport
+	token, err := provider.GetAccessToken(WithRegion(context.Background(), "westeurope"), "client", "secret", "tenant", "api://orders/.default")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if token != "token" {
+		t.Errorf("Expected the token, got %q", token)
+	}
+}
@@ -0,0 +1,85 @@
+package auth
+
+import (
+	"context"
+	"sync"
+)
+
+// tokenKey identifies the token a credential acquires for a scope
+type tokenKey struct {
+	clientID     string
+	clientSecret string
+	tenantID     string
+	scope        string
+	region       string
+	certificate  KeyVaultCertificate
+}
+
+// tokenCall is an acquisition in flight, shared by every caller that asked
+// for the same token while it ran
+type tokenCall struct {
+	done  chan struct{}
+	token string
+	err   error
+}
+
+// SingleflightTokenProvider coalesces concurrent acquisitions of the same
+// token into a single request to Entra ID, so that concurrent runs sharing a
+// credential, e.g. the schedule groups of the daemon, do not each hit the
+// token endpoint and get throttled. Acquisitions that do not overlap are
+// passed through as they are.
+type SingleflightTokenProvider struct {
+	provider ClaimsTokenProvider
+	calls    map[tokenKey]*tokenCall
+	mu       sync.Mutex
+}
+
+// NewSingleflightTokenProvider wraps provider so that concurrent
+// acquisitions of the same token share one request
+func NewSingleflightTokenProvider(provider ClaimsTokenProvider) *SingleflightTokenProvider {
+	return &SingleflightTokenProvider{
+		provider: provider,
+		calls:    make(map[tokenKey]*tokenCall),
+	}
+}
+
+// GetAccessToken acquires a token, or waits for an acquisition of the same
+// token already in flight and returns its outcome. A caller whose ctx is
+// cancelled stops waiting without cancelling the acquisition for the others.
+func (p *SingleflightTokenProvider) GetAccessToken(ctx context.Context, clientID, clientSecret, tenantID, scope string) (string, error) {
+	call := p.join(ctx, tokenKey{clientID: clientID, clientSecret: clientSecret, tenantID: tenantID, scope: scope, region: Region(ctx), certificate: Certificate(ctx)})
+	select {
+	case <-call.done:
+		return call.token, call.err
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// GetAccessTokenWithClaims acquires a token that satisfies a claims
+// challenge. It is never shared: each challenge needs a fresh token.
+func (p *SingleflightTokenProvider) GetAccessTokenWithClaims(ctx context.Context, clientID, clientSecret, tenantID, scope, claims string) (string, error) {
+	return p.provider.GetAccessTokenWithClaims(ctx, clientID, clientSecret, tenantID, scope, claims)
+}
+
+// join returns the acquisition of the token in flight, starting one when
+// there is none. The acquisition runs detached from ctx's cancellation, so
+// that the first caller giving up does not fail the others.
+func (p *SingleflightTokenProvider) join(ctx context.Context, key tokenKey) *tokenCall {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if call, ok := p.calls[key]; ok {
+		return call
+	}
+
+	call := &tokenCall{done: make(chan struct{})}
+	p.calls[key] = call
+	go func() {
+		call.token, call.err = p.provider.GetAccessToken(context.WithoutCancel(ctx), key.clientID, key.clientSecret, key.tenantID, key.scope)
+		p.mu.Lock()
+		delete(p.calls, key)
+		p.mu.Unlock()
+		close(call.done)
+	}()
+	return call
+}
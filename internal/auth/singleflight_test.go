@@ -0,0 +1,120 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingProvider acquires tokens named after their scope, blocking each
+// acquisition until release is closed
+type countingProvider struct {
+	release chan struct{}
+	calls   atomic.Int32
+	claims  atomic.Int32
+}
+
+func (p *countingProvider) GetAccessToken(ctx context.Context, clientID, clientSecret, tenantID, scope string) (string, error) {
+	p.calls.Add(1)
+	<-p.release
+	if scope == "fail" {
+		return "", errors.New("AADSTS7000215: invalid client secret")
+	}
+	return "token-" + scope, nil
+}
+
+func (p *countingProvider) GetAccessTokenWithClaims(ctx context.Context, clientID, clientSecret, tenantID, scope, claims string) (string, error) {
+	p.claims.Add(1)
+	return "claims-token", nil
+}
+
+func TestSingleflightTokenProvider(t *testing.T) {
+	inner := &countingProvider{release: make(chan struct{})}
+	provider := NewSingleflightTokenProvider(inner)
+
+	const callers = 10
+	var wg sync.WaitGroup
+	tokens := make([]string, callers)
+	errs := make([]error, callers)
+	for i := range callers {
+		scope := "orders"
+		if i%2 == 1 {
+			scope = "fail"
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tokens[i], errs[i] = provider.GetAccessToken(context.Background(), "client-id", "secret", "tenant", scope)
+		}()
+	}
+	// Let every caller join before the acquisitions complete
+	for provider.waiting() < 2 {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(inner.release)
+	wg.Wait()
+
+	if got := inner.calls.Load(); got != 2 {
+		t.Errorf("Expected one acquisition per distinct token, got %d", got)
+	}
+	for i := range callers {
+		if i%2 == 0 && (tokens[i] != "token-orders" || errs[i] != nil) {
+			t.Errorf("Caller %d: expected the shared token, got %q (%v)", i, tokens[i], errs[i])
+		}
+		if i%2 == 1 && errs[i] == nil {
+			t.Errorf("Caller %d: expected the shared error, got %q", i, tokens[i])
+		}
+	}
+
+	// Later acquisitions are not cached
+	if _, err := provider.GetAccessToken(context.Background(), "client-id", "secret", "tenant", "orders"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := inner.calls.Load(); got != 3 {
+		t.Errorf("Expected a new acquisition after the first completed, got %d in total", got)
+	}
+
+	if _, err := provider.GetAccessTokenWithClaims(context.Background(), "client-id", "secret", "tenant", "orders", `{"access_token":{}}`); err != nil || inner.claims.Load() != 1 {
+		t.Errorf("Expected claims acquisitions to be passed through, got %v", err)
+	}
+}
+
+func TestSingleflightTokenProvider_CallerCancelled(t *testing.T) {
+	inner := &countingProvider{release: make(chan struct{})}
+	provider := NewSingleflightTokenProvider(inner)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	firstErr := make(chan error, 1)
+	go func() {
+		_, err := provider.GetAccessToken(ctx, "client-id", "secret", "tenant", "orders")
+		firstErr <- err
+	}()
+	for provider.waiting() < 1 {
+		time.Sleep(time.Millisecond)
+	}
+	second := make(chan string, 1)
+	go func() {
+		token, _ := provider.GetAccessToken(context.Background(), "client-id", "secret", "tenant", "orders")
+		second <- token
+	}()
+
+	cancel()
+	if err := <-firstErr; !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the cancelled caller to stop waiting, got %v", err)
+	}
+	close(inner.release)
+	if token := <-second; token != "token-orders" {
+		t.Errorf("Expected the other caller to get the token, got %q", token)
+	}
+}
+
+// waiting returns the number of acquisitions in flight
+func (p *SingleflightTokenProvider) waiting() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.calls)
+}
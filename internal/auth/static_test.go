@@ -0,0 +1,35 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestStaticTokenProvider(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name      string
+		token     string
+		expectErr bool
+	}{
+		{"opaque token", "opaque-token", false},
+		{"valid JWT", makeToken(t, map[string]interface{}{"exp": now.Add(time.Hour).Unix()}), false},
+		{"JWT without exp", makeToken(t, map[string]interface{}{"aud": "api://orders"}), false},
+		{"expired JWT", makeToken(t, map[string]interface{}{"exp": now.Add(-time.Minute).Unix()}), true},
+		{"empty", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := &StaticTokenProvider{Token: tt.token}
+			token, err := provider.GetAccessToken(context.Background(), "client-id", "secret", "tenant", "scope")
+			if (err != nil) != tt.expectErr {
+				t.Fatalf("GetAccessToken() error = %v, expectErr %v", err, tt.expectErr)
+			}
+			if err == nil && token != tt.token {
+				t.Errorf("Expected the static token, got %q", token)
+			}
+		})
+	}
+}
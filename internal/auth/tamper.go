@@ -0,0 +1,53 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// TruncateSignature returns the token with the second half of its signature
+// cut off. An API that verifies signatures must reject it.
+func TruncateSignature(token string) (string, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 || len(parts[2]) < 2 {
+		return "", fmt.Errorf("token is not a signed JWT")
+	}
+	return parts[0] + "." + parts[1] + "." + parts[2][:len(parts[2])/2], nil
+}
+
+// Expire returns the token with its exp claim moved an hour before now (and
+// nbf and iat before that) under a random signature of the same length. An
+// API must reject it for its expiry, and for its signature.
+func Expire(token string, now time.Time) (string, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return "", fmt.Errorf("token is not a JWT")
+	}
+	claims, err := DecodePayload(token)
+	if err != nil {
+		return "", err
+	}
+
+	expired := now.Add(-time.Hour).Unix()
+	claims["exp"] = expired
+	claims["nbf"] = expired - int64(time.Hour/time.Second)
+	claims["iat"] = claims["nbf"]
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode token claims: %w", err)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[2], "="))
+	if err != nil || len(signature) == 0 {
+		signature = make([]byte, 256)
+	}
+	if _, err := rand.Read(signature); err != nil {
+		return "", fmt.Errorf("failed to generate signature: %w", err)
+	}
+
+	return parts[0] + "." + base64.RawURLEncoding.EncodeToString(payload) + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
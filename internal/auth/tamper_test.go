@@ -0,0 +1,54 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTruncateSignature(t *testing.T) {
+	token := makeToken(t, map[string]interface{}{"aud": "api://example"})
+
+	tampered, err := TruncateSignature(token)
+	if err != nil {
+		t.Fatalf("TruncateSignature() error = %v", err)
+	}
+	if !strings.HasSuffix(tampered, ".sign") || !strings.HasPrefix(tampered, strings.TrimSuffix(token, "signature")) {
+		t.Errorf("Expected the signature to be cut in half, got %s", tampered)
+	}
+
+	for _, invalid := range []string{"opaque-token", "header.payload."} {
+		if _, err := TruncateSignature(invalid); err == nil {
+			t.Errorf("Expected an error for %q", invalid)
+		}
+	}
+}
+
+func TestExpire(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	token := makeToken(t, map[string]interface{}{"aud": "api://example", "exp": now.Add(time.Hour).Unix()})
+
+	tampered, err := Expire(token, now)
+	if err != nil {
+		t.Fatalf("Expire() error = %v", err)
+	}
+
+	claims, err := ParseClaims(tampered)
+	if err != nil {
+		t.Fatalf("ParseClaims() error = %v", err)
+	}
+	if !claims.ExpiresAt.Equal(now.Add(-time.Hour)) || !claims.NotBefore.Before(claims.ExpiresAt) {
+		t.Errorf("Expected an expired token, got %+v", claims)
+	}
+	payload, err := DecodePayload(tampered)
+	if err != nil || payload["aud"] != "api://example" {
+		t.Errorf("Expected the other claims to be kept, got %v (%v)", payload, err)
+	}
+	if strings.Split(tampered, ".")[2] == strings.Split(token, ".")[2] {
+		t.Error("Expected a new signature")
+	}
+
+	if _, err := Expire("opaque-token", now); err == nil {
+		t.Error("Expected an error for a token that is not a JWT")
+	}
+}
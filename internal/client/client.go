@@ -1,6 +1,7 @@
 // Package client provides HTTP client functionality for making API requests
 // with Bearer token authentication. It supports all standard HTTP methods
-// (GET, POST, PUT, PATCH, DELETE) and includes response parsing utilities.
+// (GET, POST, PUT, PATCH, DELETE, HEAD, OPTIONS) as well as custom ones and
+// includes response parsing utilities.
 package client
 
 import (
@@ -10,7 +11,18 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
+	"unicode/utf8"
+)
+
+const (
+	// defaultRetryAfter is used when a 429 response has no usable Retry-After header
+	defaultRetryAfter = 1 * time.Second
+	// maxRetryAfter caps how long a single Retry-After is honored
+	maxRetryAfter = 60 * time.Second
 )
 
 // HTTPClient defines the interface for making HTTP requests
@@ -18,29 +30,64 @@ type HTTPClient interface {
 	Do(req *http.Request) (*http.Response, error)
 }
 
+// Exchange is a completed request/response round trip, as seen by a Recorder
+type Exchange struct {
+	Started     time.Time
+	Request     *http.Request
+	Response    *Response
+	RequestBody []byte
+	Duration    time.Duration
+}
+
+// Recorder receives every completed exchange, e.g. to write a HAR file.
+// Implementations must be safe for concurrent use.
+type Recorder interface {
+	Record(exchange *Exchange)
+}
+
+// multiRecorder notifies several recorders of every exchange
+type multiRecorder []Recorder
+
+// Record implements Recorder
+func (m multiRecorder) Record(exchange *Exchange) {
+	for _, recorder := range m {
+		recorder.Record(exchange)
+	}
+}
+
+// MultiRecorder returns a recorder that notifies each of recorders of
+// every exchange, e.g. to write a HAR file and dump failed endpoints
+func MultiRecorder(recorders ...Recorder) Recorder {
+	return multiRecorder(recorders)
+}
+
 // APIClient handles API requests with authentication
 type APIClient struct {
 	httpClient HTTPClient
-	timeout    time.Duration
+	recorder   Recorder
+	// transports caches HTTP clients per distinct TransportOptions
+	transports    map[string]HTTPClient
+	timeout       time.Duration
+	mu            sync.Mutex
+	ownsTransport bool
 }
 
 // NewAPIClient creates a new APIClient with default settings
 func NewAPIClient() *APIClient {
-	return &APIClient{
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
-		timeout: 30 * time.Second,
-	}
+	return NewAPIClientWithTimeout(30 * time.Second)
 }
 
 // NewAPIClientWithTimeout creates a new APIClient with custom timeout
 func NewAPIClientWithTimeout(timeout time.Duration) *APIClient {
+	httpClient := &http.Client{Timeout: timeout}
+	if transport, err := NewTransport(TransportOptions{}); err == nil {
+		httpClient.Transport = transport
+	}
 	return &APIClient{
-		httpClient: &http.Client{
-			Timeout: timeout,
-		},
-		timeout: timeout,
+		httpClient:    httpClient,
+		timeout:       timeout,
+		transports:    make(map[string]HTTPClient),
+		ownsTransport: true,
 	}
 }
 
@@ -52,45 +99,192 @@ func NewAPIClientWithHTTPClient(httpClient HTTPClient, timeout time.Duration) *A
 	}
 }
 
+// SetRecorder registers a recorder that is notified of every exchange
+func (c *APIClient) SetRecorder(recorder Recorder) {
+	c.recorder = recorder
+}
+
 // Response represents an API response
 type Response struct {
-	Headers    http.Header
+	Headers http.Header
+	// Proto is the protocol the response was received over, e.g. "HTTP/1.1"
+	Proto string
+	// URL is the URL the response was received from, after redirects
+	URL        string
 	Body       []byte
 	StatusCode int
+	// ThrottleRetries is the number of 429 responses that were retried
+	ThrottleRetries int
+	// ThrottleWait is the total time spent waiting on Retry-After
+	ThrottleWait time.Duration
+	// Duration is the round trip time of the final request
+	Duration time.Duration
+	// Redirects are the redirects followed to reach URL, in order
+	Redirects []Redirect
+	// TraceID is the trace ID of the traceparent header sent with the
+	// request, or "" when it was not traced
+	TraceID string
+	// Connection describes the connection the final request was sent over
+	Connection Connection
+	// Truncated reports that the body was cut off at the request's
+	// MaxBodyBytes
+	Truncated bool
+}
+
+// Request describes a single authenticated API call
+type Request struct {
+	Body map[string]interface{}
+	// RawBody is sent as is instead of Body, with ContentType as its
+	// Content-Type, e.g. a multipart form
+	RawBody     []byte
+	Headers     map[string]string
+	Method      string
+	URL         string
+	AccessToken string
+	ContentType string
+	// Transport selects connection settings such as a proxy
+	Transport *TransportOptions
+	// CookieJar, if set, supplies the request's cookies and stores those
+	// the response sets
+	CookieJar http.CookieJar
+	// MaxThrottleRetries is how many times a 429 response is retried after
+	// honoring its Retry-After header. Zero disables throttle retries.
+	MaxThrottleRetries int
+	// MaxBodyBytes caps how much of the response body is read; the rest is
+	// discarded and the response marked as truncated. Zero reads it all.
+	MaxBodyBytes int64
+	// MaxRedirects is how many redirects are followed before the request
+	// fails; zero means DefaultMaxRedirects
+	MaxRedirects int
+	// NoRedirects returns redirect responses instead of following them,
+	// e.g. to check the Location of a redirect to a SAS URL
+	NoRedirects bool
+	// Trace sends a W3C traceparent header with a new trace ID on every
+	// round trip, so that it can be found in the API's server-side logs
+	Trace bool
+}
+
+// sendsBody reports whether a request body is sent with method: it is for
+// POST, PUT, PATCH and custom methods, but not for GET, HEAD, OPTIONS and
+// DELETE
+func sendsBody(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodDelete:
+		return false
+	}
+	return true
 }
 
 // CallAPI makes an HTTP request to the specified endpoint
 func (c *APIClient) CallAPI(ctx context.Context, method, url, accessToken string, requestBody map[string]interface{}) (*Response, error) {
-	// Create context with timeout
-	ctx, cancel := context.WithTimeout(ctx, c.timeout)
-	defer cancel()
+	return c.Send(ctx, &Request{
+		Method:      method,
+		URL:         url,
+		AccessToken: accessToken,
+		Body:        requestBody,
+	})
+}
 
+// Send executes the given request and returns the response. Throttled (429)
+// responses are retried up to request.MaxThrottleRetries times.
+func (c *APIClient) Send(ctx context.Context, request *Request) (*Response, error) {
 	// Prepare request body
-	var bodyReader io.Reader
-	if requestBody != nil && (method == "POST" || method == "PUT" || method == "PATCH") {
-		jsonBody, err := json.Marshal(requestBody)
+	var body []byte
+	switch {
+	case !sendsBody(request.Method):
+	case request.RawBody != nil:
+		body = request.RawBody
+	case request.Body != nil:
+		var err error
+		body, err = json.Marshal(request.Body)
 		if err != nil {
 			return nil, fmt.Errorf("failed to marshal request body: %w", err)
 		}
-		bodyReader = bytes.NewReader(jsonBody)
+	}
+
+	var throttleWait time.Duration
+	for attempt := 0; ; attempt++ {
+		response, err := c.send(ctx, request, body)
+		if err != nil {
+			return nil, err
+		}
+
+		if response.StatusCode != http.StatusTooManyRequests || attempt >= request.MaxThrottleRetries {
+			response.ThrottleRetries = attempt
+			response.ThrottleWait = throttleWait
+			return response, nil
+		}
+
+		// Honor Retry-After before trying again
+		wait := RetryAfter(response.Headers.Get("Retry-After"), time.Now())
+		throttleWait += wait
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, fmt.Errorf("cancelled while throttled: %w", ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+// send performs a single HTTP round trip
+func (c *APIClient) send(ctx context.Context, request *Request, requestBody []byte) (*Response, error) {
+	// Create context with timeout
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	var bodyReader io.Reader
+	if requestBody != nil {
+		bodyReader = bytes.NewReader(requestBody)
 	}
 
 	// Create HTTP request
-	req, err := http.NewRequestWithContext(ctx, method, url, bodyReader)
+	req, err := http.NewRequestWithContext(ctx, request.Method, request.URL, bodyReader)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	// Set headers
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", accessToken))
-	if requestBody != nil {
+	// Set headers; a request without a token is sent without Authorization
+	if request.AccessToken != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", request.AccessToken))
+	}
+	switch {
+	case request.RawBody != nil:
+		if request.ContentType != "" {
+			req.Header.Set("Content-Type", request.ContentType)
+		}
+	case request.Body != nil:
 		req.Header.Set("Content-Type", "application/json")
 	}
+	for key, value := range request.Headers {
+		req.Header.Set(key, value)
+	}
+	var traceID string
+	if request.Trace {
+		var traceParent string
+		traceParent, traceID = newTraceParent()
+		req.Header.Set(TraceParentHeader, traceParent)
+	}
+
+	httpClient, err := c.clientFor(request.Transport)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure transport: %w", err)
+	}
+	var redirects []Redirect
+	httpClient = forRequest(httpClient, request, &redirects)
 
 	// Execute request
-	resp, err := c.httpClient.Do(req)
+	var connection Connection
+	req = req.WithContext(traceConnection(req.Context(), &connection))
+	started := time.Now()
+	resp, err := httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to execute request: %w", err)
+		err = fmt.Errorf("failed to execute request: %w", err)
+		if AddressFamily(connection.RemoteAddr) != "" {
+			err = &AddrError{Err: err, Addr: connection.RemoteAddr}
+		}
+		return nil, err
 	}
 	defer func() {
 		if closeErr := resp.Body.Close(); closeErr != nil {
@@ -99,17 +293,62 @@ func (c *APIClient) CallAPI(ctx context.Context, method, url, accessToken string
 		}
 	}()
 
-	// Read response body
-	body, err := io.ReadAll(resp.Body)
+	// Read response body, up to one byte past the limit to detect larger
+	// bodies without buffering them
+	var reader io.Reader = resp.Body
+	if request.MaxBodyBytes > 0 {
+		reader = io.LimitReader(resp.Body, request.MaxBodyBytes+1)
+	}
+	body, err := io.ReadAll(reader)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read response body: %w", err)
 	}
+	truncated := request.MaxBodyBytes > 0 && int64(len(body)) > request.MaxBodyBytes
+	if truncated {
+		body = body[:request.MaxBodyBytes]
+	}
 
-	return &Response{
+	response := &Response{
 		StatusCode: resp.StatusCode,
 		Body:       body,
 		Headers:    resp.Header,
-	}, nil
+		Proto:      resp.Proto,
+		Duration:   time.Since(started),
+		Redirects:  redirects,
+		TraceID:    traceID,
+		Connection: connection,
+		Truncated:  truncated,
+	}
+	if resp.Request != nil && resp.Request.URL != nil {
+		response.URL = resp.Request.URL.String()
+	}
+
+	if c.recorder != nil {
+		c.recorder.Record(&Exchange{
+			Started:     started,
+			Duration:    response.Duration,
+			Request:     req,
+			RequestBody: requestBody,
+			Response:    response,
+		})
+	}
+
+	return response, nil
+}
+
+// RetryAfter converts a Retry-After header (delay-seconds or HTTP-date) into a
+// wait duration, falling back to defaultRetryAfter and capping at maxRetryAfter
+func RetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	wait := defaultRetryAfter
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		wait = time.Duration(seconds) * time.Second
+	} else if when, err := http.ParseTime(value); err == nil {
+		wait = max(when.Sub(now), 0)
+	}
+
+	return min(wait, maxRetryAfter)
 }
 
 // IsSuccessStatusCode checks if the status code indicates success
@@ -122,6 +361,21 @@ func (r *Response) GetBodyAsString() string {
 	return string(r.Body)
 }
 
+// IsBinary reports whether the body is binary content, such as a PDF or a
+// zip archive, rather than text
+func (r *Response) IsBinary() bool {
+	return !utf8.Valid(r.Body) || bytes.IndexByte(r.Body, 0) >= 0
+}
+
+// DescribeBody returns the body as a string, or its size when it is binary
+// content that would garble logs
+func (r *Response) DescribeBody() string {
+	if r.IsBinary() {
+		return fmt.Sprintf("<%d bytes of binary content>", len(r.Body))
+	}
+	return string(r.Body)
+}
+
 // GetBodyAsJSON attempts to unmarshal the response body as JSON
 func (r *Response) GetBodyAsJSON(v interface{}) error {
 	if len(r.Body) == 0 {
@@ -129,3 +383,45 @@ func (r *Response) GetBodyAsJSON(v interface{}) error {
 	}
 	return json.Unmarshal(r.Body, v)
 }
+
+// DeprecationWarnings inspects the Deprecation (RFC 9745) and Sunset (RFC 8594)
+// response headers and returns human-readable warnings for each one present
+func (r *Response) DeprecationWarnings() []string {
+	var warnings []string
+
+	if value := strings.TrimSpace(r.Headers.Get("Deprecation")); value != "" {
+		if when, ok := parseHeaderDate(value); ok {
+			warnings = append(warnings, fmt.Sprintf("API is deprecated (since %s)", when.Format(time.DateOnly)))
+		} else {
+			warnings = append(warnings, "API is deprecated")
+		}
+	}
+
+	if value := strings.TrimSpace(r.Headers.Get("Sunset")); value != "" {
+		if when, ok := parseHeaderDate(value); ok {
+			warnings = append(warnings, fmt.Sprintf("API will be retired on %s", when.Format(time.DateOnly)))
+		} else {
+			warnings = append(warnings, fmt.Sprintf("API has a sunset date: %s", value))
+		}
+	}
+
+	return warnings
+}
+
+// parseHeaderDate parses either an HTTP-date or a structured field date
+// (e.g. "@1688169599") as used by the Deprecation header
+func parseHeaderDate(value string) (time.Time, bool) {
+	if unix, found := strings.CutPrefix(value, "@"); found {
+		seconds, err := strconv.ParseInt(unix, 10, 64)
+		if err != nil {
+			return time.Time{}, false
+		}
+		return time.Unix(seconds, 0).UTC(), true
+	}
+
+	when, err := http.ParseTime(value)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return when.UTC(), true
+}
@@ -123,35 +123,74 @@ func TestCallAPI_POST_WithBody(t *testing.T) {
 }
 
 func TestCallAPI_AllHTTPMethods(t *testing.T) {
-	methods := []string{"GET", "POST", "PUT", "PATCH", "DELETE"}
+	tests := []struct {
+		method   string
+		sendBody bool
+	}{
+		{"GET", false},
+		{"POST", true},
+		{"PUT", true},
+		{"PATCH", true},
+		{"DELETE", false},
+		{"HEAD", false},
+		{"OPTIONS", false},
+		{"QUERY", true},
+	}
 
-	for _, method := range methods {
-		t.Run(method, func(t *testing.T) {
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
 			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-				if r.Method != method {
-					t.Errorf("Expected %s method, got %s", method, r.Method)
+				if r.Method != tt.method {
+					t.Errorf("Expected %s method, got %s", tt.method, r.Method)
+				}
+				body, _ := io.ReadAll(r.Body)
+				if sent := len(body) > 0; sent != tt.sendBody {
+					t.Errorf("Expected body sent = %v for %s, got %q", tt.sendBody, tt.method, body)
 				}
+				w.Header().Set("Allow", "GET, HEAD, OPTIONS")
 				w.WriteHeader(http.StatusOK)
+				_, _ = w.Write([]byte(`{}`))
 			}))
 			defer server.Close()
 
 			client := NewAPIClient()
-			requestBody := map[string]interface{}{}
-			if method == "POST" || method == "PUT" || method == "PATCH" {
-				requestBody["test"] = "data"
-			}
+			requestBody := map[string]interface{}{"test": "data"}
 
-			resp, err := client.CallAPI(context.Background(), method, server.URL, "test-token", requestBody)
+			resp, err := client.CallAPI(context.Background(), tt.method, server.URL, "test-token", requestBody)
 			if err != nil {
-				t.Fatalf("Unexpected error for %s: %v", method, err)
+				t.Fatalf("Unexpected error for %s: %v", tt.method, err)
 			}
 			if resp.StatusCode != http.StatusOK {
-				t.Errorf("Expected status 200 for %s, got %d", method, resp.StatusCode)
+				t.Errorf("Expected status 200 for %s, got %d", tt.method, resp.StatusCode)
+			}
+			if tt.method == "HEAD" && len(resp.Body) != 0 {
+				t.Errorf("Expected no body for HEAD, got %q", resp.Body)
+			}
+			if resp.Headers.Get("Allow") != "GET, HEAD, OPTIONS" {
+				t.Errorf("Expected the Allow header for %s, got %q", tt.method, resp.Headers.Get("Allow"))
 			}
 		})
 	}
 }
 
+func TestCallAPI_WithoutToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := r.Header["Authorization"]; ok {
+			t.Errorf("Expected no Authorization header, got %q", r.Header.Get("Authorization"))
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	resp, err := NewAPIClient().CallAPI(context.Background(), "GET", server.URL, "", nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected status 401, got %d", resp.StatusCode)
+	}
+}
+
 func TestCallAPI_ErrorStatusCode(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusNotFound)
@@ -213,6 +252,32 @@ func TestResponse_GetBodyAsString(t *testing.T) {
 	}
 }
 
+func TestResponse_DescribeBody(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     []byte
+		expected string
+		binary   bool
+	}{
+		{name: "text", body: []byte(`{"name":"café"}`), expected: `{"name":"café"}`},
+		{name: "empty", body: nil, expected: ""},
+		{name: "invalid UTF-8", body: []byte{'P', 'K', 0x03, 0x04, 0xff}, expected: "<5 bytes of binary content>", binary: true},
+		{name: "NUL byte", body: []byte("%PDF\x00"), expected: "<5 bytes of binary content>", binary: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &Response{StatusCode: 200, Body: tt.body}
+			if resp.IsBinary() != tt.binary {
+				t.Errorf("Expected IsBinary %v", tt.binary)
+			}
+			if got := resp.DescribeBody(); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
 func TestResponse_GetBodyAsJSON(t *testing.T) {
 	t.Run("valid json", func(t *testing.T) {
 		data := map[string]string{"key": "value"}
@@ -308,3 +373,257 @@ func TestCallAPI_WithMockHTTPClient(t *testing.T) {
 		t.Errorf("Expected status 200, got %d", resp.StatusCode)
 	}
 }
+
+// countingRecorder counts the exchanges it is notified of
+type countingRecorder struct {
+	exchanges int
+}
+
+func (c *countingRecorder) Record(*Exchange) {
+	c.exchanges++
+}
+
+func TestMultiRecorder(t *testing.T) {
+	mockClient := &MockHTTPClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: 200,
+				Body:       io.NopCloser(bytes.NewBufferString(`{"status":"ok"}`)),
+				Header:     make(http.Header),
+			}, nil
+		},
+	}
+	first, second := &countingRecorder{}, &countingRecorder{}
+	client := NewAPIClientWithHTTPClient(mockClient, 30*time.Second)
+	client.SetRecorder(MultiRecorder(first, second))
+
+	for range 2 {
+		if _, err := client.CallAPI(context.Background(), "GET", "http://example.com", "test-token", nil); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if first.exchanges != 2 || second.exchanges != 2 {
+		t.Errorf("Expected both recorders to see 2 exchanges, got %d and %d", first.exchanges, second.exchanges)
+	}
+}
+
+func TestResponse_DeprecationWarnings(t *testing.T) {
+	tests := []struct {
+		name     string
+		headers  map[string]string
+		expected []string
+	}{
+		{
+			"no headers",
+			map[string]string{},
+			nil,
+		},
+		{
+			"structured deprecation date",
+			map[string]string{"Deprecation": "@1688169599"},
+			[]string{"API is deprecated (since 2023-06-30)"},
+		},
+		{
+			"legacy boolean deprecation",
+			map[string]string{"Deprecation": "true"},
+			[]string{"API is deprecated"},
+		},
+		{
+			"sunset date",
+			map[string]string{"Sunset": "Wed, 11 Nov 2026 23:59:59 GMT"},
+			[]string{"API will be retired on 2026-11-11"},
+		},
+		{
+			"deprecation and sunset",
+			map[string]string{"Deprecation": "@1688169599", "Sunset": "not-a-date"},
+			[]string{"API is deprecated (since 2023-06-30)", "API has a sunset date: not-a-date"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			headers := make(http.Header)
+			for key, value := range tt.headers {
+				headers.Set(key, value)
+			}
+			resp := &Response{StatusCode: 200, Headers: headers}
+
+			warnings := resp.DeprecationWarnings()
+			if len(warnings) != len(tt.expected) {
+				t.Fatalf("Expected %d warnings, got %d: %v", len(tt.expected), len(warnings), warnings)
+			}
+			for i := range warnings {
+				if warnings[i] != tt.expected[i] {
+					t.Errorf("Expected warning %q, got %q", tt.expected[i], warnings[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSend_ThrottleRetries(t *testing.T) {
+	t.Run("retries until success", func(t *testing.T) {
+		calls := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			if calls <= 2 {
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		client := NewAPIClient()
+		resp, err := client.Send(context.Background(), &Request{
+			Method:             "GET",
+			URL:                server.URL,
+			AccessToken:        "test-token",
+			MaxThrottleRetries: 3,
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("Expected status 200, got %d", resp.StatusCode)
+		}
+		if resp.ThrottleRetries != 2 {
+			t.Errorf("Expected 2 throttle retries, got %d", resp.ThrottleRetries)
+		}
+	})
+
+	t.Run("gives up after limit", func(t *testing.T) {
+		calls := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+		}))
+		defer server.Close()
+
+		client := NewAPIClient()
+		resp, err := client.Send(context.Background(), &Request{
+			Method:             "GET",
+			URL:                server.URL,
+			AccessToken:        "test-token",
+			MaxThrottleRetries: 1,
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if resp.StatusCode != http.StatusTooManyRequests {
+			t.Errorf("Expected status 429, got %d", resp.StatusCode)
+		}
+		if calls != 2 {
+			t.Errorf("Expected 2 calls, got %d", calls)
+		}
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		calls := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			w.WriteHeader(http.StatusTooManyRequests)
+		}))
+		defer server.Close()
+
+		client := NewAPIClient()
+		if _, err := client.CallAPI(context.Background(), "GET", server.URL, "test-token", nil); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if calls != 1 {
+			t.Errorf("Expected 1 call, got %d", calls)
+		}
+	})
+
+	t.Run("resends request body", func(t *testing.T) {
+		var bodies []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			bodies = append(bodies, string(body))
+			if len(bodies) == 1 {
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			w.WriteHeader(http.StatusCreated)
+		}))
+		defer server.Close()
+
+		client := NewAPIClient()
+		_, err := client.Send(context.Background(), &Request{
+			Method:             "POST",
+			URL:                server.URL,
+			AccessToken:        "test-token",
+			Body:               map[string]interface{}{"key": "value"},
+			MaxThrottleRetries: 1,
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(bodies) != 2 || bodies[0] != bodies[1] || bodies[1] == "" {
+			t.Errorf("Expected identical non-empty bodies on retry, got %q", bodies)
+		}
+	})
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2025, 10, 20, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		value    string
+		expected time.Duration
+	}{
+		{"delay seconds", "5", 5 * time.Second},
+		{"zero", "0", 0},
+		{"http date", "Mon, 20 Oct 2025 12:00:10 GMT", 10 * time.Second},
+		{"date in the past", "Mon, 20 Oct 2025 11:00:00 GMT", 0},
+		{"missing", "", defaultRetryAfter},
+		{"garbage", "soon", defaultRetryAfter},
+		{"capped", "3600", maxRetryAfter},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RetryAfter(tt.value, now); got != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestSend_MaxBodyBytes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("0123456789"))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name      string
+		limit     int64
+		expected  string
+		truncated bool
+	}{
+		{name: "no limit", expected: "0123456789"},
+		{name: "exact limit", limit: 10, expected: "0123456789"},
+		{name: "over limit", limit: 4, expected: "0123", truncated: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := NewAPIClient().Send(context.Background(), &Request{
+				Method:       "GET",
+				URL:          server.URL,
+				MaxBodyBytes: tt.limit,
+			})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if string(resp.Body) != tt.expected || resp.Truncated != tt.truncated {
+				t.Errorf("Expected body %q (truncated %v), got %q (truncated %v)", tt.expected, tt.truncated, resp.Body, resp.Truncated)
+			}
+		})
+	}
+}
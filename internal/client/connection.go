@@ -0,0 +1,55 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http/httptrace"
+	"time"
+)
+
+// Connection describes the connection a request was sent over, to tell
+// the latency of a new connection from that of a reused one
+type Connection struct {
+	// RemoteAddr is the address of the connection, or of the last one
+	// dialed when none was established, e.g. "[2001:db8::1]:443"
+	RemoteAddr string
+	// Reused reports that the connection had carried earlier requests
+	Reused bool
+	// IdleTime is how long a reused connection was idle before the request
+	IdleTime time.Duration
+	// TLSResumed reports that the TLS handshake of a new connection resumed
+	// an earlier session instead of a full handshake
+	TLSResumed bool
+	// TLSHandshake is how long the TLS handshake of a new connection took
+	TLSHandshake time.Duration
+}
+
+// traceConnection traces the request in ctx to record the connection it
+// is sent over in conn
+func traceConnection(ctx context.Context, conn *Connection) context.Context {
+	var handshakeStarted time.Time
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		ConnectStart: func(_, address string) {
+			conn.RemoteAddr = address
+		},
+		TLSHandshakeStart: func() {
+			handshakeStarted = time.Now()
+		},
+		TLSHandshakeDone: func(state tls.ConnectionState, err error) {
+			if err == nil {
+				conn.TLSResumed = state.DidResume
+				conn.TLSHandshake = time.Since(handshakeStarted)
+			}
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			conn.RemoteAddr = info.Conn.RemoteAddr().String()
+			conn.Reused = info.Reused
+			conn.IdleTime = info.IdleTime
+			if info.Reused {
+				// A handshake traced before belonged to an earlier hop of a
+				// redirect chain
+				conn.TLSResumed, conn.TLSHandshake = false, 0
+			}
+		},
+	})
+}
@@ -0,0 +1,43 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSend_Connection(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	c := NewAPIClient()
+	request := &Request{Method: http.MethodGet, URL: server.URL, Transport: &TransportOptions{CAFile: writeServerCA(t, server)}}
+	send := func() Connection {
+		t.Helper()
+		response, err := c.Send(context.Background(), request)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return response.Connection
+	}
+
+	first := send()
+	if first.Reused || first.TLSResumed || first.TLSHandshake <= 0 || first.RemoteAddr != server.Listener.Addr().String() {
+		t.Errorf("Expected a new connection with a full handshake, got %+v", first)
+	}
+	second := send()
+	if !second.Reused || second.TLSHandshake != 0 {
+		t.Errorf("Expected the connection to be reused, got %+v", second)
+	}
+
+	httpClient, err := c.clientFor(request.Transport)
+	if err != nil {
+		t.Fatal(err)
+	}
+	httpClient.(*http.Client).CloseIdleConnections()
+	third := send()
+	if third.Reused || !third.TLSResumed {
+		t.Errorf("Expected a new connection resuming the TLS session, got %+v", third)
+	}
+}
@@ -0,0 +1,22 @@
+package client
+
+import "net/http"
+
+// jarClient adds cookie jar handling to an HTTPClient that is not an
+// *http.Client
+type jarClient struct {
+	client HTTPClient
+	jar    http.CookieJar
+}
+
+// Do implements HTTPClient
+func (c *jarClient) Do(req *http.Request) (*http.Response, error) {
+	for _, cookie := range c.jar.Cookies(req.URL) {
+		req.AddCookie(cookie)
+	}
+	resp, err := c.client.Do(req)
+	if err == nil {
+		c.jar.SetCookies(req.URL, resp.Cookies())
+	}
+	return resp, err
+}
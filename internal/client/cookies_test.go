@@ -0,0 +1,93 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSend_CookieJar(t *testing.T) {
+	var received []string
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		received = append(received, r.Header.Get("Cookie"))
+		switch r.URL.Path {
+		case "/login":
+			http.SetCookie(w, &http.Cookie{Name: "affinity", Value: "node-2", Path: "/"})
+			http.Redirect(w, r, "/home", http.StatusFound)
+		case "/home":
+			http.SetCookie(w, &http.Cookie{Name: "XSRF-TOKEN", Value: "abc", Path: "/"})
+		}
+	}
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	defer server.Close()
+
+	mockClient := &MockHTTPClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			recorder := httptest.NewRecorder()
+			handler(recorder, req)
+			return recorder.Result(), nil
+		},
+	}
+
+	tests := []struct {
+		client   *APIClient
+		name     string
+		expected []string
+	}{
+		{
+			name:     "http.Client keeps redirect cookies",
+			client:   NewAPIClient(),
+			expected: []string{"", "affinity=node-2", "affinity=node-2; XSRF-TOKEN=abc"},
+		},
+		{
+			name:     "custom HTTPClient",
+			client:   NewAPIClientWithHTTPClient(mockClient, 30*time.Second),
+			expected: []string{"", "affinity=node-2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			received = nil
+			jar, _ := cookiejar.New(nil)
+			for _, path := range []string{"/login", "/api"} {
+				if _, err := tt.client.Send(context.Background(), &Request{Method: "GET", URL: server.URL + path, CookieJar: jar}); err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+			}
+			if len(received) != len(tt.expected) {
+				t.Fatalf("Expected Cookie headers %q, got %q", tt.expected, received)
+			}
+			for i := range received {
+				if received[i] != tt.expected[i] {
+					t.Errorf("Request %d: expected Cookie %q, got %q", i, tt.expected[i], received[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSend_WithoutCookieJar(t *testing.T) {
+	var cookies []string
+	mockClient := &MockHTTPClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			cookies = append(cookies, req.Header.Get("Cookie"))
+			header := http.Header{"Set-Cookie": []string{"session=1"}}
+			return &http.Response{StatusCode: 200, Header: header, Body: io.NopCloser(bytes.NewReader(nil))}, nil
+		},
+	}
+	client := NewAPIClientWithHTTPClient(mockClient, 30*time.Second)
+	for range 2 {
+		if _, err := client.Send(context.Background(), &Request{Method: "GET", URL: "http://example.com"}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if cookies[1] != "" {
+		t.Errorf("Expected no cookies without a jar, got %q", cookies[1])
+	}
+}
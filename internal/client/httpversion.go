@@ -0,0 +1,32 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// HTTP versions a transport may be forced to
+const (
+	HTTP1 = "1.1"
+	HTTP2 = "2"
+)
+
+// setHTTPVersion limits transport to an HTTP version. HTTP/2 is also used
+// for http:// URLs then, with prior knowledge (h2c), and a server that
+// cannot speak it fails the request instead of being downgraded.
+func setHTTPVersion(transport *http.Transport, version string) error {
+	protocols := new(http.Protocols)
+	switch version {
+	case "":
+		return nil
+	case HTTP1:
+		protocols.SetHTTP1(true)
+	case HTTP2:
+		protocols.SetHTTP2(true)
+		protocols.SetUnencryptedHTTP2(true)
+	default:
+		return fmt.Errorf("unsupported HTTP version %q (must be %s or %s)", version, HTTP1, HTTP2)
+	}
+	transport.Protocols = protocols
+	return nil
+}
@@ -0,0 +1,70 @@
+package client
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSend_HTTPVersion(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	tlsServer := httptest.NewUnstartedServer(handler)
+	tlsServer.EnableHTTP2 = true
+	tlsServer.StartTLS()
+	defer tlsServer.Close()
+	http1Server := httptest.NewUnstartedServer(handler)
+	http1Server.Config.ErrorLog = log.New(io.Discard, "", 0) // the rejected HTTP/2 handshake
+	http1Server.StartTLS()
+	defer http1Server.Close()
+	h2cServer := httptest.NewUnstartedServer(handler)
+	h2cServer.Config.Protocols = new(http.Protocols)
+	h2cServer.Config.Protocols.SetHTTP1(true)
+	h2cServer.Config.Protocols.SetUnencryptedHTTP2(true)
+	h2cServer.Start()
+	defer h2cServer.Close()
+
+	tests := []struct {
+		name          string
+		server        *httptest.Server
+		version       string
+		expectedProto string
+		expectErr     bool
+	}{
+		{name: "negotiated", server: tlsServer, expectedProto: "HTTP/2.0"},
+		{name: "forced HTTP/1.1", server: tlsServer, version: HTTP1, expectedProto: "HTTP/1.1"},
+		{name: "forced HTTP/2", server: tlsServer, version: HTTP2, expectedProto: "HTTP/2.0"},
+		{name: "HTTP/2 with prior knowledge", server: h2cServer, version: HTTP2, expectedProto: "HTTP/2.0"},
+		{name: "HTTP/2 not served", server: http1Server, version: HTTP2, expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := &TransportOptions{HTTPVersion: tt.version}
+			if tt.server.TLS != nil {
+				transport.CAFile = writeServerCA(t, tt.server)
+			}
+			response, err := NewAPIClient().Send(context.Background(), &Request{Method: http.MethodGet, URL: tt.server.URL, Transport: transport})
+			if tt.expectErr {
+				if err == nil {
+					t.Errorf("Expected an error, got a response over %s", response.Proto)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if response.Proto != tt.expectedProto {
+				t.Errorf("Expected %s, got %s", tt.expectedProto, response.Proto)
+			}
+		})
+	}
+}
+
+func TestNewTransport_InvalidHTTPVersion(t *testing.T) {
+	if _, err := NewTransport(TransportOptions{HTTPVersion: "3"}); err == nil {
+		t.Error("Expected an error for HTTP/3")
+	}
+}
@@ -0,0 +1,61 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net"
+)
+
+// IP address families a transport may be limited to
+const (
+	IPv4 = 4
+	IPv6 = 6
+)
+
+// ipVersionDialer returns a dial function that connects over the given IP
+// version only, so that a host with both A and AAAA records is reached
+// over the family under test instead of the one Happy Eyeballs picks
+func ipVersionDialer(version int, dial dialFunc) dialFunc {
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		if network == "tcp" {
+			network = fmt.Sprintf("tcp%d", version)
+		}
+		return dial(ctx, network, address)
+	}
+}
+
+// AddressFamily returns "IPv4" or "IPv6" for the IP address of address, a
+// host and port, or "" when it is not an IP address
+func AddressFamily(address string) string {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		host = address
+	}
+	ip := net.ParseIP(host)
+	switch {
+	case ip == nil:
+		return ""
+	case ip.To4() != nil:
+		return "IPv4"
+	default:
+		return "IPv6"
+	}
+}
+
+// AddrError is a failed request annotated with the last address it
+// connected, or tried to connect, to, so that failures over one address
+// family can be told apart from failures over the other
+type AddrError struct {
+	Err  error
+	Addr string
+}
+
+// Error appends the address and its family to the failure
+func (e *AddrError) Error() string {
+	return fmt.Sprintf("%v (over %s to %s)", e.Err, AddressFamily(e.Addr), e.Addr)
+}
+
+// Unwrap returns the failure
+func (e *AddrError) Unwrap() error {
+	return e.Err
+}
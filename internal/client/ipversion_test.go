@@ -0,0 +1,64 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSend_IPVersion(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	c := NewAPIClient()
+	response, err := c.Send(context.Background(), &Request{Method: http.MethodGet, URL: server.URL, Transport: &TransportOptions{IPVersion: IPv4}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if remoteAddr := response.Connection.RemoteAddr; remoteAddr != server.Listener.Addr().String() || AddressFamily(remoteAddr) != "IPv4" {
+		t.Errorf("Expected the request to record its IPv4 address %s, got %q", server.Listener.Addr(), remoteAddr)
+	}
+
+	// The test server has no IPv6 address
+	if _, err := c.Send(context.Background(), &Request{Method: http.MethodGet, URL: server.URL, Transport: &TransportOptions{IPVersion: IPv6}}); err == nil {
+		t.Error("Expected an IPv6-only request to an IPv4 address to fail")
+	}
+}
+
+func TestSend_AddrError(t *testing.T) {
+	listener, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := listener.Addr().String()
+	_ = listener.Close()
+
+	_, err = NewAPIClient().Send(context.Background(), &Request{Method: http.MethodGet, URL: "http://" + address})
+	var addrErr *AddrError
+	if !errors.As(err, &addrErr) || addrErr.Addr != address {
+		t.Fatalf("Expected the failure to record the address tried, got %v", err)
+	}
+	if !strings.HasSuffix(err.Error(), "(over IPv4 to "+address+")") {
+		t.Errorf("Expected the failure to name the address family, got %q", err)
+	}
+}
+
+func TestAddressFamily(t *testing.T) {
+	tests := map[string]string{
+		"10.0.0.1:443":          "IPv4",
+		"[2001:db8::1]:443":     "IPv6",
+		"[::ffff:10.0.0.1]:443": "IPv4",
+		"2001:db8::1":           "IPv6",
+		"api.example.com:443":   "",
+		"":                      "",
+	}
+	for address, expected := range tests {
+		if family := AddressFamily(address); family != expected {
+			t.Errorf("AddressFamily(%q): expected %q, got %q", address, expected, family)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package client
+
+import (
+	"bytes"
+	"fmt"
+	"mime/multipart"
+	"net/textproto"
+	"strings"
+)
+
+// Part is a field or file of a multipart/form-data body
+type Part struct {
+	Name  string
+	Value []byte
+	// FileName makes the part a file upload
+	FileName string
+	// ContentType is the part's Content-Type; fields without one are
+	// plain text
+	ContentType string
+}
+
+// EncodeMultipart encodes parts as a multipart/form-data body and returns
+// it with its Content-Type, which names the boundary
+func EncodeMultipart(parts []Part) ([]byte, string, error) {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	for i := range parts {
+		part := &parts[i]
+		header := make(textproto.MIMEHeader)
+		disposition := fmt.Sprintf(`form-data; name="%s"`, quoteEscaper.Replace(part.Name))
+		if part.FileName != "" {
+			disposition += fmt.Sprintf(`; filename="%s"`, quoteEscaper.Replace(part.FileName))
+		}
+		header.Set("Content-Disposition", disposition)
+		if part.ContentType != "" {
+			header.Set("Content-Type", part.ContentType)
+		}
+		w, err := writer.CreatePart(header)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to encode part %s: %w", part.Name, err)
+		}
+		if _, err := w.Write(part.Value); err != nil {
+			return nil, "", fmt.Errorf("failed to encode part %s: %w", part.Name, err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, "", fmt.Errorf("failed to encode multipart body: %w", err)
+	}
+	return buf.Bytes(), writer.FormDataContentType(), nil
+}
+
+// quoteEscaper escapes names in a Content-Disposition header, like
+// mime/multipart does
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
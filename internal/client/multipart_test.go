@@ -0,0 +1,77 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSend_Multipart(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reader, err := r.MultipartReader()
+		if err != nil {
+			t.Errorf("Expected a multipart body: %v", err)
+			return
+		}
+		type received struct {
+			name, fileName, contentType, content string
+		}
+		var parts []received
+		for {
+			part, err := reader.NextPart()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Errorf("Failed to read part: %v", err)
+				return
+			}
+			content, _ := io.ReadAll(part)
+			parts = append(parts, received{part.FormName(), part.FileName(), part.Header.Get("Content-Type"), string(content)})
+		}
+
+		expected := []received{
+			{"title", "", "", "Quarterly report"},
+			{"metadata", "", "application/json", `{"tags":["finance"]}`},
+			{"file", `report "final".pdf`, "application/pdf", "%PDF-1.7\x00\xff"},
+		}
+		if len(parts) != len(expected) {
+			t.Errorf("Expected %d parts, got %+v", len(expected), parts)
+			return
+		}
+		for i := range expected {
+			if parts[i] != expected[i] {
+				t.Errorf("Part %d: expected %+v, got %+v", i, expected[i], parts[i])
+			}
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	body, contentType, err := EncodeMultipart([]Part{
+		{Name: "title", Value: []byte("Quarterly report")},
+		{Name: "metadata", Value: []byte(`{"tags":["finance"]}`), ContentType: "application/json"},
+		{Name: "file", Value: []byte("%PDF-1.7\x00\xff"), FileName: `report "final".pdf`, ContentType: "application/pdf"},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	response, err := NewAPIClient().Send(context.Background(), &Request{
+		Method:      "POST",
+		URL:         server.URL,
+		AccessToken: "token",
+		RawBody:     body,
+		ContentType: contentType,
+		// Body is ignored in favor of RawBody
+		Body: map[string]interface{}{"ignored": true},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if response.StatusCode != http.StatusCreated {
+		t.Errorf("Expected status 201, got %d", response.StatusCode)
+	}
+}
@@ -0,0 +1,149 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Race test expectations
+const (
+	// RaceExpectAll requires every concurrent request to succeed
+	RaceExpectAll = "all"
+	// RaceExpectOne requires exactly one concurrent request to succeed, as
+	// for create-with-unique-key APIs
+	RaceExpectOne = "one"
+)
+
+// RaceResult holds the outcome of identical requests sent concurrently
+type RaceResult struct {
+	Responses []*Response
+	Errors    []error
+	// Durations are how long each request took
+	Durations []time.Duration
+}
+
+// SendConcurrently fires n identical copies of request at the same moment and
+// waits for all of them to complete
+func (c *APIClient) SendConcurrently(ctx context.Context, request *Request, n int) *RaceResult {
+	result := &RaceResult{
+		Responses: make([]*Response, n),
+		Errors:    make([]error, n),
+		Durations: make([]time.Duration, n),
+	}
+
+	var ready, done sync.WaitGroup
+	start := make(chan struct{})
+	ready.Add(n)
+	done.Add(n)
+	for i := 0; i < n; i++ {
+		go func(i int) {
+			defer done.Done()
+			ready.Done()
+			<-start
+			started := time.Now()
+			result.Responses[i], result.Errors[i] = c.Send(ctx, request)
+			result.Durations[i] = time.Since(started)
+		}(i)
+	}
+
+	// Release all requests together once every goroutine is waiting
+	ready.Wait()
+	close(start)
+	done.Wait()
+
+	return result
+}
+
+// Succeeded returns the number of requests that completed with a 2xx status
+func (r *RaceResult) Succeeded() int {
+	count := 0
+	for _, response := range r.Responses {
+		if response != nil && response.IsSuccessStatusCode() {
+			count++
+		}
+	}
+	return count
+}
+
+// Rejected returns the number of requests the API rejected with a 4xx
+// status, e.g. 409 Conflict or 412 Precondition Failed
+func (r *RaceResult) Rejected() int {
+	count := 0
+	for _, response := range r.Responses {
+		if response != nil && response.StatusCode >= http.StatusBadRequest && response.StatusCode < http.StatusInternalServerError {
+			count++
+		}
+	}
+	return count
+}
+
+// Failed returns the number of requests that failed to complete, e.g.
+// because the connection failed, and so have no response
+func (r *RaceResult) Failed() int {
+	count := 0
+	for _, err := range r.Errors {
+		if err != nil {
+			count++
+		}
+	}
+	return count
+}
+
+// Winner returns the first successful response, or the first response of any
+// kind when none succeeded. It returns nil when every request failed to connect.
+func (r *RaceResult) Winner() *Response {
+	var first *Response
+	for _, response := range r.Responses {
+		if response == nil {
+			continue
+		}
+		if response.IsSuccessStatusCode() {
+			return response
+		}
+		if first == nil {
+			first = response
+		}
+	}
+	return first
+}
+
+// FirstError returns the first transport-level error, if any
+func (r *RaceResult) FirstError() error {
+	for _, err := range r.Errors {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Check verifies the race outcome against the expectation (RaceExpectAll or
+// RaceExpectOne). A request that failed to complete is no outcome of the
+// race, and the requests that lose a RaceExpectOne race must be rejected
+// with a 4xx status rather than fail with a 5xx.
+func (r *RaceResult) Check(expect string) error {
+	total := len(r.Responses)
+	if failed := r.Failed(); failed > 0 {
+		return fmt.Errorf("%d/%d concurrent requests failed: %w", failed, total, r.FirstError())
+	}
+	succeeded := r.Succeeded()
+
+	switch expect {
+	case RaceExpectOne:
+		if succeeded != 1 {
+			return fmt.Errorf("%d/%d concurrent requests succeeded (expected exactly one)", succeeded, total)
+		}
+		if rejected := r.Rejected(); succeeded+rejected != total {
+			return fmt.Errorf("%d/%d concurrent requests were neither successful nor rejected with a 4xx status", total-succeeded-rejected, total)
+		}
+	default:
+		if succeeded != total {
+			return fmt.Errorf("%d/%d concurrent requests succeeded (expected all)", succeeded, total)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,126 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestSendConcurrently_AllSucceed(t *testing.T) {
+	var mu sync.Mutex
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		calls++
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewAPIClient()
+	result := client.SendConcurrently(context.Background(), &Request{Method: "GET", URL: server.URL, AccessToken: "test-token"}, 5)
+
+	if calls != 5 {
+		t.Errorf("Expected 5 calls, got %d", calls)
+	}
+	if result.Succeeded() != 5 {
+		t.Errorf("Expected 5 successes, got %d", result.Succeeded())
+	}
+	if err := result.Check(RaceExpectAll); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if err := result.Check(RaceExpectOne); err == nil {
+		t.Error("Expected exactly-one check to fail, got nil")
+	}
+}
+
+func TestSendConcurrently_OneWins(t *testing.T) {
+	var mu sync.Mutex
+	created := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if created {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		created = true
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	client := NewAPIClient()
+	result := client.SendConcurrently(context.Background(), &Request{Method: "POST", URL: server.URL, AccessToken: "test-token"}, 4)
+
+	if err := result.Check(RaceExpectOne); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if err := result.Check(RaceExpectAll); err == nil {
+		t.Error("Expected all-succeed check to fail, got nil")
+	}
+	if winner := result.Winner(); winner == nil || winner.StatusCode != http.StatusCreated {
+		t.Errorf("Expected winner with status 201, got %+v", winner)
+	}
+}
+
+func TestRaceResult_ConnectionErrors(t *testing.T) {
+	client := NewAPIClient()
+	result := client.SendConcurrently(context.Background(), &Request{Method: "GET", URL: "://invalid-url"}, 3)
+
+	if result.FirstError() == nil {
+		t.Error("Expected connection error, got nil")
+	}
+	if result.Winner() != nil {
+		t.Error("Expected no winner when all requests failed")
+	}
+}
+
+func TestRaceResult_Check(t *testing.T) {
+	response := func(status int) *Response { return &Response{StatusCode: status} }
+
+	tests := []struct {
+		name      string
+		result    RaceResult
+		expect    string
+		expectErr string
+	}{
+		{
+			name:   "one wins, others conflict",
+			result: RaceResult{Responses: []*Response{response(http.StatusCreated), response(http.StatusConflict), response(http.StatusPreconditionFailed)}, Errors: make([]error, 3)},
+			expect: RaceExpectOne,
+		},
+		{
+			name:      "one wins, another errors on the server",
+			result:    RaceResult{Responses: []*Response{response(http.StatusCreated), response(http.StatusConflict), response(http.StatusInternalServerError)}, Errors: make([]error, 3)},
+			expect:    RaceExpectOne,
+			expectErr: "1/3 concurrent requests were neither successful nor rejected with a 4xx status",
+		},
+		{
+			name:      "one wins, another fails to connect",
+			result:    RaceResult{Responses: []*Response{response(http.StatusCreated), response(http.StatusConflict), nil}, Errors: []error{nil, nil, errors.New("connection refused")}},
+			expect:    RaceExpectOne,
+			expectErr: "1/3 concurrent requests failed: connection refused",
+		},
+		{
+			name:      "all succeed but one fails to connect",
+			result:    RaceResult{Responses: []*Response{response(http.StatusOK), nil}, Errors: []error{nil, errors.New("connection reset")}},
+			expect:    RaceExpectAll,
+			expectErr: "1/2 concurrent requests failed: connection reset",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.result.Check(tt.expect)
+			if tt.expectErr == "" && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+			if tt.expectErr != "" && (err == nil || err.Error() != tt.expectErr) {
+				t.Errorf("Expected error %q, got %v", tt.expectErr, err)
+			}
+		})
+	}
+}
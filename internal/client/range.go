@@ -0,0 +1,138 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// byteRange is a single, possibly open-ended, byte range. A negative start
+// denotes a suffix range ("bytes=-500"), a negative end an open range
+// ("bytes=500-").
+type byteRange struct {
+	start int64
+	end   int64
+}
+
+// parseRangeHeader parses a single-range Range header value such as "bytes=0-1023"
+func parseRangeHeader(value string) (byteRange, error) {
+	spec, found := strings.CutPrefix(strings.TrimSpace(value), "bytes=")
+	if !found {
+		return byteRange{}, fmt.Errorf("range must use the bytes unit: %q", value)
+	}
+	if strings.Contains(spec, ",") {
+		return byteRange{}, fmt.Errorf("multiple ranges are not supported: %q", value)
+	}
+
+	first, last, found := strings.Cut(spec, "-")
+	if !found || (first == "" && last == "") {
+		return byteRange{}, fmt.Errorf("malformed range: %q", value)
+	}
+
+	r := byteRange{start: -1, end: -1}
+	if first != "" {
+		start, err := strconv.ParseInt(first, 10, 64)
+		if err != nil || start < 0 {
+			return byteRange{}, fmt.Errorf("malformed range start: %q", value)
+		}
+		r.start = start
+	}
+	if last != "" {
+		end, err := strconv.ParseInt(last, 10, 64)
+		if err != nil || end < 0 {
+			return byteRange{}, fmt.Errorf("malformed range end: %q", value)
+		}
+		r.end = end
+	}
+	if r.start >= 0 && r.end >= 0 && r.end < r.start {
+		return byteRange{}, fmt.Errorf("range end before start: %q", value)
+	}
+
+	return r, nil
+}
+
+// parseContentRange parses a Content-Range header value such as
+// "bytes 0-1023/4096". The total is -1 when the server reports it as "*".
+func parseContentRange(value string) (start, end, total int64, err error) {
+	spec, found := strings.CutPrefix(strings.TrimSpace(value), "bytes ")
+	if !found {
+		return 0, 0, 0, fmt.Errorf("unsupported Content-Range unit: %q", value)
+	}
+
+	span, size, found := strings.Cut(spec, "/")
+	if !found {
+		return 0, 0, 0, fmt.Errorf("malformed Content-Range: %q", value)
+	}
+	first, last, found := strings.Cut(span, "-")
+	if !found {
+		return 0, 0, 0, fmt.Errorf("malformed Content-Range: %q", value)
+	}
+
+	if start, err = strconv.ParseInt(first, 10, 64); err != nil {
+		return 0, 0, 0, fmt.Errorf("malformed Content-Range start: %q", value)
+	}
+	if end, err = strconv.ParseInt(last, 10, 64); err != nil {
+		return 0, 0, 0, fmt.Errorf("malformed Content-Range end: %q", value)
+	}
+	total = -1
+	if size != "*" {
+		if total, err = strconv.ParseInt(size, 10, 64); err != nil {
+			return 0, 0, 0, fmt.Errorf("malformed Content-Range length: %q", value)
+		}
+	}
+
+	return start, end, total, nil
+}
+
+// ValidateRange checks that the response is a correct partial response for
+// the requested Range header value: status 206, a Content-Range matching the
+// requested bytes, and a body of the advertised length
+func (r *Response) ValidateRange(requested string) error {
+	want, err := parseRangeHeader(requested)
+	if err != nil {
+		return err
+	}
+
+	if r.StatusCode != http.StatusPartialContent {
+		return fmt.Errorf("expected status 206 Partial Content, got %d", r.StatusCode)
+	}
+
+	contentRange := r.Headers.Get("Content-Range")
+	if contentRange == "" {
+		return fmt.Errorf("missing Content-Range header")
+	}
+	start, end, total, err := parseContentRange(contentRange)
+	if err != nil {
+		return err
+	}
+
+	// Work out which bytes the server should have returned
+	expectedStart, expectedEnd := want.start, want.end
+	switch {
+	case want.start < 0: // suffix range: last N bytes
+		if total < 0 {
+			return fmt.Errorf("content length unknown for suffix range: Content-Range %q", contentRange)
+		}
+		expectedStart = max(total-want.end, 0)
+		expectedEnd = total - 1
+	case want.end < 0 || (total >= 0 && want.end >= total): // open or overlong range
+		if total < 0 {
+			expectedEnd = end
+		} else {
+			expectedEnd = total - 1
+		}
+	}
+
+	if start != expectedStart || end != expectedEnd {
+		return fmt.Errorf("content range %q does not match requested range %q", contentRange, requested)
+	}
+	if total >= 0 && end >= total {
+		return fmt.Errorf("content range %q ends beyond the resource length", contentRange)
+	}
+	if length := int64(len(r.Body)); length != end-start+1 {
+		return fmt.Errorf("body length %d does not match Content-Range %q", length, contentRange)
+	}
+
+	return nil
+}
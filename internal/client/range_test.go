@@ -0,0 +1,109 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseRangeHeader(t *testing.T) {
+	tests := []struct {
+		value     string
+		expected  byteRange
+		expectErr bool
+	}{
+		{"bytes=0-1023", byteRange{0, 1023}, false},
+		{"bytes=500-", byteRange{500, -1}, false},
+		{"bytes=-500", byteRange{-1, 500}, false},
+		{"items=0-10", byteRange{}, true},
+		{"bytes=0-10,20-30", byteRange{}, true},
+		{"bytes=-", byteRange{}, true},
+		{"bytes=10-5", byteRange{}, true},
+		{"bytes=a-5", byteRange{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			r, err := parseRangeHeader(tt.value)
+			if tt.expectErr {
+				if err == nil {
+					t.Errorf("Expected error for %q, got nil", tt.value)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if r != tt.expected {
+				t.Errorf("Expected %+v, got %+v", tt.expected, r)
+			}
+		})
+	}
+}
+
+func TestResponse_ValidateRange(t *testing.T) {
+	tests := []struct {
+		name         string
+		requested    string
+		contentRange string
+		body         string
+		statusCode   int
+		expectErr    bool
+	}{
+		{"exact range", "bytes=0-3", "bytes 0-3/10", "0123", 206, false},
+		{"open range", "bytes=6-", "bytes 6-9/10", "6789", 206, false},
+		{"suffix range", "bytes=-2", "bytes 8-9/10", "89", 206, false},
+		{"overlong range clamped", "bytes=5-100", "bytes 5-9/10", "56789", 206, false},
+		{"unknown total", "bytes=0-3", "bytes 0-3/*", "0123", 206, false},
+		{"full response instead of partial", "bytes=0-3", "", "0123456789", 200, true},
+		{"missing content range", "bytes=0-3", "", "0123", 206, true},
+		{"wrong start", "bytes=2-3", "bytes 0-3/10", "0123", 206, true},
+		{"body length mismatch", "bytes=0-3", "bytes 0-3/10", "01", 206, true},
+		{"end beyond length", "bytes=0-3", "bytes 0-3/3", "0123", 206, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			headers := make(http.Header)
+			if tt.contentRange != "" {
+				headers.Set("Content-Range", tt.contentRange)
+			}
+			resp := &Response{StatusCode: tt.statusCode, Headers: headers, Body: []byte(tt.body)}
+
+			err := resp.ValidateRange(tt.requested)
+			if tt.expectErr && err == nil {
+				t.Error("Expected error, got nil")
+			}
+			if !tt.expectErr && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestSend_RangeRequestAgainstServer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "data.bin", time.Time{}, strings.NewReader("0123456789"))
+	}))
+	defer server.Close()
+
+	client := NewAPIClient()
+	resp, err := client.Send(context.Background(), &Request{
+		Method:      "GET",
+		URL:         server.URL,
+		AccessToken: "test-token",
+		Headers:     map[string]string{"Range": "bytes=2-5"},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := resp.ValidateRange("bytes=2-5"); err != nil {
+		t.Errorf("Unexpected range validation error: %v", err)
+	}
+	if resp.GetBodyAsString() != "2345" {
+		t.Errorf("Expected body 2345, got %s", resp.GetBodyAsString())
+	}
+}
@@ -0,0 +1,57 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// DefaultMaxRedirects is how many redirects are followed when a request
+// does not set MaxRedirects
+const DefaultMaxRedirects = 10
+
+// Redirect is a redirect response that was followed
+type Redirect struct {
+	// URL is the URL that responded with the redirect
+	URL string
+	// Location is the URL it redirected to
+	Location   string
+	StatusCode int
+}
+
+// forRequest returns httpClient configured for request: it uses the
+// request's cookie jar and redirect policy and appends each redirect it
+// follows to redirects. An *http.Client is copied, sharing its transport;
+// other clients only get the cookie jar, and follow redirects on their own.
+func forRequest(httpClient HTTPClient, request *Request, redirects *[]Redirect) HTTPClient {
+	standard, ok := httpClient.(*http.Client)
+	if !ok {
+		if request.CookieJar != nil {
+			return &jarClient{client: httpClient, jar: request.CookieJar}
+		}
+		return httpClient
+	}
+
+	configured := *standard
+	if request.CookieJar != nil {
+		configured.Jar = request.CookieJar
+	}
+	maxRedirects := request.MaxRedirects
+	if maxRedirects <= 0 {
+		maxRedirects = DefaultMaxRedirects
+	}
+	configured.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if request.NoRedirects {
+			return http.ErrUseLastResponse
+		}
+		if len(via) > maxRedirects {
+			return fmt.Errorf("stopped after %d redirects", maxRedirects)
+		}
+		redirect := Redirect{URL: via[len(via)-1].URL.String(), Location: req.URL.String()}
+		if req.Response != nil {
+			redirect.StatusCode = req.Response.StatusCode
+		}
+		*redirects = append(*redirects, redirect)
+		return nil
+	}
+	return &configured
+}
@@ -0,0 +1,69 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSend_Redirects(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/a":
+			http.Redirect(w, r, "/b", http.StatusMovedPermanently)
+		case "/b":
+			http.Redirect(w, r, "/c", http.StatusTemporaryRedirect)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name        string
+		request     Request
+		status      int
+		redirects   []Redirect
+		expectedErr string
+	}{
+		{
+			name:    "followed",
+			request: Request{},
+			status:  http.StatusOK,
+			redirects: []Redirect{
+				{URL: server.URL + "/a", Location: server.URL + "/b", StatusCode: http.StatusMovedPermanently},
+				{URL: server.URL + "/b", Location: server.URL + "/c", StatusCode: http.StatusTemporaryRedirect},
+			},
+		},
+		{name: "not followed", request: Request{NoRedirects: true}, status: http.StatusMovedPermanently},
+		{name: "over the limit", request: Request{MaxRedirects: 1}, expectedErr: "stopped after 1 redirects"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := tt.request
+			request.Method = "GET"
+			request.URL = server.URL + "/a"
+			request.AccessToken = "test-token"
+
+			resp, err := NewAPIClient().Send(context.Background(), &request)
+			if tt.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+					t.Errorf("Expected error %q, got %v", tt.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if resp.StatusCode != tt.status || len(resp.Redirects) != len(tt.redirects) {
+				t.Fatalf("Expected status %d with %d redirect(s), got %d with %+v", tt.status, len(tt.redirects), resp.StatusCode, resp.Redirects)
+			}
+			for i := range tt.redirects {
+				if resp.Redirects[i] != tt.redirects[i] {
+					t.Errorf("Redirect %d: expected %+v, got %+v", i, tt.redirects[i], resp.Redirects[i])
+				}
+			}
+		})
+	}
+}
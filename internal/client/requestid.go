@@ -0,0 +1,21 @@
+package client
+
+// RequestIDHeaders are the diagnostic headers Microsoft and Azure services
+// identify a request by, in the order they are shown. Support engineers ask
+// for them when a ticket is filed.
+var RequestIDHeaders = []string{"x-ms-request-id", "request-id", "x-ms-correlation-request-id"}
+
+// RequestIDs returns the values of the response's RequestIDHeaders by
+// header name, or nil when it has none
+func (r *Response) RequestIDs() map[string]string {
+	var ids map[string]string
+	for _, header := range RequestIDHeaders {
+		if value := r.Headers.Get(header); value != "" {
+			if ids == nil {
+				ids = make(map[string]string, len(RequestIDHeaders))
+			}
+			ids[header] = value
+		}
+	}
+	return ids
+}
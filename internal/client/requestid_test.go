@@ -0,0 +1,42 @@
+package client
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestResponse_RequestIDs(t *testing.T) {
+	tests := []struct {
+		name     string
+		headers  http.Header
+		expected map[string]string
+	}{
+		{name: "none", headers: http.Header{"Content-Type": {"application/json"}}},
+		{
+			name: "Azure Resource Manager",
+			headers: http.Header{
+				"X-Ms-Request-Id":             {"6f1c2d3e-0000-4000-8000-000000000001"},
+				"X-Ms-Correlation-Request-Id": {"6f1c2d3e-0000-4000-8000-000000000002"},
+			},
+			expected: map[string]string{
+				"x-ms-request-id":             "6f1c2d3e-0000-4000-8000-000000000001",
+				"x-ms-correlation-request-id": "6f1c2d3e-0000-4000-8000-000000000002",
+			},
+		},
+		{
+			name:     "Microsoft Graph",
+			headers:  http.Header{"Request-Id": {"0bd5d1d4-0000-4000-8000-000000000003"}},
+			expected: map[string]string{"request-id": "0bd5d1d4-0000-4000-8000-000000000003"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := &Response{Headers: tt.headers}
+			if got := response.RequestIDs(); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
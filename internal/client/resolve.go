@@ -0,0 +1,41 @@
+package client
+
+import (
+	"context"
+	"net"
+	"strings"
+)
+
+// dialFunc dials a network address, like net.Dialer.DialContext
+type dialFunc func(ctx context.Context, network, address string) (net.Conn, error)
+
+// resolveDialer returns a dial function that connects to the IP address
+// resolve maps a host to instead of resolving the host, like curl's
+// --resolve. The request keeps its URL, so the Host header and SNI still
+// name the host. Hosts without a mapping are dialed with dial.
+func resolveDialer(resolve map[string]string, dial dialFunc) dialFunc {
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(address)
+		if err != nil {
+			return dial(ctx, network, address)
+		}
+		if ip, ok := lookupResolve(resolve, host); ok {
+			address = net.JoinHostPort(ip, port)
+		}
+		return dial(ctx, network, address)
+	}
+}
+
+// lookupResolve returns the IP address resolve maps host to; host names
+// are case-insensitive
+func lookupResolve(resolve map[string]string, host string) (string, bool) {
+	if ip, ok := resolve[host]; ok {
+		return ip, true
+	}
+	for name, ip := range resolve {
+		if strings.EqualFold(name, host) {
+			return ip, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,50 @@
+package client
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestSend_Resolve(t *testing.T) {
+	var host, serverName string
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host, serverName = r.Host, r.TLS.ServerName
+	}))
+	defer server.Close()
+	serverURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, port, _ := net.SplitHostPort(serverURL.Host)
+
+	// The test certificate is valid for example.com, which only resolves
+	// to the test server through the mapping
+	c := NewAPIClient()
+	response, err := c.Send(context.Background(), &Request{
+		Method:    http.MethodGet,
+		URL:       "https://Example.com:" + port + "/health",
+		Transport: &TransportOptions{CAFile: writeServerCA(t, server), Resolve: map[string]string{"example.com": "127.0.0.1"}},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if response.StatusCode != http.StatusOK {
+		t.Errorf("Expected 200, got %d", response.StatusCode)
+	}
+	if host != "Example.com:"+port || serverName != "Example.com" {
+		t.Errorf("Expected the request to keep its Host and SNI, got Host %q and SNI %q", host, serverName)
+	}
+}
+
+func TestTransportOptions_IsZero(t *testing.T) {
+	if !(&TransportOptions{}).IsZero() {
+		t.Error("Expected empty options to be zero")
+	}
+	if (&TransportOptions{Resolve: map[string]string{"example.com": "127.0.0.1"}}).IsZero() {
+		t.Error("Expected options with a resolve mapping not to be zero")
+	}
+}
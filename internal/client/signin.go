@@ -0,0 +1,94 @@
+package client
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ErrSignInPage reports a response that is the Entra ID interactive sign-in
+// page, or a redirect to it, instead of an API response. It usually means
+// the API or its gateway (e.g. App Service Authentication or APIM) sends
+// callers without a session to interactive login rather than accepting the
+// bearer token.
+var ErrSignInPage = errors.New("received the Entra ID sign-in page instead of an API response")
+
+// signInHosts are the Entra ID and Microsoft account sign-in hosts across
+// the public and sovereign clouds
+var signInHosts = []string{
+	"login.microsoftonline.com",
+	"login.microsoft.com",
+	"login.windows.net",
+	"login.live.com",
+	"login.microsoftonline.us",
+	"login.chinacloudapi.cn",
+	"login.partner.microsoftonline.cn",
+}
+
+// signInMarkers are fragments of the sign-in page's HTML
+var signInMarkers = [][]byte{
+	[]byte("<title>Sign in to your account</title>"),
+	[]byte("aadcdn.msauth.net"),
+	[]byte("aadcdn.msftauth.net"),
+}
+
+// CheckSignInPage returns an error matching ErrSignInPage when the response
+// is a redirect to an Entra ID sign-in host, or a 2xx HTML page that was
+// served by one or looks like its sign-in page
+func (r *Response) CheckSignInPage() error {
+	if r.StatusCode >= 300 && r.StatusCode < 400 {
+		if location := r.Headers.Get("Location"); isSignInURL(location) {
+			return fmt.Errorf("%w: redirected (%d) to %s", ErrSignInPage, r.StatusCode, withoutQuery(location))
+		}
+		return nil
+	}
+	if !r.IsSuccessStatusCode() || !r.isHTML() {
+		return nil
+	}
+	if isSignInURL(r.URL) {
+		return fmt.Errorf("%w: redirected to %s", ErrSignInPage, withoutQuery(r.URL))
+	}
+	for _, marker := range signInMarkers {
+		if bytes.Contains(r.Body, marker) {
+			return fmt.Errorf("%w: the %d response is the sign-in HTML page", ErrSignInPage, r.StatusCode)
+		}
+	}
+	return nil
+}
+
+// isHTML reports whether the response is an HTML document
+func (r *Response) isHTML() bool {
+	if contentType := r.Headers.Get("Content-Type"); contentType != "" {
+		return strings.Contains(strings.ToLower(contentType), "text/html")
+	}
+	return http.DetectContentType(r.Body) == "text/html; charset=utf-8"
+}
+
+// isSignInURL reports whether rawURL points at an Entra ID sign-in host,
+// including Azure AD B2C tenants
+func isSignInURL(rawURL string) bool {
+	if rawURL == "" {
+		return false
+	}
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	host := strings.ToLower(parsed.Hostname())
+	for _, signInHost := range signInHosts {
+		if host == signInHost {
+			return true
+		}
+	}
+	return strings.HasSuffix(host, ".b2clogin.com")
+}
+
+// withoutQuery strips the query, which carries the redirect parameters, from
+// a sign-in URL
+func withoutQuery(rawURL string) string {
+	before, _, _ := strings.Cut(rawURL, "?")
+	return before
+}
@@ -0,0 +1,132 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const signInHTML = `<!DOCTYPE html><html><head><title>Sign in to your account</title></head><body></body></html>`
+
+func TestResponse_CheckSignInPage(t *testing.T) {
+	tests := []struct {
+		name     string
+		response Response
+		expected string
+	}{
+		{
+			name: "JSON API response",
+			response: Response{
+				StatusCode: http.StatusOK,
+				Headers:    http.Header{"Content-Type": {"application/json"}},
+				Body:       []byte(`{"title":"Sign in to your account"}`),
+				URL:        "https://api.example.com/users",
+			},
+		},
+		{
+			name: "HTML served by the sign-in host",
+			response: Response{
+				StatusCode: http.StatusOK,
+				Headers:    http.Header{"Content-Type": {"text/html; charset=utf-8"}},
+				Body:       []byte("<html></html>"),
+				URL:        "https://login.microsoftonline.com/tenant/oauth2/v2.0/authorize?client_id=abc&state=xyz",
+			},
+			expected: "redirected to https://login.microsoftonline.com/tenant/oauth2/v2.0/authorize",
+		},
+		{
+			name: "B2C sign-in host",
+			response: Response{
+				StatusCode: http.StatusOK,
+				Headers:    http.Header{"Content-Type": {"text/html"}},
+				URL:        "https://contoso.b2clogin.com/contoso.onmicrosoft.com/oauth2/v2.0/authorize",
+			},
+			expected: "redirected to https://contoso.b2clogin.com",
+		},
+		{
+			name: "sign-in page proxied by the API host",
+			response: Response{
+				StatusCode: http.StatusOK,
+				Headers:    http.Header{},
+				Body:       []byte(signInHTML),
+				URL:        "https://api.example.com/users",
+			},
+			expected: "the 200 response is the sign-in HTML page",
+		},
+		{
+			name: "unrelated HTML page",
+			response: Response{
+				StatusCode: http.StatusOK,
+				Headers:    http.Header{"Content-Type": {"text/html"}},
+				Body:       []byte("<html><title>Docs</title></html>"),
+				URL:        "https://api.example.com/docs",
+			},
+		},
+		{
+			name: "redirect to the sign-in host",
+			response: Response{
+				StatusCode: http.StatusFound,
+				Headers:    http.Header{"Location": {"https://login.microsoftonline.com/common/oauth2/authorize?redirect_uri=x"}},
+			},
+			expected: "redirected (302) to https://login.microsoftonline.com/common/oauth2/authorize",
+		},
+		{
+			name: "redirect elsewhere",
+			response: Response{
+				StatusCode: http.StatusMovedPermanently,
+				Headers:    http.Header{"Location": {"https://api.example.com/v2/users"}},
+			},
+		},
+		{
+			name: "error page",
+			response: Response{
+				StatusCode: http.StatusUnauthorized,
+				Headers:    http.Header{"Content-Type": {"text/html"}},
+				Body:       []byte(signInHTML),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.response.CheckSignInPage()
+			if tt.expected == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrSignInPage) {
+				t.Fatalf("Expected ErrSignInPage, got %v", err)
+			}
+			if !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("Expected error containing %q, got %v", tt.expected, err)
+			}
+			if strings.Contains(err.Error(), "client_id") || strings.Contains(err.Error(), "redirect_uri") {
+				t.Errorf("Expected the query to be stripped, got %v", err)
+			}
+		})
+	}
+}
+
+func TestSend_RecordsFinalURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/old" {
+			http.Redirect(w, r, "/new", http.StatusFound)
+			return
+		}
+		_, _ = io.WriteString(w, "ok")
+	}))
+	defer server.Close()
+
+	response, err := NewAPIClient().Send(context.Background(), &Request{Method: "GET", URL: server.URL + "/old"})
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if response.URL != server.URL+"/new" {
+		t.Errorf("Expected final URL %s/new, got %s", server.URL, response.URL)
+	}
+}
@@ -0,0 +1,98 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptrace"
+)
+
+// ErrHandshakeRejected reports a server that refused the TLS handshake
+// offered by a probe
+var ErrHandshakeRejected = errors.New("TLS handshake rejected")
+
+// TLSProbe restricts the TLS versions and cipher suites offered to a server,
+// to find out whether it accepts a configuration weaker than policy allows
+type TLSProbe struct {
+	// CipherSuites are the only TLS 1.0-1.2 suites offered; TLS 1.3 suites
+	// are not configurable. Empty offers the Go defaults.
+	CipherSuites []uint16
+	// MinVersion and MaxVersion bound the offered TLS versions; zero uses
+	// the endpoint's transport settings
+	MinVersion uint16
+	MaxVersion uint16
+}
+
+// ProbeTLS performs a TLS handshake with the server of rawURL through the
+// endpoint's transport settings, offering only what probe allows. It sends
+// an unauthenticated HEAD request over a fresh connection and returns the
+// negotiated connection state, or an error matching ErrHandshakeRejected
+// when the server refused the handshake.
+func (c *APIClient) ProbeTLS(ctx context.Context, rawURL string, opts *TransportOptions, probe TLSProbe) (*tls.ConnectionState, error) {
+	var transportOpts TransportOptions
+	if opts != nil {
+		transportOpts = *opts
+	}
+	transport, err := NewTransport(transportOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure transport: %w", err)
+	}
+	defer transport.CloseIdleConnections()
+
+	tlsConfig := &tls.Config{MinVersion: transportOpts.MinTLSVersion}
+	if transport.TLSClientConfig != nil {
+		tlsConfig = transport.TLSClientConfig.Clone()
+	}
+	if probe.MinVersion != 0 {
+		tlsConfig.MinVersion = probe.MinVersion // #nosec G402 -- probing whether the server accepts weak versions
+	}
+	if probe.MaxVersion != 0 {
+		tlsConfig.MaxVersion = probe.MaxVersion
+	}
+	if len(probe.CipherSuites) > 0 {
+		tlsConfig.CipherSuites = probe.CipherSuites
+	}
+	transport.TLSClientConfig = tlsConfig
+	transport.DisableKeepAlives = true
+
+	var (
+		state        tls.ConnectionState
+		handshakeErr error
+		handshaked   bool
+	)
+	trace := &httptrace.ClientTrace{
+		TLSHandshakeDone: func(connState tls.ConnectionState, err error) {
+			state, handshakeErr, handshaked = connState, err, true
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(httptrace.WithClientTrace(ctx, trace), c.timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, rawURL, http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := (&http.Client{Transport: transport}).Do(req)
+	if err == nil {
+		if closeErr := resp.Body.Close(); closeErr != nil {
+			_ = closeErr
+		}
+	}
+
+	var verifyErr *tls.CertificateVerificationError
+	switch {
+	case errors.As(handshakeErr, &verifyErr):
+		return nil, fmt.Errorf("failed to verify server certificate: %w", handshakeErr)
+	case handshaked && handshakeErr != nil:
+		return nil, fmt.Errorf("%w: %w", ErrHandshakeRejected, handshakeErr)
+	case handshaked:
+		return &state, nil
+	case err != nil:
+		return nil, fmt.Errorf("failed to connect: %w", err)
+	default:
+		return nil, fmt.Errorf("no TLS handshake with %s (not an https URL?)", rawURL)
+	}
+}
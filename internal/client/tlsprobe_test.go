@@ -0,0 +1,106 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestProbeTLS(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	server.TLS = &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		MaxVersion:   tls.VersionTLS12,
+		CipherSuites: []uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256, tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384},
+	}
+	server.StartTLS()
+	defer server.Close()
+
+	opts := &TransportOptions{CAFile: writeServerCA(t, server)}
+	client := NewAPIClientWithTimeout(5 * time.Second)
+
+	tests := []struct {
+		name          string
+		probe         TLSProbe
+		expectVersion uint16
+		expectCipher  uint16
+		expectReject  bool
+	}{
+		{
+			name:          "default handshake",
+			expectVersion: tls.VersionTLS12,
+		},
+		{
+			name:         "older version rejected",
+			probe:        TLSProbe{MinVersion: tls.VersionTLS10, MaxVersion: tls.VersionTLS11},
+			expectReject: true,
+		},
+		{
+			name:         "unsupported cipher rejected",
+			probe:        TLSProbe{MaxVersion: tls.VersionTLS12, CipherSuites: []uint16{tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256}},
+			expectReject: true,
+		},
+		{
+			name:          "supported cipher accepted",
+			probe:         TLSProbe{MaxVersion: tls.VersionTLS12, CipherSuites: []uint16{tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384}},
+			expectVersion: tls.VersionTLS12,
+			expectCipher:  tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state, err := client.ProbeTLS(context.Background(), server.URL, opts, tt.probe)
+			if tt.expectReject {
+				if !errors.Is(err, ErrHandshakeRejected) {
+					t.Errorf("Expected ErrHandshakeRejected, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if state.Version != tt.expectVersion {
+				t.Errorf("Expected version %#x, got %#x", tt.expectVersion, state.Version)
+			}
+			if tt.expectCipher != 0 && state.CipherSuite != tt.expectCipher {
+				t.Errorf("Expected cipher %#x, got %#x", tt.expectCipher, state.CipherSuite)
+			}
+		})
+	}
+}
+
+func TestProbeTLS_Errors(t *testing.T) {
+	tlsServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer tlsServer.Close()
+	plainServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer plainServer.Close()
+
+	client := NewAPIClientWithTimeout(5 * time.Second)
+	tests := []struct {
+		name string
+		url  string
+	}{
+		{"untrusted certificate", tlsServer.URL},
+		{"plain http", plainServer.URL},
+		{"connection refused", "https://127.0.0.1:1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.ProbeTLS(context.Background(), tt.url, nil, TLSProbe{})
+			if err == nil {
+				t.Fatal("Expected error, got nil")
+			}
+			if errors.Is(err, ErrHandshakeRejected) {
+				t.Errorf("Expected a probe error, not a rejection: %v", err)
+			}
+		})
+	}
+}
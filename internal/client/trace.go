@@ -0,0 +1,38 @@
+package client
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// TraceParentHeader is the W3C Trace Context header that carries the trace
+// of a request
+const TraceParentHeader = "traceparent"
+
+// newTraceParent returns a W3C traceparent header value for a new, sampled
+// trace and its trace ID
+func newTraceParent() (string, string) {
+	var ids [24]byte
+	_, _ = rand.Read(ids[:])
+	traceID := hex.EncodeToString(ids[:16])
+	parentID := hex.EncodeToString(ids[16:])
+	return "00-" + traceID + "-" + parentID + "-01", traceID
+}
+
+// CheckTraceEcho verifies that the response echoes the trace ID of its
+// request in header, e.g. traceresponse or a correlation ID header
+func (r *Response) CheckTraceEcho(header string) error {
+	if r.TraceID == "" {
+		return fmt.Errorf("request was not traced")
+	}
+	value := r.Headers.Get(header)
+	if value == "" {
+		return fmt.Errorf("response has no %s header (expected trace ID %s)", header, r.TraceID)
+	}
+	if !strings.Contains(strings.ToLower(value), r.TraceID) {
+		return fmt.Errorf("%s header %q does not contain trace ID %s", header, value, r.TraceID)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestSend_Trace(t *testing.T) {
+	var traceParents []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceParents = append(traceParents, r.Header.Get(TraceParentHeader))
+	}))
+	defer server.Close()
+
+	c := NewAPIClient()
+	untraced, err := c.Send(context.Background(), &Request{Method: http.MethodGet, URL: server.URL})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if traceParents[0] != "" || untraced.TraceID != "" {
+		t.Errorf("Expected no traceparent without Trace, got %q", traceParents[0])
+	}
+
+	var traceIDs []string
+	for range 2 {
+		response, err := c.Send(context.Background(), &Request{Method: http.MethodGet, URL: server.URL, Trace: true})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		traceIDs = append(traceIDs, response.TraceID)
+	}
+
+	format := regexp.MustCompile(`^00-[0-9a-f]{32}-[0-9a-f]{16}-01$`)
+	for i, traceParent := range traceParents[1:] {
+		if !format.MatchString(traceParent) {
+			t.Errorf("Expected a W3C traceparent, got %q", traceParent)
+		}
+		if !strings.HasPrefix(traceParent, "00-"+traceIDs[i]+"-") {
+			t.Errorf("Expected trace ID %s in %q", traceIDs[i], traceParent)
+		}
+	}
+	if traceIDs[0] == traceIDs[1] {
+		t.Errorf("Expected a new trace ID per request, got %s twice", traceIDs[0])
+	}
+}
+
+func TestResponse_CheckTraceEcho(t *testing.T) {
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	tests := []struct {
+		name        string
+		traceID     string
+		value       string
+		expectedErr string
+	}{
+		{name: "traceresponse", traceID: traceID, value: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+		{name: "upper case", traceID: traceID, value: "4BF92F3577B34DA6A3CE929D0E0E4736"},
+		{name: "missing", traceID: traceID, expectedErr: "response has no traceresponse header"},
+		{name: "different trace", traceID: traceID, value: "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01", expectedErr: "does not contain trace ID"},
+		{name: "not traced", value: "anything", expectedErr: "request was not traced"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := &Response{Headers: http.Header{}, TraceID: tt.traceID}
+			if tt.value != "" {
+				response.Headers.Set("traceresponse", tt.value)
+			}
+			err := response.CheckTraceEcho("traceresponse")
+			if tt.expectedErr == "" && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+			if tt.expectedErr != "" && (err == nil || !strings.Contains(err.Error(), tt.expectedErr)) {
+				t.Errorf("Expected error containing %q, got %v", tt.expectedErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"reflect"
+)
+
+// TransportOptions configures the connection used for a request. The zero
+// value uses the client's default transport, which honors the HTTP_PROXY,
+// HTTPS_PROXY and NO_PROXY environment variables.
+type TransportOptions struct {
+	// ProxyURL routes requests through the given proxy instead of the one
+	// selected by the environment
+	ProxyURL string
+	// CAFile is a PEM bundle trusted in addition to the system roots
+	CAFile string
+	// MinTLSVersion is the minimum TLS version (e.g. tls.VersionTLS13);
+	// zero uses the Go default
+	MinTLSVersion uint16
+	// ClientCertFile and ClientKeyFile are a PEM key pair presented to
+	// servers that require mutual TLS
+	ClientCertFile string
+	ClientKeyFile  string
+	// InsecureSkipVerify disables certificate verification entirely
+	InsecureSkipVerify bool
+	// Resolve maps host names to the IP addresses connections to them are
+	// made to
+	Resolve map[string]string
+	// IPVersion limits connections to IPv4 or IPv6; zero uses both
+	IPVersion int
+	// HTTPVersion forces HTTP1 or HTTP2; "" negotiates either
+	HTTPVersion string
+}
+
+// IsZero reports whether no transport options are set
+func (o *TransportOptions) IsZero() bool {
+	return o == nil || reflect.ValueOf(*o).IsZero()
+}
+
+// key identifies a distinct transport configuration for caching
+func (o *TransportOptions) key() string {
+	return fmt.Sprintf("%#v", *o)
+}
+
+// NewTransport builds an HTTP transport for the given options. It is also
+// used for the token acquisition path so both honor the same settings.
+func NewTransport(opts TransportOptions) (*http.Transport, error) {
+	defaultTransport, ok := http.DefaultTransport.(*http.Transport)
+	if !ok {
+		return nil, fmt.Errorf("unexpected default transport type %T", http.DefaultTransport)
+	}
+	transport := defaultTransport.Clone()
+
+	if opts.ProxyURL != "" {
+		proxyURL, err := url.Parse(opts.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy URL: %w", err)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+	if len(opts.Resolve) > 0 {
+		transport.DialContext = resolveDialer(opts.Resolve, transport.DialContext)
+	}
+	if err := setHTTPVersion(transport, opts.HTTPVersion); err != nil {
+		return nil, err
+	}
+	if opts.IPVersion != 0 {
+		transport.DialContext = ipVersionDialer(opts.IPVersion, transport.DialContext)
+	}
+
+	if opts.CAFile != "" || opts.ClientCertFile != "" || opts.MinTLSVersion != 0 || opts.InsecureSkipVerify {
+		tlsConfig := &tls.Config{
+			MinVersion:         opts.MinTLSVersion,
+			InsecureSkipVerify: opts.InsecureSkipVerify, // #nosec G402 -- explicit per-endpoint opt-in, reported as a warning
+		}
+		if opts.CAFile != "" {
+			pool, err := loadCAFile(opts.CAFile)
+			if err != nil {
+				return nil, err
+			}
+			tlsConfig.RootCAs = pool
+		}
+		if opts.ClientCertFile != "" {
+			certificate, err := tls.LoadX509KeyPair(opts.ClientCertFile, opts.ClientKeyFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load client certificate: %w", err)
+			}
+			tlsConfig.Certificates = []tls.Certificate{certificate}
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+	// Cache TLS sessions, so that new connections resume them as browsers
+	// and most HTTP clients do
+	if transport.TLSClientConfig == nil {
+		transport.TLSClientConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	transport.TLSClientConfig.ClientSessionCache = tls.NewLRUClientSessionCache(0)
+
+	return transport, nil
+}
+
+// loadCAFile returns the system cert pool extended with the certificates in
+// a PEM file, so private CAs are trusted for one endpoint without having to
+// install them machine-wide
+func loadCAFile(path string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(path) // #nosec G304 -- path comes from the user's config
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA file: %w", err)
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no PEM certificates found in CA file: %s", path)
+	}
+	return pool, nil
+}
+
+// clientFor returns the HTTP client to use for the given transport options,
+// building and caching one per distinct configuration. Clients injected via
+// NewAPIClientWithHTTPClient are always used as-is.
+func (c *APIClient) clientFor(opts *TransportOptions) (HTTPClient, error) {
+	if opts.IsZero() || !c.ownsTransport {
+		return c.httpClient, nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := opts.key()
+	if httpClient, ok := c.transports[key]; ok {
+		return httpClient, nil
+	}
+
+	transport, err := NewTransport(*opts)
+	if err != nil {
+		return nil, err
+	}
+	httpClient := &http.Client{Transport: transport, Timeout: c.timeout}
+	c.transports[key] = httpClient
+	return httpClient, nil
+}
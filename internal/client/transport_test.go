@@ -0,0 +1,345 @@
+package client
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestSend_ProxyURL(t *testing.T) {
+	var proxiedURL string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxiedURL = r.URL.String()
+		_, _ = io.WriteString(w, "via proxy")
+	}))
+	defer proxy.Close()
+
+	client := NewAPIClientWithTimeout(5 * time.Second)
+	resp, err := client.Send(context.Background(), &Request{
+		Method:      "GET",
+		URL:         "http://api.example.invalid/items",
+		AccessToken: "token",
+		Transport:   &TransportOptions{ProxyURL: proxy.URL},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := resp.GetBodyAsString(); got != "via proxy" {
+		t.Errorf("Expected response from proxy, got %q", got)
+	}
+	if proxiedURL != "http://api.example.invalid/items" {
+		t.Errorf("Expected proxy to receive the absolute target URL, got %q", proxiedURL)
+	}
+}
+
+func TestSend_SOCKS5ProxyURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "via tunnel")
+	}))
+	defer server.Close()
+	proxy, targets := startSOCKS5(t, server.Listener.Addr().String())
+
+	client := NewAPIClientWithTimeout(5 * time.Second)
+	resp, err := client.Send(context.Background(), &Request{
+		Method:    "GET",
+		URL:       "http://orders.restricted.invalid/health",
+		Transport: &TransportOptions{ProxyURL: "socks5://" + proxy},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := resp.GetBodyAsString(); got != "via tunnel" {
+		t.Errorf("Expected the response through the tunnel, got %q", got)
+	}
+	if target := <-targets; target != "orders.restricted.invalid:80" {
+		t.Errorf("Expected the proxy to resolve the host name, got CONNECT to %q", target)
+	}
+}
+
+// startSOCKS5 starts a SOCKS5 proxy without authentication that connects
+// every CONNECT to upstream, reporting the requested targets
+func startSOCKS5(t *testing.T, upstream string) (string, <-chan string) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+
+	targets := make(chan string, 10)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer func() { _ = conn.Close() }()
+				// Greeting: version, methods; choose no authentication
+				header := make([]byte, 2)
+				if _, err := io.ReadFull(conn, header); err != nil {
+					return
+				}
+				if _, err := io.ReadFull(conn, make([]byte, header[1])); err != nil {
+					return
+				}
+				_, _ = conn.Write([]byte{5, 0})
+
+				// Request: version, CONNECT, reserved, address type
+				request := make([]byte, 4)
+				if _, err := io.ReadFull(conn, request); err != nil {
+					return
+				}
+				var host string
+				switch request[3] {
+				case 1:
+					ip := make([]byte, 4)
+					_, _ = io.ReadFull(conn, ip)
+					host = net.IP(ip).String()
+				case 3:
+					length := make([]byte, 1)
+					_, _ = io.ReadFull(conn, length)
+					name := make([]byte, length[0])
+					_, _ = io.ReadFull(conn, name)
+					host = string(name)
+				default:
+					return
+				}
+				port := make([]byte, 2)
+				if _, err := io.ReadFull(conn, port); err != nil {
+					return
+				}
+				targets <- net.JoinHostPort(host, strconv.Itoa(int(port[0])<<8|int(port[1])))
+
+				backend, err := net.Dial("tcp", upstream)
+				if err != nil {
+					_, _ = conn.Write([]byte{5, 1, 0, 1, 0, 0, 0, 0, 0, 0})
+					return
+				}
+				defer func() { _ = backend.Close() }()
+				_, _ = conn.Write([]byte{5, 0, 0, 1, 0, 0, 0, 0, 0, 0})
+				go func() { _, _ = io.Copy(backend, conn) }()
+				_, _ = io.Copy(conn, backend)
+			}()
+		}
+	}()
+	return listener.Addr().String(), targets
+}
+
+func TestClientFor(t *testing.T) {
+	client := NewAPIClient()
+
+	defaultClient, err := client.clientFor(nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if defaultClient != client.httpClient {
+		t.Error("Expected nil options to use the default client")
+	}
+	if got, _ := client.clientFor(&TransportOptions{}); got != client.httpClient {
+		t.Error("Expected zero options to use the default client")
+	}
+
+	opts := &TransportOptions{ProxyURL: "http://proxy.example:8080"}
+	first, err := client.clientFor(opts)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	second, _ := client.clientFor(&TransportOptions{ProxyURL: "http://proxy.example:8080"})
+	if first != second {
+		t.Error("Expected identical options to reuse the cached client")
+	}
+	if first == client.httpClient {
+		t.Error("Expected proxy options to use a dedicated client")
+	}
+
+	if _, err := client.clientFor(&TransportOptions{ProxyURL: "http://[::1"}); err == nil {
+		t.Error("Expected error for invalid proxy URL, got nil")
+	}
+}
+
+func TestClientFor_InjectedClient(t *testing.T) {
+	mock := &MockHTTPClient{}
+	client := NewAPIClientWithHTTPClient(mock, time.Second)
+
+	got, err := client.clientFor(&TransportOptions{ProxyURL: "http://proxy.example:8080"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got != mock {
+		t.Error("Expected injected client to be used regardless of transport options")
+	}
+}
+
+func TestNewTransport(t *testing.T) {
+	transport, err := NewTransport(TransportOptions{ProxyURL: "http://proxy.example:8080"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "https://login.microsoftonline.com", nil)
+	proxyURL, err := transport.Proxy(req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if proxyURL == nil || proxyURL.Host != "proxy.example:8080" {
+		t.Errorf("Expected proxy.example:8080, got %v", proxyURL)
+	}
+}
+
+// writeServerCA writes the test server's certificate as a PEM CA bundle
+func writeServerCA(t *testing.T, server *httptest.Server) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	block := &pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}
+	if err := os.WriteFile(path, pem.EncodeToMemory(block), 0600); err != nil {
+		t.Fatalf("Failed to write CA file: %v", err)
+	}
+	return path
+}
+
+func TestSend_TLSOptions(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.TLS = &tls.Config{MaxVersion: tls.VersionTLS12}
+	server.StartTLS()
+	defer server.Close()
+
+	caFile := writeServerCA(t, server)
+
+	tests := []struct {
+		name      string
+		transport *TransportOptions
+		expectErr bool
+	}{
+		{"private CA untrusted by default", nil, true},
+		{"custom CA file", &TransportOptions{CAFile: caFile}, false},
+		{"insecure skip verify", &TransportOptions{InsecureSkipVerify: true}, false},
+		{"minimum version not offered", &TransportOptions{CAFile: caFile, MinTLSVersion: tls.VersionTLS13}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewAPIClientWithTimeout(5 * time.Second)
+			_, err := client.Send(context.Background(), &Request{
+				Method:      "GET",
+				URL:         server.URL,
+				AccessToken: "token",
+				Transport:   tt.transport,
+			})
+			if tt.expectErr && err == nil {
+				t.Error("Expected error, got nil")
+			}
+			if !tt.expectErr && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestNewTransport_InvalidCAFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "empty.pem")
+	if err := os.WriteFile(path, []byte("not a certificate"), 0600); err != nil {
+		t.Fatalf("Failed to write CA file: %v", err)
+	}
+
+	if _, err := NewTransport(TransportOptions{CAFile: path}); err == nil {
+		t.Error("Expected error for CA file without certificates, got nil")
+	}
+	if _, err := NewTransport(TransportOptions{CAFile: filepath.Join(t.TempDir(), "missing.pem")}); err == nil {
+		t.Error("Expected error for missing CA file, got nil")
+	}
+}
+
+// writeClientCertificate generates a self-signed client certificate and
+// writes it and its key as PEM files
+func writeClientCertificate(t *testing.T) (*x509.Certificate, string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "api-tester"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+	certificate, _ := x509.ParseCertificate(der)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("Failed to marshal key: %v", err)
+	}
+
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "client.pem")
+	keyFile := filepath.Join(dir, "client.key")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatalf("Failed to write certificate: %v", err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		t.Fatalf("Failed to write key: %v", err)
+	}
+	return certificate, certFile, keyFile
+}
+
+func TestSend_ClientCertificate(t *testing.T) {
+	certificate, certFile, keyFile := writeClientCertificate(t)
+
+	var presented string
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		presented = r.TLS.PeerCertificates[0].Subject.CommonName
+		w.WriteHeader(http.StatusOK)
+	}))
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(certificate)
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	server.StartTLS()
+	defer server.Close()
+
+	caFile := writeServerCA(t, server)
+	client := NewAPIClientWithTimeout(5 * time.Second)
+	request := &Request{Method: "GET", URL: server.URL, AccessToken: "token"}
+
+	request.Transport = &TransportOptions{CAFile: caFile}
+	if _, err := client.Send(context.Background(), request); err == nil {
+		t.Error("Expected handshake failure without a client certificate, got nil")
+	}
+
+	request.Transport = &TransportOptions{CAFile: caFile, ClientCertFile: certFile, ClientKeyFile: keyFile}
+	resp, err := client.Send(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK || presented != "api-tester" {
+		t.Errorf("Expected the client certificate to be presented, got status %d and CN %q", resp.StatusCode, presented)
+	}
+}
+
+func TestNewTransport_InvalidClientCertificate(t *testing.T) {
+	_, certFile, _ := writeClientCertificate(t)
+	if _, err := NewTransport(TransportOptions{ClientCertFile: certFile, ClientKeyFile: certFile}); err == nil {
+		t.Error("Expected error for mismatched key file, got nil")
+	}
+}
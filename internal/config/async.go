@@ -0,0 +1,22 @@
+package config
+
+import "fmt"
+
+// validateAsyncOperation checks the endpoint's asynchronous operation
+// settings. Polling applies to the endpoint's single request, so it cannot
+// be combined with the settings that send several.
+func (e *Endpoint) validateAsyncOperation() error {
+	if e.AsyncTimeoutMs < 0 {
+		return fmt.Errorf("asyncTimeoutMs must not be negative")
+	}
+	if !e.AsyncOperation {
+		if e.AsyncTimeoutMs > 0 {
+			return fmt.Errorf("asyncTimeoutMs requires asyncOperation")
+		}
+		return nil
+	}
+	if len(e.Personas) > 0 || len(e.Tenants) > 0 || len(e.Scopes) > 0 || e.DataFile != "" || len(e.AcceptLanguages) > 0 || e.RaceTest != nil {
+		return fmt.Errorf("asyncOperation cannot be combined with personas, tenants, scopes, dataFile, acceptLanguages or raceTest")
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package config
+
+import "testing"
+
+func TestEndpointValidate_AsyncOperation(t *testing.T) {
+	tests := []struct {
+		name      string
+		endpoint  Endpoint
+		expectErr string
+	}{
+		{name: "async", endpoint: Endpoint{AsyncOperation: true}},
+		{name: "async with timeout", endpoint: Endpoint{AsyncOperation: true, AsyncTimeoutMs: 60000}},
+		{name: "negative timeout", endpoint: Endpoint{AsyncOperation: true, AsyncTimeoutMs: -1}, expectErr: "asyncTimeoutMs must not be negative"},
+		{name: "timeout without async", endpoint: Endpoint{AsyncTimeoutMs: 60000}, expectErr: "asyncTimeoutMs requires asyncOperation"},
+		{
+			name:      "with a race test",
+			endpoint:  Endpoint{AsyncOperation: true, RaceTest: &RaceTest{Concurrency: 2}},
+			expectErr: "asyncOperation cannot be combined with personas, tenants, scopes, dataFile, acceptLanguages or raceTest",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			endpoint := tt.endpoint
+			endpoint.Name = "Deploy"
+			endpoint.URL = "https://management.example.com/deployments/1"
+			endpoint.Method = "PUT"
+			endpoint.StaticToken = "token"
+			endpoint.Scope = "scope"
+			err := endpoint.Validate()
+			if tt.expectErr == "" && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+			if tt.expectErr != "" && (err == nil || err.Error() != tt.expectErr) {
+				t.Errorf("Expected error %q, got %v", tt.expectErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package config
+
+import (
+	"fmt"
+	"mime"
+	"os"
+	"strings"
+)
+
+// Request body types
+const (
+	// BodyTypeJSON sends requestBody as JSON
+	BodyTypeJSON = "json"
+	// BodyTypeForm sends requestBody as application/x-www-form-urlencoded
+	// key/value pairs
+	BodyTypeForm = "form"
+)
+
+// MultipartPart is a field or file of a multipart/form-data request body
+type MultipartPart struct {
+	Name string `json:"name"`
+	// Value is the content of the part; placeholders are expanded
+	Value string `json:"value"`
+	// File is the path of a file to upload as the content of the part
+	File string `json:"file"`
+	// FileName is the file name sent with the part, which makes it a file
+	// upload; it defaults to the base name of File
+	FileName string `json:"fileName"`
+	// ContentType is the part's Content-Type; for a file it defaults to
+	// the type of its extension
+	ContentType string `json:"contentType"`
+}
+
+// GraphQL is a GraphQL operation sent as the JSON request body
+type GraphQL struct {
+	// Variables are the operation's variables; placeholders in their
+	// string values are expanded
+	Variables     map[string]interface{} `json:"variables"`
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+}
+
+// Validate checks if a GraphQL operation is valid
+func (g *GraphQL) Validate() error {
+	if strings.TrimSpace(g.Query) == "" {
+		return fmt.Errorf("query is required")
+	}
+	return nil
+}
+
+// Validate checks if a multipart part is valid
+func (p *MultipartPart) Validate() error {
+	if p.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if p.File != "" && p.Value != "" {
+		return fmt.Errorf("value and file are mutually exclusive")
+	}
+	if p.File != "" {
+		if _, err := os.Stat(p.File); err != nil {
+			return fmt.Errorf("file: %w", err)
+		}
+	}
+	if p.ContentType != "" {
+		if _, _, err := mime.ParseMediaType(p.ContentType); err != nil {
+			return fmt.Errorf("invalid contentType %q: %w", p.ContentType, err)
+		}
+	}
+	return nil
+}
+
+// sendsBody reports whether requests with method carry a body, as they do
+// for POST, PUT, PATCH and custom methods
+func sendsBody(method string) bool {
+	switch method {
+	case "GET", "HEAD", "OPTIONS", "DELETE":
+		return false
+	}
+	return true
+}
+
+// validateBodyType checks the endpoint's body type and that a form body
+// only holds values that can be encoded as form fields: strings, numbers,
+// booleans and arrays of them
+func (e *Endpoint) validateBodyType() error {
+	switch e.BodyType {
+	case "", BodyTypeJSON:
+		return nil
+	case BodyTypeForm:
+	default:
+		return fmt.Errorf("invalid bodyType: %s (must be json or form)", e.BodyType)
+	}
+	if len(e.Multipart) > 0 {
+		return fmt.Errorf("bodyType cannot be combined with multipart")
+	}
+	for key, value := range e.RequestBody {
+		values, isArray := value.([]interface{})
+		if !isArray {
+			values = []interface{}{value}
+		}
+		for _, v := range values {
+			switch v.(type) {
+			case string, float64, bool, int, nil:
+			default:
+				return fmt.Errorf("requestBody: form field %s must be a string, number, boolean or an array of them", key)
+			}
+		}
+	}
+	return nil
+}
+
+// validateGraphQL checks the endpoint's GraphQL operation, if any
+func (e *Endpoint) validateGraphQL() error {
+	if e.GraphQL == nil {
+		return nil
+	}
+	if e.RequestBody != nil || len(e.Multipart) > 0 || e.BodyType != "" {
+		return fmt.Errorf("graphql cannot be combined with requestBody, bodyType or multipart")
+	}
+	if e.Method != "POST" {
+		return fmt.Errorf("graphql requires the POST method (got %s)", e.Method)
+	}
+	if err := e.GraphQL.Validate(); err != nil {
+		return fmt.Errorf("graphql: %w", err)
+	}
+	return nil
+}
+
+// validateMultipart checks the endpoint's multipart body, if any
+func (e *Endpoint) validateMultipart() error {
+	if len(e.Multipart) == 0 {
+		return nil
+	}
+	if e.RequestBody != nil {
+		return fmt.Errorf("multipart cannot be combined with requestBody")
+	}
+	if !sendsBody(e.Method) {
+		return fmt.Errorf("multipart requires a method that sends a body, e.g. POST (got %s)", e.Method)
+	}
+	for i := range e.Multipart {
+		if err := e.Multipart[i].Validate(); err != nil {
+			return fmt.Errorf("multipart part %d: %w", i, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,197 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEndpointValidate_Multipart(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "report.pdf")
+	if err := os.WriteFile(file, []byte("%PDF-1.7"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		method      string
+		requestBody map[string]interface{}
+		parts       []MultipartPart
+		expectErr   string
+	}{
+		{
+			name:   "fields and files",
+			method: "POST",
+			parts: []MultipartPart{
+				{Name: "title", Value: "Q1"},
+				{Name: "metadata", Value: "{}", ContentType: "application/json"},
+				{Name: "inline", Value: "a,b", FileName: "inline.csv"},
+				{Name: "document", File: file, ContentType: "application/pdf"},
+			},
+		},
+		{
+			name:        "with requestBody",
+			method:      "POST",
+			requestBody: map[string]interface{}{"a": 1},
+			parts:       []MultipartPart{{Name: "title"}},
+			expectErr:   "multipart cannot be combined with requestBody",
+		},
+		{
+			name:      "GET",
+			method:    "GET",
+			parts:     []MultipartPart{{Name: "title"}},
+			expectErr: "multipart requires a method that sends a body, e.g. POST (got GET)",
+		},
+		{
+			name:      "missing name",
+			method:    "PUT",
+			parts:     []MultipartPart{{Value: "Q1"}},
+			expectErr: "multipart part 0: name is required",
+		},
+		{
+			name:      "value and file",
+			method:    "POST",
+			parts:     []MultipartPart{{Name: "document", Value: "x", File: file}},
+			expectErr: "multipart part 0: value and file are mutually exclusive",
+		},
+		{
+			name:      "missing file",
+			method:    "POST",
+			parts:     []MultipartPart{{Name: "document", File: file + ".missing"}},
+			expectErr: "multipart part 0: file: ",
+		},
+		{
+			name:      "invalid content type",
+			method:    "POST",
+			parts:     []MultipartPart{{Name: "title", ContentType: "not a type"}},
+			expectErr: `multipart part 0: invalid contentType "not a type"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			endpoint := Endpoint{
+				Name:        "Upload",
+				URL:         "https://api.example.com/documents",
+				Method:      tt.method,
+				StaticToken: "token",
+				Scope:       "scope",
+				RequestBody: tt.requestBody,
+				Multipart:   tt.parts,
+			}
+			err := endpoint.Validate()
+			if tt.expectErr == "" {
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.HasPrefix(err.Error(), tt.expectErr) {
+				t.Errorf("Expected error starting with %q, got %v", tt.expectErr, err)
+			}
+		})
+	}
+}
+
+func TestEndpointValidate_BodyType(t *testing.T) {
+	tests := []struct {
+		name        string
+		bodyType    string
+		requestBody map[string]interface{}
+		multipart   []MultipartPart
+		expectErr   string
+	}{
+		{name: "default", requestBody: map[string]interface{}{"a": map[string]interface{}{"b": 1}}},
+		{name: "json", bodyType: "json", requestBody: map[string]interface{}{"a": map[string]interface{}{"b": 1}}},
+		{
+			name:        "form",
+			bodyType:    "form",
+			requestBody: map[string]interface{}{"user": "ada", "quantity": 3.0, "express": true, "tags": []interface{}{"a", 1.0}, "note": nil},
+		},
+		{name: "unknown", bodyType: "xml", expectErr: "invalid bodyType: xml (must be json or form)"},
+		{
+			name:        "form with an object",
+			bodyType:    "form",
+			requestBody: map[string]interface{}{"address": map[string]interface{}{"city": "Berlin"}},
+			expectErr:   "requestBody: form field address must be a string, number, boolean or an array of them",
+		},
+		{
+			name:        "form with nested arrays",
+			bodyType:    "form",
+			requestBody: map[string]interface{}{"matrix": []interface{}{[]interface{}{1.0}}},
+			expectErr:   "requestBody: form field matrix must be a string, number, boolean or an array of them",
+		},
+		{
+			name:      "form with multipart",
+			bodyType:  "form",
+			multipart: []MultipartPart{{Name: "title"}},
+			expectErr: "bodyType cannot be combined with multipart",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			endpoint := Endpoint{
+				Name:        "Legacy",
+				URL:         "https://api.example.com/legacy",
+				Method:      "POST",
+				StaticToken: "token",
+				Scope:       "scope",
+				BodyType:    tt.bodyType,
+				RequestBody: tt.requestBody,
+				Multipart:   tt.multipart,
+			}
+			err := endpoint.Validate()
+			if tt.expectErr == "" && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+			if tt.expectErr != "" && (err == nil || err.Error() != tt.expectErr) {
+				t.Errorf("Expected error %q, got %v", tt.expectErr, err)
+			}
+		})
+	}
+}
+
+func TestEndpointValidate_GraphQL(t *testing.T) {
+	query := "{ me { id } }"
+	tests := []struct {
+		name      string
+		method    string
+		bodyType  string
+		graphQL   *GraphQL
+		expectErr string
+	}{
+		{name: "valid", method: "POST", graphQL: &GraphQL{Query: query, Variables: map[string]interface{}{"id": "42"}}},
+		{name: "missing query", method: "POST", graphQL: &GraphQL{OperationName: "Me"}, expectErr: "graphql: query is required"},
+		{name: "GET", method: "GET", graphQL: &GraphQL{Query: query}, expectErr: "graphql requires the POST method (got GET)"},
+		{
+			name:      "with bodyType",
+			method:    "POST",
+			bodyType:  "form",
+			graphQL:   &GraphQL{Query: query},
+			expectErr: "graphql cannot be combined with requestBody, bodyType or multipart",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			endpoint := Endpoint{
+				Name:        "GraphQL",
+				URL:         "https://api.example.com/graphql",
+				Method:      tt.method,
+				StaticToken: "token",
+				Scope:       "scope",
+				BodyType:    tt.bodyType,
+				GraphQL:     tt.graphQL,
+			}
+			err := endpoint.Validate()
+			if tt.expectErr == "" && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+			if tt.expectErr != "" && (err == nil || err.Error() != tt.expectErr) {
+				t.Errorf("Expected error %q, got %v", tt.expectErr, err)
+			}
+		})
+	}
+}
@@ -4,14 +4,33 @@
 package config
 
 import (
+	"bytes"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
+	"net"
+	"net/url"
 	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/hutstep/entra-id-api-tester/internal/dataset"
+	"github.com/hutstep/entra-id-api-tester/internal/expr"
 )
 
+// Methods are the HTTP methods endpoints can use without allowCustomMethod
+var Methods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS"}
+
+// methodToken matches a method or header name: an RFC 9110 token
+var methodToken = regexp.MustCompile("^[!#$%&'*+.^_`|~0-9A-Za-z-]+$")
+
 // Endpoint represents a single API endpoint to test
 type Endpoint struct {
-	RequestBody  map[string]interface{}
+	RequestBody map[string]interface{}
+	// BodyType is how RequestBody is encoded: "json" (the default) or
+	// "form" for application/x-www-form-urlencoded
+	BodyType     string
 	Name         string
 	URL          string
 	Method       string
@@ -19,30 +38,390 @@ type Endpoint struct {
 	ClientSecret string
 	TenantID     string
 	Scope        string
+	// ClientSecretSecondary is the app registration's other client secret
+	// while it is rotated, verified alongside clientSecret on request
+	ClientSecretSecondary string
+	// CredentialRef names a top-level credential to use instead of
+	// clientId, clientSecret and tenantId
+	CredentialRef string
+	// ApimSubscriptionKey is sent as the Ocp-Apim-Subscription-Key header,
+	// for APIs behind Azure API Management that require a subscription key
+	// in addition to the bearer token
+	ApimSubscriptionKey string
+	// StaticToken is sent instead of a token acquired from Entra ID, e.g. one
+	// minted by an on-behalf-of flow. The endpoint's own credentials are
+	// then optional.
+	StaticToken string
+	// ManagedIdentity acquires the token as the managed identity of the host
+	// the tester runs on instead of with client credentials. A clientId
+	// selects a user-assigned identity.
+	ManagedIdentity bool
+	// FederatedCredential acquires the token in exchange for a token of
+	// another identity provider the app registration trusts, instead of
+	// with a client secret: "github" for the OIDC token of the GitHub
+	// Actions job
+	FederatedCredential string
+	// CertificateKeyVault acquires the token with a certificate pulled from
+	// Azure Key Vault at runtime instead of with a client secret
+	CertificateKeyVault *CertificateKeyVault
+	// AzureRegion acquires the endpoint's tokens from the regional token
+	// endpoint (ESTS-R) of this Azure region, e.g. "westeurope", or of the
+	// region the tester runs in for "TryAutoDetect"
+	AzureRegion string
+	// Range is an optional Range header value (e.g. "bytes=0-1023"). When set,
+	// the response must be a valid 206 Partial Content for the requested bytes.
+	Range string
+	// CookieJar names a cookie jar shared by the endpoints that name it:
+	// cookies a response sets, e.g. gateway affinity or anti-CSRF cookies,
+	// are sent with the later requests of every endpoint in the jar
+	CookieJar string
+	// GoldenFile is a recorded response body an otherwise passing response
+	// must match, after normalization; -update-golden records it
+	GoldenFile string
+	// SaveResponseTo is a file each response body is written to, e.g. to
+	// keep a downloaded export as a build artifact
+	SaveResponseTo string
+	// ExpectedSHA256 is the hex SHA-256 digest the response body must
+	// have, to verify the integrity of a download
+	ExpectedSHA256 string
+	// DataFile is a CSV or JSON file the request is sent once per row of,
+	// with {{row.column}} placeholders filled in from the row
+	DataFile string
+	// Schedule is a cron expression the daemon command runs the endpoint
+	// on, instead of the configuration's schedule
+	Schedule string
+	// ProxyURL routes API requests through an explicit proxy instead of the
+	// one selected by HTTP_PROXY, HTTPS_PROXY and NO_PROXY
+	ProxyURL string
+	// TokenProxyURL routes token acquisition through an explicit proxy
+	TokenProxyURL string
+	// Resolve maps host names to the IP addresses the endpoint's requests
+	// connect to, like curl's --resolve, keeping the URL's Host header and
+	// SNI, e.g. to test a new instance or a blue/green slot by its address
+	Resolve map[string]string
+	// IPVersion limits the endpoint's connections to IPv4 (4) or IPv6 (6),
+	// e.g. to reproduce failures over one address family of a dual-stack
+	// host. Zero uses both and falls back to the -ip-version flag.
+	IPVersion int
+	// HTTPVersion forces the endpoint's requests to HTTP/1.1 ("1.1") or
+	// HTTP/2 ("2"), e.g. to prove that a front end serves HTTP/2 or that a
+	// backend behaves the same over HTTP/1.1. "" negotiates either.
+	HTTPVersion string
+	// TraceEchoHeader names a response header, e.g. traceresponse, that
+	// must contain the trace ID of the request's traceparent header.
+	// Setting it traces the endpoint's requests even without -trace.
+	TraceEchoHeader string
+	// TLS customizes certificate verification for API requests, e.g. to
+	// trust a private CA for this endpoint only
+	TLS *TLSConfig
+	// FollowRedirects, when false, returns redirect responses instead of
+	// following them, so that assertions can check the redirect itself.
+	// Redirects are followed by default.
+	FollowRedirects *bool
+	// MaxRedirects is how many redirects are followed before the request
+	// fails. Zero falls back to 10.
+	MaxRedirects int
+	// MaxThrottleRetries is how many times a 429 response is retried after
+	// honoring Retry-After. Unset falls back to the -max-throttle-retries
+	// flag, and zero disables retries.
+	MaxThrottleRetries *int
+	// SlowThresholdMs is how long a request may be in flight before live
+	// slow-request warnings are printed. Zero falls back to -slow-threshold.
+	SlowThresholdMs int
+	// MaxDurationMs is the response time objective: a request that takes
+	// longer fails the endpoint as a performance failure even when the
+	// response passes. Zero falls back to the -max-duration flag.
+	MaxDurationMs int
+	// DelayAfterMs is a pause after the endpoint ran, and between its
+	// -repeat runs, e.g. to stay below an API's throttling limit
+	DelayAfterMs int
+	// AsyncTimeoutMs is how long an asynchronous operation may take before
+	// it fails. Zero falls back to five minutes.
+	AsyncTimeoutMs int
+	// MaxBodyBytes caps how much of a response body is read, so that an
+	// endpoint streaming gigabytes cannot exhaust memory. Zero falls back
+	// to the -max-body-bytes flag.
+	MaxBodyBytes int64
+	// ExpectedContentLength is the size in bytes the response body must
+	// have; zero disables the check
+	ExpectedContentLength int
+	// WarmupRequests are sent, unchecked and untimed, before the measured
+	// request, so that cold starts do not count towards its duration
+	WarmupRequests int
+	// Captures extract values from the response into variables that later
+	// endpoints can reference as {{vars.name}} in their URL or body
+	Captures []Capture
+	// RaceTest, when set, replaces the single request with identical
+	// requests fired simultaneously
+	RaceTest *RaceTest
+	// Contract, when set, validates the response against the OpenAPI
+	// description of the operation
+	Contract *Contract
+	// Hooks run external commands that rewrite the request or judge the
+	// response
+	Hooks *Hooks
+	// GoldenIgnore names fields, at any depth of a JSON body, whose values
+	// vary between runs and are masked before comparing with GoldenFile
+	GoldenIgnore []string
+	// Assertions are evaluated against the response. Unless one of them
+	// checks the status code, the response must also be 2xx.
+	Assertions []Assertion
+	// DependsOn lists endpoint names that must run, and pass, first
+	DependsOn []string
+	// Latency are objectives such as "p95 < 500ms" on the endpoint's
+	// request durations across its -repeat runs
+	Latency []string
+	// AcceptLanguages repeats the request once per Accept-Language value
+	AcceptLanguages []string
+	// Personas repeats the request once per persona, each with its own
+	// token, and checks the status each persona receives. The endpoint's
+	// own credentials are then optional.
+	Personas []PersonaCheck
+	// Tenants repeats the request once per tenant ID, each with a token
+	// from that tenant, e.g. to test a multi-tenant API for every customer
+	// tenant. The endpoint's tenantId is then optional.
+	Tenants []string
+	// Scopes repeats the request once per scope, each with a token for
+	// that scope, and checks the status each receives. The endpoint's own
+	// scope is then optional.
+	Scopes []ScopeCheck
+	// ExpectLocalized maps a language from AcceptLanguages to text its
+	// response must contain. Without it, all responses must differ.
+	ExpectLocalized map[string]string
+	// GraphQL sends a GraphQL operation instead of requestBody. Responses
+	// with errors fail unless an assertion checks $.errors itself.
+	GraphQL *GraphQL
+	// Multipart sends a multipart/form-data body of fields and files
+	// instead of a JSON requestBody, e.g. to exercise upload endpoints
+	Multipart []MultipartPart
+	// NegativeAuth also sends the request without a token and with an
+	// invalid token, each of which must be rejected with 401 or 403
+	NegativeAuth bool
+	// Probe marks the endpoint as one of the subset the daemon command runs
+	// with -probe
+	Probe bool
+	// TamperedTokens also replays the acquired token with a truncated
+	// signature and as an expired, re-signed token, each of which must be
+	// rejected with 401 or 403
+	TamperedTokens bool
+	// AllowCustomMethod accepts any method name, e.g. PROPFIND or QUERY,
+	// instead of only the standard methods
+	AllowCustomMethod bool
+	// AsyncOperation polls the operation a 202 Accepted response points to,
+	// through its Azure-AsyncOperation, Operation-Location or Location
+	// header, until it completes, and checks the final response
+	AsyncOperation bool
+}
+
+// Assertion is a single response check or a combinator over other checks.
+// Exactly one of status, bodyContains, bodyEmpty, jsonPath, header, cel,
+// allOf, anyOf or not must be set.
+type Assertion struct {
+	// Equals is compared with the value selected by JSONPath, or with the
+	// values of Header
+	Equals interface{} `json:"equals"`
+	// Exists asserts that JSONPath does (true) or does not (false) resolve,
+	// or that Header is (not) present
+	Exists       *bool      `json:"exists"`
+	Not          *Assertion `json:"not"`
+	BodyContains string     `json:"bodyContains"`
+	JSONPath     string     `json:"jsonPath"`
+	// Header names a response header that must be present, equal Equals or
+	// match the regular expression Matches
+	Header  string `json:"header"`
+	Matches string `json:"matches"`
+	// CEL is an expression over response, json and duration that must
+	// evaluate to true
+	CEL       string      `json:"cel"`
+	AllOf     []Assertion `json:"allOf"`
+	AnyOf     []Assertion `json:"anyOf"`
+	Status    int         `json:"status"`
+	BodyEmpty bool        `json:"bodyEmpty"`
+}
+
+// maxRaceConcurrency is the most identical requests a race test fires at
+// once, which keeps a typo from flooding the API under test
+const maxRaceConcurrency = 50
+
+// RaceTest configures a concurrent duplicate-call test for an endpoint
+type RaceTest struct {
+	// Expect is "all" (every request must succeed, the default) or "one"
+	// (exactly one request may succeed, for create-with-unique-key APIs)
+	Expect      string `json:"expect"`
+	Concurrency int    `json:"concurrency"`
+}
+
+// Contract references the OpenAPI description an endpoint's responses must
+// conform to
+type Contract struct {
+	// Spec is the OpenAPI 3 or Swagger 2.0 description, in JSON or YAML
+	Spec string `json:"spec"`
+	// OperationID selects the operation. Without it, the operation is found
+	// by the request's method and URL path.
+	OperationID string `json:"operationId"`
+}
+
+// Validate checks if the contract settings are valid
+func (c *Contract) Validate() error {
+	if c.Spec == "" {
+		return fmt.Errorf("spec is required")
+	}
+	if _, err := os.Stat(c.Spec); err != nil {
+		return fmt.Errorf("spec: %w", err)
+	}
+	return nil
+}
+
+// TLSConfig configures TLS for an endpoint's API requests
+type TLSConfig struct {
+	// AllowedCiphers is the cipher suite policy, as Go/IANA names (e.g.
+	// "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"). The negotiated suite must be
+	// one of them and the server must reject the other TLS 1.0-1.2 suites.
+	AllowedCiphers []string `json:"allowedCiphers"`
+	// CAFile is a PEM bundle trusted in addition to the system roots
+	CAFile string `json:"caFile"`
+	// MinVersion is the minimum TLS version: "1.0", "1.1", "1.2" or "1.3"
+	MinVersion string `json:"minVersion"`
+	// RequireVersion is the TLS version policy: the negotiated version must
+	// be at least this and the server must reject handshakes below it
+	RequireVersion string `json:"requireVersion"`
+	// ClientCertFile and ClientKeyFile are a PEM certificate and private key
+	// presented to gateways that require mutual TLS, in addition to the
+	// bearer token
+	ClientCertFile string `json:"clientCertFile"`
+	ClientKeyFile  string `json:"clientKeyFile"`
+	// InsecureSkipVerify disables certificate verification. It is meant for
+	// short-lived troubleshooting only and is reported as a warning.
+	InsecureSkipVerify bool `json:"insecureSkipVerify"`
+}
+
+// tlsVersions maps minVersion values to crypto/tls constants
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// Validate checks if the TLS settings are valid
+func (t *TLSConfig) Validate() error {
+	if _, err := t.MinTLSVersion(); err != nil {
+		return err
+	}
+	if _, err := t.RequiredTLSVersion(); err != nil {
+		return err
+	}
+	if _, err := t.AllowedCipherSuites(); err != nil {
+		return err
+	}
+	if (t.ClientCertFile == "") != (t.ClientKeyFile == "") {
+		return fmt.Errorf("clientCertFile and clientKeyFile must be set together")
+	}
+	files := []struct{ name, path string }{
+		{"caFile", t.CAFile},
+		{"clientCertFile", t.ClientCertFile},
+		{"clientKeyFile", t.ClientKeyFile},
+	}
+	for _, file := range files {
+		if file.path == "" {
+			continue
+		}
+		if _, err := os.Stat(file.path); err != nil {
+			return fmt.Errorf("%s: %w", file.name, err)
+		}
+	}
+	return nil
+}
+
+// MinTLSVersion returns MinVersion as a crypto/tls constant, or zero when
+// it is not set
+func (t *TLSConfig) MinTLSVersion() (uint16, error) {
+	if t.MinVersion == "" {
+		return 0, nil
+	}
+	version, ok := tlsVersions[t.MinVersion]
+	if !ok {
+		return 0, fmt.Errorf("invalid minVersion: %s (must be 1.0, 1.1, 1.2 or 1.3)", t.MinVersion)
+	}
+	return version, nil
+}
+
+// RequiredTLSVersion returns RequireVersion as a crypto/tls constant, or
+// zero when no version policy is set
+func (t *TLSConfig) RequiredTLSVersion() (uint16, error) {
+	if t.RequireVersion == "" {
+		return 0, nil
+	}
+	version, ok := tlsVersions[t.RequireVersion]
+	if !ok {
+		return 0, fmt.Errorf("invalid requireVersion: %s (must be 1.0, 1.1, 1.2 or 1.3)", t.RequireVersion)
+	}
+	return version, nil
+}
+
+// AllowedCipherSuites returns AllowedCiphers as crypto/tls cipher suite IDs
+func (t *TLSConfig) AllowedCipherSuites() ([]uint16, error) {
+	if len(t.AllowedCiphers) == 0 {
+		return nil, nil
+	}
+	known := make(map[string]uint16)
+	for _, suites := range [][]*tls.CipherSuite{tls.CipherSuites(), tls.InsecureCipherSuites()} {
+		for _, suite := range suites {
+			known[suite.Name] = suite.ID
+		}
+	}
+
+	ids := make([]uint16, 0, len(t.AllowedCiphers))
+	for _, name := range t.AllowedCiphers {
+		id, ok := known[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown cipher suite in allowedCiphers: %s", name)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// HasPolicy reports whether a TLS version or cipher policy is set
+func (t *TLSConfig) HasPolicy() bool {
+	return t != nil && (t.RequireVersion != "" || len(t.AllowedCiphers) > 0)
+}
+
+// Capture extracts a single value from a response into a named variable
+type Capture struct {
+	Name     string `json:"name"`
+	JSONPath string `json:"jsonPath"`
+	Header   string `json:"header"`
 }
 
 // Config represents the complete configuration
 type Config struct {
-	Endpoints []Endpoint `json:"endpoints"`
+	// Notifications report failed runs to external systems
+	Notifications *Notifications `json:"notifications"`
+	// Credentials are named client credentials that endpoints reference
+	// with credentialRef
+	Credentials map[string]Credential `json:"credentials"`
+	Endpoints   []Endpoint            `json:"endpoints"`
+	// Personas are named credentials that endpoints can run as
+	Personas []Persona `json:"personas"`
+	// Schedule is the cron expression the daemon command runs endpoints
+	// without their own schedule on, e.g. "*/5 * * * *"
+	Schedule string `json:"schedule"`
+	// ScheduleJitterMs delays every scheduled run by a random time up to
+	// this long, so that many testers do not request tokens at once
+	ScheduleJitterMs int `json:"scheduleJitterMs"`
 }
 
-// LoadConfig loads the configuration from a JSON file
+// LoadConfig loads the configuration from a JSON file, or a YAML file with
+// a .yaml or .yml extension. A file may extend a
+// base configuration (see loadDocument), which is resolved first.
 func LoadConfig(filePath string) (*Config, error) {
-	// Open the file
-	file, err := os.Open(filePath) // #nosec G304 - file path is provided by user via CLI flag
+	config, err := decodeFile(filePath, false)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open config file: %w", err)
+		return nil, err
 	}
-	defer func() {
-		if closeErr := file.Close(); closeErr != nil {
-			err = fmt.Errorf("failed to close config file: %w", closeErr)
-		}
-	}()
-
-	var config Config
-	decoder := json.NewDecoder(file)
-	if err := decoder.Decode(&config); err != nil {
-		return nil, fmt.Errorf("failed to decode config file: %w", err)
+	if err := config.resolveCredentials(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
 	}
 
 	// Validate configuration
@@ -50,6 +429,30 @@ func LoadConfig(filePath string) (*Config, error) {
 		return nil, fmt.Errorf("invalid configuration: %w", err)
 	}
 
+	return config, nil
+}
+
+// decodeFile resolves the extends chain of the configuration file at
+// filePath and decodes it without validating. With strict set, fields the
+// configuration does not define are an error.
+func decodeFile(filePath string, strict bool) (*Config, error) {
+	document, err := loadDocument(filePath, nil)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(document)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode config file: %w", err)
+	}
+
+	var config Config
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if strict {
+		decoder.DisallowUnknownFields()
+	}
+	if err := decoder.Decode(&config); err != nil {
+		return nil, fmt.Errorf("failed to decode config file: %w", err)
+	}
 	return &config, nil
 }
 
@@ -65,9 +468,86 @@ func (c *Config) Validate() error {
 		}
 	}
 
+	if err := c.validatePersonas(); err != nil {
+		return err
+	}
+
+	if err := c.validateSchedules(); err != nil {
+		return err
+	}
+
+	if c.Notifications != nil {
+		if err := c.Notifications.Validate(); err != nil {
+			return fmt.Errorf("notifications: %w", err)
+		}
+	}
+
+	if _, err := c.ExecutionOrder(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
+// ExecutionOrder returns endpoint indexes ordered so that every endpoint runs
+// after the endpoints it depends on. Independent endpoints keep their
+// configuration order.
+func (c *Config) ExecutionOrder() ([]int, error) {
+	indexByName := make(map[string]int, len(c.Endpoints))
+	duplicates := make(map[string]bool)
+	for i := range c.Endpoints {
+		name := c.Endpoints[i].Name
+		if _, exists := indexByName[name]; exists {
+			duplicates[name] = true
+		}
+		indexByName[name] = i
+	}
+
+	// Build the dependency graph
+	pending := make([]int, len(c.Endpoints))
+	dependents := make([][]int, len(c.Endpoints))
+	for i := range c.Endpoints {
+		endpoint := &c.Endpoints[i]
+		for _, dependency := range endpoint.DependsOn {
+			j, exists := indexByName[dependency]
+			switch {
+			case !exists:
+				return nil, fmt.Errorf("endpoint %d (%s): depends on unknown endpoint %q", i, endpoint.Name, dependency)
+			case duplicates[dependency]:
+				return nil, fmt.Errorf("endpoint %d (%s): dependency %q is ambiguous (duplicate endpoint name)", i, endpoint.Name, dependency)
+			case j == i:
+				return nil, fmt.Errorf("endpoint %d (%s): cannot depend on itself", i, endpoint.Name)
+			}
+			pending[i]++
+			dependents[j] = append(dependents[j], i)
+		}
+	}
+
+	// Kahn's algorithm, always picking the earliest ready endpoint
+	order := make([]int, 0, len(c.Endpoints))
+	done := make([]bool, len(c.Endpoints))
+	for len(order) < len(c.Endpoints) {
+		next := -1
+		for i := range c.Endpoints {
+			if !done[i] && pending[i] == 0 {
+				next = i
+				break
+			}
+		}
+		if next < 0 {
+			return nil, fmt.Errorf("dependency cycle detected between endpoints")
+		}
+
+		done[next] = true
+		order = append(order, next)
+		for _, dependent := range dependents[next] {
+			pending[dependent]--
+		}
+	}
+
+	return order, nil
+}
+
 // Validate checks if an endpoint configuration is valid
 func (e *Endpoint) Validate() error {
 	if e.Name == "" {
@@ -81,12 +561,233 @@ func (e *Endpoint) Validate() error {
 	}
 
 	// Validate HTTP method
-	validMethods := map[string]bool{
-		"GET": true, "POST": true, "PUT": true,
-		"PATCH": true, "DELETE": true,
+	switch {
+	case slices.Contains(Methods, e.Method):
+	case e.AllowCustomMethod:
+		if !methodToken.MatchString(e.Method) {
+			return fmt.Errorf("invalid HTTP method: %q is not a valid method name", e.Method)
+		}
+	default:
+		return fmt.Errorf("invalid HTTP method: %s (must be GET, POST, PUT, PATCH, DELETE, HEAD or OPTIONS, or set allowCustomMethod)", e.Method)
+	}
+
+	if err := e.validateCredentials(); err != nil {
+		return err
+	}
+	if err := e.validateScopes(); err != nil {
+		return err
+	}
+	if e.MaxThrottleRetries != nil && *e.MaxThrottleRetries < 0 {
+		return fmt.Errorf("maxThrottleRetries must not be negative")
+	}
+	if e.SlowThresholdMs < 0 {
+		return fmt.Errorf("slowThresholdMs must not be negative")
+	}
+	if e.MaxDurationMs < 0 {
+		return fmt.Errorf("maxDurationMs must not be negative")
+	}
+	if e.DelayAfterMs < 0 {
+		return fmt.Errorf("delayAfterMs must not be negative")
+	}
+	if e.MaxRedirects < 0 {
+		return fmt.Errorf("maxRedirects must not be negative")
+	}
+	if e.MaxRedirects > 0 && !e.FollowsRedirects() {
+		return fmt.Errorf("maxRedirects cannot be combined with followRedirects: false")
+	}
+	if err := e.validateLatency(); err != nil {
+		return err
+	}
+	if err := e.validateAsyncOperation(); err != nil {
+		return err
+	}
+	if e.MaxBodyBytes < 0 {
+		return fmt.Errorf("maxBodyBytes must not be negative")
+	}
+	if e.WarmupRequests < 0 {
+		return fmt.Errorf("warmupRequests must not be negative")
+	}
+	if e.WarmupRequests > 0 && len(e.Personas) > 0 {
+		return fmt.Errorf("warmupRequests cannot be combined with personas")
+	}
+	if e.NegativeAuth && len(e.Personas) > 0 {
+		return fmt.Errorf("negativeAuth cannot be combined with personas")
+	}
+	if e.TamperedTokens && len(e.Personas) > 0 {
+		return fmt.Errorf("tamperedTokens cannot be combined with personas")
+	}
+	if err := e.validateTenants(); err != nil {
+		return err
+	}
+	if err := e.validateDataFile(); err != nil {
+		return err
+	}
+	if err := e.validateMultipart(); err != nil {
+		return err
+	}
+	if err := e.validateBodyType(); err != nil {
+		return err
+	}
+	if err := e.validateGraphQL(); err != nil {
+		return err
+	}
+	if err := e.validateDownload(); err != nil {
+		return err
+	}
+	if e.TraceEchoHeader != "" && !methodToken.MatchString(e.TraceEchoHeader) {
+		return fmt.Errorf("invalid traceEchoHeader: %q is not a valid header name", e.TraceEchoHeader)
+	}
+	if e.Range != "" && !strings.HasPrefix(e.Range, "bytes=") {
+		return fmt.Errorf("invalid range: %s (must use the bytes unit, e.g. bytes=0-1023)", e.Range)
+	}
+
+	if err := validateProxyURL(e.ProxyURL); err != nil {
+		return fmt.Errorf("proxyUrl: %w", err)
+	}
+	if err := validateProxyURL(e.TokenProxyURL); err != nil {
+		return fmt.Errorf("tokenProxyUrl: %w", err)
+	}
+	if e.IPVersion != 0 && e.IPVersion != 4 && e.IPVersion != 6 {
+		return fmt.Errorf("invalid ipVersion: %d (must be 4 or 6)", e.IPVersion)
+	}
+	if e.HTTPVersion != "" && e.HTTPVersion != "1.1" && e.HTTPVersion != "2" {
+		return fmt.Errorf("invalid httpVersion: %q (must be 1.1 or 2)", e.HTTPVersion)
+	}
+	if err := validateResolve(e.Resolve); err != nil {
+		return fmt.Errorf("resolve: %w", err)
+	}
+
+	if e.TLS != nil {
+		if err := e.TLS.Validate(); err != nil {
+			return fmt.Errorf("tls: %w", err)
+		}
+		if e.TLS.HasPolicy() && !strings.HasPrefix(strings.ToLower(e.URL), "https://") {
+			return fmt.Errorf("tls: requireVersion and allowedCiphers need an https URL")
+		}
+	}
+
+	if e.Contract != nil {
+		if err := e.Contract.Validate(); err != nil {
+			return fmt.Errorf("contract: %w", err)
+		}
+	}
+
+	if e.RaceTest != nil {
+		if err := e.RaceTest.Validate(); err != nil {
+			return fmt.Errorf("raceTest: %w", err)
+		}
+	}
+
+	if err := e.validateLocales(); err != nil {
+		return err
+	}
+
+	for i := range e.Assertions {
+		if err := e.Assertions[i].Validate(); err != nil {
+			return fmt.Errorf("assertion %d: %w", i, err)
+		}
+	}
+
+	if e.Hooks != nil {
+		if err := e.Hooks.Validate(); err != nil {
+			return fmt.Errorf("hooks: %w", err)
+		}
 	}
-	if !validMethods[e.Method] {
-		return fmt.Errorf("invalid HTTP method: %s (must be GET, POST, PUT, PATCH, or DELETE)", e.Method)
+
+	if len(e.GoldenIgnore) > 0 && e.GoldenFile == "" {
+		return fmt.Errorf("goldenIgnore requires goldenFile")
+	}
+	for i, field := range e.GoldenIgnore {
+		if field == "" {
+			return fmt.Errorf("goldenIgnore %d: field name is required", i)
+		}
+	}
+
+	for i := range e.Captures {
+		if err := e.Captures[i].Validate(); err != nil {
+			return fmt.Errorf("capture %d: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+// validateCredentials checks the endpoint's client credentials, which
+// personas, a static token, a managed identity, a federated credential and
+// a Key Vault certificate replace
+func (e *Endpoint) validateCredentials() error {
+	if e.AzureRegion != "" && (e.ManagedIdentity || e.FederatedCredential != "") {
+		return fmt.Errorf("azureRegion cannot be combined with managedIdentity or federatedCredential")
+	}
+	if e.CertificateKeyVault != nil {
+		if err := e.CertificateKeyVault.Validate(); err != nil {
+			return fmt.Errorf("certificateKeyVault: %w", err)
+		}
+		if e.ManagedIdentity || e.FederatedCredential != "" || e.StaticToken != "" || len(e.Personas) > 0 {
+			return fmt.Errorf("certificateKeyVault cannot be combined with managedIdentity, federatedCredential, staticToken or personas")
+		}
+		if e.ClientSecret != "" || e.ClientSecretSecondary != "" {
+			return fmt.Errorf("certificateKeyVault cannot be combined with clientSecret or clientSecretSecondary")
+		}
+		if e.ClientID == "" {
+			return fmt.Errorf("clientId is required")
+		}
+		if e.TenantID == "" && len(e.Tenants) == 0 {
+			return fmt.Errorf("tenantId is required")
+		}
+		return nil
+	}
+	if e.FederatedCredential != "" {
+		if e.FederatedCredential != FederatedCredentialGitHub {
+			return fmt.Errorf("invalid federatedCredential: %q (must be %q)", e.FederatedCredential, FederatedCredentialGitHub)
+		}
+		if e.ManagedIdentity || e.StaticToken != "" || len(e.Personas) > 0 {
+			return fmt.Errorf("federatedCredential cannot be combined with managedIdentity, staticToken or personas")
+		}
+		if e.ClientSecret != "" || e.ClientSecretSecondary != "" || e.CredentialRef != "" {
+			return fmt.Errorf("federatedCredential cannot be combined with clientSecret, clientSecretSecondary or credentialRef")
+		}
+		if e.ClientID == "" {
+			return fmt.Errorf("clientId is required")
+		}
+		if e.TenantID == "" && len(e.Tenants) == 0 {
+			return fmt.Errorf("tenantId is required")
+		}
+		return nil
+	}
+	if e.ManagedIdentity {
+		if e.StaticToken != "" || len(e.Personas) > 0 || len(e.Tenants) > 0 {
+			return fmt.Errorf("managedIdentity cannot be combined with staticToken, personas or tenants")
+		}
+		if e.ClientSecret != "" || e.ClientSecretSecondary != "" || e.CredentialRef != "" {
+			return fmt.Errorf("managedIdentity cannot be combined with clientSecret, clientSecretSecondary or credentialRef")
+		}
+		return nil
+	}
+	if e.ClientSecretSecondary != "" {
+		if len(e.Personas) > 0 || len(e.Tenants) > 0 || len(e.Scopes) > 0 {
+			return fmt.Errorf("clientSecretSecondary cannot be combined with personas, tenants or scopes")
+		}
+		if e.ClientSecret == "" {
+			return fmt.Errorf("clientSecretSecondary requires clientSecret")
+		}
+	}
+	if e.StaticToken != "" {
+		if len(e.Personas) > 0 || len(e.Tenants) > 0 || len(e.Scopes) > 0 {
+			return fmt.Errorf("staticToken cannot be combined with personas, tenants or scopes")
+		}
+		return nil
+	}
+	if len(e.Personas) > 0 {
+		if e.RaceTest != nil || len(e.AcceptLanguages) > 0 {
+			return fmt.Errorf("personas cannot be combined with raceTest or acceptLanguages")
+		}
+		for i := range e.Personas {
+			if err := e.Personas[i].Validate(); err != nil {
+				return fmt.Errorf("personas %d: %w", i, err)
+			}
+		}
+		return nil
 	}
 
 	if e.ClientID == "" {
@@ -95,12 +796,297 @@ func (e *Endpoint) Validate() error {
 	if e.ClientSecret == "" {
 		return fmt.Errorf("clientSecret is required")
 	}
-	if e.TenantID == "" {
+	if e.TenantID == "" && len(e.Tenants) == 0 {
 		return fmt.Errorf("tenantId is required")
 	}
-	if e.Scope == "" {
-		return fmt.Errorf("scope is required")
+	return nil
+}
+
+// TokenTenantID returns the tenant a single token for the endpoint is
+// acquired from: its tenantId, or else the first of its tenants
+func (e *Endpoint) TokenTenantID() string {
+	if e.TenantID == "" && len(e.Tenants) > 0 {
+		return e.Tenants[0]
+	}
+	return e.TenantID
+}
+
+// validateTenants checks the endpoint's tenant matrix, if any
+func (e *Endpoint) validateTenants() error {
+	if len(e.Tenants) == 0 {
+		return nil
+	}
+	if len(e.Personas) > 0 || e.RaceTest != nil || len(e.AcceptLanguages) > 0 {
+		return fmt.Errorf("tenants cannot be combined with personas, raceTest or acceptLanguages")
+	}
+	if e.WarmupRequests > 0 {
+		return fmt.Errorf("tenants cannot be combined with warmupRequests")
+	}
+	seen := make(map[string]bool, len(e.Tenants))
+	for i, tenant := range e.Tenants {
+		if tenant == "" {
+			return fmt.Errorf("tenants %d: tenant ID is required", i)
+		}
+		if seen[strings.ToLower(tenant)] {
+			return fmt.Errorf("tenants %d: duplicate tenant %s", i, tenant)
+		}
+		seen[strings.ToLower(tenant)] = true
 	}
+	return nil
+}
 
+// validateDataFile checks the endpoint's data file, if any
+func (e *Endpoint) validateDataFile() error {
+	if e.DataFile == "" {
+		return nil
+	}
+	if len(e.Personas) > 0 || len(e.Tenants) > 0 || len(e.Scopes) > 0 || e.RaceTest != nil || len(e.AcceptLanguages) > 0 {
+		return fmt.Errorf("dataFile cannot be combined with personas, tenants, scopes, raceTest or acceptLanguages")
+	}
+	if e.WarmupRequests > 0 {
+		return fmt.Errorf("dataFile cannot be combined with warmupRequests")
+	}
+	if err := dataset.CheckFormat(e.DataFile); err != nil {
+		return fmt.Errorf("dataFile: %w", err)
+	}
+	if _, err := os.Stat(e.DataFile); err != nil {
+		return fmt.Errorf("dataFile: %w", err)
+	}
 	return nil
 }
+
+// validateResolve checks that every host of a resolve mapping maps to an
+// IP address
+func validateResolve(resolve map[string]string) error {
+	for host, address := range resolve {
+		if host == "" || strings.ContainsAny(host, ":/ ") {
+			return fmt.Errorf("invalid host name %q (must be a bare host name, without scheme or port)", host)
+		}
+		if net.ParseIP(address) == nil {
+			return fmt.Errorf("invalid IP address %q for %s", address, host)
+		}
+	}
+	return nil
+}
+
+// validateProxyURL checks that an optional proxy URL is an absolute http,
+// https or SOCKS5 URL
+func validateProxyURL(raw string) error {
+	if raw == "" {
+		return nil
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("invalid URL: %w", err)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return fmt.Errorf("unsupported scheme %q (must be http, https, socks5 or socks5h)", u.Scheme)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("host is required: %s", raw)
+	}
+	return nil
+}
+
+// validateLocales checks the Accept-Language matrix settings
+func (e *Endpoint) validateLocales() error {
+	if len(e.AcceptLanguages) > 0 && e.RaceTest != nil {
+		return fmt.Errorf("acceptLanguages cannot be combined with raceTest")
+	}
+	if len(e.AcceptLanguages) == 1 && len(e.ExpectLocalized) == 0 {
+		return fmt.Errorf("acceptLanguages needs at least two languages unless expectLocalized is set")
+	}
+
+	seen := make(map[string]bool, len(e.AcceptLanguages))
+	for _, language := range e.AcceptLanguages {
+		if language == "" {
+			return fmt.Errorf("acceptLanguages must not contain empty values")
+		}
+		if seen[language] {
+			return fmt.Errorf("duplicate language in acceptLanguages: %s", language)
+		}
+		seen[language] = true
+	}
+
+	for language := range e.ExpectLocalized {
+		if !seen[language] {
+			return fmt.Errorf("expectLocalized language %s is not listed in acceptLanguages", language)
+		}
+	}
+
+	return nil
+}
+
+// Validate checks if a capture definition is valid
+func (c *Capture) Validate() error {
+	if c.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if (c.JSONPath == "") == (c.Header == "") {
+		return fmt.Errorf("exactly one of jsonPath or header is required")
+	}
+	if c.JSONPath != "" && !strings.HasPrefix(c.JSONPath, "$") {
+		return fmt.Errorf("jsonPath must start with $: %s", c.JSONPath)
+	}
+	return nil
+}
+
+// Validate checks if a race test definition is valid
+func (r *RaceTest) Validate() error {
+	if r.Concurrency < 2 {
+		return fmt.Errorf("concurrency must be at least 2")
+	}
+	if r.Concurrency > maxRaceConcurrency {
+		return fmt.Errorf("concurrency must be at most %d", maxRaceConcurrency)
+	}
+	if r.Expect != "" && r.Expect != "all" && r.Expect != "one" {
+		return fmt.Errorf("invalid expect: %s (must be all or one)", r.Expect)
+	}
+	return nil
+}
+
+// Validate checks if an assertion (and any nested assertions) is valid
+func (a *Assertion) Validate() error {
+	kinds := 0
+	for _, set := range []bool{
+		a.Status != 0, a.BodyContains != "", a.BodyEmpty, a.JSONPath != "", a.Header != "", a.CEL != "",
+		a.AllOf != nil, a.AnyOf != nil, a.Not != nil,
+	} {
+		if set {
+			kinds++
+		}
+	}
+	if kinds != 1 {
+		return fmt.Errorf("exactly one of status, bodyContains, bodyEmpty, jsonPath, header, cel, allOf, anyOf or not is required")
+	}
+
+	if a.JSONPath != "" && !strings.HasPrefix(a.JSONPath, "$") {
+		return fmt.Errorf("jsonPath must start with $: %s", a.JSONPath)
+	}
+	if a.JSONPath == "" && a.Header == "" && (a.Equals != nil || a.Exists != nil) {
+		return fmt.Errorf("equals and exists require jsonPath or header")
+	}
+	if a.Header == "" && a.Matches != "" {
+		return fmt.Errorf("matches requires header")
+	}
+	modifiers := 0
+	for _, set := range []bool{a.Equals != nil, a.Exists != nil, a.Matches != ""} {
+		if set {
+			modifiers++
+		}
+	}
+	if modifiers > 1 {
+		return fmt.Errorf("equals, exists and matches are mutually exclusive")
+	}
+	if a.Header != "" {
+		if _, ok := a.Equals.(string); a.Equals != nil && !ok {
+			return fmt.Errorf("equals of header %s must be a string", a.Header)
+		}
+		if _, err := regexp.Compile(a.Matches); err != nil {
+			return fmt.Errorf("invalid matches of header %s: %w", a.Header, err)
+		}
+	}
+	if a.CEL != "" {
+		if err := expr.Validate(a.CEL); err != nil {
+			return fmt.Errorf("cel: %w", err)
+		}
+	}
+
+	for _, group := range [][]Assertion{a.AllOf, a.AnyOf} {
+		if group != nil && len(group) == 0 {
+			return fmt.Errorf("allOf and anyOf must not be empty")
+		}
+		for i := range group {
+			if err := group[i].Validate(); err != nil {
+				return fmt.Errorf("[%d]: %w", i, err)
+			}
+		}
+	}
+	if a.Not != nil {
+		if err := a.Not.Validate(); err != nil {
+			return fmt.Errorf("not: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// ChecksStatus reports whether the assertion, or any nested assertion,
+// checks the response status code
+func (a *Assertion) ChecksStatus() bool {
+	if a.Status != 0 || (a.CEL != "" && expr.ReferencesStatus(a.CEL)) {
+		return true
+	}
+	if a.Not != nil && a.Not.ChecksStatus() {
+		return true
+	}
+	for _, group := range [][]Assertion{a.AllOf, a.AnyOf} {
+		for i := range group {
+			if group[i].ChecksStatus() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ThrottleRetries returns how many times the endpoint's 429 responses are
+// retried, zero when maxThrottleRetries is unset
+func (e *Endpoint) ThrottleRetries() int {
+	if e.MaxThrottleRetries == nil {
+		return 0
+	}
+	return *e.MaxThrottleRetries
+}
+
+// FollowsRedirects reports whether the endpoint's requests follow redirects
+func (e *Endpoint) FollowsRedirects() bool {
+	return e.FollowRedirects == nil || *e.FollowRedirects
+}
+
+// ReadsBody reports whether the assertion, or one nested in it, examines the
+// response body: anything but a status or header check
+func (a *Assertion) ReadsBody() bool {
+	if a.Status != 0 || a.Header != "" {
+		return false
+	}
+	if a.Not != nil {
+		return a.Not.ReadsBody()
+	}
+	if len(a.AllOf) > 0 || len(a.AnyOf) > 0 {
+		for _, group := range [][]Assertion{a.AllOf, a.AnyOf} {
+			for i := range group {
+				if group[i].ReadsBody() {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// ChecksGraphQLErrors reports whether the assertion, or one nested in it,
+// examines the errors of a GraphQL response: a jsonPath into $.errors or a
+// CEL expression that reads json.errors
+func (a *Assertion) ChecksGraphQLErrors() bool {
+	if a.JSONPath == "$.errors" || strings.HasPrefix(a.JSONPath, "$.errors.") || strings.HasPrefix(a.JSONPath, "$.errors[") {
+		return true
+	}
+	if a.CEL != "" && expr.ReferencesGraphQLErrors(a.CEL) {
+		return true
+	}
+	if a.Not != nil && a.Not.ChecksGraphQLErrors() {
+		return true
+	}
+	for _, group := range [][]Assertion{a.AllOf, a.AnyOf} {
+		for i := range group {
+			if group[i].ChecksGraphQLErrors() {
+				return true
+			}
+		}
+	}
+	return false
+}
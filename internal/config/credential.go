@@ -0,0 +1,157 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Federated credentials an endpoint can acquire its token with
+const (
+	// FederatedCredentialGitHub exchanges the OIDC token of the GitHub
+	// Actions job the tester runs in
+	FederatedCredentialGitHub = "github"
+)
+
+// Credential is a named app registration's client credentials. Endpoints
+// reference it with credentialRef instead of repeating the same clientId,
+// clientSecret and tenantId.
+type Credential struct {
+	ClientID     string `json:"clientId"`
+	ClientSecret string `json:"clientSecret"`
+	// ClientSecretSecondary is the app registration's other secret while
+	// it is being rotated
+	ClientSecretSecondary string `json:"clientSecretSecondary"`
+	TenantID              string `json:"tenantId"`
+	// AzureRegion is the region of the regional token endpoint to acquire
+	// the credential's tokens from; an endpoint's own azureRegion takes
+	// precedence
+	AzureRegion string `json:"azureRegion"`
+	// CertificateKeyVault authenticates with a certificate kept in Key
+	// Vault instead of a client secret
+	CertificateKeyVault *CertificateKeyVault `json:"certificateKeyVault"`
+}
+
+// CertificateKeyVault is a certificate in Azure Key Vault that signs the
+// client assertions of certificate authentication. It is pulled from the
+// vault at runtime, with the Azure credential of the host the tester runs
+// on, rather than deployed to disk.
+type CertificateKeyVault struct {
+	// Vault is the name of the vault, or its URL
+	Vault string `json:"vault"`
+	// Name is the name of the certificate in the vault
+	Name string `json:"name"`
+}
+
+// Validate checks if a Key Vault certificate reference is valid
+func (c *CertificateKeyVault) Validate() error {
+	if c.Vault == "" {
+		return fmt.Errorf("vault is required")
+	}
+	if strings.Contains(c.Vault, "://") {
+		parsed, err := url.Parse(c.Vault)
+		if err != nil || parsed.Scheme != "https" || parsed.Host == "" {
+			return fmt.Errorf("invalid vault: %q (must be a vault name or an https URL)", c.Vault)
+		}
+	}
+	if c.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	return nil
+}
+
+// Validate checks if a credential definition is valid
+func (c *Credential) Validate() error {
+	if c.ClientID == "" {
+		return fmt.Errorf("clientId is required")
+	}
+	if c.CertificateKeyVault != nil {
+		if c.ClientSecret != "" || c.ClientSecretSecondary != "" {
+			return fmt.Errorf("certificateKeyVault cannot be combined with clientSecret or clientSecretSecondary")
+		}
+		if err := c.CertificateKeyVault.Validate(); err != nil {
+			return fmt.Errorf("certificateKeyVault: %w", err)
+		}
+	} else if c.ClientSecret == "" {
+		return fmt.Errorf("clientSecret is required")
+	}
+	if c.TenantID == "" {
+		return fmt.Errorf("tenantId is required")
+	}
+	return nil
+}
+
+// validateCredentialDefinitions checks the top-level credentials in name
+// order, so that the first error reported does not depend on map order
+func (c *Config) validateCredentialDefinitions() error {
+	names := make([]string, 0, len(c.Credentials))
+	for name := range c.Credentials {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		credential := c.Credentials[name]
+		if err := credential.Validate(); err != nil {
+			return fmt.Errorf("credential %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// resolveCredentials validates the credential definitions and fills in the
+// client credentials of every endpoint that references one
+func (c *Config) resolveCredentials() error {
+	if err := c.validateCredentialDefinitions(); err != nil {
+		return err
+	}
+	for i := range c.Endpoints {
+		endpoint := &c.Endpoints[i]
+		if err := c.resolveCredential(endpoint); err != nil {
+			return fmt.Errorf("endpoint %d (%s): %w", i, endpoint.Name, err)
+		}
+	}
+	return nil
+}
+
+// resolveCredential copies the credential an endpoint references into its
+// clientId, clientSecret, clientSecretSecondary, certificateKeyVault and
+// tenantId, and into its azureRegion unless it has its own
+func (c *Config) resolveCredential(endpoint *Endpoint) error {
+	if endpoint.CredentialRef == "" {
+		return nil
+	}
+	credential, ok := c.Credentials[endpoint.CredentialRef]
+	if !ok {
+		return fmt.Errorf("unknown credential %q", endpoint.CredentialRef)
+	}
+	if endpoint.ClientID != "" || endpoint.ClientSecret != "" || endpoint.ClientSecretSecondary != "" || endpoint.CertificateKeyVault != nil || endpoint.TenantID != "" {
+		return fmt.Errorf("credentialRef cannot be combined with clientId, clientSecret, clientSecretSecondary, certificateKeyVault or tenantId")
+	}
+	endpoint.ClientID = credential.ClientID
+	endpoint.ClientSecret = credential.ClientSecret
+	endpoint.ClientSecretSecondary = credential.ClientSecretSecondary
+	endpoint.CertificateKeyVault = credential.CertificateKeyVault
+	endpoint.TenantID = credential.TenantID
+	if endpoint.AzureRegion == "" {
+		endpoint.AzureRegion = credential.AzureRegion
+	}
+	return nil
+}
+
+// Secrets returns every client secret in the configuration, so that they
+// can be kept out of output
+func (c *Config) Secrets() []string {
+	var secrets []string
+	for i := range c.Endpoints {
+		secrets = append(secrets, c.Endpoints[i].ClientSecret, c.Endpoints[i].ClientSecretSecondary, c.Endpoints[i].ApimSubscriptionKey, c.Endpoints[i].StaticToken)
+	}
+	for i := range c.Personas {
+		secrets = append(secrets, c.Personas[i].ClientSecret)
+	}
+	for _, credential := range c.Credentials {
+		secrets = append(secrets, credential.ClientSecret, credential.ClientSecretSecondary)
+	}
+	return secrets
+}
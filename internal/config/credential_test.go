@@ -0,0 +1,148 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfig_Credentials(t *testing.T) {
+	const credentials = `"credentials": {
+		"billing-app": {"clientId": "billing-id", "clientSecret": "billing-secret", "clientSecretSecondary": "billing-secondary", "tenantId": "tenant", "azureRegion": "westeurope"}
+	}`
+
+	tests := []struct {
+		name           string
+		config         string
+		expectedRegion string
+		expectedErr    string
+	}{
+		{
+			name: "resolved",
+			config: `{` + credentials + `, "endpoints": [
+				{"name": "Invoices", "url": "https://api.example.com/invoices", "method": "GET",
+				 "credentialRef": "billing-app", "scope": "api://billing/.default"}]}`,
+			expectedRegion: "westeurope",
+		},
+		{
+			name: "endpoint region",
+			config: `{` + credentials + `, "endpoints": [
+				{"name": "Invoices", "url": "https://api.example.com/invoices", "method": "GET",
+				 "credentialRef": "billing-app", "azureRegion": "northeurope", "scope": "api://billing/.default"}]}`,
+			expectedRegion: "northeurope",
+		},
+		{
+			name: "unknown credential",
+			config: `{` + credentials + `, "endpoints": [
+				{"name": "Invoices", "url": "https://api.example.com/invoices", "method": "GET",
+				 "credentialRef": "payroll-app", "scope": "api://billing/.default"}]}`,
+			expectedErr: `endpoint 0 (Invoices): unknown credential "payroll-app"`,
+		},
+		{
+			name: "combined with inline credentials",
+			config: `{` + credentials + `, "endpoints": [
+				{"name": "Invoices", "url": "https://api.example.com/invoices", "method": "GET",
+				 "credentialRef": "billing-app", "clientSecret": "other", "scope": "api://billing/.default"}]}`,
+			expectedErr: "credentialRef cannot be combined with clientId, clientSecret, clientSecretSecondary, certificateKeyVault or tenantId",
+		},
+		{
+			name: "combined with an inline secondary secret",
+			config: `{` + credentials + `, "endpoints": [
+				{"name": "Invoices", "url": "https://api.example.com/invoices", "method": "GET",
+				 "credentialRef": "billing-app", "clientSecretSecondary": "other", "scope": "api://billing/.default"}]}`,
+			expectedErr: "credentialRef cannot be combined with clientId, clientSecret, clientSecretSecondary, certificateKeyVault or tenantId",
+		},
+		{
+			name: "incomplete credential",
+			config: `{"credentials": {"billing-app": {"clientId": "billing-id", "tenantId": "tenant"}}, "endpoints": [
+				{"name": "Invoices", "url": "https://api.example.com/invoices", "method": "GET",
+				 "credentialRef": "billing-app", "scope": "api://billing/.default"}]}`,
+			expectedErr: "credential billing-app: clientSecret is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeConfigFiles(t, map[string]string{"config.json": tt.config})
+
+			cfg, err := LoadConfig(filepath.Join(dir, "config.json"))
+			if tt.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+					t.Fatalf("Expected error containing %q, got %v", tt.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadConfig() error = %v", err)
+			}
+
+			endpoint := cfg.Endpoints[0]
+			if endpoint.ClientID != "billing-id" || endpoint.ClientSecret != "billing-secret" || endpoint.ClientSecretSecondary != "billing-secondary" || endpoint.TenantID != "tenant" {
+				t.Errorf("Expected the billing-app credentials, got %s/%s/%s/%s", endpoint.ClientID, endpoint.ClientSecret, endpoint.ClientSecretSecondary, endpoint.TenantID)
+			}
+			if endpoint.AzureRegion != tt.expectedRegion {
+				t.Errorf("Expected azureRegion %q, got %q", tt.expectedRegion, endpoint.AzureRegion)
+			}
+		})
+	}
+}
+
+func TestLoadConfig_CredentialKeyVaultCertificate(t *testing.T) {
+	tests := []struct {
+		name        string
+		credential  string
+		expectedErr string
+	}{
+		{
+			name:       "resolved",
+			credential: `{"clientId": "billing-id", "tenantId": "tenant", "certificateKeyVault": {"vault": "billing-vault", "name": "signing"}}`,
+		},
+		{
+			name:        "with clientSecret",
+			credential:  `{"clientId": "billing-id", "clientSecret": "billing-secret", "tenantId": "tenant", "certificateKeyVault": {"vault": "billing-vault", "name": "signing"}}`,
+			expectedErr: "credential billing-app: certificateKeyVault cannot be combined with clientSecret or clientSecretSecondary",
+		},
+		{
+			name:        "without name",
+			credential:  `{"clientId": "billing-id", "tenantId": "tenant", "certificateKeyVault": {"vault": "billing-vault"}}`,
+			expectedErr: "credential billing-app: certificateKeyVault: name is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeConfigFiles(t, map[string]string{"config.json": `{"credentials": {"billing-app": ` + tt.credential + `}, "endpoints": [
+				{"name": "Invoices", "url": "https://api.example.com/invoices", "method": "GET",
+				 "credentialRef": "billing-app", "scope": "api://billing/.default"}]}`})
+
+			cfg, err := LoadConfig(filepath.Join(dir, "config.json"))
+			if tt.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+					t.Fatalf("Expected error containing %q, got %v", tt.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadConfig() error = %v", err)
+			}
+
+			certificate := cfg.Endpoints[0].CertificateKeyVault
+			if certificate == nil || certificate.Vault != "billing-vault" || certificate.Name != "signing" {
+				t.Errorf("Expected the billing-app certificate, got %+v", certificate)
+			}
+		})
+	}
+}
+
+func TestConfigSecrets(t *testing.T) {
+	cfg := &Config{
+		Credentials: map[string]Credential{"app": {ClientSecret: "credential-secret", ClientSecretSecondary: "credential-secondary"}},
+		Endpoints:   []Endpoint{{ClientSecret: "endpoint-secret", ClientSecretSecondary: "endpoint-secondary", ApimSubscriptionKey: "subscription-key", StaticToken: "static-token"}},
+		Personas:    []Persona{{ClientSecret: "persona-secret"}},
+	}
+
+	got := strings.Join(cfg.Secrets(), ",")
+	if got != "endpoint-secret,endpoint-secondary,subscription-key,static-token,persona-secret,credential-secret,credential-secondary" {
+		t.Errorf("Unexpected secrets: %s", got)
+	}
+}
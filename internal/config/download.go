@@ -0,0 +1,24 @@
+package config
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// validateDownload checks the endpoint's download verification settings
+func (e *Endpoint) validateDownload() error {
+	if e.ExpectedSHA256 != "" {
+		digest, err := hex.DecodeString(e.ExpectedSHA256)
+		if err != nil || len(digest) != 32 {
+			return fmt.Errorf("expectedSha256 must be 64 hexadecimal characters")
+		}
+	}
+	if e.ExpectedContentLength < 0 {
+		return fmt.Errorf("expectedContentLength must not be negative")
+	}
+	if e.SaveResponseTo != "" && strings.HasSuffix(e.SaveResponseTo, "/") {
+		return fmt.Errorf("saveResponseTo must be a file, not a directory: %s", e.SaveResponseTo)
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package config
+
+import "testing"
+
+func TestEndpointValidate_Download(t *testing.T) {
+	digest := "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+	tests := []struct {
+		name      string
+		endpoint  Endpoint
+		expectErr string
+	}{
+		{name: "valid", endpoint: Endpoint{SaveResponseTo: "artifacts/export.zip", ExpectedSHA256: digest, ExpectedContentLength: 4}},
+		{name: "uppercase digest", endpoint: Endpoint{ExpectedSHA256: "9F86D081884C7D659A2FEAA0C55AD015A3BF4F1B2B0B822CD15D6C15B0F00A08"}},
+		{name: "short digest", endpoint: Endpoint{ExpectedSHA256: digest[:40]}, expectErr: "expectedSha256 must be 64 hexadecimal characters"},
+		{name: "non-hex digest", endpoint: Endpoint{ExpectedSHA256: "z" + digest[1:]}, expectErr: "expectedSha256 must be 64 hexadecimal characters"},
+		{name: "negative length", endpoint: Endpoint{ExpectedContentLength: -1}, expectErr: "expectedContentLength must not be negative"},
+		{name: "directory", endpoint: Endpoint{SaveResponseTo: "artifacts/"}, expectErr: "saveResponseTo must be a file, not a directory: artifacts/"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			endpoint := tt.endpoint
+			endpoint.Name = "Export"
+			endpoint.URL = "https://api.example.com/export"
+			endpoint.Method = "GET"
+			endpoint.StaticToken = "token"
+			endpoint.Scope = "scope"
+			err := endpoint.Validate()
+			if tt.expectErr == "" && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+			if tt.expectErr != "" && (err == nil || err.Error() != tt.expectErr) {
+				t.Errorf("Expected error %q, got %v", tt.expectErr, err)
+			}
+		})
+	}
+}
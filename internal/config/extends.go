@@ -0,0 +1,215 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Keys with inheritance semantics in a configuration document
+const (
+	extendsKey = "extends"
+	removeKey  = "remove"
+	nameKey    = "name"
+)
+
+// namedLists are the top-level lists whose entries are merged by name with
+// the base configuration's entries instead of replacing the whole list
+var namedLists = []string{"endpoints", "personas"}
+
+// loadDocument reads the configuration document at filePath and resolves
+// its extends chain into a single document. chain holds the files already
+// being loaded, to detect cycles.
+func loadDocument(filePath string, chain []string) (map[string]interface{}, error) {
+	absPath, err := filepath.Abs(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve config path: %w", err)
+	}
+	if slices.Contains(chain, absPath) {
+		return nil, fmt.Errorf("extends cycle: %s", strings.Join(append(chain, absPath), " -> "))
+	}
+	chain = append(chain, absPath)
+
+	document, err := readDocument(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	rawBase, ok := document[extendsKey]
+	if !ok {
+		return document, nil
+	}
+	delete(document, extendsKey)
+	basePath, ok := rawBase.(string)
+	if !ok || basePath == "" {
+		return nil, fmt.Errorf("%s: extends must be a file path", filePath)
+	}
+	if !filepath.IsAbs(basePath) {
+		basePath = filepath.Join(filepath.Dir(filePath), basePath)
+	}
+
+	base, err := loadDocument(basePath, chain)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to load base configuration: %w", filePath, err)
+	}
+	if err := mergeDocument(base, document); err != nil {
+		return nil, fmt.Errorf("%s: %w", filePath, err)
+	}
+	return base, nil
+}
+
+// readDocument decodes a single configuration file without resolving
+// extends: YAML for a .yaml or .yml file, and JSON otherwise
+func readDocument(filePath string) (map[string]interface{}, error) {
+	file, err := os.Open(filePath) // #nosec G304 - file path is provided by user via CLI flag or extends
+	if err != nil {
+		return nil, fmt.Errorf("failed to open config file: %w", err)
+	}
+	defer func() {
+		if closeErr := file.Close(); closeErr != nil {
+			_ = closeErr
+		}
+	}()
+
+	var document map[string]interface{}
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".yaml", ".yml":
+		var raw interface{}
+		if err := yaml.NewDecoder(file).Decode(&raw); err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("failed to decode config file: %w", err)
+		}
+		document, _ = fromYAML(raw).(map[string]interface{})
+	default:
+		decoder := json.NewDecoder(file)
+		decoder.UseNumber()
+		if err := decoder.Decode(&document); err != nil {
+			return nil, fmt.Errorf("failed to decode config file: %w", err)
+		}
+	}
+	if document == nil {
+		return nil, fmt.Errorf("failed to decode config file: %s is not an object", filePath)
+	}
+	return document, nil
+}
+
+// fromYAML converts the map[interface{}]interface{} values YAML produces
+// for non-string keys into the map[string]interface{} of a JSON document
+func fromYAML(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = fromYAML(item)
+		}
+		return v
+	case map[interface{}]interface{}:
+		converted := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			converted[fmt.Sprint(key)] = fromYAML(item)
+		}
+		return converted
+	case []interface{}:
+		for i, item := range v {
+			v[i] = fromYAML(item)
+		}
+		return v
+	}
+	return value
+}
+
+// mergeDocument applies the overrides of a configuration document to its
+// base. Endpoints and personas are merged by name; entries with
+// "remove": true delete the base entry of that name.
+func mergeDocument(base, overrides map[string]interface{}) error {
+	for key, value := range overrides {
+		if value == nil {
+			delete(base, key)
+			continue
+		}
+		if !slices.Contains(namedLists, key) {
+			base[key] = mergeValue(base[key], value)
+			continue
+		}
+		merged, err := mergeNamedList(key, base[key], value)
+		if err != nil {
+			return err
+		}
+		base[key] = merged
+	}
+	return nil
+}
+
+// mergeValue merges override into base like a JSON merge patch (RFC 7396):
+// objects are merged recursively, null removes a field, and any other value
+// replaces the base value
+func mergeValue(base, override interface{}) interface{} {
+	overrideObject, ok := override.(map[string]interface{})
+	if !ok {
+		return override
+	}
+	baseObject, ok := base.(map[string]interface{})
+	if !ok {
+		baseObject = map[string]interface{}{}
+	}
+	for key, value := range overrideObject {
+		if value == nil {
+			delete(baseObject, key)
+			continue
+		}
+		baseObject[key] = mergeValue(baseObject[key], value)
+	}
+	return baseObject
+}
+
+// mergeNamedList merges a list of named entries into the base list: an
+// entry whose name exists in the base is merged into it, a new name is
+// appended, and "remove": true deletes the base entry
+func mergeNamedList(key string, base, overrides interface{}) ([]interface{}, error) {
+	baseList, ok := base.([]interface{})
+	if base != nil && !ok {
+		return nil, fmt.Errorf("%s must be a list", key)
+	}
+	overrideList, ok := overrides.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s must be a list", key)
+	}
+
+	merged := slices.Clone(baseList)
+	for i, rawEntry := range overrideList {
+		entry, ok := rawEntry.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%s[%d] must be an object", key, i)
+		}
+		name, ok := entry[nameKey].(string)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("%s[%d]: name is required", key, i)
+		}
+
+		index := slices.IndexFunc(merged, func(candidate interface{}) bool {
+			object, ok := candidate.(map[string]interface{})
+			return ok && object[nameKey] == name
+		})
+
+		if remove, ok := entry[removeKey].(bool); ok && remove {
+			if index < 0 {
+				return nil, fmt.Errorf("%s[%d]: cannot remove %q, the base configuration does not define it", key, i, name)
+			}
+			merged = slices.Delete(merged, index, index+1)
+			continue
+		}
+		delete(entry, removeKey)
+
+		if index < 0 {
+			merged = append(merged, entry)
+			continue
+		}
+		merged[index] = mergeValue(merged[index], entry)
+	}
+	return merged, nil
+}
@@ -0,0 +1,233 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const baseConfig = `{
+  "notifications": {"type": "webhook", "webhook": {"url": "https://hooks.example.com/base", "headers": {"X-Team": "api"}}},
+  "personas": [
+    {"name": "reader", "clientId": "reader-id", "clientSecret": "secret", "tenantId": "tenant"}
+  ],
+  "endpoints": [
+    {
+      "name": "Users",
+      "url": "https://api.example.com/users",
+      "method": "GET",
+      "clientId": "base-id",
+      "clientSecret": "base-secret",
+      "tenantId": "base-tenant",
+      "scope": "api://example/.default",
+      "tls": {"minVersion": "1.2"}
+    },
+    {
+      "name": "Orders",
+      "url": "https://api.example.com/orders",
+      "method": "GET",
+      "clientId": "base-id",
+      "clientSecret": "base-secret",
+      "tenantId": "base-tenant",
+      "scope": "api://example/.default"
+    }
+  ]
+}`
+
+func writeConfigFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestLoadConfig_Extends(t *testing.T) {
+	dir := writeConfigFiles(t, map[string]string{
+		"base/base.json": baseConfig,
+		"staging.json": `{
+  "extends": "base/base.json",
+  "notifications": {"webhook": {"url": "https://hooks.example.com/staging", "headers": null}},
+  "endpoints": [
+    {"name": "Users", "url": "https://staging.example.com/users", "tls": null},
+    {"name": "Orders", "remove": true},
+    {
+      "name": "Health",
+      "url": "https://staging.example.com/health",
+      "method": "GET",
+      "clientId": "staging-id",
+      "clientSecret": "staging-secret",
+      "tenantId": "staging-tenant",
+      "scope": "api://example/.default"
+    }
+  ]
+}`,
+	})
+
+	cfg, err := LoadConfig(filepath.Join(dir, "staging.json"))
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	if len(cfg.Endpoints) != 2 {
+		t.Fatalf("Expected 2 endpoints, got %d", len(cfg.Endpoints))
+	}
+	users := cfg.Endpoints[0]
+	if users.Name != "Users" || users.URL != "https://staging.example.com/users" {
+		t.Errorf("Expected overridden Users endpoint, got %s %s", users.Name, users.URL)
+	}
+	if users.ClientID != "base-id" || users.Scope != "api://example/.default" {
+		t.Errorf("Expected inherited credentials and scope, got %q %q", users.ClientID, users.Scope)
+	}
+	if users.TLS != nil {
+		t.Errorf("Expected tls to be removed, got %+v", users.TLS)
+	}
+	if cfg.Endpoints[1].Name != "Health" {
+		t.Errorf("Expected added Health endpoint, got %s", cfg.Endpoints[1].Name)
+	}
+
+	if len(cfg.Personas) != 1 || cfg.Personas[0].Name != "reader" {
+		t.Errorf("Expected inherited persona, got %+v", cfg.Personas)
+	}
+	webhook := cfg.Notifications.Webhook
+	if cfg.Notifications.Type != "webhook" || webhook.URL != "https://hooks.example.com/staging" || webhook.Headers != nil {
+		t.Errorf("Expected merged notifications, got %+v %+v", cfg.Notifications, webhook)
+	}
+}
+
+func TestLoadConfig_ExtendsChain(t *testing.T) {
+	dir := writeConfigFiles(t, map[string]string{
+		"base.json":  baseConfig,
+		"team.json":  `{"extends": "base.json", "endpoints": [{"name": "Orders", "method": "POST"}]}`,
+		"local.json": `{"extends": "team.json", "endpoints": [{"name": "Users", "remove": true}]}`,
+	})
+
+	cfg, err := LoadConfig(filepath.Join(dir, "local.json"))
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if len(cfg.Endpoints) != 1 || cfg.Endpoints[0].Name != "Orders" || cfg.Endpoints[0].Method != "POST" {
+		t.Errorf("Expected only the POST Orders endpoint, got %+v", cfg.Endpoints)
+	}
+}
+
+func TestLoadConfig_ExtendsYAML(t *testing.T) {
+	dir := writeConfigFiles(t, map[string]string{
+		"base-config.yaml": `
+personas:
+  - name: reader
+    clientId: reader-id
+    clientSecret: secret
+    tenantId: tenant
+endpoints:
+  - name: Users
+    url: https://api.example.com/users
+    method: GET
+    clientId: base-id
+    clientSecret: base-secret
+    tenantId: base-tenant
+    scope: api://example/.default
+    maxDurationMs: 2000
+  - name: Orders
+    url: https://api.example.com/orders
+    method: GET
+    clientId: base-id
+    clientSecret: base-secret
+    tenantId: base-tenant
+    scope: api://example/.default
+`,
+		"staging.yml": `
+extends: base-config.yaml
+endpoints:
+  - name: Users
+    url: https://staging.example.com/users
+  - name: Orders
+    remove: true
+`,
+	})
+
+	cfg, err := LoadConfig(filepath.Join(dir, "staging.yml"))
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if len(cfg.Endpoints) != 1 {
+		t.Fatalf("Expected 1 endpoint, got %d", len(cfg.Endpoints))
+	}
+	users := cfg.Endpoints[0]
+	if users.URL != "https://staging.example.com/users" || users.ClientID != "base-id" || users.MaxDurationMs != 2000 {
+		t.Errorf("Expected the overridden URL and the inherited credentials and duration limit, got %+v", users)
+	}
+	if len(cfg.Personas) != 1 || cfg.Personas[0].Name != "reader" {
+		t.Errorf("Expected inherited persona, got %+v", cfg.Personas)
+	}
+}
+
+func TestLoadConfig_ExtendsErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		expected string
+	}{
+		{
+			name:     "missing base",
+			files:    map[string]string{"config.json": `{"extends": "missing.json"}`},
+			expected: "failed to open config file",
+		},
+		{
+			name: "cycle",
+			files: map[string]string{
+				"config.json": `{"extends": "other.json"}`,
+				"other.json":  `{"extends": "config.json"}`,
+			},
+			expected: "extends cycle",
+		},
+		{
+			name:     "extends is not a path",
+			files:    map[string]string{"config.json": `{"extends": 1}`},
+			expected: "extends must be a file path",
+		},
+		{
+			name: "remove unknown endpoint",
+			files: map[string]string{
+				"base.json":   baseConfig,
+				"config.json": `{"extends": "base.json", "endpoints": [{"name": "Items", "remove": true}]}`,
+			},
+			expected: `cannot remove "Items"`,
+		},
+		{
+			name: "endpoint without name",
+			files: map[string]string{
+				"base.json":   baseConfig,
+				"config.json": `{"extends": "base.json", "endpoints": [{"url": "https://api.example.com"}]}`,
+			},
+			expected: "name is required",
+		},
+		{
+			name: "merged configuration is validated",
+			files: map[string]string{
+				"base.json":   baseConfig,
+				"config.json": `{"extends": "base.json", "endpoints": [{"name": "Users", "url": ""}]}`,
+			},
+			expected: "invalid configuration",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeConfigFiles(t, tt.files)
+
+			_, err := LoadConfig(filepath.Join(dir, "config.json"))
+			if err == nil || !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("Expected error containing %q, got %v", tt.expected, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package config
+
+import "fmt"
+
+// Hooks run external commands around an endpoint's request, for checks the
+// declarative configuration cannot express. Each command is an argument
+// list and runs without a shell.
+type Hooks struct {
+	// PreRequest reads the request as JSON on stdin and prints the request
+	// to send, e.g. with a computed signature or timestamp header
+	PreRequest []string `json:"preRequest"`
+	// PostResponse reads the response as JSON on stdin and prints a verdict
+	// and variables to capture
+	PostResponse []string `json:"postResponse"`
+	// TimeoutMs bounds each hook; zero means 10 seconds
+	TimeoutMs int `json:"timeoutMs"`
+}
+
+// Validate checks if the hooks are valid
+func (h *Hooks) Validate() error {
+	if len(h.PreRequest) == 0 && len(h.PostResponse) == 0 {
+		return fmt.Errorf("preRequest or postResponse is required")
+	}
+	for name, command := range map[string][]string{"preRequest": h.PreRequest, "postResponse": h.PostResponse} {
+		if len(command) > 0 && command[0] == "" {
+			return fmt.Errorf("%s: the command must not be empty", name)
+		}
+	}
+	if h.TimeoutMs < 0 {
+		return fmt.Errorf("timeoutMs must not be negative: %d", h.TimeoutMs)
+	}
+	return nil
+}
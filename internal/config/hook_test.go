@@ -0,0 +1,25 @@
+package config
+
+import "testing"
+
+func TestHooksValidate(t *testing.T) {
+	tests := []struct {
+		name      string
+		hooks     Hooks
+		expectErr bool
+	}{
+		{"pre-request", Hooks{PreRequest: []string{"./sign.sh", "--key", "k1"}}, false},
+		{"post-response with timeout", Hooks{PostResponse: []string{"python3", "check.py"}, TimeoutMs: 500}, false},
+		{"no hooks", Hooks{TimeoutMs: 500}, true},
+		{"empty command", Hooks{PostResponse: []string{""}}, true},
+		{"negative timeout", Hooks{PreRequest: []string{"./sign.sh"}, TimeoutMs: -1}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.hooks.Validate(); (err != nil) != tt.expectErr {
+				t.Errorf("Validate() error = %v, expectErr %v", err, tt.expectErr)
+			}
+		})
+	}
+}
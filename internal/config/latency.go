@@ -0,0 +1,65 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// latencyPattern matches a latency objective such as "p95 < 500ms"
+var latencyPattern = regexp.MustCompile(`^p(\d+(?:\.\d+)?)\s*<\s*(\S+)$`)
+
+// LatencyObjective requires a percentile of an endpoint's request
+// durations, across its repeated runs, to stay below a limit
+type LatencyObjective struct {
+	// Percentile is in (0, 100], e.g. 95 for p95
+	Percentile float64
+	Max        time.Duration
+}
+
+// String formats the objective as it is configured, e.g. "p95 < 500ms"
+func (o LatencyObjective) String() string {
+	return fmt.Sprintf("p%s < %v", strconv.FormatFloat(o.Percentile, 'f', -1, 64), o.Max)
+}
+
+// ParseLatencyObjective parses an objective of the form "p<percentile> <
+// <duration>", e.g. "p95 < 500ms" or "p99.9 < 2s"
+func ParseLatencyObjective(value string) (LatencyObjective, error) {
+	match := latencyPattern.FindStringSubmatch(strings.TrimSpace(value))
+	if match == nil {
+		return LatencyObjective{}, fmt.Errorf("invalid latency objective %q (expected e.g. \"p95 < 500ms\")", value)
+	}
+	percentile, err := strconv.ParseFloat(match[1], 64)
+	if err != nil || percentile <= 0 || percentile > 100 {
+		return LatencyObjective{}, fmt.Errorf("invalid latency objective %q: percentile must be between 0 and 100", value)
+	}
+	limit, err := time.ParseDuration(match[2])
+	if err != nil || limit <= 0 {
+		return LatencyObjective{}, fmt.Errorf("invalid latency objective %q: %q is not a positive duration", value, match[2])
+	}
+	return LatencyObjective{Percentile: percentile, Max: limit}, nil
+}
+
+// LatencyObjectives returns the endpoint's parsed latency objectives. The
+// endpoint must be valid.
+func (e *Endpoint) LatencyObjectives() []LatencyObjective {
+	objectives := make([]LatencyObjective, 0, len(e.Latency))
+	for _, value := range e.Latency {
+		if objective, err := ParseLatencyObjective(value); err == nil {
+			objectives = append(objectives, objective)
+		}
+	}
+	return objectives
+}
+
+// validateLatency checks the endpoint's latency objectives
+func (e *Endpoint) validateLatency() error {
+	for i, value := range e.Latency {
+		if _, err := ParseLatencyObjective(value); err != nil {
+			return fmt.Errorf("latency %d: %w", i, err)
+		}
+	}
+	return nil
+}
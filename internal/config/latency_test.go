@@ -0,0 +1,47 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseLatencyObjective(t *testing.T) {
+	tests := []struct {
+		value     string
+		expected  LatencyObjective
+		expectErr string
+	}{
+		{value: "p95 < 500ms", expected: LatencyObjective{Percentile: 95, Max: 500 * time.Millisecond}},
+		{value: "p99.9<2s", expected: LatencyObjective{Percentile: 99.9, Max: 2 * time.Second}},
+		{value: "p100 < 1s", expected: LatencyObjective{Percentile: 100, Max: time.Second}},
+		{value: "95 < 500ms", expectErr: `invalid latency objective "95 < 500ms" (expected e.g. "p95 < 500ms")`},
+		{value: "p0 < 500ms", expectErr: `invalid latency objective "p0 < 500ms": percentile must be between 0 and 100`},
+		{value: "p101 < 500ms", expectErr: `invalid latency objective "p101 < 500ms": percentile must be between 0 and 100`},
+		{value: "p95 < 500", expectErr: `invalid latency objective "p95 < 500": "500" is not a positive duration`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			objective, err := ParseLatencyObjective(tt.value)
+			if tt.expectErr != "" {
+				if err == nil || err.Error() != tt.expectErr {
+					t.Errorf("Expected error %q, got %v", tt.expectErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if objective != tt.expected {
+				t.Errorf("Expected %+v, got %+v", tt.expected, objective)
+			}
+		})
+	}
+}
+
+func TestLatencyObjective_String(t *testing.T) {
+	objective := LatencyObjective{Percentile: 99.9, Max: 1500 * time.Millisecond}
+	if got := objective.String(); got != "p99.9 < 1.5s" {
+		t.Errorf("Expected %q, got %q", "p99.9 < 1.5s", got)
+	}
+}
@@ -0,0 +1,285 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/hutstep/entra-id-api-tester/internal/dataset"
+	"github.com/hutstep/entra-id-api-tester/internal/openapi"
+	"github.com/hutstep/entra-id-api-tester/internal/vars"
+)
+
+// Severity is how serious a lint problem is
+type Severity string
+
+// Lint problem severities. Only errors make a configuration invalid.
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Problem is a single issue found by Lint
+type Problem struct {
+	Severity Severity
+	// Location is where the problem is, e.g. "endpoint 2 (users)", or empty
+	// for the file as a whole
+	Location string
+	Message  string
+}
+
+// String formats the problem as "location: message"
+func (p Problem) String() string {
+	if p.Location == "" {
+		return p.Message
+	}
+	return p.Location + ": " + p.Message
+}
+
+// Lint checks the configuration file at filePath without making network
+// calls. Unlike LoadConfig, which stops at the first error, it reports every
+// problem it finds: unknown fields, invalid endpoints and URLs, duplicate
+// names, {{vars.name}} placeholders no earlier endpoint captures,
+// {{row.column}} placeholders the data file lacks and missing files.
+func Lint(filePath string) []Problem {
+	var problems []Problem
+	config, err := decodeFile(filePath, true)
+	if err != nil {
+		// Keep linting past unknown fields; any other error is fatal
+		problems = append(problems, Problem{Severity: SeverityError, Message: err.Error()})
+		if config, err = decodeFile(filePath, false); err != nil {
+			return problems
+		}
+	}
+
+	if len(config.Endpoints) == 0 {
+		problems = append(problems, Problem{Severity: SeverityError, Message: "no endpoints defined in configuration"})
+	}
+
+	if err := config.validateCredentialDefinitions(); err != nil {
+		problems = append(problems, Problem{Severity: SeverityError, Message: err.Error()})
+	}
+
+	seen := make(map[string]int, len(config.Endpoints))
+	for i := range config.Endpoints {
+		endpoint := &config.Endpoints[i]
+		location := fmt.Sprintf("endpoint %d (%s)", i, endpoint.Name)
+		report := func(severity Severity, format string, args ...interface{}) {
+			problems = append(problems, Problem{Severity: severity, Location: location, Message: fmt.Sprintf(format, args...)})
+		}
+
+		if err := config.resolveCredential(endpoint); err != nil {
+			report(SeverityError, "%v", err)
+		} else if err := endpoint.Validate(); err != nil {
+			report(SeverityError, "%v", err)
+		}
+		if err := lintURL(endpoint.URL); err != nil {
+			report(SeverityError, "url: %v", err)
+		}
+		if first, exists := seen[endpoint.Name]; exists && endpoint.Name != "" {
+			report(SeverityError, "duplicate endpoint name (also endpoint %d)", first)
+		} else {
+			seen[endpoint.Name] = i
+		}
+		if endpoint.TLS != nil && endpoint.TLS.InsecureSkipVerify {
+			report(SeverityWarning, "tls.insecureSkipVerify disables certificate verification")
+		}
+		if err := endpoint.lintContract(); err != nil {
+			report(SeverityError, "contract: %v", err)
+		}
+		if _, err := endpoint.lintDataFile(); err != nil {
+			report(SeverityError, "dataFile: %v", err)
+		}
+		if endpoint.GoldenFile != "" {
+			if _, err := os.Stat(endpoint.GoldenFile); errors.Is(err, fs.ErrNotExist) {
+				report(SeverityWarning, "goldenFile %s does not exist yet (record it with -update-golden)", endpoint.GoldenFile)
+			}
+		}
+	}
+
+	problems = append(problems, config.lintVariables()...)
+
+	if err := config.validatePersonas(); err != nil {
+		problems = append(problems, Problem{Severity: SeverityError, Message: err.Error()})
+	}
+	if config.Notifications != nil {
+		if err := config.Notifications.Validate(); err != nil {
+			problems = append(problems, Problem{Severity: SeverityError, Location: "notifications", Message: err.Error()})
+		}
+	}
+	if _, err := config.ExecutionOrder(); err != nil {
+		problems = append(problems, Problem{Severity: SeverityError, Message: err.Error()})
+	}
+
+	return problems
+}
+
+// lintURL checks that an endpoint URL is an absolute http or https URL once
+// its placeholders are filled in
+func lintURL(raw string) error {
+	if raw == "" {
+		return nil
+	}
+	parsed, err := url.Parse(withoutPlaceholders(raw))
+	if err != nil {
+		return err
+	}
+	if (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("%s is not an absolute http or https URL", raw)
+	}
+	return nil
+}
+
+// lintContract checks that the endpoint's OpenAPI description can be read
+// and describes the endpoint's operation
+func (e *Endpoint) lintContract() error {
+	if e.Contract == nil || e.Contract.Validate() != nil {
+		return nil
+	}
+	spec, err := openapi.Load(e.Contract.Spec)
+	if err != nil {
+		return err
+	}
+	_, err = spec.FindOperation(e.Method, withoutPlaceholders(e.URL), e.Contract.OperationID)
+	return err
+}
+
+// lintDataFile reads the endpoint's data file, if any, and returns its
+// columns. A file that Validate already rejects is not read.
+func (e *Endpoint) lintDataFile() ([]string, error) {
+	if e.DataFile == "" || e.validateDataFile() != nil {
+		return nil, nil
+	}
+	data, err := dataset.Load(e.DataFile)
+	if err != nil {
+		return nil, err
+	}
+	return data.Columns, nil
+}
+
+// withoutPlaceholders replaces each {{ expression }} in input with a value
+// that is valid anywhere in a URL
+func withoutPlaceholders(input string) string {
+	for {
+		start := strings.Index(input, "{{")
+		if start < 0 {
+			return input
+		}
+		end := strings.Index(input[start:], "}}")
+		if end < 0 {
+			return input
+		}
+		input = input[:start] + "placeholder" + input[start+end+2:]
+	}
+}
+
+// lintVariables checks that every {{vars.name}} placeholder refers to a
+// variable captured by one of the endpoint's dependencies. A variable
+// captured by an endpoint that is not a dependency only resolves if that
+// endpoint happens to run first, so it is a warning. Every {{row.column}}
+// placeholder must name a column of the endpoint's data file.
+func (c *Config) lintVariables() []Problem {
+	capturedBy := make(map[string][]string)
+	for i := range c.Endpoints {
+		for _, capture := range c.Endpoints[i].Captures {
+			capturedBy[capture.Name] = append(capturedBy[capture.Name], c.Endpoints[i].Name)
+		}
+	}
+
+	var problems []Problem
+	for i := range c.Endpoints {
+		endpoint := &c.Endpoints[i]
+		location := fmt.Sprintf("endpoint %d (%s)", i, endpoint.Name)
+		dependencies := c.dependencies(endpoint)
+		columns, _ := endpoint.lintDataFile()
+
+		var reported []string
+		for _, expression := range endpointPlaceholders(endpoint) {
+			if slices.Contains(reported, expression) {
+				continue
+			}
+			reported = append(reported, expression)
+
+			name, found := strings.CutPrefix(expression, "vars.")
+			column, isRow := strings.CutPrefix(expression, "row.")
+			switch {
+			case isRow && endpoint.DataFile == "":
+				problems = append(problems, Problem{Severity: SeverityError, Location: location,
+					Message: fmt.Sprintf("{{%s}} needs a dataFile", expression)})
+			case isRow:
+				if columns != nil && !slices.Contains(columns, column) {
+					problems = append(problems, Problem{Severity: SeverityError, Location: location,
+						Message: fmt.Sprintf("{{%s}}: %s has no column %s", expression, endpoint.DataFile, column)})
+				}
+			case !found:
+				if err := vars.CheckFunction(expression); err != nil {
+					problems = append(problems, Problem{Severity: SeverityError, Location: location, Message: err.Error()})
+				}
+			case len(capturedBy[name]) == 0:
+				problems = append(problems, Problem{Severity: SeverityError, Location: location,
+					Message: fmt.Sprintf("{{%s}} is not captured by any endpoint", expression)})
+			case !slices.ContainsFunc(capturedBy[name], func(capturer string) bool { return dependencies[capturer] }):
+				problems = append(problems, Problem{Severity: SeverityWarning, Location: location,
+					Message: fmt.Sprintf("{{%s}} is captured by %s, which is not in dependsOn", expression, strings.Join(capturedBy[name], ", "))})
+			}
+		}
+	}
+	return problems
+}
+
+// dependencies returns the names of the endpoints the given endpoint
+// depends on, directly or transitively
+func (c *Config) dependencies(endpoint *Endpoint) map[string]bool {
+	dependencies := make(map[string]bool)
+	pending := slices.Clone(endpoint.DependsOn)
+	for len(pending) > 0 {
+		name := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		if dependencies[name] {
+			continue
+		}
+		dependencies[name] = true
+		for i := range c.Endpoints {
+			if c.Endpoints[i].Name == name {
+				pending = append(pending, c.Endpoints[i].DependsOn...)
+			}
+		}
+	}
+	return dependencies
+}
+
+// endpointPlaceholders returns the placeholder expressions in an endpoint's
+// URL, headers, request body, multipart fields and GraphQL variables
+func endpointPlaceholders(endpoint *Endpoint) []string {
+	expressions := vars.Placeholders(endpoint.URL)
+	expressions = append(expressions, vars.Placeholders(endpoint.Range)...)
+	expressions = append(expressions, vars.Placeholders(endpoint.ApimSubscriptionKey)...)
+	for _, part := range endpoint.Multipart {
+		expressions = append(expressions, vars.Placeholders(part.Value)...)
+	}
+	var walk func(value interface{})
+	walk = func(value interface{}) {
+		switch v := value.(type) {
+		case string:
+			expressions = append(expressions, vars.Placeholders(v)...)
+		case map[string]interface{}:
+			for _, key := range slices.Sorted(maps.Keys(v)) {
+				walk(v[key])
+			}
+		case []interface{}:
+			for _, item := range v {
+				walk(item)
+			}
+		}
+	}
+	walk(endpoint.RequestBody)
+	if endpoint.GraphQL != nil {
+		walk(endpoint.GraphQL.Variables)
+	}
+	return expressions
+}
@@ -0,0 +1,178 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// lintEndpoint returns a valid endpoint JSON object with extra fields
+func lintEndpoint(name, url, extra string) string {
+	return fmt.Sprintf(`{"name": %q, "url": %q, "method": "GET", "clientId": "id", "clientSecret": "secret",
+		"tenantId": "tenant", "scope": "api://example/.default"%s}`, name, url, extra)
+}
+
+const lintSpec = `
+openapi: 3.0.3
+servers: [{url: "https://api.example.com/v1"}]
+paths:
+  /items/{id}:
+    get: {operationId: getItem}
+`
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   string
+		expected []string
+	}{
+		{
+			name:   "valid",
+			config: `{"endpoints": [` + lintEndpoint("a", "https://api.example.com/a", "") + `]}`,
+		},
+		{
+			name:     "no endpoints",
+			config:   `{"endpoints": []}`,
+			expected: []string{"error: no endpoints defined in configuration"},
+		},
+		{
+			name:     "unknown field",
+			config:   `{"endpoints": [` + lintEndpoint("a", "https://api.example.com/a", `, "scopeUri": "x"`) + `]}`,
+			expected: []string{`error: failed to decode config file: json: unknown field "scopeUri"`},
+		},
+		{
+			name: "every endpoint error",
+			config: `{"endpoints": [` + lintEndpoint("a", "https://api.example.com/a", `, "method": "TRACE"`) + `,` +
+				lintEndpoint("b", "api.example.com/b", "") + `]}`,
+			expected: []string{
+				"error: endpoint 0 (a): invalid HTTP method: TRACE (must be GET, POST, PUT, PATCH, DELETE, HEAD or OPTIONS, or set allowCustomMethod)",
+				"error: endpoint 1 (b): url: api.example.com/b is not an absolute http or https URL",
+			},
+		},
+		{
+			name: "duplicate names",
+			config: `{"endpoints": [` + lintEndpoint("a", "https://api.example.com/a", "") + `,` +
+				lintEndpoint("a", "https://api.example.com/b", "") + `]}`,
+			expected: []string{"error: endpoint 1 (a): duplicate endpoint name (also endpoint 0)"},
+		},
+		{
+			name:     "missing file",
+			config:   `{"endpoints": [` + lintEndpoint("a", "https://api.example.com/a", `, "tls": {"caFile": "missing.pem"}`) + `]}`,
+			expected: []string{"error: endpoint 0 (a): tls: caFile: stat missing.pem: no such file or directory"},
+		},
+		{
+			name: "captured variable from a dependency",
+			config: `{"endpoints": [` +
+				lintEndpoint("create", "https://api.example.com/items", `, "captures": [{"name": "id", "jsonPath": "$.id"}]`) + `,` +
+				lintEndpoint("get", "https://{{vars.host}}/items/{{ vars.id }}", `, "dependsOn": ["host"]`) + `,` +
+				lintEndpoint("host", "https://api.example.com/host", `, "dependsOn": ["create"], "captures": [{"name": "host", "jsonPath": "$.host"}]`) + `]}`,
+		},
+		{
+			name: "unresolved variables",
+			config: `{"endpoints": [` +
+				lintEndpoint("create", "https://api.example.com/items", `, "captures": [{"name": "id", "jsonPath": "$.id"}]`) + `,` +
+				lintEndpoint("update", "https://api.example.com/items/{{vars.id}}",
+					`, "requestBody": {"owner": "{{vars.owner}}", "tags": ["{{env.TAG}}", "{{vars.owner}}"]}`) + `]}`,
+			expected: []string{
+				"warning: endpoint 1 (update): {{vars.id}} is captured by create, which is not in dependsOn",
+				"error: endpoint 1 (update): {{vars.owner}} is not captured by any endpoint",
+				"error: endpoint 1 (update): unknown placeholder {{env.TAG}}",
+			},
+		},
+		{
+			name: "headers, multipart fields and GraphQL variables",
+			config: `{"endpoints": [` +
+				lintEndpoint("a", "https://api.example.com/a", `, "range": "bytes={{vars.offset}}-", "apimSubscriptionKey": "{{env.APIM_KEY}}"`) + `,` +
+				lintEndpoint("b", "https://api.example.com/b", `, "method": "POST", "multipart": [{"name": "title", "value": "{{vars.title}}"}]`) + `,` +
+				lintEndpoint("c", "https://api.example.com/c", `, "method": "POST", "graphql": {"query": "query($id: ID!) { item(id: $id) { id } }", "variables": {"id": "{{vars.id}}"}}`) + `]}`,
+			expected: []string{
+				"error: endpoint 0 (a): {{vars.offset}} is not captured by any endpoint",
+				"error: endpoint 0 (a): unknown placeholder {{env.APIM_KEY}}",
+				"error: endpoint 1 (b): {{vars.title}} is not captured by any endpoint",
+				"error: endpoint 2 (c): {{vars.id}} is not captured by any endpoint",
+			},
+		},
+		{
+			name: "template functions",
+			config: `{"endpoints": [` + lintEndpoint("create", "https://api.example.com/items/{{uuid}}",
+				`, "requestBody": {"name": "item-{{randInt 1 100}}", "day": "{{now \"2006-01-02\"}}", "size": "{{randInt 1}}"}`) + `]}`,
+			expected: []string{"error: endpoint 0 (create): invalid placeholder {{randInt 1}}: usage is {{randInt min max}}"},
+		},
+		{
+			name: "data file",
+			config: `{"endpoints": [` +
+				lintEndpoint("a", "https://api.example.com/users/{{row.name}}", `, "dataFile": "users.csv", "requestBody": {"email": "{{row.email}}"}`) + `,` +
+				lintEndpoint("b", "https://api.example.com/users/{{row.name}}", "") + `,` +
+				lintEndpoint("c", "https://api.example.com/users", `, "dataFile": "empty.csv"`) + `]}`,
+			expected: []string{
+				"error: endpoint 2 (c): dataFile: data file empty.csv has no rows",
+				"error: endpoint 0 (a): {{row.email}}: users.csv has no column email",
+				"error: endpoint 1 (b): {{row.name}} needs a dataFile",
+			},
+		},
+		{
+			name: "golden files",
+			config: `{"endpoints": [` +
+				lintEndpoint("a", "https://api.example.com/a", `, "goldenFile": "users.csv"`) + `,` +
+				lintEndpoint("b", "https://api.example.com/b", `, "goldenFile": "golden/b.json"`) + `]}`,
+			expected: []string{"warning: endpoint 1 (b): goldenFile golden/b.json does not exist yet (record it with -update-golden)"},
+		},
+		{
+			name: "contract",
+			config: `{"endpoints": [` +
+				lintEndpoint("a", "https://api.example.com/v1/items/{{vars.id}}", `, "contract": {"spec": "openapi.yaml"}`) + `,` +
+				lintEndpoint("b", "https://api.example.com/v1/orders", `, "contract": {"spec": "openapi.yaml"}`) + `,` +
+				lintEndpoint("c", "https://api.example.com/v1/items", `, "contract": {"spec": "openapi.yaml", "operationId": "listItems"}`) + `,` +
+				lintEndpoint("d", "https://api.example.com/v1/items", `, "contract": {"spec": "config.json"}`) + `,` +
+				`{"name": "e", "url": "https://api.example.com/v1/items", "method": "GET", "clientId": "id", "clientSecret": "secret",
+				"tenantId": "tenant", "scope": "api://example/.default", "captures": [{"name": "id", "jsonPath": "$.id"}]}]}`,
+			expected: []string{
+				"error: endpoint 1 (b): contract: no operation matches GET /v1/orders",
+				"error: endpoint 2 (c): contract: no operation with operationId \"listItems\"",
+				"error: endpoint 3 (d): contract: not an OpenAPI or Swagger document (no openapi or swagger version)",
+				"warning: endpoint 0 (a): {{vars.id}} is captured by e, which is not in dependsOn",
+			},
+		},
+		{
+			name: "credentials",
+			config: `{"credentials": {"app": {"clientId": "id", "tenantId": "tenant"}}, "endpoints": [
+				{"name": "a", "url": "https://api.example.com/a", "method": "GET", "credentialRef": "other", "scope": "api://example/.default"},
+				{"name": "b", "url": "https://api.example.com/b", "method": "GET", "credentialRef": "app", "scope": "api://example/.default"}]}`,
+			expected: []string{
+				"error: credential app: clientSecret is required",
+				`error: endpoint 0 (a): unknown credential "other"`,
+				"error: endpoint 1 (b): clientSecret is required",
+			},
+		},
+		{
+			name:     "insecure TLS",
+			config:   `{"endpoints": [` + lintEndpoint("a", "https://api.example.com/a", `, "tls": {"insecureSkipVerify": true}`) + `]}`,
+			expected: []string{"warning: endpoint 0 (a): tls.insecureSkipVerify disables certificate verification"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeConfigFiles(t, map[string]string{
+				"config.json": tt.config, "openapi.yaml": lintSpec, "users.csv": "name,role\nalice,admin\n", "empty.csv": "name\n",
+			})
+			t.Chdir(dir)
+
+			var got []string
+			for _, problem := range Lint(filepath.Join(dir, "config.json")) {
+				got = append(got, string(problem.Severity)+": "+problem.String())
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.expected, "\n") {
+				t.Errorf("Expected problems:\n%s\ngot:\n%s", strings.Join(tt.expected, "\n"), strings.Join(got, "\n"))
+			}
+		})
+	}
+}
+
+func TestLint_UnreadableFile(t *testing.T) {
+	problems := Lint(filepath.Join(t.TempDir(), "missing.json"))
+	if len(problems) != 1 || !strings.Contains(problems[0].Message, "failed to open config file") {
+		t.Errorf("Expected a single open error, got %v", problems)
+	}
+}
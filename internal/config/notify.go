@@ -0,0 +1,73 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// Notification types select the payload format sent to the webhook
+const (
+	// NotificationWebhook sends a generic JSON summary or payloadTemplate
+	NotificationWebhook = "webhook"
+	// NotificationTeams sends an Adaptive Card to a Microsoft Teams
+	// incoming webhook (Workflows)
+	NotificationTeams = "teams"
+	// NotificationSlack sends Block Kit blocks to a Slack incoming webhook
+	NotificationSlack = "slack"
+)
+
+// Notifications configures where the outcome of a run is reported
+type Notifications struct {
+	// Webhook is called when a run has failures
+	Webhook *Webhook `json:"webhook"`
+	// Type is "webhook" (the default), "teams" or "slack"
+	Type string `json:"type"`
+}
+
+// Webhook POSTs a summary of a failed run, e.g. to incident tooling
+type Webhook struct {
+	// Headers are added to the request, e.g. an API key for the receiver
+	Headers map[string]string `json:"headers"`
+	URL     string            `json:"url"`
+	// PayloadTemplate is a Go text/template rendering the request body.
+	// Without it, a JSON summary of the run is sent.
+	PayloadTemplate string `json:"payloadTemplate"`
+}
+
+// Validate checks if the notification settings are valid
+func (n *Notifications) Validate() error {
+	switch n.Type {
+	case "", NotificationWebhook, NotificationTeams, NotificationSlack:
+	default:
+		return fmt.Errorf("invalid type: %s (must be webhook, teams or slack)", n.Type)
+	}
+	if n.Webhook == nil {
+		if n.Type != "" {
+			return fmt.Errorf("webhook is required for type %s", n.Type)
+		}
+		return nil
+	}
+	if err := n.Webhook.Validate(); err != nil {
+		return fmt.Errorf("webhook: %w", err)
+	}
+	if n.Webhook.PayloadTemplate != "" && n.Type != "" && n.Type != NotificationWebhook {
+		return fmt.Errorf("webhook: payloadTemplate cannot be combined with type %s", n.Type)
+	}
+	return nil
+}
+
+// Validate checks if the webhook settings are valid. The payload template
+// is parsed when the notifier is created.
+func (w *Webhook) Validate() error {
+	if w.URL == "" {
+		return fmt.Errorf("url is required")
+	}
+	parsed, err := url.Parse(w.URL)
+	if err != nil {
+		return fmt.Errorf("url: invalid URL: %w", err)
+	}
+	if (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("url: %s is not an absolute http or https URL", w.URL)
+	}
+	return nil
+}
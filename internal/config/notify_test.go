@@ -0,0 +1,36 @@
+package config
+
+import "testing"
+
+func TestNotificationsValidate(t *testing.T) {
+	tests := []struct {
+		name          string
+		notifications Notifications
+		expectErr     bool
+	}{
+		{"empty", Notifications{}, false},
+		{"webhook", Notifications{Webhook: &Webhook{URL: "https://hooks.example.com/incidents"}}, false},
+		{"webhook without url", Notifications{Webhook: &Webhook{}}, true},
+		{"webhook with invalid scheme", Notifications{Webhook: &Webhook{URL: "ftp://hooks.example.com"}}, true},
+		{"webhook with proxy scheme", Notifications{Webhook: &Webhook{URL: "socks5://hooks.example.com"}}, true},
+		{"webhook without host", Notifications{Webhook: &Webhook{URL: "https:///incidents"}}, true},
+		{"teams", Notifications{Type: "teams", Webhook: &Webhook{URL: "https://example.webhook.office.com/x"}}, false},
+		{"slack", Notifications{Type: "slack", Webhook: &Webhook{URL: "https://hooks.slack.com/services/x"}}, false},
+		{"unknown type", Notifications{Type: "email", Webhook: &Webhook{URL: "https://example.com"}}, true},
+		{"type without webhook", Notifications{Type: "slack"}, true},
+		{"template with slack", Notifications{Type: "slack", Webhook: &Webhook{URL: "https://hooks.slack.com/services/x", PayloadTemplate: "{}"}}, true},
+		{"template with explicit webhook type", Notifications{Type: "webhook", Webhook: &Webhook{URL: "https://example.com", PayloadTemplate: "{}"}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.notifications.Validate()
+			if tt.expectErr && err == nil {
+				t.Errorf("Expected error for %s, got nil", tt.name)
+			}
+			if !tt.expectErr && err != nil {
+				t.Errorf("Unexpected error for %s: %v", tt.name, err)
+			}
+		})
+	}
+}
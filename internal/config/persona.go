@@ -0,0 +1,85 @@
+package config
+
+import "fmt"
+
+// Persona is a named set of client credentials, e.g. one app registration
+// per role, used to verify that Conditional Access and app role policies
+// admit or reject each caller as intended
+type Persona struct {
+	Name         string `json:"name"`
+	ClientID     string `json:"clientId"`
+	ClientSecret string `json:"clientSecret"`
+	TenantID     string `json:"tenantId"`
+}
+
+// PersonaCheck runs an endpoint's request as a persona and expects a status
+type PersonaCheck struct {
+	Persona string `json:"persona"`
+	// ExpectStatus is the status the persona must receive, e.g. 200 for a
+	// permitted persona and 403 for a restricted one
+	ExpectStatus int `json:"expectStatus"`
+}
+
+// Validate checks if a persona definition is valid
+func (p *Persona) Validate() error {
+	if p.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if p.ClientID == "" {
+		return fmt.Errorf("clientId is required")
+	}
+	if p.ClientSecret == "" {
+		return fmt.Errorf("clientSecret is required")
+	}
+	if p.TenantID == "" {
+		return fmt.Errorf("tenantId is required")
+	}
+	return nil
+}
+
+// Validate checks if a persona check is valid
+func (c *PersonaCheck) Validate() error {
+	if c.Persona == "" {
+		return fmt.Errorf("persona is required")
+	}
+	if c.ExpectStatus < 100 || c.ExpectStatus > 599 {
+		return fmt.Errorf("expectStatus must be an HTTP status code, got %d", c.ExpectStatus)
+	}
+	return nil
+}
+
+// Persona returns the persona with the given name, or nil
+func (c *Config) Persona(name string) *Persona {
+	for i := range c.Personas {
+		if c.Personas[i].Name == name {
+			return &c.Personas[i]
+		}
+	}
+	return nil
+}
+
+// validatePersonas checks persona definitions and that every endpoint only
+// references defined personas
+func (c *Config) validatePersonas() error {
+	seen := make(map[string]bool, len(c.Personas))
+	for i := range c.Personas {
+		persona := &c.Personas[i]
+		if err := persona.Validate(); err != nil {
+			return fmt.Errorf("persona %d (%s): %w", i, persona.Name, err)
+		}
+		if seen[persona.Name] {
+			return fmt.Errorf("duplicate persona name: %s", persona.Name)
+		}
+		seen[persona.Name] = true
+	}
+
+	for i := range c.Endpoints {
+		endpoint := &c.Endpoints[i]
+		for _, check := range endpoint.Personas {
+			if !seen[check.Persona] {
+				return fmt.Errorf("endpoint %d (%s): unknown persona %q", i, endpoint.Name, check.Persona)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package config
+
+import "testing"
+
+func personaConfig() *Config {
+	return &Config{
+		Personas: []Persona{
+			{Name: "reader", ClientID: "reader-id", ClientSecret: "secret", TenantID: "tenant"},
+			{Name: "admin", ClientID: "admin-id", ClientSecret: "secret", TenantID: "tenant"},
+		},
+		Endpoints: []Endpoint{
+			{
+				Name:   "Delete User",
+				URL:    "https://api.example.com/users/1",
+				Method: "DELETE",
+				Scope:  "api://example/.default",
+				Personas: []PersonaCheck{
+					{Persona: "reader", ExpectStatus: 403},
+					{Persona: "admin", ExpectStatus: 204},
+				},
+			},
+		},
+	}
+}
+
+func TestConfigValidate_Personas(t *testing.T) {
+	tests := []struct {
+		name      string
+		modify    func(cfg *Config)
+		expectErr bool
+	}{
+		{"valid without endpoint credentials", func(cfg *Config) {}, false},
+		{"unknown persona", func(cfg *Config) { cfg.Endpoints[0].Personas[0].Persona = "guest" }, true},
+		{"duplicate persona", func(cfg *Config) { cfg.Personas[1].Name = "reader" }, true},
+		{"persona without secret", func(cfg *Config) { cfg.Personas[0].ClientSecret = "" }, true},
+		{"missing expectStatus", func(cfg *Config) { cfg.Endpoints[0].Personas[1].ExpectStatus = 0 }, true},
+		{"combined with race test", func(cfg *Config) { cfg.Endpoints[0].RaceTest = &RaceTest{Concurrency: 2} }, true},
+		{"endpoint without personas needs credentials", func(cfg *Config) { cfg.Endpoints[0].Personas = nil }, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := personaConfig()
+			tt.modify(cfg)
+
+			err := cfg.Validate()
+			if tt.expectErr && err == nil {
+				t.Errorf("Expected error for %s, got nil", tt.name)
+			}
+			if !tt.expectErr && err != nil {
+				t.Errorf("Unexpected error for %s: %v", tt.name, err)
+			}
+		})
+	}
+}
+
+func TestConfigPersona(t *testing.T) {
+	cfg := personaConfig()
+
+	if persona := cfg.Persona("admin"); persona == nil || persona.ClientID != "admin-id" {
+		t.Errorf("Expected admin persona, got %+v", persona)
+	}
+	if persona := cfg.Persona("guest"); persona != nil {
+		t.Errorf("Expected nil for unknown persona, got %+v", persona)
+	}
+}
@@ -0,0 +1,110 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/hutstep/entra-id-api-tester/internal/schedule"
+)
+
+// ScheduleGroup is the endpoints that run together on a schedule
+type ScheduleGroup struct {
+	Schedule *schedule.Schedule
+	// Expression is the schedule's cron expression
+	Expression string
+	// Endpoints are indexes into Config.Endpoints, in configuration order
+	Endpoints []int
+}
+
+// EndpointSchedule returns the cron expression an endpoint runs on: its
+// own, or else the configuration's
+func (c *Config) EndpointSchedule(e *Endpoint) string {
+	if e.Schedule != "" {
+		return e.Schedule
+	}
+	return c.Schedule
+}
+
+// ScheduleGroups groups the endpoints by the schedule they run on, in the
+// order the schedules first appear. Endpoints without a schedule are left
+// out.
+func (c *Config) ScheduleGroups() ([]ScheduleGroup, error) {
+	var groups []ScheduleGroup
+	index := make(map[string]int)
+	for i := range c.Endpoints {
+		expression := c.EndpointSchedule(&c.Endpoints[i])
+		if expression == "" {
+			continue
+		}
+		j, ok := index[expression]
+		if !ok {
+			parsed, err := schedule.Parse(expression)
+			if err != nil {
+				return nil, err
+			}
+			j = len(groups)
+			index[expression] = j
+			groups = append(groups, ScheduleGroup{Schedule: parsed, Expression: expression})
+		}
+		groups[j].Endpoints = append(groups[j].Endpoints, i)
+	}
+	return groups, nil
+}
+
+// ProbeConfig returns a copy of the configuration with only the endpoints
+// marked as probes. They must not depend on other endpoints.
+func (c *Config) ProbeConfig() (*Config, error) {
+	probes := *c
+	probes.Endpoints = nil
+	isProbe := make(map[string]bool)
+	for i := range c.Endpoints {
+		if c.Endpoints[i].Probe {
+			probes.Endpoints = append(probes.Endpoints, c.Endpoints[i])
+			isProbe[c.Endpoints[i].Name] = true
+		}
+	}
+	if len(probes.Endpoints) == 0 {
+		return nil, fmt.Errorf("no endpoint is marked with probe")
+	}
+	for i := range probes.Endpoints {
+		endpoint := &probes.Endpoints[i]
+		for _, name := range endpoint.DependsOn {
+			if !isProbe[name] {
+				return nil, fmt.Errorf("probe endpoint %s depends on %q, which is not a probe", endpoint.Name, name)
+			}
+		}
+	}
+	return &probes, nil
+}
+
+// validateSchedules checks the cron expressions and that endpoints only
+// depend on endpoints that run on the same schedule
+func (c *Config) validateSchedules() error {
+	if c.ScheduleJitterMs < 0 {
+		return fmt.Errorf("scheduleJitterMs must not be negative")
+	}
+	if c.Schedule != "" {
+		if _, err := schedule.Parse(c.Schedule); err != nil {
+			return err
+		}
+	}
+
+	byName := make(map[string]*Endpoint, len(c.Endpoints))
+	for i := range c.Endpoints {
+		byName[c.Endpoints[i].Name] = &c.Endpoints[i]
+	}
+	for i := range c.Endpoints {
+		endpoint := &c.Endpoints[i]
+		if endpoint.Schedule != "" {
+			if _, err := schedule.Parse(endpoint.Schedule); err != nil {
+				return fmt.Errorf("endpoint %d (%s): %w", i, endpoint.Name, err)
+			}
+		}
+		for _, name := range endpoint.DependsOn {
+			dependency, ok := byName[name]
+			if ok && c.EndpointSchedule(dependency) != c.EndpointSchedule(endpoint) {
+				return fmt.Errorf("endpoint %d (%s): depends on %q, which runs on a different schedule", i, endpoint.Name, name)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package config
+
+import "testing"
+
+func scheduleConfig() *Config {
+	endpoint := func(name string) Endpoint {
+		return Endpoint{
+			Name:         name,
+			URL:          "https://api.example.com/" + name,
+			Method:       "GET",
+			ClientID:     "client-id",
+			ClientSecret: "secret",
+			TenantID:     "tenant",
+			Scope:        "api://example/.default",
+		}
+	}
+	cfg := &Config{
+		Schedule:  "*/5 * * * *",
+		Endpoints: []Endpoint{endpoint("users"), endpoint("orders"), endpoint("reports")},
+	}
+	cfg.Endpoints[1].DependsOn = []string{"users"}
+	cfg.Endpoints[2].Schedule = "@daily"
+	return cfg
+}
+
+func TestConfigValidate_Schedules(t *testing.T) {
+	tests := []struct {
+		name      string
+		modify    func(cfg *Config)
+		expectErr bool
+	}{
+		{"valid", func(cfg *Config) {}, false},
+		{"with jitter", func(cfg *Config) { cfg.ScheduleJitterMs = 30000 }, false},
+		{"negative jitter", func(cfg *Config) { cfg.ScheduleJitterMs = -1 }, true},
+		{"invalid config schedule", func(cfg *Config) { cfg.Schedule = "every 5 minutes" }, true},
+		{"invalid endpoint schedule", func(cfg *Config) { cfg.Endpoints[2].Schedule = "0 25 * * *" }, true},
+		{"dependency on another schedule", func(cfg *Config) { cfg.Endpoints[1].DependsOn = []string{"reports"} }, true},
+		{"dependency on the same explicit schedule", func(cfg *Config) {
+			cfg.Endpoints[0].Schedule = "@daily"
+			cfg.Endpoints[1].Schedule = "@daily"
+		}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := scheduleConfig()
+			tt.modify(cfg)
+
+			err := cfg.Validate()
+			if tt.expectErr && err == nil {
+				t.Errorf("Expected error for %s, got nil", tt.name)
+			}
+			if !tt.expectErr && err != nil {
+				t.Errorf("Unexpected error for %s: %v", tt.name, err)
+			}
+		})
+	}
+}
+
+func TestConfigScheduleGroups(t *testing.T) {
+	cfg := scheduleConfig()
+	groups, err := cfg.ScheduleGroups()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(groups) != 2 || groups[0].Expression != "*/5 * * * *" || groups[1].Expression != "@daily" {
+		t.Fatalf("Expected the config and the endpoint schedule, got %+v", groups)
+	}
+	if len(groups[0].Endpoints) != 2 || groups[0].Endpoints[1] != 1 || len(groups[1].Endpoints) != 1 || groups[1].Endpoints[0] != 2 {
+		t.Errorf("Unexpected endpoints per group: %v, %v", groups[0].Endpoints, groups[1].Endpoints)
+	}
+
+	cfg.Schedule = ""
+	if groups, err = cfg.ScheduleGroups(); err != nil || len(groups) != 1 || len(groups[0].Endpoints) != 1 {
+		t.Errorf("Expected endpoints without a schedule to be left out, got %+v, %v", groups, err)
+	}
+}
+
+func TestConfigProbeConfig(t *testing.T) {
+	cfg := scheduleConfig()
+	if _, err := cfg.ProbeConfig(); err == nil {
+		t.Error("Expected error without probe endpoints, got nil")
+	}
+
+	cfg.Endpoints[1].Probe = true
+	if _, err := cfg.ProbeConfig(); err == nil {
+		t.Error("Expected error for a probe depending on a non-probe, got nil")
+	}
+
+	cfg.Endpoints[0].Probe = true
+	probes, err := cfg.ProbeConfig()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(probes.Endpoints) != 2 || probes.Endpoints[1].Name != "orders" || probes.Schedule != cfg.Schedule {
+		t.Errorf("Expected the two probe endpoints with the schedule, got %+v", probes)
+	}
+	if len(cfg.Endpoints) != 3 {
+		t.Errorf("Expected the configuration to be unchanged, got %d endpoints", len(cfg.Endpoints))
+	}
+}
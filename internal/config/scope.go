@@ -0,0 +1,60 @@
+package config
+
+import "fmt"
+
+// ScopeCheck runs an endpoint's request with a token for a scope and
+// expects a status, e.g. to verify that a token for another API's scope is
+// rejected
+type ScopeCheck struct {
+	Scope string `json:"scope"`
+	// ExpectStatus is the status a token for the scope must receive, e.g.
+	// 200 for a permitted scope and 403 for one without access
+	ExpectStatus int `json:"expectStatus"`
+}
+
+// Validate checks if a scope check is valid
+func (c *ScopeCheck) Validate() error {
+	if c.Scope == "" {
+		return fmt.Errorf("scope is required")
+	}
+	if c.ExpectStatus < 100 || c.ExpectStatus > 599 {
+		return fmt.Errorf("expectStatus must be an HTTP status code, got %d", c.ExpectStatus)
+	}
+	return nil
+}
+
+// TokenScope returns the scope a single token for the endpoint is acquired
+// for: its scope, or else the first of its scopes
+func (e *Endpoint) TokenScope() string {
+	if e.Scope == "" && len(e.Scopes) > 0 {
+		return e.Scopes[0].Scope
+	}
+	return e.Scope
+}
+
+// validateScopes checks the endpoint's scope matrix, if any
+func (e *Endpoint) validateScopes() error {
+	if len(e.Scopes) == 0 {
+		if e.Scope == "" {
+			return fmt.Errorf("scope is required")
+		}
+		return nil
+	}
+	if len(e.Personas) > 0 || len(e.Tenants) > 0 || e.RaceTest != nil || len(e.AcceptLanguages) > 0 {
+		return fmt.Errorf("scopes cannot be combined with personas, tenants, raceTest or acceptLanguages")
+	}
+	if e.WarmupRequests > 0 || e.NegativeAuth || e.TamperedTokens {
+		return fmt.Errorf("scopes cannot be combined with warmupRequests, negativeAuth or tamperedTokens")
+	}
+	seen := make(map[string]bool, len(e.Scopes))
+	for i := range e.Scopes {
+		if err := e.Scopes[i].Validate(); err != nil {
+			return fmt.Errorf("scopes %d: %w", i, err)
+		}
+		if seen[e.Scopes[i].Scope] {
+			return fmt.Errorf("scopes %d: duplicate scope %s", i, e.Scopes[i].Scope)
+		}
+		seen[e.Scopes[i].Scope] = true
+	}
+	return nil
+}
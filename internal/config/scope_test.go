@@ -0,0 +1,61 @@
+package config
+
+import "testing"
+
+func TestEndpointValidate_Scopes(t *testing.T) {
+	tests := []struct {
+		name      string
+		modify    func(e *Endpoint)
+		expectErr bool
+	}{
+		{"scopes replace scope", func(e *Endpoint) {}, false},
+		{"without scope or scopes", func(e *Endpoint) { e.Scopes = nil }, true},
+		{"missing scope", func(e *Endpoint) { e.Scopes[1].Scope = "" }, true},
+		{"missing expectStatus", func(e *Endpoint) { e.Scopes[1].ExpectStatus = 0 }, true},
+		{"duplicate scope", func(e *Endpoint) { e.Scopes[1].Scope = e.Scopes[0].Scope }, true},
+		{"with tenants", func(e *Endpoint) { e.Tenants = []string{"contoso"} }, true},
+		{"with negative auth", func(e *Endpoint) { e.NegativeAuth = true }, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			endpoint := Endpoint{
+				Name:         "Test",
+				URL:          "https://api.example.com",
+				Method:       "GET",
+				ClientID:     "client-id",
+				ClientSecret: "secret",
+				TenantID:     "tenant",
+				Scopes: []ScopeCheck{
+					{Scope: "api://orders/.default", ExpectStatus: 200},
+					{Scope: "api://billing/.default", ExpectStatus: 403},
+				},
+			}
+			tt.modify(&endpoint)
+
+			if err := endpoint.Validate(); (err != nil) != tt.expectErr {
+				t.Errorf("Validate() error = %v, expectErr %v", err, tt.expectErr)
+			}
+		})
+	}
+}
+
+func TestEndpointTokenScope(t *testing.T) {
+	tests := []struct {
+		name     string
+		endpoint Endpoint
+		expected string
+	}{
+		{"scope", Endpoint{Scope: "api://orders/.default"}, "api://orders/.default"},
+		{"first of scopes", Endpoint{Scopes: []ScopeCheck{{Scope: "api://billing/.default"}, {Scope: "api://orders/.default"}}}, "api://billing/.default"},
+		{"none", Endpoint{}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.endpoint.TokenScope(); got != tt.expected {
+				t.Errorf("TokenScope() = %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+// Package console renders the human-readable output of a run: the progress
+// and result of each endpoint and the summary. Formatters decide how much
+// is printed and how it is decorated, so that output read by scripts need
+// not strip colors, checkmarks and other symbols.
+package console
+
+import (
+	"io"
+	"os"
+	"time"
+
+	"github.com/hutstep/entra-id-api-tester/internal/config"
+	"github.com/hutstep/entra-id-api-tester/internal/history"
+	"github.com/hutstep/entra-id-api-tester/internal/runner"
+)
+
+// Formatter renders the output of a run
+type Formatter interface {
+	// Start prints the header before the first endpoint
+	Start(cfg *config.Config)
+	// Testing announces an endpoint before it runs
+	Testing(position, total int, endpoint *config.Endpoint)
+	// Result prints the outcome of an endpoint
+	Result(result *runner.Result)
+	// Cancelled reports an endpoint that was in flight when the run was
+	// cancelled
+	Cancelled(cause error)
+	// Summary prints the counts, flaky endpoints and warnings of the run.
+	// aborted is the reason the run stopped before all total endpoints
+	// ran, or nil.
+	Summary(results []*runner.Result, total int, aborted error)
+	// Regressions lists the endpoints that regressed against the previous
+	// runs in the history
+	Regressions(regressions []history.Regression, previousRuns int)
+	// Baseline lists how the run differs from the baseline at path
+	Baseline(diff *history.Diff, path string)
+	// Notef prints a notice, such as the path of a written file
+	Notef(format string, args ...any)
+}
+
+// Options control the output of a formatter
+type Options struct {
+	// Quiet prints only failed endpoints, warnings and the summary
+	Quiet bool
+	// Color highlights results with ANSI escape sequences
+	Color bool
+	// Plain prints ASCII only: no emoji, checkmarks or other symbols
+	Plain bool
+	// Progress shows a progress bar instead of every endpoint; it needs a
+	// terminal
+	Progress bool
+}
+
+// New creates the formatter for opts that writes to w
+func New(w io.Writer, opts Options) Formatter {
+	text := &Text{w: w, style: style{color: opts.Color, plain: opts.Plain}}
+	switch {
+	case opts.Quiet:
+		return &Quiet{Text: text}
+	case opts.Progress:
+		return &Progress{Text: text, now: time.Now}
+	}
+	return text
+}
+
+// ColorEnabled reports whether output to file should be colored: file must
+// be a terminal, and neither NO_COLOR (https://no-color.org) may be set nor
+// TERM be "dumb"
+func ColorEnabled(file *os.File, getenv func(string) string) bool {
+	return getenv("NO_COLOR") == "" && getenv("TERM") != "dumb" && IsTerminal(file)
+}
+
+// IsTerminal reports whether file is a terminal rather than, e.g., a pipe
+// or a regular file
+func IsTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// ANSI colors
+const (
+	colorRed    = "31"
+	colorGreen  = "32"
+	colorYellow = "33"
+	colorBold   = "1"
+)
+
+// Symbols, with their ASCII replacement in plain output
+var symbols = map[string]string{
+	"✓": "+",
+	"✗": "x",
+	"↻": "~",
+	"↘": "v",
+	"⚠": "!",
+	"•": "-",
+	"→": "->",
+}
+
+// style decorates output
+type style struct {
+	color bool
+	plain bool
+}
+
+// paint wraps text in an ANSI color
+func (s style) paint(color, text string) string {
+	if !s.color || text == "" {
+		return text
+	}
+	return "\033[" + color + "m" + text + "\033[0m"
+}
+
+// symbol returns symbol, or its ASCII replacement in plain output
+func (s style) symbol(symbol string) string {
+	if s.plain {
+		return symbols[symbol]
+	}
+	return symbol
+}
+
+// mark renders a labelled result such as "✓ PASS". Plain output omits the
+// symbol, since the label says it all.
+func (s style) mark(symbol, label, color string) string {
+	if s.plain {
+		return s.paint(color, label)
+	}
+	return s.paint(color, symbol+" "+label)
+}
@@ -0,0 +1,64 @@
+package console
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	if _, ok := New(os.Stdout, Options{}).(*Text); !ok {
+		t.Error("Expected the text formatter by default")
+	}
+	if _, ok := New(os.Stdout, Options{Quiet: true}).(*Quiet); !ok {
+		t.Error("Expected the quiet formatter with Quiet")
+	}
+}
+
+func TestColorEnabled(t *testing.T) {
+	file, err := os.Create(filepath.Join(t.TempDir(), "out.txt"))
+	if err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	env := map[string]string{}
+	if ColorEnabled(file, func(key string) string { return env[key] }) {
+		t.Error("Expected no color for a regular file")
+	}
+	if IsTerminal(file) {
+		t.Error("Expected a regular file not to be a terminal")
+	}
+}
+
+func TestStyle(t *testing.T) {
+	tests := []struct {
+		name    string
+		style   style
+		mark    string
+		symbol  string
+		painted string
+	}{
+		{name: "default", style: style{}, mark: "✓ PASS", symbol: "✓", painted: "text"},
+		{name: "color", style: style{color: true}, mark: "\033[32m✓ PASS\033[0m", symbol: "✓", painted: "\033[32mtext\033[0m"},
+		{name: "plain", style: style{plain: true}, mark: "PASS", symbol: "+", painted: "text"},
+		{name: "plain color", style: style{color: true, plain: true}, mark: "\033[32mPASS\033[0m", symbol: "+", painted: "\033[32mtext\033[0m"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.style.mark("✓", "PASS", colorGreen); got != tt.mark {
+				t.Errorf("mark = %q, expected %q", got, tt.mark)
+			}
+			if got := tt.style.symbol("✓"); got != tt.symbol {
+				t.Errorf("symbol = %q, expected %q", got, tt.symbol)
+			}
+			if got := tt.style.paint(colorGreen, "text"); got != tt.painted {
+				t.Errorf("paint = %q, expected %q", got, tt.painted)
+			}
+			if got := tt.style.paint(colorGreen, ""); got != "" {
+				t.Errorf("Expected empty text not to be painted, got %q", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,195 @@
+package console
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/hutstep/entra-id-api-tester/internal/config"
+	"github.com/hutstep/entra-id-api-tester/internal/runner"
+)
+
+// Progress bar dimensions
+const (
+	progressWidth   = 30
+	progressNameLen = 30
+)
+
+// Progress replaces the per-endpoint output of large suites on a terminal
+// with a progress bar that is redrawn in place: the running pass, fail and
+// skip counts, the estimated time remaining and the endpoint in flight.
+// Failed endpoints are printed above the bar as they finish.
+type Progress struct {
+	*Text
+	started time.Time
+	now     func() time.Time
+	current string
+	total   int
+	done    int
+	passed  int
+	failed  int
+	skipped int
+	mu      sync.Mutex
+	// visible is set while the bar is drawn on the current line
+	visible bool
+}
+
+// Start prints the header and starts the clock for the estimate
+func (p *Progress) Start(cfg *config.Config) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.Text.Start(cfg)
+	p.started = p.now()
+	p.total = len(cfg.Endpoints)
+}
+
+// Testing shows the endpoint as in flight
+func (p *Progress) Testing(_, total int, endpoint *config.Endpoint) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.total = total
+	p.current = endpoint.Name
+	p.draw()
+}
+
+// Result counts the outcome and prints a line above the bar when the
+// endpoint failed
+func (p *Progress) Result(result *runner.Result) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.done++
+	switch {
+	case result.Skipped:
+		p.skipped++
+	case result.Success():
+		p.passed++
+	default:
+		p.failed++
+		p.clear()
+		quiet := Quiet{Text: p.Text}
+		quiet.Result(result)
+	}
+	p.current = ""
+	p.draw()
+}
+
+// Cancelled reports the endpoint in flight as cancelled
+func (p *Progress) Cancelled(cause error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.clear()
+	p.printf("%s %s - %v\n", p.style.mark("⊘", "CANCELLED", colorYellow), p.current, cause)
+}
+
+// Summary removes the bar and prints the summary
+func (p *Progress) Summary(results []*runner.Result, total int, aborted error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.clear()
+	p.Text.Summary(results, total, aborted)
+}
+
+// Notef removes the bar and prints a notice
+func (p *Progress) Notef(format string, args ...any) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.clear()
+	p.Text.Notef(format, args...)
+}
+
+// Bypass returns a writer for other output to the same terminal, such as
+// log records on stderr, that moves the bar below what it writes
+func (p *Progress) Bypass(w io.Writer) io.Writer {
+	return &bypassWriter{progress: p, w: w}
+}
+
+// clear erases the bar from the current line
+func (p *Progress) clear() {
+	if p.visible {
+		p.printf("\r\033[K")
+		p.visible = false
+	}
+}
+
+// draw redraws the bar on the current line
+func (p *Progress) draw() {
+	p.printf("\r\033[K%s", p.line())
+	p.visible = true
+}
+
+// line renders the bar, e.g. "[####------] 12/40 10 passed 1 failed 1
+// skipped ETA 1m3s users"
+func (p *Progress) line() string {
+	filled := 0
+	if p.total > 0 {
+		filled = min(progressWidth, p.done*progressWidth/p.total)
+	}
+	full, empty := "█", "░"
+	if p.style.plain {
+		full, empty = "#", "-"
+	}
+	bar := strings.Repeat(full, filled) + strings.Repeat(empty, progressWidth-filled)
+
+	eta := "--"
+	if p.done > 0 && p.done < p.total {
+		elapsed := p.now().Sub(p.started)
+		eta = (elapsed / time.Duration(p.done) * time.Duration(p.total-p.done)).Round(time.Second).String()
+	} else if p.done >= p.total {
+		eta = "0s"
+	}
+
+	line := fmt.Sprintf("[%s] %d/%d  %s  %s  %s  ETA %s", bar, p.done, p.total,
+		p.style.paint(colorGreen, fmt.Sprintf("%d passed", p.passed)),
+		p.countFailed(),
+		p.style.paint(colorYellow, fmt.Sprintf("%d skipped", p.skipped)),
+		eta)
+	if p.current != "" {
+		line += "  " + p.truncate(p.current)
+	}
+	return line
+}
+
+// countFailed renders the number of failed endpoints, in red once any
+// failed
+func (p *Progress) countFailed() string {
+	text := fmt.Sprintf("%d failed", p.failed)
+	if p.failed == 0 {
+		return text
+	}
+	return p.style.paint(colorRed, text)
+}
+
+// truncate shortens an endpoint name so the bar fits on one line
+func (p *Progress) truncate(name string) string {
+	if utf8.RuneCountInString(name) <= progressNameLen {
+		return name
+	}
+	ellipsis := "…"
+	if p.style.plain {
+		ellipsis = "..."
+	}
+	runes := []rune(name)
+	return string(runes[:progressNameLen-utf8.RuneCountInString(ellipsis)]) + ellipsis
+}
+
+// bypassWriter writes above the progress bar
+type bypassWriter struct {
+	progress *Progress
+	w        io.Writer
+}
+
+// Write erases the bar, writes data and redraws the bar
+func (b *bypassWriter) Write(data []byte) (int, error) {
+	b.progress.mu.Lock()
+	defer b.progress.mu.Unlock()
+	visible := b.progress.visible
+	b.progress.clear()
+	n, err := b.w.Write(data)
+	if visible {
+		b.progress.draw()
+	}
+	return n, err
+}
@@ -0,0 +1,96 @@
+package console
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hutstep/entra-id-api-tester/internal/config"
+	"github.com/hutstep/entra-id-api-tester/internal/runner"
+)
+
+// newTestProgress creates a plain progress formatter whose clock advances
+// by a second on every reading
+func newTestProgress(buf *strings.Builder) *Progress {
+	progress, _ := New(buf, Options{Progress: true, Plain: true}).(*Progress)
+	clock := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	progress.now = func() time.Time {
+		clock = clock.Add(time.Second)
+		return clock
+	}
+	return progress
+}
+
+func TestProgress(t *testing.T) {
+	var buf strings.Builder
+	progress := newTestProgress(&buf)
+
+	cfg := &config.Config{Endpoints: make([]config.Endpoint, 4)}
+	progress.Start(cfg)
+	results := testResults()
+	for i, result := range results {
+		progress.Testing(i+1, 4, &config.Endpoint{Name: result.EndpointName})
+		progress.Result(result)
+	}
+	progress.Testing(4, 4, &config.Endpoint{Name: "a very long endpoint name that does not fit"})
+	progress.Cancelled(context.Canceled)
+	progress.Summary(results, 4, errors.New("interrupted"))
+	got := buf.String()
+
+	expected := []string{
+		"Loaded configuration with 4 endpoint(s)\n",
+		"\r\033[K[------------------------------] 0/4  0 passed  0 failed  0 skipped  ETA --  users",
+		"\r\033[K[#######-----------------------] 1/4  1 passed  0 failed  0 skipped  ETA 6s",
+		"\r\033[KFAIL orders - Unexpected status code: 500\n",
+		"\r\033[K[###############---------------] 2/4  1 passed  1 failed  0 skipped  ETA 3s",
+		"\r\033[K[######################--------] 3/4  1 passed  1 failed  1 skipped  ETA 2s  a very long endpoint name t...",
+		"\r\033[KCANCELLED a very long endpoint name that does not fit - context canceled\n",
+		"RUN ABORTED after 3/4 endpoint(s): interrupted\n",
+	}
+	for _, line := range expected {
+		if !strings.Contains(got, line) {
+			t.Errorf("Expected output to contain %q, got:\n%q", line, got)
+		}
+	}
+	for _, absent := range []string{"Testing:", "PASS -", "SKIP -"} {
+		if strings.Contains(got, absent) {
+			t.Errorf("Expected output not to contain %q, got:\n%q", absent, got)
+		}
+	}
+}
+
+func TestProgress_Bypass(t *testing.T) {
+	var buf strings.Builder
+	progress := newTestProgress(&buf)
+	progress.Start(&config.Config{Endpoints: make([]config.Endpoint, 2)})
+
+	var logs strings.Builder
+	bypass := progress.Bypass(&logs)
+	if _, err := bypass.Write([]byte("before the bar\n")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if strings.Contains(buf.String(), "\r") {
+		t.Errorf("Expected no bar to be drawn or erased, got %q", buf.String())
+	}
+
+	progress.Testing(1, 2, &config.Endpoint{Name: "users"})
+	buf.Reset()
+	if _, err := bypass.Write([]byte("while running\n")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if logs.String() != "before the bar\nwhile running\n" {
+		t.Errorf("Expected the log records to be written, got %q", logs.String())
+	}
+	expected := "\r\033[K\r\033[K[------------------------------] 0/2  0 passed  0 failed  0 skipped  ETA --  users"
+	if buf.String() != expected {
+		t.Errorf("Expected the bar to be erased and redrawn, got %q", buf.String())
+	}
+
+	progress.Result(&runner.Result{EndpointName: "users"})
+	progress.Notef("Report written to %s", "report.json")
+	if !strings.HasSuffix(buf.String(), "\r\033[KReport written to report.json\n") {
+		t.Errorf("Expected the bar to be erased before the notice, got %q", buf.String())
+	}
+}
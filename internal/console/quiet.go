@@ -0,0 +1,35 @@
+package console
+
+import (
+	"github.com/hutstep/entra-id-api-tester/internal/config"
+	"github.com/hutstep/entra-id-api-tester/internal/redact"
+	"github.com/hutstep/entra-id-api-tester/internal/runner"
+)
+
+// Quiet prints only what needs attention: one line per failed endpoint,
+// warnings about insecure endpoints and the summary
+type Quiet struct {
+	*Text
+}
+
+// Start only warns about endpoints that skip TLS certificate verification
+func (q *Quiet) Start(cfg *config.Config) {
+	q.insecureWarnings(cfg)
+}
+
+// Testing prints nothing
+func (q *Quiet) Testing(int, int, *config.Endpoint) {}
+
+// Result prints a line with the error of a failed endpoint
+func (q *Quiet) Result(result *runner.Result) {
+	if result.Success() || result.Skipped {
+		return
+	}
+	q.printf("%s %s - %s\n", q.style.mark("✗", "FAIL", colorRed), result.EndpointName, redact.String(result.Err.Error()))
+}
+
+// Cancelled prints nothing; the summary reports the aborted run
+func (q *Quiet) Cancelled(error) {}
+
+// Notef prints nothing
+func (q *Quiet) Notef(string, ...any) {}
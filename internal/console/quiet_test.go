@@ -0,0 +1,32 @@
+package console
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestQuiet(t *testing.T) {
+	var buf strings.Builder
+	render(New(&buf, Options{Quiet: true}))
+	got := buf.String()
+
+	expected := []string{
+		"⚠️  WARNING: TLS certificate verification is DISABLED for \"legacy\" (insecureSkipVerify)\n",
+		"✗ FAIL orders - Unexpected status code: 500\n",
+		"RUN ABORTED after 3/4 endpoint(s): interrupted\n",
+		"SUMMARY\n",
+		"REGRESSIONS\n",
+		"BASELINE COMPARISON\n",
+	}
+	for _, line := range expected {
+		if !strings.Contains(got, line) {
+			t.Errorf("Expected output to contain %q, got:\n%s", line, got)
+		}
+	}
+	absent := []string{"Loaded configuration", "Testing:", "PASS -", "SKIP -", "CANCELLED", "Report written"}
+	for _, line := range absent {
+		if strings.Contains(got, line) {
+			t.Errorf("Expected output not to contain %q, got:\n%s", line, got)
+		}
+	}
+}
@@ -0,0 +1,361 @@
+package console
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/hutstep/entra-id-api-tester/internal/client"
+	"github.com/hutstep/entra-id-api-tester/internal/config"
+	"github.com/hutstep/entra-id-api-tester/internal/history"
+	"github.com/hutstep/entra-id-api-tester/internal/redact"
+	"github.com/hutstep/entra-id-api-tester/internal/report"
+	"github.com/hutstep/entra-id-api-tester/internal/runner"
+)
+
+// Text is the default formatter: it prints every endpoint as it runs with
+// the details of its result, followed by the summary
+type Text struct {
+	w     io.Writer
+	style style
+}
+
+// printf writes formatted output, ignoring write errors like fmt.Printf
+func (t *Text) printf(format string, args ...any) {
+	_, _ = fmt.Fprintf(t.w, format, args...)
+}
+
+// rule prints a horizontal line of width 80
+func (t *Text) rule(char string) {
+	t.printf("%s\n", strings.Repeat(char, 80))
+}
+
+// heading prints a section heading with its underline
+func (t *Text) heading(title string) {
+	t.printf("\n%s\n", t.style.paint(colorBold, title))
+	t.rule("-")
+}
+
+// Start prints the number of endpoints and warns about endpoints that skip
+// TLS certificate verification
+func (t *Text) Start(cfg *config.Config) {
+	t.printf("Loaded configuration with %d endpoint(s)\n", len(cfg.Endpoints))
+	t.insecureWarnings(cfg)
+	t.rule("=")
+}
+
+// insecureWarnings warns about endpoints that skip TLS certificate
+// verification
+func (t *Text) insecureWarnings(cfg *config.Config) {
+	for i := range cfg.Endpoints {
+		if tlsConfig := cfg.Endpoints[i].TLS; tlsConfig != nil && tlsConfig.InsecureSkipVerify {
+			t.printf("%s: TLS certificate verification is DISABLED for %q (insecureSkipVerify)\n",
+				t.style.mark("⚠️ ", "WARNING", colorYellow), cfg.Endpoints[i].Name)
+		}
+	}
+}
+
+// Testing prints the position, name and request of the endpoint
+func (t *Text) Testing(position, total int, endpoint *config.Endpoint) {
+	t.printf("\n[%d/%d] Testing: %s\n", position, total, t.style.paint(colorBold, endpoint.Name))
+	t.printf("    URL: %s\n", redact.String(endpoint.URL))
+	t.printf("    Method: %s\n", endpoint.Method)
+}
+
+// Result prints the outcome of the endpoint and each phase it passed or
+// failed, followed by its matrix, repetitions, warnings and diagnoses
+func (t *Text) Result(result *runner.Result) {
+	if result.Skipped {
+		t.printf("    %s - %s\n", t.style.mark("⊘", "SKIP", colorYellow), redact.String(result.Err.Error()))
+		return
+	}
+
+	if result.Success() {
+		t.printf("    %s - All checks passed (Duration: %v)\n", t.style.mark("✓", "PASS", colorGreen), result.Duration)
+	} else {
+		t.printf("    %s - %s (Duration: %v)\n", t.style.mark("✗", "FAIL", colorRed), redact.String(result.Err.Error()), result.Duration)
+		t.phases(result)
+		if traceID := result.TraceID(); traceID != "" {
+			t.printf("      %s Trace ID: %s\n", t.style.symbol("•"), traceID)
+		}
+		if remoteAddr := result.RemoteAddr(); remoteAddr != "" {
+			t.printf("      %s Address: %s (%s)\n", t.style.symbol("•"), remoteAddr, client.AddressFamily(remoteAddr))
+		}
+		requestIDs := result.RequestIDs()
+		for _, header := range client.RequestIDHeaders {
+			if value, ok := requestIDs[header]; ok {
+				t.printf("      %s %s: %s\n", t.style.symbol("•"), header, value)
+			}
+		}
+	}
+	t.matrix(result)
+	switch result.ClaimsChallenge {
+	case runner.ClaimsChallengeSatisfied:
+		t.printf("    %s - Claims challenge answered with a re-acquired token\n", t.style.mark("↻", "CAE", ""))
+	case runner.ClaimsChallengeUnsatisfied:
+		t.printf("    %s - Claims challenge could not be satisfied\n", t.style.mark("⚠", "CAE", colorYellow))
+	}
+	if result.Runs > 0 {
+		flaky := ""
+		if result.Flaky() {
+			flaky = " - " + t.style.paint(colorYellow, "FLAKY")
+		}
+		t.printf("    %s - %d/%d passed (%.1f%%)%s\n", t.style.mark("↻", "RUNS", ""), result.PassedRuns, result.Runs, result.PassRate(), flaky)
+	}
+	for _, redirect := range result.Redirects {
+		t.printf("    %s - %d %s %s %s\n", t.style.mark("↪", "REDIRECT", ""), redirect.StatusCode, redact.String(redirect.URL), t.style.symbol("→"), redact.String(redirect.Location))
+	}
+	for i := range result.Latency {
+		latency := &result.Latency[i]
+		color := colorRed
+		if latency.Passed() {
+			color = ""
+		}
+		t.printf("    %s - p%g %v over %d run(s) (objective: %s)\n", t.style.mark("⏱", "LATENCY", color), latency.Objective.Percentile, latency.Duration.Truncate(time.Millisecond), latency.Samples, latency.Objective)
+	}
+	if result.OperationDuration > 0 {
+		t.printf("    %s - Operation completed in %v after %d poll(s)\n", t.style.mark("⏱", "ASYNC", ""), result.OperationDuration.Truncate(time.Millisecond), result.Polls)
+	}
+	if result.ThrottleRetries > 0 {
+		t.printf("    %s - Retried %d time(s) after 429 (waited %v)\n", t.style.mark("⏳", "THROTTLED", colorYellow), result.ThrottleRetries, result.ThrottleWait)
+	}
+	for _, warning := range result.Warnings {
+		t.printf("    %s - %s\n", t.style.mark("⚠", "WARNING", colorYellow), redact.String(warning))
+	}
+	for _, diagnosis := range result.Diagnoses {
+		t.printf("    %s - %s\n", t.style.mark("🔎", "DIAGNOSIS", ""), redact.String(diagnosis))
+	}
+	if result.Security != nil {
+		t.printf("    %s - Grade %s\n", t.style.mark("🛡", "SECURITY", gradeColor(result.Security.Grade)), result.Security.Grade)
+		for _, finding := range result.Security.Findings {
+			t.printf("      %s %s\n", t.style.symbol("•"), redact.String(finding.Message))
+		}
+	}
+}
+
+// gradeColor returns the color of a security grade: green for A, yellow
+// for B and C and red below
+func gradeColor(grade string) string {
+	switch grade {
+	case "A":
+		return colorGreen
+	case "B", "C":
+		return colorYellow
+	default:
+		return colorRed
+	}
+}
+
+// phases prints which phases a failed endpoint passed, up to the one it
+// failed in
+func (t *Text) phases(result *runner.Result) {
+	passed := t.style.paint(colorGreen, "PASSED")
+	failed := t.style.paint(colorRed, "FAILED")
+	bullet := t.style.symbol("•")
+	if !result.Passed(runner.PhaseAuth) {
+		t.printf("      %s Authentication: %s\n", bullet, failed)
+	} else {
+		t.printf("      %s Authentication: %s\n", bullet, passed)
+	}
+	switch {
+	case result.FailedPhase() == runner.PhaseSecurity:
+		t.printf("      %s Connectivity: %s\n", bullet, passed)
+		t.printf("      %s Response Status: %s (Status Code: %d)\n", bullet, passed, result.StatusCode)
+		t.printf("      %s Negative Auth: %s\n", bullet, failed)
+	case result.FailedPhase() == runner.PhaseContract:
+		t.printf("      %s Connectivity: %s\n", bullet, passed)
+		t.printf("      %s Response Status: %s (Status Code: %d)\n", bullet, passed, result.StatusCode)
+		t.printf("      %s Contract: %s\n", bullet, failed)
+	case result.FailedPhase() == runner.PhasePerformance:
+		t.printf("      %s Connectivity: %s\n", bullet, passed)
+		t.printf("      %s Response Status: %s (Status Code: %d)\n", bullet, passed, result.StatusCode)
+		t.printf("      %s Performance: %s\n", bullet, failed)
+	case result.Passed(runner.PhaseConnect):
+		t.printf("      %s Connectivity: %s\n", bullet, passed)
+		t.printf("      %s Response Status: %s (Status Code: %d)\n", bullet, failed, result.StatusCode)
+	default:
+		t.printf("      %s Connectivity: %s\n", bullet, failed)
+	}
+}
+
+// matrix prints the outcome per entry of a matrix endpoint
+func (t *Text) matrix(result *runner.Result) {
+	for i := range result.Matrix {
+		cell := &result.Matrix[i]
+		status := ""
+		if cell.StatusCode > 0 {
+			status = fmt.Sprintf(" (Status Code: %d)", cell.StatusCode)
+		}
+		if cell.Passed() {
+			t.printf("      %s %s%s\n", t.style.paint(colorGreen, t.style.symbol("✓")), cell.Label, status)
+		} else {
+			t.printf("      %s %s%s - %s\n", t.style.paint(colorRed, t.style.symbol("✗")), cell.Label, status, redact.String(cell.Err.Error()))
+		}
+	}
+}
+
+// Cancelled reports the endpoint in flight as cancelled
+func (t *Text) Cancelled(cause error) {
+	t.printf("    %s - %v\n", t.style.mark("⊘", "CANCELLED", colorYellow), cause)
+}
+
+// Summary prints the counts per outcome and failed phase, followed by the
+// flaky endpoints and the warnings
+func (t *Text) Summary(results []*runner.Result, total int, aborted error) {
+	t.printf("\n")
+	t.rule("=")
+	if aborted != nil {
+		t.printf("%s after %d/%d endpoint(s): %v\n", t.style.paint(colorYellow, "RUN ABORTED"), len(results), total, aborted)
+		t.rule("=")
+	}
+
+	summary := report.Summarize(results)
+	t.printf("%s\n", t.style.paint(colorBold, "SUMMARY"))
+	t.rule("-")
+	t.printf("Total Endpoints:           %d\n", summary.Total)
+	t.printf("Passed:                    %s\n", t.count(summary.Passed, summary.Total, colorGreen))
+	t.printf("Failed:                    %s\n", t.count(summary.Failed, summary.Total, colorRed))
+	if summary.Skipped > 0 {
+		t.printf("Skipped:                   %s\n", t.count(summary.Skipped, summary.Total, colorYellow))
+	}
+	if summary.Flaky > 0 {
+		t.printf("Flaky:                     %d (failed, but passed some runs)\n", summary.Flaky)
+	}
+	bullet := t.style.symbol("•")
+	t.printf("\n")
+	t.printf("  %s Authentication Failures:  %d\n", bullet, summary.AuthFailures)
+	if summary.PrepareFailures > 0 {
+		t.printf("  %s Preparation Failures:     %d\n", bullet, summary.PrepareFailures)
+	}
+	t.printf("  %s Connectivity Failures:    %d\n", bullet, summary.ConnectFailures)
+	t.printf("  %s Response Failures:        %d\n", bullet, summary.ResponseFailures)
+	if summary.SecurityFailures > 0 {
+		t.printf("  %s Security Failures:        %d\n", bullet, summary.SecurityFailures)
+	}
+	if summary.ContractFailures > 0 {
+		t.printf("  %s Contract Failures:        %d\n", bullet, summary.ContractFailures)
+	}
+	if summary.PerformanceFailures > 0 {
+		t.printf("  %s Performance Failures:     %d\n", bullet, summary.PerformanceFailures)
+	}
+	if summary.Throttled > 0 {
+		t.printf("\n")
+		t.printf("Throttled Endpoints:       %d\n", summary.Throttled)
+	}
+
+	t.flaky(results)
+	t.warnings(results)
+	t.security(results)
+	t.rule("=")
+}
+
+// count renders part and its percentage of total, colored when part is
+// not zero
+func (t *Text) count(part, total int, color string) string {
+	percentage := 0.0
+	if total > 0 {
+		percentage = float64(part) / float64(total) * 100
+	}
+	text := fmt.Sprintf("%d (%.1f%%)", part, percentage)
+	if part == 0 {
+		return text
+	}
+	return t.style.paint(color, text)
+}
+
+// flaky lists the endpoints that passed only some of their repeated runs,
+// with their pass rates
+func (t *Text) flaky(results []*runner.Result) {
+	var flaky []*runner.Result
+	for _, result := range results {
+		if result.Flaky() {
+			flaky = append(flaky, result)
+		}
+	}
+	if len(flaky) == 0 {
+		return
+	}
+
+	t.heading("FLAKY ENDPOINTS")
+	for _, result := range flaky {
+		t.printf("  %s %s: %d/%d runs passed (%.1f%%)\n", t.style.symbol("↻"), result.EndpointName, result.PassedRuns, result.Runs, result.PassRate())
+	}
+}
+
+// warnings lists the warnings (e.g. deprecation notices) raised by
+// endpoints
+func (t *Text) warnings(results []*runner.Result) {
+	hasWarnings := false
+	for _, result := range results {
+		if len(result.Warnings) > 0 {
+			hasWarnings = true
+			break
+		}
+	}
+	if !hasWarnings {
+		return
+	}
+
+	t.heading("WARNINGS")
+	for _, result := range results {
+		for _, warning := range result.Warnings {
+			t.printf("  %s %s: %s\n", t.style.paint(colorYellow, t.style.symbol("⚠")), result.EndpointName, redact.String(warning))
+		}
+	}
+}
+
+// security lists the security grade of each scanned endpoint
+func (t *Text) security(results []*runner.Result) {
+	heading := false
+	for _, result := range results {
+		if result.Security == nil {
+			continue
+		}
+		if !heading {
+			t.heading("SECURITY HEADERS")
+			heading = true
+		}
+		grade := result.Security.Grade
+		t.printf("  %s %s: %d finding(s)\n", t.style.paint(gradeColor(grade), grade), result.EndpointName, len(result.Security.Findings))
+	}
+}
+
+// Regressions lists the endpoints that regressed against previous runs
+func (t *Text) Regressions(regressions []history.Regression, previousRuns int) {
+	t.heading("REGRESSIONS")
+	switch {
+	case previousRuns == 0:
+		t.printf("  No previous runs recorded yet\n")
+	case len(regressions) == 0:
+		t.printf("  None compared to %d previous run(s)\n", previousRuns)
+	}
+	for _, regression := range regressions {
+		t.printf("  %s %s: %s\n", t.style.paint(colorYellow, t.style.symbol("↘")), regression.Endpoint, regression.Reason)
+	}
+	t.rule("=")
+}
+
+// Baseline lists the new failures, fixed endpoints and slowdowns compared
+// to the baseline
+func (t *Text) Baseline(diff *history.Diff, path string) {
+	t.heading("BASELINE COMPARISON")
+	if diff.Empty() {
+		t.printf("  No changes compared to %s\n", path)
+	}
+	for _, name := range diff.NewFailures {
+		t.printf("  %s %s: newly failing\n", t.style.paint(colorRed, t.style.symbol("✗")), name)
+	}
+	for _, name := range diff.Fixed {
+		t.printf("  %s %s: fixed\n", t.style.paint(colorGreen, t.style.symbol("✓")), name)
+	}
+	for _, regression := range diff.Slower {
+		t.printf("  %s %s: %s\n", t.style.paint(colorYellow, t.style.symbol("↘")), regression.Endpoint, regression.Reason)
+	}
+	t.rule("=")
+}
+
+// Notef prints a notice on a line of its own
+func (t *Text) Notef(format string, args ...any) {
+	t.printf(format+"\n", args...)
+}
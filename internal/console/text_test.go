@@ -0,0 +1,158 @@
+package console
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hutstep/entra-id-api-tester/internal/client"
+	"github.com/hutstep/entra-id-api-tester/internal/config"
+	"github.com/hutstep/entra-id-api-tester/internal/history"
+	"github.com/hutstep/entra-id-api-tester/internal/runner"
+	"github.com/hutstep/entra-id-api-tester/internal/secscan"
+)
+
+func testResults() []*runner.Result {
+	return []*runner.Result{
+		{
+			EndpointName: "users",
+			StatusCode:   200,
+			Duration:     150 * time.Millisecond,
+			Phases:       []runner.PhaseResult{{Phase: runner.PhaseAuth, Passed: true}, {Phase: runner.PhaseConnect, Passed: true}},
+			Warnings:     []string{"Deprecation: true"},
+			Polls:        3,
+			Latency: []runner.LatencyResult{{
+				Objective: config.LatencyObjective{Percentile: 95, Max: 500 * time.Millisecond},
+				Duration:  320 * time.Millisecond,
+				Samples:   20,
+			}},
+			OperationDuration: 2 * time.Second,
+			Redirects:         []client.Redirect{{URL: "https://api.example.com/users", Location: "https://api.example.com/v2/users", StatusCode: 301}},
+			Security: &secscan.Result{
+				Grade:    "B",
+				Findings: []secscan.Finding{{Check: secscan.CheckServer, Message: `Server discloses "Kestrel"`}},
+			},
+		},
+		{
+			EndpointName: "orders",
+			StatusCode:   500,
+			Duration:     80 * time.Millisecond,
+			Phases:       []runner.PhaseResult{{Phase: runner.PhaseAuth, Passed: true}, {Phase: runner.PhaseConnect, Passed: true}},
+			Attempts: []runner.Attempt{{
+				StatusCode: 500,
+				TraceID:    "4bf92f3577b34da6a3ce929d0e0e4736",
+				RequestIDs: map[string]string{"request-id": "0bd5d1d4-0000-4000-8000-000000000003", "x-ms-request-id": "6f1c2d3e-0000-4000-8000-000000000001"},
+			}},
+			Err: &runner.PhaseError{Phase: runner.PhaseResponse, Summary: "Unexpected status code: 500"},
+		},
+		runner.Skip(&config.Endpoint{Name: "items"}, "orders"),
+	}
+}
+
+// render runs every formatter method with the test results
+func render(formatter Formatter) {
+	cfg := &config.Config{Endpoints: []config.Endpoint{
+		{Name: "users", URL: "https://api.example.com/users", Method: "GET"},
+		{Name: "legacy", URL: "https://legacy.example.com", Method: "GET", TLS: &config.TLSConfig{InsecureSkipVerify: true}},
+	}}
+	formatter.Start(cfg)
+	for i, result := range testResults() {
+		formatter.Testing(i+1, 3, &cfg.Endpoints[0])
+		formatter.Result(result)
+	}
+	formatter.Cancelled(context.Canceled)
+	formatter.Summary(testResults(), 4, errors.New("interrupted"))
+	formatter.Regressions([]history.Regression{{Endpoint: "orders", Reason: "newly failing"}}, 2)
+	formatter.Baseline(&history.Diff{NewFailures: []string{"orders"}, Fixed: []string{"items"}}, "baseline.json")
+	formatter.Notef("Report written to %s", "report.json")
+}
+
+func TestText(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     Options
+		expected []string
+		absent   []string
+	}{
+		{
+			name: "default",
+			expected: []string{
+				"Loaded configuration with 2 endpoint(s)\n",
+				"⚠️  WARNING: TLS certificate verification is DISABLED for \"legacy\" (insecureSkipVerify)\n",
+				"[1/3] Testing: users\n    URL: https://api.example.com/users\n    Method: GET\n",
+				"    ✓ PASS - All checks passed (Duration: 150ms)\n",
+				"    ↪ REDIRECT - 301 https://api.example.com/users → https://api.example.com/v2/users\n",
+				"    ⏱ LATENCY - p95 320ms over 20 run(s) (objective: p95 < 500ms)\n",
+				"    ⏱ ASYNC - Operation completed in 2s after 3 poll(s)\n",
+				"    ⚠ WARNING - Deprecation: true\n",
+				"    ✗ FAIL - Unexpected status code: 500 (Duration: 80ms)\n      • Authentication: PASSED\n      • Connectivity: PASSED\n      • Response Status: FAILED (Status Code: 500)\n      • Trace ID: 4bf92f3577b34da6a3ce929d0e0e4736\n      • x-ms-request-id: 6f1c2d3e-0000-4000-8000-000000000001\n      • request-id: 0bd5d1d4-0000-4000-8000-000000000003\n",
+				"    ⊘ SKIP - Skipped (dependency failed: orders)\n",
+				"    ⊘ CANCELLED - context canceled\n",
+				"RUN ABORTED after 3/4 endpoint(s): interrupted\n",
+				"Total Endpoints:           3\nPassed:                    1 (33.3%)\nFailed:                    1 (33.3%)\nSkipped:                   1 (33.3%)\n",
+				"  • Response Failures:        1\n",
+				"WARNINGS\n",
+				"  ⚠ users: Deprecation: true\n",
+				"    🛡 SECURITY - Grade B\n      • Server discloses \"Kestrel\"\n",
+				"SECURITY HEADERS\n",
+				"  B users: 1 finding(s)\n",
+				"REGRESSIONS\n",
+				"  ↘ orders: newly failing\n",
+				"  ✗ orders: newly failing\n  ✓ items: fixed\n",
+				"Report written to report.json\n",
+			},
+			absent: []string{"\033["},
+		},
+		{
+			name: "plain",
+			opts: Options{Plain: true},
+			expected: []string{
+				"WARNING: TLS certificate verification is DISABLED",
+				"    PASS - All checks passed",
+				"    FAIL - Unexpected status code: 500 (Duration: 80ms)\n      - Authentication: PASSED\n",
+				"    SKIP - Skipped",
+				"    REDIRECT - 301 https://api.example.com/users -> https://api.example.com/v2/users\n",
+				"  - Response Failures:        1\n",
+				"  ! users: Deprecation: true\n",
+				"    SECURITY - Grade B\n      - Server discloses",
+				"  v orders: newly failing\n",
+				"  x orders: newly failing\n  + items: fixed\n",
+			},
+			absent: []string{"✓", "✗", "⊘", "⚠", "•", "↘", "→", "\033["},
+		},
+		{
+			name: "color",
+			opts: Options{Color: true},
+			expected: []string{
+				"\033[32m✓ PASS\033[0m - All checks passed",
+				"\033[31m✗ FAIL\033[0m - Unexpected status code: 500",
+				"Authentication: \033[32mPASSED\033[0m",
+				"Response Status: \033[31mFAILED\033[0m",
+				"Failed:                    \033[31m1 (33.3%)\033[0m\n",
+				"\033[1mSUMMARY\033[0m",
+				"\033[33m🛡 SECURITY\033[0m - Grade B",
+				"  \033[33mB\033[0m users: 1 finding(s)\n",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf strings.Builder
+			render(New(&buf, tt.opts))
+			got := buf.String()
+			for _, expected := range tt.expected {
+				if !strings.Contains(got, expected) {
+					t.Errorf("Expected output to contain %q, got:\n%s", expected, got)
+				}
+			}
+			for _, absent := range tt.absent {
+				if strings.Contains(got, absent) {
+					t.Errorf("Expected output not to contain %q, got:\n%s", absent, got)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+// Package dataset loads the rows a data-driven endpoint runs its request
+// for, from a CSV file with a header row or a JSON array of objects.
+package dataset
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/hutstep/entra-id-api-tester/internal/jsonpath"
+)
+
+// Dataset is the content of a data file
+type Dataset struct {
+	// Columns are the CSV header, or the keys of the JSON objects in
+	// sorted order
+	Columns []string
+	// Rows map column names to values. A JSON row may lack columns other
+	// rows have.
+	Rows []map[string]string
+}
+
+// CheckFormat reports whether path names a supported data file by its
+// extension, without reading it
+func CheckFormat(path string) error {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv", ".json":
+		return nil
+	default:
+		return fmt.Errorf("unsupported data file %s (must be .csv or .json)", path)
+	}
+}
+
+// Load reads the data file at path. A file without rows is an error, as
+// an endpoint would silently run no request.
+func Load(path string) (*Dataset, error) {
+	if err := CheckFormat(path); err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path) // #nosec G304 - the data file is chosen by the configuration owner
+	if err != nil {
+		return nil, fmt.Errorf("failed to open data file: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	var data *Dataset
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		data, err = readCSV(file)
+	} else {
+		data, err = readJSON(file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read data file %s: %w", path, err)
+	}
+	if len(data.Rows) == 0 {
+		return nil, fmt.Errorf("data file %s has no rows", path)
+	}
+	return data, nil
+}
+
+// readCSV reads a header row followed by data rows with as many fields
+func readCSV(r io.Reader) (*Dataset, error) {
+	reader := csv.NewReader(r)
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return &Dataset{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	// Spreadsheet applications often start UTF-8 files with a byte order mark
+	header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	for i, column := range header {
+		if column == "" {
+			return nil, fmt.Errorf("column %d has no name", i+1)
+		}
+		if slices.Contains(header[:i], column) {
+			return nil, fmt.Errorf("duplicate column %q", column)
+		}
+	}
+
+	data := &Dataset{Columns: header}
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return data, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		row := make(map[string]string, len(header))
+		for i, column := range header {
+			row[column] = record[i]
+		}
+		data.Rows = append(data.Rows, row)
+	}
+}
+
+// readJSON reads an array of objects, rendering values as they would be
+// captured from a response
+func readJSON(r io.Reader) (*Dataset, error) {
+	var objects []map[string]interface{}
+	if err := json.NewDecoder(r).Decode(&objects); err != nil {
+		return nil, fmt.Errorf("expected an array of objects: %w", err)
+	}
+
+	columns := make(map[string]bool)
+	data := &Dataset{Rows: make([]map[string]string, len(objects))}
+	for i, object := range objects {
+		row := make(map[string]string, len(object))
+		for key, value := range object {
+			row[key] = jsonpath.Stringify(value)
+			columns[key] = true
+		}
+		data.Rows[i] = row
+	}
+	data.Columns = slices.Sorted(maps.Keys(columns))
+	return data, nil
+}
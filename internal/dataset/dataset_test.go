@@ -0,0 +1,74 @@
+package dataset
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name          string
+		file          string
+		content       string
+		expectColumns []string
+		expectRows    []map[string]string
+		expectErr     bool
+	}{
+		{
+			name:          "csv",
+			file:          "users.csv",
+			content:       "\ufeffname,role\nalice,admin\n\"bob, jr\",reader\n",
+			expectColumns: []string{"name", "role"},
+			expectRows:    []map[string]string{{"name": "alice", "role": "admin"}, {"name": "bob, jr", "role": "reader"}},
+		},
+		{
+			name:          "json",
+			file:          "users.JSON",
+			content:       `[{"name": "alice", "age": 30}, {"name": "bob", "active": true}]`,
+			expectColumns: []string{"active", "age", "name"},
+			expectRows:    []map[string]string{{"name": "alice", "age": "30"}, {"name": "bob", "active": "true"}},
+		},
+		{name: "csv without rows", file: "users.csv", content: "name,role\n", expectErr: true},
+		{name: "empty csv", file: "users.csv", content: "", expectErr: true},
+		{name: "csv with missing field", file: "users.csv", content: "name,role\nalice\n", expectErr: true},
+		{name: "duplicate column", file: "users.csv", content: "name,name\na,b\n", expectErr: true},
+		{name: "unnamed column", file: "users.csv", content: "name,\na,b\n", expectErr: true},
+		{name: "json object", file: "users.json", content: `{"name": "alice"}`, expectErr: true},
+		{name: "empty json array", file: "users.json", content: `[]`, expectErr: true},
+		{name: "unsupported format", file: "users.xlsx", content: "name\nalice\n", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatalf("Failed to write data file: %v", err)
+			}
+
+			data, err := Load(path)
+			if tt.expectErr {
+				if err == nil {
+					t.Errorf("Expected error, got %+v", data)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(data.Columns, tt.expectColumns) {
+				t.Errorf("Expected columns %v, got %v", tt.expectColumns, data.Columns)
+			}
+			if !reflect.DeepEqual(data.Rows, tt.expectRows) {
+				t.Errorf("Expected rows %v, got %v", tt.expectRows, data.Rows)
+			}
+		})
+	}
+}
+
+func TestLoad_MissingFile(t *testing.T) {
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.csv")); err == nil {
+		t.Error("Expected error for a missing file, got nil")
+	}
+}
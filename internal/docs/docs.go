@@ -0,0 +1,238 @@
+// Package docs renders a configuration as Markdown documentation: a catalog
+// of the tested endpoints with their methods, scopes and assertions, behind
+// the "api-tester docs" subcommand. Credentials are never included.
+package docs
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/hutstep/entra-id-api-tester/internal/assert"
+	"github.com/hutstep/entra-id-api-tester/internal/config"
+)
+
+// Render writes the Markdown catalog of cfg to w. Source names the
+// configuration file in the introduction and may be empty.
+func Render(w io.Writer, cfg *config.Config, source string) error {
+	var b strings.Builder
+
+	b.WriteString("# API Test Catalog\n\n")
+	if source != "" {
+		fmt.Fprintf(&b, "Generated from `%s` by api-tester. ", source)
+	}
+	fmt.Fprintf(&b, "%d endpoint(s) are tested.\n\n", len(cfg.Endpoints))
+
+	b.WriteString("| # | Endpoint | Method | URL | Scope |\n")
+	b.WriteString("|---|----------|--------|-----|-------|\n")
+	for i := range cfg.Endpoints {
+		endpoint := &cfg.Endpoints[i]
+		fmt.Fprintf(&b, "| %d | [%s](#%s) | %s | `%s` | `%s` |\n",
+			i+1, escape(endpoint.Name), anchor(endpoint.Name), endpoint.Method, escape(endpoint.URL), escape(endpoint.TokenScope()))
+	}
+
+	for i := range cfg.Endpoints {
+		b.WriteString("\n")
+		if err := renderEndpoint(&b, &cfg.Endpoints[i]); err != nil {
+			return fmt.Errorf("endpoint %q: %w", cfg.Endpoints[i].Name, err)
+		}
+	}
+
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("failed to write documentation: %w", err)
+	}
+	return nil
+}
+
+// renderEndpoint writes the section for a single endpoint
+func renderEndpoint(b *strings.Builder, endpoint *config.Endpoint) error {
+	fmt.Fprintf(b, "## %s\n\n", endpoint.Name)
+	fmt.Fprintf(b, "- **Request:** `%s %s`\n", endpoint.Method, endpoint.URL)
+	if endpoint.Scope != "" {
+		fmt.Fprintf(b, "- **Scope:** `%s`\n", endpoint.Scope)
+	}
+	if endpoint.TenantID != "" {
+		fmt.Fprintf(b, "- **Tenant:** `%s`\n", endpoint.TenantID)
+	}
+	if len(endpoint.Tenants) > 0 {
+		fmt.Fprintf(b, "- **Tenants:** `%s` (one token each)\n", strings.Join(endpoint.Tenants, "`, `"))
+	}
+	if endpoint.CredentialRef != "" {
+		fmt.Fprintf(b, "- **Credential:** `%s`\n", endpoint.CredentialRef)
+	}
+	if endpoint.StaticToken != "" {
+		b.WriteString("- **Token:** static, not acquired from Entra ID\n")
+	}
+	if endpoint.ApimSubscriptionKey != "" {
+		b.WriteString("- **APIM subscription key:** sent as `Ocp-Apim-Subscription-Key`\n")
+	}
+	if endpoint.DataFile != "" {
+		fmt.Fprintf(b, "- **Data file:** `%s` (one request per row)\n", endpoint.DataFile)
+	}
+	if endpoint.Schedule != "" {
+		fmt.Fprintf(b, "- **Schedule:** `%s`\n", endpoint.Schedule)
+	}
+	if hooks := endpoint.Hooks; hooks != nil && len(hooks.PreRequest) > 0 {
+		fmt.Fprintf(b, "- **Pre-request hook:** `%s`\n", strings.Join(hooks.PreRequest, " "))
+	}
+	if hooks := endpoint.Hooks; hooks != nil && len(hooks.PostResponse) > 0 {
+		fmt.Fprintf(b, "- **Post-response hook:** `%s`\n", strings.Join(hooks.PostResponse, " "))
+	}
+	if len(endpoint.DependsOn) > 0 {
+		links := make([]string, len(endpoint.DependsOn))
+		for i, dependency := range endpoint.DependsOn {
+			links[i] = fmt.Sprintf("[%s](#%s)", dependency, anchor(dependency))
+		}
+		fmt.Fprintf(b, "- **Depends on:** %s\n", strings.Join(links, ", "))
+	}
+	if endpoint.Range != "" {
+		fmt.Fprintf(b, "- **Range:** `%s` (expects 206 Partial Content)\n", endpoint.Range)
+	}
+	fmt.Fprintf(b, "- **Expected response:** %s\n", expectedResponse(endpoint))
+	if endpoint.NegativeAuth {
+		b.WriteString("- **Negative auth:** rejects requests without a token or with an invalid token (401/403)\n")
+	}
+	if endpoint.TamperedTokens {
+		b.WriteString("- **Tampered tokens:** rejects tokens with a truncated signature or an expired, re-signed token (401/403)\n")
+	}
+	if endpoint.Contract != nil {
+		operation := ""
+		if endpoint.Contract.OperationID != "" {
+			operation = fmt.Sprintf(" (operation `%s`)", endpoint.Contract.OperationID)
+		}
+		fmt.Fprintf(b, "- **Contract:** `%s`%s\n", endpoint.Contract.Spec, operation)
+	}
+	if endpoint.GoldenFile != "" {
+		fmt.Fprintf(b, "- **Golden file:** `%s`\n", endpoint.GoldenFile)
+	}
+	if endpoint.MaxDurationMs > 0 {
+		fmt.Fprintf(b, "- **Max duration:** %d ms\n", endpoint.MaxDurationMs)
+	}
+	if endpoint.RaceTest != nil {
+		expect := endpoint.RaceTest.Expect
+		if expect == "" {
+			expect = "all"
+		}
+		fmt.Fprintf(b, "- **Race test:** %d concurrent requests, expect %s to succeed\n", endpoint.RaceTest.Concurrency, expect)
+	}
+	if len(endpoint.AcceptLanguages) > 0 {
+		fmt.Fprintf(b, "- **Languages:** %s\n", strings.Join(endpoint.AcceptLanguages, ", "))
+	}
+	if policy := tlsPolicy(endpoint.TLS); policy != "" {
+		fmt.Fprintf(b, "- **TLS policy:** %s\n", policy)
+	}
+
+	if len(endpoint.Assertions) > 0 {
+		b.WriteString("\n**Assertions**\n\n")
+		for i := range endpoint.Assertions {
+			fmt.Fprintf(b, "- `%s`\n", assert.Describe(&endpoint.Assertions[i]))
+		}
+	}
+
+	if len(endpoint.Personas) > 0 {
+		b.WriteString("\n**Personas**\n\n")
+		b.WriteString("| Persona | Expected status |\n")
+		b.WriteString("|---------|-----------------|\n")
+		for _, check := range endpoint.Personas {
+			fmt.Fprintf(b, "| %s | %d |\n", escape(check.Persona), check.ExpectStatus)
+		}
+	}
+
+	if len(endpoint.Scopes) > 0 {
+		b.WriteString("\n**Scopes**\n\n")
+		b.WriteString("| Scope | Expected status |\n")
+		b.WriteString("|-------|-----------------|\n")
+		for _, check := range endpoint.Scopes {
+			fmt.Fprintf(b, "| `%s` | %d |\n", escape(check.Scope), check.ExpectStatus)
+		}
+	}
+
+	if len(endpoint.ExpectLocalized) > 0 {
+		b.WriteString("\n**Localized content**\n\n")
+		languages := make([]string, 0, len(endpoint.ExpectLocalized))
+		for language := range endpoint.ExpectLocalized {
+			languages = append(languages, language)
+		}
+		sort.Strings(languages)
+		for _, language := range languages {
+			fmt.Fprintf(b, "- %s: contains %q\n", language, endpoint.ExpectLocalized[language])
+		}
+	}
+
+	if len(endpoint.Captures) > 0 {
+		b.WriteString("\n**Captures**\n\n")
+		for _, capture := range endpoint.Captures {
+			source := "body `" + capture.JSONPath + "`"
+			if capture.Header != "" {
+				source = "header `" + capture.Header + "`"
+			}
+			fmt.Fprintf(b, "- `{{vars.%s}}` from %s\n", capture.Name, source)
+		}
+	}
+
+	if endpoint.RequestBody != nil {
+		body, err := json.MarshalIndent(endpoint.RequestBody, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode request body: %w", err)
+		}
+		fmt.Fprintf(b, "\n**Request body**\n\n```json\n%s\n```\n", body)
+	}
+
+	return nil
+}
+
+// expectedResponse describes the status the endpoint must return
+func expectedResponse(endpoint *config.Endpoint) string {
+	if endpoint.Range != "" {
+		return "206 Partial Content"
+	}
+	for i := range endpoint.Assertions {
+		if endpoint.Assertions[i].ChecksStatus() {
+			return "as asserted below"
+		}
+	}
+	if len(endpoint.Personas) > 0 {
+		return "per persona, as listed below"
+	}
+	if len(endpoint.Scopes) > 0 {
+		return "per scope, as listed below"
+	}
+	return "2xx"
+}
+
+// tlsPolicy summarizes the endpoint's TLS version and cipher policy
+func tlsPolicy(tlsConfig *config.TLSConfig) string {
+	if !tlsConfig.HasPolicy() {
+		return ""
+	}
+	var parts []string
+	if tlsConfig.RequireVersion != "" {
+		parts = append(parts, "TLS "+tlsConfig.RequireVersion+" or later")
+	}
+	if len(tlsConfig.AllowedCiphers) > 0 {
+		parts = append(parts, "ciphers "+strings.Join(tlsConfig.AllowedCiphers, ", "))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// anchor returns the GitHub-style heading anchor for a section title
+func anchor(title string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(title) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_':
+			b.WriteRune(r)
+		case r == ' ':
+			b.WriteRune('-')
+		}
+	}
+	return b.String()
+}
+
+// escape makes text safe to use inside a Markdown table cell
+func escape(text string) string {
+	return strings.ReplaceAll(text, "|", "\\|")
+}
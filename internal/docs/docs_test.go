@@ -0,0 +1,133 @@
+package docs
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/hutstep/entra-id-api-tester/internal/config"
+)
+
+func TestRender(t *testing.T) {
+	cfg := &config.Config{
+		Endpoints: []config.Endpoint{
+			{
+				Name:                "Create Order",
+				URL:                 "https://api.example.com/orders",
+				Method:              "POST",
+				ClientID:            "client-id",
+				ClientSecret:        "super-secret",
+				TenantID:            "tenant-id",
+				Tenants:             []string{"contoso", "fabrikam"},
+				CredentialRef:       "orders-app",
+				ApimSubscriptionKey: "subscription-key",
+				Scope:               "api://orders/.default",
+				RequestBody:         map[string]interface{}{"sku": "A-1"},
+				Assertions:          []config.Assertion{{Status: 201}, {JSONPath: "$.id"}},
+				Captures:            []config.Capture{{Name: "orderId", JSONPath: "$.id"}},
+				TLS:                 &config.TLSConfig{RequireVersion: "1.2"},
+				MaxDurationMs:       800,
+			},
+			{
+				Name:           "Get Order",
+				URL:            "https://api.example.com/orders/{{vars.orderId}}",
+				Method:         "GET",
+				Scope:          "api://orders/.default",
+				DependsOn:      []string{"Create Order"},
+				NegativeAuth:   true,
+				TamperedTokens: true,
+				Contract:       &config.Contract{Spec: "openapi.yaml", OperationID: "getOrder"},
+				GoldenFile:     "testdata/order.json",
+				Personas:       []config.PersonaCheck{{Persona: "reader", ExpectStatus: 200}, {Persona: "guest", ExpectStatus: 403}},
+			},
+			{
+				Name:   "List Invoices",
+				URL:    "https://api.example.com/invoices",
+				Method: "GET",
+				Scopes: []config.ScopeCheck{{Scope: "api://billing/.default", ExpectStatus: 200}, {Scope: "api://orders/.default", ExpectStatus: 403}},
+			},
+			{
+				Name:        "Delegated Orders",
+				URL:         "https://api.example.com/me/orders",
+				Method:      "GET",
+				Scope:       "api://orders/.default",
+				StaticToken: "static-token",
+				DataFile:    "orders.csv",
+				Schedule:    "@hourly",
+				Hooks:       &config.Hooks{PreRequest: []string{"./sign.sh", "--key", "k1"}, PostResponse: []string{"python3", "check.py"}},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := Render(&buf, cfg, "config.json"); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	output := buf.String()
+
+	expected := []string{
+		"# API Test Catalog",
+		"Generated from `config.json` by api-tester. 4 endpoint(s) are tested.",
+		"| 1 | [Create Order](#create-order) | POST | `https://api.example.com/orders` | `api://orders/.default` |",
+		"## Create Order",
+		"- **Request:** `POST https://api.example.com/orders`",
+		"- **Tenants:** `contoso`, `fabrikam` (one token each)",
+		"- **Credential:** `orders-app`",
+		"- **APIM subscription key:** sent as `Ocp-Apim-Subscription-Key`",
+		"- **Data file:** `orders.csv` (one request per row)",
+		"- **Schedule:** `@hourly`",
+		"- **Pre-request hook:** `./sign.sh --key k1`",
+		"- **Post-response hook:** `python3 check.py`",
+		"- **Expected response:** as asserted below",
+		"- **Max duration:** 800 ms",
+		"- **Negative auth:** rejects requests without a token or with an invalid token (401/403)",
+		"- **Tampered tokens:** rejects tokens with a truncated signature or an expired, re-signed token (401/403)",
+		"- **Contract:** `openapi.yaml` (operation `getOrder`)",
+		"- **Golden file:** `testdata/order.json`",
+		"- **TLS policy:** TLS 1.2 or later",
+		"- `status == 201`",
+		"- `$.id exists`",
+		"- `{{vars.orderId}}` from body `$.id`",
+		"\"sku\": \"A-1\"",
+		"- **Depends on:** [Create Order](#create-order)",
+		"- **Expected response:** per persona, as listed below",
+		"| guest | 403 |",
+		"| 3 | [List Invoices](#list-invoices) | GET | `https://api.example.com/invoices` | `api://billing/.default` |",
+		"- **Token:** static, not acquired from Entra ID",
+		"- **Expected response:** per scope, as listed below",
+		"| `api://orders/.default` | 403 |",
+	}
+	for _, want := range expected {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected output to contain %q\n%s", want, output)
+		}
+	}
+
+	if strings.Contains(output, "super-secret") || strings.Contains(output, "subscription-key") || strings.Contains(output, "static-token") {
+		t.Error("Expected client secrets, subscription keys and tokens to be left out")
+	}
+}
+
+func TestAnchor(t *testing.T) {
+	tests := []struct {
+		title    string
+		expected string
+	}{
+		{"Users", "users"},
+		{"Production API - GET Example", "production-api---get-example"},
+		{"Orders (v2)/list", "orders-v2list"},
+		{"snake_case", "snake_case"},
+	}
+
+	for _, tt := range tests {
+		if got := anchor(tt.title); got != tt.expected {
+			t.Errorf("anchor(%q) = %q, expected %q", tt.title, got, tt.expected)
+		}
+	}
+}
+
+func TestEscape(t *testing.T) {
+	if got := escape("a|b"); got != `a\|b` {
+		t.Errorf("Expected pipe to be escaped, got %q", got)
+	}
+}
@@ -0,0 +1,587 @@
+// Package doctor implements the environment sanity checks behind the
+// "api-tester doctor" subcommand: proxy settings, trusted CAs, DNS, egress IP,
+// a test token acquisition and OpenID discovery with signature verification.
+package doctor
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/net/http/httpproxy"
+
+	"github.com/hutstep/entra-id-api-tester/internal/auth"
+	"github.com/hutstep/entra-id-api-tester/internal/client"
+	"github.com/hutstep/entra-id-api-tester/internal/config"
+	"github.com/hutstep/entra-id-api-tester/internal/runner"
+)
+
+// Status is the outcome of a single check
+type Status string
+
+// Check outcomes
+const (
+	StatusOK   Status = "OK"
+	StatusWarn Status = "WARN"
+	StatusFail Status = "FAIL"
+	StatusInfo Status = "INFO"
+)
+
+// DefaultLoginHost is the Entra ID authority host used for connectivity checks
+const DefaultLoginHost = "login.microsoftonline.com"
+
+// Check is the result of a single diagnostic
+type Check struct {
+	Name   string
+	Detail string
+	Status Status
+}
+
+// Options configures which diagnostics run
+type Options struct {
+	// Endpoint, when set, is used for the test token acquisition and its URL
+	// is included in the proxy report
+	Endpoint *config.Endpoint
+	// TokenProvider acquires the test token
+	TokenProvider auth.TokenProvider
+	// EchoURL returns the caller's public IP address as plain text
+	EchoURL string
+	// LoginHost is the authority host to resolve and handshake with
+	LoginHost string
+	// ResolvConf is the resolver configuration file to read DNS servers from
+	ResolvConf string
+	Timeout    time.Duration
+}
+
+// Run executes all diagnostics and returns their results in order
+func Run(ctx context.Context, opts Options) []Check {
+	if opts.LoginHost == "" {
+		opts.LoginHost = DefaultLoginHost
+	}
+	if opts.Timeout == 0 {
+		opts.Timeout = 10 * time.Second
+	}
+
+	var checks []Check
+	checks = append(checks, ProxyChecks(os.Getenv, opts.LoginHost, opts.Endpoint)...)
+	checks = append(checks, CAChecks(ctx, os.Getenv, opts.LoginHost, opts.Timeout)...)
+	checks = append(checks, DNSChecks(ctx, opts.ResolvConf, opts.LoginHost)...)
+	if opts.EchoURL != "" {
+		egressClient, err := endpointClient(opts.Endpoint, opts.Timeout)
+		if err != nil {
+			checks = append(checks, Check{Name: "Egress IP (via " + opts.EchoURL + ")", Status: StatusFail, Detail: err.Error()})
+		} else {
+			checks = append(checks, EgressIPCheck(ctx, egressClient, opts.EchoURL))
+		}
+	}
+	if opts.Endpoint != nil && opts.TokenProvider != nil {
+		tokenCtx := auth.WithRegion(ctx, opts.Endpoint.AzureRegion)
+		if certificate := opts.Endpoint.CertificateKeyVault; certificate != nil {
+			tokenCtx = auth.WithKeyVaultCertificate(tokenCtx, auth.KeyVaultCertificate{Vault: certificate.Vault, Name: certificate.Name})
+		}
+		checks = append(checks, TokenCheck(tokenCtx, opts.TokenProvider, opts.Endpoint))
+		client := &http.Client{Timeout: opts.Timeout}
+		checks = append(checks, DiscoveryChecks(tokenCtx, client, "https://"+opts.LoginHost, opts.TokenProvider, opts.Endpoint)...)
+	}
+	return checks
+}
+
+// proxyVariables are the environment variables Go's HTTP stack honors
+var proxyVariables = []string{"HTTPS_PROXY", "HTTP_PROXY", "NO_PROXY"}
+
+// ProxyChecks reports the proxy environment and the effective proxy for the
+// authority host and the endpoint URL
+func ProxyChecks(getenv func(string) string, loginHost string, endpoint *config.Endpoint) []Check {
+	var checks []Check
+
+	for _, name := range proxyVariables {
+		value := getenv(name)
+		if value == "" {
+			value = getenv(strings.ToLower(name))
+		}
+		if value == "" {
+			value = "(not set)"
+		}
+		checks = append(checks, Check{Name: "Proxy " + name, Status: StatusInfo, Detail: redactProxyValue(value)})
+	}
+
+	targets := []string{"https://" + loginHost + "/"}
+	if endpoint != nil {
+		targets = append(targets, endpoint.URL)
+	}
+	for _, target := range targets {
+		checks = append(checks, effectiveProxy(getenv, target))
+	}
+
+	return checks
+}
+
+// effectiveProxy determines which proxy a request to target would use
+func effectiveProxy(getenv func(string) string, target string) Check {
+	check := Check{Name: "Effective proxy for " + target, Status: StatusInfo}
+
+	targetURL, err := url.Parse(target)
+	if err != nil {
+		check.Status = StatusFail
+		check.Detail = fmt.Sprintf("invalid URL: %v", err)
+		return check
+	}
+
+	proxyFunc := proxyConfig(getenv).ProxyFunc()
+	proxyURL, err := proxyFunc(targetURL)
+	switch {
+	case err != nil:
+		check.Status = StatusFail
+		check.Detail = fmt.Sprintf("invalid proxy configuration: %v", err)
+	case proxyURL == nil:
+		check.Detail = "direct (no proxy)"
+	default:
+		check.Detail = redactURL(proxyURL)
+	}
+	return check
+}
+
+// proxyConfig builds the proxy configuration Go's HTTP stack derives from
+// the environment, reading variables through getenv so it can be tested
+func proxyConfig(getenv func(string) string) *httpproxy.Config {
+	lookup := func(name string) string {
+		if value := getenv(name); value != "" {
+			return value
+		}
+		return getenv(strings.ToLower(name))
+	}
+	return &httpproxy.Config{
+		HTTPProxy:  lookup("HTTP_PROXY"),
+		HTTPSProxy: lookup("HTTPS_PROXY"),
+		NoProxy:    lookup("NO_PROXY"),
+	}
+}
+
+// redactProxyValue hides any password in the userinfo of a proxy variable,
+// which may lack a scheme, e.g. "user:secret@proxy.corp:8080"
+func redactProxyValue(value string) string {
+	start := 0
+	if i := strings.Index(value, "://"); i >= 0 {
+		start = i + len("://")
+	}
+	at := strings.LastIndex(value, "@")
+	if at < start {
+		return value
+	}
+	username, _, hasPassword := strings.Cut(value[start:at], ":")
+	if !hasPassword {
+		return value
+	}
+	return value[:start] + username + ":xxxxx" + value[at:]
+}
+
+// redactURL hides any password embedded in a proxy URL
+func redactURL(u *url.URL) string {
+	if _, hasPassword := u.User.Password(); hasPassword {
+		redacted := *u
+		redacted.User = url.UserPassword(u.User.Username(), "xxxxx")
+		return redacted.String()
+	}
+	return u.String()
+}
+
+// CAChecks reports custom CA environment overrides, loads the system pool and
+// verifies a TLS handshake with the authority host, through the proxy the
+// environment read through getenv configures
+func CAChecks(ctx context.Context, getenv func(string) string, loginHost string, timeout time.Duration) []Check {
+	var checks []Check
+
+	for _, name := range []string{"SSL_CERT_FILE", "SSL_CERT_DIR"} {
+		if value := os.Getenv(name); value != "" {
+			checks = append(checks, Check{Name: "CA override " + name, Status: StatusInfo, Detail: value})
+		}
+	}
+
+	if _, err := x509.SystemCertPool(); err != nil {
+		checks = append(checks, Check{Name: "System CA store", Status: StatusFail, Detail: err.Error()})
+	} else {
+		checks = append(checks, Check{Name: "System CA store", Status: StatusOK, Detail: "loaded"})
+	}
+
+	checks = append(checks, tlsHandshakeCheck(ctx, getenv, loginHost, timeout))
+	return checks
+}
+
+// tlsHandshakeCheck connects to host:443 the way token requests do, through
+// the effective proxy, and reports the verified chain. Behind a proxy that
+// inspects TLS, the issuer is the proxy's CA.
+func tlsHandshakeCheck(ctx context.Context, getenv func(string) string, host string, timeout time.Duration) Check {
+	check := Check{Name: "TLS handshake with " + host}
+
+	proxyFunc := proxyConfig(getenv).ProxyFunc()
+	proxyURL, err := proxyFunc(&url.URL{Scheme: "https", Host: host})
+	if err != nil {
+		check.Status = StatusFail
+		check.Detail = fmt.Sprintf("invalid proxy configuration: %v", err)
+		return check
+	}
+	via := ""
+	if proxyURL != nil {
+		via = " via proxy " + redactURL(proxyURL)
+	}
+
+	transport := &http.Transport{
+		Proxy:               func(req *http.Request) (*url.URL, error) { return proxyFunc(req.URL) },
+		TLSClientConfig:     &tls.Config{MinVersion: tls.VersionTLS12},
+		TLSHandshakeTimeout: timeout,
+	}
+	defer transport.CloseIdleConnections()
+	client := &http.Client{
+		Transport: transport,
+		Timeout:   timeout,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, "https://"+host+"/", http.NoBody)
+	if err != nil {
+		check.Status = StatusFail
+		check.Detail = err.Error()
+		return check
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		check.Status = StatusFail
+		check.Detail = err.Error() + via
+		return check
+	}
+	defer resp.Body.Close()
+
+	if resp.TLS == nil {
+		check.Status = StatusFail
+		check.Detail = "no TLS connection state" + via
+		return check
+	}
+	check.Status = StatusOK
+	check.Detail = fmt.Sprintf("%s, issuer: %s%s", tls.VersionName(resp.TLS.Version), issuerName(*resp.TLS), via)
+	return check
+}
+
+// issuerName returns the issuer of the leaf certificate
+func issuerName(state tls.ConnectionState) string {
+	if len(state.PeerCertificates) == 0 {
+		return "unknown"
+	}
+	return state.PeerCertificates[0].Issuer.CommonName
+}
+
+// DNSChecks reports configured nameservers and resolves the authority host
+func DNSChecks(ctx context.Context, resolvConf, loginHost string) []Check {
+	var checks []Check
+
+	if resolvConf == "" {
+		resolvConf = "/etc/resolv.conf"
+	}
+	servers, err := readNameservers(resolvConf)
+	switch {
+	case err != nil:
+		checks = append(checks, Check{Name: "DNS servers", Status: StatusInfo, Detail: fmt.Sprintf("unavailable (%v)", err)})
+	case len(servers) == 0:
+		checks = append(checks, Check{Name: "DNS servers", Status: StatusWarn, Detail: "none configured in " + resolvConf})
+	default:
+		checks = append(checks, Check{Name: "DNS servers", Status: StatusInfo, Detail: strings.Join(servers, ", ")})
+	}
+
+	addresses, err := net.DefaultResolver.LookupHost(ctx, loginHost)
+	if err != nil {
+		checks = append(checks, Check{Name: "Resolve " + loginHost, Status: StatusFail, Detail: err.Error()})
+	} else {
+		checks = append(checks, Check{Name: "Resolve " + loginHost, Status: StatusOK, Detail: strings.Join(addresses, ", ")})
+	}
+
+	return checks
+}
+
+// readNameservers parses nameserver entries from a resolv.conf file
+func readNameservers(path string) ([]string, error) {
+	file, err := os.Open(path) // #nosec G304 - resolver config path is fixed or provided by the user
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if closeErr := file.Close(); closeErr != nil {
+			_ = closeErr
+		}
+	}()
+	return parseNameservers(file)
+}
+
+// parseNameservers extracts nameserver addresses from resolv.conf content
+func parseNameservers(r io.Reader) ([]string, error) {
+	var servers []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == "nameserver" {
+			servers = append(servers, fields[1])
+		}
+	}
+	return servers, scanner.Err()
+}
+
+// endpointClient returns an HTTP client that connects like the endpoint's
+// API requests, through its proxyUrl and with its resolve and TLS settings,
+// or like Go's default client without an endpoint
+func endpointClient(endpoint *config.Endpoint, timeout time.Duration) (*http.Client, error) {
+	var options client.TransportOptions
+	if endpoint != nil {
+		endpointOptions, err := runner.TransportOptions(endpoint)
+		if err != nil {
+			return nil, err
+		}
+		if endpointOptions != nil {
+			options = *endpointOptions
+		}
+	}
+	transport, err := client.NewTransport(options)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Client{Transport: transport, Timeout: timeout}, nil
+}
+
+// EgressIPCheck asks an echo service which public IP the tester appears
+// from when it connects with httpClient
+func EgressIPCheck(ctx context.Context, httpClient *http.Client, echoURL string) Check {
+	check := Check{Name: "Egress IP (via " + echoURL + ")"}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, echoURL, http.NoBody)
+	if err != nil {
+		check.Status = StatusFail
+		check.Detail = err.Error()
+		return check
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		check.Status = StatusFail
+		check.Detail = err.Error()
+		return check
+	}
+	defer func() {
+		if closeErr := resp.Body.Close(); closeErr != nil {
+			_ = closeErr
+		}
+	}()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 256))
+	if err != nil || resp.StatusCode != http.StatusOK {
+		check.Status = StatusFail
+		check.Detail = fmt.Sprintf("unexpected response (status %d)", resp.StatusCode)
+		return check
+	}
+
+	check.Status = StatusOK
+	check.Detail = strings.TrimSpace(string(body))
+	return check
+}
+
+// TokenCheck acquires a token with the endpoint's credentials, managed
+// identity or federated credential, or checks its static token
+func TokenCheck(ctx context.Context, tokenProvider auth.TokenProvider, endpoint *config.Endpoint) Check {
+	check := Check{Name: "Token acquisition (" + endpoint.Name + ")"}
+	if len(endpoint.Personas) > 0 && endpoint.ClientID == "" {
+		check.Status = StatusInfo
+		check.Detail = "endpoint runs as personas only; skipped"
+		return check
+	}
+
+	tokenProvider, err := endpointTokenProvider(tokenProvider, endpoint)
+	if err != nil {
+		check.Status = StatusFail
+		check.Detail = err.Error()
+		return check
+	}
+
+	started := time.Now()
+	token, err := tokenProvider.GetAccessToken(ctx, endpoint.ClientID, endpoint.ClientSecret, endpoint.TokenTenantID(), endpoint.TokenScope())
+	if err != nil {
+		check.Status = StatusFail
+		check.Detail = err.Error()
+		return check
+	}
+
+	check.Status = StatusOK
+	check.Detail = fmt.Sprintf("acquired in %v", time.Since(started).Truncate(time.Millisecond))
+	if endpoint.ManagedIdentity {
+		check.Detail += " from the " + auth.ManagedIdentitySource() + " managed identity endpoint"
+	}
+	if claims, err := auth.ParseClaims(token); err == nil {
+		if skew := claims.ClockSkew(time.Now()); skew > 0 {
+			check.Status = StatusWarn
+			check.Detail += fmt.Sprintf(", local clock %v behind issuer", skew)
+		}
+	}
+	return check
+}
+
+// endpointTokenProvider returns the token provider of endpoint: its static
+// token, its host's managed identity or GitHub Actions OIDC token through its
+// tokenProxyUrl, or else tokenProvider
+func endpointTokenProvider(tokenProvider auth.TokenProvider, endpoint *config.Endpoint) (auth.TokenProvider, error) {
+	if endpoint.StaticToken != "" {
+		return &auth.StaticTokenProvider{Token: endpoint.StaticToken}, nil
+	}
+	if !endpoint.ManagedIdentity && endpoint.FederatedCredential != config.FederatedCredentialGitHub {
+		return tokenProvider, nil
+	}
+	managedIdentity, gitHubActions, err := runner.HostTokenProviders(endpoint.TokenProxyURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tokenProxyUrl: %w", err)
+	}
+	if endpoint.ManagedIdentity {
+		return managedIdentity, nil
+	}
+	return gitHubActions, nil
+}
+
+// DiscoveryChecks fetches the OpenID configuration and JWKS of each tenant
+// the endpoint acquires tokens from, and verifies a token acquired for that
+// tenant against the published signing keys
+func DiscoveryChecks(ctx context.Context, client *http.Client, authority string, tokenProvider auth.TokenProvider, endpoint *config.Endpoint) []Check {
+	if len(endpoint.Personas) > 0 && endpoint.ClientID == "" {
+		return nil
+	}
+	tokenProvider, err := endpointTokenProvider(tokenProvider, endpoint)
+	if err != nil {
+		return []Check{{Name: "OpenID discovery", Status: StatusFail, Detail: err.Error()}}
+	}
+
+	var checks []Check
+	for _, tenant := range discoveryTenants(endpoint) {
+		checks = append(checks, discoveryChecks(ctx, client, authority, tokenProvider, endpoint, tenant)...)
+	}
+	return checks
+}
+
+// discoveryTenants returns the tenants an endpoint acquires tokens from; a
+// static token names its tenant in the tid claim
+func discoveryTenants(endpoint *config.Endpoint) []string {
+	if len(endpoint.Tenants) > 0 {
+		return endpoint.Tenants
+	}
+	if tenant := endpoint.TokenTenantID(); tenant != "" {
+		return []string{tenant}
+	}
+	if claims, err := auth.DecodePayload(endpoint.StaticToken); err == nil {
+		if tid, ok := claims["tid"].(string); ok && tid != "" {
+			return []string{tid}
+		}
+	}
+	return nil
+}
+
+// discoveryChecks runs the metadata and signature checks for one tenant
+func discoveryChecks(ctx context.Context, client *http.Client, authority string, tokenProvider auth.TokenProvider, endpoint *config.Endpoint, tenant string) []Check {
+	metadata := Check{Name: "OpenID discovery (" + tenant + ")"}
+	configuration, err := auth.FetchOpenIDConfiguration(ctx, client, authority, tenant)
+	if err != nil {
+		metadata.Status = StatusFail
+		metadata.Detail = err.Error()
+		return []Check{metadata}
+	}
+	if problems := configuration.Problems(tenant); len(problems) > 0 {
+		metadata.Status = StatusFail
+		metadata.Detail = strings.Join(problems, "; ")
+		return []Check{metadata}
+	}
+	keys, err := auth.FetchJWKS(ctx, client, configuration.JWKSURI)
+	if err != nil {
+		metadata.Status = StatusFail
+		metadata.Detail = err.Error()
+		return []Check{metadata}
+	}
+	if len(keys.Keys) == 0 {
+		metadata.Status = StatusFail
+		metadata.Detail = configuration.JWKSURI + " publishes no signing keys"
+		return []Check{metadata}
+	}
+	metadata.Status = StatusOK
+	metadata.Detail = fmt.Sprintf("issuer %s, %d signing keys", configuration.Issuer, len(keys.Keys))
+
+	return []Check{metadata, signatureCheck(ctx, tokenProvider, endpoint, tenant, configuration, keys)}
+}
+
+// signatureCheck verifies a token for tenant against the published keys and
+// compares its issuer with the discovered one
+func signatureCheck(ctx context.Context, tokenProvider auth.TokenProvider, endpoint *config.Endpoint, tenant string, configuration *auth.OpenIDConfiguration, keys *auth.JSONWebKeySet) Check {
+	check := Check{Name: "Token signature (" + tenant + ")"}
+
+	token, err := tokenProvider.GetAccessToken(ctx, endpoint.ClientID, endpoint.ClientSecret, tenant, endpoint.TokenScope())
+	if err != nil {
+		check.Status = StatusFail
+		check.Detail = fmt.Sprintf("failed to acquire token: %v", err)
+		return check
+	}
+
+	key, err := auth.VerifySignature(token, keys)
+	switch {
+	case errors.Is(err, auth.ErrNonceToken):
+		check.Status = StatusInfo
+		check.Detail = "token has a nonce header (a Microsoft Graph token); only Graph can validate its signature"
+		return check
+	case errors.Is(err, auth.ErrUnknownKey):
+		check.Status = StatusFail
+		check.Detail = fmt.Sprintf("%v: the signing keys rolled over, or the app uses its own signing key (%s?appid=%s)", err, configuration.JWKSURI, endpoint.ClientID)
+		return check
+	case err != nil:
+		check.Status = StatusFail
+		check.Detail = err.Error()
+		return check
+	}
+
+	check.Status = StatusOK
+	check.Detail = "valid, kid " + key.KeyID
+	if cert, err := key.Certificate(); err == nil && time.Now().After(cert.NotAfter) {
+		check.Status = StatusWarn
+		check.Detail += fmt.Sprintf(", but its certificate expired at %s", cert.NotAfter.UTC().Format(time.RFC3339))
+	}
+	if issuer := tokenIssuer(token); issuer != "" && issuer != expectedIssuer(configuration.Issuer, token) && !strings.HasPrefix(issuer, "https://sts.windows.net/") {
+		check.Status = StatusWarn
+		check.Detail += fmt.Sprintf(", but the token issuer %s differs from the discovered issuer %s", issuer, configuration.Issuer)
+	}
+	return check
+}
+
+// tokenIssuer returns the iss claim of a token
+func tokenIssuer(token string) string {
+	claims, err := auth.DecodePayload(token)
+	if err != nil {
+		return ""
+	}
+	issuer, ok := claims["iss"].(string)
+	if !ok {
+		return ""
+	}
+	return issuer
+}
+
+// expectedIssuer substitutes the token's tid into the {tenantid} placeholder
+// of a multi-tenant (common, organizations) issuer
+func expectedIssuer(issuer, token string) string {
+	if !strings.Contains(issuer, "{tenantid}") {
+		return issuer
+	}
+	claims, err := auth.DecodePayload(token)
+	if err != nil {
+		return issuer
+	}
+	if tid, ok := claims["tid"].(string); ok {
+		return strings.ReplaceAll(issuer, "{tenantid}", tid)
+	}
+	return issuer
+}
@@ -0,0 +1,167 @@
+// Package dump writes the requests and responses of failed endpoints to
+// files, so that CI failures can be diagnosed from build artifacts without
+// re-running the suite. Credentials are redacted like in HAR files.
+package dump
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/hutstep/entra-id-api-tester/internal/client"
+	"github.com/hutstep/entra-id-api-tester/internal/redact"
+	"github.com/hutstep/entra-id-api-tester/internal/runner"
+)
+
+// maxNameLen limits the part of a file name derived from the endpoint name
+const maxNameLen = 60
+
+// Recorder buffers the exchanges of the endpoint under test and writes them
+// to a file in its directory when the endpoint fails. Endpoints must run one
+// at a time.
+type Recorder struct {
+	dir       string
+	exchanges []*client.Exchange
+	files     int
+	mu        sync.Mutex
+}
+
+// New creates a recorder that writes to dir, creating it if needed
+func New(dir string) (*Recorder, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create dump directory: %w", err)
+	}
+	return &Recorder{dir: dir}, nil
+}
+
+// Record implements client.Recorder
+func (r *Recorder) Record(exchange *client.Exchange) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.exchanges = append(r.exchanges, exchange)
+}
+
+// Reset discards the exchanges recorded so far, e.g. before the next
+// endpoint runs
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.exchanges = nil
+}
+
+// Dump writes the result of a failed endpoint and the exchanges recorded
+// since the last Reset to a new file, and returns its path. The exchanges
+// are discarded.
+func (r *Recorder) Dump(result *runner.Result) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	exchanges := r.exchanges
+	r.exchanges = nil
+	r.files++
+
+	path := filepath.Join(r.dir, fmt.Sprintf("%03d-%s.txt", r.files, fileName(result.EndpointName)))
+	if err := os.WriteFile(path, []byte(Format(result, exchanges)), 0o600); err != nil {
+		return "", fmt.Errorf("failed to write dump: %w", err)
+	}
+	return path, nil
+}
+
+// Format renders the result of an endpoint and its exchanges as text, with
+// credentials redacted
+func Format(result *runner.Result, exchanges []*client.Exchange) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Endpoint: %s\n", result.EndpointName)
+	if result.Method != "" {
+		fmt.Fprintf(&b, "# Request: %s %s\n", result.Method, redact.String(result.URL))
+	}
+	if phase := result.FailedPhase(); phase != "" {
+		fmt.Fprintf(&b, "# Failed phase: %s\n", phase)
+	}
+	if result.Err != nil {
+		fmt.Fprintf(&b, "# Error: %s\n", redact.String(result.Err.Error()))
+	}
+	if len(exchanges) == 0 {
+		b.WriteString("\nNo response was received.\n")
+		return b.String()
+	}
+
+	for i, exchange := range exchanges {
+		fmt.Fprintf(&b, "\n### Exchange %d of %d (%s, %v)\n\n", i+1, len(exchanges),
+			exchange.Started.UTC().Format(time.RFC3339Nano), exchange.Duration.Round(time.Millisecond))
+		writeRequest(&b, exchange)
+		b.WriteString("\n")
+		writeResponse(&b, exchange.Response)
+	}
+	return b.String()
+}
+
+// writeRequest writes the request line, headers and body
+func writeRequest(b *strings.Builder, exchange *client.Exchange) {
+	req := exchange.Request
+	fmt.Fprintf(b, "%s %s %s\n", req.Method, redact.String(req.URL.String()), req.Proto)
+	writeHeaders(b, req.Header)
+	writeBody(b, exchange.RequestBody)
+}
+
+// writeResponse writes the status line, headers and body
+func writeResponse(b *strings.Builder, resp *client.Response) {
+	fmt.Fprintf(b, "%s %d %s\n", resp.Proto, resp.StatusCode, http.StatusText(resp.StatusCode))
+	writeHeaders(b, resp.Headers)
+	writeBody(b, resp.Body)
+}
+
+// writeHeaders writes redacted headers, sorted for stable output
+func writeHeaders(b *strings.Builder, headers http.Header) {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, value := range headers[name] {
+			fmt.Fprintf(b, "%s: %s\n", name, redact.Header(name, value))
+		}
+	}
+}
+
+// writeBody writes a redacted text body after a blank line, or the size of
+// a binary one
+func writeBody(b *strings.Builder, body []byte) {
+	switch {
+	case len(body) == 0:
+	case utf8.Valid(body):
+		fmt.Fprintf(b, "\n%s\n", strings.TrimRight(redact.String(string(body)), "\n"))
+	default:
+		fmt.Fprintf(b, "\n<%d bytes of binary content>\n", len(body))
+	}
+}
+
+// fileName turns an endpoint name into a safe file name, e.g. "Orders API
+// (v2)" into "orders-api-v2"
+func fileName(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	result := strings.TrimRight(b.String(), "-")
+	if len(result) > maxNameLen {
+		result = strings.TrimRight(result[:maxNameLen], "-")
+	}
+	if result == "" {
+		return "endpoint"
+	}
+	return result
+}
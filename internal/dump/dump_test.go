@@ -0,0 +1,138 @@
+package dump
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hutstep/entra-id-api-tester/internal/client"
+	"github.com/hutstep/entra-id-api-tester/internal/runner"
+)
+
+func TestRecorder_Dump(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Set-Cookie", "session=secret-session")
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte(`{"error":"boom"}`))
+	}))
+	defer server.Close()
+
+	dir := filepath.Join(t.TempDir(), "dumps")
+	recorder, err := New(dir)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	apiClient := client.NewAPIClient()
+	apiClient.SetRecorder(recorder)
+	send := func() {
+		_, err := apiClient.Send(context.Background(), &client.Request{
+			Method:      "POST",
+			URL:         server.URL + "/orders",
+			AccessToken: "super-secret-token",
+			Body:        map[string]interface{}{"name": "test"},
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	// The exchange of a passing endpoint is discarded
+	send()
+	recorder.Reset()
+	send()
+
+	result := &runner.Result{
+		EndpointName: "Orders API (v2)",
+		Method:       "POST",
+		URL:          server.URL + "/orders",
+		StatusCode:   500,
+		Err:          &runner.PhaseError{Phase: runner.PhaseResponse, Summary: "Unexpected status code: 500"},
+	}
+	path, err := recorder.Dump(result)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if filepath.Base(path) != "001-orders-api-v2.txt" {
+		t.Errorf("Unexpected file name: %s", path)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read dump: %v", err)
+	}
+	text := string(content)
+
+	expected := []string{
+		"# Endpoint: Orders API (v2)\n",
+		"# Request: POST " + server.URL + "/orders\n",
+		"# Failed phase: response\n",
+		"# Error: Unexpected status code: 500\n",
+		"### Exchange 1 of 1",
+		"POST " + server.URL + "/orders HTTP/1.1\n",
+		"Authorization: Bearer ",
+		"\n\n{\"name\":\"test\"}\n",
+		"HTTP/1.1 500 Internal Server Error\n",
+		"Content-Type: application/json\n",
+		"\n\n{\"error\":\"boom\"}\n",
+	}
+	for _, line := range expected {
+		if !strings.Contains(text, line) {
+			t.Errorf("Expected dump to contain %q, got:\n%s", line, text)
+		}
+	}
+	for _, secret := range []string{"super-secret-token", "secret-session", "Exchange 2"} {
+		if strings.Contains(text, secret) {
+			t.Errorf("Expected dump not to contain %q, got:\n%s", secret, text)
+		}
+	}
+
+	// Dumping discards the exchanges, and numbers the next file
+	path, err = recorder.Dump(&runner.Result{EndpointName: "Users", Err: errors.New("failed")})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	content, err = os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read dump: %v", err)
+	}
+	if filepath.Base(path) != "002-users.txt" || !strings.Contains(string(content), "No response was received.") {
+		t.Errorf("Unexpected dump %s:\n%s", path, content)
+	}
+}
+
+func TestFormat_BinaryBody(t *testing.T) {
+	request := httptest.NewRequest(http.MethodGet, "https://api.example.com/logo.png", nil)
+	exchange := &client.Exchange{
+		Request:  request,
+		Response: &client.Response{Proto: "HTTP/2.0", StatusCode: 200, Body: []byte{0x89, 'P', 'N', 'G', 0xff}},
+	}
+	text := Format(&runner.Result{EndpointName: "Logo"}, []*client.Exchange{exchange})
+	if !strings.Contains(text, "HTTP/2.0 200 OK\n\n<5 bytes of binary content>\n") {
+		t.Errorf("Expected the size of the binary body, got:\n%s", text)
+	}
+}
+
+func TestFileName(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"Users", "users"},
+		{"Orders API (v2)", "orders-api-v2"},
+		{"  ../../etc/passwd", "etc-passwd"},
+		{"Größe", "gr-e"},
+		{"???", "endpoint"},
+		{strings.Repeat("a", 70), strings.Repeat("a", 60)},
+	}
+
+	for _, tt := range tests {
+		if got := fileName(tt.name); got != tt.expected {
+			t.Errorf("fileName(%q) = %q, expected %q", tt.name, got, tt.expected)
+		}
+	}
+}
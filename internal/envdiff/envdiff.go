@@ -0,0 +1,235 @@
+// Package envdiff compares the results of testing the same endpoints in two
+// environments, e.g. staging and production before a promotion: their
+// status codes, the shape of their JSON responses and their latency.
+package envdiff
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hutstep/entra-id-api-tester/internal/runner"
+)
+
+// maxShapeDifferences bounds the shape differences listed per endpoint
+const maxShapeDifferences = 5
+
+// Outcome is what one environment returned for an endpoint
+type Outcome struct {
+	// Err is nil when the endpoint passed
+	Err error
+	// Shape maps the paths of a JSON body, such as $.items[].id, to their
+	// types; it is nil when the body is empty or not JSON
+	Shape      map[string]string
+	Duration   time.Duration
+	StatusCode int
+	// Ran is false when the environment does not define the endpoint
+	Ran bool
+}
+
+// Summary formats the outcome for a side-by-side table, e.g. "200 in 120ms"
+func (o *Outcome) Summary() string {
+	switch {
+	case !o.Ran:
+		return "-"
+	case o.StatusCode == 0:
+		return "FAIL"
+	case o.Err != nil:
+		return fmt.Sprintf("%d FAIL in %v", o.StatusCode, o.Duration.Round(time.Millisecond))
+	default:
+		return fmt.Sprintf("%d in %v", o.StatusCode, o.Duration.Round(time.Millisecond))
+	}
+}
+
+// Row compares an endpoint between the two environments
+type Row struct {
+	Endpoint    string
+	Differences []string
+	Left        Outcome
+	Right       Outcome
+}
+
+// Options configure a comparison
+type Options struct {
+	// Left and Right name the environments in differences
+	Left, Right string
+	// LatencyThreshold is the percentage by which the slower environment
+	// may exceed the faster one before latency counts as a difference
+	LatencyThreshold float64
+}
+
+// Compare pairs the results of the two environments by endpoint name, in
+// the order of left followed by endpoints only right defines. Results
+// should keep their response bodies, see runner.Runner.KeepBodies.
+func Compare(left, right []*runner.Result, opts Options) []Row {
+	var rows []Row
+	index := make(map[string]int)
+	for _, result := range left {
+		index[result.EndpointName] = len(rows)
+		rows = append(rows, Row{Endpoint: result.EndpointName, Left: outcome(result)})
+	}
+	for _, result := range right {
+		i, ok := index[result.EndpointName]
+		if !ok {
+			i = len(rows)
+			rows = append(rows, Row{Endpoint: result.EndpointName})
+		}
+		rows[i].Right = outcome(result)
+	}
+	for i := range rows {
+		rows[i].Differences = differences(&rows[i], opts)
+	}
+	return rows
+}
+
+// Differing returns the number of rows with differences
+func Differing(rows []Row) int {
+	count := 0
+	for i := range rows {
+		if len(rows[i].Differences) > 0 {
+			count++
+		}
+	}
+	return count
+}
+
+// outcome extracts what the comparison needs from a result
+func outcome(result *runner.Result) Outcome {
+	return Outcome{
+		Err:        result.Err,
+		Shape:      Shape(result.Body),
+		Duration:   result.Duration,
+		StatusCode: result.StatusCode,
+		Ran:        !result.Skipped,
+	}
+}
+
+// differences lists how the two outcomes of a row differ
+func differences(row *Row, opts Options) []string {
+	left, right := &row.Left, &row.Right
+	switch {
+	case !left.Ran && !right.Ran:
+		return nil
+	case !left.Ran:
+		return []string{"not run in " + opts.Left}
+	case !right.Ran:
+		return []string{"not run in " + opts.Right}
+	}
+
+	var found []string
+	if left.StatusCode != right.StatusCode {
+		found = append(found, fmt.Sprintf("status %d in %s, %d in %s", left.StatusCode, opts.Left, right.StatusCode, opts.Right))
+	} else if (left.Err == nil) != (right.Err == nil) {
+		failed := opts.Left
+		if right.Err != nil {
+			failed = opts.Right
+		}
+		found = append(found, "fails only in "+failed)
+	}
+	found = append(found, shapeDifferences(left.Shape, right.Shape, opts)...)
+	if difference := latencyDifference(left.Duration, right.Duration, opts); difference != "" {
+		found = append(found, difference)
+	}
+	return found
+}
+
+// shapeDifferences lists the paths whose presence or type differs
+func shapeDifferences(left, right map[string]string, opts Options) []string {
+	if left == nil || right == nil {
+		return nil
+	}
+	paths := slices.Sorted(maps.Keys(left))
+	for path := range right {
+		if _, ok := left[path]; !ok {
+			paths = append(paths, path)
+		}
+	}
+	slices.Sort(paths)
+
+	var found []string
+	for _, path := range paths {
+		leftType, inLeft := left[path]
+		rightType, inRight := right[path]
+		switch {
+		case !inLeft:
+			found = append(found, fmt.Sprintf("%s only in %s", path, opts.Right))
+		case !inRight:
+			found = append(found, fmt.Sprintf("%s only in %s", path, opts.Left))
+		case leftType != rightType:
+			found = append(found, fmt.Sprintf("%s is %s in %s, %s in %s", path, leftType, opts.Left, rightType, opts.Right))
+		}
+	}
+	if len(found) > maxShapeDifferences {
+		found = append(found[:maxShapeDifferences], fmt.Sprintf("%d more shape differences", len(found)-maxShapeDifferences))
+	}
+	return found
+}
+
+// latencyDifference describes the latency gap when the slower environment
+// exceeds the faster one by more than the threshold
+func latencyDifference(left, right time.Duration, opts Options) string {
+	faster, slower, name := left, right, opts.Right
+	if right < left {
+		faster, slower, name = right, left, opts.Left
+	}
+	if faster <= 0 || float64(slower) <= float64(faster)*(1+opts.LatencyThreshold/100) {
+		return ""
+	}
+	return fmt.Sprintf("%.0f%% slower in %s (%v vs %v)", (float64(slower)/float64(faster)-1)*100, name,
+		slower.Round(time.Millisecond), faster.Round(time.Millisecond))
+}
+
+// Shape maps every path of a JSON body to its type: object, array, string,
+// number, boolean or null. Array elements share the path segment [], and
+// paths whose elements have different types list them joined with "|".
+func Shape(body []byte) map[string]string {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil
+	}
+	var document interface{}
+	if err := json.Unmarshal(body, &document); err != nil {
+		return nil
+	}
+	types := make(map[string]map[string]bool)
+	walk("$", document, types)
+
+	shape := make(map[string]string, len(types))
+	for path, seen := range types {
+		shape[path] = strings.Join(slices.Sorted(maps.Keys(seen)), "|")
+	}
+	return shape
+}
+
+// walk records the type of value at path and descends into it
+func walk(path string, value interface{}, types map[string]map[string]bool) {
+	record := func(kind string) {
+		if types[path] == nil {
+			types[path] = make(map[string]bool)
+		}
+		types[path][kind] = true
+	}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		record("object")
+		for key, item := range v {
+			walk(path+"."+key, item, types)
+		}
+	case []interface{}:
+		record("array")
+		for _, item := range v {
+			walk(path+"[]", item, types)
+		}
+	case string:
+		record("string")
+	case float64:
+		record("number")
+	case bool:
+		record("boolean")
+	default:
+		record("null")
+	}
+}
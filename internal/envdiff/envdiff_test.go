@@ -0,0 +1,102 @@
+package envdiff
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/hutstep/entra-id-api-tester/internal/runner"
+)
+
+func TestShape(t *testing.T) {
+	shape := Shape([]byte(`{"id": 1, "tags": ["a", 2], "owner": {"name": "x", "email": null}}`))
+	expected := map[string]string{
+		"$":             "object",
+		"$.id":          "number",
+		"$.tags":        "array",
+		"$.tags[]":      "number|string",
+		"$.owner":       "object",
+		"$.owner.name":  "string",
+		"$.owner.email": "null",
+	}
+	if !reflect.DeepEqual(shape, expected) {
+		t.Errorf("Expected %v, got %v", expected, shape)
+	}
+	if shape := Shape([]byte("not json")); shape != nil {
+		t.Errorf("Expected no shape for a non-JSON body, got %v", shape)
+	}
+	if shape := Shape(nil); shape != nil {
+		t.Errorf("Expected no shape for an empty body, got %v", shape)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	failed := errors.New("response check failed")
+	left := []*runner.Result{
+		{EndpointName: "same", StatusCode: 200, Duration: 100 * time.Millisecond, Body: []byte(`{"id": 1}`)},
+		{EndpointName: "status", StatusCode: 200, Duration: 100 * time.Millisecond},
+		{EndpointName: "shape", StatusCode: 200, Duration: 100 * time.Millisecond, Body: []byte(`{"id": 1, "name": "a"}`)},
+		{EndpointName: "slow", StatusCode: 200, Duration: 100 * time.Millisecond},
+		{EndpointName: "assertion", StatusCode: 200, Duration: 100 * time.Millisecond},
+		{EndpointName: "left only", StatusCode: 200},
+	}
+	right := []*runner.Result{
+		{EndpointName: "same", StatusCode: 200, Duration: 140 * time.Millisecond, Body: []byte(`{"id": 2}`)},
+		{EndpointName: "status", StatusCode: 500, Duration: 100 * time.Millisecond},
+		{EndpointName: "shape", StatusCode: 200, Duration: 100 * time.Millisecond, Body: []byte(`{"id": "1", "email": "b"}`)},
+		{EndpointName: "slow", StatusCode: 200, Duration: 300 * time.Millisecond},
+		{EndpointName: "assertion", StatusCode: 200, Duration: 100 * time.Millisecond, Err: failed},
+		{EndpointName: "right only", StatusCode: 200},
+	}
+
+	rows := Compare(left, right, Options{Left: "dev", Right: "staging", LatencyThreshold: 50})
+
+	expected := map[string][]string{
+		"same":   nil,
+		"status": {"status 200 in dev, 500 in staging"},
+		"shape": {
+			"$.email only in staging",
+			"$.id is number in dev, string in staging",
+			"$.name only in dev",
+		},
+		"slow":       {"200% slower in staging (300ms vs 100ms)"},
+		"assertion":  {"fails only in staging"},
+		"left only":  {"not run in staging"},
+		"right only": {"not run in dev"},
+	}
+	if len(rows) != len(expected) {
+		t.Fatalf("Expected %d rows, got %d", len(expected), len(rows))
+	}
+	if rows[len(rows)-1].Endpoint != "right only" {
+		t.Errorf("Expected endpoints only the right environment defines last, got %s", rows[len(rows)-1].Endpoint)
+	}
+	for _, row := range rows {
+		if !reflect.DeepEqual(row.Differences, expected[row.Endpoint]) {
+			t.Errorf("%s: expected differences %q, got %q", row.Endpoint, expected[row.Endpoint], row.Differences)
+		}
+	}
+	if got := Differing(rows); got != 6 {
+		t.Errorf("Expected 6 differing endpoints, got %d", got)
+	}
+}
+
+func TestOutcomeSummary(t *testing.T) {
+	tests := []struct {
+		outcome  Outcome
+		expected string
+	}{
+		{Outcome{Ran: true, StatusCode: 200, Duration: 1234567 * time.Microsecond}, "200 in 1.235s"},
+		{Outcome{Ran: true, StatusCode: 404, Duration: time.Second, Err: errors.New("failed")}, "404 FAIL in 1s"},
+		{Outcome{Ran: true, Err: errors.New("no response")}, "FAIL"},
+		{Outcome{}, "-"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			if got := tt.outcome.Summary(); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+// Package expr compiles and evaluates CEL assertion expressions against
+// API responses, e.g.
+//
+//	response.status == 200 && json.value.size() > 0 && duration < duration("2s")
+package expr
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/google/cel-go/cel"
+	celast "github.com/google/cel-go/common/ast"
+	"github.com/google/cel-go/common/types"
+
+	"github.com/hutstep/entra-id-api-tester/internal/client"
+)
+
+var (
+	envOnce sync.Once
+	env     *cel.Env
+	envErr  error
+	// programs caches compiled expressions by source
+	programs sync.Map
+)
+
+// environment returns the CEL environment expressions are compiled in:
+// response (status, headers with lowercase names, body), json (the parsed
+// body, or null) and duration (the request's round trip time)
+func environment() (*cel.Env, error) {
+	envOnce.Do(func() {
+		env, envErr = cel.NewEnv(
+			cel.Variable("response", cel.MapType(cel.StringType, cel.DynType)),
+			cel.Variable("json", cel.DynType),
+			cel.Variable("duration", cel.DurationType),
+		)
+	})
+	return env, envErr
+}
+
+// compile parses and type-checks an expression
+func compile(source string) (*cel.Ast, error) {
+	environment, err := environment()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CEL environment: %w", err)
+	}
+	ast, issues := environment.Compile(source)
+	if issues != nil && issues.Err() != nil {
+		return nil, fmt.Errorf("invalid CEL expression: %w", issues.Err())
+	}
+	if ast.OutputType() != cel.BoolType && ast.OutputType() != cel.DynType {
+		return nil, fmt.Errorf("CEL expression must evaluate to a bool, not %s", ast.OutputType())
+	}
+	return ast, nil
+}
+
+// Validate checks that source compiles to a boolean expression
+func Validate(source string) error {
+	_, err := program(source)
+	return err
+}
+
+// program returns the compiled program for source, compiling it once
+func program(source string) (cel.Program, error) {
+	if cached, ok := programs.Load(source); ok {
+		if prg, ok := cached.(cel.Program); ok {
+			return prg, nil
+		}
+	}
+	ast, err := compile(source)
+	if err != nil {
+		return nil, err
+	}
+	environment, err := environment()
+	if err != nil {
+		return nil, err
+	}
+	prg, err := environment.Program(ast)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CEL expression: %w", err)
+	}
+	programs.Store(source, prg)
+	return prg, nil
+}
+
+// Evaluate runs the expression against the response and reports whether it
+// holds
+func Evaluate(source string, response *client.Response) (bool, error) {
+	prg, err := program(source)
+	if err != nil {
+		return false, err
+	}
+
+	out, _, err := prg.Eval(map[string]interface{}{
+		"response": map[string]interface{}{
+			"status":  response.StatusCode,
+			"headers": headers(response.Headers),
+			"body":    string(response.Body),
+		},
+		"json":     parseBody(response.Body),
+		"duration": response.Duration,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to evaluate: %w", err)
+	}
+	holds, ok := out.(types.Bool)
+	if !ok {
+		return false, errors.New("did not evaluate to a bool")
+	}
+	return bool(holds), nil
+}
+
+// ReferencesStatus reports whether the expression reads response.status,
+// i.e. checks the status code itself
+func ReferencesStatus(source string) bool {
+	return selects(source, "response", "status")
+}
+
+// ReferencesGraphQLErrors reports whether the expression reads json.errors,
+// i.e. checks the errors of a GraphQL response itself
+func ReferencesGraphQLErrors(source string) bool {
+	return selects(source, "json", "errors")
+}
+
+// selects reports whether the expression selects field of the variable
+// ident, e.g. response.status
+func selects(source, ident, field string) bool {
+	ast, err := compile(source)
+	if err != nil {
+		return false
+	}
+	found := false
+	celast.PostOrderVisit(ast.NativeRep().Expr(), celast.NewExprVisitor(func(e celast.Expr) {
+		if e.Kind() != celast.SelectKind {
+			return
+		}
+		selection := e.AsSelect()
+		operand := selection.Operand()
+		if selection.FieldName() == field && operand.Kind() == celast.IdentKind && operand.AsIdent() == ident {
+			found = true
+		}
+	}))
+	return found
+}
+
+// headers flattens response headers into lowercase names with their values
+// joined by ", "
+func headers(header http.Header) map[string]string {
+	flattened := make(map[string]string, len(header))
+	for name, values := range header {
+		flattened[strings.ToLower(name)] = strings.Join(values, ", ")
+	}
+	return flattened
+}
+
+// parseBody decodes a JSON body, or returns nil when it is not JSON
+func parseBody(body []byte) interface{} {
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return nil
+	}
+	return value
+}
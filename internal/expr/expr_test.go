@@ -0,0 +1,99 @@
+package expr
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hutstep/entra-id-api-tester/internal/client"
+)
+
+func TestEvaluate(t *testing.T) {
+	response := &client.Response{
+		StatusCode: 200,
+		Headers:    http.Header{"Content-Type": []string{"application/json"}},
+		Body:       []byte(`{"value": [{"id": 1}, {"id": 2}], "count": 2, "status": "open"}`),
+		Duration:   1500 * time.Millisecond,
+	}
+
+	tests := []struct {
+		expression string
+		expectErr  string
+		holds      bool
+	}{
+		{expression: `response.status == 200 && json.value.size() > 0 && duration < duration("2s")`, holds: true},
+		{expression: `duration < duration("1s")`},
+		{expression: `json.count == 2 && json.value[1].id == 2`, holds: true},
+		{expression: `response.headers["content-type"].startsWith("application/json")`, holds: true},
+		{expression: `response.body.contains("closed")`},
+		{expression: `json.status in ["open", "pending"]`, holds: true},
+		{expression: `json.missing == 1`, expectErr: "no such key"},
+		{expression: `response.status ==`, expectErr: "invalid CEL expression"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			holds, err := Evaluate(tt.expression, response)
+			if tt.expectErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectErr) {
+					t.Fatalf("Expected error containing %q, got %v", tt.expectErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if holds != tt.holds {
+				t.Errorf("Evaluate() = %v, expected %v", holds, tt.holds)
+			}
+		})
+	}
+}
+
+func TestEvaluate_NonJSONBody(t *testing.T) {
+	holds, err := Evaluate(`json == null && response.body == "OK"`, &client.Response{StatusCode: 200, Body: []byte("OK")})
+	if err != nil || !holds {
+		t.Errorf("Expected json to be null for a plain text body, got %v, %v", holds, err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := Validate(`response.status == 200`); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if err := Validate(`response.status + 1`); err == nil {
+		t.Error("Expected an error for a non-bool expression")
+	}
+	if err := Validate(`request.method == "GET"`); err == nil {
+		t.Error("Expected an error for an undeclared variable")
+	}
+}
+
+func TestReferencesStatus(t *testing.T) {
+	tests := map[string]bool{
+		`response.status == 204`:                          true,
+		`json.value.size() > 0 || response.status == 404`: true,
+		`json.status == "open"`:                           false,
+		`response.body.contains("status")`:                false,
+	}
+	for expression, expected := range tests {
+		if got := ReferencesStatus(expression); got != expected {
+			t.Errorf("ReferencesStatus(%q) = %v, expected %v", expression, got, expected)
+		}
+	}
+}
+
+func TestReferencesGraphQLErrors(t *testing.T) {
+	tests := map[string]bool{
+		`!has(json.errors)`:                             true,
+		`json.errors[0].extensions.code == "FORBIDDEN"`: true,
+		`json.data.errors.size() == 0`:                  false,
+		`response.body.contains("errors")`:              false,
+	}
+	for expression, expected := range tests {
+		if got := ReferencesGraphQLErrors(expression); got != expected {
+			t.Errorf("ReferencesGraphQLErrors(%q) = %v, expected %v", expression, got, expected)
+		}
+	}
+}
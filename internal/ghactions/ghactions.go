@@ -0,0 +1,90 @@
+// Package ghactions integrates the tester with GitHub Actions: it formats
+// workflow commands that surface failures as annotations and writes step
+// outputs that later steps can branch on.
+package ghactions
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Enabled reports whether the process runs inside a GitHub Actions job
+func Enabled(getenv func(string) string) bool {
+	return getenv("GITHUB_ACTIONS") == "true"
+}
+
+// Error formats an error annotation workflow command
+func Error(title, message string) string {
+	return command("error", title, message)
+}
+
+// Warning formats a warning annotation workflow command
+func Warning(title, message string) string {
+	return command("warning", title, message)
+}
+
+// command formats a workflow command with a title property
+func command(name, title, message string) string {
+	return fmt.Sprintf("::%s title=%s::%s", name, escapeProperty(title), escapeData(message))
+}
+
+// escapeData escapes a workflow command message
+func escapeData(value string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(value)
+}
+
+// escapeProperty escapes a workflow command property value
+func escapeProperty(value string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(value)
+}
+
+// Output is a step output
+type Output struct {
+	Name  string
+	Value string
+}
+
+// WriteOutputs appends outputs to the file GitHub Actions reads step
+// outputs from (the GITHUB_OUTPUT environment variable). Multi-line values
+// use a random heredoc delimiter.
+func WriteOutputs(path string, outputs []Output) error {
+	var b strings.Builder
+	for _, output := range outputs {
+		if !strings.ContainsAny(output.Value, "\r\n") {
+			fmt.Fprintf(&b, "%s=%s\n", output.Name, output.Value)
+			continue
+		}
+		delimiter, err := randomDelimiter()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(&b, "%s<<%s\n%s\n%s\n", output.Name, delimiter, output.Value, delimiter)
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600) // #nosec G304 - path is provided by the Actions runner
+	if err != nil {
+		return fmt.Errorf("failed to open GITHUB_OUTPUT: %w", err)
+	}
+	if _, err := file.WriteString(b.String()); err != nil {
+		if closeErr := file.Close(); closeErr != nil {
+			_ = closeErr
+		}
+		return fmt.Errorf("failed to write GITHUB_OUTPUT: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write GITHUB_OUTPUT: %w", err)
+	}
+	return nil
+}
+
+// randomDelimiter returns a heredoc delimiter that cannot appear in values
+func randomDelimiter() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate output delimiter: %w", err)
+	}
+	return "ghadelimiter_" + hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,86 @@
+package ghactions
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+)
+
+func TestEnabled(t *testing.T) {
+	env := map[string]string{"GITHUB_ACTIONS": "true"}
+	if !Enabled(func(name string) string { return env[name] }) {
+		t.Error("Expected GitHub Actions to be detected")
+	}
+	if Enabled(func(string) string { return "" }) {
+		t.Error("Expected GitHub Actions not to be detected")
+	}
+}
+
+func TestAnnotations(t *testing.T) {
+	tests := []struct {
+		name     string
+		got      string
+		expected string
+	}{
+		{
+			name:     "error",
+			got:      Error("API test failed: Orders", "Unexpected status code: 500"),
+			expected: "::error title=API test failed%3A Orders::Unexpected status code: 500",
+		},
+		{
+			name:     "warning",
+			got:      Warning("Users, v2", "Slow request: took 12s"),
+			expected: "::warning title=Users%2C v2::Slow request: took 12s",
+		},
+		{
+			name:     "multi-line message",
+			got:      Error("Orders", "first line\nsecond line, 100%"),
+			expected: "::error title=Orders::first line%0Asecond line, 100%25",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, tt.got)
+			}
+		})
+	}
+}
+
+func TestWriteOutputs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "github_output")
+	if err := os.WriteFile(path, []byte("existing=1\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	err := WriteOutputs(path, []Output{
+		{Name: "passed", Value: "3"},
+		{Name: "report-path", Value: ""},
+		{Name: "errors", Value: "first\nsecond"},
+	})
+	if err != nil {
+		t.Fatalf("WriteOutputs failed: %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pattern := regexp.MustCompile(`^existing=1\npassed=3\nreport-path=\nerrors<<(ghadelimiter_[0-9a-f]{32})\nfirst\nsecond\n(ghadelimiter_[0-9a-f]{32})\n$`)
+	match := pattern.FindStringSubmatch(string(content))
+	if match == nil {
+		t.Fatalf("Unexpected output file:\n%s", content)
+	}
+	if match[1] != match[2] {
+		t.Errorf("Expected matching heredoc delimiters, got %s and %s", match[1], match[2])
+	}
+}
+
+func TestWriteOutputs_InvalidPath(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing", "github_output")
+	if err := WriteOutputs(path, []Output{{Name: "passed", Value: "1"}}); err == nil {
+		t.Error("Expected error for missing directory, got nil")
+	}
+}
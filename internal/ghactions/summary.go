@@ -0,0 +1,90 @@
+package ghactions
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hutstep/entra-id-api-tester/internal/report"
+)
+
+// resultIcons mark endpoint statuses in the step summary
+var resultIcons = map[string]string{
+	report.StatusPassed:  "✅",
+	report.StatusFailed:  "❌",
+	report.StatusSkipped: "⏭️",
+}
+
+// Summary renders a run report as the Markdown job summary: the counts,
+// a table with one row per endpoint and the errors of failed endpoints
+func Summary(runReport *report.Report) string {
+	var b strings.Builder
+	summary := runReport.Summary
+	switch {
+	case runReport.Aborted != "":
+		fmt.Fprintf(&b, "## ⚠️ API tests aborted\n\n%s\n\n", cell(runReport.Aborted))
+	case summary.Failed > 0:
+		b.WriteString("## ❌ API tests failed\n\n")
+	default:
+		b.WriteString("## ✅ API tests passed\n\n")
+	}
+	fmt.Fprintf(&b, "**%d** endpoint(s): **%d** passed, **%d** failed, **%d** skipped in %v\n\n",
+		summary.Total, summary.Passed, summary.Failed, summary.Skipped,
+		runReport.FinishedAt.Sub(runReport.StartedAt).Round(time.Millisecond))
+	if len(runReport.Endpoints) == 0 {
+		return b.String()
+	}
+
+	b.WriteString("| Endpoint | Result | Status code | Duration |\n")
+	b.WriteString("| --- | --- | --- | --- |\n")
+	for _, endpoint := range runReport.Endpoints {
+		result := resultIcons[endpoint.Status] + " " + endpoint.Status
+		if endpoint.FailedPhase != "" {
+			result += " (" + endpoint.FailedPhase + ")"
+		}
+		if endpoint.Flaky {
+			result += " flaky"
+		}
+		statusCode := "-"
+		if endpoint.StatusCode > 0 {
+			statusCode = fmt.Sprint(endpoint.StatusCode)
+		}
+		fmt.Fprintf(&b, "| %s | %s | %s | %d ms |\n", cell(endpoint.Name), result, statusCode, endpoint.DurationMs)
+	}
+
+	if summary.Failed == 0 {
+		return b.String()
+	}
+	b.WriteString("\n### Failures\n\n")
+	for _, endpoint := range runReport.Endpoints {
+		if endpoint.Status == report.StatusFailed {
+			fmt.Fprintf(&b, "- **%s** (%s): %s\n", cell(endpoint.Name), endpoint.FailedPhase, cell(endpoint.Error))
+		}
+	}
+	return b.String()
+}
+
+// cell escapes a value for a single line of Markdown, such as a table cell
+func cell(value string) string {
+	return strings.NewReplacer("|", "\\|", "\r\n", " ", "\n", " ", "\r", " ").Replace(value)
+}
+
+// AppendSummary appends Markdown to the job summary file (the
+// GITHUB_STEP_SUMMARY environment variable)
+func AppendSummary(path, markdown string) error {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600) // #nosec G304 - path is provided by the Actions runner
+	if err != nil {
+		return fmt.Errorf("failed to open GITHUB_STEP_SUMMARY: %w", err)
+	}
+	if _, err := file.WriteString(markdown); err != nil {
+		if closeErr := file.Close(); closeErr != nil {
+			_ = closeErr
+		}
+		return fmt.Errorf("failed to write GITHUB_STEP_SUMMARY: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write GITHUB_STEP_SUMMARY: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package ghactions
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hutstep/entra-id-api-tester/internal/report"
+)
+
+func TestSummary(t *testing.T) {
+	startedAt := time.Date(2026, 1, 14, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		report   *report.Report
+		expected []string
+		absent   []string
+	}{
+		{
+			name: "failed",
+			report: &report.Report{
+				StartedAt:  startedAt,
+				FinishedAt: startedAt.Add(1500 * time.Millisecond),
+				Endpoints: []report.Endpoint{
+					{Name: "Users", Status: report.StatusPassed, StatusCode: 200, DurationMs: 120},
+					{Name: "Orders | v2", Status: report.StatusFailed, FailedPhase: "response", StatusCode: 500, DurationMs: 80, Error: "Unexpected status code: 500\nbody"},
+					{Name: "Items", Status: report.StatusSkipped, Error: "Skipped (dependency failed: Orders | v2)"},
+				},
+				Summary: report.Summary{Total: 3, Passed: 1, Failed: 1, Skipped: 1},
+			},
+			expected: []string{
+				"## ❌ API tests failed",
+				"**3** endpoint(s): **1** passed, **1** failed, **1** skipped in 1.5s",
+				"| Users | ✅ passed | 200 | 120 ms |",
+				"| Orders \\| v2 | ❌ failed (response) | 500 | 80 ms |",
+				"| Items | ⏭️ skipped | - | 0 ms |",
+				"### Failures",
+				"- **Orders \\| v2** (response): Unexpected status code: 500 body",
+			},
+		},
+		{
+			name: "passed",
+			report: &report.Report{
+				StartedAt:  startedAt,
+				FinishedAt: startedAt.Add(time.Second),
+				Endpoints:  []report.Endpoint{{Name: "Users", Status: report.StatusPassed, StatusCode: 200, DurationMs: 120}},
+				Summary:    report.Summary{Total: 1, Passed: 1},
+			},
+			expected: []string{"## ✅ API tests passed", "| Users | ✅ passed | 200 | 120 ms |"},
+			absent:   []string{"### Failures"},
+		},
+		{
+			name: "aborted",
+			report: &report.Report{
+				StartedAt:  startedAt,
+				FinishedAt: startedAt,
+				Aborted:    "interrupted by SIGINT",
+			},
+			expected: []string{"## ⚠️ API tests aborted", "interrupted by SIGINT", "**0** endpoint(s)"},
+			absent:   []string{"| Endpoint |"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Summary(tt.report)
+			for _, expected := range tt.expected {
+				if !strings.Contains(got, expected) {
+					t.Errorf("Expected summary to contain %q, got:\n%s", expected, got)
+				}
+			}
+			for _, absent := range tt.absent {
+				if strings.Contains(got, absent) {
+					t.Errorf("Expected summary not to contain %q, got:\n%s", absent, got)
+				}
+			}
+		})
+	}
+}
+
+func TestAppendSummary(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "summary.md")
+	if err := AppendSummary(path, "## First\n"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := AppendSummary(path, "## Second\n"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if string(content) != "## First\n## Second\n" {
+		t.Errorf("Expected both summaries, got %q", content)
+	}
+}
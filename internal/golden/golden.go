@@ -0,0 +1,177 @@
+// Package golden compares response bodies with recorded golden files to
+// catch unintended changes. Bodies are normalized first: JSON is indented
+// with sorted keys and the values of volatile fields (IDs, timestamps) are
+// masked, so that only meaningful differences are reported.
+package golden
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Masked replaces the value of every ignored field
+const Masked = "<ignored>"
+
+// maxDiffLines bounds the diff reported for a mismatch, and maxErrorLines
+// the part of it its error message shows
+const (
+	maxDiffLines  = 40
+	maxErrorLines = 6
+)
+
+// MismatchError reports how a body differs from its golden file
+type MismatchError struct {
+	Path string
+	// Diff lists the differing lines, prefixed with "-" for the golden file
+	// and "+" for the response
+	Diff []string
+}
+
+// Error summarizes the mismatch with its first differences, joined with
+// "; "
+func (e *MismatchError) Error() string {
+	shown := e.Diff
+	if len(shown) > maxErrorLines {
+		shown = append(slices.Clip(shown[:maxErrorLines]), "...")
+	}
+	return fmt.Sprintf("response differs from %s: %s", e.Path, strings.Join(shown, "; "))
+}
+
+// Normalize renders a body the way it is stored in a golden file: JSON
+// indented with sorted keys, with the values of fields named in ignore
+// replaced by Masked at any depth, and any other body with LF line endings
+func Normalize(body []byte, ignore []string) []byte {
+	var document interface{}
+	if err := json.Unmarshal(body, &document); err == nil {
+		var normalized bytes.Buffer
+		encoder := json.NewEncoder(&normalized)
+		encoder.SetEscapeHTML(false)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(mask(document, ignore)); err == nil {
+			return normalized.Bytes()
+		}
+	}
+	normalized := bytes.ReplaceAll(body, []byte("\r\n"), []byte("\n"))
+	if len(normalized) > 0 && normalized[len(normalized)-1] != '\n' {
+		normalized = append(normalized, '\n')
+	}
+	return normalized
+}
+
+// mask replaces the values of ignored fields in a decoded JSON value
+func mask(value interface{}, ignore []string) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			if slices.Contains(ignore, key) {
+				v[key] = Masked
+			} else {
+				v[key] = mask(item, ignore)
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = mask(item, ignore)
+		}
+	}
+	return value
+}
+
+// Check compares a body with the golden file at path. A mismatch is a
+// *MismatchError.
+func Check(path string, body []byte, ignore []string) error {
+	recorded, err := os.ReadFile(path) // #nosec G304 - golden files are chosen by the configuration owner
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("golden file %s does not exist (record it with -update-golden)", path)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read golden file: %w", err)
+	}
+
+	// The recorded file is normalized again, so that hand edits to its
+	// formatting do not count as differences
+	want := Normalize(recorded, ignore)
+	got := Normalize(body, ignore)
+	if bytes.Equal(want, got) {
+		return nil
+	}
+	return &MismatchError{Path: path, Diff: diff(lines(want), lines(got))}
+}
+
+// Update records a body as the golden file at path, creating its
+// directory, and reports whether the file changed
+func Update(path string, body []byte, ignore []string) (bool, error) {
+	normalized := Normalize(body, ignore)
+	if recorded, err := os.ReadFile(path); err == nil && bytes.Equal(recorded, normalized) { // #nosec G304 - see Check
+		return false, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return false, fmt.Errorf("failed to create golden file directory: %w", err)
+	}
+	if err := os.WriteFile(path, normalized, 0o600); err != nil {
+		return false, fmt.Errorf("failed to write golden file: %w", err)
+	}
+	return true, nil
+}
+
+// lines splits normalized content into lines without the final newline
+func lines(content []byte) []string {
+	return strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+}
+
+// diff returns the lines only in want, prefixed with "-", and only in got,
+// prefixed with "+", in order, based on their longest common subsequence.
+// Bodies too large to align report their first differing line instead.
+func diff(want, got []string) []string {
+	if len(want)*len(got) > 1_000_000 {
+		for i := 0; i < len(want) && i < len(got); i++ {
+			if want[i] != got[i] {
+				return []string{fmt.Sprintf("line %d:", i+1), "-" + want[i], "+" + got[i]}
+			}
+		}
+		return []string{fmt.Sprintf("%d lines, expected %d", len(got), len(want))}
+	}
+
+	// common[i][j] is the length of the longest common subsequence of
+	// want[i:] and got[j:]
+	common := make([][]int, len(want)+1)
+	for i := range common {
+		common[i] = make([]int, len(got)+1)
+	}
+	for i := len(want) - 1; i >= 0; i-- {
+		for j := len(got) - 1; j >= 0; j-- {
+			if want[i] == got[j] {
+				common[i][j] = common[i+1][j+1] + 1
+			} else {
+				common[i][j] = max(common[i+1][j], common[i][j+1])
+			}
+		}
+	}
+
+	var differences []string
+	i, j := 0, 0
+	for (i < len(want) || j < len(got)) && len(differences) < maxDiffLines {
+		switch {
+		case i < len(want) && j < len(got) && want[i] == got[j]:
+			i++
+			j++
+		case i < len(want) && (j == len(got) || common[i+1][j] >= common[i][j+1]):
+			differences = append(differences, "-"+want[i])
+			i++
+		default:
+			differences = append(differences, "+"+got[j])
+			j++
+		}
+	}
+	if i < len(want) || j < len(got) {
+		differences = append(differences, "...")
+	}
+	return differences
+}
@@ -0,0 +1,117 @@
+package golden
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		ignore   []string
+		expected string
+	}{
+		{
+			name:     "json with sorted keys",
+			body:     `{"b": 1, "a": [true, null]}`,
+			expected: "{\n  \"a\": [\n    true,\n    null\n  ],\n  \"b\": 1\n}\n",
+		},
+		{
+			name:     "masked fields at any depth",
+			body:     `{"id": 7, "items": [{"id": "x", "name": "a", "createdAt": {"date": "today"}}]}`,
+			ignore:   []string{"id", "createdAt"},
+			expected: "{\n  \"id\": \"<ignored>\",\n  \"items\": [\n    {\n      \"createdAt\": \"<ignored>\",\n      \"id\": \"<ignored>\",\n      \"name\": \"a\"\n    }\n  ]\n}\n",
+		},
+		{
+			name:     "text",
+			body:     "line 1\r\nline 2",
+			expected: "line 1\nline 2\n",
+		},
+		{
+			name:     "empty",
+			body:     "",
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(Normalize([]byte(tt.body), tt.ignore)); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestUpdateAndCheck(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "testdata", "items.json")
+	ignore := []string{"etag"}
+
+	if err := Check(path, []byte(`{}`), ignore); err == nil || !strings.Contains(err.Error(), "-update-golden") {
+		t.Errorf("Expected a missing golden file to suggest -update-golden, got %v", err)
+	}
+
+	changed, err := Update(path, []byte(`{"name": "a", "etag": "1"}`), ignore)
+	if err != nil || !changed {
+		t.Fatalf("Expected the golden file to be written, got %v, %v", changed, err)
+	}
+	if changed, err := Update(path, []byte(`{"etag": "2", "name": "a"}`), ignore); err != nil || changed {
+		t.Errorf("Expected an equivalent body to leave the golden file unchanged, got %v, %v", changed, err)
+	}
+
+	if err := Check(path, []byte(`{"etag":"3","name":"a"}`), ignore); err != nil {
+		t.Errorf("Expected a body differing only in ignored fields to match, got %v", err)
+	}
+
+	err = Check(path, []byte(`{"name": "b", "etag": "1"}`), ignore)
+	var mismatch *MismatchError
+	if !errors.As(err, &mismatch) {
+		t.Fatalf("Expected a MismatchError, got %v", err)
+	}
+	if expected := []string{`-  "name": "a"`, `+  "name": "b"`}; !reflect.DeepEqual(mismatch.Diff, expected) {
+		t.Errorf("Expected diff %q, got %q", expected, mismatch.Diff)
+	}
+}
+
+func TestCheck_ReformattedGoldenFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "items.json")
+	if err := os.WriteFile(path, []byte(`{"name":"a","tags":["x"]}`), 0o600); err != nil {
+		t.Fatalf("Failed to write golden file: %v", err)
+	}
+	if err := Check(path, []byte(`{"tags": ["x"], "name": "a"}`), nil); err != nil {
+		t.Errorf("Expected formatting differences to be ignored, got %v", err)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		want     []string
+		got      []string
+		expected []string
+	}{
+		{"inserted line", []string{"a", "c"}, []string{"a", "b", "c"}, []string{"+b"}},
+		{"removed line", []string{"a", "b", "c"}, []string{"a", "c"}, []string{"-b"}},
+		{"changed line", []string{"a", "b"}, []string{"a", "x"}, []string{"-b", "+x"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := diff(tt.want, tt.got); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestMismatchError_Truncates(t *testing.T) {
+	err := &MismatchError{Path: "golden.json", Diff: []string{"-1", "-2", "-3", "-4", "-5", "-6", "-7", "+8"}}
+	if got := err.Error(); got != "response differs from golden.json: -1; -2; -3; -4; -5; -6; ..." {
+		t.Errorf("Unexpected message: %s", got)
+	}
+}
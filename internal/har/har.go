@@ -0,0 +1,180 @@
+// Package har records API exchanges in the HTTP Archive (HAR 1.2) format so
+// failed runs can be replayed and inspected in browser devtools. Credentials
+// in headers, URLs and bodies are redacted before they are written.
+package har
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/hutstep/entra-id-api-tester/internal/client"
+	"github.com/hutstep/entra-id-api-tester/internal/redact"
+)
+
+// Recorder streams exchanges from the API client to a HAR document as they
+// happen, so large runs never hold every response body in memory
+type Recorder struct {
+	w       io.Writer
+	closer  io.Closer
+	err     error
+	entries int
+	mu      sync.Mutex
+}
+
+// New starts a HAR document on w
+func New(w io.Writer, creatorVersion string) *Recorder {
+	r := &Recorder{w: w}
+
+	creator, err := json.Marshal(Creator{Name: "api-tester", Version: creatorVersion})
+	if err != nil {
+		r.err = fmt.Errorf("failed to encode HAR creator: %w", err)
+		return r
+	}
+	r.write(`{"log":{"version":"1.2","creator":` + string(creator) + `,"entries":[`)
+	return r
+}
+
+// Create starts a HAR document in a new file at path
+func Create(path, creatorVersion string) (*Recorder, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600) // #nosec G304 - output path is provided by user via CLI flag
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HAR file: %w", err)
+	}
+	r := New(file, creatorVersion)
+	r.closer = file
+	return r, nil
+}
+
+// Record implements client.Recorder
+func (r *Recorder) Record(exchange *client.Exchange) {
+	data, err := json.Marshal(newEntry(exchange))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err != nil {
+		r.err = fmt.Errorf("failed to encode HAR entry: %w", err)
+		return
+	}
+	if r.entries > 0 {
+		r.write(",")
+	}
+	r.write(string(data))
+	r.entries++
+}
+
+// Close terminates the HAR document and closes the underlying file, returning
+// the first error encountered while recording
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.write("]}}\n")
+	if r.closer != nil {
+		if err := r.closer.Close(); err != nil && r.err == nil {
+			r.err = fmt.Errorf("failed to close HAR file: %w", err)
+		}
+	}
+	return r.err
+}
+
+// write appends raw output unless an earlier write failed
+func (r *Recorder) write(s string) {
+	if r.err != nil {
+		return
+	}
+	if _, err := io.WriteString(r.w, s); err != nil {
+		r.err = fmt.Errorf("failed to write HAR file: %w", err)
+	}
+}
+
+// newEntry converts a client exchange into a HAR entry
+func newEntry(exchange *client.Exchange) Entry {
+	req := exchange.Request
+	resp := exchange.Response
+	elapsed := float64(exchange.Duration) / float64(time.Millisecond)
+
+	entry := Entry{
+		StartedDateTime: exchange.Started.Format(time.RFC3339Nano),
+		Time:            elapsed,
+		Request: Request{
+			Method:      req.Method,
+			URL:         redact.String(req.URL.String()),
+			HTTPVersion: req.Proto,
+			Cookies:     []Cookie{},
+			Headers:     headers(req.Header),
+			QueryString: queryString(req),
+			HeadersSize: -1,
+			BodySize:    len(exchange.RequestBody),
+		},
+		Response: Response{
+			Status:      resp.StatusCode,
+			StatusText:  http.StatusText(resp.StatusCode),
+			HTTPVersion: resp.Proto,
+			Cookies:     []Cookie{},
+			Headers:     headers(resp.Headers),
+			Content:     content(resp),
+			RedirectURL: resp.Headers.Get("Location"),
+			HeadersSize: -1,
+			BodySize:    len(resp.Body),
+		},
+		Cache:   struct{}{},
+		Timings: Timings{Send: 0, Wait: elapsed, Receive: 0},
+	}
+
+	if exchange.RequestBody != nil {
+		entry.Request.PostData = &PostData{
+			MimeType: req.Header.Get("Content-Type"),
+			Text:     redact.String(string(exchange.RequestBody)),
+		}
+	}
+
+	return entry
+}
+
+// headers converts and redacts HTTP headers, sorted for stable output
+func headers(h http.Header) []NameValue {
+	pairs := make([]NameValue, 0, len(h))
+	for name, values := range h {
+		for _, value := range values {
+			pairs = append(pairs, NameValue{Name: name, Value: redact.Header(name, value)})
+		}
+	}
+	sort.Slice(pairs, func(i, j int) bool { return pairs[i].Name < pairs[j].Name })
+	return pairs
+}
+
+// queryString lists the request's query parameters
+func queryString(req *http.Request) []NameValue {
+	query := req.URL.Query()
+	pairs := make([]NameValue, 0, len(query))
+	for name, values := range query {
+		for _, value := range values {
+			pairs = append(pairs, NameValue{Name: name, Value: redact.String(value)})
+		}
+	}
+	sort.Slice(pairs, func(i, j int) bool { return pairs[i].Name < pairs[j].Name })
+	return pairs
+}
+
+// content describes the response body, base64-encoding binary payloads
+func content(resp *client.Response) Content {
+	c := Content{
+		Size:     len(resp.Body),
+		MimeType: resp.Headers.Get("Content-Type"),
+	}
+	if utf8.Valid(resp.Body) {
+		c.Text = redact.String(string(resp.Body))
+	} else {
+		c.Text = base64.StdEncoding.EncodeToString(resp.Body)
+		c.Encoding = "base64"
+	}
+	return c
+}
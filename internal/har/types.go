@@ -0,0 +1,93 @@
+package har
+
+// The types below follow the HAR 1.2 specification
+// (http://www.softwareishard.com/blog/har-12-spec/), limited to the fields
+// the tester populates.
+
+// Archive is the root of a HAR document
+type Archive struct {
+	Log Log `json:"log"`
+}
+
+// Log contains the recorded entries
+type Log struct {
+	Creator Creator `json:"creator"`
+	Version string  `json:"version"`
+	Entries []Entry `json:"entries"`
+}
+
+// Creator identifies the application that produced the archive
+type Creator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// Entry is a single request/response exchange
+type Entry struct {
+	Cache           struct{} `json:"cache"`
+	StartedDateTime string   `json:"startedDateTime"`
+	Request         Request  `json:"request"`
+	Response        Response `json:"response"`
+	Timings         Timings  `json:"timings"`
+	Time            float64  `json:"time"`
+}
+
+// Request describes the outgoing request
+type Request struct {
+	PostData    *PostData   `json:"postData,omitempty"`
+	Method      string      `json:"method"`
+	URL         string      `json:"url"`
+	HTTPVersion string      `json:"httpVersion"`
+	Cookies     []Cookie    `json:"cookies"`
+	Headers     []NameValue `json:"headers"`
+	QueryString []NameValue `json:"queryString"`
+	HeadersSize int         `json:"headersSize"`
+	BodySize    int         `json:"bodySize"`
+}
+
+// Response describes the received response
+type Response struct {
+	StatusText  string      `json:"statusText"`
+	HTTPVersion string      `json:"httpVersion"`
+	RedirectURL string      `json:"redirectURL"`
+	Content     Content     `json:"content"`
+	Cookies     []Cookie    `json:"cookies"`
+	Headers     []NameValue `json:"headers"`
+	Status      int         `json:"status"`
+	HeadersSize int         `json:"headersSize"`
+	BodySize    int         `json:"bodySize"`
+}
+
+// Cookie is a HAR cookie. Cookies are not recorded individually; they
+// remain visible (redacted) in the Cookie and Set-Cookie headers.
+type Cookie struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// NameValue is a header or query string pair
+type NameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// PostData is the request body
+type PostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+// Content is the response body
+type Content struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+	Encoding string `json:"encoding,omitempty"`
+	Size     int    `json:"size"`
+}
+
+// Timings breaks down the exchange duration in milliseconds
+type Timings struct {
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
@@ -0,0 +1,90 @@
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/hutstep/entra-id-api-tester/internal/report"
+)
+
+// Diff is the difference between a run and a known-good baseline run
+type Diff struct {
+	// NewFailures are endpoints that fail now but did not fail in the
+	// baseline
+	NewFailures []string
+	// Fixed are endpoints that pass now but failed in the baseline
+	Fixed []string
+	// Slower are passing endpoints more than the threshold slower than in
+	// the baseline
+	Slower []Regression
+}
+
+// Empty reports whether the run matches its baseline
+func (d *Diff) Empty() bool {
+	return len(d.NewFailures) == 0 && len(d.Fixed) == 0 && len(d.Slower) == 0
+}
+
+// LoadBaseline reads a baseline written with WriteBaseline, or any JSON run
+// report
+func LoadBaseline(path string) (Run, error) {
+	data, err := os.ReadFile(path) // #nosec G304 - baseline path is provided by the user
+	if err != nil {
+		return Run{}, fmt.Errorf("failed to read baseline: %w", err)
+	}
+	var baseline report.Report
+	if err := json.Unmarshal(data, &baseline); err != nil {
+		return Run{}, fmt.Errorf("failed to parse baseline: %w", err)
+	}
+	return FromReport(&baseline), nil
+}
+
+// WriteBaseline records a run report as the baseline at path. Only runs
+// without failures are accepted, since later runs are judged against it.
+func WriteBaseline(path string, runReport *report.Report) error {
+	if runReport.Summary.Failed > 0 || runReport.Aborted != "" {
+		return fmt.Errorf("refusing to record a baseline from a run with failures")
+	}
+	if _, err := runReport.WriteFile(path, report.FormatJSON); err != nil {
+		return fmt.Errorf("failed to write baseline: %w", err)
+	}
+	return nil
+}
+
+// CompareBaseline diffs current against baseline. Endpoints missing from
+// the baseline count as new failures when they fail; passing endpoints are
+// slower when their duration exceeds the baseline's by more than
+// slowerPercent.
+func CompareBaseline(baseline, current Run, slowerPercent float64) Diff {
+	before := make(map[string]Entry, len(baseline.Endpoints))
+	for _, entry := range baseline.Endpoints {
+		before[entry.Name] = entry
+	}
+
+	var diff Diff
+	for _, entry := range current.Endpoints {
+		previous, known := before[entry.Name]
+		switch entry.Status {
+		case report.StatusFailed:
+			if !known || previous.Status != report.StatusFailed {
+				diff.NewFailures = append(diff.NewFailures, entry.Name)
+			}
+		case report.StatusPassed:
+			switch {
+			case known && previous.Status == report.StatusFailed:
+				diff.Fixed = append(diff.Fixed, entry.Name)
+			case known && previous.Status == report.StatusPassed && previous.DurationMs > 0:
+				limit := float64(previous.DurationMs) * (1 + slowerPercent/100)
+				if float64(entry.DurationMs) > limit {
+					diff.Slower = append(diff.Slower, Regression{
+						Endpoint:   entry.Name,
+						Reason:     fmt.Sprintf("%dms, %.0f%% slower than the baseline's %dms", entry.DurationMs, (float64(entry.DurationMs)/float64(previous.DurationMs)-1)*100, previous.DurationMs),
+						DurationMs: entry.DurationMs,
+						AverageMs:  previous.DurationMs,
+					})
+				}
+			}
+		}
+	}
+	return diff
+}
@@ -0,0 +1,89 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/hutstep/entra-id-api-tester/internal/report"
+)
+
+func TestWriteAndLoadBaseline(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "baseline.json")
+	runReport := &report.Report{
+		Version: "1.0.0",
+		Endpoints: []report.Endpoint{
+			{Name: "users", Status: report.StatusPassed, DurationMs: 120},
+			{Name: "orders", Status: report.StatusSkipped},
+		},
+		Summary: report.Summary{Total: 2, Passed: 1, Skipped: 1},
+	}
+
+	if err := WriteBaseline(path, runReport); err != nil {
+		t.Fatalf("WriteBaseline() error = %v", err)
+	}
+	baseline, err := LoadBaseline(path)
+	if err != nil {
+		t.Fatalf("LoadBaseline() error = %v", err)
+	}
+	if baseline.Version != "1.0.0" || len(baseline.Endpoints) != 2 || baseline.Endpoints[0] != passed("users", 120) {
+		t.Errorf("Unexpected baseline: %+v", baseline)
+	}
+}
+
+func TestWriteBaseline_RejectsFailures(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "baseline.json")
+
+	for _, runReport := range []*report.Report{
+		{Summary: report.Summary{Total: 1, Failed: 1}},
+		{Summary: report.Summary{Total: 1, Passed: 1}, Aborted: "interrupted"},
+	} {
+		if err := WriteBaseline(path, runReport); err == nil {
+			t.Errorf("Expected error for %+v", runReport)
+		}
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Expected no baseline to be written, stat error = %v", err)
+	}
+}
+
+func TestLoadBaseline_Invalid(t *testing.T) {
+	dir := t.TempDir()
+	invalid := filepath.Join(dir, "invalid.json")
+	if err := os.WriteFile(invalid, []byte("not json"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadBaseline(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("Expected error for a missing baseline")
+	}
+	if _, err := LoadBaseline(invalid); err == nil {
+		t.Error("Expected error for an invalid baseline")
+	}
+}
+
+func TestCompareBaseline(t *testing.T) {
+	baseline := run(passed("users", 100), failed("orders"), passed("items", 100), failed("reports"), passed("search", 0))
+	current := run(failed("users"), passed("orders", 500), passed("items", 151), failed("reports"), failed("audit"), passed("search", 900), passed("health", 10))
+
+	diff := CompareBaseline(baseline, current, 50)
+
+	if !slices.Equal(diff.NewFailures, []string{"users", "audit"}) {
+		t.Errorf("Expected new failures [users audit], got %v", diff.NewFailures)
+	}
+	if !slices.Equal(diff.Fixed, []string{"orders"}) {
+		t.Errorf("Expected fixed [orders], got %v", diff.Fixed)
+	}
+	if len(diff.Slower) != 1 || diff.Slower[0].Endpoint != "items" || !strings.Contains(diff.Slower[0].Reason, "51% slower") {
+		t.Errorf("Expected items to be slower, got %+v", diff.Slower)
+	}
+	if diff.Empty() {
+		t.Error("Expected a non-empty diff")
+	}
+
+	if diff := CompareBaseline(baseline, baseline, 50); !diff.Empty() {
+		t.Errorf("Expected an empty diff against itself, got %+v", diff)
+	}
+}
@@ -0,0 +1,338 @@
+// Package history keeps the outcome of past runs so that a run can be
+// compared against its predecessors, or against a known-good baseline, and
+// regressions flagged: endpoints that newly fail, or that are much slower
+// than they used to be.
+package history
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	// Registers the pure-Go "sqlite" driver, which needs no cgo
+	_ "modernc.org/sqlite"
+
+	"github.com/hutstep/entra-id-api-tester/internal/report"
+)
+
+// DefaultWindow is the number of previous runs an endpoint's rolling
+// average duration is computed over
+const DefaultWindow = 10
+
+// CompareRuns is the number of latest runs loaded to compare a run
+// against: its rolling averages, and the last status of endpoints that
+// were skipped in the runs before it
+const CompareRuns = 100
+
+// Run is the recorded outcome of one run
+type Run struct {
+	StartedAt time.Time `json:"startedAt"`
+	Endpoints []Entry   `json:"endpoints"`
+	Version   string    `json:"version"`
+}
+
+// Entry is the recorded outcome of one endpoint in a run
+type Entry struct {
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	DurationMs int64  `json:"durationMs"`
+}
+
+// FromReport extracts what history records from a run report
+func FromReport(runReport *report.Report) Run {
+	run := Run{
+		StartedAt: runReport.StartedAt,
+		Endpoints: make([]Entry, len(runReport.Endpoints)),
+		Version:   runReport.Version,
+	}
+	for i, endpoint := range runReport.Endpoints {
+		run.Endpoints[i] = Entry{Name: endpoint.Name, Status: endpoint.Status, DurationMs: endpoint.DurationMs}
+	}
+	return run
+}
+
+// schema creates the tables of a history database: one row per run, and
+// one per endpoint of a run in the order they ran
+const schema = `
+CREATE TABLE IF NOT EXISTS runs (
+	id         INTEGER PRIMARY KEY AUTOINCREMENT,
+	started_at TEXT NOT NULL,
+	version    TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS results (
+	run_id      INTEGER NOT NULL REFERENCES runs (id),
+	endpoint    TEXT NOT NULL,
+	status      TEXT NOT NULL,
+	duration_ms INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS results_endpoint ON results (endpoint, run_id);
+`
+
+// sqliteHeader starts every SQLite database file
+const sqliteHeader = "SQLite format 3\x00"
+
+// open opens the history database at path, creating its tables if needed.
+// Concurrent writers, such as a daemon and a run sharing a history, wait
+// for each other instead of failing.
+func open(path string) (*sql.DB, error) {
+	if err := checkDatabase(path); err != nil {
+		return nil, err
+	}
+	dsn, err := dataSourceName(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history: %w", err)
+	}
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history: %w", err)
+	}
+	if _, err := db.Exec(schema); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to open history: %w", err)
+	}
+	return db, nil
+}
+
+// dataSourceName returns the SQLite URI of the database at path. The path
+// is made absolute and escaped, as a "?" or "#" in it would otherwise start
+// the URI's query or fragment.
+func dataSourceName(path string) (string, error) {
+	absolute, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	absolute = filepath.ToSlash(absolute)
+	if !strings.HasPrefix(absolute, "/") {
+		// A Windows drive letter, as in file:/C:/history.db
+		absolute = "/" + absolute
+	}
+	return "file:" + (&url.URL{Path: absolute}).EscapedPath() + "?_pragma=busy_timeout(5000)", nil
+}
+
+// checkDatabase rejects an existing file at path that is not a SQLite
+// database, e.g. a JSON Lines history of an earlier version, rather than
+// letting SQLite report it as corrupt
+func checkDatabase(path string) error {
+	file, err := os.Open(path) // #nosec G304 - history path is provided by the user
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open history: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	header := make([]byte, len(sqliteHeader))
+	n, err := io.ReadFull(file, header)
+	if n == 0 && (err == nil || errors.Is(err, io.EOF)) {
+		return nil
+	}
+	if string(header[:n]) != sqliteHeader {
+		return fmt.Errorf("history %s is not a SQLite database", path)
+	}
+	return nil
+}
+
+// Load reads the latest limit runs recorded in the history database at
+// path, or all of them for a limit of 0, oldest first. A missing file is an
+// empty history.
+func Load(path string, limit int) ([]Run, error) {
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	db, err := open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = db.Close() }()
+
+	var runs []Run
+	var firstID int64
+	index := map[int64]int{}
+	if limit <= 0 {
+		limit = -1 // no limit
+	}
+	rows, err := db.Query("SELECT id, started_at, version FROM (SELECT id, started_at, version FROM runs ORDER BY id DESC LIMIT ?) ORDER BY id", limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+	for rows.Next() {
+		var id int64
+		var startedAt string
+		var run Run
+		if err := rows.Scan(&id, &startedAt, &run.Version); err != nil {
+			_ = rows.Close()
+			return nil, fmt.Errorf("failed to read history: %w", err)
+		}
+		if run.StartedAt, err = time.Parse(time.RFC3339Nano, startedAt); err != nil {
+			_ = rows.Close()
+			return nil, fmt.Errorf("failed to parse history run %d: %w", id, err)
+		}
+		if len(runs) == 0 {
+			firstID = id
+		}
+		index[id] = len(runs)
+		runs = append(runs, run)
+	}
+	if err := closeRows(rows); err != nil {
+		return nil, err
+	}
+
+	if len(runs) == 0 {
+		return nil, nil
+	}
+	rows, err = db.Query("SELECT run_id, endpoint, status, duration_ms FROM results WHERE run_id >= ? ORDER BY run_id, rowid", firstID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+	for rows.Next() {
+		var runID int64
+		var entry Entry
+		if err := rows.Scan(&runID, &entry.Name, &entry.Status, &entry.DurationMs); err != nil {
+			_ = rows.Close()
+			return nil, fmt.Errorf("failed to read history: %w", err)
+		}
+		if i, ok := index[runID]; ok {
+			runs[i].Endpoints = append(runs[i].Endpoints, entry)
+		}
+	}
+	if err := closeRows(rows); err != nil {
+		return nil, err
+	}
+	return runs, nil
+}
+
+// closeRows closes rows, reporting any error that ended their iteration
+func closeRows(rows *sql.Rows) error {
+	if err := rows.Err(); err != nil {
+		_ = rows.Close()
+		return fmt.Errorf("failed to read history: %w", err)
+	}
+	if err := rows.Close(); err != nil {
+		return fmt.Errorf("failed to read history: %w", err)
+	}
+	return nil
+}
+
+// Append records run in the history database at path, creating it if
+// needed
+func Append(path string, run Run) error {
+	db, err := open(path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = db.Close() }()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	inserted, err := tx.Exec("INSERT INTO runs (started_at, version) VALUES (?, ?)", run.StartedAt.UTC().Format(time.RFC3339Nano), run.Version)
+	if err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	runID, err := inserted.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	for _, entry := range run.Endpoints {
+		if _, err := tx.Exec("INSERT INTO results (run_id, endpoint, status, duration_ms) VALUES (?, ?, ?, ?)", runID, entry.Name, entry.Status, entry.DurationMs); err != nil {
+			return fmt.Errorf("failed to write history: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	return nil
+}
+
+// Regression is an endpoint that got worse compared to previous runs
+type Regression struct {
+	Endpoint string
+	// Reason describes the regression, e.g. "newly failing (passed in the
+	// previous run)"
+	Reason string
+	// DurationMs and AverageMs are set for slowdowns
+	DurationMs int64
+	AverageMs  int64
+}
+
+// Compare flags the endpoints of current that regressed against previous
+// runs (oldest first): endpoints that fail now but passed the last time
+// they ran, and passing endpoints more than slowerPercent slower than their
+// average duration over the last window passing runs
+func Compare(previous []Run, current Run, window int, slowerPercent float64) []Regression {
+	if window <= 0 {
+		window = DefaultWindow
+	}
+
+	var regressions []Regression
+	for _, entry := range current.Endpoints {
+		switch entry.Status {
+		case report.StatusFailed:
+			if last, ok := lastStatus(previous, entry.Name); ok && last == report.StatusPassed {
+				regressions = append(regressions, Regression{
+					Endpoint: entry.Name,
+					Reason:   "newly failing (passed in the previous run)",
+				})
+			}
+		case report.StatusPassed:
+			average, ok := averageDuration(previous, entry.Name, window)
+			if !ok || average <= 0 {
+				continue
+			}
+			limit := float64(average) * (1 + slowerPercent/100)
+			if float64(entry.DurationMs) > limit {
+				regressions = append(regressions, Regression{
+					Endpoint:   entry.Name,
+					Reason:     fmt.Sprintf("%dms, %.0f%% slower than its rolling average of %dms", entry.DurationMs, (float64(entry.DurationMs)/float64(average)-1)*100, average),
+					DurationMs: entry.DurationMs,
+					AverageMs:  average,
+				})
+			}
+		}
+	}
+	return regressions
+}
+
+// lastStatus returns the status of the endpoint in the most recent run in
+// which it was not skipped
+func lastStatus(runs []Run, name string) (string, bool) {
+	for i := len(runs) - 1; i >= 0; i-- {
+		for _, entry := range runs[i].Endpoints {
+			if entry.Name == name && entry.Status != report.StatusSkipped {
+				return entry.Status, true
+			}
+		}
+	}
+	return "", false
+}
+
+// averageDuration returns the endpoint's mean duration over its last window
+// passing runs
+func averageDuration(runs []Run, name string, window int) (int64, bool) {
+	var total int64
+	samples := 0
+	for i := len(runs) - 1; i >= 0 && samples < window; i-- {
+		for _, entry := range runs[i].Endpoints {
+			if entry.Name == name && entry.Status == report.StatusPassed {
+				total += entry.DurationMs
+				samples++
+				break
+			}
+		}
+	}
+	if samples == 0 {
+		return 0, false
+	}
+	return total / int64(samples), true
+}
@@ -0,0 +1,221 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hutstep/entra-id-api-tester/internal/report"
+)
+
+func run(entries ...Entry) Run {
+	return Run{Endpoints: entries}
+}
+
+func passed(name string, durationMs int64) Entry {
+	return Entry{Name: name, Status: report.StatusPassed, DurationMs: durationMs}
+}
+
+func failed(name string) Entry {
+	return Entry{Name: name, Status: report.StatusFailed}
+}
+
+func skipped(name string) Entry {
+	return Entry{Name: name, Status: report.StatusSkipped}
+}
+
+func TestLoadAndAppend(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.db")
+
+	runs, err := Load(path, 0)
+	if err != nil {
+		t.Fatalf("Load() of a missing file error = %v", err)
+	}
+	if len(runs) != 0 {
+		t.Fatalf("Expected empty history, got %d run(s)", len(runs))
+	}
+
+	startedAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	if err := Append(path, Run{StartedAt: startedAt, Version: "1.0.0", Endpoints: []Entry{passed("users", 120), passed("orders", 80)}}); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+	if err := Append(path, run(failed("users"))); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+
+	runs, err = Load(path, 0)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(runs) != 2 {
+		t.Fatalf("Expected 2 runs, got %d", len(runs))
+	}
+	if !runs[0].StartedAt.Equal(startedAt) || runs[0].Version != "1.0.0" || len(runs[0].Endpoints) != 2 || runs[0].Endpoints[0] != passed("users", 120) || runs[0].Endpoints[1] != passed("orders", 80) {
+		t.Errorf("Unexpected first run: %+v", runs[0])
+	}
+	if len(runs[1].Endpoints) != 1 || runs[1].Endpoints[0].Status != report.StatusFailed {
+		t.Errorf("Unexpected second run: %+v", runs[1])
+	}
+}
+
+func TestLoadLimit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.db")
+	for _, duration := range []int64{100, 200, 300} {
+		if err := Append(path, run(passed("users", duration))); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+	}
+
+	runs, err := Load(path, 2)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(runs) != 2 || runs[0].Endpoints[0].DurationMs != 200 || runs[1].Endpoints[0].DurationMs != 300 {
+		t.Errorf("Expected the latest 2 runs, oldest first, got %+v", runs)
+	}
+}
+
+func TestLoadAndAppend_PathWithURICharacters(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "runs?#1 %20")
+	if err := os.Mkdir(dir, 0750); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "history.db")
+
+	if err := Append(path, run(passed("users", 100))); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("Expected the database at %s: %v", path, err)
+	}
+	runs, err := Load(path, 0)
+	if err != nil || len(runs) != 1 {
+		t.Errorf("Expected the appended run, got %d run(s), %v", len(runs), err)
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.db")
+	if err := os.WriteFile(path, []byte("{\"endpoints\":[]}\n\nnot json\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := Load(path, 0)
+	if err == nil || !strings.Contains(err.Error(), "is not a SQLite database") {
+		t.Errorf("Expected a JSON Lines history to be rejected, got %v", err)
+	}
+	if err := Append(path, run(passed("users", 100))); err == nil {
+		t.Error("Expected appending to a JSON Lines history to fail")
+	}
+}
+
+func TestFromReport(t *testing.T) {
+	runReport := &report.Report{
+		StartedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		Version:   "1.0.0",
+		Endpoints: []report.Endpoint{
+			{Name: "users", Status: report.StatusPassed, DurationMs: 120, StatusCode: 200},
+			{Name: "orders", Status: report.StatusFailed, DurationMs: 80, Error: "boom"},
+		},
+	}
+
+	recorded := FromReport(runReport)
+	if !recorded.StartedAt.Equal(runReport.StartedAt) || recorded.Version != "1.0.0" {
+		t.Errorf("Unexpected run metadata: %+v", recorded)
+	}
+	expected := []Entry{passed("users", 120), {Name: "orders", Status: report.StatusFailed, DurationMs: 80}}
+	if len(recorded.Endpoints) != len(expected) {
+		t.Fatalf("Expected %d entries, got %d", len(expected), len(recorded.Endpoints))
+	}
+	for i := range expected {
+		if recorded.Endpoints[i] != expected[i] {
+			t.Errorf("Entry %d: expected %+v, got %+v", i, expected[i], recorded.Endpoints[i])
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		name     string
+		previous []Run
+		current  Run
+		window   int
+		expected []string
+	}{
+		{
+			name:     "no history",
+			current:  run(failed("users"), passed("orders", 1000)),
+			expected: nil,
+		},
+		{
+			name:     "newly failing",
+			previous: []Run{run(passed("users", 100))},
+			current:  run(failed("users")),
+			expected: []string{"users: newly failing"},
+		},
+		{
+			name:     "still failing",
+			previous: []Run{run(passed("users", 100)), run(failed("users"))},
+			current:  run(failed("users")),
+			expected: nil,
+		},
+		{
+			name:     "skipped runs are ignored",
+			previous: []Run{run(passed("users", 100)), run(skipped("users"))},
+			current:  run(failed("users")),
+			expected: []string{"users: newly failing"},
+		},
+		{
+			name:     "slower than average",
+			previous: []Run{run(passed("users", 100)), run(passed("users", 300))},
+			current:  run(passed("users", 301)),
+			expected: []string{"users: 301ms, 50% slower than its rolling average of 200ms"},
+		},
+		{
+			name:     "within threshold",
+			previous: []Run{run(passed("users", 100)), run(passed("users", 300))},
+			current:  run(passed("users", 300)),
+			expected: nil,
+		},
+		{
+			name:     "failed runs do not count toward the average",
+			previous: []Run{run(passed("users", 100)), run(failed("users"))},
+			current:  run(passed("users", 140)),
+			expected: nil,
+		},
+		{
+			name:     "window limits the average",
+			previous: []Run{run(passed("users", 10)), run(passed("users", 100))},
+			current:  run(passed("users", 140)),
+			window:   1,
+			expected: nil,
+		},
+		{
+			name:     "new endpoint",
+			previous: []Run{run(passed("users", 100))},
+			current:  run(passed("orders", 1000), failed("items")),
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			regressions := Compare(tt.previous, tt.current, tt.window, 50)
+
+			var got []string
+			for _, regression := range regressions {
+				got = append(got, regression.Endpoint+": "+regression.Reason)
+			}
+			if len(got) != len(tt.expected) {
+				t.Fatalf("Expected %v, got %v", tt.expected, got)
+			}
+			for i := range got {
+				if !strings.HasPrefix(got[i], tt.expected[i]) {
+					t.Errorf("Expected %q, got %q", tt.expected[i], got[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+// Package hook runs the external commands an endpoint configures around its
+// request, for cases the declarative configuration cannot cover: a
+// pre-request hook may rewrite the request (e.g. add a computed signature),
+// a post-response hook may judge the response and extract variables. Hooks
+// exchange JSON on stdin and stdout.
+package hook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/hutstep/entra-id-api-tester/internal/redact"
+)
+
+// DefaultTimeout bounds a hook that does not configure its own timeout
+const DefaultTimeout = 10 * time.Second
+
+// EndpointEnv names the environment variable that tells a hook which
+// endpoint it runs for
+const EndpointEnv = "API_TESTER_ENDPOINT"
+
+// Request is the request a pre-request hook reads from stdin. The hook
+// prints the request to send; fields it leaves out keep their values, and
+// headers and a body it prints replace the request's as a whole, so that
+// it can remove headers and body keys.
+type Request struct {
+	Body    map[string]interface{} `json:"body"`
+	Headers map[string]string      `json:"headers"`
+	Method  string                 `json:"method"`
+	URL     string                 `json:"url"`
+}
+
+// Response is the response a post-response hook reads from stdin
+type Response struct {
+	Headers map[string]string `json:"headers"`
+	// JSON is the parsed body, or nil when the body is not JSON
+	JSON       interface{} `json:"json"`
+	Body       string      `json:"body"`
+	Status     int         `json:"status"`
+	DurationMs int64       `json:"durationMs"`
+}
+
+// Verdict is what a post-response hook prints. A hook that prints nothing
+// and exits with status 0 passes.
+type Verdict struct {
+	// Pass, when set to false, fails the endpoint
+	Pass *bool `json:"pass"`
+	// Variables are stored like captures, for later endpoints to use
+	Variables map[string]string `json:"variables"`
+	// Message explains the verdict
+	Message string `json:"message"`
+}
+
+// Passed reports whether the hook accepted the response
+func (v *Verdict) Passed() bool {
+	return v.Pass == nil || *v.Pass
+}
+
+// Run runs command for the named endpoint with input as JSON on stdin and
+// decodes its output, if any, into output. A hook that exits with a
+// non-zero status fails with its stderr.
+func Run(ctx context.Context, command []string, timeout time.Duration, endpoint string, input, output interface{}) error {
+	if len(command) == 0 {
+		return errors.New("no command")
+	}
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	stdin, err := json.Marshal(input)
+	if err != nil {
+		return fmt.Errorf("failed to encode hook input: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, command[0], command[1:]...) // #nosec G204 - hooks are commands the configuration owner chose to run
+	cmd.Env = append(os.Environ(), EndpointEnv+"="+endpoint)
+	cmd.Stdin = bytes.NewReader(stdin)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return fmt.Errorf("%s timed out after %v", command[0], timeout)
+		}
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return fmt.Errorf("%s failed: %w: %s", command[0], err, redact.String(message))
+		}
+		return fmt.Errorf("%s failed: %w", command[0], err)
+	}
+
+	if len(bytes.TrimSpace(stdout.Bytes())) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(stdout.Bytes(), output); err != nil {
+		return fmt.Errorf("%s printed invalid JSON: %w", command[0], err)
+	}
+	return nil
+}
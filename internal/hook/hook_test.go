@@ -0,0 +1,97 @@
+package hook
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// helperEnv selects the behavior of the test binary when it runs as a hook
+const helperEnv = "HOOK_TEST_HELPER"
+
+// TestMain lets the test binary act as a hook command, so the tests do not
+// depend on a shell
+func TestMain(m *testing.M) {
+	switch os.Getenv(helperEnv) {
+	case "":
+		os.Exit(m.Run())
+	case "sign":
+		input, _ := io.ReadAll(os.Stdin)
+		if !strings.Contains(string(input), `"method":"POST"`) {
+			os.Exit(3)
+		}
+		fmt.Printf(`{"headers":{"X-Signature":"sig-%s"}}`, os.Getenv(EndpointEnv))
+	case "silent":
+	case "fail":
+		fmt.Fprint(os.Stderr, "signing key not found")
+		os.Exit(1)
+	case "garbage":
+		fmt.Print("not json")
+	case "sleep":
+		time.Sleep(time.Minute)
+	}
+	os.Exit(0)
+}
+
+// helper returns the command that runs the test binary as the named hook
+func helper(t *testing.T, behavior string) []string {
+	t.Helper()
+	t.Setenv(helperEnv, behavior)
+	return []string{os.Args[0]}
+}
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		behavior  string
+		timeout   time.Duration
+		expectErr string
+		expectSig string
+	}{
+		{behavior: "sign", expectSig: "sig-Orders"},
+		{behavior: "silent"},
+		{behavior: "fail", expectErr: "signing key not found"},
+		{behavior: "garbage", expectErr: "printed invalid JSON"},
+		{behavior: "sleep", timeout: 100 * time.Millisecond, expectErr: "timed out after 100ms"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.behavior, func(t *testing.T) {
+			request := Request{Method: "POST", URL: "https://api.contoso.com/orders", Headers: map[string]string{"Range": "bytes=0-1"}}
+
+			err := Run(context.Background(), helper(t, tt.behavior), tt.timeout, "Orders", request, &request)
+
+			if tt.expectErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectErr) {
+					t.Fatalf("Expected error containing %q, got %v", tt.expectErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if request.Headers["X-Signature"] != tt.expectSig || request.URL != "https://api.contoso.com/orders" {
+				t.Errorf("Unexpected request after hook: %+v", request)
+			}
+		})
+	}
+}
+
+func TestVerdictPassed(t *testing.T) {
+	pass, fail := true, false
+	for _, tt := range []struct {
+		verdict Verdict
+		passed  bool
+	}{
+		{Verdict{}, true},
+		{Verdict{Pass: &pass}, true},
+		{Verdict{Pass: &fail}, false},
+	} {
+		if got := tt.verdict.Passed(); got != tt.passed {
+			t.Errorf("Passed() = %v for %+v, expected %v", got, tt.verdict, tt.passed)
+		}
+	}
+}
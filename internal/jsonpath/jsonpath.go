@@ -0,0 +1,132 @@
+// Package jsonpath implements the small subset of JSONPath needed to pick
+// values out of API responses: dot notation ($.a.b), bracketed names
+// ($['a']) and array indexes ($.items[0], $.items[-1]).
+package jsonpath
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Lookup evaluates path against a decoded JSON document (as produced by
+// json.Unmarshal into an interface{}) and returns the selected value
+func Lookup(document interface{}, path string) (interface{}, error) {
+	segments, err := parse(path)
+	if err != nil {
+		return nil, err
+	}
+
+	current := document
+	for _, segment := range segments {
+		switch node := current.(type) {
+		case map[string]interface{}:
+			if segment.isIndex {
+				return nil, fmt.Errorf("%s: cannot index object with [%d]", path, segment.index)
+			}
+			value, ok := node[segment.name]
+			if !ok {
+				return nil, fmt.Errorf("%s: key %q not found", path, segment.name)
+			}
+			current = value
+		case []interface{}:
+			if !segment.isIndex {
+				return nil, fmt.Errorf("%s: cannot select %q from array", path, segment.name)
+			}
+			index := segment.index
+			if index < 0 {
+				index += len(node)
+			}
+			if index < 0 || index >= len(node) {
+				return nil, fmt.Errorf("%s: index %d out of range (length %d)", path, segment.index, len(node))
+			}
+			current = node[index]
+		default:
+			return nil, fmt.Errorf("%s: cannot descend into %T", path, current)
+		}
+	}
+
+	return current, nil
+}
+
+// LookupBytes decodes a raw JSON body and evaluates path against it
+func LookupBytes(body []byte, path string) (interface{}, error) {
+	var document interface{}
+	if err := json.Unmarshal(body, &document); err != nil {
+		return nil, fmt.Errorf("response body is not valid JSON: %w", err)
+	}
+	return Lookup(document, path)
+}
+
+// Stringify renders a JSON value the way it should appear when substituted
+// into a URL or header: strings verbatim, everything else as compact JSON
+func Stringify(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case nil:
+		return "null"
+	default:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(encoded)
+	}
+}
+
+// segment is one step of a parsed path
+type segment struct {
+	name    string
+	index   int
+	isIndex bool
+}
+
+// parse splits a path such as $.items[0]['display name'] into segments
+func parse(path string) ([]segment, error) {
+	rest, found := strings.CutPrefix(strings.TrimSpace(path), "$")
+	if !found {
+		return nil, fmt.Errorf("path must start with $: %q", path)
+	}
+
+	var segments []segment
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("empty key in path %q", path)
+			}
+			segments = append(segments, segment{name: rest[:end]})
+			rest = rest[end:]
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated bracket in path %q", path)
+			}
+			inner := strings.TrimSpace(rest[1:end])
+			rest = rest[end+1:]
+
+			if len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0] {
+				segments = append(segments, segment{name: inner[1 : len(inner)-1]})
+				continue
+			}
+			index, err := strconv.Atoi(inner)
+			if err != nil {
+				return nil, fmt.Errorf("invalid index %q in path %q", inner, path)
+			}
+			segments = append(segments, segment{index: index, isIndex: true})
+		default:
+			return nil, fmt.Errorf("unexpected character %q in path %q", rest[0], path)
+		}
+	}
+
+	return segments, nil
+}
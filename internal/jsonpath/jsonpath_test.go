@@ -0,0 +1,76 @@
+package jsonpath
+
+import (
+	"testing"
+)
+
+const testDocument = `{
+	"id": "abc-123",
+	"count": 3,
+	"active": true,
+	"owner": {"name": "Contoso", "display name": "Contoso Ltd"},
+	"items": [{"id": 1}, {"id": 2}, {"id": 3}]
+}`
+
+func TestLookupBytes(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"$.id", "abc-123"},
+		{"$.count", "3"},
+		{"$.active", "true"},
+		{"$.owner.name", "Contoso"},
+		{"$.owner['display name']", "Contoso Ltd"},
+		{"$.items[0].id", "1"},
+		{"$.items[-1].id", "3"},
+		{"$.owner", `{"display name":"Contoso Ltd","name":"Contoso"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			value, err := LookupBytes([]byte(testDocument), tt.path)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := Stringify(value); got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestLookupBytes_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		path string
+	}{
+		{"missing key", testDocument, "$.missing"},
+		{"index out of range", testDocument, "$.items[5]"},
+		{"index on object", testDocument, "$.owner[0]"},
+		{"key on array", testDocument, "$.items.id"},
+		{"descend into scalar", testDocument, "$.id.value"},
+		{"no root", testDocument, "id"},
+		{"unterminated bracket", testDocument, "$.items[0"},
+		{"invalid json", "not json", "$.id"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := LookupBytes([]byte(tt.body), tt.path); err == nil {
+				t.Errorf("Expected error for %s, got nil", tt.path)
+			}
+		})
+	}
+}
+
+func TestLookup_Root(t *testing.T) {
+	value, err := LookupBytes([]byte(`[1, 2]`), "$")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := Stringify(value); got != "[1,2]" {
+		t.Errorf("Expected [1,2], got %s", got)
+	}
+}
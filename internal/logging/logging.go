@@ -0,0 +1,82 @@
+// Package logging builds the structured logger used for diagnostics, live
+// warnings and per-endpoint result records. Logs are kept separate from the
+// human-readable report so they can be shipped to a log pipeline (e.g. Log
+// Analytics) when the tester runs as a long-lived service.
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/hutstep/entra-id-api-tester/internal/redact"
+)
+
+// Log formats
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Options configures a logger
+type Options struct {
+	// Level is debug, info, warn or error
+	Level string
+	// Format is FormatText or FormatJSON
+	Format string
+	// File receives the logs (appended); empty means the fallback writer
+	File string
+}
+
+// ParseLevel converts a level name to a slog level
+func ParseLevel(name string) (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(name)); err != nil {
+		return 0, fmt.Errorf("invalid log level: %s (must be debug, info, warn or error)", name)
+	}
+	return level, nil
+}
+
+// New creates a logger for the options, writing to fallback unless a file
+// is configured. The returned closer releases the log file, if any.
+func New(opts Options, fallback io.Writer) (*slog.Logger, io.Closer, error) {
+	level, err := ParseLevel(opts.Level)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	format := strings.ToLower(opts.Format)
+	if format != FormatText && format != FormatJSON && format != "" {
+		return nil, nil, fmt.Errorf("invalid log format: %s (must be text or json)", opts.Format)
+	}
+
+	w := fallback
+	var closer io.Closer = nopCloser{}
+	if opts.File != "" {
+		file, err := os.OpenFile(opts.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600) // #nosec G304 -- path comes from the command line
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to open log file: %w", err)
+		}
+		w, closer = file, file
+	}
+
+	handlerOpts := &slog.HandlerOptions{Level: level}
+	var handler slog.Handler = slog.NewTextHandler(w, handlerOpts)
+	if format == FormatJSON {
+		handler = slog.NewJSONHandler(w, handlerOpts)
+	}
+
+	// Debug records include response bodies, which may echo credentials
+	return slog.New(redact.Handler(handler)), closer, nil
+}
+
+// Discard returns a logger that drops every record
+func Discard() *slog.Logger {
+	return slog.New(slog.DiscardHandler)
+}
+
+type nopCloser struct{}
+
+func (nopCloser) Close() error { return nil }
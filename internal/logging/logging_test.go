@@ -0,0 +1,96 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		name      string
+		expected  slog.Level
+		expectErr bool
+	}{
+		{"debug", slog.LevelDebug, false},
+		{"INFO", slog.LevelInfo, false},
+		{"warn", slog.LevelWarn, false},
+		{"error", slog.LevelError, false},
+		{"verbose", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			level, err := ParseLevel(tt.name)
+			if tt.expectErr {
+				if err == nil {
+					t.Errorf("Expected error for %q, got nil", tt.name)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if level != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, level)
+			}
+		})
+	}
+}
+
+func TestNew_JSON(t *testing.T) {
+	var buf bytes.Buffer
+	logger, closer, err := New(Options{Level: "info", Format: FormatJSON}, &buf)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer closer.Close()
+
+	logger.Debug("hidden")
+	logger.Info("endpoint finished", "endpoint", "Users", "status", 200)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("Expected 1 record (debug filtered), got %d: %q", len(lines), buf.String())
+	}
+	var record map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &record); err != nil {
+		t.Fatalf("Expected JSON record: %v", err)
+	}
+	if record["msg"] != "endpoint finished" || record["endpoint"] != "Users" || record["status"] != float64(200) {
+		t.Errorf("Unexpected record: %v", record)
+	}
+}
+
+func TestNew_TextToFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tester.log")
+	logger, closer, err := New(Options{Level: "debug", File: path}, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	logger.Debug("authenticating", "endpoint", "Users")
+	if err := closer.Close(); err != nil {
+		t.Fatalf("Unexpected close error: %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read log file: %v", err)
+	}
+	if !strings.Contains(string(content), "level=DEBUG msg=authenticating endpoint=Users") {
+		t.Errorf("Unexpected log file content: %q", content)
+	}
+}
+
+func TestNew_InvalidOptions(t *testing.T) {
+	if _, _, err := New(Options{Level: "loud"}, &bytes.Buffer{}); err == nil {
+		t.Error("Expected error for invalid level, got nil")
+	}
+	if _, _, err := New(Options{Level: "info", Format: "xml"}, &bytes.Buffer{}); err == nil {
+		t.Error("Expected error for invalid format, got nil")
+	}
+}
@@ -0,0 +1,113 @@
+// Package monitor samples the tester's own memory and goroutine usage so
+// very large suites can report their footprint and abort gracefully, with a
+// partial summary, instead of being OOM-killed.
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// ErrMemoryLimit is the cancellation cause when the memory limit is exceeded
+var ErrMemoryLimit = errors.New("memory limit exceeded")
+
+// DefaultInterval is how often usage is sampled
+const DefaultInterval = 250 * time.Millisecond
+
+// Usage is a snapshot of peak resource usage
+type Usage struct {
+	PeakHeapBytes  uint64
+	PeakGoroutines int
+}
+
+// String renders usage for the summary
+func (u Usage) String() string {
+	return fmt.Sprintf("peak heap %.1f MB, peak goroutines %d", float64(u.PeakHeapBytes)/(1024*1024), u.PeakGoroutines)
+}
+
+// Monitor tracks peak usage and enforces an optional heap limit
+type Monitor struct {
+	stop          chan struct{}
+	done          chan struct{}
+	usage         Usage
+	limitBytes    uint64
+	previousLimit int64
+	mu            sync.Mutex
+}
+
+// New creates a monitor. A limitMB of zero only records usage.
+func New(limitMB int) *Monitor {
+	return &Monitor{
+		limitBytes: uint64(limitMB) * 1024 * 1024, // #nosec G115 - limit is validated non-negative
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+}
+
+// Start samples usage every interval until Stop is called. When the heap
+// exceeds the limit, cancel is invoked with ErrMemoryLimit. A soft limit is
+// also handed to the garbage collector so it works harder before that point.
+func (m *Monitor) Start(cancel context.CancelCauseFunc, interval time.Duration) {
+	if m.limitBytes > 0 {
+		m.previousLimit = debug.SetMemoryLimit(int64(m.limitBytes)) // #nosec G115 - limit comes from a small MB value
+	}
+
+	go func() {
+		defer close(m.done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			if m.Sample() {
+				cancel(fmt.Errorf("%w (%d MB)", ErrMemoryLimit, m.limitBytes/(1024*1024)))
+				return
+			}
+			select {
+			case <-m.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop ends sampling and waits for the sampler to exit
+func (m *Monitor) Stop() {
+	select {
+	case <-m.stop:
+	default:
+		close(m.stop)
+	}
+	<-m.done
+
+	if m.previousLimit != 0 {
+		debug.SetMemoryLimit(m.previousLimit)
+		m.previousLimit = 0
+	}
+}
+
+// Sample records current usage and reports whether the limit is exceeded
+func (m *Monitor) Sample() bool {
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	goroutines := runtime.NumGoroutine()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.usage.PeakHeapBytes = max(m.usage.PeakHeapBytes, stats.HeapAlloc)
+	m.usage.PeakGoroutines = max(m.usage.PeakGoroutines, goroutines)
+
+	return m.limitBytes > 0 && stats.HeapAlloc > m.limitBytes
+}
+
+// Usage returns the peak usage observed so far
+func (m *Monitor) Usage() Usage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.usage
+}
@@ -0,0 +1,56 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMonitor_RecordsUsage(t *testing.T) {
+	m := New(0)
+	if m.Sample() {
+		t.Error("Expected no limit to be exceeded without a limit")
+	}
+
+	usage := m.Usage()
+	if usage.PeakHeapBytes == 0 {
+		t.Error("Expected peak heap to be recorded")
+	}
+	if usage.PeakGoroutines < 1 {
+		t.Errorf("Expected at least one goroutine, got %d", usage.PeakGoroutines)
+	}
+}
+
+// ballast keeps memory reachable so the heap exceeds a small limit
+var ballast []byte
+
+func TestMonitor_CancelsWhenLimitExceeded(t *testing.T) {
+	m := New(1)
+	ballast = make([]byte, 4*1024*1024)
+	defer func() { ballast = nil }()
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	defer cancel(nil)
+	m.Start(cancel, 10*time.Millisecond)
+	defer m.Stop()
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected context to be cancelled when memory limit exceeded")
+	}
+	if !errors.Is(context.Cause(ctx), ErrMemoryLimit) {
+		t.Errorf("Expected ErrMemoryLimit cause, got %v", context.Cause(ctx))
+	}
+}
+
+func TestMonitor_StopIsIdempotent(t *testing.T) {
+	m := New(0)
+	_, cancel := context.WithCancelCause(context.Background())
+	defer cancel(nil)
+
+	m.Start(cancel, time.Millisecond)
+	m.Stop()
+	m.Stop()
+}
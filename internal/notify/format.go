@@ -0,0 +1,114 @@
+package notify
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hutstep/entra-id-api-tester/internal/report"
+)
+
+const (
+	// maxListedFailures caps the failed endpoints listed in chat messages,
+	// which limit the number of blocks and the message size
+	maxListedFailures = 20
+	// maxErrorLength truncates long error messages in chat messages
+	maxErrorLength = 500
+)
+
+// object is a JSON object in a chat message payload
+type object = map[string]interface{}
+
+// teamsMessage renders the payload as an Adaptive Card for a Microsoft
+// Teams incoming webhook
+func teamsMessage(payload *Payload) object {
+	facts := []object{
+		{"title": "Total", "value": fmt.Sprint(payload.Summary.Total)},
+		{"title": "Passed", "value": fmt.Sprint(payload.Summary.Passed)},
+		{"title": "Failed", "value": fmt.Sprint(payload.Summary.Failed)},
+		{"title": "Skipped", "value": fmt.Sprint(payload.Summary.Skipped)},
+		{"title": "Duration", "value": runDuration(payload)},
+	}
+	body := []interface{}{
+		object{"type": "TextBlock", "text": payload.Text, "weight": "Bolder", "size": "Medium", "color": "Attention", "wrap": true},
+		object{"type": "FactSet", "facts": facts},
+	}
+
+	listed, more := listedFailures(payload)
+	for _, endpoint := range listed {
+		body = append(body,
+			object{"type": "TextBlock", "text": fmt.Sprintf("**%s** (%s, %dms)", endpoint.Name, endpoint.FailedPhase, endpoint.DurationMs), "wrap": true, "separator": true},
+			object{"type": "TextBlock", "text": truncate(endpoint.Error), "wrap": true, "isSubtle": true, "fontType": "Monospace"},
+		)
+	}
+	if more > 0 {
+		body = append(body, object{"type": "TextBlock", "text": fmt.Sprintf("…and %d more failed endpoint(s)", more), "wrap": true, "isSubtle": true})
+	}
+
+	return object{
+		"type": "message",
+		"attachments": []object{{
+			"contentType": "application/vnd.microsoft.card.adaptive",
+			"content": object{
+				"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+				"type":    "AdaptiveCard",
+				"version": "1.4",
+				"body":    body,
+			},
+		}},
+	}
+}
+
+// slackMessage renders the payload as Block Kit blocks for a Slack
+// incoming webhook. Text is the notification fallback.
+func slackMessage(payload *Payload) object {
+	blocks := []object{
+		{"type": "header", "text": object{"type": "plain_text", "text": "API test run failed"}},
+		{"type": "section", "text": object{"type": "mrkdwn", "text": payload.Text}},
+		{"type": "section", "fields": []object{
+			{"type": "mrkdwn", "text": fmt.Sprintf("*Passed*\n%d", payload.Summary.Passed)},
+			{"type": "mrkdwn", "text": fmt.Sprintf("*Failed*\n%d", payload.Summary.Failed)},
+			{"type": "mrkdwn", "text": fmt.Sprintf("*Skipped*\n%d", payload.Summary.Skipped)},
+			{"type": "mrkdwn", "text": fmt.Sprintf("*Duration*\n%s", runDuration(payload))},
+		}},
+		{"type": "divider"},
+	}
+
+	listed, more := listedFailures(payload)
+	for _, endpoint := range listed {
+		text := fmt.Sprintf("*%s* (%s, %dms)\n```%s```", endpoint.Name, endpoint.FailedPhase, endpoint.DurationMs, truncate(endpoint.Error))
+		blocks = append(blocks, object{"type": "section", "text": object{"type": "mrkdwn", "text": text}})
+	}
+	if more > 0 {
+		blocks = append(blocks, object{"type": "context", "elements": []object{
+			{"type": "mrkdwn", "text": fmt.Sprintf("…and %d more failed endpoint(s)", more)},
+		}})
+	}
+
+	return object{"text": payload.Text, "blocks": blocks}
+}
+
+// listedFailures returns the failures to list and how many were left out
+func listedFailures(payload *Payload) (listed []report.Endpoint, more int) {
+	if len(payload.Failures) <= maxListedFailures {
+		return payload.Failures, 0
+	}
+	return payload.Failures[:maxListedFailures], len(payload.Failures) - maxListedFailures
+}
+
+// runDuration returns the wall-clock duration of the run
+func runDuration(payload *Payload) string {
+	if payload.StartedAt.IsZero() || payload.FinishedAt.IsZero() {
+		return "unknown"
+	}
+	return payload.FinishedAt.Sub(payload.StartedAt).Round(time.Millisecond).String()
+}
+
+// truncate shortens an error message for chat messages
+func truncate(message string) string {
+	message = strings.TrimSpace(message)
+	if len([]rune(message)) <= maxErrorLength {
+		return message
+	}
+	return string([]rune(message)[:maxErrorLength]) + "…"
+}
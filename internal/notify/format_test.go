@@ -0,0 +1,127 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hutstep/entra-id-api-tester/internal/config"
+	"github.com/hutstep/entra-id-api-tester/internal/report"
+)
+
+func TestNew_NotConfigured(t *testing.T) {
+	for _, cfg := range []*config.Notifications{nil, {}} {
+		webhook, err := New(cfg, nil)
+		if err != nil || webhook != nil {
+			t.Errorf("Expected no notifier, got %v (%v)", webhook, err)
+		}
+	}
+}
+
+func TestWebhook_Teams(t *testing.T) {
+	received, server := newReceiver(t, http.StatusAccepted)
+	webhook, err := New(&config.Notifications{Type: config.NotificationTeams, Webhook: &config.Webhook{URL: server.URL}}, nil)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if err := webhook.Notify(context.Background(), testReport()); err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+
+	var message struct {
+		Type        string `json:"type"`
+		Attachments []struct {
+			Content struct {
+				Type string `json:"type"`
+				Body []struct {
+					Type string `json:"type"`
+					Text string `json:"text"`
+				} `json:"body"`
+			} `json:"content"`
+			ContentType string `json:"contentType"`
+		} `json:"attachments"`
+	}
+	if err := json.Unmarshal([]byte(received.body), &message); err != nil {
+		t.Fatalf("Message is not valid JSON: %v", err)
+	}
+	if message.Type != "message" || len(message.Attachments) != 1 {
+		t.Fatalf("Unexpected message: %s", received.body)
+	}
+	card := message.Attachments[0]
+	if card.ContentType != "application/vnd.microsoft.card.adaptive" || card.Content.Type != "AdaptiveCard" {
+		t.Errorf("Expected an Adaptive Card, got %s", received.body)
+	}
+	if card.Content.Body[0].Text != Summary(testReport()) {
+		t.Errorf("Expected the summary as title, got %q", card.Content.Body[0].Text)
+	}
+	if !strings.Contains(received.body, "**orders** (auth, 0ms)") || !strings.Contains(received.body, "invalid_client") {
+		t.Errorf("Expected failed endpoints to be listed, got %s", received.body)
+	}
+}
+
+func TestWebhook_Slack(t *testing.T) {
+	received, server := newReceiver(t, http.StatusOK)
+	webhook, err := New(&config.Notifications{Type: config.NotificationSlack, Webhook: &config.Webhook{URL: server.URL}}, nil)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	runReport := testReport()
+	runReport.StartedAt = time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	runReport.FinishedAt = runReport.StartedAt.Add(1500 * time.Millisecond)
+	if err := webhook.Notify(context.Background(), runReport); err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+
+	var message struct {
+		Text   string                   `json:"text"`
+		Blocks []map[string]interface{} `json:"blocks"`
+	}
+	if err := json.Unmarshal([]byte(received.body), &message); err != nil {
+		t.Fatalf("Message is not valid JSON: %v", err)
+	}
+	if message.Text != Summary(runReport) {
+		t.Errorf("Expected the summary as fallback text, got %q", message.Text)
+	}
+	if message.Blocks[0]["type"] != "header" {
+		t.Errorf("Expected a header block first, got %v", message.Blocks[0])
+	}
+	// header, summary, counts, divider and one section per failure
+	if len(message.Blocks) != 6 {
+		t.Errorf("Expected 6 blocks, got %d", len(message.Blocks))
+	}
+	if !strings.Contains(received.body, `*Duration*\n1.5s`) {
+		t.Errorf("Expected the run duration, got %s", received.body)
+	}
+}
+
+func TestListedFailures(t *testing.T) {
+	payload := &Payload{Report: &report.Report{}}
+	for i := 0; i < maxListedFailures+3; i++ {
+		payload.Failures = append(payload.Failures, report.Endpoint{Name: fmt.Sprintf("endpoint %d", i)})
+	}
+
+	listed, more := listedFailures(payload)
+	if len(listed) != maxListedFailures || more != 3 {
+		t.Errorf("Expected %d listed and 3 more, got %d and %d", maxListedFailures, len(listed), more)
+	}
+
+	message := slackMessage(payload)
+	if !strings.Contains(fmt.Sprint(message), "…and 3 more failed endpoint(s)") {
+		t.Error("Expected the omitted failures to be counted")
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	if got := truncate("  short  "); got != "short" {
+		t.Errorf("Expected trimmed message, got %q", got)
+	}
+	long := strings.Repeat("é", maxErrorLength+10)
+	got := truncate(long)
+	if len([]rune(got)) != maxErrorLength+1 || !strings.HasSuffix(got, "…") {
+		t.Errorf("Expected message truncated to %d runes, got %d", maxErrorLength, len([]rune(got)))
+	}
+}
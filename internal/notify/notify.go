@@ -0,0 +1,223 @@
+// Package notify reports the outcome of a run to external systems, such as
+// incident tooling listening on a webhook.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/hutstep/entra-id-api-tester/internal/config"
+	"github.com/hutstep/entra-id-api-tester/internal/report"
+)
+
+// DefaultTimeout bounds a single notification request
+const DefaultTimeout = 30 * time.Second
+
+// HTTPClient is the subset of *http.Client used to send notifications
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Payload is the data available to payload templates
+type Payload struct {
+	*report.Report
+	// Failures are the endpoints that failed
+	Failures []report.Endpoint
+	// Text is a one-line summary, e.g. "API test run failed: 2 of 5
+	// endpoint(s) failed (2 authentication)"
+	Text string
+}
+
+// NewPayload builds the template data for a report
+func NewPayload(runReport *report.Report) *Payload {
+	payload := &Payload{Report: runReport, Text: Summary(runReport)}
+	for _, endpoint := range runReport.Endpoints {
+		if endpoint.Status == report.StatusFailed {
+			payload.Failures = append(payload.Failures, endpoint)
+		}
+	}
+	return payload
+}
+
+// Summary describes the outcome of a run in one line
+func Summary(runReport *report.Report) string {
+	summary := runReport.Summary
+	if summary.Failed == 0 {
+		return fmt.Sprintf("API test run passed: %d of %d endpoint(s) passed", summary.Passed, summary.Total)
+	}
+
+	var reasons []string
+	for _, reason := range []struct {
+		name  string
+		count int
+	}{
+		{"authentication", summary.AuthFailures},
+		{"preparation", summary.PrepareFailures},
+		{"connectivity", summary.ConnectFailures},
+		{"response", summary.ResponseFailures},
+		{"security", summary.SecurityFailures},
+		{"contract", summary.ContractFailures},
+		{"performance", summary.PerformanceFailures},
+	} {
+		if reason.count > 0 {
+			reasons = append(reasons, fmt.Sprintf("%d %s", reason.count, reason.name))
+		}
+	}
+	return fmt.Sprintf("API test run failed: %d of %d endpoint(s) failed (%s)", summary.Failed, summary.Total, strings.Join(reasons, ", "))
+}
+
+// templateFuncs are available in payload templates
+var templateFuncs = template.FuncMap{
+	// json encodes a value, e.g. {{json .Text}} for a quoted JSON string
+	"json": func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+}
+
+// Webhook POSTs run summaries to a configured URL
+type Webhook struct {
+	client   HTTPClient
+	template *template.Template
+	headers  map[string]string
+	url      string
+	// format is the notification type: webhook, teams or slack
+	format string
+}
+
+// New creates the notifier configured in cfg, parsing its payload
+// template, or returns nil when no notifications are configured. A nil
+// httpClient uses one with DefaultTimeout.
+func New(cfg *config.Notifications, httpClient HTTPClient) (*Webhook, error) {
+	if cfg == nil || cfg.Webhook == nil {
+		return nil, nil
+	}
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: DefaultTimeout}
+	}
+	webhook := &Webhook{
+		client:  httpClient,
+		headers: cfg.Webhook.Headers,
+		url:     cfg.Webhook.URL,
+		format:  cfg.Type,
+	}
+	if webhook.format == "" {
+		webhook.format = config.NotificationWebhook
+	}
+	if cfg.Webhook.PayloadTemplate != "" {
+		tmpl, err := template.New("payload").Funcs(templateFuncs).Option("missingkey=error").Parse(cfg.Webhook.PayloadTemplate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid payloadTemplate: %w", err)
+		}
+		webhook.template = tmpl
+	}
+	return webhook, nil
+}
+
+// Notify sends the report summary to the webhook
+func (w *Webhook) Notify(ctx context.Context, runReport *report.Report) error {
+	body, err := w.render(NewPayload(runReport))
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range w.headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call webhook: %w", err)
+	}
+	defer func() {
+		if closeErr := resp.Body.Close(); closeErr != nil {
+			_ = closeErr
+		}
+	}()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		detail, err := io.ReadAll(io.LimitReader(resp.Body, 512))
+		if err != nil {
+			return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+		}
+		return fmt.Errorf("webhook returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(detail)))
+	}
+	return nil
+}
+
+// defaultPayload is sent when no payload template is configured
+type defaultPayload struct {
+	StartedAt  time.Time        `json:"startedAt"`
+	FinishedAt time.Time        `json:"finishedAt"`
+	Failures   []defaultFailure `json:"failures"`
+	Text       string           `json:"text"`
+	Version    string           `json:"version"`
+	Aborted    string           `json:"aborted,omitempty"`
+	Summary    report.Summary   `json:"summary"`
+}
+
+// defaultFailure is a failed endpoint in the default payload
+type defaultFailure struct {
+	Name        string `json:"name"`
+	FailedPhase string `json:"failedPhase"`
+	Error       string `json:"error"`
+	StatusCode  int    `json:"statusCode,omitempty"`
+}
+
+// render produces the request body in the notifier's format: a Teams or
+// Slack message, the payload template, or the default JSON payload
+func (w *Webhook) render(payload *Payload) ([]byte, error) {
+	switch w.format {
+	case config.NotificationTeams:
+		return encode(teamsMessage(payload))
+	case config.NotificationSlack:
+		return encode(slackMessage(payload))
+	}
+
+	if w.template != nil {
+		var buf bytes.Buffer
+		if err := w.template.Execute(&buf, payload); err != nil {
+			return nil, fmt.Errorf("failed to render payloadTemplate: %w", err)
+		}
+		return buf.Bytes(), nil
+	}
+
+	body := defaultPayload{
+		StartedAt:  payload.StartedAt,
+		FinishedAt: payload.FinishedAt,
+		Failures:   make([]defaultFailure, len(payload.Failures)),
+		Text:       payload.Text,
+		Version:    payload.Version,
+		Aborted:    payload.Aborted,
+		Summary:    payload.Summary,
+	}
+	for i, endpoint := range payload.Failures {
+		body.Failures[i] = defaultFailure{
+			Name:        endpoint.Name,
+			FailedPhase: endpoint.FailedPhase,
+			Error:       endpoint.Error,
+			StatusCode:  endpoint.StatusCode,
+		}
+	}
+	return encode(body)
+}
+
+// encode marshals a webhook payload
+func encode(payload interface{}) ([]byte, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,147 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hutstep/entra-id-api-tester/internal/config"
+	"github.com/hutstep/entra-id-api-tester/internal/report"
+)
+
+func testReport() *report.Report {
+	return &report.Report{
+		Version: "1.2.3",
+		Endpoints: []report.Endpoint{
+			{Name: "users", Status: report.StatusPassed},
+			{Name: "orders", Status: report.StatusFailed, FailedPhase: "auth", Error: "Authentication failed: invalid_client"},
+			{Name: "items", Status: report.StatusFailed, FailedPhase: "response", Error: `Unexpected status code: 500 "oops"`, StatusCode: 500},
+		},
+		Summary: report.Summary{Total: 3, Passed: 1, Failed: 2, AuthFailures: 1, ResponseFailures: 1},
+	}
+}
+
+// receiver records the last webhook request
+type receiver struct {
+	headers http.Header
+	body    string
+}
+
+func newReceiver(t *testing.T, status int) (*receiver, *httptest.Server) {
+	t.Helper()
+	received := &receiver{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received.headers = r.Header
+		received.body = string(body)
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+	return received, server
+}
+
+// newWebhook creates a generic webhook notifier for cfg
+func newWebhook(t *testing.T, cfg *config.Webhook, httpClient HTTPClient) (*Webhook, error) {
+	t.Helper()
+	return New(&config.Notifications{Webhook: cfg}, httpClient)
+}
+
+func TestSummary(t *testing.T) {
+	expected := "API test run failed: 2 of 3 endpoint(s) failed (1 authentication, 1 response)"
+	if got := Summary(testReport()); got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+
+	passed := &report.Report{Summary: report.Summary{Total: 2, Passed: 2}}
+	if got := Summary(passed); got != "API test run passed: 2 of 2 endpoint(s) passed" {
+		t.Errorf("Unexpected summary: %q", got)
+	}
+}
+
+func TestWebhook_DefaultPayload(t *testing.T) {
+	received, server := newReceiver(t, http.StatusAccepted)
+	webhook, err := newWebhook(t, &config.Webhook{
+		URL:     server.URL,
+		Headers: map[string]string{"X-Api-Key": "secret"},
+	}, nil)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	if err := webhook.Notify(context.Background(), testReport()); err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+
+	if received.headers.Get("X-Api-Key") != "secret" || received.headers.Get("Content-Type") != "application/json" {
+		t.Errorf("Unexpected headers: %v", received.headers)
+	}
+	var payload defaultPayload
+	if err := json.Unmarshal([]byte(received.body), &payload); err != nil {
+		t.Fatalf("Payload is not valid JSON: %v", err)
+	}
+	if payload.Summary.Failed != 2 || len(payload.Failures) != 2 || payload.Version != "1.2.3" {
+		t.Errorf("Unexpected payload: %+v", payload)
+	}
+	if payload.Failures[1].Name != "items" || payload.Failures[1].StatusCode != 500 {
+		t.Errorf("Unexpected failure: %+v", payload.Failures[1])
+	}
+}
+
+func TestWebhook_PayloadTemplate(t *testing.T) {
+	received, server := newReceiver(t, http.StatusOK)
+	webhook, err := newWebhook(t, &config.Webhook{
+		URL:             server.URL,
+		Headers:         map[string]string{"Content-Type": "text/plain"},
+		PayloadTemplate: `{"summary": {{json .Text}}, "failed": [{{range $i, $f := .Failures}}{{if $i}}, {{end}}{{json $f.Error}}{{end}}], "total": {{.Summary.Total}}}`,
+	}, nil)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	if err := webhook.Notify(context.Background(), testReport()); err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+
+	var payload struct {
+		Summary string   `json:"summary"`
+		Failed  []string `json:"failed"`
+		Total   int      `json:"total"`
+	}
+	if err := json.Unmarshal([]byte(received.body), &payload); err != nil {
+		t.Fatalf("Rendered payload is not valid JSON: %v\n%s", err, received.body)
+	}
+	if payload.Total != 3 || len(payload.Failed) != 2 || payload.Failed[1] != `Unexpected status code: 500 "oops"` {
+		t.Errorf("Unexpected payload: %+v", payload)
+	}
+	if received.headers.Get("Content-Type") != "text/plain" {
+		t.Errorf("Expected configured header to override Content-Type, got %q", received.headers.Get("Content-Type"))
+	}
+}
+
+func TestWebhook_Errors(t *testing.T) {
+	if _, err := newWebhook(t, &config.Webhook{URL: "https://example.com", PayloadTemplate: "{{.Text"}, nil); err == nil {
+		t.Error("Expected error for invalid template, got nil")
+	}
+
+	_, server := newReceiver(t, http.StatusInternalServerError)
+	webhook, err := newWebhook(t, &config.Webhook{URL: server.URL}, nil)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if err := webhook.Notify(context.Background(), testReport()); err == nil || !strings.Contains(err.Error(), "status 500") {
+		t.Errorf("Expected status error, got %v", err)
+	}
+
+	webhook, err = newWebhook(t, &config.Webhook{URL: server.URL, PayloadTemplate: "{{.Missing}}"}, &http.Client{Timeout: time.Second})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if err := webhook.Notify(context.Background(), testReport()); err == nil {
+		t.Error("Expected error for unknown template field, got nil")
+	}
+}
@@ -0,0 +1,107 @@
+package openapi
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// importMethods are the methods endpoints can be configured with
+var importMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS"}
+
+// Endpoint is an endpoint generated from an operation
+type Endpoint struct {
+	// Body is the example request body, if the operation takes one
+	Body   map[string]interface{}
+	Name   string
+	Method string
+	URL    string
+}
+
+// Import generates an endpoint for each operation, with its path relative
+// to baseURL. Path and required query parameters are filled in from their
+// examples. The returned notes list operations that were skipped or whose
+// endpoint needs editing.
+func (s *Spec) Import(baseURL string) ([]Endpoint, []string) {
+	baseURL = strings.TrimSuffix(baseURL, "/")
+	var endpoints []Endpoint
+	var notes []string
+	names := make(map[string]int)
+
+	for _, operation := range s.Operations() {
+		label := operation.Method + " " + operation.Path
+		if !slices.Contains(importMethods, operation.Method) {
+			notes = append(notes, fmt.Sprintf("%s: skipped, %s is not supported", label, operation.Method))
+			continue
+		}
+
+		name := operation.ID
+		if name == "" {
+			name = label
+		}
+		names[name]++
+		if names[name] > 1 {
+			name = fmt.Sprintf("%s (%d)", name, names[name])
+		}
+
+		endpointURL, urlNotes := s.operationURL(baseURL, operation)
+		notes = append(notes, prefixed(label, urlNotes)...)
+		endpoint := Endpoint{Name: name, Method: operation.Method, URL: endpointURL}
+
+		if example, ok := s.RequestExample(operation); ok {
+			if body, isObject := example.(map[string]interface{}); isObject {
+				endpoint.Body = body
+			} else {
+				notes = append(notes, label+": the request body example is not a JSON object; add the body by hand")
+			}
+		}
+		endpoints = append(endpoints, endpoint)
+	}
+	return endpoints, notes
+}
+
+// operationURL returns the URL of an operation with its path and required
+// query parameters set to their examples. Parameters without an example are
+// left as {name} and reported.
+func (s *Spec) operationURL(baseURL string, operation Operation) (string, []string) {
+	var notes []string
+	path := operation.Path
+	for _, parameter := range s.Parameters(operation, "path") {
+		name := asString(parameter["name"])
+		example, ok := s.ParameterExample(parameter)
+		if !ok {
+			notes = append(notes, fmt.Sprintf("no example for path parameter %s; replace {%s} in the URL", name, name))
+			continue
+		}
+		path = strings.ReplaceAll(path, "{"+name+"}", url.PathEscape(fmt.Sprint(example)))
+	}
+
+	query := url.Values{}
+	for _, parameter := range s.Parameters(operation, "query") {
+		if parameter["required"] != true {
+			continue
+		}
+		name := asString(parameter["name"])
+		example, ok := s.ParameterExample(parameter)
+		if !ok {
+			notes = append(notes, fmt.Sprintf("no example for required query parameter %s; add it to the URL", name))
+			continue
+		}
+		query.Set(name, fmt.Sprint(example))
+	}
+
+	endpointURL := baseURL + path
+	if len(query) > 0 {
+		endpointURL += "?" + query.Encode()
+	}
+	return endpointURL, notes
+}
+
+// prefixed prefixes each note with the operation it is about
+func prefixed(label string, notes []string) []string {
+	for i, note := range notes {
+		notes[i] = label + ": " + note
+	}
+	return notes
+}
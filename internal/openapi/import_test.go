@@ -0,0 +1,93 @@
+package openapi
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSpec_Import(t *testing.T) {
+	tests := []struct {
+		name      string
+		spec      string
+		baseURL   string
+		endpoints []Endpoint
+		notes     []string
+	}{
+		{
+			name:    "OpenAPI 3",
+			spec:    petstoreYAML,
+			baseURL: "https://pets.example.com/",
+			endpoints: []Endpoint{
+				{Name: "listPets", Method: "GET", URL: "https://pets.example.com/pets?limit=10"},
+				{Name: "createPet", Method: "POST", URL: "https://pets.example.com/pets", Body: map[string]interface{}{
+					"name":  "Rex",
+					"born":  "2025-01-01",
+					"tags":  []interface{}{"cat"},
+					"owner": map[string]interface{}{},
+				}},
+				{Name: "GET /pets/{petId}", Method: "GET", URL: "https://pets.example.com/pets/rex"},
+				{Name: "updatePet", Method: "PUT", URL: "https://pets.example.com/pets/rex", Body: map[string]interface{}{"name": "Max"}},
+				{Name: "petExists", Method: "HEAD", URL: "https://pets.example.com/pets/rex"},
+			},
+		},
+		{
+			name:    "Swagger 2.0",
+			spec:    swaggerJSON,
+			baseURL: "http://api.example.com/v2",
+			endpoints: []Endpoint{
+				{Name: "POST /orders", Method: "POST", URL: "http://api.example.com/v2/orders", Body: map[string]interface{}{"quantity": 0, "paid": false}},
+				{Name: "DELETE /orders/{id}", Method: "DELETE", URL: "http://api.example.com/v2/orders/42"},
+			},
+		},
+		{
+			name: "missing examples",
+			spec: `
+openapi: 3.1.0
+paths:
+  /items/{id}:
+    get:
+      operationId: getItem
+      parameters:
+        - {name: id, in: path, required: true, schema: {type: string}}
+        - {name: q, in: query, required: true, schema: {type: string}}
+  /items:
+    post:
+      operationId: getItem
+      requestBody:
+        content:
+          application/json:
+            example: [1, 2]
+    trace:
+      operationId: traceItems
+`,
+			baseURL: "https://api.example.com",
+			endpoints: []Endpoint{
+				{Name: "getItem", Method: "POST", URL: "https://api.example.com/items"},
+				{Name: "getItem (2)", Method: "GET", URL: "https://api.example.com/items/{id}"},
+			},
+			notes: []string{
+				"POST /items: the request body example is not a JSON object; add the body by hand",
+				"TRACE /items: skipped, TRACE is not supported",
+				"GET /items/{id}: no example for path parameter id; replace {id} in the URL",
+				"GET /items/{id}: no example for required query parameter q; add it to the URL",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec, err := Parse([]byte(tt.spec))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			endpoints, notes := spec.Import(tt.baseURL)
+			if !reflect.DeepEqual(endpoints, tt.endpoints) {
+				t.Errorf("Expected endpoints %+v, got %+v", tt.endpoints, endpoints)
+			}
+			if !reflect.DeepEqual(notes, tt.notes) {
+				t.Errorf("Expected notes %q, got %q", tt.notes, notes)
+			}
+		})
+	}
+}
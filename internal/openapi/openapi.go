@@ -0,0 +1,416 @@
+// Package openapi reads OpenAPI 3 and Swagger 2.0 API descriptions, in JSON
+// or YAML, to bootstrap endpoint configurations from an API's contract. Only
+// local $ref references (#/components/..., #/definitions/...) are resolved.
+package openapi
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// maxDepth bounds $ref chains and nested schemas, which may be recursive
+const maxDepth = 16
+
+// methods are the operation keys of a path item, in the order operations
+// are listed
+var methods = []string{"get", "post", "put", "patch", "delete", "head", "options", "trace"}
+
+// Spec is a parsed API description
+type Spec struct {
+	document map[string]interface{}
+}
+
+// Operation is a single method on a path
+type Operation struct {
+	node map[string]interface{}
+	// parameters are the path item's parameters, which the operation's own
+	// parameters of the same name and location override
+	parameters []interface{}
+	Method     string
+	Path       string
+	ID         string
+	Summary    string
+}
+
+// Load reads an API description from a JSON or YAML file
+func Load(path string) (*Spec, error) {
+	data, err := os.ReadFile(path) // #nosec G304 - spec path is provided by user via CLI argument
+	if err != nil {
+		return nil, fmt.Errorf("failed to read API description: %w", err)
+	}
+	return Parse(data)
+}
+
+// Parse decodes a JSON or YAML API description
+func Parse(data []byte) (*Spec, error) {
+	var raw interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to decode API description: %w", err)
+	}
+	document, ok := normalize(raw).(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("failed to decode API description: not an object")
+	}
+	if document["openapi"] == nil && document["swagger"] == nil {
+		return nil, fmt.Errorf("not an OpenAPI or Swagger document (no openapi or swagger version)")
+	}
+	return &Spec{document: document}, nil
+}
+
+// normalize converts the map[interface{}]interface{} values YAML produces for
+// non-string keys, such as status codes, into map[string]interface{}
+func normalize(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = normalize(item)
+		}
+		return v
+	case map[interface{}]interface{}:
+		converted := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			converted[fmt.Sprint(key)] = normalize(item)
+		}
+		return converted
+	case []interface{}:
+		for i, item := range v {
+			v[i] = normalize(item)
+		}
+		return v
+	default:
+		return value
+	}
+}
+
+// BaseURL returns the URL the paths are relative to: the first server of an
+// OpenAPI 3 document, with its variables set to their defaults, or the
+// scheme, host and base path of a Swagger 2.0 document. It is empty when the
+// document does not say.
+func (s *Spec) BaseURL() string {
+	if servers := asList(s.document["servers"]); len(servers) > 0 {
+		server := asMap(servers[0])
+		serverURL := asString(server["url"])
+		for name, variable := range asMap(server["variables"]) {
+			serverURL = strings.ReplaceAll(serverURL, "{"+name+"}", fmt.Sprint(asMap(variable)["default"]))
+		}
+		return strings.TrimSuffix(serverURL, "/")
+	}
+
+	host := asString(s.document["host"])
+	if host == "" {
+		return ""
+	}
+	scheme := "https"
+	if schemes := asList(s.document["schemes"]); len(schemes) > 0 && !slices.Contains(schemes, interface{}("https")) {
+		scheme = asString(schemes[0])
+	}
+	return scheme + "://" + host + strings.TrimSuffix(asString(s.document["basePath"]), "/")
+}
+
+// Operations returns the operations ordered by path, then method
+func (s *Spec) Operations() []Operation {
+	paths := asMap(s.document["paths"])
+	var operations []Operation
+	for _, path := range sortedKeys(paths) {
+		item := asMap(s.resolve(paths[path]))
+		parameters := asList(item["parameters"])
+		for _, method := range methods {
+			node, ok := item[method].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			operations = append(operations, Operation{
+				node:       node,
+				parameters: parameters,
+				Method:     strings.ToUpper(method),
+				Path:       path,
+				ID:         asString(node["operationId"]),
+				Summary:    asString(node["summary"]),
+			})
+		}
+	}
+	return operations
+}
+
+// Parameters returns the operation's parameters in a location ("path",
+// "query", "header", "body"...), with references resolved
+func (s *Spec) Parameters(operation Operation, location string) []map[string]interface{} {
+	own := asList(operation.node["parameters"])
+	var parameters []map[string]interface{}
+	seen := make(map[string]bool)
+	for _, list := range [][]interface{}{own, operation.parameters} {
+		for _, raw := range list {
+			parameter := asMap(s.resolve(raw))
+			name := asString(parameter["name"])
+			if asString(parameter["in"]) != location || seen[name] {
+				continue
+			}
+			seen[name] = true
+			parameters = append(parameters, parameter)
+		}
+	}
+	return parameters
+}
+
+// ParameterExample returns the example value given for a parameter by its
+// example, its examples or its schema
+func (s *Spec) ParameterExample(parameter map[string]interface{}) (interface{}, bool) {
+	if example, ok := parameter["example"]; ok {
+		return example, true
+	}
+	if example, ok := parameter["x-example"]; ok {
+		return example, true
+	}
+	if example, ok := s.firstExample(parameter["examples"]); ok {
+		return example, true
+	}
+	schema := parameter["schema"]
+	if schema == nil {
+		// Swagger 2.0 declares non-body parameter types inline
+		schema = parameter
+	}
+	return explicitExample(asMap(s.resolve(schema)))
+}
+
+// RequestExample returns an example request body for the operation: an
+// example given for its JSON content, or one built from the schema
+func (s *Spec) RequestExample(operation Operation) (interface{}, bool) {
+	if body := asMap(s.resolve(operation.node["requestBody"])); body != nil {
+		media := s.jsonMedia(body["content"])
+		if media == nil {
+			return nil, false
+		}
+		if example, ok := media["example"]; ok {
+			return example, true
+		}
+		if example, ok := s.firstExample(media["examples"]); ok {
+			return example, true
+		}
+		return s.schemaExample(media["schema"])
+	}
+
+	if parameters := s.Parameters(operation, "body"); len(parameters) > 0 {
+		return s.schemaExample(parameters[0]["schema"])
+	}
+	return nil, false
+}
+
+// jsonMedia returns the media type object for JSON in a content map
+func (s *Spec) jsonMedia(content interface{}) map[string]interface{} {
+	media := asMap(content)
+	for _, mediaType := range sortedKeys(media) {
+		if mediaType == "application/json" || strings.HasSuffix(mediaType, "+json") {
+			return asMap(media[mediaType])
+		}
+	}
+	return nil
+}
+
+// firstExample returns the value of the first entry, by name, of an OpenAPI 3
+// examples map
+func (s *Spec) firstExample(examples interface{}) (interface{}, bool) {
+	entries := asMap(examples)
+	for _, name := range sortedKeys(entries) {
+		if value, ok := asMap(s.resolve(entries[name]))["value"]; ok {
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+// schemaExample returns the schema's own example, or builds one from its
+// properties
+func (s *Spec) schemaExample(schema interface{}) (interface{}, bool) {
+	if schema == nil {
+		return nil, false
+	}
+	example := s.buildExample(schema, nil)
+	return example, example != nil
+}
+
+// buildExample builds an example value for a schema. Explicit examples,
+// defaults and enum values are preferred over placeholders by type. refs
+// holds the references being expanded, so that recursive schemas end.
+func (s *Spec) buildExample(raw interface{}, refs []string) interface{} {
+	if ref, ok := asMap(raw)["$ref"].(string); ok {
+		if slices.Contains(refs, ref) || len(refs) >= maxDepth {
+			return nil
+		}
+		refs = slices.Concat(refs, []string{ref})
+	}
+	schema := asMap(s.resolve(raw))
+	if schema == nil {
+		return nil
+	}
+	if example, ok := explicitExample(schema); ok {
+		return example
+	}
+	if all := asList(schema["allOf"]); all != nil {
+		merged := make(map[string]interface{})
+		for _, part := range all {
+			for key, value := range asMap(s.buildExample(part, refs)) {
+				merged[key] = value
+			}
+		}
+		return merged
+	}
+	for _, key := range []string{"oneOf", "anyOf"} {
+		if choices := asList(schema[key]); len(choices) > 0 {
+			return s.buildExample(choices[0], refs)
+		}
+	}
+
+	switch schemaType(schema) {
+	case "object":
+		example := make(map[string]interface{})
+		properties := asMap(schema["properties"])
+		for _, name := range sortedKeys(properties) {
+			if asMap(s.resolve(properties[name]))["readOnly"] == true {
+				continue
+			}
+			if value := s.buildExample(properties[name], refs); value != nil {
+				example[name] = value
+			}
+		}
+		return example
+	case "array":
+		if item := s.buildExample(schema["items"], refs); item != nil {
+			return []interface{}{item}
+		}
+		return []interface{}{}
+	case "string":
+		switch schema["format"] {
+		case "date-time":
+			return "2025-01-01T00:00:00Z"
+		case "date":
+			return "2025-01-01"
+		case "uuid":
+			return "00000000-0000-0000-0000-000000000000"
+		case "email":
+			return "user@example.com"
+		case "uri", "url":
+			return "https://example.com"
+		}
+		return "string"
+	case "integer", "number":
+		return 0
+	case "boolean":
+		return false
+	}
+	return nil
+}
+
+// explicitExample returns the value a schema gives as its example, default
+// or first allowed value
+func explicitExample(schema map[string]interface{}) (interface{}, bool) {
+	if example, ok := schema["example"]; ok {
+		return example, true
+	}
+	if examples := asList(schema["examples"]); len(examples) > 0 {
+		return examples[0], true
+	}
+	if value, ok := schema["default"]; ok {
+		return value, true
+	}
+	if values := asList(schema["enum"]); len(values) > 0 {
+		return values[0], true
+	}
+	return nil, false
+}
+
+// schemaType returns a schema's type. An OpenAPI 3.1 type list yields its
+// first non-null type, and a schema with properties is an object.
+func schemaType(schema map[string]interface{}) string {
+	switch t := schema["type"].(type) {
+	case string:
+		return t
+	case []interface{}:
+		for _, item := range t {
+			if item != "null" {
+				return asString(item)
+			}
+		}
+	}
+	if schema["properties"] != nil {
+		return "object"
+	}
+	return ""
+}
+
+// resolve follows a local $ref, returning the referenced node. A reference
+// that cannot be followed resolves to nil.
+func (s *Spec) resolve(node interface{}) interface{} {
+	for range maxDepth {
+		ref, ok := asMap(node)["$ref"].(string)
+		if !ok {
+			return node
+		}
+		node = s.lookup(ref)
+	}
+	return nil
+}
+
+// lookup evaluates a local JSON pointer reference such as
+// "#/components/schemas/User"
+func (s *Spec) lookup(ref string) interface{} {
+	pointer, found := strings.CutPrefix(ref, "#/")
+	if !found {
+		return nil
+	}
+	var current interface{} = s.document
+	for _, token := range strings.Split(pointer, "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		switch node := current.(type) {
+		case map[string]interface{}:
+			current = node[token]
+		case []interface{}:
+			index, err := strconv.Atoi(token)
+			if err != nil || index < 0 || index >= len(node) {
+				return nil
+			}
+			current = node[index]
+		default:
+			return nil
+		}
+	}
+	return current
+}
+
+// asMap returns value as an object, or nil
+func asMap(value interface{}) map[string]interface{} {
+	if m, ok := value.(map[string]interface{}); ok {
+		return m
+	}
+	return nil
+}
+
+// asList returns value as an array, or nil
+func asList(value interface{}) []interface{} {
+	if list, ok := value.([]interface{}); ok {
+		return list
+	}
+	return nil
+}
+
+// asString returns value as a string, or ""
+func asString(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	return ""
+}
+
+// sortedKeys returns the keys of an object in sorted order
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
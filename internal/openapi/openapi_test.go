@@ -0,0 +1,188 @@
+package openapi
+
+import (
+	"reflect"
+	"testing"
+)
+
+const petstoreYAML = `
+openapi: 3.0.3
+info: {title: Pets, version: "1"}
+servers:
+  - url: https://{region}.pets.example.com/v1/
+    variables:
+      region: {default: eu}
+paths:
+  /pets:
+    get:
+      operationId: listPets
+      parameters:
+        - {name: limit, in: query, required: true, schema: {type: integer, default: 10}}
+        - {name: cursor, in: query, schema: {type: string}}
+    post:
+      operationId: createPet
+      requestBody:
+        content:
+          application/json:
+            schema: {$ref: '#/components/schemas/NewPet'}
+  /pets/{petId}:
+    parameters:
+      - {name: petId, in: path, required: true, schema: {type: string}, example: rex}
+    get:
+      summary: Get a pet
+      responses:
+        200:
+          description: A pet
+    put:
+      operationId: updatePet
+      requestBody:
+        content:
+          application/merge-patch+json:
+            examples:
+              rename: {value: {name: Max}}
+    head:
+      operationId: petExists
+components:
+  schemas:
+    NewPet:
+      allOf:
+        - $ref: '#/components/schemas/Named'
+        - type: object
+          properties:
+            id: {type: string, format: uuid, readOnly: true}
+            born: {type: string, format: date}
+            tags: {type: array, items: {type: string, enum: [cat, dog]}}
+            owner: {$ref: '#/components/schemas/Owner'}
+    Named:
+      type: object
+      properties:
+        name: {type: string, example: Rex}
+    Owner:
+      type: object
+      properties:
+        friend: {$ref: '#/components/schemas/Owner'}
+`
+
+const swaggerJSON = `{
+  "swagger": "2.0",
+  "host": "api.example.com",
+  "basePath": "/v2",
+  "schemes": ["http"],
+  "paths": {
+    "/orders/{id}": {
+      "delete": {"parameters": [{"name": "id", "in": "path", "type": "integer", "x-example": 42}]}
+    },
+    "/orders": {
+      "post": {"parameters": [{"name": "order", "in": "body", "schema": {"$ref": "#/definitions/Order"}}]}
+    }
+  },
+  "definitions": {
+    "Order": {"properties": {"quantity": {"type": "integer"}, "paid": {"type": "boolean"}}}
+  }
+}`
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		baseURL string
+		wantErr bool
+	}{
+		{"OpenAPI 3 YAML", petstoreYAML, "https://eu.pets.example.com/v1", false},
+		{"Swagger 2.0 JSON", swaggerJSON, "http://api.example.com/v2", false},
+		{"not an API description", `{"endpoints": []}`, "", true},
+		{"not an object", `[1, 2]`, "", true},
+		{"invalid YAML", "paths: [", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec, err := Parse([]byte(tt.data))
+			if tt.wantErr {
+				if err == nil {
+					t.Error("Expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if got := spec.BaseURL(); got != tt.baseURL {
+				t.Errorf("Expected base URL %q, got %q", tt.baseURL, got)
+			}
+		})
+	}
+}
+
+func TestSpec_Operations(t *testing.T) {
+	spec, err := Parse([]byte(petstoreYAML))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, operation := range spec.Operations() {
+		got = append(got, operation.Method+" "+operation.Path+" "+operation.ID)
+	}
+	expected := []string{
+		"GET /pets listPets",
+		"POST /pets createPet",
+		"GET /pets/{petId} ",
+		"PUT /pets/{petId} updatePet",
+		"HEAD /pets/{petId} petExists",
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
+
+func TestSpec_RequestExample(t *testing.T) {
+	tests := []struct {
+		name      string
+		spec      string
+		operation int
+		expected  interface{}
+	}{
+		{
+			name:      "built from the schema",
+			spec:      petstoreYAML,
+			operation: 1,
+			expected: map[string]interface{}{
+				"name":  "Rex",
+				"born":  "2025-01-01",
+				"tags":  []interface{}{"cat"},
+				"owner": map[string]interface{}{},
+			},
+		},
+		{
+			name:      "named example",
+			spec:      petstoreYAML,
+			operation: 3,
+			expected:  map[string]interface{}{"name": "Max"},
+		},
+		{
+			name:      "no body",
+			spec:      petstoreYAML,
+			operation: 0,
+		},
+		{
+			name:      "Swagger body parameter",
+			spec:      swaggerJSON,
+			operation: 0,
+			expected:  map[string]interface{}{"quantity": 0, "paid": false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec, err := Parse([]byte(tt.spec))
+			if err != nil {
+				t.Fatal(err)
+			}
+			example, ok := spec.RequestExample(spec.Operations()[tt.operation])
+			if ok != (tt.expected != nil) || !reflect.DeepEqual(example, tt.expected) {
+				t.Errorf("Expected %v, got %v (%v)", tt.expected, example, ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,417 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxViolations caps how many violations a single response reports
+const maxViolations = 20
+
+// uuidPattern matches the uuid string format
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// FindOperation returns the operation with the given ID or, without one, the
+// operation whose method and path template match the request URL. The
+// request path may include the base path of the description's server.
+func (s *Spec) FindOperation(method, requestURL, operationID string) (Operation, error) {
+	operations := s.Operations()
+	if operationID != "" {
+		for _, operation := range operations {
+			if operation.ID == operationID {
+				return operation, nil
+			}
+		}
+		return Operation{}, fmt.Errorf("no operation with operationId %q", operationID)
+	}
+
+	parsed, err := url.Parse(requestURL)
+	if err != nil {
+		return Operation{}, fmt.Errorf("invalid request URL: %w", err)
+	}
+	candidates := []string{parsed.Path}
+	if base, err := url.Parse(s.BaseURL()); err == nil && base.Path != "" {
+		if trimmed, found := strings.CutPrefix(parsed.Path, strings.TrimSuffix(base.Path, "/")); found {
+			candidates = append([]string{trimmed}, candidates...)
+		}
+	}
+
+	// Literal segments take precedence over templated ones, so /users/me is
+	// preferred to /users/{id}
+	best, bestParams := -1, 0
+	for _, path := range candidates {
+		for i, operation := range operations {
+			if operation.Method != strings.ToUpper(method) {
+				continue
+			}
+			if params, ok := matchPath(operation.Path, path); ok && (best < 0 || params < bestParams) {
+				best, bestParams = i, params
+			}
+		}
+		if best >= 0 {
+			return operations[best], nil
+		}
+	}
+	return Operation{}, fmt.Errorf("no operation matches %s %s", strings.ToUpper(method), parsed.Path)
+}
+
+// matchPath reports whether a request path matches a path template, and how
+// many template parameters it matched
+func matchPath(template, path string) (int, bool) {
+	templateSegments := strings.Split(strings.Trim(template, "/"), "/")
+	pathSegments := strings.Split(strings.Trim(path, "/"), "/")
+	if len(templateSegments) != len(pathSegments) {
+		return 0, false
+	}
+	params := 0
+	for i, segment := range templateSegments {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			if pathSegments[i] == "" {
+				return 0, false
+			}
+			params++
+			continue
+		}
+		if segment != pathSegments[i] {
+			return 0, false
+		}
+	}
+	return params, true
+}
+
+// ValidateResponse checks a response against what the operation declares
+// for its status code: the status must be documented and a JSON body must
+// match the declared schema. Responses to HEAD have no body, so only their
+// status is checked. It returns the contract violations.
+func (s *Spec) ValidateResponse(operation Operation, status int, contentType string, body []byte) []string {
+	label := operation.ID
+	if label == "" {
+		label = operation.Method + " " + operation.Path
+	}
+	response := s.responseFor(operation, status)
+	if response == nil {
+		return []string{fmt.Sprintf("status %d is not a documented response of %s", status, label)}
+	}
+	if operation.Method == "HEAD" {
+		return nil
+	}
+
+	schema := response["schema"]
+	if content := asMap(response["content"]); content != nil {
+		schema = s.jsonMedia(content)["schema"]
+	}
+	if schema == nil {
+		return nil
+	}
+
+	if len(bytes.TrimSpace(body)) == 0 {
+		return []string{fmt.Sprintf("expected a JSON body for status %d, got an empty body", status)}
+	}
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil &&
+		mediaType != "application/json" && !strings.HasSuffix(mediaType, "+json") {
+		return []string{fmt.Sprintf("expected a JSON body for status %d, got Content-Type %s", status, contentType)}
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return []string{fmt.Sprintf("response body is not valid JSON: %v", err)}
+	}
+
+	v := &validator{spec: s}
+	v.validate(value, schema, "$", 0)
+	if len(v.violations) > maxViolations {
+		return append(v.violations[:maxViolations], fmt.Sprintf("... and %d more", len(v.violations)-maxViolations))
+	}
+	return v.violations
+}
+
+// responseFor returns the response object for a status code: the exact
+// code, then its range (e.g. 2XX), then the default response
+func (s *Spec) responseFor(operation Operation, status int) map[string]interface{} {
+	responses := asMap(operation.node["responses"])
+	code := strconv.Itoa(status)
+	for _, key := range []string{code, code[:1] + "XX", code[:1] + "xx", "default"} {
+		if response, ok := responses[key]; ok {
+			return asMap(s.resolve(response))
+		}
+	}
+	return nil
+}
+
+// validator collects the violations of a value against a schema
+type validator struct {
+	spec       *Spec
+	violations []string
+}
+
+// violation records a violation at path
+func (v *validator) violation(path, format string, args ...interface{}) {
+	v.violations = append(v.violations, path+": "+fmt.Sprintf(format, args...))
+}
+
+// passes reports whether value matches schema without recording violations
+func (v *validator) passes(value, schema interface{}, depth int) bool {
+	trial := &validator{spec: v.spec}
+	trial.validate(value, schema, "$", depth)
+	return len(trial.violations) == 0
+}
+
+// validate checks value, found at path, against a schema. It supports the
+// JSON Schema keywords API descriptions commonly use: type, nullable, enum,
+// properties, required, additionalProperties, items, allOf, anyOf, oneOf,
+// length and range limits, pattern and the date-time, date and uuid formats.
+func (v *validator) validate(value, raw interface{}, path string, depth int) {
+	if depth > maxDepth*4 {
+		return
+	}
+	// A null may be allowed next to a $ref, as OpenAPI 3.1 permits
+	if value == nil && allowsNull(asMap(raw)) {
+		return
+	}
+	schema := asMap(v.spec.resolve(raw))
+	if schema == nil {
+		return
+	}
+	if value == nil && allowsNull(schema) {
+		return
+	}
+
+	for _, part := range asList(schema["allOf"]) {
+		v.validate(value, part, path, depth+1)
+	}
+	if choices := asList(schema["anyOf"]); len(choices) > 0 {
+		matched := false
+		for _, choice := range choices {
+			if v.passes(value, choice, depth+1) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			v.violation(path, "does not match any of the anyOf schemas")
+		}
+	}
+	if choices := asList(schema["oneOf"]); len(choices) > 0 {
+		matched := 0
+		for _, choice := range choices {
+			if v.passes(value, choice, depth+1) {
+				matched++
+			}
+		}
+		if matched != 1 {
+			v.violation(path, "matches %d of the oneOf schemas, expected exactly 1", matched)
+		}
+	}
+
+	if values := asList(schema["enum"]); len(values) > 0 && !containsJSON(values, value) {
+		v.violation(path, "%s is not one of the allowed values", describe(value))
+	}
+
+	types := schemaTypes(schema)
+	if len(types) > 0 {
+		actual := jsonType(value)
+		if !typeMatches(types, actual) {
+			v.violation(path, "expected %s, got %s", strings.Join(types, " or "), actual)
+			return
+		}
+	}
+
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		v.validateObject(typed, schema, path, depth)
+	case []interface{}:
+		v.validateArray(typed, schema, path, depth)
+	case string:
+		v.validateString(typed, schema, path)
+	case json.Number:
+		v.validateNumber(typed, schema, path)
+	}
+}
+
+// validateObject checks required, declared and additional properties
+func (v *validator) validateObject(object map[string]interface{}, schema map[string]interface{}, path string, depth int) {
+	properties := asMap(schema["properties"])
+	for _, name := range asList(schema["required"]) {
+		if _, ok := object[asString(name)]; !ok {
+			v.violation(path, "missing required property %q", asString(name))
+		}
+	}
+	for _, name := range sortedKeys(object) {
+		childPath := path + "." + name
+		if property, ok := properties[name]; ok {
+			v.validate(object[name], property, childPath, depth+1)
+			continue
+		}
+		switch additional := schema["additionalProperties"].(type) {
+		case bool:
+			if !additional {
+				v.violation(path, "unexpected property %q", name)
+			}
+		case map[string]interface{}:
+			v.validate(object[name], additional, childPath, depth+1)
+		}
+	}
+}
+
+// validateArray checks the items and the item count
+func (v *validator) validateArray(array []interface{}, schema map[string]interface{}, path string, depth int) {
+	if limit, ok := number(schema["minItems"]); ok && float64(len(array)) < limit {
+		v.violation(path, "has %d items, expected at least %v", len(array), limit)
+	}
+	if limit, ok := number(schema["maxItems"]); ok && float64(len(array)) > limit {
+		v.violation(path, "has %d items, expected at most %v", len(array), limit)
+	}
+	if items := schema["items"]; items != nil {
+		for i, item := range array {
+			v.validate(item, items, fmt.Sprintf("%s[%d]", path, i), depth+1)
+		}
+	}
+}
+
+// validateString checks the length, pattern and format of a string
+func (v *validator) validateString(value string, schema map[string]interface{}, path string) {
+	length := len([]rune(value))
+	if limit, ok := number(schema["minLength"]); ok && float64(length) < limit {
+		v.violation(path, "is %d characters long, expected at least %v", length, limit)
+	}
+	if limit, ok := number(schema["maxLength"]); ok && float64(length) > limit {
+		v.violation(path, "is %d characters long, expected at most %v", length, limit)
+	}
+	if pattern := asString(schema["pattern"]); pattern != "" {
+		if re, err := regexp.Compile(pattern); err == nil && !re.MatchString(value) {
+			v.violation(path, "%q does not match the pattern %s", value, pattern)
+		}
+	}
+
+	var err error
+	switch schema["format"] {
+	case "date-time":
+		_, err = time.Parse(time.RFC3339, value)
+	case "date":
+		_, err = time.Parse(time.DateOnly, value)
+	case "uuid":
+		if !uuidPattern.MatchString(value) {
+			err = fmt.Errorf("not a UUID")
+		}
+	}
+	if err != nil {
+		v.violation(path, "%q is not a valid %s", value, schema["format"])
+	}
+}
+
+// validateNumber checks the range of a number
+func (v *validator) validateNumber(value json.Number, schema map[string]interface{}, path string) {
+	actual, err := value.Float64()
+	if err != nil {
+		return
+	}
+	if limit, ok := number(schema["minimum"]); ok && actual < limit {
+		v.violation(path, "%s is less than the minimum %v", value, limit)
+	}
+	if limit, ok := number(schema["maximum"]); ok && actual > limit {
+		v.violation(path, "%s is greater than the maximum %v", value, limit)
+	}
+}
+
+// schemaTypes returns the types a schema allows, other than null
+func schemaTypes(schema map[string]interface{}) []string {
+	var types []string
+	switch t := schema["type"].(type) {
+	case string:
+		types = append(types, t)
+	case []interface{}:
+		for _, item := range t {
+			if name := asString(item); name != "null" {
+				types = append(types, name)
+			}
+		}
+	}
+	return types
+}
+
+// allowsNull reports whether a schema is nullable (OpenAPI 3.0) or its type
+// list includes null (OpenAPI 3.1)
+func allowsNull(schema map[string]interface{}) bool {
+	return schema["nullable"] == true || slices.Contains(asList(schema["type"]), interface{}("null"))
+}
+
+// typeMatches reports whether a JSON value of the actual type satisfies one
+// of the schema types. An integer is also a number.
+func typeMatches(types []string, actual string) bool {
+	for _, expected := range types {
+		if expected == actual || (expected == "number" && actual == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonType returns the JSON Schema type of a decoded value
+func jsonType(value interface{}) string {
+	switch typed := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case json.Number:
+		if _, err := typed.Int64(); err == nil {
+			return "integer"
+		}
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// containsJSON reports whether values contains value, comparing their JSON
+// encodings so that YAML and JSON numbers compare equal
+func containsJSON(values []interface{}, value interface{}) bool {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return false
+	}
+	for _, candidate := range values {
+		if candidateJSON, err := json.Marshal(candidate); err == nil && bytes.Equal(candidateJSON, encoded) {
+			return true
+		}
+	}
+	return false
+}
+
+// describe formats a value for a violation message
+func describe(value interface{}) string {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(encoded)
+}
+
+// number returns a numeric schema keyword as a float64
+func number(value interface{}) (float64, bool) {
+	switch typed := value.(type) {
+	case int:
+		return float64(typed), true
+	case float64:
+		return typed, true
+	case json.Number:
+		f, err := typed.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
@@ -0,0 +1,226 @@
+package openapi
+
+import (
+	"reflect"
+	"testing"
+)
+
+const usersYAML = `
+openapi: 3.1.0
+servers: [{url: "https://api.example.com/v1"}]
+paths:
+  /users/{id}:
+    get:
+      operationId: getUser
+      responses:
+        "200":
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/User'}
+        4XX:
+          content:
+            application/problem+json:
+              schema:
+                type: object
+                required: [title]
+                properties: {title: {type: string}}
+    head:
+      operationId: userExists
+      responses:
+        "200":
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/User'}
+  /users/me:
+    get:
+      operationId: getMe
+      responses:
+        default:
+          description: Anything
+  /users:
+    post:
+      operationId: createUser
+      responses:
+        "201":
+          content:
+            text/plain: {schema: {type: string}}
+components:
+  schemas:
+    User:
+      type: object
+      additionalProperties: false
+      required: [id, name, role]
+      properties:
+        id: {type: string, format: uuid}
+        name: {type: string, minLength: 1, maxLength: 10}
+        role: {type: string, enum: [admin, reader]}
+        age: {type: integer, minimum: 0}
+        manager: {type: [object, "null"], $ref: '#/components/schemas/User'}
+        tags: {type: array, maxItems: 2, items: {type: string, pattern: "^[a-z]+$"}}
+        created: {type: string, format: date-time}
+        contact:
+          oneOf:
+            - {type: object, required: [email], properties: {email: {type: string}}}
+            - {type: object, required: [phone], properties: {phone: {type: string}}}
+`
+
+func TestSpec_FindOperation(t *testing.T) {
+	spec, err := Parse([]byte(usersYAML))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		method      string
+		url         string
+		operationID string
+		expected    string
+		wantErr     bool
+	}{
+		{"by operationId", "GET", "https://other.example.com/anything", "createUser", "createUser", false},
+		{"unknown operationId", "GET", "", "deleteUser", "", true},
+		{"templated path under the base path", "GET", "https://api.example.com/v1/users/42?expand=true", "", "getUser", false},
+		{"literal segments win", "GET", "https://api.example.com/v1/users/me", "", "getMe", false},
+		{"path without the base path", "post", "https://gateway.example.com/users", "", "createUser", false},
+		{"method must match", "DELETE", "https://api.example.com/v1/users/42", "", "", true},
+		{"no match", "GET", "https://api.example.com/v1/orders", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			operation, err := spec.FindOperation(tt.method, tt.url, tt.operationID)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected an error, got %s", operation.ID)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("FindOperation() error = %v", err)
+			}
+			if operation.ID != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, operation.ID)
+			}
+		})
+	}
+}
+
+func TestSpec_ValidateResponse(t *testing.T) {
+	spec, err := Parse([]byte(usersYAML))
+	if err != nil {
+		t.Fatal(err)
+	}
+	operations := map[string]Operation{}
+	for _, operation := range spec.Operations() {
+		operations[operation.ID] = operation
+	}
+
+	tests := []struct {
+		name        string
+		operation   string
+		status      int
+		contentType string
+		body        string
+		expected    []string
+	}{
+		{
+			name:        "valid",
+			operation:   "getUser",
+			status:      200,
+			contentType: "application/json; charset=utf-8",
+			body: `{"id": "3fa85f64-5717-4562-b3fc-2c963f66afa6", "name": "Ada", "role": "admin", "age": 36,
+				"manager": null, "tags": ["a"], "created": "2025-01-02T03:04:05Z", "contact": {"email": "ada@example.com"}}`,
+		},
+		{
+			name:        "violations",
+			operation:   "getUser",
+			status:      200,
+			contentType: "application/json",
+			body: `{"id": "42", "name": "", "role": "owner", "age": -1.5, "extra": true,
+				"manager": {"id": "3fa85f64-5717-4562-b3fc-2c963f66afa6", "name": 7, "role": "reader"},
+				"tags": ["a", "B", "c"], "created": "yesterday", "contact": {"email": "x", "phone": "y"}}`,
+			expected: []string{
+				`$.age: expected integer, got number`,
+				`$.contact: matches 2 of the oneOf schemas, expected exactly 1`,
+				`$.created: "yesterday" is not a valid date-time`,
+				`$: unexpected property "extra"`,
+				`$.id: "42" is not a valid uuid`,
+				`$.manager.name: expected string, got integer`,
+				`$.name: is 0 characters long, expected at least 1`,
+				`$.role: "owner" is not one of the allowed values`,
+				`$.tags: has 3 items, expected at most 2`,
+				`$.tags[1]: "B" does not match the pattern ^[a-z]+$`,
+			},
+		},
+		{
+			name:        "missing required property",
+			operation:   "getUser",
+			status:      200,
+			contentType: "application/json",
+			body:        `{"id": "3fa85f64-5717-4562-b3fc-2c963f66afa6", "name": "Ada"}`,
+			expected:    []string{`$: missing required property "role"`},
+		},
+		{
+			name:        "status range",
+			operation:   "getUser",
+			status:      404,
+			contentType: "application/problem+json",
+			body:        `{"status": 404}`,
+			expected:    []string{`$: missing required property "title"`},
+		},
+		{
+			name:      "undocumented status",
+			operation: "getUser",
+			status:    500,
+			body:      `{}`,
+			expected:  []string{"status 500 is not a documented response of getUser"},
+		},
+		{
+			name:        "not JSON",
+			operation:   "getUser",
+			status:      200,
+			contentType: "text/html",
+			body:        `<html></html>`,
+			expected:    []string{"expected a JSON body for status 200, got Content-Type text/html"},
+		},
+		{
+			name:      "empty body",
+			operation: "getUser",
+			status:    200,
+			expected:  []string{"expected a JSON body for status 200, got an empty body"},
+		},
+		{
+			name:      "HEAD without a body",
+			operation: "userExists",
+			status:    200,
+		},
+		{
+			name:      "HEAD with an undocumented status",
+			operation: "userExists",
+			status:    404,
+			expected:  []string{"status 404 is not a documented response of userExists"},
+		},
+		{
+			name:      "default response without a schema",
+			operation: "getMe",
+			status:    503,
+			body:      `anything`,
+		},
+		{
+			name:      "no JSON content declared",
+			operation: "createUser",
+			status:    201,
+			body:      `created`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			violations := spec.ValidateResponse(operations[tt.operation], tt.status, tt.contentType, []byte(tt.body))
+			if !reflect.DeepEqual(violations, tt.expected) {
+				t.Errorf("Expected violations:\n%q\ngot:\n%q", tt.expected, violations)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+// Package probe reports the outcome of the latest scheduled runs on a
+// /healthz endpoint, so that Kubernetes liveness and readiness probes, or
+// alerts built on them, can consume the health of the tested APIs.
+package probe
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/hutstep/entra-id-api-tester/internal/report"
+)
+
+// Health statuses
+const (
+	StatusPass    = "pass"
+	StatusFail    = "fail"
+	StatusPending = "pending"
+)
+
+// Health is the body of a /healthz response
+type Health struct {
+	// CheckedAt is when the latest run finished
+	CheckedAt *time.Time `json:"checkedAt,omitempty"`
+	Status    string     `json:"status"`
+	// Failed names the endpoints that failed their latest run
+	Failed []string `json:"failed,omitempty"`
+	// Errors are runs that could not complete, by group
+	Errors map[string]string `json:"errors,omitempty"`
+}
+
+// outcome is the latest run of a group
+type outcome struct {
+	finishedAt time.Time
+	err        error
+	failed     []string
+}
+
+// Tracker records the latest run of each group of endpoints. It is pending
+// until every group ran once, and passes while all latest runs passed.
+type Tracker struct {
+	outcomes map[string]outcome
+	groups   []string
+	mu       sync.Mutex
+}
+
+// NewTracker creates a tracker for the named groups, e.g. schedules
+func NewTracker(groups []string) *Tracker {
+	return &Tracker{groups: groups, outcomes: make(map[string]outcome, len(groups))}
+}
+
+// Record records the run of a group: its report, or the error that kept it
+// from completing
+func (t *Tracker) Record(group string, runReport *report.Report, err error) {
+	recorded := outcome{finishedAt: time.Now(), err: err}
+	if runReport != nil {
+		recorded.finishedAt = runReport.FinishedAt
+		for _, endpoint := range runReport.Endpoints {
+			if endpoint.Status == report.StatusFailed {
+				recorded.failed = append(recorded.failed, endpoint.Name)
+			}
+		}
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.outcomes[group] = recorded
+}
+
+// Health summarizes the latest runs
+func (t *Tracker) Health() Health {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	health := Health{Status: StatusPass}
+	for _, group := range t.groups {
+		recorded, ok := t.outcomes[group]
+		if !ok {
+			health.Status = StatusPending
+			continue
+		}
+		if health.CheckedAt == nil || recorded.finishedAt.After(*health.CheckedAt) {
+			finishedAt := recorded.finishedAt.UTC()
+			health.CheckedAt = &finishedAt
+		}
+		health.Failed = append(health.Failed, recorded.failed...)
+		if recorded.err != nil {
+			if health.Errors == nil {
+				health.Errors = make(map[string]string)
+			}
+			health.Errors[group] = recorded.err.Error()
+		}
+	}
+	slices.Sort(health.Failed)
+	if len(health.Failed) > 0 || len(health.Errors) > 0 {
+		health.Status = StatusFail
+	}
+	return health
+}
+
+// ServeHTTP responds 200 while the latest runs passed, and 503 while they
+// are pending or any failed
+func (t *Tracker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	health := t.Health()
+	status := http.StatusOK
+	if health.Status != StatusPass {
+		status = http.StatusServiceUnavailable
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(health)
+}
@@ -0,0 +1,129 @@
+package probe
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hutstep/entra-id-api-tester/internal/report"
+)
+
+func runReport(finishedAt time.Time, statuses map[string]string) *report.Report {
+	runReport := &report.Report{FinishedAt: finishedAt}
+	for name, status := range statuses {
+		runReport.Endpoints = append(runReport.Endpoints, report.Endpoint{Name: name, Status: status})
+	}
+	return runReport
+}
+
+func TestTracker_Health(t *testing.T) {
+	first := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	second := first.Add(time.Minute)
+	tests := []struct {
+		name     string
+		record   func(tracker *Tracker)
+		status   string
+		failed   []string
+		errors   int
+		httpCode int
+	}{
+		{
+			name:     "pending",
+			record:   func(tracker *Tracker) {},
+			status:   StatusPending,
+			httpCode: http.StatusServiceUnavailable,
+		},
+		{
+			name: "one group pending",
+			record: func(tracker *Tracker) {
+				tracker.Record("fast", runReport(first, map[string]string{"users": report.StatusPassed}), nil)
+			},
+			status:   StatusPending,
+			httpCode: http.StatusServiceUnavailable,
+		},
+		{
+			name: "all passed",
+			record: func(tracker *Tracker) {
+				tracker.Record("fast", runReport(first, map[string]string{"users": report.StatusPassed}), nil)
+				tracker.Record("slow", runReport(second, map[string]string{"orders": report.StatusSkipped}), nil)
+			},
+			status:   StatusPass,
+			httpCode: http.StatusOK,
+		},
+		{
+			name: "endpoint failed",
+			record: func(tracker *Tracker) {
+				tracker.Record("fast", runReport(first, map[string]string{"users": report.StatusFailed, "items": report.StatusFailed}), nil)
+				tracker.Record("slow", runReport(second, map[string]string{"orders": report.StatusPassed}), nil)
+			},
+			status:   StatusFail,
+			failed:   []string{"items", "users"},
+			httpCode: http.StatusServiceUnavailable,
+		},
+		{
+			name: "recovered",
+			record: func(tracker *Tracker) {
+				tracker.Record("fast", runReport(first, map[string]string{"users": report.StatusFailed}), nil)
+				tracker.Record("fast", runReport(second, map[string]string{"users": report.StatusPassed}), nil)
+				tracker.Record("slow", runReport(second, map[string]string{"orders": report.StatusPassed}), nil)
+			},
+			status:   StatusPass,
+			httpCode: http.StatusOK,
+		},
+		{
+			name: "run error",
+			record: func(tracker *Tracker) {
+				tracker.Record("fast", nil, errors.New("failed to configure token acquisition"))
+				tracker.Record("slow", runReport(second, map[string]string{"orders": report.StatusPassed}), nil)
+			},
+			status:   StatusFail,
+			errors:   1,
+			httpCode: http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := NewTracker([]string{"fast", "slow"})
+			tt.record(tracker)
+
+			recorder := httptest.NewRecorder()
+			tracker.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+			if recorder.Code != tt.httpCode {
+				t.Errorf("Expected HTTP %d, got %d", tt.httpCode, recorder.Code)
+			}
+			var health Health
+			if err := json.Unmarshal(recorder.Body.Bytes(), &health); err != nil {
+				t.Fatalf("Failed to decode health: %v", err)
+			}
+			if health.Status != tt.status {
+				t.Errorf("Expected status %s, got %s", tt.status, health.Status)
+			}
+			if len(health.Failed) != len(tt.failed) {
+				t.Fatalf("Expected failed %v, got %v", tt.failed, health.Failed)
+			}
+			for i := range tt.failed {
+				if health.Failed[i] != tt.failed[i] {
+					t.Errorf("Expected failed %v, got %v", tt.failed, health.Failed)
+				}
+			}
+			if len(health.Errors) != tt.errors {
+				t.Errorf("Expected %d errors, got %v", tt.errors, health.Errors)
+			}
+		})
+	}
+}
+
+func TestTracker_CheckedAt(t *testing.T) {
+	tracker := NewTracker([]string{"fast", "slow"})
+	latest := time.Date(2026, 1, 1, 10, 5, 0, 0, time.UTC)
+	tracker.Record("slow", runReport(latest, nil), nil)
+	tracker.Record("fast", runReport(latest.Add(-time.Minute), nil), nil)
+
+	if health := tracker.Health(); health.CheckedAt == nil || !health.CheckedAt.Equal(latest) {
+		t.Errorf("Expected the latest finish %v, got %v", latest, health.CheckedAt)
+	}
+}
@@ -0,0 +1,72 @@
+package redact
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+// handler redacts the message and attributes of every record before
+// passing it on
+type handler struct {
+	next slog.Handler
+}
+
+// Handler wraps next so that no log record can carry a credential
+func Handler(next slog.Handler) slog.Handler {
+	return handler{next: next}
+}
+
+// Enabled reports whether the wrapped handler handles records at level
+func (h handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+// Handle redacts the record and passes it to the wrapped handler
+func (h handler) Handle(ctx context.Context, record slog.Record) error {
+	redacted := slog.NewRecord(record.Time, record.Level, String(record.Message), record.PC)
+	record.Attrs(func(a slog.Attr) bool {
+		redacted.AddAttrs(attr(a))
+		return true
+	})
+	return h.next.Handle(ctx, redacted)
+}
+
+// WithAttrs returns a handler with redacted attributes added
+func (h handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		redacted[i] = attr(a)
+	}
+	return handler{next: h.next.WithAttrs(redacted)}
+}
+
+// WithGroup returns a handler that qualifies later attributes with name
+func (h handler) WithGroup(name string) slog.Handler {
+	return handler{next: h.next.WithGroup(name)}
+}
+
+// attr redacts string, error and Stringer values, including those in
+// groups; other kinds cannot carry a credential and are kept as they are
+func attr(a slog.Attr) slog.Attr {
+	value := a.Value.Resolve()
+	switch value.Kind() {
+	case slog.KindString:
+		return slog.String(a.Key, String(value.String()))
+	case slog.KindGroup:
+		group := value.Group()
+		redacted := make([]slog.Attr, len(group))
+		for i, member := range group {
+			redacted[i] = attr(member)
+		}
+		return slog.Attr{Key: a.Key, Value: slog.GroupValue(redacted...)}
+	case slog.KindAny:
+		switch v := value.Any().(type) {
+		case error:
+			return slog.String(a.Key, String(v.Error()))
+		case fmt.Stringer:
+			return slog.String(a.Key, String(v.String()))
+		}
+	}
+	return slog.Attr{Key: a.Key, Value: value}
+}
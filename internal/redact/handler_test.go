@@ -0,0 +1,34 @@
+package redact
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestHandler(t *testing.T) {
+	const secret = "handler-secret-0123456789"
+	Register(secret)
+
+	var buffer bytes.Buffer
+	logger := slog.New(Handler(slog.NewJSONHandler(&buffer, nil)))
+
+	logger.With("client", secret).WithGroup("request").Info("sending "+secret,
+		"body", `{"token": "`+secret+`"}`,
+		"err", errors.New("rejected "+secret),
+		slog.Group("auth", "header", "Bearer "+secret),
+		"status", 401,
+	)
+
+	output := buffer.String()
+	if strings.Contains(output, secret) {
+		t.Fatalf("Expected the secret to be redacted, got %s", output)
+	}
+	for _, want := range []string{`"msg":"sending han***6789"`, `"client":"han***6789"`, `"err":"rejected han***6789"`, `"header":"Bearer han***6789"`, `"status":401`} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected output to contain %s, got %s", want, output)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+// Package redact keeps credentials out of everything the tester writes:
+// console output, logs, HAR files and reports. Known secrets (client secrets
+// from the configuration and acquired access tokens) are registered once and
+// replaced wherever they appear; bearer tokens, JWTs and the signatures of
+// Azure Storage SAS URLs are recognized even when they were never
+// registered. Each credential is replaced with a
+// fingerprint such as "sk-***1234", so two outputs can still be correlated
+// without revealing the value.
+package redact
+
+import (
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Mask replaces a credential too short to fingerprint safely
+const Mask = "***"
+
+// fingerprintLength is the shortest credential whose first three and last
+// four characters are shown; shorter ones are masked entirely
+const fingerprintLength = 16
+
+// minSecretLength is the shortest value Register accepts, so that a short
+// placeholder secret does not mask unrelated text
+const minSecretLength = 8
+
+var (
+	// bearerPattern matches a bearer credential, e.g. in an Authorization
+	// header echoed into an error message
+	bearerPattern = regexp.MustCompile(`(?i)\b(bearer)\s+([A-Za-z0-9._~+/-]{16,}=*)`)
+	// jwtPattern matches a JSON Web Token such as an Entra ID access token
+	jwtPattern = regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`)
+	// sasSignaturePattern matches the signature of a shared access
+	// signature URL, e.g. the Location of a redirect to a storage blob
+	sasSignaturePattern = regexp.MustCompile(`([?&]sig=)([^&#\s"']+)`)
+)
+
+// sensitiveHeaders carry credentials and are never written in clear text
+var sensitiveHeaders = map[string]bool{
+	"Authorization":             true,
+	"Cookie":                    true,
+	"Set-Cookie":                true,
+	"Ocp-Apim-Subscription-Key": true,
+	"X-Api-Key":                 true,
+}
+
+// registry holds the secrets registered so far, longest first so that a
+// secret containing another one is replaced as a whole
+var registry struct {
+	secrets []string
+	mu      sync.RWMutex
+}
+
+// Register adds secrets that must never be written. Empty and very short
+// values are ignored.
+func Register(secrets ...string) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	for _, secret := range secrets {
+		if len(secret) < minSecretLength || contains(registry.secrets, secret) {
+			continue
+		}
+		registry.secrets = append(registry.secrets, secret)
+	}
+	sort.SliceStable(registry.secrets, func(i, j int) bool {
+		return len(registry.secrets[i]) > len(registry.secrets[j])
+	})
+}
+
+// contains reports whether secrets holds secret
+func contains(secrets []string, secret string) bool {
+	for _, registered := range secrets {
+		if registered == secret {
+			return true
+		}
+	}
+	return false
+}
+
+// Fingerprint returns a recognizable but non-secret stand-in for a
+// credential: its first three and last four characters, e.g. "sk-***1234"
+func Fingerprint(secret string) string {
+	if len(secret) < fingerprintLength {
+		return Mask
+	}
+	return secret[:3] + Mask + secret[len(secret)-4:]
+}
+
+// String replaces every registered secret, bearer token, JWT and SAS
+// signature in s with its fingerprint
+func String(s string) string {
+	if s == "" {
+		return s
+	}
+
+	registry.mu.RLock()
+	for _, secret := range registry.secrets {
+		if strings.Contains(s, secret) {
+			s = strings.ReplaceAll(s, secret, Fingerprint(secret))
+		}
+	}
+	registry.mu.RUnlock()
+
+	s = jwtPattern.ReplaceAllStringFunc(s, Fingerprint)
+	s = sasSignaturePattern.ReplaceAllStringFunc(s, func(match string) string {
+		groups := sasSignaturePattern.FindStringSubmatch(match)
+		return groups[1] + Fingerprint(groups[2])
+	})
+	return bearerPattern.ReplaceAllStringFunc(s, func(match string) string {
+		groups := bearerPattern.FindStringSubmatch(match)
+		return groups[1] + " " + Fingerprint(groups[2])
+	})
+}
+
+// IsSensitiveHeader reports whether the named header carries a credential
+func IsSensitiveHeader(name string) bool {
+	return sensitiveHeaders[http.CanonicalHeaderKey(name)]
+}
+
+// Header returns a header value safe to write: sensitive headers keep only
+// their scheme (e.g. "Bearer") and the credential's fingerprint, and any
+// other header has known secrets replaced
+func Header(name, value string) string {
+	if !IsSensitiveHeader(name) {
+		return String(value)
+	}
+	if scheme, credential, found := strings.Cut(value, " "); found {
+		return scheme + " " + Fingerprint(credential)
+	}
+	return Fingerprint(value)
+}
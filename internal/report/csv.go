@@ -0,0 +1,56 @@
+package report
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// csvHeader names the columns of a CSV report
+var csvHeader = []string{"name", "url", "method", "status", "statusCode", "durationMs", "failedPhase", "error", "errorCode"}
+
+// WriteCSV writes the report as CSV with a header and one row per endpoint,
+// e.g. for spreadsheets
+func (r *Report) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return fmt.Errorf("failed to encode report: %w", err)
+	}
+	for i := range r.Endpoints {
+		endpoint := &r.Endpoints[i]
+		statusCode := ""
+		if endpoint.StatusCode > 0 {
+			statusCode = strconv.Itoa(endpoint.StatusCode)
+		}
+		row := []string{
+			csvText(endpoint.Name),
+			csvText(endpoint.URL),
+			endpoint.Method,
+			endpoint.Status,
+			statusCode,
+			strconv.FormatInt(endpoint.DurationMs, 10),
+			endpoint.FailedPhase,
+			csvText(endpoint.Error),
+			endpoint.ErrorCode,
+		}
+		if err := writer.Write(row); err != nil {
+			return fmt.Errorf("failed to encode report: %w", err)
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("failed to encode report: %w", err)
+	}
+	return nil
+}
+
+// csvText keeps spreadsheets from evaluating a value as a formula by
+// prefixing values that start with a formula character with a quote
+func csvText(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
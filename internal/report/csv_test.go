@@ -0,0 +1,62 @@
+package report
+
+import (
+	"encoding/csv"
+	"strings"
+	"testing"
+)
+
+func TestWriteCSV(t *testing.T) {
+	runReport := &Report{
+		Endpoints: []Endpoint{
+			{Name: "users", URL: "https://api.example.com/users", Method: "GET", Status: StatusPassed, StatusCode: 200, DurationMs: 150},
+			{Name: "orders, v2", URL: "https://api.example.com/orders", Method: "POST", Status: StatusFailed, FailedPhase: "response", StatusCode: 500, DurationMs: 80, ErrorCode: "Non2xx", Error: "Unexpected status code: 500\n\"boom\""},
+			{Name: "=HYPERLINK(\"x\")", URL: "https://api.example.com/items", Method: "GET", Status: StatusSkipped, ErrorCode: "Skipped", Error: "Skipped (dependency failed: orders, v2)"},
+		},
+	}
+
+	var buf strings.Builder
+	if err := runReport.WriteCSV(&buf); err != nil {
+		t.Fatalf("WriteCSV failed: %v", err)
+	}
+	rows, err := csv.NewReader(strings.NewReader(buf.String())).ReadAll()
+	if err != nil {
+		t.Fatalf("Report is not valid CSV: %v", err)
+	}
+
+	expected := [][]string{
+		{"name", "url", "method", "status", "statusCode", "durationMs", "failedPhase", "error", "errorCode"},
+		{"users", "https://api.example.com/users", "GET", "passed", "200", "150", "", "", ""},
+		{"orders, v2", "https://api.example.com/orders", "POST", "failed", "500", "80", "response", "Unexpected status code: 500\n\"boom\"", "Non2xx"},
+		{"'=HYPERLINK(\"x\")", "https://api.example.com/items", "GET", "skipped", "", "0", "", "Skipped (dependency failed: orders, v2)", "Skipped"},
+	}
+	if len(rows) != len(expected) {
+		t.Fatalf("Expected %d rows, got %d: %q", len(expected), len(rows), rows)
+	}
+	for i := range expected {
+		if strings.Join(rows[i], "|") != strings.Join(expected[i], "|") {
+			t.Errorf("Row %d: expected %q, got %q", i, expected[i], rows[i])
+		}
+	}
+}
+
+func TestCSVText(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected string
+	}{
+		{"", ""},
+		{"users", "users"},
+		{"=1+1", "'=1+1"},
+		{"+1", "'+1"},
+		{"-1", "'-1"},
+		{"@SUM(A1)", "'@SUM(A1)"},
+		{"a=b", "a=b"},
+	}
+
+	for _, tt := range tests {
+		if got := csvText(tt.value); got != tt.expected {
+			t.Errorf("csvText(%q) = %q, expected %q", tt.value, got, tt.expected)
+		}
+	}
+}
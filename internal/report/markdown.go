@@ -0,0 +1,199 @@
+package report
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/hutstep/entra-id-api-tester/internal/client"
+)
+
+// WriteMarkdown writes the report as Markdown for pull requests, wikis and
+// incident timelines: the summary, a table with one row per endpoint and a
+// collapsible section with the details of each failed endpoint
+func (r *Report) WriteMarkdown(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("# API Test Report\n\n")
+	result := "✅ passed"
+	switch {
+	case r.Aborted != "":
+		result = "⚠️ aborted: " + markdownText(r.Aborted)
+	case r.Summary.Failed > 0:
+		result = "❌ failed"
+	}
+	fmt.Fprintf(&b, "- **Result:** %s\n", result)
+	fmt.Fprintf(&b, "- **Started:** %s\n", r.StartedAt.Format(time.RFC3339))
+	fmt.Fprintf(&b, "- **Duration:** %v\n", r.FinishedAt.Sub(r.StartedAt).Round(time.Millisecond))
+	fmt.Fprintf(&b, "- **Version:** %s %s\n", r.Tool, r.Version)
+	fmt.Fprintf(&b, "- **Endpoints:** %d total, %d passed, %d failed, %d skipped\n",
+		r.Summary.Total, r.Summary.Passed, r.Summary.Failed, r.Summary.Skipped)
+
+	if len(r.Endpoints) > 0 {
+		b.WriteString("\n| Endpoint | Request | Result | Status code | Duration |\n")
+		b.WriteString("| --- | --- | --- | --- | --- |\n")
+		for i := range r.Endpoints {
+			endpoint := &r.Endpoints[i]
+			request := "-"
+			if endpoint.URL != "" {
+				request = "`" + strings.TrimSpace(endpoint.Method+" "+markdownCode(endpoint.URL)) + "`"
+			}
+			statusCode := "-"
+			if endpoint.StatusCode > 0 {
+				statusCode = fmt.Sprint(endpoint.StatusCode)
+			}
+			fmt.Fprintf(&b, "| %s | %s | %s | %s | %d ms |\n",
+				markdownText(endpoint.Name), request, markdownResult(endpoint), statusCode, endpoint.DurationMs)
+		}
+	}
+
+	writeMarkdownSecurity(&b, r.Endpoints)
+
+	if r.Summary.Failed > 0 {
+		b.WriteString("\n## Failures\n")
+		for i := range r.Endpoints {
+			if endpoint := &r.Endpoints[i]; endpoint.Status == StatusFailed {
+				writeMarkdownFailure(&b, endpoint)
+			}
+		}
+	}
+
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	return nil
+}
+
+// writeMarkdownFailure writes the details of a failed endpoint as a
+// collapsible section
+func writeMarkdownFailure(b *strings.Builder, endpoint *Endpoint) {
+	fmt.Fprintf(b, "\n<details>\n<summary><strong>%s</strong>: failed in %s</summary>\n\n",
+		html.EscapeString(endpoint.Name), html.EscapeString(endpoint.FailedPhase))
+	writeMarkdownBlock(b, endpoint.Error)
+	for _, assertion := range endpoint.Assertions {
+		if !assertion.Passed {
+			fmt.Fprintf(b, "- ❌ `%s`: %s\n", markdownCode(assertion.Description), markdownText(assertion.Error))
+		}
+	}
+	for _, cell := range endpoint.Matrix {
+		if !cell.Passed {
+			fmt.Fprintf(b, "- ❌ %s: %s\n", markdownText(cell.Label), markdownText(cell.Error))
+		}
+	}
+	for _, diagnosis := range endpoint.Diagnoses {
+		fmt.Fprintf(b, "- 💡 %s\n", markdownText(diagnosis))
+	}
+	for _, attempt := range endpoint.Attempts {
+		if attempt.Error != "" {
+			label := "Attempt"
+			if attempt.Label != "" {
+				label = markdownText(attempt.Label)
+			}
+			fmt.Fprintf(b, "- %s: %s\n", label, markdownText(attempt.Error))
+		}
+	}
+	if traceID := lastTraceID(endpoint.Attempts); traceID != "" {
+		fmt.Fprintf(b, "- Trace ID: `%s`\n", traceID)
+	}
+	requestIDs := lastRequestIDs(endpoint.Attempts)
+	for _, header := range client.RequestIDHeaders {
+		if value, ok := requestIDs[header]; ok {
+			fmt.Fprintf(b, "- %s: `%s`\n", header, markdownCode(value))
+		}
+	}
+	b.WriteString("\n</details>\n")
+}
+
+// writeMarkdownSecurity writes the security grade and findings of each
+// scanned endpoint as a table
+func writeMarkdownSecurity(b *strings.Builder, endpoints []Endpoint) {
+	header := false
+	for i := range endpoints {
+		endpoint := &endpoints[i]
+		if endpoint.Security == nil {
+			continue
+		}
+		if !header {
+			b.WriteString("\n## Security Headers\n\n| Endpoint | Grade | Findings |\n| --- | --- | --- |\n")
+			header = true
+		}
+		findings := make([]string, len(endpoint.Security.Findings))
+		for j, finding := range endpoint.Security.Findings {
+			findings[j] = markdownText(finding.Message)
+		}
+		if len(findings) == 0 {
+			findings = []string{"-"}
+		}
+		fmt.Fprintf(b, "| %s | %s | %s |\n", markdownText(endpoint.Name), endpoint.Security.Grade, strings.Join(findings, "<br>"))
+	}
+}
+
+// writeMarkdownBlock writes text as a fenced code block, with a fence
+// longer than any run of backticks in it
+func writeMarkdownBlock(b *strings.Builder, text string) {
+	if text == "" {
+		return
+	}
+	fence := "```"
+	for strings.Contains(text, fence) {
+		fence += "`"
+	}
+	fmt.Fprintf(b, "%stext\n%s\n%s\n", fence, strings.TrimRight(text, "\n"), fence)
+}
+
+// markdownResult renders the status of an endpoint for the table
+func markdownResult(endpoint *Endpoint) string {
+	var result string
+	switch endpoint.Status {
+	case StatusPassed:
+		result = "✅ passed"
+	case StatusSkipped:
+		result = "⏭️ skipped"
+	default:
+		result = "❌ failed"
+		if endpoint.FailedPhase != "" {
+			result += " (" + endpoint.FailedPhase + ")"
+		}
+	}
+	if endpoint.Flaky {
+		result += " flaky"
+	}
+	return result
+}
+
+// markdownText escapes a value for a single line of Markdown, such as a
+// table cell
+func markdownText(value string) string {
+	return strings.NewReplacer(
+		"|", "\\|", "<", "&lt;", ">", "&gt;",
+		"\r\n", " ", "\n", " ", "\r", " ",
+	).Replace(value)
+}
+
+// markdownCode escapes a value for inline code in a table cell
+func markdownCode(value string) string {
+	return strings.NewReplacer("`", "'", "|", "\\|", "\r\n", " ", "\n", " ", "\r", " ").Replace(value)
+}
+
+// lastTraceID returns the trace ID of the last traced attempt, or "" when
+// none was traced
+func lastTraceID(attempts []Attempt) string {
+	for i := len(attempts) - 1; i >= 0; i-- {
+		if attempts[i].TraceID != "" {
+			return attempts[i].TraceID
+		}
+	}
+	return ""
+}
+
+// lastRequestIDs returns the request IDs of the last attempt that had any,
+// or nil when none had
+func lastRequestIDs(attempts []Attempt) map[string]string {
+	for i := len(attempts) - 1; i >= 0; i-- {
+		if len(attempts[i].RequestIDs) > 0 {
+			return attempts[i].RequestIDs
+		}
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package report
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteMarkdown(t *testing.T) {
+	startedAt := time.Date(2026, 1, 14, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		report   *Report
+		expected []string
+		absent   []string
+	}{
+		{
+			name: "failed",
+			report: &Report{
+				StartedAt:  startedAt,
+				FinishedAt: startedAt.Add(1500 * time.Millisecond),
+				Tool:       "api-tester",
+				Version:    "1.2.3",
+				Endpoints: []Endpoint{
+					{Name: "Users", Method: "GET", URL: "https://api.example.com/users", Status: StatusPassed, StatusCode: 200, DurationMs: 120},
+					{
+						Name: "Orders | <v2>", Method: "POST", URL: "https://api.example.com/orders", Status: StatusFailed,
+						FailedPhase: "response", StatusCode: 500, DurationMs: 80, Error: "Unexpected status code: 500\n```json\n{}\n```",
+						Assertions: []Assertion{
+							{Description: "status == 201", Error: "got 500"},
+							{Description: "header present", Passed: true},
+						},
+						Diagnoses: []string{"the token has no roles claim"},
+						Attempts: []Attempt{{
+							StatusCode: 500,
+							TraceID:    "4bf92f3577b34da6a3ce929d0e0e4736",
+							RequestIDs: map[string]string{"x-ms-request-id": "6f1c2d3e-0000-4000-8000-000000000001"},
+						}},
+					},
+					{Name: "Items", Method: "GET", URL: "https://api.example.com/items", Status: StatusSkipped, Error: "Skipped (dependency failed: Orders)"},
+				},
+				Summary: Summary{Total: 3, Passed: 1, Failed: 1, Skipped: 1},
+			},
+			expected: []string{
+				"# API Test Report",
+				"- **Result:** ❌ failed",
+				"- **Started:** 2026-01-14T10:00:00Z",
+				"- **Duration:** 1.5s",
+				"- **Version:** api-tester 1.2.3",
+				"- **Endpoints:** 3 total, 1 passed, 1 failed, 1 skipped",
+				"| Users | `GET https://api.example.com/users` | ✅ passed | 200 | 120 ms |",
+				"| Orders \\| &lt;v2&gt; | `POST https://api.example.com/orders` | ❌ failed (response) | 500 | 80 ms |",
+				"| Items | `GET https://api.example.com/items` | ⏭️ skipped | - | 0 ms |",
+				"## Failures",
+				"<details>\n<summary><strong>Orders | &lt;v2&gt;</strong>: failed in response</summary>",
+				"````text\nUnexpected status code: 500\n```json\n{}\n```\n````",
+				"- ❌ `status == 201`: got 500",
+				"- 💡 the token has no roles claim",
+				"- Trace ID: `4bf92f3577b34da6a3ce929d0e0e4736`",
+				"- x-ms-request-id: `6f1c2d3e-0000-4000-8000-000000000001`",
+				"</details>",
+			},
+			absent: []string{"header present"},
+		},
+		{
+			name: "passed",
+			report: &Report{
+				StartedAt:  startedAt,
+				FinishedAt: startedAt.Add(time.Second),
+				Endpoints:  []Endpoint{{Name: "Users", Method: "GET", URL: "https://api.example.com/users", Status: StatusPassed, StatusCode: 200, DurationMs: 120, Flaky: true}},
+				Summary:    Summary{Total: 1, Passed: 1},
+			},
+			expected: []string{"- **Result:** ✅ passed", "✅ passed flaky"},
+			absent:   []string{"## Failures", "<details>", "## Security Headers"},
+		},
+		{
+			name: "security",
+			report: &Report{
+				StartedAt:  startedAt,
+				FinishedAt: startedAt,
+				Endpoints: []Endpoint{
+					{Name: "Users", Status: StatusPassed, Security: &Security{Grade: "A"}},
+					{Name: "Orders", Status: StatusPassed, Security: &Security{Grade: "C", Findings: []SecurityFinding{
+						{Check: "server", Message: `Server discloses "nginx"`},
+						{Check: "x-powered-by", Message: `X-Powered-By discloses "<Express>"`},
+					}}},
+					{Name: "Items", Status: StatusPassed},
+				},
+				Summary: Summary{Total: 3, Passed: 3},
+			},
+			expected: []string{
+				"## Security Headers\n\n| Endpoint | Grade | Findings |\n| --- | --- | --- |\n",
+				"| Users | A | - |\n| Orders | C | Server discloses \"nginx\"<br>X-Powered-By discloses \"&lt;Express&gt;\" |\n",
+			},
+			absent: []string{"&gt;\" |\n| Items"},
+		},
+		{
+			name: "aborted",
+			report: &Report{
+				StartedAt:  startedAt,
+				FinishedAt: startedAt,
+				Aborted:    "interrupted by SIGINT",
+			},
+			expected: []string{"- **Result:** ⚠️ aborted: interrupted by SIGINT", "0 total"},
+			absent:   []string{"| Endpoint |"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf strings.Builder
+			if err := tt.report.WriteMarkdown(&buf); err != nil {
+				t.Fatalf("WriteMarkdown failed: %v", err)
+			}
+			got := buf.String()
+			for _, expected := range tt.expected {
+				if !strings.Contains(got, expected) {
+					t.Errorf("Expected report to contain %q, got:\n%s", expected, got)
+				}
+			}
+			for _, absent := range tt.absent {
+				if strings.Contains(got, absent) {
+					t.Errorf("Expected report not to contain %q, got:\n%s", absent, got)
+				}
+			}
+		})
+	}
+}
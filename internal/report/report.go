@@ -0,0 +1,564 @@
+// Package report builds machine-readable run reports from endpoint results
+// and signs them so test evidence can be verified after the run.
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"time"
+
+	"github.com/hutstep/entra-id-api-tester/internal/client"
+	"github.com/hutstep/entra-id-api-tester/internal/redact"
+	"github.com/hutstep/entra-id-api-tester/internal/runner"
+)
+
+// Supported report formats
+const (
+	FormatJSON     = "json"
+	FormatCSV      = "csv"
+	FormatMarkdown = "markdown"
+)
+
+// Endpoint statuses
+const (
+	StatusPassed  = "passed"
+	StatusFailed  = "failed"
+	StatusSkipped = "skipped"
+)
+
+// Report is the outcome of a complete run
+type Report struct {
+	StartedAt  time.Time `json:"startedAt"`
+	FinishedAt time.Time `json:"finishedAt"`
+	Tool       string    `json:"tool"`
+	Version    string    `json:"version"`
+	// Aborted is the reason the run stopped early, if it did
+	Aborted string `json:"aborted,omitempty"`
+	// Pacing describes how requests were paced, if they were
+	Pacing *Pacing `json:"pacing,omitempty"`
+	// Tokens is the outcome of acquiring the tokens before the endpoints
+	// ran, if they were
+	Tokens    *Tokens    `json:"tokens,omitempty"`
+	Endpoints []Endpoint `json:"endpoints"`
+	Summary   Summary    `json:"summary"`
+}
+
+// Pacing describes how a run paced its requests with -rate and
+// delayAfterMs
+type Pacing struct {
+	// Rate is the configured rate limit, e.g. "5/s", if any
+	Rate string `json:"rate,omitempty"`
+	// WaitMs is the total time spent waiting for the rate limit and the
+	// endpoints' delays
+	WaitMs int64 `json:"waitMs"`
+	// Requests is the number of requests sent, counting a race test's
+	// concurrent requests as one
+	Requests int `json:"requests"`
+	// EffectiveRate is the number of requests sent per second over the run
+	EffectiveRate float64 `json:"effectiveRate"`
+}
+
+// Tokens is the outcome of acquiring every distinct token up front with
+// -preacquire-tokens
+type Tokens struct {
+	// DurationMs is how long acquiring all of them took
+	DurationMs int64 `json:"durationMs"`
+	Acquired   int   `json:"acquired"`
+	// Failures are the tokens that could not be acquired
+	Failures []TokenFailure `json:"failures,omitempty"`
+}
+
+// TokenFailure is a token that could not be acquired
+type TokenFailure struct {
+	TenantID string `json:"tenantId"`
+	ClientID string `json:"clientId"`
+	Scope    string `json:"scope"`
+	// Endpoints are the names of the endpoints that use the token
+	Endpoints []string `json:"endpoints"`
+	Error     string   `json:"error"`
+}
+
+// Summary counts endpoint outcomes
+type Summary struct {
+	Total            int `json:"total"`
+	Passed           int `json:"passed"`
+	Failed           int `json:"failed"`
+	Skipped          int `json:"skipped"`
+	AuthFailures     int `json:"authFailures"`
+	PrepareFailures  int `json:"prepareFailures"`
+	ConnectFailures  int `json:"connectFailures"`
+	ResponseFailures int `json:"responseFailures"`
+	// ContractFailures are endpoints whose response passed its checks but
+	// violated its OpenAPI contract
+	ContractFailures int `json:"contractFailures"`
+	// SecurityFailures are endpoints that accepted a request with missing
+	// or invalid credentials
+	SecurityFailures int `json:"securityFailures"`
+	// PerformanceFailures are endpoints that passed their checks but
+	// exceeded their maximum duration
+	PerformanceFailures int `json:"performanceFailures"`
+	Throttled           int `json:"throttled"`
+	// Flaky are failed endpoints that passed some of their repeated runs
+	Flaky int `json:"flaky"`
+	// ErrorCodes counts the failed and skipped endpoints by error code
+	ErrorCodes map[string]int `json:"errorCodes,omitempty"`
+}
+
+// Endpoint is the outcome of a single endpoint
+type Endpoint struct {
+	Warnings []string `json:"warnings,omitempty"`
+	// Diagnoses explain a 401 or 403 from the claims of the token sent
+	Diagnoses  []string    `json:"diagnoses,omitempty"`
+	Phases     []Phase     `json:"phases,omitempty"`
+	Attempts   []Attempt   `json:"attempts,omitempty"`
+	Assertions []Assertion `json:"assertions,omitempty"`
+	// Matrix is the outcome per tenant or scope of a matrix endpoint
+	Matrix []MatrixCell `json:"matrix,omitempty"`
+	// Redirects is the redirect chain the response followed
+	Redirects []Redirect `json:"redirects,omitempty"`
+	// Latency is the outcome of each latency objective
+	Latency []Latency `json:"latency,omitempty"`
+	// Security grades the response's security headers when they were
+	// scanned
+	Security *Security `json:"security,omitempty"`
+	// Token is the validity of the endpoint's token
+	Token *Token `json:"token,omitempty"`
+	// Groups are the object IDs in the groups claim of the endpoint's token
+	Groups []string `json:"groups,omitempty"`
+	// GroupsOverage reports that the token had too many groups to list them
+	GroupsOverage bool `json:"groupsOverage,omitempty"`
+	// TokenServer is the Entra ID server that issued the endpoint's token
+	TokenServer *TokenServer `json:"tokenServer,omitempty"`
+	Name        string       `json:"name"`
+	Method      string       `json:"method,omitempty"`
+	URL         string       `json:"url,omitempty"`
+	Status      string       `json:"status"`
+	FailedPhase string       `json:"failedPhase,omitempty"`
+	// ErrorCode is the cause of the failure, e.g. DNSFailure or Non2xx
+	ErrorCode  string `json:"errorCode,omitempty"`
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"durationMs"`
+	StatusCode int    `json:"statusCode,omitempty"`
+	// ThrottleRetries is the number of 429 responses that were retried
+	ThrottleRetries int `json:"throttleRetries,omitempty"`
+	// PacingWaitMs is the time the endpoint waited for the rate limit and
+	// its delayAfterMs
+	PacingWaitMs int64 `json:"pacingWaitMs,omitempty"`
+	// Polls and OperationDurationMs describe an asynchronous operation
+	Polls               int   `json:"polls,omitempty"`
+	OperationDurationMs int64 `json:"operationDurationMs,omitempty"`
+	// Runs, PassedRuns and Flaky describe an endpoint run with -repeat
+	Runs       int  `json:"runs,omitempty"`
+	PassedRuns int  `json:"passedRuns,omitempty"`
+	Flaky      bool `json:"flaky,omitempty"`
+	// BodyTruncated reports that a response body exceeded the body limit
+	BodyTruncated bool `json:"bodyTruncated,omitempty"`
+	// ClaimsChallenge is the outcome of a CAE claims challenge: satisfied or
+	// unsatisfied
+	ClaimsChallenge string `json:"claimsChallenge,omitempty"`
+}
+
+// Phase is the outcome of one phase of an endpoint test
+type Phase struct {
+	Name       string `json:"name"`
+	DurationMs int64  `json:"durationMs"`
+	Passed     bool   `json:"passed"`
+}
+
+// Attempt is a single request sent for an endpoint
+type Attempt struct {
+	Label string `json:"label,omitempty"`
+	Error string `json:"error,omitempty"`
+	// TraceID is the trace ID of the traceparent header sent
+	TraceID string `json:"traceId,omitempty"`
+	// RequestIDs are the response's diagnostic request ID headers, e.g.
+	// x-ms-request-id, by header name
+	RequestIDs map[string]string `json:"requestIds,omitempty"`
+	// RemoteAddr and AddressFamily (IPv4 or IPv6) are the address the
+	// request was sent to, or the last one tried when it failed to connect
+	RemoteAddr    string `json:"remoteAddr,omitempty"`
+	AddressFamily string `json:"addressFamily,omitempty"`
+	// Protocol is the protocol the response was received over, e.g.
+	// "HTTP/2.0"
+	Protocol string `json:"protocol,omitempty"`
+	// Connection describes the connection a response was received over
+	Connection *Connection `json:"connection,omitempty"`
+	DurationMs int64       `json:"durationMs"`
+	StatusCode int         `json:"statusCode,omitempty"`
+}
+
+// Connection tells a new connection from a reused one, whose requests
+// skip the connection setup
+type Connection struct {
+	Reused bool `json:"reused"`
+	// IdleMs is how long a reused connection was idle
+	IdleMs int64 `json:"idleMs,omitempty"`
+	// TLSResumed reports that a new connection resumed an earlier TLS
+	// session, and TLSHandshakeMs how long its handshake took
+	TLSResumed     bool  `json:"tlsResumed,omitempty"`
+	TLSHandshakeMs int64 `json:"tlsHandshakeMs,omitempty"`
+}
+
+// Token describes how long a token is valid
+type Token struct {
+	ExpiresOn time.Time `json:"expiresOn"`
+	// LifetimeMs is how long the token was issued for
+	LifetimeMs int64 `json:"lifetimeMs,omitempty"`
+	// RemainingMs is how long it was still valid when acquired
+	RemainingMs int64 `json:"remainingMs"`
+}
+
+// TokenServer identifies the Entra ID server that issued a token
+type TokenServer struct {
+	Host string `json:"host"`
+	// Region is the Azure region of a regional token endpoint
+	Region string `json:"region,omitempty"`
+	// Server is the x-ms-ests-server header of the token response
+	Server string `json:"server,omitempty"`
+}
+
+// MatrixCell is the outcome of one entry of a matrix endpoint
+type MatrixCell struct {
+	Label       string `json:"label"`
+	FailedPhase string `json:"failedPhase,omitempty"`
+	Error       string `json:"error,omitempty"`
+	StatusCode  int    `json:"statusCode,omitempty"`
+	Passed      bool   `json:"passed"`
+}
+
+// Latency is the outcome of a latency objective across an endpoint's runs
+type Latency struct {
+	// Objective is the objective as configured, e.g. "p95 < 500ms"
+	Objective  string  `json:"objective"`
+	Percentile float64 `json:"percentile"`
+	DurationMs int64   `json:"durationMs"`
+	MaxMs      int64   `json:"maxMs"`
+	Samples    int     `json:"samples"`
+	Passed     bool    `json:"passed"`
+}
+
+// Redirect is a redirect response that was followed
+type Redirect struct {
+	URL        string `json:"url"`
+	Location   string `json:"location"`
+	StatusCode int    `json:"statusCode"`
+}
+
+// Security is the grade of an endpoint's security headers, from A (every
+// check passed) to F
+type Security struct {
+	Grade    string            `json:"grade"`
+	Findings []SecurityFinding `json:"findings,omitempty"`
+}
+
+// SecurityFinding is a failed security header check
+type SecurityFinding struct {
+	Check   string `json:"check"`
+	Message string `json:"message"`
+}
+
+// Assertion is the outcome of one assertion
+type Assertion struct {
+	Description string `json:"description"`
+	Error       string `json:"error,omitempty"`
+	Passed      bool   `json:"passed"`
+}
+
+// New builds a report from the results of a run
+func New(version string, startedAt, finishedAt time.Time, results []*runner.Result) *Report {
+	report := &Report{
+		StartedAt:  startedAt.UTC(),
+		FinishedAt: finishedAt.UTC(),
+		Tool:       "api-tester",
+		Version:    version,
+		Endpoints:  make([]Endpoint, len(results)),
+		Summary:    Summarize(results),
+	}
+	for i, result := range results {
+		report.Endpoints[i] = newEndpoint(result)
+	}
+	return report
+}
+
+// RecordPacing records how the run paced the requests it sent: at rate,
+// e.g. "5/s", or by the endpoints' delays alone when rate is "". It records
+// nothing when the run was not paced.
+func (r *Report) RecordPacing(rate string, requests int) {
+	pacing := &Pacing{Rate: rate, Requests: requests}
+	for i := range r.Endpoints {
+		pacing.WaitMs += r.Endpoints[i].PacingWaitMs
+	}
+	if rate == "" && pacing.WaitMs == 0 {
+		return
+	}
+	if elapsed := r.FinishedAt.Sub(r.StartedAt).Seconds(); elapsed > 0 {
+		pacing.EffectiveRate = math.Round(float64(requests)/elapsed*100) / 100
+	}
+	r.Pacing = pacing
+}
+
+// RecordTokens records the outcome of acquiring the tokens before the
+// endpoints ran, which took duration
+func (r *Report) RecordTokens(duration time.Duration, acquisitions []runner.TokenAcquisition) {
+	tokens := &Tokens{DurationMs: duration.Milliseconds()}
+	for _, acquisition := range acquisitions {
+		if acquisition.Err == nil {
+			tokens.Acquired++
+			continue
+		}
+		tokens.Failures = append(tokens.Failures, TokenFailure{
+			TenantID:  acquisition.TenantID,
+			ClientID:  acquisition.ClientID,
+			Scope:     acquisition.Scope,
+			Endpoints: acquisition.Endpoints,
+			Error:     redact.String(acquisition.Err.Error()),
+		})
+	}
+	r.Tokens = tokens
+}
+
+// Summarize counts the outcomes of results
+func Summarize(results []*runner.Result) Summary {
+	summary := Summary{Total: len(results)}
+	for _, result := range results {
+		if result.ThrottleRetries > 0 {
+			summary.Throttled++
+		}
+		if code := result.ErrorCode(); code != "" {
+			if summary.ErrorCodes == nil {
+				summary.ErrorCodes = map[string]int{}
+			}
+			summary.ErrorCodes[string(code)]++
+		}
+		switch {
+		case result.Skipped:
+			summary.Skipped++
+			continue
+		case result.Success():
+			summary.Passed++
+			continue
+		}
+
+		summary.Failed++
+		if result.Flaky() {
+			summary.Flaky++
+		}
+		switch result.FailedPhase() {
+		case runner.PhaseAuth:
+			summary.AuthFailures++
+		case runner.PhasePrepare:
+			summary.PrepareFailures++
+		case runner.PhaseConnect:
+			summary.ConnectFailures++
+		case runner.PhaseContract:
+			summary.ContractFailures++
+		case runner.PhaseSecurity:
+			summary.SecurityFailures++
+		case runner.PhasePerformance:
+			summary.PerformanceFailures++
+		default:
+			summary.ResponseFailures++
+		}
+	}
+	return summary
+}
+
+// newEndpoint converts a result to its report form
+func newEndpoint(result *runner.Result) Endpoint {
+	endpoint := Endpoint{
+		Warnings:        redactAll(result.Warnings),
+		Diagnoses:       redactAll(result.Diagnoses),
+		Name:            result.EndpointName,
+		Method:          result.Method,
+		URL:             redact.String(result.URL),
+		Status:          StatusPassed,
+		FailedPhase:     string(result.FailedPhase()),
+		ErrorCode:       string(result.ErrorCode()),
+		DurationMs:      result.Duration.Milliseconds(),
+		StatusCode:      result.StatusCode,
+		ThrottleRetries: result.ThrottleRetries,
+		Polls:           result.Polls,
+		Runs:            result.Runs,
+		PassedRuns:      result.PassedRuns,
+		Flaky:           result.Flaky(),
+		BodyTruncated:   result.BodyTruncated,
+		ClaimsChallenge: result.ClaimsChallenge,
+		Groups:          result.Groups,
+		GroupsOverage:   result.GroupsOverage,
+	}
+	switch {
+	case result.Skipped:
+		endpoint.Status = StatusSkipped
+	case !result.Success():
+		endpoint.Status = StatusFailed
+	}
+	if result.Err != nil {
+		endpoint.Error = redact.String(result.Err.Error())
+	}
+	if !result.Token.ExpiresAt.IsZero() {
+		endpoint.Token = &Token{
+			ExpiresOn:   result.Token.ExpiresAt.UTC(),
+			LifetimeMs:  result.Token.Lifetime.Milliseconds(),
+			RemainingMs: result.Token.Remaining.Milliseconds(),
+		}
+	}
+	if result.TokenServer.Host != "" {
+		endpoint.TokenServer = &TokenServer{
+			Host:   result.TokenServer.Host,
+			Region: result.TokenServer.Region(),
+			Server: result.TokenServer.Server,
+		}
+	}
+
+	for _, phase := range result.Phases {
+		endpoint.Phases = append(endpoint.Phases, Phase{
+			Name:       string(phase.Phase),
+			DurationMs: phase.Duration.Milliseconds(),
+			Passed:     phase.Passed,
+		})
+	}
+	for _, attempt := range result.Attempts {
+		converted := Attempt{
+			Label:         attempt.Label,
+			TraceID:       attempt.TraceID,
+			RequestIDs:    attempt.RequestIDs,
+			RemoteAddr:    attempt.Connection.RemoteAddr,
+			AddressFamily: client.AddressFamily(attempt.Connection.RemoteAddr),
+			Protocol:      attempt.Protocol,
+			DurationMs:    attempt.Duration.Milliseconds(),
+			StatusCode:    attempt.StatusCode,
+		}
+		if attempt.Err != nil {
+			converted.Error = redact.String(attempt.Err.Error())
+		} else if attempt.StatusCode != 0 {
+			converted.Connection = &Connection{
+				Reused:         attempt.Connection.Reused,
+				IdleMs:         attempt.Connection.IdleTime.Milliseconds(),
+				TLSResumed:     attempt.Connection.TLSResumed,
+				TLSHandshakeMs: attempt.Connection.TLSHandshake.Milliseconds(),
+			}
+		}
+		endpoint.Attempts = append(endpoint.Attempts, converted)
+	}
+	for i := range result.Assertions {
+		assertion := &result.Assertions[i]
+		converted := Assertion{
+			Description: assertion.Description,
+			Passed:      assertion.Passed(),
+		}
+		if assertion.Err != nil {
+			converted.Error = redact.String(assertion.Err.Error())
+		}
+		endpoint.Assertions = append(endpoint.Assertions, converted)
+	}
+	for i := range result.Matrix {
+		cell := &result.Matrix[i]
+		converted := MatrixCell{
+			Label:       cell.Label,
+			FailedPhase: string(cell.FailedPhase()),
+			StatusCode:  cell.StatusCode,
+			Passed:      cell.Passed(),
+		}
+		if cell.Err != nil {
+			converted.Error = redact.String(cell.Err.Error())
+		}
+		endpoint.Matrix = append(endpoint.Matrix, converted)
+	}
+	if result.PacingWait > 0 {
+		endpoint.PacingWaitMs = result.PacingWait.Milliseconds()
+	}
+	if result.OperationDuration > 0 {
+		endpoint.OperationDurationMs = result.OperationDuration.Milliseconds()
+	}
+	for i := range result.Latency {
+		latency := &result.Latency[i]
+		endpoint.Latency = append(endpoint.Latency, Latency{
+			Objective:  latency.Objective.String(),
+			Percentile: latency.Objective.Percentile,
+			DurationMs: latency.Duration.Milliseconds(),
+			MaxMs:      latency.Objective.Max.Milliseconds(),
+			Samples:    latency.Samples,
+			Passed:     latency.Passed(),
+		})
+	}
+	for _, redirect := range result.Redirects {
+		endpoint.Redirects = append(endpoint.Redirects, Redirect{
+			URL:        redact.String(redirect.URL),
+			Location:   redact.String(redirect.Location),
+			StatusCode: redirect.StatusCode,
+		})
+	}
+	if result.Security != nil {
+		endpoint.Security = &Security{Grade: result.Security.Grade}
+		for _, finding := range result.Security.Findings {
+			endpoint.Security.Findings = append(endpoint.Security.Findings, SecurityFinding{
+				Check:   finding.Check,
+				Message: redact.String(finding.Message),
+			})
+		}
+	}
+
+	return endpoint
+}
+
+// redactAll returns values with credentials replaced by their fingerprints
+func redactAll(values []string) []string {
+	if values == nil {
+		return nil
+	}
+	redacted := make([]string, len(values))
+	for i, value := range values {
+		redacted[i] = redact.String(value)
+	}
+	return redacted
+}
+
+// WriteJSON writes the report as indented JSON
+func (r *Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(r); err != nil {
+		return fmt.Errorf("failed to encode report: %w", err)
+	}
+	return nil
+}
+
+// Write renders the report in the given format and returns the bytes, so
+// the exact file content can also be signed
+func (r *Report) Write(format string) ([]byte, error) {
+	var buf bytes.Buffer
+	switch format {
+	case FormatJSON:
+		if err := r.WriteJSON(&buf); err != nil {
+			return nil, err
+		}
+	case FormatCSV:
+		if err := r.WriteCSV(&buf); err != nil {
+			return nil, err
+		}
+	case FormatMarkdown:
+		if err := r.WriteMarkdown(&buf); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported report format: %s (must be json, csv or markdown)", format)
+	}
+	return buf.Bytes(), nil
+}
+
+// WriteFile renders the report in the given format to path and returns the
+// bytes written
+func (r *Report) WriteFile(path, format string) ([]byte, error) {
+	data, err := r.Write(format)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return nil, fmt.Errorf("failed to write report: %w", err)
+	}
+	return data, nil
+}
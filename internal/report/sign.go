@@ -0,0 +1,268 @@
+package report
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+)
+
+// SignatureExtension is appended to the report path for the detached
+// signature file
+const SignatureExtension = ".jws"
+
+// ErrInvalidSignature reports a signature that does not match the report
+var ErrInvalidSignature = errors.New("invalid signature")
+
+// jwsHeader is the protected header of a detached JWS
+type jwsHeader struct {
+	Algorithm string `json:"alg"`
+	Type      string `json:"typ"`
+}
+
+// Sign creates a detached JWS (RFC 7515, Appendix F) over data with the
+// PEM-encoded private key: "header..signature", with the payload omitted.
+// RSA (RS256), ECDSA (ES256/ES384/ES512) and Ed25519 (EdDSA) keys are
+// supported.
+func Sign(data, keyPEM []byte) (string, error) {
+	key, err := parsePrivateKey(keyPEM)
+	if err != nil {
+		return "", err
+	}
+
+	var alg string
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		alg = "RS256"
+	case *ecdsa.PrivateKey:
+		alg, err = ecdsaAlgorithm(&k.PublicKey)
+		if err != nil {
+			return "", err
+		}
+	case ed25519.PrivateKey:
+		alg = "EdDSA"
+	default:
+		return "", fmt.Errorf("unsupported private key type %T", key)
+	}
+
+	header, err := json.Marshal(jwsHeader{Algorithm: alg, Type: "JOSE"})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode JWS header: %w", err)
+	}
+	encodedHeader := base64.RawURLEncoding.EncodeToString(header)
+	signingInput := encodedHeader + "." + base64.RawURLEncoding.EncodeToString(data)
+
+	signature, err := signInput(key, alg, []byte(signingInput))
+	if err != nil {
+		return "", err
+	}
+	return encodedHeader + ".." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// SignFile signs data with the private key in keyFile and writes the
+// detached JWS to reportPath + SignatureExtension, returning its path
+func SignFile(reportPath string, data []byte, keyFile string) (string, error) {
+	keyPEM, err := os.ReadFile(keyFile) // #nosec G304 - key path is provided by the user
+	if err != nil {
+		return "", fmt.Errorf("failed to read signing key: %w", err)
+	}
+	jws, err := Sign(data, keyPEM)
+	if err != nil {
+		return "", err
+	}
+
+	path := reportPath + SignatureExtension
+	if err := os.WriteFile(path, []byte(jws+"\n"), 0600); err != nil {
+		return "", fmt.Errorf("failed to write signature: %w", err)
+	}
+	return path, nil
+}
+
+// Verify checks a detached JWS created by Sign against data and the
+// PEM-encoded public key or certificate of the signer
+func Verify(data []byte, jws string, publicKeyPEM []byte) error {
+	key, err := parsePublicKey(publicKeyPEM)
+	if err != nil {
+		return err
+	}
+
+	parts := strings.Split(strings.TrimSpace(jws), ".")
+	if len(parts) != 3 || parts[1] != "" {
+		return fmt.Errorf("%w: expected detached JWS (header..signature)", ErrInvalidSignature)
+	}
+	headerJSON, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return fmt.Errorf("%w: malformed header: %w", ErrInvalidSignature, err)
+	}
+	var header jwsHeader
+	if err := json.Unmarshal(headerJSON, &header); err != nil {
+		return fmt.Errorf("%w: malformed header: %w", ErrInvalidSignature, err)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return fmt.Errorf("%w: malformed signature: %w", ErrInvalidSignature, err)
+	}
+
+	signingInput := []byte(parts[0] + "." + base64.RawURLEncoding.EncodeToString(data))
+	if !verifyInput(key, header.Algorithm, signingInput, signature) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// signInput signs the JWS signing input with the algorithm's hash
+func signInput(key crypto.Signer, alg string, input []byte) ([]byte, error) {
+	if alg == "EdDSA" {
+		return key.Sign(rand.Reader, input, crypto.Hash(0))
+	}
+
+	hash := algorithmHash(alg)
+	digest := hashInput(hash, input)
+	signature, err := key.Sign(rand.Reader, digest, hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign report: %w", err)
+	}
+
+	// JWS encodes ECDSA signatures as fixed-size R || S instead of ASN.1
+	if ecKey, ok := key.(*ecdsa.PrivateKey); ok {
+		var parsed struct{ R, S *big.Int }
+		if _, err := asn1.Unmarshal(signature, &parsed); err != nil {
+			return nil, fmt.Errorf("failed to encode ECDSA signature: %w", err)
+		}
+		size := (ecKey.Curve.Params().BitSize + 7) / 8
+		raw := make([]byte, 2*size)
+		parsed.R.FillBytes(raw[:size])
+		parsed.S.FillBytes(raw[size:])
+		return raw, nil
+	}
+	return signature, nil
+}
+
+// verifyInput verifies a JWS signature with the given public key
+func verifyInput(key crypto.PublicKey, alg string, input, signature []byte) bool {
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		if alg != "RS256" {
+			return false
+		}
+		return rsa.VerifyPKCS1v15(k, crypto.SHA256, hashInput(crypto.SHA256, input), signature) == nil
+	case *ecdsa.PublicKey:
+		expected, err := ecdsaAlgorithm(k)
+		if err != nil || alg != expected {
+			return false
+		}
+		size := (k.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return false
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		return ecdsa.Verify(k, hashInput(algorithmHash(alg), input), r, s)
+	case ed25519.PublicKey:
+		return alg == "EdDSA" && ed25519.Verify(k, input, signature)
+	}
+	return false
+}
+
+// ecdsaAlgorithm returns the JWS algorithm for an ECDSA key's curve
+func ecdsaAlgorithm(key *ecdsa.PublicKey) (string, error) {
+	switch key.Curve.Params().BitSize {
+	case 256:
+		return "ES256", nil
+	case 384:
+		return "ES384", nil
+	case 521:
+		return "ES512", nil
+	}
+	return "", fmt.Errorf("unsupported ECDSA curve %s", key.Curve.Params().Name)
+}
+
+// algorithmHash returns the hash function of a JWS algorithm
+func algorithmHash(alg string) crypto.Hash {
+	switch alg {
+	case "ES384":
+		return crypto.SHA384
+	case "ES512":
+		return crypto.SHA512
+	default:
+		return crypto.SHA256
+	}
+}
+
+// hashInput hashes input with the given hash function
+func hashInput(hash crypto.Hash, input []byte) []byte {
+	switch hash {
+	case crypto.SHA384:
+		sum := sha512.Sum384(input)
+		return sum[:]
+	case crypto.SHA512:
+		sum := sha512.Sum512(input)
+		return sum[:]
+	default:
+		sum := sha256.Sum256(input)
+		return sum[:]
+	}
+}
+
+// parsePrivateKey decodes a PKCS#8, PKCS#1 (RSA) or SEC 1 (EC) private key
+func parsePrivateKey(keyPEM []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(keyPEM)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data found in signing key")
+	}
+
+	var key interface{}
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse signing key: %w", err)
+	}
+
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key type %T", key)
+	}
+	return signer, nil
+}
+
+// parsePublicKey decodes a PKIX public key or the key of an X.509 certificate
+func parsePublicKey(keyPEM []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(keyPEM)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data found in public key")
+	}
+
+	if block.Type == "CERTIFICATE" {
+		certificate, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse certificate: %w", err)
+		}
+		return certificate.PublicKey, nil
+	}
+
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key: %w", err)
+	}
+	return key, nil
+}
@@ -0,0 +1,201 @@
+package report
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// encodeKeys returns the PKCS#8 private key and PKIX public key as PEM
+func encodeKeys(t *testing.T, key crypto.Signer) (privatePEM, publicPEM []byte) {
+	t.Helper()
+	privateDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("Failed to marshal private key: %v", err)
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(key.Public())
+	if err != nil {
+		t.Fatalf("Failed to marshal public key: %v", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER}),
+		pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})
+}
+
+func TestSignVerify(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p256Key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p384Key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p521Key, err := ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		key  crypto.Signer
+		name string
+		alg  string
+	}{
+		{rsaKey, "RSA", "RS256"},
+		{p256Key, "P-256", "ES256"},
+		{p384Key, "P-384", "ES384"},
+		{p521Key, "P-521", "ES512"},
+		{edKey, "Ed25519", "EdDSA"},
+	}
+
+	data := []byte(`{"summary":{"total":1,"passed":1}}`)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			privatePEM, publicPEM := encodeKeys(t, tt.key)
+
+			jws, err := Sign(data, privatePEM)
+			if err != nil {
+				t.Fatalf("Sign failed: %v", err)
+			}
+			if parts := strings.Split(jws, "."); len(parts) != 3 || parts[1] != "" {
+				t.Fatalf("Expected detached JWS, got %q", jws)
+			}
+			if !strings.Contains(decodeHeader(t, jws), `"alg":"`+tt.alg+`"`) {
+				t.Errorf("Expected alg %s in header", tt.alg)
+			}
+
+			if err := Verify(data, jws, publicPEM); err != nil {
+				t.Errorf("Verify failed: %v", err)
+			}
+
+			tampered := []byte(strings.Replace(string(data), `"passed":1`, `"passed":2`, 1))
+			if err := Verify(tampered, jws, publicPEM); !errors.Is(err, ErrInvalidSignature) {
+				t.Errorf("Expected ErrInvalidSignature for tampered data, got %v", err)
+			}
+		})
+	}
+}
+
+func decodeHeader(t *testing.T, jws string) string {
+	t.Helper()
+	header, err := base64.RawURLEncoding.DecodeString(strings.Split(jws, ".")[0])
+	if err != nil {
+		t.Fatalf("Failed to decode header: %v", err)
+	}
+	return string(header)
+}
+
+func TestSign_LegacyKeyFormats(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecDER, err := x509.MarshalECPrivateKey(ecKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keys := map[string][]byte{
+		"RSA PRIVATE KEY": pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)}),
+		"EC PRIVATE KEY":  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: ecDER}),
+	}
+	for name, keyPEM := range keys {
+		if _, err := Sign([]byte("report"), keyPEM); err != nil {
+			t.Errorf("%s: Sign failed: %v", name, err)
+		}
+	}
+}
+
+func TestVerify_WrongKey(t *testing.T) {
+	_, signingKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, otherKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	privatePEM, _ := encodeKeys(t, signingKey)
+	_, otherPublicPEM := encodeKeys(t, otherKey)
+
+	jws, err := Sign([]byte("report"), privatePEM)
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	if err := Verify([]byte("report"), jws, otherPublicPEM); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Expected ErrInvalidSignature, got %v", err)
+	}
+}
+
+func TestVerify_Malformed(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, publicPEM := encodeKeys(t, key)
+
+	for _, jws := range []string{"", "abc", "a.b.c", "!!..sig"} {
+		if err := Verify([]byte("report"), jws, publicPEM); !errors.Is(err, ErrInvalidSignature) {
+			t.Errorf("%q: expected ErrInvalidSignature, got %v", jws, err)
+		}
+	}
+}
+
+func TestSign_InvalidKey(t *testing.T) {
+	if _, err := Sign([]byte("report"), []byte("not a key")); err == nil {
+		t.Error("Expected error for non-PEM key")
+	}
+}
+
+func TestSignFile(t *testing.T) {
+	dir := t.TempDir()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	privatePEM, publicPEM := encodeKeys(t, key)
+	keyPath := filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(keyPath, privatePEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	reportPath := filepath.Join(dir, "report.json")
+	data := []byte(`{"endpoints":[]}`)
+	signaturePath, err := SignFile(reportPath, data, keyPath)
+	if err != nil {
+		t.Fatalf("SignFile failed: %v", err)
+	}
+	if signaturePath != reportPath+SignatureExtension {
+		t.Errorf("Unexpected signature path: %s", signaturePath)
+	}
+
+	jws, err := os.ReadFile(signaturePath)
+	if err != nil {
+		t.Fatalf("Failed to read signature: %v", err)
+	}
+	if err := Verify(data, string(jws), publicPEM); err != nil {
+		t.Errorf("Verify failed: %v", err)
+	}
+}
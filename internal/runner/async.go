@@ -0,0 +1,161 @@
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/hutstep/entra-id-api-tester/internal/client"
+	"github.com/hutstep/entra-id-api-tester/internal/config"
+)
+
+// DefaultAsyncTimeout is how long an asynchronous operation may take by
+// default
+const DefaultAsyncTimeout = 5 * time.Minute
+
+// operationHeaders point to the status of an asynchronous operation, in
+// order of precedence. A status monitor reports the operation's status in
+// its body; a Location keeps answering 202 until the result is ready.
+var operationHeaders = []string{"Azure-AsyncOperation", "Operation-Location", "Location"}
+
+// OperationError reports an asynchronous operation that failed, was
+// canceled or did not complete in time
+type OperationError struct {
+	// Status is the operation's terminal status, e.g. "Failed", or "" when
+	// it did not reach one
+	Status string
+	Reason string
+}
+
+// Error describes the status and the reason
+func (e *OperationError) Error() string {
+	switch {
+	case e.Status == "":
+		return e.Reason
+	case e.Reason == "":
+		return "operation " + e.Status
+	default:
+		return fmt.Sprintf("operation %s: %s", e.Status, e.Reason)
+	}
+}
+
+// asyncTimeout returns how long the endpoint's asynchronous operation may
+// take
+func asyncTimeout(endpoint *config.Endpoint) time.Duration {
+	if endpoint.AsyncTimeoutMs > 0 {
+		return time.Duration(endpoint.AsyncTimeoutMs) * time.Millisecond
+	}
+	return DefaultAsyncTimeout
+}
+
+// awaitOperation polls the asynchronous operation a 202 Accepted response
+// points to, with the request's token, until it completes, waiting as long
+// as each response's Retry-After asks. It returns the final response, which
+// is the status monitor's or, for a Location, the operation's result. Any
+// other response is returned as is. An operation that fails or times out
+// returns the last response and an *OperationError.
+func (r *Runner) awaitOperation(ctx context.Context, endpoint *config.Endpoint, request *client.Request, response *client.Response, started time.Time, result *Result) (*client.Response, error) {
+	if response.StatusCode != http.StatusAccepted {
+		return response, nil
+	}
+	header, monitor, err := operationMonitor(request.URL, response.Headers)
+	if err != nil {
+		return response, err
+	}
+
+	poll := *request
+	poll.Method = http.MethodGet
+	poll.URL = monitor
+	poll.Body, poll.RawBody, poll.ContentType = nil, nil, ""
+
+	timeout := asyncTimeout(endpoint)
+	deadline := started.Add(timeout)
+	for {
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return response, &OperationError{Reason: fmt.Sprintf("operation did not complete within %v", timeout)}
+		}
+		wait := min(client.RetryAfter(response.Headers.Get("Retry-After"), time.Now()), remaining)
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, fmt.Errorf("cancelled while polling: %w", ctx.Err())
+		case <-timer.C:
+		}
+
+		result.Polls++
+		r.debug(endpoint, "polling operation", "header", header, "poll", result.Polls)
+		response, err = r.send(ctx, endpoint, &poll, fmt.Sprintf("poll %d", result.Polls), result)
+		if err != nil {
+			return nil, err
+		}
+		done, err := operationDone(header, response)
+		if done || err != nil {
+			result.OperationDuration = time.Since(started)
+			return response, err
+		}
+	}
+}
+
+// operationMonitor returns the header pointing to the operation's status
+// and its URL, resolved against the request URL
+func operationMonitor(requestURL string, headers http.Header) (string, string, error) {
+	for _, header := range operationHeaders {
+		value := headers.Get(header)
+		if value == "" {
+			continue
+		}
+		base, err := url.Parse(requestURL)
+		if err != nil {
+			return "", "", err
+		}
+		reference, err := url.Parse(value)
+		if err != nil {
+			return "", "", &OperationError{Reason: fmt.Sprintf("invalid %s header: %v", header, err)}
+		}
+		return header, base.ResolveReference(reference).String(), nil
+	}
+	return "", "", &OperationError{Reason: "202 Accepted without an Azure-AsyncOperation, Operation-Location or Location header"}
+}
+
+// operationDone reports whether a poll response ends the operation. A
+// Location answers 202 until the operation completes; a status monitor
+// reports a status of Succeeded, Failed or Canceled when it does.
+func operationDone(header string, response *client.Response) (bool, error) {
+	if header == "Location" {
+		return response.StatusCode != http.StatusAccepted, nil
+	}
+	if !response.IsSuccessStatusCode() {
+		return true, &OperationError{Reason: fmt.Sprintf("status monitor returned %d", response.StatusCode)}
+	}
+
+	var monitor struct {
+		Error *struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+		Status string `json:"status"`
+	}
+	if err := json.Unmarshal(response.Body, &monitor); err != nil {
+		return true, &OperationError{Reason: fmt.Sprintf("invalid status monitor response: %v", err)}
+	}
+	switch strings.ToLower(monitor.Status) {
+	case "succeeded":
+		return true, nil
+	case "failed", "canceled", "cancelled":
+		operationErr := &OperationError{Status: monitor.Status}
+		if monitor.Error != nil {
+			operationErr.Reason = monitor.Error.Message
+			if monitor.Error.Code != "" {
+				operationErr.Reason = strings.TrimSuffix(monitor.Error.Code+": "+monitor.Error.Message, ": ")
+			}
+		}
+		return true, operationErr
+	}
+	return false, nil
+}
@@ -0,0 +1,106 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hutstep/entra-id-api-tester/internal/config"
+)
+
+func TestRun_AsyncOperation(t *testing.T) {
+	tests := []struct {
+		name        string
+		header      string
+		statuses    []string
+		assertions  []config.Assertion
+		timeoutMs   int
+		expectPolls int
+		expectErr   string
+	}{
+		{
+			name:        "status monitor succeeds",
+			header:      "Azure-AsyncOperation",
+			statuses:    []string{`{"status":"InProgress"}`, `{"status":"Succeeded"}`},
+			assertions:  []config.Assertion{{JSONPath: "$.status", Equals: "Succeeded"}},
+			expectPolls: 2,
+		},
+		{
+			name:        "Location answers 202 until done",
+			header:      "Location",
+			statuses:    []string{"", "", `{"id":"42"}`},
+			assertions:  []config.Assertion{{Status: 200}, {JSONPath: "$.id", Equals: "42"}},
+			expectPolls: 3,
+		},
+		{
+			name:      "status monitor fails",
+			header:    "Operation-Location",
+			statuses:  []string{`{"status":"Failed","error":{"code":"QuotaExceeded","message":"No capacity"}}`},
+			expectErr: "Async operation failed: operation Failed: QuotaExceeded: No capacity",
+		},
+		{
+			name:      "times out",
+			header:    "Azure-AsyncOperation",
+			statuses:  []string{`{"status":"Running"}`},
+			timeoutMs: 50,
+			expectErr: "Async operation failed: operation did not complete within 50ms",
+		},
+		{
+			name:      "no monitor",
+			expectErr: "Async operation failed: 202 Accepted without an Azure-AsyncOperation, Operation-Location or Location header",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			polls := 0
+			var authorization []string
+			runner, endpoint := newTestRunner(t, func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Retry-After", "0")
+				if r.URL.Path != "/operations/1" {
+					if tt.header != "" {
+						w.Header().Set(tt.header, "/operations/1")
+					}
+					w.WriteHeader(http.StatusAccepted)
+					return
+				}
+				authorization = append(authorization, r.Header.Get("Authorization"))
+				status := tt.statuses[min(polls, len(tt.statuses)-1)]
+				polls++
+				if status == "" {
+					w.WriteHeader(http.StatusAccepted)
+					return
+				}
+				_, _ = w.Write([]byte(status))
+			})
+			endpoint.URL += "/deployments"
+			endpoint.Method = "PUT"
+			endpoint.RequestBody = map[string]interface{}{"sku": "S1"}
+			endpoint.AsyncOperation = true
+			endpoint.AsyncTimeoutMs = tt.timeoutMs
+			endpoint.Assertions = tt.assertions
+
+			result := runner.Run(context.Background(), endpoint, &fakeTokenProvider{token: "token"})
+
+			for _, value := range authorization {
+				if value != "Bearer token" {
+					t.Errorf("Expected polls to send the request's token, got %q", value)
+				}
+			}
+			if tt.expectErr != "" {
+				if !errors.Is(result.Err, ErrResponse) || !strings.HasPrefix(result.Err.Error(), tt.expectErr) {
+					t.Errorf("Expected response failure %q, got %v", tt.expectErr, result.Err)
+				}
+				return
+			}
+			if !result.Success() {
+				t.Fatalf("Expected success, got %v", result.Err)
+			}
+			if result.Polls != tt.expectPolls || len(result.Attempts) != tt.expectPolls+1 || result.OperationDuration <= 0 {
+				t.Errorf("Expected %d polls, got %d (attempts %d, duration %v)", tt.expectPolls, result.Polls, len(result.Attempts), result.OperationDuration)
+			}
+		})
+	}
+}
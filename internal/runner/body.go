@@ -0,0 +1,77 @@
+package runner
+
+import (
+	"fmt"
+	"mime"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"github.com/hutstep/entra-id-api-tester/internal/client"
+	"github.com/hutstep/entra-id-api-tester/internal/config"
+	"github.com/hutstep/entra-id-api-tester/internal/vars"
+)
+
+// defaultFileContentType is sent for uploaded files of an unknown type
+const defaultFileContentType = "application/octet-stream"
+
+// formContentType is the Content-Type of form bodies
+const formContentType = "application/x-www-form-urlencoded"
+
+// formBody encodes a request body as form fields, with a field repeated
+// for each element of an array
+func formBody(body map[string]interface{}) []byte {
+	values := url.Values{}
+	for key, value := range body {
+		elements, isArray := value.([]interface{})
+		if !isArray {
+			elements = []interface{}{value}
+		}
+		for _, element := range elements {
+			if element == nil {
+				element = ""
+			}
+			values.Add(key, fmt.Sprint(element))
+		}
+	}
+	return []byte(values.Encode())
+}
+
+// multipartBody encodes the endpoint's multipart parts, expanding the
+// placeholders of values and reading files, and returns the body with its
+// Content-Type
+func multipartBody(variables *vars.Store, parts []config.MultipartPart) ([]byte, string, error) {
+	encoded := make([]client.Part, len(parts))
+	for i := range parts {
+		part := &parts[i]
+		encoded[i] = client.Part{
+			Name:        part.Name,
+			FileName:    part.FileName,
+			ContentType: part.ContentType,
+		}
+		if part.File == "" {
+			value, err := variables.Expand(part.Value)
+			if err != nil {
+				return nil, "", fmt.Errorf("multipart part %s: %w", part.Name, err)
+			}
+			encoded[i].Value = []byte(value)
+			continue
+		}
+
+		content, err := os.ReadFile(part.File) // #nosec G304 - upload files are chosen by the configuration owner
+		if err != nil {
+			return nil, "", fmt.Errorf("multipart part %s: %w", part.Name, err)
+		}
+		encoded[i].Value = content
+		if encoded[i].FileName == "" {
+			encoded[i].FileName = filepath.Base(part.File)
+		}
+		if encoded[i].ContentType == "" {
+			encoded[i].ContentType = mime.TypeByExtension(filepath.Ext(part.File))
+		}
+		if encoded[i].ContentType == "" {
+			encoded[i].ContentType = defaultFileContentType
+		}
+	}
+	return client.EncodeMultipart(encoded)
+}
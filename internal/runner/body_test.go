@@ -0,0 +1,111 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hutstep/entra-id-api-tester/internal/config"
+)
+
+func TestRun_Multipart(t *testing.T) {
+	dir := t.TempDir()
+	document := filepath.Join(dir, "report.pdf")
+	if err := os.WriteFile(document, []byte("%PDF-1.7"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	unknown := filepath.Join(dir, "data.unknownext")
+	if err := os.WriteFile(unknown, []byte{0x00, 0x01}, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var received []string
+	runner, endpoint := newTestRunner(t, func(w http.ResponseWriter, r *http.Request) {
+		reader, err := r.MultipartReader()
+		if err != nil {
+			w.WriteHeader(http.StatusUnsupportedMediaType)
+			return
+		}
+		for {
+			part, err := reader.NextPart()
+			if err != nil {
+				break
+			}
+			content, _ := io.ReadAll(part)
+			received = append(received, strings.Join([]string{part.FormName(), part.FileName(), part.Header.Get("Content-Type"), string(content)}, "|"))
+		}
+		w.WriteHeader(http.StatusCreated)
+	})
+	runner.variables.Set("folder", "finance")
+	endpoint.Method = "POST"
+	endpoint.Multipart = []config.MultipartPart{
+		{Name: "folder", Value: "{{vars.folder}}"},
+		{Name: "metadata", Value: `{"title":"Q1"}`, ContentType: "application/json"},
+		{Name: "document", File: document},
+		{Name: "attachment", File: unknown, FileName: "data.bin"},
+	}
+
+	result := runner.Run(context.Background(), endpoint, &fakeTokenProvider{token: "token"})
+
+	if !result.Success() {
+		t.Fatalf("Expected success, got %v", result.Err)
+	}
+	expected := []string{
+		"folder|||finance",
+		`metadata||application/json|{"title":"Q1"}`,
+		"document|report.pdf|application/pdf|%PDF-1.7",
+		"attachment|data.bin|application/octet-stream|\x00\x01",
+	}
+	if strings.Join(received, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected parts:\n%q\ngot:\n%q", expected, received)
+	}
+}
+
+func TestRun_MultipartMissingFile(t *testing.T) {
+	runner, endpoint := newTestRunner(t, respond(http.StatusOK, `{}`))
+	endpoint.Method = "POST"
+	endpoint.Multipart = []config.MultipartPart{{Name: "document", File: filepath.Join(t.TempDir(), "missing.pdf")}}
+
+	result := runner.Run(context.Background(), endpoint, &fakeTokenProvider{token: "token"})
+
+	if !errors.Is(result.Err, ErrPrepare) || !errors.Is(result.Err, os.ErrNotExist) {
+		t.Errorf("Expected a prepare failure for the missing file, got %v", result.Err)
+	}
+}
+
+func TestRun_FormBody(t *testing.T) {
+	var contentType, body string
+	runner, endpoint := newTestRunner(t, func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+	})
+	runner.variables.Set("user", "ada@example.com")
+	endpoint.Method = "POST"
+	endpoint.BodyType = config.BodyTypeForm
+	endpoint.RequestBody = map[string]interface{}{
+		"user":     "{{vars.user}}",
+		"quantity": float64(3),
+		"express":  true,
+		"tags":     []interface{}{"a b", "c&d"},
+		"note":     nil,
+	}
+
+	result := runner.Run(context.Background(), endpoint, &fakeTokenProvider{token: "token"})
+
+	if !result.Success() {
+		t.Fatalf("Expected success, got %v", result.Err)
+	}
+	if contentType != "application/x-www-form-urlencoded" {
+		t.Errorf("Unexpected Content-Type: %s", contentType)
+	}
+	expected := "express=true&note=&quantity=3&tags=a+b&tags=c%26d&user=ada%40example.com"
+	if body != expected {
+		t.Errorf("Expected body %q, got %q", expected, body)
+	}
+}
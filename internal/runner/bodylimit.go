@@ -0,0 +1,67 @@
+package runner
+
+import (
+	"fmt"
+
+	"github.com/hutstep/entra-id-api-tester/internal/config"
+)
+
+// DefaultMaxBodyBytes is how much of a response body is read by default
+const DefaultMaxBodyBytes int64 = 100 << 20
+
+// maxBodyBytes returns the endpoint's response body limit, falling back to
+// the runner default; zero means none
+func (r *Runner) maxBodyBytes(endpoint *config.Endpoint) int64 {
+	if endpoint.MaxBodyBytes > 0 {
+		return endpoint.MaxBodyBytes
+	}
+	return r.MaxBodyBytes
+}
+
+// BodyTruncatedError reports that a response body was cut off at the body
+// limit although the endpoint checks it
+type BodyTruncatedError struct {
+	// Check names the check that needs the whole body
+	Check string
+	Limit int64
+}
+
+// Error describes the limit and the check
+func (e *BodyTruncatedError) Error() string {
+	return fmt.Sprintf("response body exceeds the %d byte limit (maxBodyBytes), so %s cannot be evaluated", e.Limit, e.Check)
+}
+
+// checkTruncated returns a *BodyTruncatedError when the endpoint checks a
+// response body that was truncated at limit. Status and header checks do not
+// need the body and are evaluated as usual.
+func checkTruncated(endpoint *config.Endpoint, limit int64) error {
+	check := ""
+	switch {
+	case endpoint.GraphQL != nil:
+		check = "graphql"
+	case endpoint.ExpectedSHA256 != "":
+		check = "expectedSha256"
+	case endpoint.ExpectedContentLength > 0:
+		check = "expectedContentLength"
+	case endpoint.GoldenFile != "":
+		check = "goldenFile"
+	case endpoint.Contract != nil:
+		check = "contract"
+	case endpoint.Hooks != nil && len(endpoint.Hooks.PostResponse) > 0:
+		check = "the postResponse hook"
+	}
+	for i := range endpoint.Assertions {
+		if check == "" && endpoint.Assertions[i].ReadsBody() {
+			check = fmt.Sprintf("assertion %d", i+1)
+		}
+	}
+	for _, capture := range endpoint.Captures {
+		if check == "" && capture.Header == "" {
+			check = "capture " + capture.Name
+		}
+	}
+	if check == "" {
+		return nil
+	}
+	return &BodyTruncatedError{Check: check, Limit: limit}
+}
@@ -0,0 +1,76 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hutstep/entra-id-api-tester/internal/config"
+)
+
+func TestRun_MaxBodyBytes(t *testing.T) {
+	body := `{"items":[` + strings.Repeat(`{"id":1},`, 100) + `{"id":1}]}`
+	tests := []struct {
+		name       string
+		assertions []config.Assertion
+		captures   []config.Capture
+		status     int
+		limit      int64
+		expectErr  string
+	}{
+		{name: "within the limit", limit: 4096, assertions: []config.Assertion{{JSONPath: "$.items[0].id", Equals: float64(1)}}},
+		{name: "status only", limit: 16},
+		{
+			name:       "status and header checks",
+			limit:      16,
+			assertions: []config.Assertion{{Status: 200}, {Header: "Content-Type", Equals: "application/json"}},
+		},
+		{
+			name:       "body assertion",
+			limit:      16,
+			assertions: []config.Assertion{{Status: 200}, {JSONPath: "$.items[0].id", Equals: float64(1)}},
+			expectErr:  "Response body too large: response body exceeds the 16 byte limit (maxBodyBytes), so assertion 2 cannot be evaluated",
+		},
+		{
+			name:      "body capture",
+			limit:     16,
+			captures:  []config.Capture{{Name: "id", JSONPath: "$.items[0].id"}},
+			expectErr: "Response body too large: response body exceeds the 16 byte limit (maxBodyBytes), so capture id cannot be evaluated",
+		},
+		{name: "unexpected status", status: http.StatusBadGateway, limit: 16, expectErr: "Unexpected status code: 502"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := tt.status
+			if status == 0 {
+				status = http.StatusOK
+			}
+			runner, endpoint := newTestRunner(t, respond(status, body))
+			endpoint.MaxBodyBytes = tt.limit
+			endpoint.Assertions = tt.assertions
+			endpoint.Captures = tt.captures
+
+			result := runner.Run(context.Background(), endpoint, &fakeTokenProvider{token: "token"})
+
+			truncated := tt.limit < int64(len(body))
+			if result.BodyTruncated != truncated {
+				t.Errorf("Expected BodyTruncated %v", truncated)
+			}
+			if truncated && (len(result.Warnings) != 1 || result.Warnings[0] != "Response body truncated at 16 bytes (maxBodyBytes)") {
+				t.Errorf("Expected a truncation warning, got %v", result.Warnings)
+			}
+			if tt.expectErr == "" {
+				if !result.Success() {
+					t.Errorf("Expected success, got %v", result.Err)
+				}
+				return
+			}
+			if !errors.Is(result.Err, ErrResponse) || result.Err.Error() != tt.expectErr {
+				t.Errorf("Expected response failure %q, got %v", tt.expectErr, result.Err)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/hutstep/entra-id-api-tester/internal/auth"
+	"github.com/hutstep/entra-id-api-tester/internal/client"
+	"github.com/hutstep/entra-id-api-tester/internal/config"
+	"github.com/hutstep/entra-id-api-tester/internal/redact"
+)
+
+// Outcomes of a Continuous Access Evaluation (CAE) claims challenge, as
+// recorded in Result.ClaimsChallenge
+const (
+	// ClaimsChallengeSatisfied means the request was retried with a token
+	// carrying the requested claims, and the API did not challenge it again
+	ClaimsChallengeSatisfied = "satisfied"
+	// ClaimsChallengeUnsatisfied means the challenge could not be answered,
+	// or the API challenged the retry again
+	ClaimsChallengeUnsatisfied = "unsatisfied"
+)
+
+// claimsChallengeLabel labels the retry of a challenged request
+const claimsChallengeLabel = "claims challenge"
+
+// answerClaimsChallenge handles a 401 with a CAE claims challenge: it
+// acquires a token with the requested claims and retries the request once,
+// returning the retry's response. Any other response is returned as is.
+func (r *Runner) answerClaimsChallenge(ctx context.Context, endpoint *config.Endpoint, tokenProvider auth.TokenProvider, request *client.Request, response *client.Response, result *Result) (*client.Response, error) {
+	if response.StatusCode != http.StatusUnauthorized {
+		return response, nil
+	}
+	claims, ok, err := auth.ClaimsChallenge(auth.ParseChallenges(response.Headers.Values("WWW-Authenticate")))
+	if !ok {
+		return response, nil
+	}
+	result.ClaimsChallenge = ClaimsChallengeUnsatisfied
+	if err != nil {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("CAE: the API sent an invalid claims challenge: %v", err))
+		return response, nil
+	}
+	r.debug(endpoint, "received claims challenge", "claims", claims)
+
+	provider, ok := tokenProvider.(auth.ClaimsTokenProvider)
+	if !ok {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("CAE: the API sent a claims challenge, but %s cannot be re-acquired with the requested claims", tokenSource(endpoint, tokenProvider)))
+		return response, nil
+	}
+	token, err := provider.GetAccessTokenWithClaims(TokenContext(ctx, endpoint), endpoint.ClientID, endpoint.ClientSecret, endpoint.TokenTenantID(), endpoint.TokenScope(), claims)
+	if err != nil {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("CAE: failed to acquire a token with the challenged claims: %v", err))
+		return response, nil
+	}
+	redact.Register(token)
+
+	retry := *request
+	retry.AccessToken = token
+	r.debug(endpoint, "retrying with the challenged claims")
+	retried, err := r.send(ctx, endpoint, &retry, claimsChallengeLabel, result)
+	if err != nil {
+		return nil, err
+	}
+	if _, challenged, _ := auth.ClaimsChallenge(auth.ParseChallenges(retried.Headers.Values("WWW-Authenticate"))); challenged && retried.StatusCode == http.StatusUnauthorized {
+		result.Warnings = append(result.Warnings, "CAE: the API challenged the token with the requested claims again")
+		return retried, nil
+	}
+	result.ClaimsChallenge = ClaimsChallengeSatisfied
+	return retried, nil
+}
+
+// tokenSource describes where the endpoint's token comes from, naming the
+// type of tokenProvider when the configuration does not tell
+func tokenSource(endpoint *config.Endpoint, tokenProvider auth.TokenProvider) string {
+	switch {
+	case endpoint.StaticToken != "":
+		return "a static token"
+	case endpoint.ManagedIdentity:
+		return "a managed identity token"
+	case endpoint.FederatedCredential != "":
+		return "a federated credential token"
+	}
+	return fmt.Sprintf("a token of %T", tokenProvider)
+}
@@ -0,0 +1,107 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// claimsTokenProvider issues "token", or "cae-token" when claims are
+// requested
+type claimsTokenProvider struct {
+	err    error
+	claims string
+}
+
+func (p *claimsTokenProvider) GetAccessToken(ctx context.Context, clientID, clientSecret, tenantID, scope string) (string, error) {
+	return "token", nil
+}
+
+func (p *claimsTokenProvider) GetAccessTokenWithClaims(ctx context.Context, clientID, clientSecret, tenantID, scope, claims string) (string, error) {
+	p.claims = claims
+	return "cae-token", p.err
+}
+
+func TestRun_ClaimsChallenge(t *testing.T) {
+	// {"access_token":{"nbf":{"essential":true,"value":"1700000000"}}}
+	const challenge = `Bearer error="insufficient_claims", claims="eyJhY2Nlc3NfdG9rZW4iOnsibmJmIjp7ImVzc2VudGlhbCI6dHJ1ZSwidmFsdWUiOiIxNzAwMDAwMDAwIn19fQ=="`
+
+	tests := []struct {
+		name          string
+		acceptClaims  bool
+		providerErr   error
+		expectOutcome string
+		expectSuccess bool
+		expectRetry   bool
+	}{
+		{name: "claims accepted", acceptClaims: true, expectOutcome: ClaimsChallengeSatisfied, expectSuccess: true, expectRetry: true},
+		{name: "challenged again", expectOutcome: ClaimsChallengeUnsatisfied, expectRetry: true},
+		{name: "token with claims refused", acceptClaims: true, providerErr: errors.New("AADSTS50173"), expectOutcome: ClaimsChallengeUnsatisfied},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner, endpoint := newTestRunner(t, func(w http.ResponseWriter, r *http.Request) {
+				if tt.acceptClaims && r.Header.Get("Authorization") == "Bearer cae-token" {
+					return
+				}
+				w.Header().Set("WWW-Authenticate", challenge)
+				w.WriteHeader(http.StatusUnauthorized)
+			})
+			provider := &claimsTokenProvider{err: tt.providerErr}
+
+			result := runner.Run(context.Background(), endpoint, provider)
+
+			if result.ClaimsChallenge != tt.expectOutcome {
+				t.Errorf("Expected claims challenge outcome %q, got %q", tt.expectOutcome, result.ClaimsChallenge)
+			}
+			if result.Success() != tt.expectSuccess {
+				t.Errorf("Expected success %v, got %v", tt.expectSuccess, result.Err)
+			}
+			if provider.claims != `{"access_token":{"nbf":{"essential":true,"value":"1700000000"}}}` {
+				t.Errorf("Expected the challenged claims to be requested, got %q", provider.claims)
+			}
+			retried := len(result.Attempts) == 2 && result.Attempts[1].Label == claimsChallengeLabel
+			if retried != tt.expectRetry {
+				t.Errorf("Expected retry %v, got attempts %+v", tt.expectRetry, result.Attempts)
+			}
+		})
+	}
+}
+
+func TestRun_ClaimsChallengeWithoutClaimsSupport(t *testing.T) {
+	runner, endpoint := newTestRunner(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("WWW-Authenticate", `Bearer error="insufficient_claims", claims="e30="`)
+		w.WriteHeader(http.StatusUnauthorized)
+	})
+
+	result := runner.Run(context.Background(), endpoint, &fakeTokenProvider{token: "token"})
+
+	if result.ClaimsChallenge != ClaimsChallengeUnsatisfied || len(result.Warnings) != 1 {
+		t.Fatalf("Expected an unsatisfied challenge with a warning, got %q %v", result.ClaimsChallenge, result.Warnings)
+	}
+	if !strings.Contains(result.Warnings[0], "a token of *runner.fakeTokenProvider cannot be re-acquired") {
+		t.Errorf("Expected the warning to name the token provider, got %q", result.Warnings[0])
+	}
+	if !errors.Is(result.Err, ErrResponse) || len(result.Attempts) != 1 {
+		t.Errorf("Expected the 401 to fail the response without a retry, got %v", result.Err)
+	}
+}
+
+func TestRun_ClaimsChallengeManagedIdentity(t *testing.T) {
+	runner, endpoint := newTestRunner(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("WWW-Authenticate", `Bearer error="insufficient_claims", claims="e30="`)
+		w.WriteHeader(http.StatusUnauthorized)
+	})
+	runner.ManagedIdentity = &fakeTokenProvider{token: "mi-token"}
+	endpoint.ManagedIdentity = true
+	endpoint.ClientSecret = ""
+
+	result := runner.Run(context.Background(), endpoint, &claimsTokenProvider{})
+
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "a managed identity token cannot be re-acquired") {
+		t.Errorf("Expected a warning naming the managed identity, got %v", result.Warnings)
+	}
+}
@@ -0,0 +1,62 @@
+package runner
+
+import (
+	"strings"
+	"time"
+
+	"github.com/hutstep/entra-id-api-tester/internal/client"
+	"github.com/hutstep/entra-id-api-tester/internal/config"
+	"github.com/hutstep/entra-id-api-tester/internal/openapi"
+)
+
+// ContractError lists the ways a response violates its OpenAPI contract
+type ContractError struct {
+	Violations []string
+}
+
+// Error joins the violations with "; "
+func (e *ContractError) Error() string {
+	return strings.Join(e.Violations, "; ")
+}
+
+// spec returns the OpenAPI description at path, loading it on first use
+func (r *Runner) spec(path string) (*openapi.Spec, error) {
+	if spec, ok := r.specs[path]; ok {
+		return spec, nil
+	}
+	spec, err := openapi.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	if r.specs == nil {
+		r.specs = make(map[string]*openapi.Spec)
+	}
+	r.specs[path] = spec
+	return spec, nil
+}
+
+// checkContract validates an otherwise passing response against the
+// operation the endpoint's contract describes: its status must be
+// documented and its body must match the declared schema
+func (r *Runner) checkContract(endpoint *config.Endpoint, request *client.Request, response *client.Response, result *Result) *Result {
+	if result.Err != nil || endpoint.Contract == nil {
+		return result
+	}
+
+	phaseStart := time.Now()
+	spec, err := r.spec(endpoint.Contract.Spec)
+	if err != nil {
+		return result.fail(PhaseContract, phaseStart, "Contract check failed", err)
+	}
+	operation, err := spec.FindOperation(request.Method, request.URL, endpoint.Contract.OperationID)
+	if err != nil {
+		return result.fail(PhaseContract, phaseStart, "Contract check failed", err)
+	}
+	violations := spec.ValidateResponse(operation, response.StatusCode, response.Headers.Get("Content-Type"), response.Body)
+	if len(violations) > 0 {
+		return result.fail(PhaseContract, phaseStart, "Contract violated", &ContractError{Violations: violations})
+	}
+	r.debug(endpoint, "response conforms to contract", "operation", operation.Method+" "+operation.Path)
+	result.phase(PhaseContract, phaseStart, true)
+	return result
+}
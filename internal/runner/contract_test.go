@@ -0,0 +1,78 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hutstep/entra-id-api-tester/internal/config"
+)
+
+const contractSpec = `
+openapi: 3.0.3
+paths:
+  /:
+    get:
+      operationId: getItem
+      responses:
+        "200":
+          content:
+            application/json:
+              schema:
+                type: object
+                required: [id]
+                properties: {id: {type: integer}}
+`
+
+func TestRun_Contract(t *testing.T) {
+	spec := filepath.Join(t.TempDir(), "openapi.yaml")
+	if err := os.WriteFile(spec, []byte(contractSpec), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		operationID string
+		status      int
+		body        string
+		expectErr   error
+		violations  int
+	}{
+		{"conforming response", "", http.StatusOK, `{"id": 42}`, nil, 0},
+		{"schema violation", "getItem", http.StatusOK, `{"id": "42"}`, ErrContract, 1},
+		{"undocumented status", "", http.StatusAccepted, `{}`, ErrContract, 1},
+		{"unknown operation", "deleteItem", http.StatusOK, `{"id": 42}`, ErrContract, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner, endpoint := newTestRunner(t, respond(tt.status, tt.body))
+			endpoint.Contract = &config.Contract{Spec: spec, OperationID: tt.operationID}
+
+			result := runner.Run(context.Background(), endpoint, &fakeTokenProvider{token: "token"})
+
+			if !errors.Is(result.Err, tt.expectErr) || (tt.expectErr == nil) != (result.Err == nil) {
+				t.Fatalf("Expected %v, got %v", tt.expectErr, result.Err)
+			}
+			if tt.expectErr == nil {
+				if !result.Passed(PhaseContract) {
+					t.Errorf("Expected the contract phase to pass, got %+v", result.Phases)
+				}
+				return
+			}
+			if result.FailedPhase() != PhaseContract || !result.Passed(PhaseResponse) {
+				t.Errorf("Expected only the contract phase to fail, got %+v", result.Phases)
+			}
+			var contractErr *ContractError
+			if errors.As(result.Err, &contractErr) != (tt.violations > 0) {
+				t.Fatalf("Unexpected error type: %v", result.Err)
+			}
+			if contractErr != nil && len(contractErr.Violations) != tt.violations {
+				t.Errorf("Expected %d violation(s), got %q", tt.violations, contractErr.Violations)
+			}
+		})
+	}
+}
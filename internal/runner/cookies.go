@@ -0,0 +1,29 @@
+package runner
+
+import (
+	"net/http"
+	"net/http/cookiejar"
+
+	"golang.org/x/net/publicsuffix"
+
+	"github.com/hutstep/entra-id-api-tester/internal/config"
+)
+
+// cookieJar returns the endpoint's named cookie jar, creating it on first
+// use, or nil when the endpoint does not keep cookies. Jars last for the
+// runner's lifetime, so cookies carry over between chained endpoints.
+func (r *Runner) cookieJar(endpoint *config.Endpoint) http.CookieJar {
+	if endpoint.CookieJar == "" {
+		return nil
+	}
+	if jar, ok := r.cookieJars[endpoint.CookieJar]; ok {
+		return jar
+	}
+	// cookiejar.New never returns an error
+	jar, _ := cookiejar.New(&cookiejar.Options{PublicSuffixList: publicsuffix.List})
+	if r.cookieJars == nil {
+		r.cookieJars = make(map[string]http.CookieJar)
+	}
+	r.cookieJars[endpoint.CookieJar] = jar
+	return jar
+}
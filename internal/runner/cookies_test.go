@@ -0,0 +1,45 @@
+package runner
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/hutstep/entra-id-api-tester/internal/config"
+)
+
+func TestRun_CookieJar(t *testing.T) {
+	received := map[string]string{}
+	runner, endpoint := newTestRunner(t, func(w http.ResponseWriter, r *http.Request) {
+		received[r.URL.Path] = r.Header.Get("Cookie")
+		if r.URL.Path == "/session" {
+			http.SetCookie(w, &http.Cookie{Name: "ARRAffinity", Value: "instance-1", Path: "/"})
+		}
+	})
+	base := endpoint.URL
+	endpoints := []config.Endpoint{
+		{Name: "Session", URL: base + "/session", CookieJar: "gateway"},
+		{Name: "Orders", URL: base + "/orders", CookieJar: "gateway"},
+		{Name: "Other jar", URL: base + "/other", CookieJar: "partner"},
+		{Name: "No jar", URL: base + "/none"},
+	}
+
+	for i := range endpoints {
+		endpoints[i].Method = "GET"
+		if result := runner.Run(context.Background(), &endpoints[i], &fakeTokenProvider{token: "token"}); !result.Success() {
+			t.Fatalf("%s: expected success, got %v", endpoints[i].Name, result.Err)
+		}
+	}
+
+	expected := map[string]string{
+		"/session": "",
+		"/orders":  "ARRAffinity=instance-1",
+		"/other":   "",
+		"/none":    "",
+	}
+	for path, cookie := range expected {
+		if received[path] != cookie {
+			t.Errorf("%s: expected Cookie %q, got %q", path, cookie, received[path])
+		}
+	}
+}
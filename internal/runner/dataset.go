@@ -0,0 +1,107 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hutstep/entra-id-api-tester/internal/client"
+	"github.com/hutstep/entra-id-api-tester/internal/config"
+	"github.com/hutstep/entra-id-api-tester/internal/dataset"
+)
+
+// runDataRows sends the endpoint's request once per row of its data file,
+// with {{row.column}} placeholders filled in from the row, and checks every
+// response. Like a matrix, every row runs even when an earlier one failed
+// and the result holds one cell per row in Matrix. Captures and the checks
+// that follow a passing response use the first row.
+func (r *Runner) runDataRows(ctx context.Context, endpoint *config.Endpoint, token string, result *Result) *Result {
+	phaseStart := time.Now()
+	data, err := dataset.Load(endpoint.DataFile)
+	if err != nil {
+		return result.fail(PhasePrepare, phaseStart, "Failed to load data file", err)
+	}
+
+	cells := make([]MatrixCell, len(data.Rows))
+	requests := make([]*client.Request, len(data.Rows))
+	for i, row := range data.Rows {
+		cells[i].Label = fmt.Sprintf("row %d", i+1)
+		request, summary, err := r.buildRequest(ctx, endpoint, r.variables.WithRow(row), token)
+		if err != nil {
+			cells[i].fail(PhasePrepare, summary, err)
+			continue
+		}
+		requests[i] = request
+	}
+	defer func() { result.Matrix = cells }()
+	result.phase(PhasePrepare, phaseStart, allPassed(cells))
+
+	first := -1
+	for i := range requests {
+		if requests[i] != nil {
+			first = i
+			break
+		}
+	}
+	if first >= 0 {
+		warnInsecure(requests[first], result)
+		if !r.checkTLSPolicy(ctx, endpoint, requests[first], result) {
+			return result
+		}
+	}
+
+	phaseStart = time.Now()
+	responses := make([]*client.Response, len(requests))
+	for i, request := range requests {
+		if request == nil {
+			continue
+		}
+		r.debug(endpoint, "sending data row request", "row", i+1, "url", request.URL)
+		response, err := r.send(ctx, endpoint, request, cells[i].Label, result)
+		if err != nil {
+			cells[i].fail(PhaseConnect, "Request failed", err)
+			continue
+		}
+		cells[i].StatusCode = response.StatusCode
+		if err := response.CheckSignInPage(); err != nil {
+			cells[i].fail(PhaseAuth, "Redirected to interactive sign-in", err)
+			continue
+		}
+		responses[i] = response
+	}
+	result.phase(PhaseConnect, phaseStart, allPassed(cells))
+
+	// The result shows the first response, or the first that failed its
+	// checks
+	phaseStart = time.Now()
+	first = -1
+	failedChecks := false
+	for i, response := range responses {
+		if response == nil {
+			continue
+		}
+		checked := &Result{}
+		if summary, err := r.checkResponse(ctx, endpoint, response, checked); summary != "" {
+			cells[i].fail(PhaseResponse, summary, err)
+		}
+		if first < 0 {
+			first = i
+			result.StatusCode = response.StatusCode
+			result.Warnings = append(result.Warnings, response.DeprecationWarnings()...)
+			result.Assertions = checked.Assertions
+		}
+		if !cells[i].Passed() && !failedChecks {
+			result.StatusCode = response.StatusCode
+			result.Assertions = checked.Assertions
+			failedChecks = true
+		}
+	}
+
+	if err := matrixFailure("data", cells); err != nil {
+		return failMatrix(result, phaseStart, "row", cells, err)
+	}
+	r.debug(endpoint, "data rows verified", "rows", len(cells))
+
+	result = r.capture(endpoint, responses[first], phaseStart, result)
+	return r.finish(ctx, endpoint, requests[first], responses[first], result)
+}
@@ -0,0 +1,103 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hutstep/entra-id-api-tester/internal/config"
+)
+
+// writeDataFile writes a data file into a temporary directory
+func writeDataFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write data file: %v", err)
+	}
+	return path
+}
+
+func TestRun_DataFile(t *testing.T) {
+	var received []string
+	runner, endpoint := newTestRunner(t, func(w http.ResponseWriter, r *http.Request) {
+		received = append(received, r.URL.Path)
+		if r.URL.Path == "/users/mallory" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_, _ = w.Write([]byte(`{"id": "` + r.URL.Path + `"}`))
+	})
+	endpoint.URL += "/users/{{row.name}}"
+	endpoint.DataFile = writeDataFile(t, "users.csv", "name\nalice\nmallory\nbob\n")
+	endpoint.Captures = []config.Capture{{Name: "id", JSONPath: "$.id"}}
+
+	result := runner.Run(context.Background(), endpoint, &fakeTokenProvider{token: "token"})
+
+	if len(received) != 3 {
+		t.Fatalf("Expected one request per row, got %v", received)
+	}
+	if !errors.Is(result.Err, ErrResponse) {
+		t.Fatalf("Expected the rejected row to fail the response phase, got %v", result.Err)
+	}
+	var matrixErr *MatrixError
+	if !errors.As(result.Err, &matrixErr) || len(matrixErr.Failures) != 1 {
+		t.Fatalf("Expected one failed row, got %v", result.Err)
+	}
+	if len(result.Matrix) != 3 || result.Matrix[1].Label != "row 2" || result.Matrix[1].Passed() || !result.Matrix[2].Passed() {
+		t.Errorf("Expected row 2 to fail, got %+v", result.Matrix)
+	}
+	if result.StatusCode != http.StatusForbidden {
+		t.Errorf("Expected the failed row's status 403, got %d", result.StatusCode)
+	}
+}
+
+func TestRun_DataFileCapturesFirstRow(t *testing.T) {
+	runner, endpoint := newTestRunner(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"id": "` + r.URL.Query().Get("name") + `"}`))
+	})
+	endpoint.URL += "/users?name={{row.name}}"
+	endpoint.DataFile = writeDataFile(t, "users.json", `[{"name": "alice"}, {"name": "bob"}]`)
+	endpoint.Captures = []config.Capture{{Name: "id", JSONPath: "$.id"}}
+
+	result := runner.Run(context.Background(), endpoint, &fakeTokenProvider{token: "token"})
+
+	if !result.Success() {
+		t.Fatalf("Expected success, got %v", result.Err)
+	}
+	if value, _ := runner.variables.Get("id"); value != "alice" {
+		t.Errorf("Expected the first row's id to be captured, got %q", value)
+	}
+}
+
+func TestRun_DataFileFailures(t *testing.T) {
+	tests := []struct {
+		name      string
+		url       string
+		content   string
+		expectErr error
+	}{
+		{name: "unknown column", url: "/users/{{row.email}}", content: "name\nalice\n", expectErr: ErrPrepare},
+		{name: "no rows", url: "/users/{{row.name}}", content: "name\n", expectErr: ErrPrepare},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner, endpoint := newTestRunner(t, respond(http.StatusOK, `{}`))
+			endpoint.URL += tt.url
+			endpoint.DataFile = writeDataFile(t, "users.csv", tt.content)
+
+			result := runner.Run(context.Background(), endpoint, &fakeTokenProvider{token: "token"})
+
+			if !errors.Is(result.Err, tt.expectErr) {
+				t.Errorf("Expected error to match %v, got %v", tt.expectErr, result.Err)
+			}
+			if got := result.FailedPhase(); got != PhasePrepare {
+				t.Errorf("Expected failed phase %s, got %s", PhasePrepare, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package runner
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/hutstep/entra-id-api-tester/internal/auth"
+	"github.com/hutstep/entra-id-api-tester/internal/config"
+)
+
+// diagnoseToken explains a 401 or 403 from the claims of the token that
+// was sent, e.g. an audience for another API than the one called. A token
+// that is not a JWT yields no diagnoses.
+func diagnoseToken(endpoint *config.Endpoint, requestURL, token string, status int, now time.Time) []string {
+	if status != http.StatusUnauthorized && status != http.StatusForbidden {
+		return nil
+	}
+	claims, err := auth.DecodePayload(token)
+	if err != nil {
+		return nil
+	}
+
+	var diagnoses []string
+	audiences := claimStrings(claims["aud"])
+	if len(audiences) == 0 {
+		diagnoses = append(diagnoses, "the token has no aud (audience) claim")
+	}
+	for _, audience := range audiences {
+		if diagnosis := diagnoseAudience(endpoint, requestURL, audience); diagnosis != "" {
+			diagnoses = append(diagnoses, diagnosis)
+		}
+	}
+
+	if exp, ok := claims["exp"].(float64); ok && now.After(time.Unix(int64(exp), 0)) {
+		diagnoses = append(diagnoses, fmt.Sprintf("the token expired at %s", time.Unix(int64(exp), 0).UTC().Format(time.RFC3339)))
+	}
+
+	roles := claimStrings(claims["roles"])
+	scopes := strings.Fields(strings.Join(claimStrings(claims["scp"]), " "))
+	switch {
+	case len(roles) == 0 && len(scopes) == 0:
+		diagnoses = append(diagnoses, "the token has no roles or scp claim: the client has no permissions (app roles) on the API, or admin consent was not granted")
+	case status == http.StatusForbidden && len(roles) > 0:
+		diagnoses = append(diagnoses, fmt.Sprintf("the token grants roles %s; check that the API requires one of them", strings.Join(roles, ", ")))
+	case status == http.StatusForbidden:
+		diagnoses = append(diagnoses, fmt.Sprintf("the token grants scopes %s; check that the API requires one of them", strings.Join(scopes, ", ")))
+	}
+
+	if names, ok := claims["_claim_names"].(map[string]interface{}); (ok && names["groups"] != nil) || claims["hasgroups"] == true {
+		diagnoses = append(diagnoses, "the token has a groups overage instead of a groups claim; an API that authorizes by groups must look them up with a Microsoft Graph call")
+	}
+	return diagnoses
+}
+
+// diagnoseAudience compares a token audience with the called host and the
+// configured scope. Application ID URIs such as api://orders and bare
+// application IDs cannot be compared with a host, only with each other.
+func diagnoseAudience(endpoint *config.Endpoint, requestURL, audience string) string {
+	if audienceHost := httpsHost(audience); audienceHost != "" {
+		if host := httpsHost(requestURL); host != "" && !strings.EqualFold(audienceHost, host) {
+			return fmt.Sprintf("the token audience is %s but the request goes to %s; is the scope %s for this API?", audienceHost, host, endpoint.Scope)
+		}
+	}
+
+	resource := scopeResource(endpoint.Scope)
+	comparable := (guidPattern.MatchString(audience) && guidPattern.MatchString(strings.TrimPrefix(resource, "api://"))) ||
+		(strings.HasPrefix(audience, "api://") && strings.HasPrefix(resource, "api://")) ||
+		(httpsHost(audience) != "" && httpsHost(resource) != "")
+	if comparable && !sameResource(audience, resource) {
+		return fmt.Sprintf("the token audience %s does not match the scope's resource %s", audience, resource)
+	}
+	return ""
+}
+
+// httpsHost returns the host of an http or https URL, or ""
+func httpsHost(raw string) string {
+	parsed, err := url.Parse(raw)
+	if err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") {
+		return ""
+	}
+	return parsed.Hostname()
+}
+
+// claimStrings returns a string or string array claim as a slice
+func claimStrings(claim interface{}) []string {
+	switch value := claim.(type) {
+	case string:
+		if value == "" {
+			return nil
+		}
+		return []string{value}
+	case []interface{}:
+		var values []string
+		for _, item := range value {
+			if s, ok := item.(string); ok && s != "" {
+				values = append(values, s)
+			}
+		}
+		return values
+	default:
+		return nil
+	}
+}
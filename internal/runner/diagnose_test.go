@@ -0,0 +1,102 @@
+package runner
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hutstep/entra-id-api-tester/internal/config"
+)
+
+// unsignedToken encodes claims as a JWT with a dummy signature
+func unsignedToken(t *testing.T, claims map[string]interface{}) string {
+	t.Helper()
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatalf("Failed to encode claims: %v", err)
+	}
+	return "eyJhbGciOiJSUzI1NiJ9." + base64.RawURLEncoding.EncodeToString(payload) + ".c2lnbmF0dXJl"
+}
+
+func TestDiagnoseToken(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	const appID = "11111111-1111-1111-1111-111111111111"
+
+	tests := []struct {
+		name     string
+		scope    string
+		claims   map[string]interface{}
+		status   int
+		expected []string
+	}{
+		{
+			name:     "Graph token for a custom API",
+			scope:    "https://graph.microsoft.com/.default",
+			claims:   map[string]interface{}{"aud": "https://graph.microsoft.com", "roles": []string{"User.Read.All"}},
+			status:   http.StatusUnauthorized,
+			expected: []string{"the token audience is graph.microsoft.com but the request goes to api.contoso.com; is the scope https://graph.microsoft.com/.default for this API?"},
+		},
+		{
+			name:     "audience of another app",
+			scope:    "api://" + appID + "/.default",
+			claims:   map[string]interface{}{"aud": "22222222-2222-2222-2222-222222222222", "roles": []string{"Orders.Read"}},
+			status:   http.StatusUnauthorized,
+			expected: []string{"the token audience 22222222-2222-2222-2222-222222222222 does not match the scope's resource api://" + appID},
+		},
+		{
+			name:     "no permissions",
+			scope:    "api://" + appID + "/.default",
+			claims:   map[string]interface{}{"aud": appID},
+			status:   http.StatusForbidden,
+			expected: []string{"the token has no roles or scp claim: the client has no permissions (app roles) on the API, or admin consent was not granted"},
+		},
+		{
+			name:     "missing role",
+			scope:    "api://orders/.default",
+			claims:   map[string]interface{}{"aud": "api://orders", "roles": []string{"Orders.Read"}, "exp": now.Add(-time.Minute).Unix()},
+			status:   http.StatusForbidden,
+			expected: []string{"the token expired at 2023-11-14T22:12:20Z", "the token grants roles Orders.Read; check that the API requires one of them"},
+		},
+		{
+			name:   "groups overage",
+			scope:  "api://orders/.default",
+			claims: map[string]interface{}{"aud": "api://orders", "roles": []string{"Orders.Read"}, "_claim_names": map[string]string{"groups": "src1"}},
+			status: http.StatusForbidden,
+			expected: []string{
+				"the token grants roles Orders.Read; check that the API requires one of them",
+				"the token has a groups overage instead of a groups claim; an API that authorizes by groups must look them up with a Microsoft Graph call",
+			},
+		},
+		{
+			name:   "not an auth failure",
+			scope:  "api://orders/.default",
+			claims: map[string]interface{}{"aud": "https://graph.microsoft.com"},
+			status: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			endpoint := &config.Endpoint{Scope: tt.scope}
+			got := diagnoseToken(endpoint, "https://api.contoso.com/orders", unsignedToken(t, tt.claims), tt.status, now)
+			if strings.Join(got, "\n") != strings.Join(tt.expected, "\n") {
+				t.Errorf("diagnoseToken() = %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRun_Diagnoses(t *testing.T) {
+	runner, endpoint := newTestRunner(t, respond(http.StatusForbidden, `{}`))
+	token := unsignedToken(t, map[string]interface{}{"aud": "api://example"})
+
+	result := runner.Run(context.Background(), endpoint, &fakeTokenProvider{token: token})
+
+	if len(result.Diagnoses) != 1 || !strings.Contains(result.Diagnoses[0], "no roles or scp claim") {
+		t.Errorf("Expected a missing permissions diagnosis, got %q", result.Diagnoses)
+	}
+}
@@ -0,0 +1,43 @@
+package runner
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hutstep/entra-id-api-tester/internal/client"
+	"github.com/hutstep/entra-id-api-tester/internal/config"
+)
+
+// saveResponse writes the response body to the endpoint's saveResponseTo
+// file, if any, creating its directory
+func saveResponse(endpoint *config.Endpoint, response *client.Response) error {
+	if endpoint.SaveResponseTo == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(endpoint.SaveResponseTo), 0o750); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", endpoint.SaveResponseTo, err)
+	}
+	if err := os.WriteFile(endpoint.SaveResponseTo, response.Body, 0o600); err != nil {
+		return fmt.Errorf("failed to save response: %w", err)
+	}
+	return nil
+}
+
+// verifyDownload checks the size and SHA-256 digest of the response body
+// against the endpoint's expectations
+func verifyDownload(endpoint *config.Endpoint, response *client.Response) error {
+	if endpoint.ExpectedContentLength > 0 && len(response.Body) != endpoint.ExpectedContentLength {
+		return fmt.Errorf("expected %d bytes, got %d", endpoint.ExpectedContentLength, len(response.Body))
+	}
+	if endpoint.ExpectedSHA256 != "" {
+		digest := sha256.Sum256(response.Body)
+		if actual := hex.EncodeToString(digest[:]); !strings.EqualFold(actual, endpoint.ExpectedSHA256) {
+			return fmt.Errorf("expected SHA-256 %s, got %s", strings.ToLower(endpoint.ExpectedSHA256), actual)
+		}
+	}
+	return nil
+}
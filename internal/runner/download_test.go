@@ -0,0 +1,58 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRun_Download(t *testing.T) {
+	archive := []byte{'P', 'K', 0x03, 0x04, 0x00, 0xff}
+	digest := "32c5298373643a70f302359fcc0d482269cfe8feedfa635f874bd546a71be65c"
+	other := "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+
+	tests := []struct {
+		name      string
+		sha256    string
+		length    int
+		expectErr string
+	}{
+		{name: "no checks"},
+		{name: "matching length", length: len(archive)},
+		{name: "wrong length", length: 10, expectErr: "Download verification failed: expected 10 bytes, got 6"},
+		{name: "matching digest", sha256: digest, length: len(archive)},
+		{name: "wrong digest", sha256: other, expectErr: "Download verification failed: expected SHA-256 " + other + ", got " + digest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner, endpoint := newTestRunner(t, func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/zip")
+				_, _ = w.Write(archive)
+			})
+			endpoint.SaveResponseTo = filepath.Join(t.TempDir(), "exports", "export.zip")
+			endpoint.ExpectedSHA256 = tt.sha256
+			endpoint.ExpectedContentLength = tt.length
+
+			result := runner.Run(context.Background(), endpoint, &fakeTokenProvider{token: "token"})
+
+			saved, err := os.ReadFile(endpoint.SaveResponseTo)
+			if err != nil || !bytes.Equal(saved, archive) {
+				t.Errorf("Expected the response to be saved, got %v (%v)", saved, err)
+			}
+			if tt.expectErr == "" {
+				if !result.Success() {
+					t.Errorf("Expected success, got %v", result.Err)
+				}
+				return
+			}
+			if !errors.Is(result.Err, ErrResponse) || result.Err.Error() != tt.expectErr {
+				t.Errorf("Expected response failure %q, got %v", tt.expectErr, result.Err)
+			}
+		})
+	}
+}
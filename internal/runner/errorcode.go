@@ -0,0 +1,128 @@
+package runner
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+
+	"github.com/hutstep/entra-id-api-tester/internal/auth"
+)
+
+// ErrorCode is the cause of a failed endpoint test, stable across releases
+// so that automation can alert on and route failures without parsing
+// their messages
+type ErrorCode string
+
+const (
+	// CodeAuthInvalidSecret is a client secret Entra ID rejected as wrong
+	// or expired
+	CodeAuthInvalidSecret ErrorCode = "AuthInvalidSecret"
+	// CodeAuthThrottled is a token request Entra ID throttled
+	CodeAuthThrottled ErrorCode = "AuthThrottled"
+	// CodeAuthFailed is any other failure to acquire a valid token
+	CodeAuthFailed ErrorCode = "AuthFailed"
+	// CodePrepareFailed is a request that could not be built
+	CodePrepareFailed ErrorCode = "PrepareFailed"
+	// CodeDNSFailure is a host name that could not be resolved
+	CodeDNSFailure ErrorCode = "DNSFailure"
+	// CodeTLSFailure is a failed TLS handshake or a violated TLS policy
+	CodeTLSFailure ErrorCode = "TLSFailure"
+	// CodeTimeout is a token request or API request that timed out
+	CodeTimeout ErrorCode = "Timeout"
+	// CodeConnectionFailed is any other failure to get a response
+	CodeConnectionFailed ErrorCode = "ConnectionFailed"
+	// CodeNon2xx is a response with an unexpected non-2xx status
+	CodeNon2xx ErrorCode = "Non2xx"
+	// CodeAssertionFailed is a response that failed its assertions
+	CodeAssertionFailed ErrorCode = "AssertionFailed"
+	// CodeResponseInvalid is any other response check that failed, e.g. a
+	// missing trace echo or a body over the size limit
+	CodeResponseInvalid ErrorCode = "ResponseInvalid"
+	// CodeContractViolated is a response that violates the endpoint's
+	// contract or golden file
+	CodeContractViolated ErrorCode = "ContractViolated"
+	// CodeTooSlow is a response time or latency objective that was missed
+	CodeTooSlow ErrorCode = "TooSlow"
+	// CodeNotProtected is an endpoint that accepted invalid credentials
+	CodeNotProtected ErrorCode = "NotProtected"
+	// CodeSkipped is an endpoint skipped because a dependency failed
+	CodeSkipped ErrorCode = "Skipped"
+)
+
+// ErrorCode classifies the failure of the result, or returns "" when it
+// passed
+func (r *Result) ErrorCode() ErrorCode {
+	if r.Err == nil {
+		return ""
+	}
+	if errors.Is(r.Err, ErrSkipped) {
+		return CodeSkipped
+	}
+
+	switch r.FailedPhase() {
+	case PhaseAuth:
+		switch {
+		case auth.IsInvalidSecret(r.Err):
+			return CodeAuthInvalidSecret
+		case auth.IsThrottled(r.Err):
+			return CodeAuthThrottled
+		case isTimeout(r.Err):
+			return CodeTimeout
+		}
+		return CodeAuthFailed
+	case PhasePrepare:
+		return CodePrepareFailed
+	case PhaseConnect:
+		return connectErrorCode(r.Err)
+	case PhaseContract:
+		return CodeContractViolated
+	case PhasePerformance:
+		return CodeTooSlow
+	case PhaseSecurity:
+		return CodeNotProtected
+	}
+
+	var assertionErr *AssertionError
+	switch {
+	case errors.As(r.Err, &assertionErr):
+		return CodeAssertionFailed
+	case r.StatusCode != 0 && (r.StatusCode < 200 || r.StatusCode > 299):
+		return CodeNon2xx
+	}
+	return CodeResponseInvalid
+}
+
+// connectErrorCode classifies a failure to get a response
+func connectErrorCode(err error) ErrorCode {
+	var (
+		dnsErr           *net.DNSError
+		verificationErr  *tls.CertificateVerificationError
+		recordHeaderErr  tls.RecordHeaderError
+		alertErr         tls.AlertError
+		unknownAuthority x509.UnknownAuthorityError
+		hostnameErr      x509.HostnameError
+		invalidCertErr   x509.CertificateInvalidError
+		assertionErr     *AssertionError
+	)
+	switch {
+	case errors.As(err, &dnsErr) && !dnsErr.IsTimeout:
+		return CodeDNSFailure
+	case errors.As(err, &verificationErr), errors.As(err, &recordHeaderErr), errors.As(err, &alertErr),
+		errors.As(err, &unknownAuthority), errors.As(err, &hostnameErr), errors.As(err, &invalidCertErr),
+		errors.As(err, &assertionErr):
+		// The only assertions of the connect phase are the TLS policy's
+		return CodeTLSFailure
+	case isTimeout(err):
+		return CodeTimeout
+	}
+	return CodeConnectionFailed
+}
+
+// isTimeout reports whether err is a deadline that passed, e.g. the
+// client's request timeout
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout())
+}
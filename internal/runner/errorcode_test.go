@@ -0,0 +1,50 @@
+package runner
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+)
+
+func TestResult_ErrorCode(t *testing.T) {
+	fail := func(phase Phase, err error) error {
+		return &PhaseError{Phase: phase, Summary: "failed", Err: err}
+	}
+	tests := []struct {
+		name     string
+		result   Result
+		expected ErrorCode
+	}{
+		{name: "passed", result: Result{StatusCode: 200}},
+		{name: "skipped", result: Result{Err: &SkipError{Dependency: "login"}}, expected: CodeSkipped},
+		{name: "invalid secret", result: Result{Err: fail(PhaseAuth, errors.New("AADSTS7000215: Invalid client secret provided"))}, expected: CodeAuthInvalidSecret},
+		{name: "expired secret", result: Result{Err: fail(PhaseAuth, errors.New("AADSTS7000222: The provided client secret keys are expired"))}, expected: CodeAuthInvalidSecret},
+		{name: "auth throttled", result: Result{Err: fail(PhaseAuth, errors.New("AADSTS50196: The server terminated an operation because it encountered a client request loop"))}, expected: CodeAuthThrottled},
+		{name: "auth timeout", result: Result{Err: fail(PhaseAuth, fmt.Errorf("failed to acquire token: %w", context.DeadlineExceeded))}, expected: CodeTimeout},
+		{name: "auth failed", result: Result{Err: fail(PhaseAuth, errors.New("AADSTS700016: Application not found"))}, expected: CodeAuthFailed},
+		{name: "prepare", result: Result{Err: fail(PhasePrepare, errors.New("undefined variable"))}, expected: CodePrepareFailed},
+		{name: "dns", result: Result{Err: fail(PhaseConnect, &net.DNSError{Err: "no such host", Name: "api.invalid", IsNotFound: true})}, expected: CodeDNSFailure},
+		{name: "dns timeout", result: Result{Err: fail(PhaseConnect, &net.DNSError{Err: "i/o timeout", Name: "api.example.com", IsTimeout: true})}, expected: CodeTimeout},
+		{name: "tls", result: Result{Err: fail(PhaseConnect, fmt.Errorf("failed to execute request: %w", x509.UnknownAuthorityError{}))}, expected: CodeTLSFailure},
+		{name: "tls policy", result: Result{Err: fail(PhaseConnect, &AssertionError{Failures: []error{errors.New("negotiated TLS 1.2")}})}, expected: CodeTLSFailure},
+		{name: "timeout", result: Result{Err: fail(PhaseConnect, fmt.Errorf("failed to execute request: %w", context.DeadlineExceeded))}, expected: CodeTimeout},
+		{name: "refused", result: Result{Err: fail(PhaseConnect, errors.New("connection refused"))}, expected: CodeConnectionFailed},
+		{name: "non-2xx", result: Result{StatusCode: 503, Err: fail(PhaseResponse, nil)}, expected: CodeNon2xx},
+		{name: "assertion", result: Result{StatusCode: 500, Err: fail(PhaseResponse, &AssertionError{Failures: []error{errors.New("status")}})}, expected: CodeAssertionFailed},
+		{name: "other response check", result: Result{StatusCode: 200, Err: fail(PhaseResponse, errors.New("no traceresponse header"))}, expected: CodeResponseInvalid},
+		{name: "contract", result: Result{Err: fail(PhaseContract, nil)}, expected: CodeContractViolated},
+		{name: "performance", result: Result{Err: fail(PhasePerformance, nil)}, expected: CodeTooSlow},
+		{name: "security", result: Result{Err: fail(PhaseSecurity, nil)}, expected: CodeNotProtected},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code := tt.result.ErrorCode(); code != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, code)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package runner
+
+import (
+	"errors"
+	"time"
+
+	"github.com/hutstep/entra-id-api-tester/internal/client"
+	"github.com/hutstep/entra-id-api-tester/internal/config"
+	"github.com/hutstep/entra-id-api-tester/internal/golden"
+)
+
+// checkGolden compares an otherwise passing response body with the
+// endpoint's golden file or, with UpdateGolden, records the body as the
+// golden file. A mismatch fails the contract phase, as it reveals a change
+// of the API's responses.
+func (r *Runner) checkGolden(endpoint *config.Endpoint, response *client.Response, result *Result) *Result {
+	if result.Err != nil || endpoint.GoldenFile == "" {
+		return result
+	}
+
+	phaseStart := time.Now()
+	if r.UpdateGolden {
+		changed, err := golden.Update(endpoint.GoldenFile, response.Body, endpoint.GoldenIgnore)
+		if err != nil {
+			return result.fail(PhaseContract, phaseStart, "Golden file update failed", err)
+		}
+		if changed {
+			result.Warnings = append(result.Warnings, "Golden file "+endpoint.GoldenFile+" updated")
+		}
+		result.phase(PhaseContract, phaseStart, true)
+		return result
+	}
+
+	if err := golden.Check(endpoint.GoldenFile, response.Body, endpoint.GoldenIgnore); err != nil {
+		var mismatch *golden.MismatchError
+		if errors.As(err, &mismatch) {
+			r.debug(endpoint, "response differs from golden file", "file", endpoint.GoldenFile, "diff", mismatch.Diff)
+			return result.fail(PhaseContract, phaseStart, "Golden file mismatch", err)
+		}
+		return result.fail(PhaseContract, phaseStart, "Golden file check failed", err)
+	}
+	r.debug(endpoint, "response matches golden file", "file", endpoint.GoldenFile)
+	result.phase(PhaseContract, phaseStart, true)
+	return result
+}
@@ -0,0 +1,40 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"testing"
+)
+
+func TestRun_GoldenFile(t *testing.T) {
+	body := `{"id": "1", "name": "widget"}`
+	runner, endpoint := newTestRunner(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(body))
+	})
+	endpoint.GoldenFile = filepath.Join(t.TempDir(), "widget.json")
+	endpoint.GoldenIgnore = []string{"id"}
+
+	if result := runner.Run(context.Background(), endpoint, &fakeTokenProvider{token: "token"}); !errors.Is(result.Err, ErrContract) {
+		t.Fatalf("Expected a missing golden file to fail the contract phase, got %v", result.Err)
+	}
+
+	runner.UpdateGolden = true
+	result := runner.Run(context.Background(), endpoint, &fakeTokenProvider{token: "token"})
+	if !result.Success() || len(result.Warnings) != 1 {
+		t.Fatalf("Expected the golden file to be recorded with a warning, got %v, %v", result.Err, result.Warnings)
+	}
+
+	runner.UpdateGolden = false
+	body = `{"id": "2", "name": "widget"}`
+	if result := runner.Run(context.Background(), endpoint, &fakeTokenProvider{token: "token"}); !result.Success() {
+		t.Errorf("Expected a change of an ignored field to pass, got %v", result.Err)
+	}
+
+	body = `{"id": "2", "name": "gadget"}`
+	result = runner.Run(context.Background(), endpoint, &fakeTokenProvider{token: "token"})
+	if !errors.Is(result.Err, ErrContract) || result.FailedPhase() != PhaseContract {
+		t.Errorf("Expected a changed body to fail the contract phase, got %v", result.Err)
+	}
+}
@@ -0,0 +1,70 @@
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hutstep/entra-id-api-tester/internal/config"
+	"github.com/hutstep/entra-id-api-tester/internal/vars"
+)
+
+// graphQLBody builds the JSON request body of a GraphQL operation,
+// expanding the placeholders of its variables
+func graphQLBody(variables *vars.Store, operation *config.GraphQL) (map[string]interface{}, error) {
+	body := map[string]interface{}{"query": operation.Query}
+	if operation.OperationName != "" {
+		body["operationName"] = operation.OperationName
+	}
+	if operation.Variables != nil {
+		expanded, err := variables.ExpandBody(operation.Variables)
+		if err != nil {
+			return nil, err
+		}
+		body["variables"] = expanded
+	}
+	return body, nil
+}
+
+// GraphQLError reports the errors of a GraphQL response, which APIs
+// typically return with a 200 status
+type GraphQLError struct {
+	// Messages are the errors' messages, prefixed with their path if any
+	Messages []string
+}
+
+// Error lists the messages
+func (e *GraphQLError) Error() string {
+	return fmt.Sprintf("%d error(s): %s", len(e.Messages), strings.Join(e.Messages, "; "))
+}
+
+// checkGraphQLErrors returns a *GraphQLError when the response body is a
+// GraphQL response with a non-empty errors array, and nil otherwise
+func checkGraphQLErrors(body []byte) error {
+	var response struct {
+		Errors []struct {
+			Message string        `json:"message"`
+			Path    []interface{} `json:"path"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil || len(response.Errors) == 0 {
+		return nil
+	}
+
+	messages := make([]string, len(response.Errors))
+	for i, graphQLErr := range response.Errors {
+		message := graphQLErr.Message
+		if message == "" {
+			message = "(no message)"
+		}
+		if len(graphQLErr.Path) > 0 {
+			path := make([]string, len(graphQLErr.Path))
+			for j, segment := range graphQLErr.Path {
+				path[j] = fmt.Sprint(segment)
+			}
+			message = strings.Join(path, ".") + ": " + message
+		}
+		messages[i] = message
+	}
+	return &GraphQLError{Messages: messages}
+}
@@ -0,0 +1,97 @@
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/hutstep/entra-id-api-tester/internal/config"
+)
+
+func TestRun_GraphQL(t *testing.T) {
+	tests := []struct {
+		name       string
+		response   string
+		assertions []config.Assertion
+		expectErr  string
+	}{
+		{
+			name:     "data",
+			response: `{"data":{"user":{"id":"42"}}}`,
+		},
+		{
+			name:      "errors with a 200",
+			response:  `{"data":{"user":null},"errors":[{"message":"Not authorized","path":["user",0,"email"]},{"message":"Rate limited"}]}`,
+			expectErr: "GraphQL errors: 2 error(s): user.0.email: Not authorized; Rate limited",
+		},
+		{
+			name:       "errors checked by an assertion",
+			response:   `{"errors":[{"message":"Not authorized","extensions":{"code":"FORBIDDEN"}}]}`,
+			assertions: []config.Assertion{{JSONPath: "$.errors[0].extensions.code", Equals: "FORBIDDEN"}},
+		},
+		{
+			name:       "errors checked by a CEL expression",
+			response:   `{"errors":[{"message":"Not authorized"}]}`,
+			assertions: []config.Assertion{{Not: &config.Assertion{CEL: "!has(json.errors)"}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var received map[string]interface{}
+			runner, endpoint := newTestRunner(t, func(w http.ResponseWriter, r *http.Request) {
+				_ = json.NewDecoder(r.Body).Decode(&received)
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(tt.response))
+			})
+			runner.variables.Set("userId", "42")
+			endpoint.Method = "POST"
+			endpoint.GraphQL = &config.GraphQL{
+				Query:         "query GetUser($id: ID!) { user(id: $id) { id } }",
+				OperationName: "GetUser",
+				Variables:     map[string]interface{}{"id": "{{vars.userId}}"},
+			}
+			endpoint.Assertions = tt.assertions
+
+			result := runner.Run(context.Background(), endpoint, &fakeTokenProvider{token: "token"})
+
+			expectedBody := map[string]interface{}{
+				"query":         endpoint.GraphQL.Query,
+				"operationName": "GetUser",
+				"variables":     map[string]interface{}{"id": "42"},
+			}
+			if !reflect.DeepEqual(received, expectedBody) {
+				t.Errorf("Expected request body %v, got %v", expectedBody, received)
+			}
+			if tt.expectErr == "" {
+				if !result.Success() {
+					t.Errorf("Expected success, got %v", result.Err)
+				}
+				return
+			}
+			var graphQLErr *GraphQLError
+			if !errors.Is(result.Err, ErrResponse) || !errors.As(result.Err, &graphQLErr) || result.Err.Error() != tt.expectErr {
+				t.Errorf("Expected response failure %q, got %v", tt.expectErr, result.Err)
+			}
+		})
+	}
+}
+
+func TestCheckGraphQLErrors(t *testing.T) {
+	tests := map[string]bool{
+		`{"data":{}}`:                          false,
+		`{"data":{},"errors":[]}`:              false,
+		`{"errors":null}`:                      false,
+		`not json`:                             false,
+		`{"errors":[{"message":"boom"}]}`:      true,
+		`{"data":null,"errors":[{"path":[]}]}`: true,
+	}
+	for body, expected := range tests {
+		if got := checkGraphQLErrors([]byte(body)) != nil; got != expected {
+			t.Errorf("checkGraphQLErrors(%s) = %v, expected %v", body, got, expected)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/hutstep/entra-id-api-tester/internal/client"
+	"github.com/hutstep/entra-id-api-tester/internal/config"
+	"github.com/hutstep/entra-id-api-tester/internal/hook"
+)
+
+// postResponseHookDescription describes the post-response hook's verdict
+// among the endpoint's assertions
+const postResponseHookDescription = "postResponse hook"
+
+// hookTimeout returns how long each of the endpoint's hooks may run
+func hookTimeout(endpoint *config.Endpoint) time.Duration {
+	return time.Duration(endpoint.Hooks.TimeoutMs) * time.Millisecond
+}
+
+// runPreRequestHook lets the endpoint's pre-request hook, if any, rewrite
+// the method, URL, headers and body of the request. Headers and a body the
+// hook prints replace the request's.
+func (r *Runner) runPreRequestHook(ctx context.Context, endpoint *config.Endpoint, request *client.Request) error {
+	if endpoint.Hooks == nil || len(endpoint.Hooks.PreRequest) == 0 {
+		return nil
+	}
+
+	hookRequest := hook.Request{
+		Body:    request.Body,
+		Headers: request.Headers,
+		Method:  request.Method,
+		URL:     request.URL,
+	}
+	if hookRequest.Headers == nil {
+		hookRequest.Headers = make(map[string]string)
+	}
+	// Decoding into the hook's input would merge the maps it prints into
+	// the request's, which keeps the headers and body keys it removed
+	var rewritten hook.Request
+	if err := hook.Run(ctx, endpoint.Hooks.PreRequest, hookTimeout(endpoint), endpoint.Name, hookRequest, &rewritten); err != nil {
+		return err
+	}
+
+	if rewritten.Body != nil {
+		request.Body = rewritten.Body
+	}
+	if rewritten.Headers != nil {
+		request.Headers = rewritten.Headers
+	}
+	if rewritten.Method != "" {
+		request.Method = rewritten.Method
+	}
+	if rewritten.URL != "" {
+		request.URL = rewritten.URL
+	}
+	r.debug(endpoint, "pre-request hook applied", "method", request.Method, "url", request.URL)
+	return nil
+}
+
+// runPostResponseHook passes the response to the endpoint's post-response
+// hook, stores the variables it returns and fails when it rejects the
+// response
+func (r *Runner) runPostResponseHook(ctx context.Context, endpoint *config.Endpoint, response *client.Response) error {
+	input := hook.Response{
+		Headers:    make(map[string]string, len(response.Headers)),
+		JSON:       jsonBody(response.Body),
+		Body:       response.GetBodyAsString(),
+		Status:     response.StatusCode,
+		DurationMs: response.Duration.Milliseconds(),
+	}
+	for name := range response.Headers {
+		input.Headers[name] = response.Headers.Get(name)
+	}
+
+	var verdict hook.Verdict
+	if err := hook.Run(ctx, endpoint.Hooks.PostResponse, hookTimeout(endpoint), endpoint.Name, input, &verdict); err != nil {
+		return err
+	}
+	for name, value := range verdict.Variables {
+		r.variables.Set(name, value)
+		// Values may be secrets, e.g. a token the hook minted
+		r.debug(endpoint, "hook set variable", "name", name)
+	}
+	if !verdict.Passed() {
+		if verdict.Message == "" {
+			return errors.New("rejected by the postResponse hook")
+		}
+		return errors.New("rejected by the postResponse hook: " + verdict.Message)
+	}
+	return nil
+}
+
+// jsonBody decodes a JSON response body, or returns nil when it is not JSON
+func jsonBody(body []byte) interface{} {
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return nil
+	}
+	return value
+}
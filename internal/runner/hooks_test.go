@@ -0,0 +1,151 @@
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/hutstep/entra-id-api-tester/internal/config"
+	"github.com/hutstep/entra-id-api-tester/internal/hook"
+)
+
+// hookHelperEnv selects the behavior of the test binary when it runs as a
+// hook
+const hookHelperEnv = "RUNNER_HOOK_HELPER"
+
+// TestMain lets the test binary act as a hook command
+func TestMain(m *testing.M) {
+	switch os.Getenv(hookHelperEnv) {
+	case "":
+		os.Exit(m.Run())
+	case "sign":
+		var request hook.Request
+		if err := json.NewDecoder(os.Stdin).Decode(&request); err != nil {
+			os.Exit(2)
+		}
+		fmt.Printf(`{"headers":{"X-Signature":"%s %s"}}`, request.Method, request.URL)
+	case "strip":
+		var request hook.Request
+		if err := json.NewDecoder(os.Stdin).Decode(&request); err != nil {
+			os.Exit(2)
+		}
+		delete(request.Headers, "Range")
+		delete(request.Body, "debug")
+		if err := json.NewEncoder(os.Stdout).Encode(hook.Request{Headers: request.Headers, Body: request.Body}); err != nil {
+			os.Exit(2)
+		}
+	case "judge":
+		var response hook.Response
+		if err := json.NewDecoder(os.Stdin).Decode(&response); err != nil {
+			os.Exit(2)
+		}
+		body, ok := response.JSON.(map[string]interface{})
+		if !ok || body["status"] != "open" {
+			fmt.Print(`{"pass":false,"message":"order is not open"}`)
+			return
+		}
+		fmt.Printf(`{"variables":{"orderStatus":"%v"}}`, body["status"])
+	}
+	os.Exit(0)
+}
+
+// hookCommand returns the command that runs the test binary as a hook
+func hookCommand(t *testing.T, behavior string) []string {
+	t.Helper()
+	t.Setenv(hookHelperEnv, behavior)
+	return []string{os.Args[0]}
+}
+
+func TestRun_PreRequestHook(t *testing.T) {
+	var signature string
+	runner, endpoint := newTestRunner(t, func(w http.ResponseWriter, r *http.Request) {
+		signature = r.Header.Get("X-Signature")
+	})
+	endpoint.Hooks = &config.Hooks{PreRequest: hookCommand(t, "sign")}
+
+	result := runner.Run(context.Background(), endpoint, &fakeTokenProvider{token: "token"})
+
+	if !result.Success() {
+		t.Fatalf("Expected success, got %v", result.Err)
+	}
+	if signature != "GET "+endpoint.URL {
+		t.Errorf("Expected the hook's signature header, got %q", signature)
+	}
+}
+
+func TestRun_PreRequestHookRemoves(t *testing.T) {
+	var headers http.Header
+	var body map[string]interface{}
+	runner, endpoint := newTestRunner(t, func(w http.ResponseWriter, r *http.Request) {
+		headers = r.Header
+		_ = json.NewDecoder(r.Body).Decode(&body)
+	})
+	endpoint.Method = http.MethodPost
+	endpoint.Range = "bytes=0-99"
+	endpoint.ApimSubscriptionKey = "subscription-key"
+	endpoint.RequestBody = map[string]interface{}{"debug": true, "name": "order"}
+	endpoint.Hooks = &config.Hooks{PreRequest: hookCommand(t, "strip")}
+
+	// The range check fails the response, which was sent without Range
+	runner.Run(context.Background(), endpoint, &fakeTokenProvider{token: "token"})
+
+	if headers == nil {
+		t.Fatal("Expected the request to be sent")
+	}
+	if headers.Get("Range") != "" || headers.Get("Ocp-Apim-Subscription-Key") != "subscription-key" {
+		t.Errorf("Expected the hook to remove Range and keep the subscription key, got %v", headers)
+	}
+	if _, ok := body["debug"]; ok || body["name"] != "order" {
+		t.Errorf("Expected the hook to remove the debug key, got %v", body)
+	}
+}
+
+func TestRun_PreRequestHookFails(t *testing.T) {
+	runner, endpoint := newTestRunner(t, respond(http.StatusOK, `{}`))
+	endpoint.Hooks = &config.Hooks{PreRequest: []string{"./does-not-exist"}}
+
+	result := runner.Run(context.Background(), endpoint, &fakeTokenProvider{token: "token"})
+
+	if !errors.Is(result.Err, ErrPrepare) || len(result.Attempts) != 0 {
+		t.Errorf("Expected a prepare failure without a request, got %v", result.Err)
+	}
+}
+
+func TestRun_PostResponseHook(t *testing.T) {
+	tests := []struct {
+		name          string
+		body          string
+		expectSuccess bool
+		expectVar     string
+	}{
+		{name: "accepted", body: `{"status":"open"}`, expectSuccess: true, expectVar: "open"},
+		{name: "rejected", body: `{"status":"closed"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner, endpoint := newTestRunner(t, respond(http.StatusOK, tt.body))
+			endpoint.Hooks = &config.Hooks{PostResponse: hookCommand(t, "judge")}
+
+			result := runner.Run(context.Background(), endpoint, &fakeTokenProvider{token: "token"})
+
+			if result.Success() != tt.expectSuccess {
+				t.Fatalf("Expected success %v, got %v", tt.expectSuccess, result.Err)
+			}
+			if len(result.Assertions) != 1 || result.Assertions[0].Description != postResponseHookDescription {
+				t.Fatalf("Expected the hook's verdict among the assertions, got %+v", result.Assertions)
+			}
+			if !tt.expectSuccess && !strings.Contains(result.Err.Error(), "order is not open") {
+				t.Errorf("Expected the hook's message, got %v", result.Err)
+			}
+			if value, _ := runner.variables.Get("orderStatus"); value != tt.expectVar {
+				t.Errorf("Expected orderStatus %q, got %q", tt.expectVar, value)
+			}
+		})
+	}
+}
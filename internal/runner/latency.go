@@ -0,0 +1,79 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/hutstep/entra-id-api-tester/internal/config"
+)
+
+// LatencyResult is the outcome of a latency objective across an endpoint's
+// runs
+type LatencyResult struct {
+	Objective config.LatencyObjective
+	// Duration is the objective's percentile of the runs' request durations
+	Duration time.Duration
+	// Samples is the number of runs that sent a request
+	Samples int
+}
+
+// Passed reports whether the percentile stayed below the objective's limit
+func (l *LatencyResult) Passed() bool {
+	return l.Duration < l.Objective.Max
+}
+
+// requestDuration returns the duration of the run's slowest request, not
+// counting time spent waiting on Retry-After, and false when it sent none
+func requestDuration(result *Result) (time.Duration, bool) {
+	var slowest time.Duration
+	for _, attempt := range result.Attempts {
+		slowest = max(slowest, attempt.Duration-attempt.ThrottleWait)
+	}
+	return slowest, len(result.Attempts) > 0
+}
+
+// percentile returns the nearest-rank percentile of durations, which must
+// be sorted and not empty
+func percentile(durations []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p / 100 * float64(len(durations))))
+	return durations[max(rank, 1)-1]
+}
+
+// checkLatency evaluates the endpoint's latency objectives over the request
+// durations of its runs and fails an otherwise passing result when one is
+// missed
+func (r *Runner) checkLatency(endpoint *config.Endpoint, durations []time.Duration, result *Result) *Result {
+	objectives := endpoint.LatencyObjectives()
+	if len(objectives) == 0 || len(durations) == 0 {
+		return result
+	}
+
+	phaseStart := time.Now()
+	sorted := slices.Clone(durations)
+	slices.Sort(sorted)
+	var missed []error
+	for _, objective := range objectives {
+		latency := LatencyResult{
+			Objective: objective,
+			Duration:  percentile(sorted, objective.Percentile),
+			Samples:   len(sorted),
+		}
+		result.Latency = append(result.Latency, latency)
+		r.debug(endpoint, "latency objective evaluated", "objective", objective.String(), "duration", latency.Duration, "passed", latency.Passed())
+		if !latency.Passed() {
+			missed = append(missed, fmt.Errorf("p%g was %v, objective is %s", objective.Percentile, latency.Duration.Truncate(time.Millisecond), objective))
+		}
+	}
+
+	if result.Err != nil {
+		return result
+	}
+	if len(missed) > 0 {
+		return result.fail(PhasePerformance, phaseStart, "Latency objective missed", errors.Join(missed...))
+	}
+	result.phase(PhasePerformance, phaseStart, true)
+	return result
+}
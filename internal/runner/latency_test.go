@@ -0,0 +1,80 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPercentile(t *testing.T) {
+	durations := []time.Duration{10, 20, 30, 40, 50, 60, 70, 80, 90, 100}
+	tests := []struct {
+		p        float64
+		expected time.Duration
+	}{
+		{50, 50},
+		{90, 90},
+		{95, 100},
+		{99.9, 100},
+		{100, 100},
+		{1, 10},
+	}
+
+	for _, tt := range tests {
+		if got := percentile(durations, tt.p); got != tt.expected {
+			t.Errorf("p%g: expected %v, got %v", tt.p, tt.expected, got)
+		}
+	}
+}
+
+func TestRunRepeated_Latency(t *testing.T) {
+	tests := []struct {
+		name      string
+		latency   []string
+		runs      int
+		expectErr string
+	}{
+		{name: "met", latency: []string{"p50 < 40ms"}, runs: 4},
+		{name: "missed", latency: []string{"p50 < 40ms", "p100 < 40ms"}, runs: 4, expectErr: "Latency objective missed: p100 was"},
+		{name: "single run", latency: []string{"p95 < 40ms"}, runs: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			runner, endpoint := newTestRunner(t, func(w http.ResponseWriter, r *http.Request) {
+				// Every fourth request is slow
+				if calls.Add(1)%4 == 0 {
+					time.Sleep(60 * time.Millisecond)
+				}
+				respond(http.StatusOK, `{}`)(w, r)
+			})
+			endpoint.Latency = tt.latency
+
+			result := runner.RunRepeated(context.Background(), endpoint, &fakeTokenProvider{token: "token"}, tt.runs)
+
+			if len(result.Latency) != len(tt.latency) {
+				t.Fatalf("Expected %d latency results, got %d", len(tt.latency), len(result.Latency))
+			}
+			if result.Latency[0].Samples != tt.runs {
+				t.Errorf("Expected %d samples, got %d", tt.runs, result.Latency[0].Samples)
+			}
+			if tt.expectErr != "" {
+				if !errors.Is(result.Err, ErrPerformance) || !strings.HasPrefix(result.Err.Error(), tt.expectErr) {
+					t.Errorf("Expected performance failure %q, got %v", tt.expectErr, result.Err)
+				}
+				if result.PassedRuns != tt.runs || result.Flaky() {
+					t.Errorf("Expected every run to pass on its own, got %d/%d", result.PassedRuns, result.Runs)
+				}
+				return
+			}
+			if !result.Success() {
+				t.Errorf("Expected success, got %v", result.Err)
+			}
+		})
+	}
+}
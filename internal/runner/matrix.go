@@ -0,0 +1,265 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hutstep/entra-id-api-tester/internal/auth"
+	"github.com/hutstep/entra-id-api-tester/internal/client"
+	"github.com/hutstep/entra-id-api-tester/internal/config"
+)
+
+// MatrixCell is the outcome of one entry of an endpoint's matrix, e.g. the
+// request sent with a token from one tenant or for one scope
+type MatrixCell struct {
+	// Err is nil when the entry passed. Otherwise it is a *PhaseError.
+	Err        error
+	Label      string
+	StatusCode int
+}
+
+// Passed reports whether the entry passed
+func (c *MatrixCell) Passed() bool {
+	return c.Err == nil
+}
+
+// FailedPhase returns the phase the entry failed in, or "" when it passed
+func (c *MatrixCell) FailedPhase() Phase {
+	var phaseErr *PhaseError
+	if errors.As(c.Err, &phaseErr) {
+		return phaseErr.Phase
+	}
+	return ""
+}
+
+// fail records the entry's failure in a phase
+func (c *MatrixCell) fail(phase Phase, summary string, err error) {
+	c.Err = &PhaseError{Phase: phase, Summary: summary, Err: err}
+}
+
+// MatrixError lists the failed entries of a matrix endpoint
+type MatrixError struct {
+	Failures []error
+}
+
+// Error joins the failures with "; "
+func (e *MatrixError) Error() string {
+	messages := make([]string, len(e.Failures))
+	for i, failure := range e.Failures {
+		messages[i] = failure.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Unwrap returns the individual failures
+func (e *MatrixError) Unwrap() []error {
+	return e.Failures
+}
+
+// matrixEntry is one variation of the endpoint's request
+type matrixEntry struct {
+	label    string
+	tenantID string
+	scope    string
+	// expectStatus, when set, is the status the entry must receive instead
+	// of a 2xx. The endpoint's assertions only apply to a 2xx status.
+	expectStatus int
+}
+
+// expectsSuccess reports whether the entry's response must be 2xx and pass
+// the endpoint's checks
+func (e *matrixEntry) expectsSuccess() bool {
+	return e.expectStatus == 0 || (e.expectStatus >= 200 && e.expectStatus < 300)
+}
+
+// phaseOrder ranks phases in the order they run, to pick the earliest
+// phase any matrix entry failed in
+var phaseOrder = map[Phase]int{
+	PhaseAuth:     0,
+	PhasePrepare:  1,
+	PhaseConnect:  2,
+	PhaseResponse: 3,
+}
+
+// tenantMatrix returns the matrix entries of an endpoint with tenants
+func tenantMatrix(endpoint *config.Endpoint) []matrixEntry {
+	entries := make([]matrixEntry, len(endpoint.Tenants))
+	for i, tenant := range endpoint.Tenants {
+		entries[i] = matrixEntry{label: tenant, tenantID: tenant, scope: endpoint.Scope}
+	}
+	return entries
+}
+
+// scopeMatrix returns the matrix entries of an endpoint with scopes
+func scopeMatrix(endpoint *config.Endpoint) []matrixEntry {
+	entries := make([]matrixEntry, len(endpoint.Scopes))
+	for i, check := range endpoint.Scopes {
+		entries[i] = matrixEntry{
+			label:        check.Scope,
+			tenantID:     endpoint.TenantID,
+			scope:        check.Scope,
+			expectStatus: check.ExpectStatus,
+		}
+	}
+	return entries
+}
+
+// runMatrix sends the endpoint's request once per matrix entry, each with
+// its own token, and checks every response. Unlike a single request, every
+// entry runs even when an earlier one failed, so the result holds the whole
+// matrix in Matrix. The endpoint fails in the earliest phase any entry
+// failed in.
+func (r *Runner) runMatrix(ctx context.Context, endpoint *config.Endpoint, tokenProvider auth.TokenProvider, name string, entries []matrixEntry, result *Result) *Result {
+	cells := make([]MatrixCell, len(entries))
+	for i, entry := range entries {
+		cells[i].Label = entry.label
+	}
+	defer func() { result.Matrix = cells }()
+
+	phaseStart := time.Now()
+	tokens := make([]string, len(entries))
+	for i, entry := range entries {
+		token, err := r.acquireToken(ctx, endpoint, tokenProvider, endpoint.ClientID, endpoint.ClientSecret, entry.tenantID, entry.scope, result)
+		if err != nil {
+			cells[i].fail(PhaseAuth, "Authentication failed", err)
+			continue
+		}
+		tokens[i] = token
+	}
+	result.phase(PhaseAuth, phaseStart, allPassed(cells))
+
+	request, ok := r.prepare(ctx, endpoint, "", result)
+	if !ok {
+		return result
+	}
+	if !r.checkTLSPolicy(ctx, endpoint, request, result) {
+		return result
+	}
+
+	phaseStart = time.Now()
+	responses := make([]*client.Response, len(entries))
+	for i, entry := range entries {
+		if !cells[i].Passed() {
+			continue
+		}
+		entryRequest := *request
+		entryRequest.AccessToken = tokens[i]
+		r.debug(endpoint, "sending matrix request", name, entry.label)
+		response, err := r.send(ctx, endpoint, &entryRequest, entry.label, result)
+		if err != nil {
+			cells[i].fail(PhaseConnect, "Request failed", err)
+			continue
+		}
+		cells[i].StatusCode = response.StatusCode
+		if err := response.CheckSignInPage(); err != nil {
+			cells[i].fail(PhaseAuth, "Redirected to interactive sign-in", err)
+			continue
+		}
+		responses[i] = response
+	}
+	result.phase(PhaseConnect, phaseStart, allPassed(cells))
+
+	// The result shows the first response, or the first that failed its
+	// checks. Captures and the checks that follow a passing response use
+	// the first response that had to succeed.
+	phaseStart = time.Now()
+	first, primary := -1, -1
+	failedChecks := false
+	for i := range entries {
+		if responses[i] == nil {
+			continue
+		}
+		checked := &Result{}
+		if summary, err := r.checkMatrixResponse(ctx, endpoint, &entries[i], responses[i], checked); summary != "" {
+			cells[i].fail(PhaseResponse, summary, err)
+		}
+		if first < 0 {
+			first = i
+			result.StatusCode = responses[i].StatusCode
+			result.Warnings = append(result.Warnings, responses[i].DeprecationWarnings()...)
+			result.Assertions = checked.Assertions
+		}
+		if primary < 0 && entries[i].expectsSuccess() {
+			primary = i
+		}
+		if !cells[i].Passed() && !failedChecks {
+			result.StatusCode = responses[i].StatusCode
+			result.Assertions = checked.Assertions
+			failedChecks = true
+		}
+	}
+
+	if err := matrixFailure(name, cells); err != nil {
+		return failMatrix(result, phaseStart, name, cells, err)
+	}
+	r.debug(endpoint, "matrix verified", name+"s", len(entries))
+
+	if primary < 0 {
+		result.phase(PhaseResponse, phaseStart, true)
+		return r.checkPerformance(endpoint, result)
+	}
+	request.AccessToken = tokens[primary]
+	result = r.capture(endpoint, responses[primary], phaseStart, result)
+	return r.finish(ctx, endpoint, request, responses[primary], result)
+}
+
+// checkMatrixResponse checks an entry's response: its expected status, if
+// any, and the endpoint's checks when it had to succeed
+func (r *Runner) checkMatrixResponse(ctx context.Context, endpoint *config.Endpoint, entry *matrixEntry, response *client.Response, result *Result) (string, error) {
+	if entry.expectStatus != 0 && response.StatusCode != entry.expectStatus {
+		return fmt.Sprintf("Unexpected status code: %d", response.StatusCode), fmt.Errorf("expected status %d", entry.expectStatus)
+	}
+	if !entry.expectsSuccess() {
+		return "", nil
+	}
+	return r.checkResponse(ctx, endpoint, response, result)
+}
+
+// failMatrix fails the result in the earliest phase a matrix entry failed
+// in. A failed phase is only recorded once; an entry redirected to the
+// sign-in page fails authentication after it was recorded as passed.
+func failMatrix(result *Result, phaseStart time.Time, name string, cells []MatrixCell, err error) *Result {
+	earliest := PhaseResponse
+	failed := 0
+	for i := range cells {
+		if phase := cells[i].FailedPhase(); phase != "" {
+			failed++
+			if phaseOrder[phase] < phaseOrder[earliest] {
+				earliest = phase
+			}
+		}
+	}
+	summary := fmt.Sprintf("%s matrix failed (%d of %d %ss)", strings.ToUpper(name[:1])+name[1:], failed, len(cells), name)
+	if earliest != PhaseResponse && !result.Passed(earliest) {
+		result.Err = &PhaseError{Phase: earliest, Summary: summary, Err: err}
+		return result
+	}
+	return result.fail(earliest, phaseStart, summary, err)
+}
+
+// matrixFailure returns a *MatrixError listing the failed entries, or nil
+func matrixFailure(name string, cells []MatrixCell) error {
+	var failures []error
+	for i := range cells {
+		if cells[i].Err != nil {
+			failures = append(failures, fmt.Errorf("%s %s: %w", name, cells[i].Label, cells[i].Err))
+		}
+	}
+	if len(failures) == 0 {
+		return nil
+	}
+	return &MatrixError{Failures: failures}
+}
+
+// allPassed reports whether no matrix entry has failed so far
+func allPassed(cells []MatrixCell) bool {
+	for i := range cells {
+		if !cells[i].Passed() {
+			return false
+		}
+	}
+	return true
+}
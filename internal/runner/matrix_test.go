@@ -0,0 +1,169 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hutstep/entra-id-api-tester/internal/config"
+)
+
+// tenantIDTokenProvider issues the tenant ID as the token, so the test
+// server can tell tenants apart
+type tenantIDTokenProvider struct{}
+
+func (tenantIDTokenProvider) GetAccessToken(ctx context.Context, clientID, clientSecret, tenantID, scope string) (string, error) {
+	if tenantID == "unconsented" {
+		return "", errors.New("AADSTS650052: the app needs access to a service")
+	}
+	return tenantID, nil
+}
+
+func TestRun_Tenants(t *testing.T) {
+	tests := []struct {
+		name         string
+		expectErr    error
+		expectFailed []string
+		tenants      []string
+	}{
+		{
+			name:    "all tenants pass",
+			tenants: []string{"contoso", "fabrikam"},
+		},
+		{
+			name:         "tenant rejected",
+			tenants:      []string{"contoso", "revoked", "fabrikam"},
+			expectErr:    ErrResponse,
+			expectFailed: []string{"revoked"},
+		},
+		{
+			name:         "token fails for one tenant",
+			tenants:      []string{"unconsented", "contoso", "revoked"},
+			expectErr:    ErrAuth,
+			expectFailed: []string{"unconsented", "revoked"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner, endpoint := newTestRunner(t, func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("Authorization") == "Bearer revoked" {
+					w.WriteHeader(http.StatusForbidden)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			})
+			endpoint.TenantID = ""
+			endpoint.Tenants = tt.tenants
+
+			result := runner.Run(context.Background(), endpoint, tenantIDTokenProvider{})
+
+			if len(result.Matrix) != len(tt.tenants) {
+				t.Fatalf("Expected %d matrix cells, got %d", len(tt.tenants), len(result.Matrix))
+			}
+			var failed []string
+			for i, cell := range result.Matrix {
+				if cell.Label != tt.tenants[i] {
+					t.Errorf("Expected cell %d for tenant %s, got %s", i, tt.tenants[i], cell.Label)
+				}
+				if !cell.Passed() {
+					failed = append(failed, cell.Label)
+				}
+			}
+			if strings.Join(failed, ",") != strings.Join(tt.expectFailed, ",") {
+				t.Errorf("Expected failed tenants %v, got %v", tt.expectFailed, failed)
+			}
+
+			if tt.expectErr == nil {
+				if !result.Success() {
+					t.Fatalf("Expected success, got %v", result.Err)
+				}
+				return
+			}
+			if !errors.Is(result.Err, tt.expectErr) {
+				t.Fatalf("Expected %v, got %v", tt.expectErr, result.Err)
+			}
+			var matrixErr *MatrixError
+			if !errors.As(result.Err, &matrixErr) || len(matrixErr.Failures) != len(tt.expectFailed) {
+				t.Errorf("Expected a MatrixError with %d failures, got %v", len(tt.expectFailed), result.Err)
+			}
+		})
+	}
+}
+
+// scopeTokenProvider issues the scope as the token, so the test server can
+// tell scopes apart
+type scopeTokenProvider struct{}
+
+func (scopeTokenProvider) GetAccessToken(ctx context.Context, clientID, clientSecret, tenantID, scope string) (string, error) {
+	return scope, nil
+}
+
+func TestRun_Scopes(t *testing.T) {
+	tests := []struct {
+		name         string
+		expectErr    error
+		expectFailed []string
+		scopes       []config.ScopeCheck
+	}{
+		{
+			name: "boundaries enforced",
+			scopes: []config.ScopeCheck{
+				{Scope: "api://orders/.default", ExpectStatus: http.StatusOK},
+				{Scope: "api://billing/.default", ExpectStatus: http.StatusForbidden},
+			},
+		},
+		{
+			name: "only rejections",
+			scopes: []config.ScopeCheck{
+				{Scope: "api://billing/.default", ExpectStatus: http.StatusForbidden},
+			},
+		},
+		{
+			name: "foreign scope admitted",
+			scopes: []config.ScopeCheck{
+				{Scope: "api://orders/.default", ExpectStatus: http.StatusOK},
+				{Scope: "api://billing/.default", ExpectStatus: http.StatusOK},
+			},
+			expectErr:    ErrResponse,
+			expectFailed: []string{"api://billing/.default"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner, endpoint := newTestRunner(t, func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("Authorization") != "Bearer api://orders/.default" {
+					w.WriteHeader(http.StatusForbidden)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			})
+			endpoint.Scope = ""
+			endpoint.Scopes = tt.scopes
+
+			result := runner.Run(context.Background(), endpoint, scopeTokenProvider{})
+
+			var failed []string
+			for _, cell := range result.Matrix {
+				if !cell.Passed() {
+					failed = append(failed, cell.Label)
+				}
+			}
+			if len(result.Matrix) != len(tt.scopes) || strings.Join(failed, ",") != strings.Join(tt.expectFailed, ",") {
+				t.Errorf("Expected failed scopes %v of %d, got %+v", tt.expectFailed, len(tt.scopes), result.Matrix)
+			}
+			if tt.expectErr == nil {
+				if !result.Success() {
+					t.Fatalf("Expected success, got %v", result.Err)
+				}
+				return
+			}
+			if !errors.Is(result.Err, tt.expectErr) {
+				t.Fatalf("Expected %v, got %v", tt.expectErr, result.Err)
+			}
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/hutstep/entra-id-api-tester/internal/auth"
+	"github.com/hutstep/entra-id-api-tester/internal/client"
+	"github.com/hutstep/entra-id-api-tester/internal/config"
+)
+
+// invalidToken is sent by the invalid token probe. It is not a JWT at all,
+// so no API that validates tokens can accept it.
+const invalidToken = "api-tester-invalid-token"
+
+// SecurityError lists the probes an endpoint failed to reject
+type SecurityError struct {
+	Failures []string
+}
+
+// Error joins the failures with "; "
+func (e *SecurityError) Error() string {
+	return strings.Join(e.Failures, "; ")
+}
+
+// authProbe is a request with missing or invalid credentials that the
+// endpoint must reject
+type authProbe struct {
+	// label names the probe in attempts and failures, e.g. "without a token"
+	label string
+	// token replaces the access token; empty sends no Authorization header
+	token string
+	// challenge requires a 401 to carry a Bearer challenge for the
+	// endpoint's tenant and resource
+	challenge bool
+}
+
+// authProbes returns the probes for the endpoint's negativeAuth and
+// tamperedTokens settings, replaying token for the latter. A probe that
+// cannot be built is reported as a failure.
+func authProbes(endpoint *config.Endpoint, token string) ([]authProbe, []string) {
+	var probes []authProbe
+	var failures []string
+	if endpoint.NegativeAuth {
+		probes = append(probes,
+			authProbe{label: "without a token", challenge: true},
+			authProbe{label: "with an invalid token", token: invalidToken},
+		)
+	}
+	if endpoint.TamperedTokens {
+		tampered := []struct {
+			label  string
+			tamper func(string) (string, error)
+		}{
+			{"with a truncated signature", auth.TruncateSignature},
+			{"with an expired token", func(token string) (string, error) { return auth.Expire(token, time.Now()) }},
+		}
+		for _, t := range tampered {
+			probeToken, err := t.tamper(token)
+			if err != nil {
+				failures = append(failures, fmt.Sprintf("cannot send a request %s: %v", t.label, err))
+				continue
+			}
+			probes = append(probes, authProbe{label: t.label, token: probeToken})
+		}
+	}
+	return probes, failures
+}
+
+// rejected reports whether response rejects a request for its
+// credentials: with 401 or 403, or by redirecting it to the Entra ID
+// sign-in page
+func rejected(response *client.Response) bool {
+	return response.StatusCode == http.StatusUnauthorized || response.StatusCode == http.StatusForbidden || response.CheckSignInPage() != nil
+}
+
+// checkNegativeAuth sends the otherwise passing endpoint's request with
+// missing, invalid or tampered credentials, failing the endpoint unless
+// every probe is rejected with 401 or 403, or sent to sign in. The probes
+// are not part of the endpoint's duration.
+func (r *Runner) checkNegativeAuth(ctx context.Context, endpoint *config.Endpoint, request *client.Request, result *Result) *Result {
+	if result.Err != nil || (!endpoint.NegativeAuth && !endpoint.TamperedTokens) {
+		return result
+	}
+
+	phaseStart := time.Now()
+	pacingWait := result.PacingWait
+	defer func() {
+		result.ProbeDuration = time.Since(phaseStart) - (result.PacingWait - pacingWait)
+	}()
+	probes, failures := authProbes(endpoint, request.AccessToken)
+	for _, probe := range probes {
+		probeRequest := *request
+		probeRequest.AccessToken = probe.token
+		r.debug(endpoint, "sending request "+probe.label)
+		response, err := r.send(ctx, endpoint, &probeRequest, probe.label, result)
+		switch {
+		case err != nil:
+			failures = append(failures, fmt.Sprintf("request %s failed: %v", probe.label, err))
+		case !rejected(response):
+			failures = append(failures, fmt.Sprintf("request %s returned %d, expected 401 or 403", probe.label, response.StatusCode))
+		case probe.challenge && response.StatusCode == http.StatusUnauthorized:
+			if err := checkChallenge(endpoint, response.Headers); err != nil {
+				failures = append(failures, fmt.Sprintf("request %s: %v", probe.label, err))
+				break
+			}
+			r.debug(endpoint, "request rejected "+probe.label, "status", response.StatusCode)
+		default:
+			r.debug(endpoint, "request rejected "+probe.label, "status", response.StatusCode)
+		}
+	}
+
+	if len(failures) > 0 {
+		return result.fail(PhaseSecurity, phaseStart, "Negative authentication failed", &SecurityError{Failures: failures})
+	}
+	result.phase(PhaseSecurity, phaseStart, true)
+	return result
+}
+
+// multiTenantAuthorities are authority paths that are valid for any tenant
+var multiTenantAuthorities = map[string]bool{"common": true, "organizations": true, "consumers": true}
+
+// guidPattern matches a tenant or application ID
+var guidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// checkChallenge validates the WWW-Authenticate header of a 401 to a
+// request without a token (RFC 6750, section 3): it must hold a Bearer
+// challenge, and an authority or resource the challenge names must be the
+// endpoint's tenant and the resource of its scope. A mismatch points at
+// middleware configured for another tenant or application.
+func checkChallenge(endpoint *config.Endpoint, headers http.Header) error {
+	values := headers.Values("WWW-Authenticate")
+	if len(values) == 0 {
+		return fmt.Errorf("401 without a WWW-Authenticate header")
+	}
+	challenge, ok := auth.Bearer(auth.ParseChallenges(values))
+	if !ok {
+		return fmt.Errorf("401 without a Bearer challenge (WWW-Authenticate: %s)", strings.Join(values, ", "))
+	}
+
+	for _, name := range []string{"authorization_uri", "authorization"} {
+		authority := challenge.Params[name]
+		if authority == "" {
+			continue
+		}
+		tenant := authorityTenant(authority)
+		if guidPattern.MatchString(tenant) && guidPattern.MatchString(endpoint.TenantID) && !strings.EqualFold(tenant, endpoint.TenantID) {
+			return fmt.Errorf("the Bearer challenge's %s %s is for tenant %s, expected %s", name, authority, tenant, endpoint.TenantID)
+		}
+	}
+	for _, name := range []string{"resource_id", "resource"} {
+		resource := challenge.Params[name]
+		if resource == "" {
+			continue
+		}
+		if expected := scopeResource(endpoint.Scope); !sameResource(resource, expected) {
+			return fmt.Errorf("the Bearer challenge's %s %s does not match the scope's resource %s", name, resource, expected)
+		}
+	}
+	return nil
+}
+
+// authorityTenant returns the tenant of an authority URI such as
+// https://login.microsoftonline.com/{tenant}/oauth2/authorize, or "" for a
+// multi-tenant authority
+func authorityTenant(authority string) string {
+	parsed, err := url.Parse(authority)
+	if err != nil {
+		return ""
+	}
+	tenant, _, _ := strings.Cut(strings.TrimPrefix(parsed.Path, "/"), "/")
+	if multiTenantAuthorities[strings.ToLower(tenant)] {
+		return ""
+	}
+	return tenant
+}
+
+// scopeResource returns the resource a scope belongs to, e.g. api://orders
+// for api://orders/.default
+func scopeResource(scope string) string {
+	if i := strings.LastIndex(scope, "/"); i > len("https://") {
+		return scope[:i]
+	}
+	return scope
+}
+
+// sameResource compares resource identifiers, treating api://{app-id} and
+// the bare application ID as the same
+func sameResource(a, b string) bool {
+	normalize := func(resource string) string {
+		return strings.TrimSuffix(strings.TrimPrefix(strings.ToLower(resource), "api://"), "/")
+	}
+	return normalize(a) == normalize(b)
+}
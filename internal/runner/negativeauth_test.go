@@ -0,0 +1,232 @@
+package runner
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hutstep/entra-id-api-tester/internal/config"
+)
+
+func TestRun_NegativeAuth(t *testing.T) {
+	tests := []struct {
+		name      string
+		handler   http.HandlerFunc
+		expectErr error
+		failures  []string
+	}{
+		{
+			name: "protected",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				switch r.Header.Get("Authorization") {
+				case "Bearer token":
+					respond(http.StatusOK, `{}`)(w, r)
+				case "":
+					w.Header().Set("WWW-Authenticate", `Bearer realm="", error="invalid_token"`)
+					respond(http.StatusUnauthorized, `{}`)(w, r)
+				default:
+					respond(http.StatusForbidden, `{}`)(w, r)
+				}
+			},
+		},
+		{
+			name: "sent to sign in",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("Authorization") == "Bearer token" {
+					respond(http.StatusOK, `{}`)(w, r)
+					return
+				}
+				w.Header().Set("Content-Type", "text/html")
+				_, _ = w.Write([]byte(`<html><head><title>Sign in to your account</title></head></html>`))
+			},
+		},
+		{
+			name:      "unprotected",
+			handler:   respond(http.StatusOK, `{}`),
+			expectErr: ErrSecurity,
+			failures: []string{
+				"request without a token returned 200, expected 401 or 403",
+				"request with an invalid token returned 200, expected 401 or 403",
+			},
+		},
+		{
+			name: "accepts any token",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				if strings.HasPrefix(r.Header.Get("Authorization"), "Bearer ") {
+					respond(http.StatusOK, `{}`)(w, r)
+					return
+				}
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				respond(http.StatusUnauthorized, `{}`)(w, r)
+			},
+			expectErr: ErrSecurity,
+			failures:  []string{"request with an invalid token returned 200, expected 401 or 403"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner, endpoint := newTestRunner(t, tt.handler)
+			endpoint.NegativeAuth = true
+
+			result := runner.Run(context.Background(), endpoint, &fakeTokenProvider{token: "token"})
+
+			if !errors.Is(result.Err, tt.expectErr) || (tt.expectErr == nil) != (result.Err == nil) {
+				t.Fatalf("Expected %v, got %v", tt.expectErr, result.Err)
+			}
+			if len(result.Attempts) != 3 || result.Attempts[1].Label != "without a token" {
+				t.Errorf("Expected the request and two labelled probes, got %+v", result.Attempts)
+			}
+			if tt.expectErr == nil {
+				if !result.Passed(PhaseSecurity) {
+					t.Errorf("Expected the security phase to pass, got %+v", result.Phases)
+				}
+				return
+			}
+			var securityErr *SecurityError
+			if !errors.As(result.Err, &securityErr) || strings.Join(securityErr.Failures, "\n") != strings.Join(tt.failures, "\n") {
+				t.Errorf("Expected failures %q, got %v", tt.failures, result.Err)
+			}
+		})
+	}
+}
+
+func TestRun_NegativeAuthNotTimed(t *testing.T) {
+	runner, endpoint := newTestRunner(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			time.Sleep(100 * time.Millisecond)
+			respond(http.StatusUnauthorized, `{}`)(w, r)
+			return
+		}
+		respond(http.StatusOK, `{}`)(w, r)
+	})
+	endpoint.NegativeAuth = true
+
+	result := runner.Run(context.Background(), endpoint, &fakeTokenProvider{token: "token"})
+
+	if result.ProbeDuration < 200*time.Millisecond {
+		t.Errorf("Expected the probes to take at least 200ms, got %v", result.ProbeDuration)
+	}
+	if result.Duration >= 100*time.Millisecond {
+		t.Errorf("Expected the probes not to count towards the duration, got %v", result.Duration)
+	}
+}
+
+func TestRun_NegativeAuthSkippedAfterFailure(t *testing.T) {
+	runner, endpoint := newTestRunner(t, respond(http.StatusInternalServerError, `{}`))
+	endpoint.NegativeAuth = true
+
+	result := runner.Run(context.Background(), endpoint, &fakeTokenProvider{token: "token"})
+
+	if !errors.Is(result.Err, ErrResponse) || len(result.Attempts) != 1 {
+		t.Errorf("Expected only the failed request, got %v with %d attempt(s)", result.Err, len(result.Attempts))
+	}
+}
+
+func TestRun_TamperedTokens(t *testing.T) {
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"RS256","typ":"JWT"}`))
+	payload := base64.RawURLEncoding.EncodeToString([]byte(`{"aud":"api://example","exp":4102444800}`))
+	jwt := header + "." + payload + "." + base64.RawURLEncoding.EncodeToString([]byte("a-valid-looking-signature"))
+
+	tests := []struct {
+		name     string
+		token    string
+		handler  http.HandlerFunc
+		failures []string
+	}{
+		{
+			name:  "validates tokens",
+			token: jwt,
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("Authorization") == "Bearer "+jwt {
+					respond(http.StatusOK, `{}`)(w, r)
+					return
+				}
+				respond(http.StatusUnauthorized, `{}`)(w, r)
+			},
+		},
+		{
+			name:    "skips signature and expiry validation",
+			token:   jwt,
+			handler: respond(http.StatusOK, `{}`),
+			failures: []string{
+				"request with a truncated signature returned 200, expected 401 or 403",
+				"request with an expired token returned 200, expected 401 or 403",
+			},
+		},
+		{
+			name:    "opaque token",
+			token:   "opaque-token",
+			handler: respond(http.StatusOK, `{}`),
+			failures: []string{
+				"cannot send a request with a truncated signature: token is not a signed JWT",
+				"cannot send a request with an expired token: token is not a JWT",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner, endpoint := newTestRunner(t, tt.handler)
+			endpoint.TamperedTokens = true
+
+			result := runner.Run(context.Background(), endpoint, &fakeTokenProvider{token: tt.token})
+
+			if len(tt.failures) == 0 {
+				if result.Err != nil || len(result.Attempts) != 3 {
+					t.Errorf("Expected both probes to be rejected, got %v with %d attempt(s)", result.Err, len(result.Attempts))
+				}
+				return
+			}
+			var securityErr *SecurityError
+			if !errors.As(result.Err, &securityErr) || strings.Join(securityErr.Failures, "\n") != strings.Join(tt.failures, "\n") {
+				t.Errorf("Expected failures %q, got %v", tt.failures, result.Err)
+			}
+		})
+	}
+}
+
+func TestCheckChallenge(t *testing.T) {
+	const tenant = "72f988bf-86f1-41af-91ab-2d7cd011db47"
+	endpoint := &config.Endpoint{TenantID: tenant, Scope: "api://3fa85f64-5717-4562-b3fc-2c963f66afa6/.default"}
+
+	tests := []struct {
+		name     string
+		header   []string
+		expected string
+	}{
+		{"bare Bearer", []string{"Bearer"}, ""},
+		{
+			name:   "matching authority and resource",
+			header: []string{`Bearer authorization_uri="https://login.microsoftonline.com/` + tenant + `/oauth2/authorize", resource_id="3fa85f64-5717-4562-b3fc-2c963f66afa6"`},
+		},
+		{"multi-tenant authority", []string{`Bearer authorization_uri="https://login.microsoftonline.com/common/oauth2/authorize"`}, ""},
+		{"Bearer among other challenges", []string{`Negotiate`, `Basic realm="api", bearer error="invalid_token"`}, ""},
+		{"missing header", nil, "401 without a WWW-Authenticate header"},
+		{"Basic only", []string{`Basic realm="api"`}, `401 without a Bearer challenge (WWW-Authenticate: Basic realm="api")`},
+		{
+			name:     "other tenant",
+			header:   []string{`Bearer authorization_uri="https://login.microsoftonline.com/00000000-0000-0000-0000-000000000001/oauth2/authorize"`},
+			expected: "the Bearer challenge's authorization_uri https://login.microsoftonline.com/00000000-0000-0000-0000-000000000001/oauth2/authorize is for tenant 00000000-0000-0000-0000-000000000001, expected " + tenant,
+		},
+		{
+			name:     "other resource",
+			header:   []string{`Bearer resource="api://billing"`},
+			expected: "the Bearer challenge's resource api://billing does not match the scope's resource api://3fa85f64-5717-4562-b3fc-2c963f66afa6",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkChallenge(endpoint, http.Header{"Www-Authenticate": tt.header})
+			if got := fmt.Sprint(err); (err == nil && tt.expected != "") || (err != nil && got != tt.expected) {
+				t.Errorf("Expected %q, got %v", tt.expected, err)
+			}
+		})
+	}
+}
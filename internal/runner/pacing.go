@@ -0,0 +1,127 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hutstep/entra-id-api-tester/internal/config"
+)
+
+// Rate is a maximum request rate, e.g. 5 requests per second. The zero
+// Rate is unlimited.
+type Rate struct {
+	Requests int
+	Per      time.Duration
+}
+
+// ParseRate parses a rate of the form "<requests>/<period>", where the
+// period is s, m or h, e.g. "5/s" or "100/m", or a duration, e.g. "1/250ms"
+func ParseRate(value string) (Rate, error) {
+	requests, period, found := strings.Cut(strings.TrimSpace(value), "/")
+	if !found {
+		return Rate{}, fmt.Errorf("invalid rate %q (expected e.g. 5/s or 100/m)", value)
+	}
+	count, err := strconv.Atoi(requests)
+	if err != nil || count < 1 {
+		return Rate{}, fmt.Errorf("invalid rate %q: %q is not a positive number of requests", value, requests)
+	}
+	var per time.Duration
+	switch period {
+	case "s":
+		per = time.Second
+	case "m":
+		per = time.Minute
+	case "h":
+		per = time.Hour
+	default:
+		per, err = time.ParseDuration(period)
+		if err != nil || per <= 0 {
+			return Rate{}, fmt.Errorf("invalid rate %q: %q is not s, m, h or a positive duration", value, period)
+		}
+	}
+	return Rate{Requests: count, Per: per}, nil
+}
+
+// String formats the rate as it is parsed, e.g. "5/s"
+func (r Rate) String() string {
+	if r.IsZero() {
+		return ""
+	}
+	switch r.Per {
+	case time.Second:
+		return fmt.Sprintf("%d/s", r.Requests)
+	case time.Minute:
+		return fmt.Sprintf("%d/m", r.Requests)
+	case time.Hour:
+		return fmt.Sprintf("%d/h", r.Requests)
+	}
+	return fmt.Sprintf("%d/%v", r.Requests, r.Per)
+}
+
+// IsZero reports whether the rate is unlimited
+func (r Rate) IsZero() bool {
+	return r.Requests <= 0 || r.Per <= 0
+}
+
+// interval returns the time between two requests at the rate
+func (r Rate) interval() time.Duration {
+	return r.Per / time.Duration(r.Requests)
+}
+
+// pace waits until the runner's rate allows another request and records
+// the wait in the result. Requests are spread evenly, one per interval.
+func (r *Runner) pace(ctx context.Context, result *Result) error {
+	r.paceMu.Lock()
+	r.requests++
+	if r.Rate.IsZero() {
+		r.paceMu.Unlock()
+		return nil
+	}
+	now := time.Now()
+	slot := now
+	if r.nextRequest.After(now) {
+		slot = r.nextRequest
+	}
+	r.nextRequest = slot.Add(r.Rate.interval())
+	r.paceMu.Unlock()
+
+	return r.wait(ctx, slot.Sub(now), result)
+}
+
+// Requests returns the number of requests the runner has sent, counting a
+// race test's concurrent requests as one
+func (r *Runner) Requests() int {
+	r.paceMu.Lock()
+	defer r.paceMu.Unlock()
+	return r.requests
+}
+
+// delayAfter waits the endpoint's delayAfterMs, if any, after it ran and
+// records the wait in the result
+func (r *Runner) delayAfter(ctx context.Context, endpoint *config.Endpoint, result *Result) {
+	if endpoint.DelayAfterMs > 0 {
+		_ = r.wait(ctx, time.Duration(endpoint.DelayAfterMs)*time.Millisecond, result)
+	}
+}
+
+// wait sleeps for d unless ctx is cancelled first, adding the time waited
+// to the result's PacingWait
+func (r *Runner) wait(ctx context.Context, d time.Duration, result *Result) error {
+	if d <= 0 {
+		return nil
+	}
+	started := time.Now()
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		result.PacingWait += time.Since(started)
+		return fmt.Errorf("cancelled while pacing: %w", ctx.Err())
+	case <-timer.C:
+		result.PacingWait += time.Since(started)
+		return nil
+	}
+}
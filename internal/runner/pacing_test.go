@@ -0,0 +1,82 @@
+package runner
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestParseRate(t *testing.T) {
+	tests := []struct {
+		value     string
+		expected  Rate
+		formatted string
+		expectErr bool
+	}{
+		{value: "5/s", expected: Rate{Requests: 5, Per: time.Second}, formatted: "5/s"},
+		{value: "100/m", expected: Rate{Requests: 100, Per: time.Minute}, formatted: "100/m"},
+		{value: "1000/h", expected: Rate{Requests: 1000, Per: time.Hour}, formatted: "1000/h"},
+		{value: "1/250ms", expected: Rate{Requests: 1, Per: 250 * time.Millisecond}, formatted: "1/250ms"},
+		{value: "5", expectErr: true},
+		{value: "0/s", expectErr: true},
+		{value: "five/s", expectErr: true},
+		{value: "5/day", expectErr: true},
+		{value: "5/-1s", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			rate, err := ParseRate(tt.value)
+			if tt.expectErr {
+				if err == nil {
+					t.Errorf("Expected error, got %v", rate)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if rate != tt.expected || rate.String() != tt.formatted {
+				t.Errorf("Expected %v (%s), got %v (%s)", tt.expected, tt.formatted, rate, rate)
+			}
+		})
+	}
+}
+
+func TestRunRepeated_Pacing(t *testing.T) {
+	var mu sync.Mutex
+	var arrivals []time.Time
+	runner, endpoint := newTestRunner(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		arrivals = append(arrivals, time.Now())
+		mu.Unlock()
+		respond(http.StatusOK, `{}`)(w, r)
+	})
+	runner.Rate = Rate{Requests: 1, Per: 40 * time.Millisecond}
+	endpoint.WarmupRequests = 1
+	endpoint.DelayAfterMs = 30
+
+	result := runner.RunRepeated(context.Background(), endpoint, &fakeTokenProvider{token: "token"}, 3)
+
+	if !result.Success() {
+		t.Fatalf("Expected success, got %v", result.Err)
+	}
+	if len(arrivals) != 6 {
+		t.Fatalf("Expected 6 requests, got %d", len(arrivals))
+	}
+	for i := 1; i < len(arrivals); i++ {
+		// Allow for timer granularity
+		if gap := arrivals[i].Sub(arrivals[i-1]); gap < 35*time.Millisecond {
+			t.Errorf("Expected requests at most every 40ms, got %v between requests %d and %d", gap, i, i+1)
+		}
+	}
+	// Three delays after runs plus the paced waits
+	if result.PacingWait < 90*time.Millisecond {
+		t.Errorf("Expected the pacing wait to include the delays after each run, got %v", result.PacingWait)
+	}
+	if result.Duration >= 40*time.Millisecond {
+		t.Errorf("Expected the duration to leave out pacing, got %v", result.Duration)
+	}
+}
@@ -0,0 +1,36 @@
+package runner
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hutstep/entra-id-api-tester/internal/config"
+)
+
+// maxDuration returns the endpoint's response time objective, falling back
+// to the runner default; zero means none
+func (r *Runner) maxDuration(endpoint *config.Endpoint) time.Duration {
+	if endpoint.MaxDurationMs > 0 {
+		return time.Duration(endpoint.MaxDurationMs) * time.Millisecond
+	}
+	return r.MaxDuration
+}
+
+// checkPerformance fails an otherwise passing endpoint whose slowest request
+// exceeded its maximum duration. Time spent waiting on Retry-After is not
+// counted against the objective.
+func (r *Runner) checkPerformance(endpoint *config.Endpoint, result *Result) *Result {
+	limit := r.maxDuration(endpoint)
+	if result.Err != nil || limit <= 0 {
+		return result
+	}
+
+	phaseStart := time.Now()
+	slowest, _ := requestDuration(result)
+	if slowest > limit {
+		return result.fail(PhasePerformance, phaseStart, "Performance objective missed",
+			fmt.Errorf("took %v, maximum is %v", slowest.Truncate(time.Millisecond), limit))
+	}
+	result.phase(PhasePerformance, phaseStart, true)
+	return result
+}
@@ -0,0 +1,68 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/hutstep/entra-id-api-tester/internal/config"
+)
+
+func TestCheckPerformance(t *testing.T) {
+	tests := []struct {
+		name          string
+		runnerMax     time.Duration
+		endpointMaxMs int
+		attempts      []Attempt
+		expectFailure bool
+	}{
+		{"no objective", 0, 0, []Attempt{{Duration: time.Hour}}, false},
+		{"within runner default", time.Second, 0, []Attempt{{Duration: 900 * time.Millisecond}}, false},
+		{"exceeds runner default", time.Second, 0, []Attempt{{Duration: 1100 * time.Millisecond}}, true},
+		{"endpoint overrides default", time.Second, 2000, []Attempt{{Duration: 1500 * time.Millisecond}}, false},
+		{"slowest attempt counts", 0, 1000, []Attempt{{Duration: 10 * time.Millisecond}, {Duration: 1500 * time.Millisecond}}, true},
+		{"throttle wait is not counted", 0, 1000, []Attempt{{Duration: 5 * time.Second, ThrottleWait: 4500 * time.Millisecond}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := &Runner{MaxDuration: tt.runnerMax}
+			endpoint := &config.Endpoint{MaxDurationMs: tt.endpointMaxMs}
+			result := &Result{Attempts: tt.attempts}
+
+			runner.checkPerformance(endpoint, result)
+
+			if tt.expectFailure {
+				if !errors.Is(result.Err, ErrPerformance) || result.FailedPhase() != PhasePerformance {
+					t.Errorf("Expected a performance failure, got %v", result.Err)
+				}
+				return
+			}
+			if result.Err != nil {
+				t.Errorf("Expected no failure, got %v", result.Err)
+			}
+		})
+	}
+}
+
+func TestRun_PerformanceFailure(t *testing.T) {
+	runner, endpoint := newTestRunner(t, func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	})
+	endpoint.MaxDurationMs = 10
+
+	result := runner.Run(context.Background(), endpoint, &fakeTokenProvider{token: "token"})
+
+	if !errors.Is(result.Err, ErrPerformance) {
+		t.Fatalf("Expected a performance failure, got %v", result.Err)
+	}
+	if !result.Passed(PhaseResponse) || result.Passed(PhasePerformance) {
+		t.Errorf("Expected the response to pass and performance to fail, got %+v", result.Phases)
+	}
+	if result.StatusCode != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", result.StatusCode)
+	}
+}
@@ -0,0 +1,90 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hutstep/entra-id-api-tester/internal/auth"
+	"github.com/hutstep/entra-id-api-tester/internal/client"
+	"github.com/hutstep/entra-id-api-tester/internal/config"
+)
+
+// persona returns the runner's persona with the given name, or nil
+func (r *Runner) persona(name string) *config.Persona {
+	for i := range r.Personas {
+		if r.Personas[i].Name == name {
+			return &r.Personas[i]
+		}
+	}
+	return nil
+}
+
+// runPersonas sends the endpoint's request once per persona, each with its
+// own token, and checks that every persona receives its expected status.
+// This verifies Conditional Access and app role policies end-to-end:
+// restricted personas must be rejected and permitted ones admitted.
+func (r *Runner) runPersonas(ctx context.Context, endpoint *config.Endpoint, tokenProvider auth.TokenProvider, result *Result) *Result {
+	phaseStart := time.Now()
+	tokens := make([]string, len(endpoint.Personas))
+	for i, check := range endpoint.Personas {
+		persona := r.persona(check.Persona)
+		if persona == nil {
+			return result.fail(PhaseAuth, phaseStart, "Authentication failed", fmt.Errorf("unknown persona %q", check.Persona))
+		}
+		token, err := r.acquireToken(ctx, endpoint, tokenProvider, persona.ClientID, persona.ClientSecret, persona.TenantID, endpoint.Scope, result)
+		if err != nil {
+			return result.fail(PhaseAuth, phaseStart, fmt.Sprintf("Authentication failed (persona %s)", check.Persona), err)
+		}
+		tokens[i] = token
+	}
+	result.phase(PhaseAuth, phaseStart, true)
+
+	request, ok := r.prepare(ctx, endpoint, "", result)
+	if !ok {
+		return result
+	}
+
+	phaseStart = time.Now()
+	responses := make([]*client.Response, len(endpoint.Personas))
+	for i, check := range endpoint.Personas {
+		personaRequest := *request
+		personaRequest.AccessToken = tokens[i]
+
+		r.debug(endpoint, "sending request as persona", "persona", check.Persona)
+		response, err := r.send(ctx, endpoint, &personaRequest, check.Persona, result)
+		if err != nil {
+			return result.fail(PhaseConnect, phaseStart, fmt.Sprintf("Request failed (persona %s)", check.Persona), err)
+		}
+		responses[i] = response
+	}
+	result.phase(PhaseConnect, phaseStart, true)
+	result.StatusCode = responses[0].StatusCode
+	result.Warnings = append(result.Warnings, responses[0].DeprecationWarnings()...)
+	for _, response := range responses {
+		if !checkSignInPage(response, result) {
+			return result
+		}
+	}
+
+	phaseStart = time.Now()
+	result.Assertions = make([]AssertionResult, len(endpoint.Personas))
+	var failures []error
+	for i, check := range endpoint.Personas {
+		var err error
+		if responses[i].StatusCode != check.ExpectStatus {
+			err = fmt.Errorf("persona %s: expected status %d, got %d", check.Persona, check.ExpectStatus, responses[i].StatusCode)
+			failures = append(failures, err)
+		}
+		result.Assertions[i] = AssertionResult{
+			Err:         err,
+			Description: fmt.Sprintf("persona %s receives status %d", check.Persona, check.ExpectStatus),
+		}
+	}
+	if len(failures) > 0 {
+		return result.fail(PhaseResponse, phaseStart, "Persona check failed", &AssertionError{Failures: failures})
+	}
+
+	result.phase(PhaseResponse, phaseStart, true)
+	return r.checkPerformance(endpoint, result)
+}
@@ -0,0 +1,109 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/hutstep/entra-id-api-tester/internal/config"
+)
+
+// clientIDTokenProvider issues the client ID as the token, so the test
+// server can tell personas apart
+type clientIDTokenProvider struct{}
+
+func (clientIDTokenProvider) GetAccessToken(ctx context.Context, clientID, clientSecret, tenantID, scope string) (string, error) {
+	if clientID == "locked-out" {
+		return "", errors.New("AADSTS53003: blocked by Conditional Access")
+	}
+	return clientID, nil
+}
+
+func TestRun_Personas(t *testing.T) {
+	personas := []config.Persona{
+		{Name: "reader", ClientID: "reader-id", ClientSecret: "secret", TenantID: "tenant"},
+		{Name: "admin", ClientID: "admin-id", ClientSecret: "secret", TenantID: "tenant"},
+		{Name: "blocked", ClientID: "locked-out", ClientSecret: "secret", TenantID: "tenant"},
+	}
+
+	tests := []struct {
+		name      string
+		checks    []config.PersonaCheck
+		expectErr error
+	}{
+		{
+			name: "policies enforced",
+			checks: []config.PersonaCheck{
+				{Persona: "reader", ExpectStatus: http.StatusForbidden},
+				{Persona: "admin", ExpectStatus: http.StatusOK},
+			},
+		},
+		{
+			name: "restricted persona admitted",
+			checks: []config.PersonaCheck{
+				{Persona: "reader", ExpectStatus: http.StatusOK},
+				{Persona: "admin", ExpectStatus: http.StatusOK},
+			},
+			expectErr: ErrResponse,
+		},
+		{
+			name:      "token blocked",
+			checks:    []config.PersonaCheck{{Persona: "blocked", ExpectStatus: http.StatusForbidden}},
+			expectErr: ErrAuth,
+		},
+		{
+			name:      "unknown persona",
+			checks:    []config.PersonaCheck{{Persona: "guest", ExpectStatus: http.StatusForbidden}},
+			expectErr: ErrAuth,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner, endpoint := newTestRunner(t, func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("Authorization") == "Bearer admin-id" {
+					w.WriteHeader(http.StatusOK)
+					return
+				}
+				w.WriteHeader(http.StatusForbidden)
+			})
+			runner.Personas = personas
+			endpoint.Personas = tt.checks
+
+			result := runner.Run(context.Background(), endpoint, clientIDTokenProvider{})
+
+			if tt.expectErr == nil {
+				if !result.Success() {
+					t.Fatalf("Expected success, got %v", result.Err)
+				}
+				if len(result.Attempts) != len(tt.checks) || result.Attempts[0].Label != tt.checks[0].Persona {
+					t.Errorf("Expected one attempt per persona, got %+v", result.Attempts)
+				}
+				return
+			}
+			if !errors.Is(result.Err, tt.expectErr) {
+				t.Errorf("Expected error to match %v, got %v", tt.expectErr, result.Err)
+			}
+		})
+	}
+}
+
+func TestRun_PersonaAssertions(t *testing.T) {
+	runner, endpoint := newTestRunner(t, respond(http.StatusOK, `{}`))
+	runner.Personas = []config.Persona{{Name: "reader", ClientID: "reader-id", ClientSecret: "secret", TenantID: "tenant"}}
+	endpoint.Personas = []config.PersonaCheck{{Persona: "reader", ExpectStatus: http.StatusForbidden}}
+
+	result := runner.Run(context.Background(), endpoint, clientIDTokenProvider{})
+
+	var assertionErr *AssertionError
+	if !errors.As(result.Err, &assertionErr) || len(assertionErr.Failures) != 1 {
+		t.Fatalf("Expected one persona failure, got %v", result.Err)
+	}
+	if len(result.Assertions) != 1 || result.Assertions[0].Passed() {
+		t.Errorf("Expected a failed persona assertion, got %+v", result.Assertions)
+	}
+	if got := result.Err.Error(); got != "Persona check failed: persona reader: expected status 403, got 200" {
+		t.Errorf("Unexpected message: %q", got)
+	}
+}
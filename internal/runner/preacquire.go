@@ -0,0 +1,188 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/hutstep/entra-id-api-tester/internal/auth"
+	"github.com/hutstep/entra-id-api-tester/internal/config"
+	"github.com/hutstep/entra-id-api-tester/internal/redact"
+)
+
+// maxPreAcquisitions is how many tokens PreAcquireTokens acquires at once
+const maxPreAcquisitions = 8
+
+// preAcquiredExpiryMargin is how long before its expiry a pre-acquired
+// token is no longer handed out, so that long runs acquire a fresh one
+const preAcquiredExpiryMargin = time.Minute
+
+// TokenAcquisition is the outcome of acquiring one distinct token before
+// the endpoints run
+type TokenAcquisition struct {
+	TenantID string
+	ClientID string
+	Scope    string
+	// Endpoints are the names of the endpoints that use the token
+	Endpoints []string
+	Duration  time.Duration
+	// Err is nil when the token was acquired
+	Err error
+}
+
+// credential is a token an endpoint acquires: the credentials, the scope
+// and the token proxy, region and Key Vault certificate it is acquired
+// through
+type credential struct {
+	proxyURL     string
+	tenantID     string
+	clientID     string
+	clientSecret string
+	scope        string
+	region       string
+	certificate  auth.KeyVaultCertificate
+}
+
+// acquired is the outcome of a pre-acquisition, as handed out to endpoints
+type acquired struct {
+	token string
+	err   error
+}
+
+// PreAcquireTokens acquires every distinct token the endpoints need, in
+// parallel, before any endpoint runs, so that authentication failures are
+// reported together at the start and the endpoints' durations measure the
+// API calls alone. It returns providers that hand out the pre-acquired
+// tokens, and their failures, in place of providers, and the outcome of
+// each acquisition in the order the endpoints first need them. Endpoints
+// with a static token, a managed identity or a federated credential
+// acquire nothing.
+func PreAcquireTokens(ctx context.Context, endpoints []config.Endpoint, personas []config.Persona, providers map[string]auth.TokenProvider) (map[string]auth.TokenProvider, []TokenAcquisition) {
+	var credentials []credential
+	acquisitions := map[credential]*TokenAcquisition{}
+	for i := range endpoints {
+		endpoint := &endpoints[i]
+		for _, c := range endpointCredentials(endpoint, personas) {
+			acquisition, ok := acquisitions[c]
+			if !ok {
+				acquisition = &TokenAcquisition{TenantID: c.tenantID, ClientID: c.clientID, Scope: c.scope}
+				acquisitions[c] = acquisition
+				credentials = append(credentials, c)
+			}
+			if n := len(acquisition.Endpoints); n == 0 || acquisition.Endpoints[n-1] != endpoint.Name {
+				acquisition.Endpoints = append(acquisition.Endpoints, endpoint.Name)
+			}
+		}
+	}
+
+	tokens := make([]acquired, len(credentials))
+	slots := make(chan struct{}, maxPreAcquisitions)
+	var wg sync.WaitGroup
+	for i, c := range credentials {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			start := time.Now()
+			token, err := providers[c.proxyURL].GetAccessToken(auth.WithKeyVaultCertificate(auth.WithRegion(ctx, c.region), c.certificate), c.clientID, c.clientSecret, c.tenantID, c.scope)
+			if err == nil {
+				redact.Register(token)
+			}
+			tokens[i] = acquired{token: token, err: err}
+			acquisitions[c].Duration = time.Since(start)
+			acquisitions[c].Err = err
+		}()
+	}
+	wg.Wait()
+
+	wrapped := make(map[string]*preAcquiredTokenProvider, len(providers))
+	preAcquired := make(map[string]auth.TokenProvider, len(providers))
+	for proxyURL, provider := range providers {
+		wrapped[proxyURL] = &preAcquiredTokenProvider{provider: provider, tokens: map[credential]acquired{}}
+		preAcquired[proxyURL] = wrapped[proxyURL]
+	}
+	results := make([]TokenAcquisition, len(credentials))
+	for i, c := range credentials {
+		// A cancelled acquisition is not an answer from Entra ID: endpoints
+		// acquire the token themselves
+		if !errors.Is(tokens[i].err, context.Canceled) && !errors.Is(tokens[i].err, context.DeadlineExceeded) {
+			key := c
+			key.proxyURL = ""
+			wrapped[c.proxyURL].tokens[key] = tokens[i]
+		}
+		results[i] = *acquisitions[c]
+	}
+	return preAcquired, results
+}
+
+// endpointCredentials returns the tokens the endpoint acquires when it runs
+func endpointCredentials(endpoint *config.Endpoint, personas []config.Persona) []credential {
+	if endpoint.ManagedIdentity || endpoint.FederatedCredential != "" || endpoint.StaticToken != "" && len(endpoint.Personas) == 0 && len(endpoint.Tenants) == 0 && len(endpoint.Scopes) == 0 {
+		return nil
+	}
+
+	var credentials []credential
+	switch {
+	case len(endpoint.Personas) > 0:
+		for _, check := range endpoint.Personas {
+			for i := range personas {
+				if personas[i].Name == check.Persona {
+					credentials = append(credentials, credential{tenantID: personas[i].TenantID, clientID: personas[i].ClientID, clientSecret: personas[i].ClientSecret, scope: endpoint.Scope})
+				}
+			}
+		}
+	case len(endpoint.Tenants) > 0:
+		for _, entry := range tenantMatrix(endpoint) {
+			credentials = append(credentials, credential{tenantID: entry.tenantID, clientID: endpoint.ClientID, clientSecret: endpoint.ClientSecret, scope: entry.scope})
+		}
+	case len(endpoint.Scopes) > 0:
+		for _, entry := range scopeMatrix(endpoint) {
+			credentials = append(credentials, credential{tenantID: entry.tenantID, clientID: endpoint.ClientID, clientSecret: endpoint.ClientSecret, scope: entry.scope})
+		}
+	default:
+		credentials = append(credentials, credential{tenantID: endpoint.TenantID, clientID: endpoint.ClientID, clientSecret: endpoint.ClientSecret, scope: endpoint.Scope})
+	}
+	for i := range credentials {
+		credentials[i].proxyURL = endpoint.TokenProxyURL
+		credentials[i].region = endpoint.AzureRegion
+		if certificate := endpoint.CertificateKeyVault; certificate != nil {
+			credentials[i].certificate = auth.KeyVaultCertificate{Vault: certificate.Vault, Name: certificate.Name}
+		}
+	}
+	return credentials
+}
+
+// preAcquiredTokenProvider hands out the tokens acquired by
+// PreAcquireTokens, and the errors of those that could not be, and
+// acquires any other token, or one about to expire, with provider
+type preAcquiredTokenProvider struct {
+	provider auth.TokenProvider
+	// tokens are keyed by credential without the proxy URL
+	tokens map[credential]acquired
+}
+
+// GetAccessToken returns the pre-acquired token or error, if any
+func (p *preAcquiredTokenProvider) GetAccessToken(ctx context.Context, clientID, clientSecret, tenantID, scope string) (string, error) {
+	if token, ok := p.tokens[credential{tenantID: tenantID, clientID: clientID, clientSecret: clientSecret, scope: scope, region: auth.Region(ctx), certificate: auth.Certificate(ctx)}]; ok {
+		if token.err != nil {
+			return "", token.err
+		}
+		if claims, err := auth.ParseClaims(token.token); err != nil || claims.ExpiresAt.IsZero() || time.Until(claims.ExpiresAt) > preAcquiredExpiryMargin {
+			return token.token, nil
+		}
+	}
+	return p.provider.GetAccessToken(ctx, clientID, clientSecret, tenantID, scope)
+}
+
+// GetAccessTokenWithClaims acquires a fresh token with provider, as a
+// claims challenge cannot be answered with a pre-acquired token
+func (p *preAcquiredTokenProvider) GetAccessTokenWithClaims(ctx context.Context, clientID, clientSecret, tenantID, scope, claims string) (string, error) {
+	provider, ok := p.provider.(auth.ClaimsTokenProvider)
+	if !ok {
+		return "", errors.New("the token provider cannot acquire tokens with claims")
+	}
+	return provider.GetAccessTokenWithClaims(ctx, clientID, clientSecret, tenantID, scope, claims)
+}
@@ -0,0 +1,90 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/hutstep/entra-id-api-tester/internal/auth"
+	"github.com/hutstep/entra-id-api-tester/internal/config"
+)
+
+// countingTokenProvider acquires tokens named after their scope, failing for
+// the scope "denied", and counts its acquisitions
+type countingTokenProvider struct {
+	mu     sync.Mutex
+	scopes []string
+}
+
+func (p *countingTokenProvider) GetAccessToken(ctx context.Context, clientID, clientSecret, tenantID, scope string) (string, error) {
+	p.mu.Lock()
+	p.scopes = append(p.scopes, scope)
+	p.mu.Unlock()
+	if scope == "denied" {
+		return "", errors.New("AADSTS65001: consent required")
+	}
+	return "token-" + scope, nil
+}
+
+func TestPreAcquireTokens(t *testing.T) {
+	endpoints := []config.Endpoint{
+		{Name: "List", TenantID: "tenant", ClientID: "client", ClientSecret: "secret", Scope: "orders"},
+		{Name: "Get", TenantID: "tenant", ClientID: "client", ClientSecret: "secret", Scope: "orders"},
+		{Name: "Admin", TenantID: "tenant", ClientID: "client", ClientSecret: "secret", Scope: "denied"},
+		{Name: "Static", StaticToken: "static", Scope: "static"},
+		{Name: "Personas", Scope: "orders", Personas: []config.PersonaCheck{{Persona: "reader", ExpectStatus: 200}}},
+	}
+	personas := []config.Persona{{Name: "reader", TenantID: "tenant", ClientID: "reader", ClientSecret: "reader-secret"}}
+	inner := &countingTokenProvider{}
+
+	providers, acquisitions := PreAcquireTokens(context.Background(), endpoints, personas, map[string]auth.TokenProvider{"": inner})
+
+	if len(acquisitions) != 3 {
+		t.Fatalf("Expected 3 distinct tokens, got %+v", acquisitions)
+	}
+	if got := acquisitions[0]; got.Scope != "orders" || got.ClientID != "client" || got.Err != nil || !slices.Equal(got.Endpoints, []string{"List", "Get"}) {
+		t.Errorf("Unexpected first acquisition: %+v", got)
+	}
+	if got := acquisitions[1]; got.Scope != "denied" || got.Err == nil || !slices.Equal(got.Endpoints, []string{"Admin"}) {
+		t.Errorf("Expected the denied token to fail for Admin, got %+v", got)
+	}
+	if got := acquisitions[2]; got.ClientID != "reader" || got.Err != nil {
+		t.Errorf("Expected the persona's token, got %+v", got)
+	}
+
+	// Endpoints get the pre-acquired tokens and failures without acquiring
+	// them again
+	provider := providers[""]
+	if token, err := provider.GetAccessToken(context.Background(), "client", "secret", "tenant", "orders"); token != "token-orders" || err != nil {
+		t.Errorf("Expected the pre-acquired token, got %q (%v)", token, err)
+	}
+	if _, err := provider.GetAccessToken(context.Background(), "client", "secret", "tenant", "denied"); err == nil {
+		t.Error("Expected the pre-acquisition's failure")
+	}
+	if len(inner.scopes) != 3 {
+		t.Errorf("Expected no further acquisitions, got %v", inner.scopes)
+	}
+	if token, _ := provider.GetAccessToken(context.Background(), "client", "secret", "tenant", "other"); token != "token-other" || len(inner.scopes) != 4 {
+		t.Errorf("Expected other tokens to be acquired, got %q", token)
+	}
+}
+
+func TestRun_PreAcquiredToken(t *testing.T) {
+	var authorization string
+	runner, endpoint := newTestRunner(t, func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+	})
+	inner := &countingTokenProvider{}
+	providers, _ := PreAcquireTokens(context.Background(), []config.Endpoint{*endpoint}, nil, map[string]auth.TokenProvider{"": inner})
+
+	result := runner.Run(context.Background(), endpoint, providers[""])
+	if !result.Success() {
+		t.Fatalf("Expected success, got %v", result.Err)
+	}
+	if authorization != "Bearer token-scope" || len(inner.scopes) != 1 {
+		t.Errorf("Expected the pre-acquired token to be sent once acquired, got %q after %d acquisition(s)", authorization, len(inner.scopes))
+	}
+}
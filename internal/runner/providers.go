@@ -0,0 +1,105 @@
+package runner
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/hutstep/entra-id-api-tester/internal/auth"
+	"github.com/hutstep/entra-id-api-tester/internal/client"
+	"github.com/hutstep/entra-id-api-tester/internal/config"
+)
+
+// TokenProviders creates one token provider per distinct tokenProxyUrl, so
+// endpoints with different token proxies each reach Entra ID the right way.
+// Concurrent acquisitions of the same token through a provider share a
+// single request.
+func TokenProviders(endpoints []config.Endpoint) (map[string]auth.TokenProvider, error) {
+	// Every provider has a transport of its own, which the global and the
+	// regional token endpoints are both reached through. Without an
+	// explicit proxy it honors HTTP_PROXY, HTTPS_PROXY and NO_PROXY.
+	proxyURLs := []string{""}
+	for i := range endpoints {
+		proxyURLs = append(proxyURLs, endpoints[i].TokenProxyURL)
+	}
+
+	providers := map[string]auth.TokenProvider{}
+	for _, proxyURL := range proxyURLs {
+		if _, ok := providers[proxyURL]; ok {
+			continue
+		}
+		tokenClient, err := TokenClient(proxyURL)
+		if err != nil {
+			return nil, err
+		}
+		provider := auth.NewEntraIDTokenProviderWithTransport(30*time.Second, tokenClient)
+		providers[proxyURL] = auth.NewSingleflightTokenProvider(provider)
+	}
+
+	return providers, nil
+}
+
+// TokenClient returns the HTTP client that acquires tokens through the
+// tokenProxyUrl proxyURL
+func TokenClient(proxyURL string) (*http.Client, error) {
+	transport, err := client.NewTransport(client.TransportOptions{ProxyURL: proxyURL})
+	if err != nil {
+		return nil, err
+	}
+	return &http.Client{Transport: transport}, nil
+}
+
+// HostTokenProviders returns the managed identity and GitHub Actions token
+// providers of endpoints with the tokenProxyUrl proxyURL. Without a proxy the
+// SDK default transport honors HTTP_PROXY, HTTPS_PROXY and NO_PROXY.
+func HostTokenProviders(proxyURL string) (managedIdentity, gitHubActions auth.TokenProvider, err error) {
+	if proxyURL == "" {
+		return auth.NewManagedIdentityTokenProvider(), auth.NewGitHubActionsTokenProvider(), nil
+	}
+	tokenClient, err := TokenClient(proxyURL)
+	if err != nil {
+		return nil, nil, err
+	}
+	return auth.NewManagedIdentityTokenProviderWithTransport(30*time.Second, tokenClient), auth.NewGitHubActionsTokenProviderWithClient(tokenClient), nil
+}
+
+// hostProviders are the managed identity and GitHub Actions token providers
+// of a tokenProxyUrl
+type hostProviders struct {
+	managedIdentity auth.TokenProvider
+	gitHubActions   auth.TokenProvider
+}
+
+// hostTokenProviders returns the managed identity and GitHub Actions token
+// providers of endpoint: ManagedIdentity and GitHubActions without a
+// tokenProxyUrl, or else ones for its proxy, created on first use
+func (r *Runner) hostTokenProviders(endpoint *config.Endpoint) (managedIdentity, gitHubActions auth.TokenProvider, err error) {
+	if endpoint.TokenProxyURL == "" {
+		return r.ManagedIdentity, r.GitHubActions, nil
+	}
+
+	r.proxiedMu.Lock()
+	defer r.proxiedMu.Unlock()
+	if providers, ok := r.proxied[endpoint.TokenProxyURL]; ok {
+		return providers.managedIdentity, providers.gitHubActions, nil
+	}
+	managedIdentity, gitHubActions, err = HostTokenProviders(endpoint.TokenProxyURL)
+	if err != nil {
+		return nil, nil, err
+	}
+	if r.proxied == nil {
+		r.proxied = map[string]hostProviders{}
+	}
+	r.proxied[endpoint.TokenProxyURL] = hostProviders{managedIdentity: managedIdentity, gitHubActions: gitHubActions}
+	return managedIdentity, gitHubActions, nil
+}
+
+// FailedDependency returns the name of the first dependency of endpoint that
+// did not pass, or "" when all dependencies passed
+func FailedDependency(endpoint *config.Endpoint, passed map[string]bool) string {
+	for _, dependency := range endpoint.DependsOn {
+		if !passed[dependency] {
+			return dependency
+		}
+	}
+	return ""
+}
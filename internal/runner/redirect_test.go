@@ -0,0 +1,74 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hutstep/entra-id-api-tester/internal/config"
+)
+
+func TestRun_Redirects(t *testing.T) {
+	follow := false
+	tests := []struct {
+		followRedirects *bool
+		name            string
+		path            string
+		assertions      []config.Assertion
+		maxRedirects    int
+		redirects       int
+		expectErr       string
+	}{
+		{name: "followed", path: "/exports/42", redirects: 1},
+		{
+			name:            "not followed",
+			path:            "/exports/42",
+			followRedirects: &follow,
+			assertions:      []config.Assertion{{Status: 302}, {Header: "Location", Matches: `/blob\?sig=`}},
+		},
+		{name: "chain within the limit", path: "/legacy/exports/42", maxRedirects: 2, redirects: 2},
+		{name: "chain over the limit", path: "/legacy/exports/42", maxRedirects: 1, expectErr: "stopped after 1 redirects"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner, endpoint := newTestRunner(t, func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/legacy/exports/42":
+					http.Redirect(w, r, "/exports/42", http.StatusMovedPermanently)
+				case "/exports/42":
+					http.Redirect(w, r, "/blob?sig=signature", http.StatusFound)
+				default:
+					_, _ = w.Write([]byte("PK"))
+				}
+			})
+			endpoint.URL += tt.path
+			endpoint.FollowRedirects = tt.followRedirects
+			endpoint.MaxRedirects = tt.maxRedirects
+			endpoint.Assertions = tt.assertions
+
+			result := runner.Run(context.Background(), endpoint, &fakeTokenProvider{token: "token"})
+
+			if tt.expectErr != "" {
+				if !errors.Is(result.Err, ErrConnect) || !strings.Contains(result.Err.Error(), tt.expectErr) {
+					t.Errorf("Expected a request failure %q, got %v", tt.expectErr, result.Err)
+				}
+				return
+			}
+			if !result.Success() {
+				t.Fatalf("Expected success, got %v", result.Err)
+			}
+			if len(result.Redirects) != tt.redirects {
+				t.Fatalf("Expected %d redirect(s), got %+v", tt.redirects, result.Redirects)
+			}
+			if tt.redirects > 0 {
+				last := result.Redirects[len(result.Redirects)-1]
+				if last.StatusCode != http.StatusFound || !strings.HasSuffix(last.URL, "/exports/42") || !strings.HasSuffix(last.Location, "/blob?sig=signature") {
+					t.Errorf("Unexpected redirect: %+v", last)
+				}
+			}
+		})
+	}
+}
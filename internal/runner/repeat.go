@@ -0,0 +1,57 @@
+package runner
+
+import (
+	"context"
+	"time"
+
+	"github.com/hutstep/entra-id-api-tester/internal/auth"
+	"github.com/hutstep/entra-id-api-tester/internal/config"
+)
+
+// RunRepeated tests the endpoint runs times in a row to tell consistently
+// failing endpoints from flaky ones. The result is that of the first failed
+// run, or of the last run when all passed, with Runs and PassedRuns set,
+// and fails when the runs miss the endpoint's latency objectives. A run cut
+// short by cancellation is returned as it is, like from Run.
+func (r *Runner) RunRepeated(ctx context.Context, endpoint *config.Endpoint, tokenProvider auth.TokenProvider, runs int) *Result {
+	if runs <= 1 {
+		result := r.Run(ctx, endpoint, tokenProvider)
+		if ctx.Err() != nil && !result.Success() {
+			return result
+		}
+		r.delayAfter(ctx, endpoint, result)
+		if duration, ok := requestDuration(result); ok {
+			result = r.checkLatency(endpoint, []time.Duration{duration}, result)
+		}
+		return result
+	}
+
+	var combined *Result
+	var durations []time.Duration
+	var pacingWait time.Duration
+	completed, passed := 0, 0
+	for completed < runs && (completed == 0 || ctx.Err() == nil) {
+		result := r.Run(ctx, endpoint, tokenProvider)
+		if ctx.Err() != nil && !result.Success() {
+			return result
+		}
+		completed++
+		r.delayAfter(ctx, endpoint, result)
+		pacingWait += result.PacingWait
+		if duration, ok := requestDuration(result); ok {
+			durations = append(durations, duration)
+		}
+		if result.Success() {
+			passed++
+		}
+		if combined == nil || (combined.Success() && !result.Success()) {
+			combined = result
+		}
+		r.debug(endpoint, "repeated run finished", "run", completed, "of", runs, "success", result.Success())
+	}
+
+	combined.Runs = completed
+	combined.PassedRuns = passed
+	combined.PacingWait = pacingWait
+	return r.checkLatency(endpoint, durations, combined)
+}
@@ -0,0 +1,69 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+)
+
+func TestRunRepeated(t *testing.T) {
+	tests := []struct {
+		name       string
+		statuses   []int
+		runs       int
+		expectErr  error
+		passedRuns int
+		flaky      bool
+	}{
+		{"all pass", []int{200, 200, 200}, 3, nil, 3, false},
+		{"intermittent failure", []int{200, 500, 200, 503}, 4, ErrResponse, 2, true},
+		{"consistent failure", []int{500, 500}, 2, ErrResponse, 0, false},
+		{"single run", []int{500}, 1, ErrResponse, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			runner, endpoint := newTestRunner(t, func(w http.ResponseWriter, r *http.Request) {
+				call := int(calls.Add(1)) - 1
+				respond(tt.statuses[call%len(tt.statuses)], `{}`)(w, r)
+			})
+
+			result := runner.RunRepeated(context.Background(), endpoint, &fakeTokenProvider{token: "token"}, tt.runs)
+
+			if !errors.Is(result.Err, tt.expectErr) || (tt.expectErr == nil) != (result.Err == nil) {
+				t.Fatalf("Expected %v, got %v", tt.expectErr, result.Err)
+			}
+			if int(calls.Load()) != tt.runs {
+				t.Errorf("Expected %d requests, got %d", tt.runs, calls.Load())
+			}
+			if tt.runs > 1 && (result.Runs != tt.runs || result.PassedRuns != tt.passedRuns) {
+				t.Errorf("Expected %d/%d runs to pass, got %d/%d", tt.passedRuns, tt.runs, result.PassedRuns, result.Runs)
+			}
+			if result.Flaky() != tt.flaky {
+				t.Errorf("Expected flaky=%v, got %v", tt.flaky, result.Flaky())
+			}
+			if tt.flaky && result.StatusCode != tt.statuses[1] {
+				t.Errorf("Expected the first failed run's status %d, got %d", tt.statuses[1], result.StatusCode)
+			}
+		})
+	}
+}
+
+func TestRunRepeated_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var calls atomic.Int32
+	runner, endpoint := newTestRunner(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		respond(http.StatusOK, `{}`)(w, r)
+	})
+
+	result := runner.RunRepeated(ctx, endpoint, &fakeTokenProvider{token: "token"}, 5)
+
+	if result.Success() || result.Runs != 0 || calls.Load() != 0 {
+		t.Errorf("Expected the cancelled run to be returned without repeating, got %d/%d runs and %d request(s)", result.PassedRuns, result.Runs, calls.Load())
+	}
+}
@@ -0,0 +1,23 @@
+package runner
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestRun_RequestIDs(t *testing.T) {
+	runner, endpoint := newTestRunner(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("x-ms-request-id", "6f1c2d3e-0000-4000-8000-000000000001")
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	result := runner.Run(context.Background(), endpoint, &fakeTokenProvider{token: "token"})
+
+	if result.Success() {
+		t.Fatal("Expected failure for 500")
+	}
+	if got := result.RequestIDs()["x-ms-request-id"]; got != "6f1c2d3e-0000-4000-8000-000000000001" {
+		t.Errorf("Expected the response's x-ms-request-id, got %q", got)
+	}
+}
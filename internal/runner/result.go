@@ -0,0 +1,415 @@
+package runner
+
+import (
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/hutstep/entra-id-api-tester/internal/auth"
+	"github.com/hutstep/entra-id-api-tester/internal/client"
+	"github.com/hutstep/entra-id-api-tester/internal/secscan"
+)
+
+// Phase identifies a stage of an endpoint test
+type Phase string
+
+const (
+	// PhaseAuth acquires (and waits for the validity of) the access token
+	PhaseAuth Phase = "auth"
+	// PhasePrepare expands variables and builds the request
+	PhasePrepare Phase = "prepare"
+	// PhaseConnect sends the request and receives a response
+	PhaseConnect Phase = "connect"
+	// PhaseResponse checks the response and captures variables
+	PhaseResponse Phase = "response"
+	// PhaseContract validates the response against the endpoint's OpenAPI
+	// contract and golden file
+	PhaseContract Phase = "contract"
+	// PhasePerformance checks response times against the endpoint's
+	// maximum duration
+	PhasePerformance Phase = "performance"
+	// PhaseSecurity checks that the endpoint rejects requests without valid
+	// credentials
+	PhaseSecurity Phase = "security"
+)
+
+// Sentinel errors for the phase an endpoint test failed in. Every failed
+// Result's Err matches exactly one of them with errors.Is.
+var (
+	ErrAuth        = errors.New("authentication failed")
+	ErrPrepare     = errors.New("request preparation failed")
+	ErrConnect     = errors.New("request failed")
+	ErrResponse    = errors.New("response check failed")
+	ErrContract    = errors.New("contract violated")
+	ErrPerformance = errors.New("response too slow")
+	ErrSecurity    = errors.New("endpoint not protected")
+	ErrSkipped     = errors.New("skipped")
+)
+
+// sentinel returns the sentinel error for the phase
+func (p Phase) sentinel() error {
+	switch p {
+	case PhaseAuth:
+		return ErrAuth
+	case PhasePrepare:
+		return ErrPrepare
+	case PhaseConnect:
+		return ErrConnect
+	case PhaseContract:
+		return ErrContract
+	case PhasePerformance:
+		return ErrPerformance
+	case PhaseSecurity:
+		return ErrSecurity
+	default:
+		return ErrResponse
+	}
+}
+
+// PhaseError is the failure of an endpoint test in a single phase. It
+// matches the phase's sentinel (e.g. ErrAuth) as well as its cause with
+// errors.Is and errors.As.
+type PhaseError struct {
+	// Err is the underlying cause, or nil when Summary says it all
+	Err error
+	// Summary is the human-readable failure, e.g. "Authentication failed"
+	Summary string
+	Phase   Phase
+}
+
+// Error formats the failure as "Summary: cause"
+func (e *PhaseError) Error() string {
+	if e.Err == nil {
+		return e.Summary
+	}
+	return e.Summary + ": " + e.Err.Error()
+}
+
+// Unwrap returns the phase sentinel and the cause
+func (e *PhaseError) Unwrap() []error {
+	if e.Err == nil {
+		return []error{e.Phase.sentinel()}
+	}
+	return []error{e.Phase.sentinel(), e.Err}
+}
+
+// AssertionError collects the failed assertions of a response
+type AssertionError struct {
+	Failures []error
+}
+
+// Error joins the individual failures with "; "
+func (e *AssertionError) Error() string {
+	messages := make([]string, len(e.Failures))
+	for i, failure := range e.Failures {
+		messages[i] = failure.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Unwrap returns the individual failures
+func (e *AssertionError) Unwrap() []error {
+	return e.Failures
+}
+
+// SkipError reports an endpoint that was not run because a dependency did
+// not pass. It matches ErrSkipped with errors.Is.
+type SkipError struct {
+	Dependency string
+}
+
+// Error describes the failed dependency
+func (e *SkipError) Error() string {
+	return "Skipped (dependency failed: " + e.Dependency + ")"
+}
+
+// Is reports whether target is ErrSkipped
+func (e *SkipError) Is(target error) bool {
+	return target == ErrSkipped
+}
+
+// PhaseResult records the outcome and timing of one phase
+type PhaseResult struct {
+	Phase    Phase
+	Duration time.Duration
+	Passed   bool
+}
+
+// Attempt records a single request sent for an endpoint. Locale matrix and
+// race tests send several.
+type Attempt struct {
+	// Err is set when no response was received
+	Err error
+	// Label distinguishes attempts, e.g. the Accept-Language value
+	Label string
+	// TraceID is the trace ID of the traceparent header sent, or "" when
+	// the request was not traced
+	TraceID string
+	// RequestIDs are the response's diagnostic request ID headers, e.g.
+	// x-ms-request-id, by header name
+	RequestIDs map[string]string
+	// Connection describes the connection the request was sent over; only
+	// its RemoteAddr, the last address tried, is set when it failed to
+	// connect
+	Connection client.Connection
+	// Protocol is the protocol the response was received over, e.g.
+	// "HTTP/2.0"
+	Protocol        string
+	Duration        time.Duration
+	ThrottleWait    time.Duration
+	StatusCode      int
+	ThrottleRetries int
+}
+
+// AssertionResult records the outcome of one configured assertion
+type AssertionResult struct {
+	// Err is nil when the assertion passed
+	Err         error
+	Description string
+}
+
+// Passed reports whether the assertion held
+func (a *AssertionResult) Passed() bool {
+	return a.Err == nil
+}
+
+// Result is the outcome of testing a single endpoint
+type Result struct {
+	// Err is nil when the endpoint passed. Otherwise it is a *PhaseError,
+	// or a *SkipError when a dependency failed.
+	Err          error
+	EndpointName string
+	// Method and URL are the endpoint's configured request, before
+	// placeholders are expanded
+	Method string
+	URL    string
+	// ClaimsChallenge is the outcome of a CAE claims challenge, e.g.
+	// ClaimsChallengeSatisfied, or "" when the API sent none
+	ClaimsChallenge string
+	Warnings        []string
+	// Diagnoses explain a 401 or 403 from the claims of the token sent
+	Diagnoses []string
+	// Token is the validity of the last token the endpoint acquired, when
+	// it is a JWT with an expiry
+	Token TokenValidity
+	// Groups are the object IDs in the groups claim of the endpoint's token
+	Groups []string
+	// GroupsOverage reports that the token left out its groups claim
+	// because the caller is in too many groups
+	GroupsOverage bool
+	// TokenServer is the Entra ID server that issued the endpoint's token,
+	// when the endpoint acquired it from Entra ID rather than a cache
+	TokenServer auth.TokenServer
+	// Body is the body of the first response when the runner keeps bodies
+	Body []byte
+	// Security grades the first response's security headers when the
+	// runner scans them
+	Security *secscan.Result
+	// Redirects is the redirect chain of the first redirected response
+	Redirects []client.Redirect
+	Phases    []PhaseResult
+	Attempts  []Attempt
+	// Warmups are the endpoint's warmup requests, which are not checked
+	Warmups    []Attempt
+	Assertions []AssertionResult
+	// Matrix holds the outcome of each entry of a tenant or scope matrix
+	Matrix []MatrixCell
+	// Latency holds the outcome of each of the endpoint's latency
+	// objectives
+	Latency      []LatencyResult
+	Duration     time.Duration
+	ThrottleWait time.Duration
+	// WarmupDuration is the time spent on warmups, which is not part of
+	// Duration
+	WarmupDuration time.Duration
+	// PacingWait is the time spent waiting for the rate limit and the
+	// endpoint's delayAfterMs, which is not part of Duration
+	PacingWait time.Duration
+	// ProbeDuration is the time spent on negative authentication probes,
+	// pacing aside, which is not part of Duration
+	ProbeDuration time.Duration
+	// OperationDuration is how long an asynchronous operation took, from
+	// the request to its completion
+	OperationDuration time.Duration
+	StatusCode        int
+	// ThrottleRetries is the total number of 429 retries across attempts
+	ThrottleRetries int
+	// Polls is the number of times an asynchronous operation was polled
+	Polls int
+	// Runs and PassedRuns count the repetitions of a repeated endpoint;
+	// both are zero when it ran once
+	Runs       int
+	PassedRuns int
+	Skipped    bool
+	// BodyTruncated reports that a response body exceeded the body limit
+	// and was cut off
+	BodyTruncated bool
+}
+
+// TokenValidity describes how long a token is valid
+type TokenValidity struct {
+	ExpiresAt time.Time
+	// Lifetime is how long the token was issued for
+	Lifetime time.Duration
+	// Remaining is how long the token was still valid when acquired, less
+	// than Lifetime for a token served from a cache
+	Remaining time.Duration
+}
+
+// Success reports whether the endpoint ran and passed all checks
+func (r *Result) Success() bool {
+	return !r.Skipped && r.Err == nil
+}
+
+// Flaky reports whether a repeated endpoint passed some runs but not all
+func (r *Result) Flaky() bool {
+	return r.PassedRuns > 0 && r.PassedRuns < r.Runs
+}
+
+// PassRate returns the percentage of repeated runs that passed
+func (r *Result) PassRate() float64 {
+	if r.Runs == 0 {
+		return 0
+	}
+	return float64(r.PassedRuns) / float64(r.Runs) * 100
+}
+
+// TraceID returns the trace ID of the last traced attempt, or "" when no
+// request was traced
+func (r *Result) TraceID() string {
+	for i := len(r.Attempts) - 1; i >= 0; i-- {
+		if r.Attempts[i].TraceID != "" {
+			return r.Attempts[i].TraceID
+		}
+	}
+	return ""
+}
+
+// RemoteAddr returns the address the last request that reached one was
+// sent to, or "" when none did
+func (r *Result) RemoteAddr() string {
+	for i := len(r.Attempts) - 1; i >= 0; i-- {
+		if r.Attempts[i].Connection.RemoteAddr != "" {
+			return r.Attempts[i].Connection.RemoteAddr
+		}
+	}
+	return ""
+}
+
+// Protocol returns the protocol of the last response, e.g. "HTTP/2.0", or
+// "" when no response was received
+func (r *Result) Protocol() string {
+	for i := len(r.Attempts) - 1; i >= 0; i-- {
+		if r.Attempts[i].Protocol != "" {
+			return r.Attempts[i].Protocol
+		}
+	}
+	return ""
+}
+
+// RequestIDs returns the diagnostic request IDs of the last response that
+// had any, or nil when none had
+func (r *Result) RequestIDs() map[string]string {
+	for i := len(r.Attempts) - 1; i >= 0; i-- {
+		if len(r.Attempts[i].RequestIDs) > 0 {
+			return r.Attempts[i].RequestIDs
+		}
+	}
+	return nil
+}
+
+// Passed reports whether the given phase ran and succeeded. A phase that
+// failed after it was first recorded as passed, such as authentication
+// when the response turns out to be the sign-in page, did not succeed.
+func (r *Result) Passed(phase Phase) bool {
+	passed := false
+	for _, result := range r.Phases {
+		if result.Phase == phase {
+			passed = result.Passed
+		}
+	}
+	return passed
+}
+
+// FailedPhase returns the phase the endpoint failed in, or "" when it
+// passed or was skipped
+func (r *Result) FailedPhase() Phase {
+	var phaseErr *PhaseError
+	if errors.As(r.Err, &phaseErr) {
+		return phaseErr.Phase
+	}
+	return ""
+}
+
+// LogValue summarizes the result as structured log attributes
+func (r *Result) LogValue() slog.Value {
+	attrs := []slog.Attr{
+		slog.String("endpoint", r.EndpointName),
+		slog.Bool("success", r.Success()),
+		slog.Bool("skipped", r.Skipped),
+		slog.Int("status", r.StatusCode),
+		slog.Int64("duration_ms", r.Duration.Milliseconds()),
+		slog.Int("attempts", len(r.Attempts)),
+	}
+	if phase := r.FailedPhase(); phase != "" {
+		attrs = append(attrs, slog.String("failed_phase", string(phase)))
+	}
+	if r.Err != nil {
+		attrs = append(attrs, slog.String("error", r.Err.Error()), slog.String("error_code", string(r.ErrorCode())))
+	}
+	if traceID := r.TraceID(); traceID != "" {
+		attrs = append(attrs, slog.String("trace_id", traceID))
+	}
+	if protocol := r.Protocol(); protocol != "" {
+		attrs = append(attrs, slog.String("protocol", protocol))
+	}
+	if remoteAddr := r.RemoteAddr(); remoteAddr != "" {
+		attrs = append(attrs, slog.String("remote_addr", remoteAddr), slog.String("address_family", client.AddressFamily(remoteAddr)))
+	}
+	if !r.Token.ExpiresAt.IsZero() {
+		attrs = append(attrs, slog.Time("token_expires_on", r.Token.ExpiresAt))
+	}
+	if r.TokenServer.Host != "" {
+		attrs = append(attrs, slog.String("token_host", r.TokenServer.Host), slog.String("token_server", r.TokenServer.Server))
+	}
+	if requestIDs := r.RequestIDs(); requestIDs != nil {
+		attrs = append(attrs, slog.Any("request_ids", requestIDs))
+	}
+	if r.ThrottleRetries > 0 {
+		attrs = append(attrs, slog.Int("throttle_retries", r.ThrottleRetries))
+	}
+	if r.ClaimsChallenge != "" {
+		attrs = append(attrs, slog.String("claims_challenge", r.ClaimsChallenge))
+	}
+	if r.Runs > 0 {
+		attrs = append(attrs, slog.Int("runs", r.Runs), slog.Int("passed_runs", r.PassedRuns), slog.Bool("flaky", r.Flaky()))
+	}
+	if len(r.Warnings) > 0 {
+		attrs = append(attrs, slog.Any("warnings", r.Warnings))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// phase records the outcome of a phase that started at started
+func (r *Result) phase(phase Phase, started time.Time, passed bool) {
+	r.Phases = append(r.Phases, PhaseResult{
+		Phase:    phase,
+		Duration: time.Since(started),
+		Passed:   passed,
+	})
+}
+
+// fail records a failed phase and sets Err
+func (r *Result) fail(phase Phase, started time.Time, summary string, err error) *Result {
+	r.phase(phase, started, false)
+	r.Err = &PhaseError{Phase: phase, Summary: summary, Err: err}
+	return r
+}
+
+// addAttempt records an attempt and accumulates its throttling
+func (r *Result) addAttempt(attempt Attempt) {
+	r.Attempts = append(r.Attempts, attempt)
+	r.ThrottleRetries += attempt.ThrottleRetries
+	r.ThrottleWait += attempt.ThrottleWait
+}
@@ -0,0 +1,131 @@
+package runner
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPhaseError_Is(t *testing.T) {
+	cause := errors.New("invalid_client")
+	err := error(&PhaseError{Phase: PhaseAuth, Summary: "Authentication failed", Err: cause})
+
+	if !errors.Is(err, ErrAuth) {
+		t.Error("Expected error to match ErrAuth")
+	}
+	if !errors.Is(err, cause) {
+		t.Error("Expected error to match its cause")
+	}
+	if errors.Is(err, ErrConnect) {
+		t.Error("Expected error not to match ErrConnect")
+	}
+	if got := err.Error(); got != "Authentication failed: invalid_client" {
+		t.Errorf("Unexpected message: %q", got)
+	}
+}
+
+func TestPhaseError_WithoutCause(t *testing.T) {
+	err := error(&PhaseError{Phase: PhaseResponse, Summary: "Unexpected status code: 500"})
+
+	if !errors.Is(err, ErrResponse) {
+		t.Error("Expected error to match ErrResponse")
+	}
+	if got := err.Error(); got != "Unexpected status code: 500" {
+		t.Errorf("Unexpected message: %q", got)
+	}
+}
+
+func TestAssertionError(t *testing.T) {
+	first := errors.New("status: expected 200, got 404")
+	err := error(&PhaseError{
+		Phase:   PhaseResponse,
+		Summary: "Assertion failed",
+		Err:     &AssertionError{Failures: []error{first, errors.New("jsonPath $.id: not found")}},
+	})
+
+	var assertionErr *AssertionError
+	if !errors.As(err, &assertionErr) {
+		t.Fatal("Expected error to contain an AssertionError")
+	}
+	if len(assertionErr.Failures) != 2 {
+		t.Errorf("Expected 2 failures, got %d", len(assertionErr.Failures))
+	}
+	if !errors.Is(err, first) {
+		t.Error("Expected error to match an individual failure")
+	}
+	if got := err.Error(); got != "Assertion failed: status: expected 200, got 404; jsonPath $.id: not found" {
+		t.Errorf("Unexpected message: %q", got)
+	}
+}
+
+func TestSkipError(t *testing.T) {
+	err := error(&SkipError{Dependency: "Create Order"})
+
+	if !errors.Is(err, ErrSkipped) {
+		t.Error("Expected error to match ErrSkipped")
+	}
+	if got := err.Error(); got != "Skipped (dependency failed: Create Order)" {
+		t.Errorf("Unexpected message: %q", got)
+	}
+}
+
+func TestResult_Phases(t *testing.T) {
+	result := &Result{}
+	result.phase(PhaseAuth, time.Now(), true)
+	result.phase(PhasePrepare, time.Now(), true)
+	result.fail(PhaseConnect, time.Now(), "Request failed", errors.New("connection refused"))
+
+	if result.Success() {
+		t.Error("Expected failed result")
+	}
+	if !result.Passed(PhaseAuth) || !result.Passed(PhasePrepare) {
+		t.Error("Expected auth and prepare phases to pass")
+	}
+	if result.Passed(PhaseConnect) || result.Passed(PhaseResponse) {
+		t.Error("Expected connect and response phases not to pass")
+	}
+	if got := result.FailedPhase(); got != PhaseConnect {
+		t.Errorf("Expected failed phase %q, got %q", PhaseConnect, got)
+	}
+	if !errors.Is(result.Err, ErrConnect) {
+		t.Error("Expected Err to match ErrConnect")
+	}
+
+	result.fail(PhaseAuth, time.Now(), "Redirected to interactive sign-in", errors.New("sign-in page"))
+	if result.Passed(PhaseAuth) {
+		t.Error("Expected a later auth failure to override the passed auth phase")
+	}
+}
+
+func TestResult_AddAttempt(t *testing.T) {
+	result := &Result{}
+	result.addAttempt(Attempt{StatusCode: 200, ThrottleRetries: 1, ThrottleWait: time.Second})
+	result.addAttempt(Attempt{StatusCode: 200, ThrottleRetries: 2, ThrottleWait: 2 * time.Second})
+
+	if len(result.Attempts) != 2 {
+		t.Errorf("Expected 2 attempts, got %d", len(result.Attempts))
+	}
+	if result.ThrottleRetries != 3 {
+		t.Errorf("Expected 3 throttle retries, got %d", result.ThrottleRetries)
+	}
+	if result.ThrottleWait != 3*time.Second {
+		t.Errorf("Expected 3s throttle wait, got %v", result.ThrottleWait)
+	}
+}
+
+func TestResult_LogValue(t *testing.T) {
+	result := &Result{EndpointName: "Users", StatusCode: 500}
+	result.fail(PhaseResponse, time.Now(), "Unexpected status code: 500", nil)
+
+	var buf bytes.Buffer
+	slog.New(slog.NewTextHandler(&buf, nil)).Info("endpoint finished", "result", result)
+
+	for _, expected := range []string{"result.endpoint=Users", "result.success=false", "result.status=500", "result.failed_phase=response", `result.error="Unexpected status code: 500"`} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("Expected %s in %q", expected, buf.String())
+		}
+	}
+}
@@ -0,0 +1,754 @@
+// Package runner executes endpoint tests: it acquires a token, sends the
+// configured request(s) and checks the responses, producing a typed Result
+// per endpoint that callers can inspect with errors.Is and errors.As.
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/hutstep/entra-id-api-tester/internal/assert"
+	"github.com/hutstep/entra-id-api-tester/internal/auth"
+	"github.com/hutstep/entra-id-api-tester/internal/client"
+	"github.com/hutstep/entra-id-api-tester/internal/config"
+	"github.com/hutstep/entra-id-api-tester/internal/logging"
+	"github.com/hutstep/entra-id-api-tester/internal/openapi"
+	"github.com/hutstep/entra-id-api-tester/internal/redact"
+	"github.com/hutstep/entra-id-api-tester/internal/secscan"
+	"github.com/hutstep/entra-id-api-tester/internal/vars"
+)
+
+// DefaultMaxClockSkew is how long a token whose nbf lies in the future is
+// waited for by default
+const DefaultMaxClockSkew = 5 * time.Minute
+
+// apimSubscriptionKeyHeader carries the Azure API Management subscription key
+const apimSubscriptionKeyHeader = "Ocp-Apim-Subscription-Key"
+
+// Runner tests endpoints in sequence, sharing captured variables between them
+type Runner struct {
+	client    *client.APIClient
+	variables *vars.Store
+	// specs caches the OpenAPI descriptions of endpoint contracts by path
+	specs map[string]*openapi.Spec
+	// cookieJars holds the named cookie jars of endpoints
+	cookieJars map[string]http.CookieJar
+	// nextRequest is the earliest time Rate allows the next request, and
+	// requests counts the requests sent
+	nextRequest time.Time
+	requests    int
+	paceMu      sync.Mutex
+	// Logger receives progress at debug level and live warnings, e.g. about
+	// slow requests, at warn level
+	Logger *slog.Logger
+	// Personas are the credentials endpoints with persona checks run as
+	Personas []config.Persona
+	// ManagedIdentity acquires the tokens of endpoints with managedIdentity
+	ManagedIdentity auth.TokenProvider
+	// GitHubActions acquires the tokens of endpoints with the "github"
+	// federatedCredential
+	GitHubActions auth.TokenProvider
+	// proxied holds the managed identity and GitHub Actions token providers
+	// of endpoints with a tokenProxyUrl, by proxy
+	proxied   map[string]hostProviders
+	proxiedMu sync.Mutex
+	// MaxClockSkew is the longest wait for a token to become valid
+	MaxClockSkew time.Duration
+	// SlowThreshold is how long a request may be in flight before it is
+	// reported as slow; endpoints may override it and zero disables it
+	SlowThreshold time.Duration
+	// MaxDuration is the default response time objective; endpoints may
+	// override it and zero disables it
+	MaxDuration time.Duration
+	// MaxBodyBytes is the default limit on how much of a response body is
+	// read; endpoints may override it and zero disables it
+	MaxBodyBytes int64
+	// UpdateGolden records response bodies as the endpoints' golden files
+	// instead of comparing them
+	UpdateGolden bool
+	// KeepBodies keeps the body of each endpoint's first response in its
+	// Result, e.g. to compare responses between environments
+	KeepBodies bool
+	// SecurityScan grades each endpoint's first response against the
+	// security header checklist
+	SecurityScan bool
+	// Rate paces all requests, e.g. to stay below an API's throttling
+	// limit; the zero Rate is unlimited
+	Rate Rate
+	// Trace sends a W3C traceparent header with every request, so that
+	// failures can be correlated with the API's server-side logs
+	Trace bool
+	// MinTokenLifetime is the shortest token lifetime endpoints accept
+	// without a warning, e.g. to notice Conditional Access policies that
+	// shorten it; zero disables the check
+	MinTokenLifetime time.Duration
+	// VerifySecondarySecrets also acquires a token with the
+	// clientSecretSecondary of endpoints that have one, failing them unless
+	// both of their secrets work
+	VerifySecondarySecrets bool
+}
+
+// New creates a Runner that sends requests with apiClient and stores
+// captured values in variables
+func New(apiClient *client.APIClient, variables *vars.Store) *Runner {
+	return &Runner{
+		client:          apiClient,
+		variables:       variables,
+		Logger:          logging.Discard(),
+		ManagedIdentity: auth.NewManagedIdentityTokenProvider(),
+		GitHubActions:   auth.NewGitHubActionsTokenProvider(),
+		MaxClockSkew:    DefaultMaxClockSkew,
+		SlowThreshold:   DefaultSlowThreshold,
+		MaxBodyBytes:    DefaultMaxBodyBytes,
+	}
+}
+
+// Skip returns the result for an endpoint that was not run because the
+// named dependency did not pass
+func Skip(endpoint *config.Endpoint, dependency string) *Result {
+	return &Result{
+		EndpointName: endpoint.Name,
+		Method:       endpoint.Method,
+		URL:          endpoint.URL,
+		Err:          &SkipError{Dependency: dependency},
+		Skipped:      true,
+	}
+}
+
+// Run tests a single endpoint using tokenProvider to authenticate
+func (r *Runner) Run(ctx context.Context, endpoint *config.Endpoint, tokenProvider auth.TokenProvider) *Result {
+	result := &Result{
+		EndpointName: endpoint.Name,
+		Method:       endpoint.Method,
+		URL:          endpoint.URL,
+	}
+
+	startTime := time.Now()
+	defer func() {
+		result.Duration = time.Since(startTime) - result.WarmupDuration - result.PacingWait - result.ProbeDuration
+	}()
+
+	if endpoint.ManagedIdentity || endpoint.FederatedCredential == config.FederatedCredentialGitHub {
+		managedIdentity, gitHubActions, err := r.hostTokenProviders(endpoint)
+		if err != nil {
+			return result.fail(PhaseAuth, startTime, "Authentication failed", err)
+		}
+		if endpoint.ManagedIdentity {
+			r.debug(endpoint, "using managed identity", "source", auth.ManagedIdentitySource())
+			tokenProvider = managedIdentity
+		}
+		if endpoint.FederatedCredential == config.FederatedCredentialGitHub {
+			r.debug(endpoint, "using GitHub Actions OIDC token")
+			tokenProvider = gitHubActions
+		}
+	}
+	if len(endpoint.Personas) > 0 {
+		return r.runPersonas(ctx, endpoint, tokenProvider, result)
+	}
+	if len(endpoint.Tenants) > 0 {
+		return r.runMatrix(ctx, endpoint, tokenProvider, "tenant", tenantMatrix(endpoint), result)
+	}
+	if len(endpoint.Scopes) > 0 {
+		return r.runMatrix(ctx, endpoint, tokenProvider, "scope", scopeMatrix(endpoint), result)
+	}
+
+	// Step 1: Authenticate, unless a token was provided
+	if endpoint.StaticToken != "" {
+		tokenProvider = &auth.StaticTokenProvider{Token: endpoint.StaticToken}
+	}
+	token, ok := r.authenticate(ctx, endpoint, tokenProvider, result)
+	if !ok {
+		return result
+	}
+	if endpoint.DataFile != "" {
+		return r.runDataRows(ctx, endpoint, token, result)
+	}
+
+	// Step 2: Build the request
+	request, ok := r.prepare(ctx, endpoint, token, result)
+	if !ok {
+		return result
+	}
+
+	// Step 3: Enforce the TLS policy, if any
+	if !r.checkTLSPolicy(ctx, endpoint, request, result) {
+		return result
+	}
+
+	// Step 4: Warm up, then send and check
+	r.warmUp(ctx, endpoint, request, result)
+	switch {
+	case len(endpoint.AcceptLanguages) > 0:
+		return r.runLocaleMatrix(ctx, endpoint, request, result)
+	case endpoint.RaceTest != nil:
+		return r.runRaceTest(ctx, endpoint, request, result)
+	}
+
+	phaseStart := time.Now()
+	r.debug(endpoint, "sending request", "method", request.Method, "url", request.URL)
+	response, err := r.send(ctx, endpoint, request, "", result)
+	if err == nil {
+		response, err = r.answerClaimsChallenge(ctx, endpoint, tokenProvider, request, response, result)
+	}
+	if err == nil && endpoint.AsyncOperation {
+		response, err = r.awaitOperation(ctx, endpoint, request, response, phaseStart, result)
+	}
+	var operationErr *OperationError
+	if errors.As(err, &operationErr) {
+		result.phase(PhaseConnect, phaseStart, true)
+		result.StatusCode = response.StatusCode
+		return result.fail(PhaseResponse, time.Now(), "Async operation failed", err)
+	}
+	if err != nil {
+		return result.fail(PhaseConnect, phaseStart, "Request failed", err)
+	}
+	result.phase(PhaseConnect, phaseStart, true)
+	result.StatusCode = response.StatusCode
+	result.Warnings = append(result.Warnings, response.DeprecationWarnings()...)
+	r.debug(endpoint, "request completed", "status", response.StatusCode)
+	if !checkSignInPage(response, result) {
+		return result
+	}
+
+	phaseStart = time.Now()
+	if summary, err := r.checkResponse(ctx, endpoint, response, result); summary != "" {
+		if len(response.Body) > 0 {
+			r.debug(endpoint, "unexpected response", "body", response.DescribeBody())
+		}
+		result.Diagnoses = diagnoseToken(endpoint, request.URL, request.AccessToken, response.StatusCode, time.Now())
+		return result.fail(PhaseResponse, phaseStart, summary, err)
+	}
+	result = r.capture(endpoint, response, phaseStart, result)
+	return r.finish(ctx, endpoint, request, response, result)
+}
+
+// finish runs the checks that follow a passing response: the contract, the
+// golden file, the performance objective and the negative authentication
+// probes
+func (r *Runner) finish(ctx context.Context, endpoint *config.Endpoint, request *client.Request, response *client.Response, result *Result) *Result {
+	result = r.checkContract(endpoint, request, response, result)
+	result = r.checkGolden(endpoint, response, result)
+	result = r.checkPerformance(endpoint, result)
+	return r.checkNegativeAuth(ctx, endpoint, request, result)
+}
+
+// authenticate acquires a token with the endpoint's credentials and, when
+// secrets are verified, checks that its secondary secret works too
+func (r *Runner) authenticate(ctx context.Context, endpoint *config.Endpoint, tokenProvider auth.TokenProvider, result *Result) (string, bool) {
+	phaseStart := time.Now()
+
+	token, err := r.acquireToken(ctx, endpoint, tokenProvider, endpoint.ClientID, endpoint.ClientSecret, endpoint.TenantID, endpoint.Scope, result)
+	if err != nil {
+		result.fail(PhaseAuth, phaseStart, "Authentication failed", err)
+		return "", false
+	}
+	if r.VerifySecondarySecrets && endpoint.ClientSecretSecondary != "" && endpoint.StaticToken == "" {
+		if _, err := r.acquireToken(ctx, endpoint, tokenProvider, endpoint.ClientID, endpoint.ClientSecretSecondary, endpoint.TenantID, endpoint.Scope, result); err != nil {
+			result.fail(PhaseAuth, phaseStart, "Authentication with clientSecretSecondary failed", err)
+			return "", false
+		}
+		r.debug(endpoint, "secondary client secret verified")
+	}
+
+	result.phase(PhaseAuth, phaseStart, true)
+	r.debug(endpoint, "authentication successful")
+	return token, true
+}
+
+// TokenContext returns a context in which the endpoint's tokens are
+// acquired from its azureRegion, if any, with its Key Vault certificate, if
+// any
+func TokenContext(ctx context.Context, endpoint *config.Endpoint) context.Context {
+	ctx = auth.WithRegion(ctx, endpoint.AzureRegion)
+	if certificate := endpoint.CertificateKeyVault; certificate != nil {
+		ctx = auth.WithKeyVaultCertificate(ctx, auth.KeyVaultCertificate{Vault: certificate.Vault, Name: certificate.Name})
+	}
+	return ctx
+}
+
+// acquireToken gets a token for a scope with the given credentials. It
+// reports clock skew as a warning and waits for nbf rather than sending a
+// token the API would consider not yet valid.
+func (r *Runner) acquireToken(ctx context.Context, endpoint *config.Endpoint, tokenProvider auth.TokenProvider, clientID, clientSecret, tenantID, scope string, result *Result) (string, error) {
+	r.debug(endpoint, "authenticating", "tenant", tenantID, "client", clientID, "scope", scope)
+	ctx = TokenContext(ctx, endpoint)
+
+	// A coalesced acquisition may report its server after a cancelled
+	// caller returned
+	var mu sync.Mutex
+	var server auth.TokenServer
+	ctx = auth.WithTokenServer(ctx, func(issued auth.TokenServer) {
+		mu.Lock()
+		defer mu.Unlock()
+		server = issued
+	})
+	token, err := tokenProvider.GetAccessToken(ctx, clientID, clientSecret, tenantID, scope)
+	mu.Lock()
+	if server.Host != "" {
+		result.TokenServer = server
+		r.debug(endpoint, "token endpoint responded", "host", server.Host, "region", server.Region(), "server", server.Server)
+	}
+	mu.Unlock()
+	if err != nil {
+		return "", err
+	}
+	redact.Register(token)
+
+	if claims, err := auth.ParseClaims(token); err == nil {
+		r.recordTokenValidity(endpoint, claims, result)
+		r.recordGroups(endpoint, claims, result)
+		if skew := claims.ClockSkew(time.Now()); skew > 0 {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("Local clock is %v behind the token issuer (clock skew)", skew))
+		}
+		waited, err := claims.WaitUntilValid(ctx, r.MaxClockSkew)
+		if err != nil {
+			return "", err
+		}
+		if waited > 0 {
+			r.debug(endpoint, "waited for token to become valid (nbf)", "waited", waited.Truncate(time.Millisecond))
+		}
+	}
+
+	return token, nil
+}
+
+// recordTokenValidity records when an acquired token expires and warns
+// when it was issued for less than MinTokenLifetime
+func (r *Runner) recordTokenValidity(endpoint *config.Endpoint, claims *auth.Claims, result *Result) {
+	if claims.ExpiresAt.IsZero() {
+		return
+	}
+	result.Token = TokenValidity{
+		ExpiresAt: claims.ExpiresAt,
+		Lifetime:  claims.Lifetime(),
+		Remaining: time.Until(claims.ExpiresAt).Truncate(time.Second),
+	}
+	r.debug(endpoint, "token valid", "expires_on", claims.ExpiresAt, "lifetime", result.Token.Lifetime, "remaining", result.Token.Remaining)
+
+	if r.MinTokenLifetime > 0 && result.Token.Lifetime > 0 && result.Token.Lifetime < r.MinTokenLifetime {
+		warning := fmt.Sprintf("Token lifetime of %v is shorter than the expected %v (a Conditional Access sign-in frequency or token lifetime policy may shorten it)", result.Token.Lifetime, r.MinTokenLifetime)
+		if !slices.Contains(result.Warnings, warning) {
+			result.Warnings = append(result.Warnings, warning)
+		}
+	}
+}
+
+// recordGroups records the groups of an acquired token and warns about a
+// groups overage, which APIs that authorize by group commonly mishandle
+func (r *Runner) recordGroups(endpoint *config.Endpoint, claims *auth.Claims, result *Result) {
+	result.Groups = claims.Groups
+	result.GroupsOverage = claims.GroupsOverage
+	if len(claims.Groups) > 0 {
+		r.debug(endpoint, "token groups", "groups", claims.Groups)
+	}
+	if !claims.GroupsOverage {
+		return
+	}
+	warning := "Token has no groups claim because the caller is in too many groups (groups overage); an API that authorizes by groups must look them up with a Microsoft Graph call, or the app registration should emit only the groups assigned to the application"
+	if !slices.Contains(result.Warnings, warning) {
+		result.Warnings = append(result.Warnings, warning)
+	}
+}
+
+// prepare expands variables and builds the request for the endpoint, which
+// its pre-request hook, if any, may rewrite
+func (r *Runner) prepare(ctx context.Context, endpoint *config.Endpoint, token string, result *Result) (*client.Request, bool) {
+	phaseStart := time.Now()
+
+	request, summary, err := r.buildRequest(ctx, endpoint, r.variables, token)
+	if err != nil {
+		result.fail(PhasePrepare, phaseStart, summary, err)
+		return nil, false
+	}
+	warnInsecure(request, result)
+
+	result.phase(PhasePrepare, phaseStart, true)
+	return request, true
+}
+
+// buildRequest expands the placeholders of the endpoint's request from
+// variables and runs its pre-request hook. On failure it returns a summary
+// of what failed.
+func (r *Runner) buildRequest(ctx context.Context, endpoint *config.Endpoint, variables *vars.Store, token string) (*client.Request, string, error) {
+	url, err := variables.Expand(endpoint.URL)
+	if err != nil {
+		return nil, "Variable substitution failed", err
+	}
+	body, err := variables.ExpandBody(endpoint.RequestBody)
+	if err != nil {
+		return nil, "Variable substitution failed", err
+	}
+	if endpoint.GraphQL != nil {
+		body, err = graphQLBody(variables, endpoint.GraphQL)
+		if err != nil {
+			return nil, "Variable substitution failed", err
+		}
+	}
+
+	request := &client.Request{
+		Method:      endpoint.Method,
+		URL:         url,
+		AccessToken: token,
+		Body:        body,
+
+		MaxThrottleRetries: endpoint.ThrottleRetries(),
+		MaxBodyBytes:       r.maxBodyBytes(endpoint),
+		MaxRedirects:       endpoint.MaxRedirects,
+		NoRedirects:        !endpoint.FollowsRedirects(),
+		Trace:              r.Trace || endpoint.TraceEchoHeader != "",
+	}
+	if len(endpoint.Multipart) > 0 {
+		request.RawBody, request.ContentType, err = multipartBody(variables, endpoint.Multipart)
+		if err != nil {
+			return nil, "Multipart body failed", err
+		}
+	}
+	request.CookieJar = r.cookieJar(endpoint)
+	request.Transport, err = TransportOptions(endpoint)
+	if err != nil {
+		return nil, "Invalid TLS settings", err
+	}
+	request.Headers, err = expandHeaders(variables, requestHeaders(endpoint))
+	if err != nil {
+		return nil, "Variable substitution failed", err
+	}
+	if err := r.runPreRequestHook(ctx, endpoint, request); err != nil {
+		return nil, "Pre-request hook failed", err
+	}
+	if endpoint.BodyType == config.BodyTypeForm && request.Body != nil {
+		request.RawBody, request.ContentType = formBody(request.Body), formContentType
+	}
+	return request, "", nil
+}
+
+// warnInsecure warns when the request skips certificate verification
+func warnInsecure(request *client.Request, result *Result) {
+	if request.Transport != nil && request.Transport.InsecureSkipVerify {
+		result.Warnings = append(result.Warnings, "TLS certificate verification is DISABLED (insecureSkipVerify); responses may come from an impostor")
+	}
+}
+
+// requestHeaders returns the extra headers of the endpoint's requests, or
+// nil when there are none
+func requestHeaders(endpoint *config.Endpoint) map[string]string {
+	headers := make(map[string]string)
+	if endpoint.Range != "" {
+		headers["Range"] = endpoint.Range
+	}
+	if endpoint.ApimSubscriptionKey != "" {
+		headers[apimSubscriptionKeyHeader] = endpoint.ApimSubscriptionKey
+	}
+	if len(headers) == 0 {
+		return nil
+	}
+	return headers
+}
+
+// expandHeaders expands placeholders in header values. An expanded
+// subscription key, e.g. from {{vars.key}}, is redacted like the key
+// itself.
+func expandHeaders(variables *vars.Store, headers map[string]string) (map[string]string, error) {
+	for name, value := range headers {
+		expanded, err := variables.Expand(value)
+		if err != nil {
+			return nil, fmt.Errorf("header %s: %w", name, err)
+		}
+		if name == apimSubscriptionKeyHeader {
+			redact.Register(expanded)
+		}
+		headers[name] = expanded
+	}
+	return headers, nil
+}
+
+// TransportOptions returns the connection settings for an endpoint's API
+// requests, or nil when the defaults apply
+func TransportOptions(endpoint *config.Endpoint) (*client.TransportOptions, error) {
+	opts := &client.TransportOptions{ProxyURL: endpoint.ProxyURL, Resolve: endpoint.Resolve, IPVersion: endpoint.IPVersion, HTTPVersion: endpoint.HTTPVersion}
+	if endpoint.TLS != nil {
+		version, err := endpoint.TLS.MinTLSVersion()
+		if err != nil {
+			return nil, err
+		}
+		opts.CAFile = endpoint.TLS.CAFile
+		opts.ClientCertFile = endpoint.TLS.ClientCertFile
+		opts.ClientKeyFile = endpoint.TLS.ClientKeyFile
+		opts.MinTLSVersion = version
+		opts.InsecureSkipVerify = endpoint.TLS.InsecureSkipVerify
+	}
+	if opts.IsZero() {
+		return nil, nil
+	}
+	return opts, nil
+}
+
+// send sends the endpoint's request and records it as an attempt
+func (r *Runner) send(ctx context.Context, endpoint *config.Endpoint, request *client.Request, label string, result *Result) (*client.Response, error) {
+	if err := r.pace(ctx, result); err != nil {
+		result.addAttempt(Attempt{Err: err, Label: label})
+		return nil, err
+	}
+	started := time.Now()
+	stopWatch := r.watchSlow(endpoint, started)
+	response, err := r.client.Send(ctx, request)
+	stopWatch()
+	elapsed := time.Since(started)
+	r.checkSlow(endpoint, elapsed, result)
+	r.record(endpoint, request, response, err, label, elapsed, result)
+
+	return response, err
+}
+
+// record adds the attempt of a sent request to result, and the first
+// response's body, security headers, redirects and truncation
+func (r *Runner) record(endpoint *config.Endpoint, request *client.Request, response *client.Response, err error, label string, duration time.Duration, result *Result) {
+	attempt := Attempt{
+		Err:      err,
+		Label:    label,
+		Duration: duration,
+	}
+	var addrErr *client.AddrError
+	if errors.As(err, &addrErr) {
+		attempt.Connection.RemoteAddr = addrErr.Addr
+	}
+	if response != nil {
+		attempt.Connection = response.Connection
+		r.debug(endpoint, "connection", "remote_addr", response.Connection.RemoteAddr, "reused", response.Connection.Reused,
+			"idle", response.Connection.IdleTime, "tls_resumed", response.Connection.TLSResumed, "tls_handshake", response.Connection.TLSHandshake)
+		attempt.Protocol = response.Proto
+		attempt.StatusCode = response.StatusCode
+		attempt.ThrottleRetries = response.ThrottleRetries
+		attempt.ThrottleWait = response.ThrottleWait
+		attempt.TraceID = response.TraceID
+		attempt.RequestIDs = response.RequestIDs()
+		if r.KeepBodies && result.Body == nil {
+			result.Body = response.Body
+		}
+		if r.SecurityScan && result.Security == nil {
+			result.Security = secscan.Scan(request.URL, response.Headers, request.AccessToken != "")
+		}
+		if len(response.Redirects) > 0 && result.Redirects == nil {
+			result.Redirects = response.Redirects
+		}
+		if response.Truncated && !result.BodyTruncated {
+			result.BodyTruncated = true
+			result.Warnings = append(result.Warnings, fmt.Sprintf("Response body truncated at %d bytes (maxBodyBytes)", request.MaxBodyBytes))
+		}
+	}
+	result.addAttempt(attempt)
+}
+
+// checkSignInPage fails the endpoint with an authentication failure when the
+// response is the Entra ID sign-in page: a 200 from interactive login is a
+// misconfigured gateway or API, not a pass
+func checkSignInPage(response *client.Response, result *Result) bool {
+	if err := response.CheckSignInPage(); err != nil {
+		result.StatusCode = response.StatusCode
+		result.fail(PhaseAuth, time.Now(), "Redirected to interactive sign-in", err)
+		return false
+	}
+	return true
+}
+
+// checkResponse saves the response if configured and validates it against
+// the endpoint's expectations, recording each assertion's outcome and that
+// of the post-response hook. It
+// returns a failure summary and cause, or "" when the response is
+// acceptable.
+func (r *Runner) checkResponse(ctx context.Context, endpoint *config.Endpoint, response *client.Response, result *Result) (string, error) {
+	if err := saveResponse(endpoint, response); err != nil {
+		return "Saving response failed", err
+	}
+
+	checksStatus, checksGraphQLErrors := false, false
+	for i := range endpoint.Assertions {
+		checksStatus = checksStatus || endpoint.Assertions[i].ChecksStatus()
+		checksGraphQLErrors = checksGraphQLErrors || endpoint.Assertions[i].ChecksGraphQLErrors()
+	}
+
+	if endpoint.Range != "" {
+		if err := response.ValidateRange(endpoint.Range); err != nil {
+			return "Range check failed", err
+		}
+	} else if !checksStatus && !response.IsSuccessStatusCode() {
+		return fmt.Sprintf("Unexpected status code: %d", response.StatusCode), nil
+	}
+	if endpoint.TraceEchoHeader != "" {
+		if err := response.CheckTraceEcho(endpoint.TraceEchoHeader); err != nil {
+			return "Trace not echoed", err
+		}
+	}
+	if response.Truncated {
+		if err := checkTruncated(endpoint, r.maxBodyBytes(endpoint)); err != nil {
+			return "Response body too large", err
+		}
+	}
+	if endpoint.GraphQL != nil && !checksGraphQLErrors {
+		if err := checkGraphQLErrors(response.Body); err != nil {
+			return "GraphQL errors", err
+		}
+	}
+	if err := verifyDownload(endpoint, response); err != nil {
+		return "Download verification failed", err
+	}
+
+	result.Assertions = make([]AssertionResult, len(endpoint.Assertions))
+	var failures []error
+	for i := range endpoint.Assertions {
+		err := assert.Check(&endpoint.Assertions[i], response)
+		result.Assertions[i] = AssertionResult{
+			Err:         err,
+			Description: assert.Describe(&endpoint.Assertions[i]),
+		}
+		if err != nil {
+			failures = append(failures, err)
+		}
+	}
+	if endpoint.Hooks != nil && len(endpoint.Hooks.PostResponse) > 0 {
+		err := r.runPostResponseHook(ctx, endpoint, response)
+		result.Assertions = append(result.Assertions, AssertionResult{Err: err, Description: postResponseHookDescription})
+		if err != nil {
+			failures = append(failures, err)
+		}
+	}
+	if len(failures) > 0 {
+		return "Assertion failed", &AssertionError{Failures: failures}
+	}
+
+	return "", nil
+}
+
+// capture stores the values selected by the endpoint's captures and
+// completes the response phase
+func (r *Runner) capture(endpoint *config.Endpoint, response *client.Response, phaseStart time.Time, result *Result) *Result {
+	for _, capture := range endpoint.Captures {
+		var err error
+		if capture.Header != "" {
+			err = r.variables.CaptureFromHeader(capture.Name, response.Headers, capture.Header)
+		} else {
+			err = r.variables.CaptureFromBody(capture.Name, response.Body, capture.JSONPath)
+		}
+		if err != nil {
+			return result.fail(PhaseResponse, phaseStart, "Capture failed", err)
+		}
+		value, _ := r.variables.Get(capture.Name)
+		r.debug(endpoint, "captured variable", "name", capture.Name, "value", value)
+	}
+
+	result.phase(PhaseResponse, phaseStart, true)
+	return result
+}
+
+// runLocaleMatrix repeats the endpoint's request once per Accept-Language
+// value, checks each response, and verifies the responses are localized
+func (r *Runner) runLocaleMatrix(ctx context.Context, endpoint *config.Endpoint, request *client.Request, result *Result) *Result {
+	phaseStart := time.Now()
+	responses := make([]*client.Response, 0, len(endpoint.AcceptLanguages))
+
+	for _, language := range endpoint.AcceptLanguages {
+		localized := *request
+		localized.Headers = make(map[string]string, len(request.Headers)+1)
+		for key, value := range request.Headers {
+			localized.Headers[key] = value
+		}
+		localized.Headers["Accept-Language"] = language
+
+		r.debug(endpoint, "sending localized request", "language", language)
+		response, err := r.send(ctx, endpoint, &localized, language, result)
+		if err != nil {
+			return result.fail(PhaseConnect, phaseStart, fmt.Sprintf("Request failed (%s)", language), err)
+		}
+		responses = append(responses, response)
+	}
+	result.phase(PhaseConnect, phaseStart, true)
+	result.StatusCode = responses[0].StatusCode
+	for _, response := range responses {
+		if !checkSignInPage(response, result) {
+			return result
+		}
+	}
+	result.Warnings = append(result.Warnings, responses[0].DeprecationWarnings()...)
+
+	phaseStart = time.Now()
+	bodies := make([][]byte, len(responses))
+	for i, response := range responses {
+		language := endpoint.AcceptLanguages[i]
+		if summary, err := r.checkResponse(ctx, endpoint, response, result); summary != "" {
+			result.StatusCode = response.StatusCode
+			return result.fail(PhaseResponse, phaseStart, fmt.Sprintf("%s (%s)", summary, language), err)
+		}
+		bodies[i] = response.Body
+	}
+
+	if err := assert.CheckLocalized(endpoint.AcceptLanguages, bodies, endpoint.ExpectLocalized); err != nil {
+		return result.fail(PhaseResponse, phaseStart, "Localization check failed", err)
+	}
+	r.debug(endpoint, "localized responses verified", "languages", len(endpoint.AcceptLanguages))
+
+	result = r.capture(endpoint, responses[0], phaseStart, result)
+	return r.finish(ctx, endpoint, request, responses[0], result)
+}
+
+// runRaceTest fires the endpoint's request concurrently, records each
+// request like a single one's, checks the outcome against the race test
+// expectation, and checks each successful response against the endpoint's
+// expectations
+func (r *Runner) runRaceTest(ctx context.Context, endpoint *config.Endpoint, request *client.Request, result *Result) *Result {
+	if err := r.pace(ctx, result); err != nil {
+		return result.fail(PhaseConnect, time.Now(), "Request failed", err)
+	}
+	phaseStart := time.Now()
+	r.debug(endpoint, "firing concurrent requests", "concurrency", endpoint.RaceTest.Concurrency)
+
+	stopWatch := r.watchSlow(endpoint, phaseStart)
+	race := r.client.SendConcurrently(ctx, request, endpoint.RaceTest.Concurrency)
+	stopWatch()
+	elapsed := time.Since(phaseStart)
+	r.checkSlow(endpoint, elapsed, result)
+	for i, response := range race.Responses {
+		r.record(endpoint, request, response, race.Errors[i], fmt.Sprintf("#%d", i+1), race.Durations[i], result)
+	}
+
+	// A request that failed to connect neither won nor lost the race
+	if failed := race.Failed(); failed > 0 {
+		return result.fail(PhaseConnect, phaseStart, fmt.Sprintf("Request failed (%d/%d concurrent requests)", failed, len(race.Responses)), race.FirstError())
+	}
+	winner := race.Winner()
+	result.phase(PhaseConnect, phaseStart, true)
+	result.StatusCode = winner.StatusCode
+	result.Warnings = append(result.Warnings, winner.DeprecationWarnings()...)
+	r.debug(endpoint, "concurrent requests completed", "succeeded", race.Succeeded(), "total", len(race.Responses))
+	if !checkSignInPage(winner, result) {
+		return result
+	}
+
+	phaseStart = time.Now()
+	if err := race.Check(endpoint.RaceTest.Expect); err != nil {
+		return result.fail(PhaseResponse, phaseStart, "Race test failed", err)
+	}
+	// The requests that had to succeed are checked like a single request's;
+	// those expected to lose the race are not
+	for i, response := range race.Responses {
+		if response == nil || !response.IsSuccessStatusCode() {
+			continue
+		}
+		if summary, err := r.checkResponse(ctx, endpoint, response, result); summary != "" {
+			result.StatusCode = response.StatusCode
+			return result.fail(PhaseResponse, phaseStart, fmt.Sprintf("%s (#%d)", summary, i+1), err)
+		}
+	}
+
+	result = r.capture(endpoint, winner, phaseStart, result)
+	return r.finish(ctx, endpoint, request, winner, result)
+}
+
+// debug logs progress for an endpoint
+func (r *Runner) debug(endpoint *config.Endpoint, msg string, args ...interface{}) {
+	r.Logger.Debug(msg, append([]interface{}{"endpoint", endpoint.Name}, args...)...)
+}
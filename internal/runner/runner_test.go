@@ -0,0 +1,641 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hutstep/entra-id-api-tester/internal/auth"
+	"github.com/hutstep/entra-id-api-tester/internal/client"
+	"github.com/hutstep/entra-id-api-tester/internal/config"
+	"github.com/hutstep/entra-id-api-tester/internal/vars"
+)
+
+// fakeTokenProvider returns a fixed token or error
+type fakeTokenProvider struct {
+	err   error
+	token string
+}
+
+func (f *fakeTokenProvider) GetAccessToken(ctx context.Context, clientID, clientSecret, tenantID, scope string) (string, error) {
+	return f.token, f.err
+}
+
+// newTestRunner returns a runner and an endpoint pointing at handler
+func newTestRunner(t *testing.T, handler http.HandlerFunc) (*Runner, *config.Endpoint) {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	runner := New(client.NewAPIClientWithTimeout(5*time.Second), vars.NewStore())
+	endpoint := &config.Endpoint{
+		Name:         "Test",
+		URL:          server.URL,
+		Method:       "GET",
+		ClientID:     "client-id",
+		ClientSecret: "secret",
+		TenantID:     "tenant",
+		Scope:        "scope",
+	}
+	return runner, endpoint
+}
+
+func respond(status int, body string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = io.WriteString(w, body)
+	}
+}
+
+func TestRun_Success(t *testing.T) {
+	runner, endpoint := newTestRunner(t, respond(http.StatusOK, `{"id":"42"}`))
+	endpoint.Captures = []config.Capture{{Name: "id", JSONPath: "$.id"}}
+	exists := true
+	endpoint.Assertions = []config.Assertion{{JSONPath: "$.id", Exists: &exists}}
+
+	result := runner.Run(context.Background(), endpoint, &fakeTokenProvider{token: "token"})
+
+	if !result.Success() {
+		t.Fatalf("Expected success, got %v", result.Err)
+	}
+	for _, phase := range []Phase{PhaseAuth, PhasePrepare, PhaseConnect, PhaseResponse} {
+		if !result.Passed(phase) {
+			t.Errorf("Expected phase %s to pass", phase)
+		}
+	}
+	if len(result.Attempts) != 1 || result.Attempts[0].StatusCode != http.StatusOK {
+		t.Errorf("Expected one 200 attempt, got %+v", result.Attempts)
+	}
+	if len(result.Assertions) != 1 || !result.Assertions[0].Passed() {
+		t.Errorf("Expected one passed assertion, got %+v", result.Assertions)
+	}
+	if value, _ := runner.variables.Get("id"); value != "42" {
+		t.Errorf("Expected captured id 42, got %q", value)
+	}
+	if result.Duration <= 0 {
+		t.Error("Expected a positive duration")
+	}
+}
+
+func TestRun_Failures(t *testing.T) {
+	tests := []struct {
+		name      string
+		handler   http.HandlerFunc
+		setup     func(endpoint *config.Endpoint)
+		tokenErr  error
+		expectErr error
+		phase     Phase
+	}{
+		{
+			name:      "authentication",
+			handler:   respond(http.StatusOK, `{}`),
+			tokenErr:  errors.New("invalid_client"),
+			expectErr: ErrAuth,
+			phase:     PhaseAuth,
+		},
+		{
+			name:      "variable substitution",
+			handler:   respond(http.StatusOK, `{}`),
+			setup:     func(endpoint *config.Endpoint) { endpoint.URL += "/{{vars.missing}}" },
+			expectErr: ErrPrepare,
+			phase:     PhasePrepare,
+		},
+		{
+			name:      "connection",
+			handler:   respond(http.StatusOK, `{}`),
+			setup:     func(endpoint *config.Endpoint) { endpoint.URL = "http://127.0.0.1:1" },
+			expectErr: ErrConnect,
+			phase:     PhaseConnect,
+		},
+		{
+			name:      "status code",
+			handler:   respond(http.StatusInternalServerError, `{}`),
+			expectErr: ErrResponse,
+			phase:     PhaseResponse,
+		},
+		{
+			name: "sign-in page",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/html")
+				_, _ = io.WriteString(w, "<html><head><title>Sign in to your account</title></head></html>")
+			},
+			expectErr: client.ErrSignInPage,
+			phase:     PhaseAuth,
+		},
+		{
+			name:      "capture",
+			handler:   respond(http.StatusOK, `{}`),
+			setup:     func(endpoint *config.Endpoint) { endpoint.Captures = []config.Capture{{Name: "id", JSONPath: "$.id"}} },
+			expectErr: ErrResponse,
+			phase:     PhaseResponse,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner, endpoint := newTestRunner(t, tt.handler)
+			if tt.setup != nil {
+				tt.setup(endpoint)
+			}
+
+			result := runner.Run(context.Background(), endpoint, &fakeTokenProvider{token: "token", err: tt.tokenErr})
+
+			if result.Success() {
+				t.Fatal("Expected failure, got success")
+			}
+			if !errors.Is(result.Err, tt.expectErr) {
+				t.Errorf("Expected error to match %v, got %v", tt.expectErr, result.Err)
+			}
+			if got := result.FailedPhase(); got != tt.phase {
+				t.Errorf("Expected failed phase %s, got %s", tt.phase, got)
+			}
+		})
+	}
+}
+
+func TestRun_AssertionFailure(t *testing.T) {
+	runner, endpoint := newTestRunner(t, respond(http.StatusNotFound, `{"error":"gone"}`))
+	endpoint.Assertions = []config.Assertion{
+		{Status: http.StatusNotFound},
+		{BodyContains: "present"},
+	}
+
+	result := runner.Run(context.Background(), endpoint, &fakeTokenProvider{token: "token"})
+
+	var assertionErr *AssertionError
+	if !errors.As(result.Err, &assertionErr) {
+		t.Fatalf("Expected an AssertionError, got %v", result.Err)
+	}
+	if len(assertionErr.Failures) != 1 {
+		t.Errorf("Expected 1 failure, got %d", len(assertionErr.Failures))
+	}
+	if len(result.Assertions) != 2 || !result.Assertions[0].Passed() || result.Assertions[1].Passed() {
+		t.Errorf("Expected first assertion to pass and second to fail, got %+v", result.Assertions)
+	}
+	if result.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", result.StatusCode)
+	}
+}
+
+func TestRun_LocaleMatrix(t *testing.T) {
+	runner, endpoint := newTestRunner(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "hello "+r.Header.Get("Accept-Language"))
+	})
+	endpoint.AcceptLanguages = []string{"en-US", "de-DE"}
+
+	result := runner.Run(context.Background(), endpoint, &fakeTokenProvider{token: "token"})
+
+	if !result.Success() {
+		t.Fatalf("Expected success, got %v", result.Err)
+	}
+	if len(result.Attempts) != 2 || result.Attempts[1].Label != "de-DE" {
+		t.Errorf("Expected one attempt per language, got %+v", result.Attempts)
+	}
+}
+
+func TestRun_ApimSubscriptionKey(t *testing.T) {
+	runner, endpoint := newTestRunner(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Ocp-Apim-Subscription-Key") != "subscription-key" || r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	})
+	endpoint.ApimSubscriptionKey = "subscription-key"
+
+	result := runner.Run(context.Background(), endpoint, &fakeTokenProvider{token: "token"})
+
+	if !result.Success() {
+		t.Fatalf("Expected the subscription key and token to be sent, got %v", result.Err)
+	}
+}
+
+func TestRun_TemplateFunctions(t *testing.T) {
+	t.Setenv("API_TESTER_APIM_KEY", "subscription-key")
+	runner, endpoint := newTestRunner(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Ocp-Apim-Subscription-Key") != "subscription-key" || len(r.URL.Path) != len("/items/")+36 {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	})
+	endpoint.URL += "/items/{{uuid}}"
+	endpoint.ApimSubscriptionKey = `{{env "API_TESTER_APIM_KEY"}}`
+
+	result := runner.Run(context.Background(), endpoint, &fakeTokenProvider{token: "token"})
+
+	if !result.Success() {
+		t.Fatalf("Expected the functions to be expanded, got %v", result.Err)
+	}
+}
+
+func TestRun_KeepBodies(t *testing.T) {
+	runner, endpoint := newTestRunner(t, respond(http.StatusOK, `{"id":"42"}`))
+
+	if result := runner.Run(context.Background(), endpoint, &fakeTokenProvider{token: "token"}); result.Body != nil {
+		t.Errorf("Expected no body by default, got %s", result.Body)
+	}
+	runner.KeepBodies = true
+	if result := runner.Run(context.Background(), endpoint, &fakeTokenProvider{token: "token"}); string(result.Body) != `{"id":"42"}` {
+		t.Errorf("Expected the response body to be kept, got %q", result.Body)
+	}
+}
+
+func TestRun_SecurityScan(t *testing.T) {
+	runner, endpoint := newTestRunner(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.Header().Set("Cache-Control", "no-store")
+		w.Header().Set("X-Powered-By", "Express")
+	})
+
+	if result := runner.Run(context.Background(), endpoint, &fakeTokenProvider{token: "token"}); result.Security != nil {
+		t.Errorf("Expected no security scan by default, got %+v", result.Security)
+	}
+	runner.SecurityScan = true
+	result := runner.Run(context.Background(), endpoint, &fakeTokenProvider{token: "token"})
+	if !result.Success() {
+		t.Fatalf("Expected a poor grade not to fail the endpoint, got %v", result.Err)
+	}
+	if result.Security == nil || result.Security.Grade != "C" || len(result.Security.Findings) != 2 {
+		t.Errorf("Expected grade C for plain HTTP and X-Powered-By, got %+v", result.Security)
+	}
+}
+
+func TestRun_StaticToken(t *testing.T) {
+	runner, endpoint := newTestRunner(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer static-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	})
+	endpoint.StaticToken = "static-token"
+
+	result := runner.Run(context.Background(), endpoint, &fakeTokenProvider{err: errors.New("acquisition must be skipped")})
+
+	if !result.Success() {
+		t.Fatalf("Expected the static token to be sent, got %v", result.Err)
+	}
+}
+
+func TestRun_ManagedIdentity(t *testing.T) {
+	runner, endpoint := newTestRunner(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer mi-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	})
+	runner.ManagedIdentity = &fakeTokenProvider{token: "mi-token"}
+	endpoint.ManagedIdentity = true
+	endpoint.ClientSecret = ""
+
+	result := runner.Run(context.Background(), endpoint, &fakeTokenProvider{err: errors.New("client credentials must not be used")})
+
+	if !result.Success() {
+		t.Fatalf("Expected the managed identity's token to be sent, got %v", result.Err)
+	}
+}
+
+func TestRun_ManagedIdentityTokenProxy(t *testing.T) {
+	// The proxy answers the App Service managed identity endpoint's requests
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.Host
+		fmt.Fprintf(w, `{"access_token":"mi-token","expires_on":"%d","resource":"api://orders","token_type":"Bearer"}`, time.Now().Add(time.Hour).Unix())
+	}))
+	defer proxy.Close()
+	t.Setenv("IDENTITY_ENDPOINT", "http://identity.example.com/msi/token")
+	t.Setenv("IDENTITY_HEADER", "header")
+
+	runner, endpoint := newTestRunner(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer mi-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	})
+	runner.ManagedIdentity = &fakeTokenProvider{err: errors.New("the token proxy must be used")}
+	endpoint.ManagedIdentity = true
+	endpoint.ClientSecret = ""
+	endpoint.TokenProxyURL = proxy.URL
+
+	result := runner.Run(context.Background(), endpoint, &fakeTokenProvider{err: errors.New("client credentials must not be used")})
+
+	if !result.Success() {
+		t.Fatalf("Expected the managed identity's token to be sent, got %v", result.Err)
+	}
+	if proxied != "identity.example.com" {
+		t.Errorf("Expected the token to be requested through the proxy, got a request for %q", proxied)
+	}
+}
+
+func TestRun_FederatedCredential(t *testing.T) {
+	runner, endpoint := newTestRunner(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer federated-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	})
+	runner.GitHubActions = &fakeTokenProvider{token: "federated-token"}
+	endpoint.FederatedCredential = config.FederatedCredentialGitHub
+	endpoint.ClientSecret = ""
+
+	result := runner.Run(context.Background(), endpoint, &fakeTokenProvider{err: errors.New("client credentials must not be used")})
+
+	if !result.Success() {
+		t.Fatalf("Expected the exchanged token to be sent, got %v", result.Err)
+	}
+}
+
+// regionTokenProvider returns a token naming the region it was asked for
+type regionTokenProvider struct{}
+
+func (regionTokenProvider) GetAccessToken(ctx context.Context, clientID, clientSecret, tenantID, scope string) (string, error) {
+	return "token-" + auth.Region(ctx), nil
+}
+
+func TestRun_AzureRegion(t *testing.T) {
+	runner, endpoint := newTestRunner(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token-westeurope" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	})
+	endpoint.AzureRegion = "westeurope"
+
+	result := runner.Run(context.Background(), endpoint, regionTokenProvider{})
+
+	if !result.Success() {
+		t.Fatalf("Expected the token to be acquired in the endpoint's region, got %v", result.Err)
+	}
+}
+
+// certificateTokenProvider returns a token naming the Key Vault certificate
+// it was asked to authenticate with
+type certificateTokenProvider struct{}
+
+func (certificateTokenProvider) GetAccessToken(ctx context.Context, clientID, clientSecret, tenantID, scope string) (string, error) {
+	certificate := auth.Certificate(ctx)
+	return "token-" + certificate.Vault + "-" + certificate.Name, nil
+}
+
+func TestRun_CertificateKeyVault(t *testing.T) {
+	runner, endpoint := newTestRunner(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token-signing-vault-signing" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	})
+	endpoint.ClientSecret = ""
+	endpoint.CertificateKeyVault = &config.CertificateKeyVault{Vault: "signing-vault", Name: "signing"}
+
+	result := runner.Run(context.Background(), endpoint, certificateTokenProvider{})
+
+	if !result.Success() {
+		t.Fatalf("Expected the token to be acquired with the endpoint's certificate, got %v", result.Err)
+	}
+}
+
+func TestRun_TokenLifetime(t *testing.T) {
+	now := time.Now()
+	token := unsignedToken(t, map[string]interface{}{"iat": now.Unix(), "exp": now.Add(20 * time.Minute).Unix()})
+
+	tests := []struct {
+		name        string
+		minLifetime time.Duration
+		warned      bool
+	}{
+		{name: "check disabled"},
+		{name: "long enough", minLifetime: 15 * time.Minute},
+		{name: "shortened", minLifetime: time.Hour, warned: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner, endpoint := newTestRunner(t, func(w http.ResponseWriter, r *http.Request) {})
+			runner.MinTokenLifetime = tt.minLifetime
+
+			result := runner.Run(context.Background(), endpoint, &fakeTokenProvider{token: token})
+
+			if !result.Success() {
+				t.Fatalf("Expected success, got %v", result.Err)
+			}
+			if !result.Token.ExpiresAt.Equal(time.Unix(now.Add(20*time.Minute).Unix(), 0)) {
+				t.Errorf("Unexpected expiry: %v", result.Token.ExpiresAt)
+			}
+			if result.Token.Lifetime != 20*time.Minute {
+				t.Errorf("Expected a lifetime of 20m, got %v", result.Token.Lifetime)
+			}
+			if result.Token.Remaining <= 0 || result.Token.Remaining > 20*time.Minute {
+				t.Errorf("Unexpected remaining lifetime: %v", result.Token.Remaining)
+			}
+			warned := strings.Contains(strings.Join(result.Warnings, "\n"), "Token lifetime of 20m0s is shorter than the expected 1h0m0s")
+			if warned != tt.warned {
+				t.Errorf("Expected warned=%v, got warnings %q", tt.warned, result.Warnings)
+			}
+		})
+	}
+}
+
+func TestRun_GroupsOverage(t *testing.T) {
+	token := unsignedToken(t, map[string]interface{}{"_claim_names": map[string]string{"groups": "src1"}})
+	runner, endpoint := newTestRunner(t, func(w http.ResponseWriter, r *http.Request) {})
+
+	result := runner.Run(context.Background(), endpoint, &fakeTokenProvider{token: token})
+
+	if !result.Success() {
+		t.Fatalf("Expected success, got %v", result.Err)
+	}
+	if !result.GroupsOverage {
+		t.Error("Expected the groups overage to be recorded")
+	}
+	if !strings.Contains(strings.Join(result.Warnings, "\n"), "(groups overage)") {
+		t.Errorf("Expected a groups overage warning, got %q", result.Warnings)
+	}
+}
+
+func TestRun_VerifySecondarySecrets(t *testing.T) {
+	tests := []struct {
+		name         string
+		verify       bool
+		secondary    string
+		acquisitions int
+		expectErr    bool
+	}{
+		{name: "not verified", secondary: "expired", acquisitions: 1},
+		{name: "both working", verify: true, secondary: "new-secret", acquisitions: 2},
+		{name: "secondary expired", verify: true, secondary: "expired", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner, endpoint := newTestRunner(t, respond(http.StatusOK, `{}`))
+			runner.VerifySecondarySecrets = tt.verify
+			endpoint.ClientSecretSecondary = tt.secondary
+			provider := &countingTokenProvider{}
+
+			result := runner.Run(context.Background(), endpoint, secretTokenProvider{provider})
+
+			if tt.expectErr {
+				if !errors.Is(result.Err, ErrAuth) || result.ErrorCode() != CodeAuthInvalidSecret {
+					t.Errorf("Expected the secondary secret to fail authentication, got %v (%s)", result.Err, result.ErrorCode())
+				}
+				return
+			}
+			if !result.Success() || len(provider.scopes) != tt.acquisitions {
+				t.Errorf("Expected success after %d acquisition(s), got %v after %d", tt.acquisitions, result.Err, len(provider.scopes))
+			}
+		})
+	}
+}
+
+// secretTokenProvider rejects the client secret "expired" like Entra ID
+// rejects an expired secret
+type secretTokenProvider struct {
+	*countingTokenProvider
+}
+
+func (p secretTokenProvider) GetAccessToken(ctx context.Context, clientID, clientSecret, tenantID, scope string) (string, error) {
+	if clientSecret == "expired" {
+		return "", errors.New("AADSTS7000222: The provided client secret keys for app are expired")
+	}
+	return p.countingTokenProvider.GetAccessToken(ctx, clientID, clientSecret, tenantID, scope)
+}
+
+func TestRun_RaceTest(t *testing.T) {
+	runner, endpoint := newTestRunner(t, respond(http.StatusCreated, `{}`))
+	endpoint.RaceTest = &config.RaceTest{Concurrency: 3, Expect: client.RaceExpectOne}
+
+	result := runner.Run(context.Background(), endpoint, &fakeTokenProvider{token: "token"})
+
+	if !errors.Is(result.Err, ErrResponse) {
+		t.Fatalf("Expected race test to fail the response phase, got %v", result.Err)
+	}
+	if len(result.Attempts) != 3 {
+		t.Errorf("Expected 3 attempts, got %d", len(result.Attempts))
+	}
+}
+
+func TestRun_RaceTestRecordsResponses(t *testing.T) {
+	runner, endpoint := newTestRunner(t, respond(http.StatusOK, `{"status":"done"}`))
+	runner.KeepBodies = true
+	runner.SecurityScan = true
+	endpoint.RaceTest = &config.RaceTest{Concurrency: 2}
+
+	result := runner.Run(context.Background(), endpoint, &fakeTokenProvider{token: "token"})
+
+	if !result.Success() {
+		t.Fatalf("Expected success, got %v", result.Err)
+	}
+	if string(result.Body) != `{"status":"done"}` {
+		t.Errorf("Expected the response body to be kept, got %q", result.Body)
+	}
+	if result.Security == nil {
+		t.Error("Expected the response headers to be scanned")
+	}
+	for _, attempt := range result.Attempts {
+		if attempt.Protocol == "" {
+			t.Errorf("Expected attempt %s to record its protocol", attempt.Label)
+		}
+	}
+}
+
+func TestRun_RaceTestConnectionFailure(t *testing.T) {
+	runner, endpoint := newTestRunner(t, respond(http.StatusCreated, `{}`))
+	endpoint.URL = "http://127.0.0.1:1/unreachable"
+	endpoint.RaceTest = &config.RaceTest{Concurrency: 2, Expect: client.RaceExpectOne}
+
+	result := runner.Run(context.Background(), endpoint, &fakeTokenProvider{token: "token"})
+
+	if result.FailedPhase() != PhaseConnect {
+		t.Fatalf("Expected the connection failure to fail the connect phase, got %v", result.Err)
+	}
+}
+
+func TestRun_RaceTestAssertions(t *testing.T) {
+	tests := []struct {
+		name   string
+		expect string
+		body   string
+		failed bool
+	}{
+		{name: "all pass", expect: client.RaceExpectAll, body: `{"status":"done"}`},
+		{name: "all violate assertions", expect: client.RaceExpectAll, body: `{"status":"pending"}`, failed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner, endpoint := newTestRunner(t, respond(http.StatusCreated, tt.body))
+			endpoint.RaceTest = &config.RaceTest{Concurrency: 3, Expect: tt.expect}
+			endpoint.Assertions = []config.Assertion{{BodyContains: `"done"`}}
+
+			result := runner.Run(context.Background(), endpoint, &fakeTokenProvider{token: "token"})
+
+			var assertionErr *AssertionError
+			if tt.failed != errors.As(result.Err, &assertionErr) {
+				t.Fatalf("Expected assertion failure %v, got %v", tt.failed, result.Err)
+			}
+			var phaseErr *PhaseError
+			if tt.failed && (!errors.As(result.Err, &phaseErr) || !strings.HasPrefix(phaseErr.Summary, "Assertion failed (#")) {
+				t.Errorf("Expected the failing request to be named, got %v", result.Err)
+			}
+		})
+	}
+
+	t.Run("losers are not checked", func(t *testing.T) {
+		var mu sync.Mutex
+		created := false
+		runner, endpoint := newTestRunner(t, func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+			if created {
+				w.WriteHeader(http.StatusConflict)
+				_, _ = w.Write([]byte(`{"error":"exists"}`))
+				return
+			}
+			created = true
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"status":"done"}`))
+		})
+		endpoint.RaceTest = &config.RaceTest{Concurrency: 3, Expect: client.RaceExpectOne}
+		endpoint.Assertions = []config.Assertion{{BodyContains: `"done"`}}
+
+		result := runner.Run(context.Background(), endpoint, &fakeTokenProvider{token: "token"})
+
+		if !result.Success() {
+			t.Fatalf("Expected only the winning response to be checked, got %v", result.Err)
+		}
+	})
+}
+
+func TestSkip(t *testing.T) {
+	result := Skip(&config.Endpoint{Name: "Get Order"}, "Create Order")
+
+	if !result.Skipped || result.Success() {
+		t.Error("Expected a skipped, unsuccessful result")
+	}
+	if !errors.Is(result.Err, ErrSkipped) {
+		t.Errorf("Expected error to match ErrSkipped, got %v", result.Err)
+	}
+	if result.FailedPhase() != "" {
+		t.Errorf("Expected no failed phase, got %s", result.FailedPhase())
+	}
+}
+
+func TestTransportOptions(t *testing.T) {
+	endpoint := &config.Endpoint{}
+	if opts, err := TransportOptions(endpoint); err != nil || opts != nil {
+		t.Errorf("Expected nil options for defaults, got %+v, %v", opts, err)
+	}
+
+	endpoint.ProxyURL = "http://proxy.example:8080"
+	endpoint.TLS = &config.TLSConfig{MinVersion: "1.3", InsecureSkipVerify: true}
+	opts, err := TransportOptions(endpoint)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if opts.ProxyURL != endpoint.ProxyURL || opts.MinTLSVersion == 0 || !opts.InsecureSkipVerify {
+		t.Errorf("Unexpected options: %+v", opts)
+	}
+
+	endpoint.TLS.MinVersion = "2.0"
+	if _, err := TransportOptions(endpoint); err == nil {
+		t.Error("Expected error for invalid minVersion, got nil")
+	}
+}
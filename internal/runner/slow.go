@@ -0,0 +1,58 @@
+package runner
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hutstep/entra-id-api-tester/internal/config"
+)
+
+// DefaultSlowThreshold is how long a request may be in flight before it is
+// reported as slow by default
+const DefaultSlowThreshold = 10 * time.Second
+
+// slowThreshold returns the endpoint's slow threshold, falling back to the
+// runner default
+func (r *Runner) slowThreshold(endpoint *config.Endpoint) time.Duration {
+	if endpoint.SlowThresholdMs > 0 {
+		return time.Duration(endpoint.SlowThresholdMs) * time.Millisecond
+	}
+	return r.SlowThreshold
+}
+
+// watchSlow reports, once per threshold interval, that a request started at
+// started is still in flight, so operators can see which endpoint is hanging
+// long before its timeout. The returned function stops the watch.
+func (r *Runner) watchSlow(endpoint *config.Endpoint, started time.Time) func() {
+	threshold := r.slowThreshold(endpoint)
+	if threshold <= 0 {
+		return func() {}
+	}
+
+	ticker := time.NewTicker(threshold)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case now := <-ticker.C:
+				r.Logger.Warn("slow request still in flight", "endpoint", endpoint.Name, "elapsed", now.Sub(started).Truncate(time.Second), "threshold", threshold)
+			}
+		}
+	}()
+
+	return func() {
+		ticker.Stop()
+		close(done)
+	}
+}
+
+// checkSlow adds a warning to the result when a request took longer than
+// the endpoint's slow threshold
+func (r *Runner) checkSlow(endpoint *config.Endpoint, elapsed time.Duration, result *Result) {
+	threshold := r.slowThreshold(endpoint)
+	if threshold > 0 && elapsed > threshold {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("Slow request: took %v (threshold %v)", elapsed.Truncate(time.Millisecond), threshold))
+	}
+}
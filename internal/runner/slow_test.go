@@ -0,0 +1,80 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hutstep/entra-id-api-tester/internal/config"
+)
+
+func TestSlowThreshold(t *testing.T) {
+	runner := &Runner{SlowThreshold: 10 * time.Second}
+
+	if got := runner.slowThreshold(&config.Endpoint{}); got != 10*time.Second {
+		t.Errorf("Expected runner default 10s, got %v", got)
+	}
+	if got := runner.slowThreshold(&config.Endpoint{SlowThresholdMs: 250}); got != 250*time.Millisecond {
+		t.Errorf("Expected endpoint override 250ms, got %v", got)
+	}
+}
+
+func TestRun_SlowRequestWarnings(t *testing.T) {
+	runner, endpoint := newTestRunner(t, func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(150 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	})
+	endpoint.SlowThresholdMs = 40
+
+	logs := &syncBuffer{}
+	runner.Logger = slog.New(slog.NewTextHandler(logs, &slog.HandlerOptions{Level: slog.LevelWarn}))
+
+	result := runner.Run(context.Background(), endpoint, &fakeTokenProvider{token: "token"})
+	if !result.Success() {
+		t.Fatalf("Expected success, got %v", result.Err)
+	}
+
+	if !strings.Contains(logs.String(), `msg="slow request still in flight" endpoint=Test`) {
+		t.Errorf("Expected live slow-request warnings while the request was in flight, got %q", logs.String())
+	}
+	if len(result.Warnings) != 1 || !strings.HasPrefix(result.Warnings[0], "Slow request") {
+		t.Errorf("Expected a slow request warning on the result, got %v", result.Warnings)
+	}
+}
+
+func TestRun_FastRequestNoWarning(t *testing.T) {
+	runner, endpoint := newTestRunner(t, respond(http.StatusOK, `{}`))
+	logs := &syncBuffer{}
+	runner.Logger = slog.New(slog.NewTextHandler(logs, &slog.HandlerOptions{Level: slog.LevelWarn}))
+
+	result := runner.Run(context.Background(), endpoint, &fakeTokenProvider{token: "token"})
+	if len(result.Warnings) != 0 {
+		t.Errorf("Expected no warnings, got %v", result.Warnings)
+	}
+	if logs.String() != "" {
+		t.Errorf("Unexpected live warning: %q", logs.String())
+	}
+}
+
+// syncBuffer is a bytes.Buffer safe for concurrent log writes
+type syncBuffer struct {
+	buf bytes.Buffer
+	mu  sync.Mutex
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
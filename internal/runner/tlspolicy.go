@@ -0,0 +1,107 @@
+package runner
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/hutstep/entra-id-api-tester/internal/client"
+	"github.com/hutstep/entra-id-api-tester/internal/config"
+)
+
+// checkTLSPolicy enforces the endpoint's TLS version and cipher policy
+// before the request is sent. It checks what a normal handshake negotiates
+// and then probes whether the server also accepts anything weaker.
+func (r *Runner) checkTLSPolicy(ctx context.Context, endpoint *config.Endpoint, request *client.Request, result *Result) bool {
+	if !endpoint.TLS.HasPolicy() {
+		return true
+	}
+	phaseStart := time.Now()
+
+	required, err := endpoint.TLS.RequiredTLSVersion()
+	if err != nil {
+		result.fail(PhasePrepare, phaseStart, "Invalid TLS settings", err)
+		return false
+	}
+	allowed, err := endpoint.TLS.AllowedCipherSuites()
+	if err != nil {
+		result.fail(PhasePrepare, phaseStart, "Invalid TLS settings", err)
+		return false
+	}
+
+	state, err := r.client.ProbeTLS(ctx, request.URL, request.Transport, client.TLSProbe{})
+	if err != nil {
+		result.fail(PhaseConnect, phaseStart, "TLS handshake failed", err)
+		return false
+	}
+	r.debug(endpoint, "negotiated TLS", "version", tls.VersionName(state.Version), "cipher", tls.CipherSuiteName(state.CipherSuite))
+
+	var failures []error
+	if state.Version < required {
+		failures = append(failures, fmt.Errorf("negotiated %s, policy requires %s or later", tls.VersionName(state.Version), tls.VersionName(required)))
+	}
+	if len(allowed) > 0 && !slices.Contains(allowed, state.CipherSuite) {
+		failures = append(failures, fmt.Errorf("negotiated cipher %s is not allowed", tls.CipherSuiteName(state.CipherSuite)))
+	}
+
+	if required > tls.VersionTLS10 {
+		probe := client.TLSProbe{MinVersion: tls.VersionTLS10, MaxVersion: required - 1}
+		accepted, err := r.probeWeaker(ctx, request, probe)
+		if err != nil {
+			result.fail(PhaseConnect, phaseStart, "TLS version probe failed", err)
+			return false
+		}
+		if accepted != nil {
+			failures = append(failures, fmt.Errorf("server accepts %s, policy requires %s or later", tls.VersionName(accepted.Version), tls.VersionName(required)))
+		}
+	}
+
+	if disallowed := disallowedCipherSuites(allowed); len(disallowed) > 0 {
+		probe := client.TLSProbe{MinVersion: tls.VersionTLS10, MaxVersion: tls.VersionTLS12, CipherSuites: disallowed}
+		accepted, err := r.probeWeaker(ctx, request, probe)
+		if err != nil {
+			result.fail(PhaseConnect, phaseStart, "TLS cipher probe failed", err)
+			return false
+		}
+		if accepted != nil {
+			failures = append(failures, fmt.Errorf("server accepts disallowed cipher %s", tls.CipherSuiteName(accepted.CipherSuite)))
+		}
+	}
+
+	if len(failures) > 0 {
+		result.fail(PhaseConnect, phaseStart, "TLS policy violated", &AssertionError{Failures: failures})
+		return false
+	}
+	return true
+}
+
+// probeWeaker offers the server a weaker handshake and returns the
+// negotiated state if it was accepted, or nil if it was rejected
+func (r *Runner) probeWeaker(ctx context.Context, request *client.Request, probe client.TLSProbe) (*tls.ConnectionState, error) {
+	state, err := r.client.ProbeTLS(ctx, request.URL, request.Transport, probe)
+	if errors.Is(err, client.ErrHandshakeRejected) {
+		return nil, nil
+	}
+	return state, err
+}
+
+// disallowedCipherSuites returns the TLS 1.0-1.2 cipher suites Go can offer
+// that are not in allowed. TLS 1.3 suites are not configurable and are
+// therefore only checked when negotiated.
+func disallowedCipherSuites(allowed []uint16) []uint16 {
+	if len(allowed) == 0 {
+		return nil
+	}
+	var disallowed []uint16
+	for _, suites := range [][]*tls.CipherSuite{tls.CipherSuites(), tls.InsecureCipherSuites()} {
+		for _, suite := range suites {
+			if !slices.Contains(suite.SupportedVersions, tls.VersionTLS13) && !slices.Contains(allowed, suite.ID) {
+				disallowed = append(disallowed, suite.ID)
+			}
+		}
+	}
+	return disallowed
+}
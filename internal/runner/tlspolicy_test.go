@@ -0,0 +1,123 @@
+package runner
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/hutstep/entra-id-api-tester/internal/config"
+)
+
+// newTLSTestRunner returns a runner and an endpoint pointing at a TLS server
+// with the given configuration, trusting its certificate
+func newTLSTestRunner(t *testing.T, serverTLS *tls.Config) (*Runner, *config.Endpoint) {
+	t.Helper()
+	runner, endpoint := newTestRunner(t, respond(http.StatusOK, `{}`))
+
+	server := httptest.NewUnstartedServer(respond(http.StatusOK, `{}`))
+	server.TLS = serverTLS
+	server.StartTLS()
+	t.Cleanup(server.Close)
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	block := &pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}
+	if err := os.WriteFile(caFile, pem.EncodeToMemory(block), 0600); err != nil {
+		t.Fatalf("Failed to write CA file: %v", err)
+	}
+
+	endpoint.URL = server.URL
+	endpoint.TLS = &config.TLSConfig{CAFile: caFile}
+	return runner, endpoint
+}
+
+func TestRun_TLSPolicy(t *testing.T) {
+	gcmSuites := []uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256, tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384}
+
+	tests := []struct {
+		server        *tls.Config
+		name          string
+		expectFailure string
+		policy        config.TLSConfig
+	}{
+		{
+			name:   "version policy met",
+			server: &tls.Config{MinVersion: tls.VersionTLS12},
+			policy: config.TLSConfig{RequireVersion: "1.2"},
+		},
+		{
+			name:          "negotiated version too old",
+			server:        &tls.Config{MaxVersion: tls.VersionTLS12},
+			policy:        config.TLSConfig{RequireVersion: "1.3"},
+			expectFailure: "negotiated TLS 1.2, policy requires TLS 1.3 or later",
+		},
+		{
+			name:          "server accepts older version",
+			server:        &tls.Config{MinVersion: tls.VersionTLS10},
+			policy:        config.TLSConfig{RequireVersion: "1.2"},
+			expectFailure: "server accepts TLS 1.1",
+		},
+		{
+			name:   "cipher policy met",
+			server: &tls.Config{MaxVersion: tls.VersionTLS12, CipherSuites: gcmSuites},
+			policy: config.TLSConfig{AllowedCiphers: []string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256", "TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384"}},
+		},
+		{
+			name:          "server accepts disallowed cipher",
+			server:        &tls.Config{MaxVersion: tls.VersionTLS12, CipherSuites: gcmSuites},
+			policy:        config.TLSConfig{AllowedCiphers: []string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"}},
+			expectFailure: "server accepts disallowed cipher TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner, endpoint := newTLSTestRunner(t, tt.server)
+			endpoint.TLS.RequireVersion = tt.policy.RequireVersion
+			endpoint.TLS.AllowedCiphers = tt.policy.AllowedCiphers
+
+			result := runner.Run(context.Background(), endpoint, &fakeTokenProvider{token: "token"})
+
+			if tt.expectFailure == "" {
+				if !result.Success() {
+					t.Fatalf("Expected success, got %v", result.Err)
+				}
+				return
+			}
+			if !errors.Is(result.Err, ErrConnect) {
+				t.Fatalf("Expected ErrConnect, got %v", result.Err)
+			}
+			if !strings.Contains(result.Err.Error(), tt.expectFailure) {
+				t.Errorf("Expected %q in %q", tt.expectFailure, result.Err)
+			}
+			if len(result.Attempts) != 0 {
+				t.Errorf("Expected no request to be sent, got %d attempts", len(result.Attempts))
+			}
+		})
+	}
+}
+
+func TestDisallowedCipherSuites(t *testing.T) {
+	if got := disallowedCipherSuites(nil); got != nil {
+		t.Errorf("Expected no probe without a cipher policy, got %#x", got)
+	}
+
+	allowed := []uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256}
+	disallowed := disallowedCipherSuites(allowed)
+	if slices.Contains(disallowed, tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256) {
+		t.Error("Expected allowed suite not to be probed")
+	}
+	if !slices.Contains(disallowed, tls.TLS_RSA_WITH_RC4_128_SHA) {
+		t.Error("Expected insecure suites to be probed")
+	}
+	if slices.Contains(disallowed, tls.TLS_AES_128_GCM_SHA256) {
+		t.Error("Expected TLS 1.3 suites not to be probed")
+	}
+}
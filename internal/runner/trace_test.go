@@ -0,0 +1,60 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hutstep/entra-id-api-tester/internal/client"
+)
+
+func TestRun_Trace(t *testing.T) {
+	tests := []struct {
+		name       string
+		trace      bool
+		echoHeader string
+		echo       bool
+		expectErr  string
+	}{
+		{name: "off"},
+		{name: "traced", trace: true},
+		{name: "echoed", echoHeader: "traceresponse", echo: true},
+		{name: "not echoed", echoHeader: "traceresponse", expectErr: "Trace not echoed: response has no traceresponse header"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var traceParent string
+			runner, endpoint := newTestRunner(t, func(w http.ResponseWriter, r *http.Request) {
+				traceParent = r.Header.Get(client.TraceParentHeader)
+				if tt.echo {
+					w.Header().Set("traceresponse", traceParent)
+				}
+			})
+			runner.Trace = tt.trace
+			endpoint.TraceEchoHeader = tt.echoHeader
+
+			result := runner.Run(context.Background(), endpoint, &fakeTokenProvider{token: "token"})
+
+			traceID := result.TraceID()
+			if tt.trace || tt.echoHeader != "" {
+				if traceID == "" || !strings.Contains(traceParent, traceID) {
+					t.Errorf("Expected the result to record the trace ID of %q, got %q", traceParent, traceID)
+				}
+			} else if traceParent != "" || traceID != "" {
+				t.Errorf("Expected no traceparent, got %q", traceParent)
+			}
+			if tt.expectErr != "" {
+				if !errors.Is(result.Err, ErrResponse) || !strings.HasPrefix(result.Err.Error(), tt.expectErr) {
+					t.Errorf("Expected response failure %q, got %v", tt.expectErr, result.Err)
+				}
+				return
+			}
+			if !result.Success() {
+				t.Errorf("Expected success, got %v", result.Err)
+			}
+		})
+	}
+}
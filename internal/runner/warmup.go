@@ -0,0 +1,42 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hutstep/entra-id-api-tester/internal/client"
+	"github.com/hutstep/entra-id-api-tester/internal/config"
+)
+
+// warmUp sends the endpoint's warmup requests so that cold starts, e.g. of
+// serverless APIs, are not measured. Their outcome never fails the endpoint;
+// they are recorded in Warmups and their time is left out of the result's
+// duration.
+func (r *Runner) warmUp(ctx context.Context, endpoint *config.Endpoint, request *client.Request, result *Result) {
+	for i := 1; i <= endpoint.WarmupRequests && ctx.Err() == nil; i++ {
+		if r.pace(ctx, result) != nil {
+			return
+		}
+		started := time.Now()
+		response, err := r.client.Send(ctx, request)
+		warmup := Attempt{
+			Err:      err,
+			Label:    fmt.Sprintf("warmup %d", i),
+			Duration: time.Since(started),
+		}
+		if response != nil {
+			warmup.StatusCode = response.StatusCode
+			warmup.ThrottleRetries = response.ThrottleRetries
+			warmup.ThrottleWait = response.ThrottleWait
+		}
+		result.Warmups = append(result.Warmups, warmup)
+		result.WarmupDuration += warmup.Duration
+
+		if err != nil {
+			r.debug(endpoint, "warmup request failed", "warmup", i, "duration", warmup.Duration.Truncate(time.Millisecond), "error", err)
+			continue
+		}
+		r.debug(endpoint, "warmup request completed", "warmup", i, "status", warmup.StatusCode, "duration", warmup.Duration.Truncate(time.Millisecond))
+	}
+}
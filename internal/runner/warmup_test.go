@@ -0,0 +1,40 @@
+package runner
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRun_Warmup(t *testing.T) {
+	const coldStart = 100 * time.Millisecond
+	var calls atomic.Int32
+	runner, endpoint := newTestRunner(t, func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			// Cold start: slow and failing
+			time.Sleep(coldStart)
+			respond(http.StatusServiceUnavailable, `{}`)(w, r)
+			return
+		}
+		respond(http.StatusOK, `{}`)(w, r)
+	})
+	endpoint.WarmupRequests = 2
+	endpoint.MaxDurationMs = int(coldStart.Milliseconds()) / 2
+
+	result := runner.Run(context.Background(), endpoint, &fakeTokenProvider{token: "token"})
+
+	if result.Err != nil {
+		t.Fatalf("Expected the endpoint to pass despite the failed warmup, got %v", result.Err)
+	}
+	if calls.Load() != 3 || len(result.Attempts) != 1 {
+		t.Errorf("Expected 2 warmups and 1 measured request, got %d request(s) and %d attempt(s)", calls.Load(), len(result.Attempts))
+	}
+	if len(result.Warmups) != 2 || result.Warmups[0].StatusCode != http.StatusServiceUnavailable || result.Warmups[1].Label != "warmup 2" {
+		t.Errorf("Unexpected warmups: %+v", result.Warmups)
+	}
+	if result.WarmupDuration < coldStart || result.Duration >= coldStart {
+		t.Errorf("Expected the cold start to be excluded from the duration, got %v (warmups %v)", result.Duration, result.WarmupDuration)
+	}
+}
@@ -0,0 +1,171 @@
+// Package scaffold renders starter configurations, with a placeholder in
+// place of the client secret: the interactive "api-tester init" subcommand
+// asks for the details of a first endpoint, and "api-tester import" derives
+// endpoints from an API description.
+package scaffold
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// SecretPlaceholder stands in for the client secret, which is never asked for
+// so that it does not end up in the terminal history or scrollback
+const SecretPlaceholder = "your-client-secret-here"
+
+// guidPattern matches an Entra ID application or tenant ID
+var guidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// Answers are the details of the first endpoint
+type Answers struct {
+	Name     string
+	URL      string
+	Method   string
+	TenantID string
+	ClientID string
+	Scope    string
+}
+
+// question is a single prompt. validate returns why an answer is rejected.
+type question struct {
+	validate func(answer string) error
+	answer   *string
+	prompt   string
+	fallback string
+}
+
+// Prompt asks for each answer on out, reading lines from in. An empty line
+// accepts the default shown in brackets, and an invalid answer is asked
+// again.
+func Prompt(in io.Reader, out io.Writer) (Answers, error) {
+	answers := Answers{Method: "GET"}
+	questions := []question{
+		{prompt: "Endpoint name", fallback: "My API", answer: &answers.Name, validate: required},
+		{prompt: "URL", answer: &answers.URL, validate: validateURL},
+		{prompt: "HTTP method", fallback: "GET", answer: &answers.Method, validate: validateMethod},
+		{prompt: "Tenant ID (GUID or domain, e.g. contoso.onmicrosoft.com)", answer: &answers.TenantID, validate: required},
+		{prompt: "Client ID of the calling app registration", answer: &answers.ClientID, validate: validateGUID},
+		{prompt: "Scope", answer: &answers.Scope, validate: validateScope},
+	}
+
+	scanner := bufio.NewScanner(in)
+	for _, q := range questions {
+		for {
+			if q.fallback != "" {
+				fmt.Fprintf(out, "%s [%s]: ", q.prompt, q.fallback)
+			} else {
+				fmt.Fprintf(out, "%s: ", q.prompt)
+			}
+			if !scanner.Scan() {
+				if err := scanner.Err(); err != nil {
+					return Answers{}, fmt.Errorf("failed to read answer: %w", err)
+				}
+				return Answers{}, fmt.Errorf("input ended before %q was answered", q.prompt)
+			}
+
+			answer := strings.TrimSpace(scanner.Text())
+			if answer == "" {
+				answer = q.fallback
+			}
+			if err := q.validate(answer); err != nil {
+				fmt.Fprintf(out, "  %v\n", err)
+				continue
+			}
+			*q.answer = answer
+			break
+		}
+	}
+	answers.Method = strings.ToUpper(answers.Method)
+	return answers, nil
+}
+
+// Endpoint is an endpoint as written to a starter configuration, in the
+// field order of config.example.json
+type Endpoint struct {
+	RequestBody  map[string]interface{} `json:"requestBody,omitempty"`
+	Name         string                 `json:"name"`
+	URL          string                 `json:"url"`
+	Method       string                 `json:"method"`
+	ClientID     string                 `json:"clientId"`
+	ClientSecret string                 `json:"clientSecret"`
+	TenantID     string                 `json:"tenantId"`
+	Scope        string                 `json:"scope"`
+}
+
+// Render returns the starter configuration for the answers
+func Render(answers Answers) ([]byte, error) {
+	return RenderEndpoints([]Endpoint{{
+		Name:         answers.Name,
+		URL:          answers.URL,
+		Method:       answers.Method,
+		ClientID:     answers.ClientID,
+		ClientSecret: SecretPlaceholder,
+		TenantID:     answers.TenantID,
+		Scope:        answers.Scope,
+	}})
+}
+
+// RenderEndpoints returns a configuration holding the endpoints
+func RenderEndpoints(endpoints []Endpoint) ([]byte, error) {
+	starter := struct {
+		Endpoints []Endpoint `json:"endpoints"`
+	}{Endpoints: endpoints}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(starter); err != nil {
+		return nil, fmt.Errorf("failed to encode config: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// required rejects an empty answer
+func required(answer string) error {
+	if answer == "" {
+		return fmt.Errorf("a value is required")
+	}
+	return nil
+}
+
+// validateURL requires an absolute http or https URL
+func validateURL(answer string) error {
+	parsed, err := url.Parse(answer)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("enter an absolute URL, e.g. https://api.example.com/v1/resource")
+	}
+	return nil
+}
+
+// validateMethod accepts the methods the configuration supports
+func validateMethod(answer string) error {
+	switch strings.ToUpper(answer) {
+	case "GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS":
+		return nil
+	}
+	return fmt.Errorf("enter GET, POST, PUT, PATCH, DELETE, HEAD or OPTIONS")
+}
+
+// validateGUID requires an application (client) ID
+func validateGUID(answer string) error {
+	if !guidPattern.MatchString(answer) {
+		return fmt.Errorf("enter the application (client) ID, e.g. 00000000-0000-0000-0000-000000000000")
+	}
+	return nil
+}
+
+// validateScope requires a /.default scope, the only kind the client
+// credentials flow accepts
+func validateScope(answer string) error {
+	if !strings.HasSuffix(answer, "/.default") {
+		return fmt.Errorf("enter the API's /.default scope, e.g. api://00000000-0000-0000-0000-000000000000/.default")
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package scaffold
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestPrompt(t *testing.T) {
+	input := strings.Join([]string{
+		"",                      // default name
+		"api.example.com/users", // rejected: not absolute
+		"https://api.example.com/users",
+		"post",
+		"contoso.onmicrosoft.com",
+		"not-a-guid", // rejected
+		"11111111-1111-1111-1111-111111111111",
+		"api://22222222-2222-2222-2222-222222222222", // rejected: not /.default
+		"api://22222222-2222-2222-2222-222222222222/.default",
+	}, "\n")
+	var out bytes.Buffer
+
+	answers, err := Prompt(strings.NewReader(input), &out)
+	if err != nil {
+		t.Fatalf("Prompt() error = %v", err)
+	}
+
+	expected := Answers{
+		Name:     "My API",
+		URL:      "https://api.example.com/users",
+		Method:   "POST",
+		TenantID: "contoso.onmicrosoft.com",
+		ClientID: "11111111-1111-1111-1111-111111111111",
+		Scope:    "api://22222222-2222-2222-2222-222222222222/.default",
+	}
+	if answers != expected {
+		t.Errorf("Expected %+v, got %+v", expected, answers)
+	}
+	for _, rejection := range []string{"enter an absolute URL", "enter the application (client) ID", "enter the API's /.default scope"} {
+		if !strings.Contains(out.String(), rejection) {
+			t.Errorf("Expected output to contain %q, got:\n%s", rejection, out.String())
+		}
+	}
+}
+
+func TestPrompt_EndOfInput(t *testing.T) {
+	if _, err := Prompt(strings.NewReader("My API\n"), &bytes.Buffer{}); err == nil || !strings.Contains(err.Error(), `"URL"`) {
+		t.Errorf("Expected an error naming the unanswered question, got %v", err)
+	}
+}
+
+func TestRender(t *testing.T) {
+	data, err := Render(Answers{
+		Name:     "Users",
+		URL:      "https://api.example.com/users",
+		Method:   "GET",
+		TenantID: "tenant",
+		ClientID: "client",
+		Scope:    "api://api/.default",
+	})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+
+	var decoded struct {
+		Endpoints []map[string]string `json:"endpoints"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Rendered config is not valid JSON: %v", err)
+	}
+	if len(decoded.Endpoints) != 1 {
+		t.Fatalf("Expected one endpoint, got %d", len(decoded.Endpoints))
+	}
+	endpoint := decoded.Endpoints[0]
+	if endpoint["clientSecret"] != SecretPlaceholder || endpoint["clientId"] != "client" || endpoint["scope"] != "api://api/.default" {
+		t.Errorf("Unexpected endpoint: %v", endpoint)
+	}
+}
@@ -0,0 +1,193 @@
+// Package schedule parses cron expressions, so that the daemon command can
+// run scheduled checks without an external cron. Expressions have the five
+// standard fields (minute, hour, day of month, month, day of week) with
+// lists, ranges, steps and three-letter month and day names, or are one of
+// the shorthands @hourly, @daily, @weekly, @monthly and @yearly.
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxYears bounds the search for the next activation, so that expressions
+// that never match, such as "0 0 31 2 *", do not loop forever
+const maxYears = 5
+
+// shorthands are the supported @ expressions
+var shorthands = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// field describes the range and names of a cron field
+type field struct {
+	names    []string
+	name     string
+	min, max int
+}
+
+var (
+	minuteField = field{name: "minute", min: 0, max: 59}
+	hourField   = field{name: "hour", min: 0, max: 23}
+	dayField    = field{name: "day of month", min: 1, max: 31}
+	monthField  = field{
+		name: "month", min: 1, max: 12,
+		names: []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"},
+	}
+	// Sunday is both 0 and 7
+	weekdayField = field{
+		name: "day of week", min: 0, max: 7,
+		names: []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"},
+	}
+)
+
+// Schedule is a parsed cron expression. Each field is a bit set of the
+// values it matches.
+type Schedule struct {
+	minutes, hours, days, months, weekdays uint64
+	// anyDay and anyWeekday record a day of month or day of week starting
+	// with *, such as * or */2: unless either does, cron matches a day when
+	// either field matches
+	anyDay, anyWeekday bool
+}
+
+// Parse parses a cron expression
+func Parse(expression string) (*Schedule, error) {
+	spec := strings.TrimSpace(expression)
+	if strings.HasPrefix(spec, "@") {
+		expanded, ok := shorthands[strings.ToLower(spec)]
+		if !ok {
+			return nil, fmt.Errorf("unknown schedule %s (must be @hourly, @daily, @weekly, @monthly or @yearly)", spec)
+		}
+		spec = expanded
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid schedule %q: expected 5 fields (minute hour day-of-month month day-of-week), got %d", expression, len(fields))
+	}
+	s := &Schedule{anyDay: strings.HasPrefix(fields[2], "*"), anyWeekday: strings.HasPrefix(fields[4], "*")}
+	var err error
+	for i, target := range []struct {
+		set   *uint64
+		field field
+	}{
+		{&s.minutes, minuteField},
+		{&s.hours, hourField},
+		{&s.days, dayField},
+		{&s.months, monthField},
+		{&s.weekdays, weekdayField},
+	} {
+		if *target.set, err = parseField(fields[i], target.field); err != nil {
+			return nil, fmt.Errorf("invalid schedule %q: %w", expression, err)
+		}
+	}
+	if s.weekdays&(1<<7) != 0 {
+		s.weekdays |= 1
+	}
+	return s, nil
+}
+
+// parseField parses a comma-separated list of values, ranges and steps
+func parseField(text string, f field) (uint64, error) {
+	var set uint64
+	for _, part := range strings.Split(text, ",") {
+		rangeText, stepText, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepText); err != nil || step < 1 {
+				return 0, fmt.Errorf("%s: invalid step %q", f.name, stepText)
+			}
+		}
+
+		low, high := f.min, f.max
+		switch startText, endText, isRange := strings.Cut(rangeText, "-"); {
+		case rangeText == "*":
+		case isRange:
+			var err error
+			if low, err = f.value(startText); err != nil {
+				return 0, err
+			}
+			if high, err = f.value(endText); err != nil {
+				return 0, err
+			}
+			if low > high {
+				return 0, fmt.Errorf("%s: range %s is reversed", f.name, rangeText)
+			}
+		default:
+			var err error
+			if low, err = f.value(rangeText); err != nil {
+				return 0, err
+			}
+			if !hasStep {
+				high = low
+			}
+		}
+		for value := low; value <= high; value += step {
+			set |= 1 << value
+		}
+	}
+	return set, nil
+}
+
+// value parses a single number or name of the field
+func (f *field) value(text string) (int, error) {
+	for i, name := range f.names {
+		if strings.EqualFold(text, name) {
+			return i + f.min, nil
+		}
+	}
+	value, err := strconv.Atoi(text)
+	if err != nil || value < f.min || value > f.max {
+		return 0, fmt.Errorf("%s: %q is not between %d and %d", f.name, text, f.min, f.max)
+	}
+	return value, nil
+}
+
+// Next returns the first activation strictly after t, in t's location, or
+// the zero time when the schedule never activates
+func (s *Schedule) Next(t time.Time) time.Time {
+	next := t.Truncate(time.Minute).Add(time.Minute)
+	limit := next.AddDate(maxYears, 0, 0)
+	for next.Before(limit) {
+		switch {
+		case !has(s.months, int(next.Month())):
+			next = time.Date(next.Year(), next.Month()+1, 1, 0, 0, 0, 0, next.Location())
+		case !s.matchesDay(next):
+			next = time.Date(next.Year(), next.Month(), next.Day()+1, 0, 0, 0, 0, next.Location())
+		case !has(s.hours, next.Hour()):
+			next = time.Date(next.Year(), next.Month(), next.Day(), next.Hour()+1, 0, 0, 0, next.Location())
+		case !has(s.minutes, next.Minute()):
+			next = next.Add(time.Minute)
+		default:
+			return next
+		}
+	}
+	return time.Time{}
+}
+
+// matchesDay reports whether the day of t matches. As in Vixie cron, when
+// either the day of month or the day of week starts with "*", both must
+// match; when neither does, either may match.
+func (s *Schedule) matchesDay(t time.Time) bool {
+	day := has(s.days, t.Day())
+	weekday := has(s.weekdays, int(t.Weekday()))
+	if s.anyDay || s.anyWeekday {
+		return day && weekday
+	}
+	return day || weekday
+}
+
+// has reports whether value is in set
+func has(set uint64, value int) bool {
+	return set&(1<<value) != 0
+}
@@ -0,0 +1,83 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse_Invalid(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+	}{
+		{name: "empty", expression: ""},
+		{name: "too few fields", expression: "*/5 * * *"},
+		{name: "too many fields", expression: "0 */5 * * * *"},
+		{name: "minute out of range", expression: "60 * * * *"},
+		{name: "hour out of range", expression: "0 24 * * *"},
+		{name: "day zero", expression: "0 0 0 * *"},
+		{name: "reversed range", expression: "0 10-5 * * *"},
+		{name: "zero step", expression: "*/0 * * * *"},
+		{name: "unknown name", expression: "0 0 * foo *"},
+		{name: "unknown shorthand", expression: "@often"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse(tt.expression); err == nil {
+				t.Errorf("Expected error for %q, got nil", tt.expression)
+			}
+		})
+	}
+}
+
+func TestSchedule_Next(t *testing.T) {
+	// Wednesday
+	start := time.Date(2026, 1, 14, 10, 7, 30, 0, time.UTC)
+	tests := []struct {
+		name       string
+		expression string
+		expected   time.Time
+	}{
+		{name: "every five minutes", expression: "*/5 * * * *", expected: time.Date(2026, 1, 14, 10, 10, 0, 0, time.UTC)},
+		{name: "every minute", expression: "* * * * *", expected: time.Date(2026, 1, 14, 10, 8, 0, 0, time.UTC)},
+		{name: "list", expression: "0,30 * * * *", expected: time.Date(2026, 1, 14, 10, 30, 0, 0, time.UTC)},
+		{name: "range with step", expression: "0 9-17/4 * * *", expected: time.Date(2026, 1, 14, 13, 0, 0, 0, time.UTC)},
+		{name: "start with step", expression: "5/20 * * * *", expected: time.Date(2026, 1, 14, 10, 25, 0, 0, time.UTC)},
+		{name: "next day", expression: "0 6 * * *", expected: time.Date(2026, 1, 15, 6, 0, 0, 0, time.UTC)},
+		{name: "weekday name", expression: "0 9 * * mon-fri", expected: time.Date(2026, 1, 15, 9, 0, 0, 0, time.UTC)},
+		{name: "sunday as 7", expression: "0 0 * * 7", expected: time.Date(2026, 1, 18, 0, 0, 0, 0, time.UTC)},
+		{name: "month name", expression: "0 0 1 mar *", expected: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{name: "day of month or week", expression: "0 0 20 * 5", expected: time.Date(2026, 1, 16, 0, 0, 0, 0, time.UTC)},
+		{name: "day of month step", expression: "0 0 */5 * *", expected: time.Date(2026, 1, 16, 0, 0, 0, 0, time.UTC)},
+		{name: "day of week step", expression: "0 0 * * */3", expected: time.Date(2026, 1, 17, 0, 0, 0, 0, time.UTC)},
+		{name: "day of month and week step", expression: "0 0 2 * */2", expected: time.Date(2026, 4, 2, 0, 0, 0, 0, time.UTC)},
+		{name: "leap day", expression: "0 0 29 2 *", expected: time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{name: "hourly", expression: "@hourly", expected: time.Date(2026, 1, 14, 11, 0, 0, 0, time.UTC)},
+		{name: "weekly", expression: "@weekly", expected: time.Date(2026, 1, 18, 0, 0, 0, 0, time.UTC)},
+		{name: "never", expression: "0 0 31 2 *", expected: time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Parse(tt.expression)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := s.Next(start); !got.Equal(tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestSchedule_NextOnActivation(t *testing.T) {
+	s, err := Parse("*/5 * * * *")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	at := time.Date(2026, 1, 14, 10, 10, 0, 0, time.UTC)
+	if got := s.Next(at); !got.Equal(at.Add(5 * time.Minute)) {
+		t.Errorf("Expected the activation after %v, got %v", at, got)
+	}
+}
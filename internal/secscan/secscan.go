@@ -0,0 +1,120 @@
+// Package secscan grades API responses against a checklist of security
+// headers: HSTS, X-Content-Type-Options, Cache-Control on authenticated
+// responses and the absence of headers that disclose the server software.
+package secscan
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// MinHSTSMaxAge is the shortest Strict-Transport-Security max-age accepted,
+// in seconds (180 days)
+const MinHSTSMaxAge = 180 * 24 * 60 * 60
+
+// Checks of the checklist
+const (
+	CheckHSTS               = "hsts"
+	CheckContentTypeOptions = "x-content-type-options"
+	CheckCacheControl       = "cache-control"
+	CheckServer             = "server"
+	CheckPoweredBy          = "x-powered-by"
+)
+
+// grades are indexed by the number of failed checks; more failures than
+// grades get the last
+var grades = []string{"A", "B", "C", "D", "F"}
+
+// Finding is a failed check
+type Finding struct {
+	Check   string
+	Message string
+}
+
+// Result is the grade of a response and the checks it failed
+type Result struct {
+	// Grade is A when every check passed, down to F for four or more
+	// failures
+	Grade    string
+	Findings []Finding
+}
+
+// Scan checks the headers of the response to a request for rawURL. The
+// Cache-Control check only applies to authenticated responses.
+func Scan(rawURL string, headers http.Header, authenticated bool) *Result {
+	var findings []Finding
+	add := func(check, format string, args ...any) {
+		findings = append(findings, Finding{Check: check, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if message := checkHSTS(rawURL, headers); message != "" {
+		add(CheckHSTS, "%s", message)
+	}
+	if value := headers.Get("X-Content-Type-Options"); !strings.EqualFold(strings.TrimSpace(value), "nosniff") {
+		if value == "" {
+			add(CheckContentTypeOptions, "X-Content-Type-Options is missing; expected nosniff")
+		} else {
+			add(CheckContentTypeOptions, "X-Content-Type-Options is %q; expected nosniff", value)
+		}
+	}
+	if authenticated {
+		if value := headers.Get("Cache-Control"); !hasDirective(headers.Values("Cache-Control"), "no-store") {
+			if value == "" {
+				add(CheckCacheControl, "Cache-Control is missing; authenticated responses should be no-store")
+			} else {
+				add(CheckCacheControl, "Cache-Control is %q; authenticated responses should be no-store", value)
+			}
+		}
+	}
+	if value := headers.Get("Server"); value != "" {
+		add(CheckServer, "Server discloses %q", value)
+	}
+	if value := headers.Get("X-Powered-By"); value != "" {
+		add(CheckPoweredBy, "X-Powered-By discloses %q", value)
+	}
+
+	return &Result{Grade: grades[min(len(findings), len(grades)-1)], Findings: findings}
+}
+
+// checkHSTS returns why the response does not enforce HTTPS with
+// Strict-Transport-Security, or "" when it does
+func checkHSTS(rawURL string, headers http.Header) string {
+	if parsed, err := url.Parse(rawURL); err == nil && strings.EqualFold(parsed.Scheme, "http") {
+		return "Served over plain HTTP; Strict-Transport-Security cannot apply"
+	}
+	value := headers.Get("Strict-Transport-Security")
+	if value == "" {
+		return "Strict-Transport-Security is missing"
+	}
+	for _, directive := range strings.Split(value, ";") {
+		name, arg, _ := strings.Cut(strings.TrimSpace(directive), "=")
+		if !strings.EqualFold(strings.TrimSpace(name), "max-age") {
+			continue
+		}
+		maxAge, err := strconv.Atoi(strings.Trim(strings.TrimSpace(arg), `"`))
+		if err != nil {
+			return fmt.Sprintf("Strict-Transport-Security has an invalid max-age: %q", value)
+		}
+		if maxAge < MinHSTSMaxAge {
+			return fmt.Sprintf("Strict-Transport-Security max-age=%d is shorter than %d (180 days)", maxAge, MinHSTSMaxAge)
+		}
+		return ""
+	}
+	return fmt.Sprintf("Strict-Transport-Security has no max-age: %q", value)
+}
+
+// hasDirective reports whether a comma-separated header lists directive
+func hasDirective(values []string, directive string) bool {
+	for _, value := range values {
+		for _, part := range strings.Split(value, ",") {
+			name, _, _ := strings.Cut(strings.TrimSpace(part), "=")
+			if strings.EqualFold(name, directive) {
+				return true
+			}
+		}
+	}
+	return false
+}
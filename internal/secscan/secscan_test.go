@@ -0,0 +1,132 @@
+package secscan
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+// secureHeaders passes every check
+func secureHeaders() http.Header {
+	return http.Header{
+		"Strict-Transport-Security": {"max-age=31536000; includeSubDomains"},
+		"X-Content-Type-Options":    {"nosniff"},
+		"Cache-Control":             {"private", "no-store"},
+	}
+}
+
+func TestScan(t *testing.T) {
+	tests := []struct {
+		name          string
+		url           string
+		modify        func(http.Header)
+		authenticated bool
+		grade         string
+		checks        []string
+	}{
+		{
+			name:          "secure",
+			url:           "https://api.example.com/users",
+			modify:        func(http.Header) {},
+			authenticated: true,
+			grade:         "A",
+		},
+		{
+			name:          "server disclosed",
+			url:           "https://api.example.com/users",
+			modify:        func(h http.Header) { h.Set("Server", "Kestrel") },
+			authenticated: true,
+			grade:         "B",
+			checks:        []string{CheckServer},
+		},
+		{
+			name: "short HSTS and powered by",
+			url:  "https://api.example.com/users",
+			modify: func(h http.Header) {
+				h.Set("Strict-Transport-Security", "max-age=300")
+				h.Set("X-Powered-By", "Express")
+			},
+			authenticated: true,
+			grade:         "C",
+			checks:        []string{CheckHSTS, CheckPoweredBy},
+		},
+		{
+			name:          "cacheable authenticated response",
+			url:           "https://api.example.com/users",
+			modify:        func(h http.Header) { h.Set("Cache-Control", "public, max-age=60") },
+			authenticated: true,
+			grade:         "B",
+			checks:        []string{CheckCacheControl},
+		},
+		{
+			name:   "cacheable anonymous response",
+			url:    "https://api.example.com/users",
+			modify: func(h http.Header) { h.Del("Cache-Control") },
+			grade:  "A",
+		},
+		{
+			name:          "nothing set over plain HTTP",
+			url:           "http://localhost:8080/users",
+			modify:        func(h http.Header) { clear(h) },
+			authenticated: true,
+			grade:         "D",
+			checks:        []string{CheckHSTS, CheckContentTypeOptions, CheckCacheControl},
+		},
+		{
+			name: "everything wrong",
+			url:  "https://api.example.com/users",
+			modify: func(h http.Header) {
+				clear(h)
+				h.Set("X-Content-Type-Options", "sniff")
+				h.Set("Server", "nginx/1.25")
+				h.Set("X-Powered-By", "PHP/8.3")
+			},
+			authenticated: true,
+			grade:         "F",
+			checks:        []string{CheckHSTS, CheckContentTypeOptions, CheckCacheControl, CheckServer, CheckPoweredBy},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			headers := secureHeaders()
+			tt.modify(headers)
+			result := Scan(tt.url, headers, tt.authenticated)
+			if result.Grade != tt.grade {
+				t.Errorf("Grade = %s, expected %s (findings: %v)", result.Grade, tt.grade, result.Findings)
+			}
+			var checks []string
+			for _, finding := range result.Findings {
+				checks = append(checks, finding.Check)
+			}
+			if !reflect.DeepEqual(checks, tt.checks) {
+				t.Errorf("Failed checks = %v, expected %v", checks, tt.checks)
+			}
+		})
+	}
+}
+
+func TestCheckHSTS(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected string
+	}{
+		{"max-age=15552000", ""},
+		{`max-age="31536000"; preload`, ""},
+		{"includeSubDomains; MAX-AGE=63072000", ""},
+		{"", "Strict-Transport-Security is missing"},
+		{"max-age=86400", "Strict-Transport-Security max-age=86400 is shorter than 15552000 (180 days)"},
+		{"max-age=forever", `Strict-Transport-Security has an invalid max-age: "max-age=forever"`},
+		{"includeSubDomains", `Strict-Transport-Security has no max-age: "includeSubDomains"`},
+	}
+
+	for _, tt := range tests {
+		headers := http.Header{}
+		if tt.value != "" {
+			headers.Set("Strict-Transport-Security", tt.value)
+		}
+		if got := checkHSTS("https://api.example.com", headers); got != tt.expected {
+			t.Errorf("checkHSTS(%q) = %q, expected %q", tt.value, got, tt.expected)
+		}
+	}
+}
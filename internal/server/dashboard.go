@@ -0,0 +1,132 @@
+package server
+
+import (
+	_ "embed"
+	"net/http"
+	"time"
+
+	"github.com/hutstep/entra-id-api-tester/internal/report"
+)
+
+// dashboardHTML is the single-page dashboard, which polls the API
+//
+//go:embed dashboard.html
+var dashboardHTML []byte
+
+// sparklineRuns is the number of recent durations listed per endpoint
+const sparklineRuns = 30
+
+// EndpointSummary is the latest state of an endpoint, for the dashboard
+type EndpointSummary struct {
+	// LastErrorAt is the start of the run LastError was reported in
+	LastErrorAt *time.Time `json:"lastErrorAt,omitempty"`
+	Name        string     `json:"name"`
+	Status      string     `json:"status"`
+	// LastError is the error of the endpoint's latest failure, which may
+	// be older than its status
+	LastError string `json:"lastError,omitempty"`
+	// DurationsMs are the endpoint's durations in its recent runs, oldest
+	// first
+	DurationsMs []int64 `json:"durationsMs"`
+	DurationMs  int64   `json:"durationMs"`
+	StatusCode  int     `json:"statusCode,omitempty"`
+}
+
+// RunSummary counts the endpoint outcomes of a recorded run
+type RunSummary struct {
+	StartedAt time.Time `json:"startedAt"`
+	Total     int       `json:"total"`
+	Passed    int       `json:"passed"`
+	Failed    int       `json:"failed"`
+	Skipped   int       `json:"skipped"`
+}
+
+// failure is the latest error of an endpoint
+type failure struct {
+	at      time.Time
+	message string
+}
+
+// handleDashboard serves the dashboard page
+func handleDashboard(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Content-Security-Policy", "default-src 'none'; script-src 'unsafe-inline'; style-src 'unsafe-inline'; connect-src 'self'")
+	_, _ = w.Write(dashboardHTML)
+}
+
+// handleEndpoints responds with the summary of every endpoint in the latest
+// run, followed by endpoints only earlier runs recorded
+func (s *Server) handleEndpoints(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var summaries []EndpointSummary
+	index := make(map[string]int)
+	add := func(name, status string, durationMs int64, statusCode int) {
+		index[name] = len(summaries)
+		summary := EndpointSummary{Name: name, Status: status, DurationMs: durationMs, StatusCode: statusCode, DurationsMs: []int64{}}
+		if latest, ok := s.failures[name]; ok {
+			at := latest.at
+			summary.LastErrorAt, summary.LastError = &at, latest.message
+		}
+		summaries = append(summaries, summary)
+	}
+	if s.latest != nil {
+		for _, endpoint := range s.latest.Endpoints {
+			add(endpoint.Name, endpoint.Status, endpoint.DurationMs, endpoint.StatusCode)
+		}
+	}
+	for i := len(s.history) - 1; i >= 0; i-- {
+		for _, entry := range s.history[i].Endpoints {
+			if _, ok := index[entry.Name]; !ok {
+				add(entry.Name, entry.Status, entry.DurationMs, 0)
+			}
+		}
+	}
+
+	for _, run := range s.history {
+		for _, entry := range run.Endpoints {
+			if entry.Status == report.StatusSkipped {
+				continue
+			}
+			summary := &summaries[index[entry.Name]]
+			summary.DurationsMs = append(summary.DurationsMs, entry.DurationMs)
+			if len(summary.DurationsMs) > sparklineRuns {
+				summary.DurationsMs = summary.DurationsMs[1:]
+			}
+		}
+	}
+	if summaries == nil {
+		summaries = []EndpointSummary{}
+	}
+	writeJSON(w, http.StatusOK, summaries)
+}
+
+// handleRuns responds with the recorded runs, newest first, limited to the
+// most recent ones with ?limit=N
+func (s *Server) handleRuns(w http.ResponseWriter, r *http.Request) {
+	limit, err := parseLimit(r.URL.Query().Get("limit"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid limit: "+err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	runs := []RunSummary{}
+	for i := len(s.history) - 1; i >= 0 && (limit == 0 || len(runs) < limit); i-- {
+		run := RunSummary{StartedAt: s.history[i].StartedAt, Total: len(s.history[i].Endpoints)}
+		for _, entry := range s.history[i].Endpoints {
+			switch entry.Status {
+			case report.StatusPassed:
+				run.Passed++
+			case report.StatusFailed:
+				run.Failed++
+			case report.StatusSkipped:
+				run.Skipped++
+			}
+		}
+		runs = append(runs, run)
+	}
+	writeJSON(w, http.StatusOK, runs)
+}
@@ -0,0 +1,93 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestServer_Dashboard(t *testing.T) {
+	s, err := New(fakeRun(), Options{Token: "secret"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	httpServer := newTestServer(t, s)
+
+	request, err := http.NewRequestWithContext(context.Background(), http.MethodGet, httpServer.URL+"/", nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer func() { _ = response.Body.Close() }()
+	body, _ := io.ReadAll(response.Body)
+	if response.StatusCode != http.StatusOK || !strings.Contains(string(body), "<title>API Tester</title>") {
+		t.Errorf("Expected the dashboard without a token, got %d", response.StatusCode)
+	}
+	if !strings.HasPrefix(response.Header.Get("Content-Type"), "text/html") {
+		t.Errorf("Expected an HTML content type, got %q", response.Header.Get("Content-Type"))
+	}
+
+	var failure map[string]string
+	if status := call(t, http.MethodGet, httpServer.URL+"/endpoints", "", &failure); status != http.StatusUnauthorized {
+		t.Errorf("Expected the API to require the token, got %d", status)
+	}
+	if status := call(t, http.MethodGet, httpServer.URL+"/missing", "", &failure); status != http.StatusUnauthorized {
+		t.Errorf("Expected other paths to require the token, got %d", status)
+	}
+}
+
+func TestServer_Endpoints(t *testing.T) {
+	s, err := New(fakeRun(), Options{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	httpServer := newTestServer(t, s)
+
+	var empty []EndpointSummary
+	if status := call(t, http.MethodGet, httpServer.URL+"/endpoints", "", &empty); status != http.StatusOK || len(empty) != 0 {
+		t.Errorf("Expected no endpoints before the first run, got %d %+v", status, empty)
+	}
+
+	for i := 0; i < 3; i++ {
+		call(t, http.MethodPost, httpServer.URL+"/runs?wait=true", "", nil)
+	}
+	var endpoints []EndpointSummary
+	call(t, http.MethodGet, httpServer.URL+"/endpoints", "", &endpoints)
+	if len(endpoints) != 1 {
+		t.Fatalf("Expected one endpoint, got %+v", endpoints)
+	}
+	users := endpoints[0]
+	if users.Status != "passed" || users.DurationMs != 300 {
+		t.Errorf("Expected the latest status, got %+v", users)
+	}
+	if len(users.DurationsMs) != 3 || users.DurationsMs[0] != 100 || users.DurationsMs[2] != 300 {
+		t.Errorf("Expected the durations of all runs, oldest first, got %v", users.DurationsMs)
+	}
+	if users.LastError != "Unexpected status code: 500" || users.LastErrorAt == nil || users.LastErrorAt.Day() != 2 {
+		t.Errorf("Expected the error of the second run, got %q at %v", users.LastError, users.LastErrorAt)
+	}
+}
+
+func TestServer_Runs(t *testing.T) {
+	s, err := New(fakeRun(), Options{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	httpServer := newTestServer(t, s)
+	for i := 0; i < 3; i++ {
+		call(t, http.MethodPost, httpServer.URL+"/runs?wait=true", "", nil)
+	}
+
+	var runs []RunSummary
+	if status := call(t, http.MethodGet, httpServer.URL+"/runs?limit=2", "", &runs); status != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", status)
+	}
+	if len(runs) != 2 || runs[0].StartedAt.Day() != 3 || runs[0].Passed != 1 || runs[1].Failed != 1 {
+		t.Errorf("Expected the last two runs, newest first, got %+v", runs)
+	}
+}
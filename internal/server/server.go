@@ -0,0 +1,346 @@
+// Package server exposes the tester over HTTP, so that other systems, such
+// as deployment orchestrators or chatops bots, can trigger a run, fetch the
+// latest results and query the history of an endpoint.
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hutstep/entra-id-api-tester/internal/history"
+	"github.com/hutstep/entra-id-api-tester/internal/logging"
+	"github.com/hutstep/entra-id-api-tester/internal/report"
+)
+
+// maxRuns bounds the runs kept in memory for history queries
+const maxRuns = 100
+
+// RunFunc tests the endpoints and returns the report of the run. It is
+// called for every triggered run, so it may reload the configuration.
+type RunFunc func(ctx context.Context) (*report.Report, error)
+
+// Options configure a server
+type Options struct {
+	// Logger receives the outcome of runs; by default logs are discarded
+	Logger *slog.Logger
+	// Token, when set, must be sent as a bearer token with every request
+	Token string
+	// HistoryPath is a history file (JSON Lines) that the history is loaded
+	// from and every run is appended to; without it, history is kept in
+	// memory only
+	HistoryPath string
+}
+
+// Status describes the runs of the server
+type Status struct {
+	// StartedAt is the start of the run in progress
+	StartedAt *time.Time `json:"startedAt,omitempty"`
+	// LastError is why the last run failed to complete, e.g. an invalid
+	// configuration
+	LastError string `json:"lastError,omitempty"`
+	// Runs is the number of runs completed since the server started
+	Runs    int  `json:"runs"`
+	Running bool `json:"running"`
+}
+
+// EndpointHistory is the recorded outcome of an endpoint, oldest first
+type EndpointHistory struct {
+	Endpoint string        `json:"endpoint"`
+	Runs     []EndpointRun `json:"runs"`
+}
+
+// EndpointRun is the outcome of an endpoint in one run
+type EndpointRun struct {
+	StartedAt  time.Time `json:"startedAt"`
+	Status     string    `json:"status"`
+	DurationMs int64     `json:"durationMs"`
+}
+
+// pending is a run in progress
+type pending struct {
+	startedAt time.Time
+	done      chan struct{}
+	report    *report.Report
+	err       error
+}
+
+// Server triggers runs and serves their results. Only one run is in
+// progress at a time.
+type Server struct {
+	ctx     context.Context
+	run     RunFunc
+	logger  *slog.Logger
+	cancel  context.CancelFunc
+	current *pending
+	latest  *report.Report
+	opts    Options
+	lastErr string
+	// failures holds the latest error of every endpoint that failed
+	failures map[string]failure
+	history  []history.Run
+	runs     int
+	wg       sync.WaitGroup
+	mu       sync.Mutex
+}
+
+// New creates a server that tests with run, loading the history file of
+// opts when it is set
+func New(run RunFunc, opts Options) (*Server, error) {
+	var runs []history.Run
+	if opts.HistoryPath != "" {
+		var err error
+		if runs, err = history.Load(opts.HistoryPath, maxRuns); err != nil {
+			return nil, err
+		}
+	}
+	logger := opts.Logger
+	if logger == nil {
+		logger = logging.Discard()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Server{
+		ctx:      ctx,
+		cancel:   cancel,
+		run:      run,
+		logger:   logger,
+		opts:     opts,
+		failures: make(map[string]failure),
+		history:  runs,
+	}, nil
+}
+
+// Handler returns the dashboard and the HTTP API:
+//
+//	GET  /                         the dashboard
+//	POST /runs                     start a run (?wait=true returns its report)
+//	GET  /runs                     the recorded runs, newest first
+//	GET  /runs/latest              the report of the latest completed run
+//	GET  /status                   whether a run is in progress
+//	GET  /endpoints                the latest status of every endpoint
+//	GET  /endpoints/{name}/history the outcome of an endpoint per run
+func (s *Server) Handler() http.Handler {
+	api := http.NewServeMux()
+	api.HandleFunc("POST /runs", s.handleTrigger)
+	api.HandleFunc("GET /runs", s.handleRuns)
+	api.HandleFunc("GET /runs/latest", s.handleLatest)
+	api.HandleFunc("GET /status", s.handleStatus)
+	api.HandleFunc("GET /endpoints", s.handleEndpoints)
+	api.HandleFunc("GET /endpoints/{name}/history", s.handleHistory)
+
+	mux := http.NewServeMux()
+	// The dashboard page holds no data; it asks for the token itself
+	mux.HandleFunc("GET /{$}", handleDashboard)
+	mux.Handle("/", s.authenticate(api))
+	return mux
+}
+
+// Close cancels the run in progress and waits for it to finish
+func (s *Server) Close() {
+	s.cancel()
+	s.wg.Wait()
+}
+
+// trigger starts a run unless one is in progress, and reports whether it
+// did. The returned run is the one in progress either way.
+func (s *Server) trigger() (*pending, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.current != nil {
+		return s.current, false
+	}
+	run := &pending{startedAt: time.Now(), done: make(chan struct{})}
+	s.current = run
+	s.wg.Add(1)
+	go s.execute(run)
+	return run, true
+}
+
+// execute runs the tests and records their outcome
+func (s *Server) execute(run *pending) {
+	defer s.wg.Done()
+	defer close(run.done)
+	s.logger.Info("run started")
+	run.report, run.err = s.run(s.ctx)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.current = nil
+	if run.err != nil {
+		s.lastErr = run.err.Error()
+		s.logger.Error("run failed", "error", run.err)
+		return
+	}
+	s.lastErr = ""
+	s.latest = run.report
+	s.runs++
+	s.logger.Info("run finished", "endpoints", run.report.Summary.Total, "failed", run.report.Summary.Failed)
+	for _, endpoint := range run.report.Endpoints {
+		if endpoint.Status == report.StatusFailed {
+			s.failures[endpoint.Name] = failure{at: run.report.StartedAt, message: endpoint.Error}
+		}
+	}
+
+	recorded := history.FromReport(run.report)
+	s.history = append(s.history, recorded)
+	if len(s.history) > maxRuns {
+		s.history = s.history[len(s.history)-maxRuns:]
+	}
+	if s.opts.HistoryPath != "" {
+		if err := history.Append(s.opts.HistoryPath, recorded); err != nil {
+			s.logger.Error("failed to update run history", "error", err)
+		}
+	}
+}
+
+// status returns the current status
+func (s *Server) status() Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	status := Status{LastError: s.lastErr, Runs: s.runs, Running: s.current != nil}
+	if s.current != nil {
+		startedAt := s.current.startedAt.UTC()
+		status.StartedAt = &startedAt
+	}
+	return status
+}
+
+// handleTrigger starts a run. With ?wait=true it responds with the report
+// once the run finished; otherwise it responds 202 right away.
+func (s *Server) handleTrigger(w http.ResponseWriter, r *http.Request) {
+	wait, err := parseBool(r.URL.Query().Get("wait"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid wait: "+err.Error())
+		return
+	}
+	run, started := s.trigger()
+	if !started {
+		writeError(w, http.StatusConflict, "a run is already in progress")
+		return
+	}
+	if !wait {
+		writeJSON(w, http.StatusAccepted, s.status())
+		return
+	}
+
+	select {
+	case <-run.done:
+	case <-r.Context().Done():
+		// The client is gone; the run continues and is recorded
+		return
+	}
+	if run.err != nil {
+		writeError(w, http.StatusInternalServerError, run.err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, run.report)
+}
+
+// handleLatest responds with the report of the latest completed run
+func (s *Server) handleLatest(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	latest := s.latest
+	s.mu.Unlock()
+	if latest == nil {
+		writeError(w, http.StatusNotFound, "no run has completed yet")
+		return
+	}
+	writeJSON(w, http.StatusOK, latest)
+}
+
+// handleStatus responds with the current status
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.status())
+}
+
+// handleHistory responds with the outcome of an endpoint in each recorded
+// run, limited to the most recent ones with ?limit=N
+func (s *Server) handleHistory(w http.ResponseWriter, r *http.Request) {
+	limit, err := parseLimit(r.URL.Query().Get("limit"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid limit: "+err.Error())
+		return
+	}
+
+	name := r.PathValue("name")
+	result := EndpointHistory{Endpoint: name, Runs: []EndpointRun{}}
+	s.mu.Lock()
+	for _, run := range s.history {
+		for _, entry := range run.Endpoints {
+			if entry.Name == name {
+				result.Runs = append(result.Runs, EndpointRun{StartedAt: run.StartedAt, Status: entry.Status, DurationMs: entry.DurationMs})
+				break
+			}
+		}
+	}
+	s.mu.Unlock()
+
+	if len(result.Runs) == 0 {
+		writeError(w, http.StatusNotFound, "no recorded runs of endpoint "+strconv.Quote(name))
+		return
+	}
+	if limit > 0 && len(result.Runs) > limit {
+		result.Runs = result.Runs[len(result.Runs)-limit:]
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// authenticate rejects requests without the server's bearer token
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	if s.opts.Token == "" {
+		return next
+	}
+	expected := []byte("Bearer " + s.opts.Token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), expected) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeError(w, http.StatusUnauthorized, "missing or invalid bearer token")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// parseBool parses an optional boolean query parameter
+func parseBool(value string) (bool, error) {
+	if value == "" {
+		return false, nil
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, errors.New("expected true or false")
+	}
+	return parsed, nil
+}
+
+// parseLimit parses an optional ?limit=N query parameter, 0 when absent
+func parseLimit(value string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 1 {
+		return 0, errors.New("must be a positive number")
+	}
+	return limit, nil
+}
+
+// writeJSON responds with value encoded as JSON
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	_ = encoder.Encode(value)
+}
+
+// writeError responds with {"error": message}
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
@@ -0,0 +1,205 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hutstep/entra-id-api-tester/internal/history"
+	"github.com/hutstep/entra-id-api-tester/internal/report"
+)
+
+// fakeRun returns reports in which "users" passes on odd runs
+func fakeRun() RunFunc {
+	count := 0
+	return func(ctx context.Context) (*report.Report, error) {
+		count++
+		users := report.Endpoint{Name: "users", Status: report.StatusPassed, DurationMs: int64(100 * count)}
+		if count%2 == 0 {
+			users.Status, users.Error = report.StatusFailed, "Unexpected status code: 500"
+		}
+		return &report.Report{
+			StartedAt: time.Date(2026, 1, count, 0, 0, 0, 0, time.UTC),
+			Endpoints: []report.Endpoint{users},
+			Summary:   report.Summary{Total: 1},
+		}, nil
+	}
+}
+
+// newTestServer serves s and closes both at the end of the test
+func newTestServer(t *testing.T, s *Server) *httptest.Server {
+	t.Helper()
+	httpServer := httptest.NewServer(s.Handler())
+	t.Cleanup(func() {
+		httpServer.Close()
+		s.Close()
+	})
+	return httpServer
+}
+
+// call sends a request and decodes the JSON response into target
+func call(t *testing.T, method, url, token string, target interface{}) int {
+	t.Helper()
+	request, err := http.NewRequestWithContext(context.Background(), method, url, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if token != "" {
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer func() { _ = response.Body.Close() }()
+	if target != nil {
+		if err := json.NewDecoder(response.Body).Decode(target); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+	}
+	return response.StatusCode
+}
+
+func TestServer_RunAndHistory(t *testing.T) {
+	s, err := New(fakeRun(), Options{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	httpServer := newTestServer(t, s)
+
+	var failure map[string]string
+	if status := call(t, http.MethodGet, httpServer.URL+"/runs/latest", "", &failure); status != http.StatusNotFound {
+		t.Errorf("Expected 404 before the first run, got %d", status)
+	}
+
+	for i := 0; i < 3; i++ {
+		var runReport report.Report
+		if status := call(t, http.MethodPost, httpServer.URL+"/runs?wait=true", "", &runReport); status != http.StatusOK {
+			t.Fatalf("Expected 200 for a waited run, got %d", status)
+		}
+		if len(runReport.Endpoints) != 1 {
+			t.Fatalf("Expected the report of the run, got %+v", runReport)
+		}
+	}
+
+	var latest report.Report
+	if status := call(t, http.MethodGet, httpServer.URL+"/runs/latest", "", &latest); status != http.StatusOK || latest.Endpoints[0].DurationMs != 300 {
+		t.Errorf("Expected the third run as the latest, got %d %+v", status, latest)
+	}
+
+	var endpointHistory EndpointHistory
+	if status := call(t, http.MethodGet, httpServer.URL+"/endpoints/users/history?limit=2", "", &endpointHistory); status != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", status)
+	}
+	if len(endpointHistory.Runs) != 2 || endpointHistory.Runs[0].Status != report.StatusFailed || endpointHistory.Runs[1].Status != report.StatusPassed {
+		t.Errorf("Expected the last two runs, got %+v", endpointHistory.Runs)
+	}
+
+	if status := call(t, http.MethodGet, httpServer.URL+"/endpoints/orders/history", "", &failure); status != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown endpoint, got %d", status)
+	}
+	if status := call(t, http.MethodGet, httpServer.URL+"/endpoints/users/history?limit=0", "", &failure); status != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an invalid limit, got %d", status)
+	}
+
+	var status Status
+	if code := call(t, http.MethodGet, httpServer.URL+"/status", "", &status); code != http.StatusOK || status.Runs != 3 || status.Running {
+		t.Errorf("Expected 3 completed runs, got %d %+v", code, status)
+	}
+}
+
+func TestServer_RunInProgress(t *testing.T) {
+	release := make(chan struct{})
+	s, err := New(func(ctx context.Context) (*report.Report, error) {
+		<-release
+		return &report.Report{}, nil
+	}, Options{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	httpServer := newTestServer(t, s)
+
+	var status Status
+	if code := call(t, http.MethodPost, httpServer.URL+"/runs", "", &status); code != http.StatusAccepted || !status.Running || status.StartedAt == nil {
+		t.Errorf("Expected 202 with a running status, got %d %+v", code, status)
+	}
+	var failure map[string]string
+	if code := call(t, http.MethodPost, httpServer.URL+"/runs", "", &failure); code != http.StatusConflict {
+		t.Errorf("Expected 409 while a run is in progress, got %d", code)
+	}
+	close(release)
+}
+
+func TestServer_RunError(t *testing.T) {
+	s, err := New(func(ctx context.Context) (*report.Report, error) {
+		return nil, errors.New("invalid configuration")
+	}, Options{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	httpServer := newTestServer(t, s)
+
+	var failure map[string]string
+	if code := call(t, http.MethodPost, httpServer.URL+"/runs?wait=true", "", &failure); code != http.StatusInternalServerError || failure["error"] != "invalid configuration" {
+		t.Errorf("Expected 500 with the error, got %d %v", code, failure)
+	}
+	var status Status
+	if call(t, http.MethodGet, httpServer.URL+"/status", "", &status); status.LastError != "invalid configuration" || status.Runs != 0 {
+		t.Errorf("Expected the error in the status, got %+v", status)
+	}
+}
+
+func TestServer_Token(t *testing.T) {
+	s, err := New(fakeRun(), Options{Token: "secret"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	httpServer := newTestServer(t, s)
+
+	tests := []struct {
+		name   string
+		token  string
+		status int
+	}{
+		{name: "missing", status: http.StatusUnauthorized},
+		{name: "wrong", token: "guess", status: http.StatusUnauthorized},
+		{name: "valid", token: "secret", status: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body map[string]interface{}
+			if status := call(t, http.MethodGet, httpServer.URL+"/status", tt.token, &body); status != tt.status {
+				t.Errorf("Expected %d, got %d", tt.status, status)
+			}
+		})
+	}
+}
+
+func TestServer_HistoryFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.db")
+	previous := history.Run{StartedAt: time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC), Endpoints: []history.Entry{{Name: "users", Status: report.StatusPassed}}}
+	if err := history.Append(path, previous); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	s, err := New(fakeRun(), Options{HistoryPath: path})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	httpServer := newTestServer(t, s)
+	call(t, http.MethodPost, httpServer.URL+"/runs?wait=true", "", nil)
+
+	var endpointHistory EndpointHistory
+	call(t, http.MethodGet, httpServer.URL+"/endpoints/users/history", "", &endpointHistory)
+	if len(endpointHistory.Runs) != 2 || !endpointHistory.Runs[0].StartedAt.Equal(previous.StartedAt) {
+		t.Errorf("Expected the recorded and the new run, got %+v", endpointHistory.Runs)
+	}
+	if runs, err := history.Load(path, 0); err != nil || len(runs) != 2 {
+		t.Errorf("Expected the run to be appended to the history file, got %d runs, %v", len(runs), err)
+	}
+}
@@ -0,0 +1,89 @@
+package vars
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Export formats
+const (
+	ExportDotenv = "dotenv"
+	ExportJSON   = "json"
+)
+
+// envNamePattern matches names usable as environment variables
+var envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// All returns a copy of every captured variable
+func (s *Store) All() map[string]string {
+	values := make(map[string]string, len(s.values))
+	for name, value := range s.values {
+		values[name] = value
+	}
+	return values
+}
+
+// ExportFormat returns the export format for path: JSON for a .json file,
+// dotenv otherwise
+func ExportFormat(path string) string {
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		return ExportJSON
+	}
+	return ExportDotenv
+}
+
+// Export renders the captured variables as a JSON object or as dotenv
+// NAME="value" lines, sorted by name
+func (s *Store) Export(format string) ([]byte, error) {
+	switch format {
+	case ExportJSON:
+		data, err := json.MarshalIndent(s.values, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode variables: %w", err)
+		}
+		return append(data, '\n'), nil
+	case ExportDotenv:
+		names := make([]string, 0, len(s.values))
+		for name := range s.values {
+			if !envNamePattern.MatchString(name) {
+				return nil, fmt.Errorf("variable %q is not a valid environment variable name", name)
+			}
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		var b strings.Builder
+		for _, name := range names {
+			fmt.Fprintf(&b, "%s=%s\n", name, quoteDotenv(s.values[name]))
+		}
+		return []byte(b.String()), nil
+	default:
+		return nil, fmt.Errorf("unsupported export format: %s (must be dotenv or json)", format)
+	}
+}
+
+// ExportFile writes the captured variables to path in the format its
+// extension selects. The file may contain secrets, so it is only readable
+// by the owner.
+func (s *Store) ExportFile(path string) error {
+	data, err := s.Export(ExportFormat(path))
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write variables: %w", err)
+	}
+	return nil
+}
+
+// quoteDotenv double-quotes a dotenv value, escaping what dotenv parsers
+// would otherwise interpret
+func quoteDotenv(value string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", `\$`, "\n", `\n`, "\r", `\r`)
+	return `"` + replacer.Replace(value) + `"`
+}
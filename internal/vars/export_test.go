@@ -0,0 +1,69 @@
+package vars
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStore_ExportDotenv(t *testing.T) {
+	store := NewStore()
+	store.Set("userId", "abc-123")
+	store.Set("NOTE", "say \"hi\"\nto $HOME\\")
+
+	data, err := store.Export(ExportDotenv)
+	if err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	expected := "NOTE=\"say \\\"hi\\\"\\nto \\$HOME\\\\\"\nuserId=\"abc-123\"\n"
+	if string(data) != expected {
+		t.Errorf("Expected %q, got %q", expected, data)
+	}
+
+	store.Set("item-id", "1")
+	if _, err := store.Export(ExportDotenv); err == nil {
+		t.Error("Expected error for a name that is not a valid environment variable")
+	}
+}
+
+func TestStore_ExportFile(t *testing.T) {
+	store := NewStore()
+	store.Set("item-id", "42")
+	store.Set("url", "https://api.example.com/items/42")
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "vars.json")
+	if err := store.ExportFile(path); err != nil {
+		t.Fatalf("ExportFile() error = %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var values map[string]string
+	if err := json.Unmarshal(data, &values); err != nil {
+		t.Fatalf("Expected JSON, got %q: %v", data, err)
+	}
+	if values["item-id"] != "42" || values["url"] != "https://api.example.com/items/42" {
+		t.Errorf("Unexpected variables: %v", values)
+	}
+
+	if err := store.ExportFile(filepath.Join(dir, "vars.env")); err == nil {
+		t.Error("Expected dotenv export to reject item-id")
+	}
+}
+
+func TestExportFormat(t *testing.T) {
+	tests := map[string]string{
+		"vars.env":  ExportDotenv,
+		".env":      ExportDotenv,
+		"vars.json": ExportJSON,
+		"VARS.JSON": ExportJSON,
+	}
+	for path, expected := range tests {
+		if got := ExportFormat(path); got != expected {
+			t.Errorf("ExportFormat(%q) = %q, expected %q", path, got, expected)
+		}
+	}
+}
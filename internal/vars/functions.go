@@ -0,0 +1,148 @@
+package vars
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hutstep/entra-id-api-tester/internal/redact"
+)
+
+// function is a template function callable as {{name arg...}}
+type function struct {
+	call  func(args []string) (string, error)
+	usage string
+	// minArgs and maxArgs bound the number of arguments
+	minArgs, maxArgs int
+}
+
+// functions are the template functions placeholders can call, e.g. to give
+// every run unique payload values
+var functions = map[string]function{
+	"uuid": {
+		usage: "uuid",
+		call: func([]string) (string, error) {
+			return uuid.NewString(), nil
+		},
+	},
+	"now": {
+		usage:   `now ["layout"]`,
+		maxArgs: 1,
+		call: func(args []string) (string, error) {
+			layout := time.RFC3339
+			if len(args) == 1 {
+				layout = args[0]
+			}
+			return time.Now().UTC().Format(layout), nil
+		},
+	},
+	"randInt": {
+		usage:   "randInt min max",
+		minArgs: 2,
+		maxArgs: 2,
+		call: func(args []string) (string, error) {
+			low, err := strconv.Atoi(args[0])
+			if err != nil {
+				return "", fmt.Errorf("invalid min %q", args[0])
+			}
+			high, err := strconv.Atoi(args[1])
+			if err != nil {
+				return "", fmt.Errorf("invalid max %q", args[1])
+			}
+			if high < low {
+				return "", fmt.Errorf("max %d is less than min %d", high, low)
+			}
+			return strconv.Itoa(low + rand.IntN(high-low+1)), nil // #nosec G404 - test data, not a secret
+		},
+	},
+	"env": {
+		usage:   `env "NAME"`,
+		minArgs: 1,
+		maxArgs: 1,
+		call: func(args []string) (string, error) {
+			value, ok := os.LookupEnv(args[0])
+			if !ok {
+				return "", fmt.Errorf("environment variable %s is not set", args[0])
+			}
+			// Environment variables are where CI keeps its secrets
+			redact.Register(value)
+			return value, nil
+		},
+	},
+}
+
+// call evaluates a template function expression such as now "2006-01-02"
+func call(expression string) (string, error) {
+	fn, args, err := parseCall(expression)
+	if err != nil {
+		return "", err
+	}
+	value, err := fn.call(args)
+	if err != nil {
+		return "", fmt.Errorf("{{%s}}: %w", expression, err)
+	}
+	return value, nil
+}
+
+// CheckFunction reports whether expression is a well-formed template
+// function call, without calling it
+func CheckFunction(expression string) error {
+	_, _, err := parseCall(expression)
+	return err
+}
+
+// parseCall splits a function call into the function and its arguments.
+// Arguments are bare words or double-quoted Go strings.
+func parseCall(expression string) (function, []string, error) {
+	words, err := splitWords(expression)
+	if err != nil {
+		return function{}, nil, fmt.Errorf("invalid placeholder {{%s}}: %w", expression, err)
+	}
+	if len(words) == 0 {
+		return function{}, nil, fmt.Errorf("empty placeholder {{}}")
+	}
+	fn, ok := functions[words[0]]
+	if !ok {
+		return function{}, nil, fmt.Errorf("unknown placeholder {{%s}}", expression)
+	}
+	args := words[1:]
+	if len(args) < fn.minArgs || len(args) > fn.maxArgs {
+		return function{}, nil, fmt.Errorf("invalid placeholder {{%s}}: usage is {{%s}}", expression, fn.usage)
+	}
+	return fn, args, nil
+}
+
+// splitWords splits input at whitespace, keeping double-quoted strings,
+// which may contain spaces, together
+func splitWords(input string) ([]string, error) {
+	var words []string
+	for {
+		input = strings.TrimLeft(input, " \t")
+		if input == "" {
+			return words, nil
+		}
+		if input[0] == '"' {
+			quoted, err := strconv.QuotedPrefix(input)
+			if err != nil {
+				return nil, fmt.Errorf("unterminated string %s", input)
+			}
+			word, err := strconv.Unquote(quoted)
+			if err != nil {
+				return nil, err
+			}
+			words = append(words, word)
+			input = input[len(quoted):]
+			continue
+		}
+		end := strings.IndexAny(input, " \t")
+		if end < 0 {
+			end = len(input)
+		}
+		words = append(words, input[:end])
+		input = input[end:]
+	}
+}
@@ -0,0 +1,111 @@
+package vars
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hutstep/entra-id-api-tester/internal/redact"
+)
+
+func TestStore_ExpandEnvRedacted(t *testing.T) {
+	t.Setenv("API_TESTER_KEY", "apim-key-from-ci")
+
+	if _, err := NewStore().Expand(`{{env "API_TESTER_KEY"}}`); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := redact.String("key: apim-key-from-ci"); strings.Contains(got, "apim-key-from-ci") {
+		t.Errorf("Expected the environment variable's value to be redacted, got %q", got)
+	}
+}
+
+func TestStore_ExpandFunctions(t *testing.T) {
+	t.Setenv("API_TESTER_TAG", "nightly build")
+	store := NewStore()
+
+	tests := []struct {
+		input     string
+		pattern   string
+		expectErr bool
+	}{
+		{"/items/{{uuid}}", `^/items/[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[0-9a-f]{4}-[0-9a-f]{12}$`, false},
+		{`{{now "2006-01-02"}}`, `^` + time.Now().UTC().Format("2006-01-02") + `$`, false},
+		{"{{ now }}", `^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}Z$`, false},
+		{"order-{{randInt 5 5}}", `^order-5$`, false},
+		{`{{env "API_TESTER_TAG"}}`, `^nightly build$`, false},
+		{`{{env "API_TESTER_UNSET"}}`, "", true},
+		{"{{randInt 1}}", "", true},
+		{"{{randInt 10 1}}", "", true},
+		{"{{randInt one 10}}", "", true},
+		{"{{uuid 4}}", "", true},
+		{`{{now "2006}}`, "", true},
+		{"{{}}", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := store.Expand(tt.input)
+			if tt.expectErr {
+				if err == nil {
+					t.Errorf("Expected error for %q, got %q", tt.input, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !regexp.MustCompile(tt.pattern).MatchString(got) {
+				t.Errorf("Expected %q to match %s", got, tt.pattern)
+			}
+		})
+	}
+}
+
+func TestStore_ExpandFunctionsPerPlaceholder(t *testing.T) {
+	got, err := NewStore().Expand("{{uuid}} {{uuid}}")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(got) != 73 || got[:36] == got[37:] {
+		t.Errorf("Expected two different UUIDs, got %q", got)
+	}
+}
+
+func TestRandIntBounds(t *testing.T) {
+	for range 100 {
+		value, err := call("randInt -2 2")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if n, err := strconv.Atoi(value); err != nil || n < -2 || n > 2 {
+			t.Fatalf("Expected an integer in [-2, 2], got %q", value)
+		}
+	}
+}
+
+func TestCheckFunction(t *testing.T) {
+	tests := []struct {
+		expression string
+		expectErr  bool
+	}{
+		{"uuid", false},
+		{`now "Jan 2, 2006"`, false},
+		{"randInt 1 100", false},
+		// env is not called, so an unset variable is not an error
+		{`env "API_TESTER_UNSET"`, false},
+		{"env", true},
+		{"env.TAG", true},
+		{"randInt 1 2 3", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			err := CheckFunction(tt.expression)
+			if (err != nil) != tt.expectErr {
+				t.Errorf("Expected error %v, got %v", tt.expectErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,176 @@
+// Package vars holds values captured from earlier responses and expands
+// {{vars.name}} placeholders in request URLs, headers and bodies, enabling
+// chained create-then-get-then-delete test flows. Placeholders can also call
+// template functions such as {{uuid}} for values unique to each run, and
+// read {{row.column}} from the data file row a request is sent for.
+package vars
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/hutstep/entra-id-api-tester/internal/jsonpath"
+)
+
+// placeholderPattern matches {{ expression }} placeholders
+var placeholderPattern = regexp.MustCompile(`\{\{\s*([^{}]*?)\s*\}\}`)
+
+// Store holds named variables captured during a run
+type Store struct {
+	values map[string]string
+	// row holds the data file row {{row.column}} reads, if any
+	row map[string]string
+}
+
+// NewStore creates an empty variable store
+func NewStore() *Store {
+	return &Store{
+		values: make(map[string]string),
+	}
+}
+
+// WithRow returns a store that shares s's variables and also expands
+// {{row.column}} placeholders from row
+func (s *Store) WithRow(row map[string]string) *Store {
+	return &Store{values: s.values, row: row}
+}
+
+// Set stores a variable, replacing any previous value
+func (s *Store) Set(name, value string) {
+	s.values[name] = value
+}
+
+// Get returns a variable and whether it has been set
+func (s *Store) Get(name string) (string, bool) {
+	value, ok := s.values[name]
+	return value, ok
+}
+
+// CaptureFromBody evaluates a JSONPath expression against a JSON response
+// body and stores the selected value under name
+func (s *Store) CaptureFromBody(name string, body []byte, path string) error {
+	value, err := jsonpath.LookupBytes(body, path)
+	if err != nil {
+		return fmt.Errorf("failed to capture %s: %w", name, err)
+	}
+	s.Set(name, jsonpath.Stringify(value))
+	return nil
+}
+
+// CaptureFromHeader stores the value of a response header under name
+func (s *Store) CaptureFromHeader(name string, headers http.Header, header string) error {
+	value := headers.Get(header)
+	if value == "" {
+		return fmt.Errorf("failed to capture %s: header %s not present", name, header)
+	}
+	s.Set(name, value)
+	return nil
+}
+
+// Expand replaces every {{vars.name}}, {{row.column}} and template function
+// placeholder in input. Each placeholder is evaluated on its own, so two
+// {{uuid}} yield two different values. Referencing a variable that has not
+// been captured is an error.
+func (s *Store) Expand(input string) (string, error) {
+	var expandErr error
+	output := placeholderPattern.ReplaceAllStringFunc(input, func(match string) string {
+		if expandErr != nil {
+			return match
+		}
+		expression := placeholderPattern.FindStringSubmatch(match)[1]
+		value, err := s.evaluate(expression)
+		if err != nil {
+			expandErr = err
+			return match
+		}
+		return value
+	})
+	if expandErr != nil {
+		return "", expandErr
+	}
+	return output, nil
+}
+
+// ExpandBody returns a copy of a JSON request body with placeholders in all
+// string values expanded
+func (s *Store) ExpandBody(body map[string]interface{}) (map[string]interface{}, error) {
+	if body == nil {
+		return nil, nil
+	}
+	expanded, err := s.expandValue(body)
+	if err != nil {
+		return nil, err
+	}
+	return expanded.(map[string]interface{}), nil
+}
+
+// expandValue walks a decoded JSON value, expanding strings
+func (s *Store) expandValue(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		return s.Expand(v)
+	case map[string]interface{}:
+		expanded := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			result, err := s.expandValue(item)
+			if err != nil {
+				return nil, err
+			}
+			expanded[key] = result
+		}
+		return expanded, nil
+	case []interface{}:
+		expanded := make([]interface{}, len(v))
+		for i, item := range v {
+			result, err := s.expandValue(item)
+			if err != nil {
+				return nil, err
+			}
+			expanded[i] = result
+		}
+		return expanded, nil
+	default:
+		return value, nil
+	}
+}
+
+// evaluate resolves a single placeholder expression: a variable, a data
+// row column or a template function call
+func (s *Store) evaluate(expression string) (string, error) {
+	if column, found := strings.CutPrefix(expression, "row."); found {
+		return s.column(column)
+	}
+	name, found := strings.CutPrefix(expression, "vars.")
+	if !found {
+		return call(expression)
+	}
+	value, ok := s.Get(name)
+	if !ok {
+		return "", fmt.Errorf("unresolved variable {{%s}}", expression)
+	}
+	return value, nil
+}
+
+// column resolves a {{row.column}} placeholder
+func (s *Store) column(name string) (string, error) {
+	if s.row == nil {
+		return "", fmt.Errorf("{{row.%s}} needs a dataFile", name)
+	}
+	value, ok := s.row[name]
+	if !ok {
+		return "", fmt.Errorf("{{row.%s}}: the data row has no column %s", name, name)
+	}
+	return value, nil
+}
+
+// Placeholders returns the expressions of the {{ expression }} placeholders
+// in input, in order of appearance
+func Placeholders(input string) []string {
+	var expressions []string
+	for _, match := range placeholderPattern.FindAllStringSubmatch(input, -1) {
+		expressions = append(expressions, match[1])
+	}
+	return expressions
+}
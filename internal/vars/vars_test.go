@@ -0,0 +1,145 @@
+package vars
+
+import (
+	"net/http"
+	"slices"
+	"testing"
+)
+
+func TestStore_Expand(t *testing.T) {
+	store := NewStore()
+	store.Set("id", "abc-123")
+	store.Set("tenant", "contoso")
+
+	tests := []struct {
+		input     string
+		expected  string
+		expectErr bool
+	}{
+		{"https://api.example.com/items", "https://api.example.com/items", false},
+		{"https://api.example.com/items/{{vars.id}}", "https://api.example.com/items/abc-123", false},
+		{"/{{ vars.tenant }}/items/{{vars.id}}", "/contoso/items/abc-123", false},
+		{"/items/{{vars.missing}}", "", true},
+		{"/items/{{unknown}}", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := store.Expand(tt.input)
+			if tt.expectErr {
+				if err == nil {
+					t.Errorf("Expected error for %q, got nil", tt.input)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestStore_ExpandBody(t *testing.T) {
+	store := NewStore()
+	store.Set("id", "abc-123")
+
+	body := map[string]interface{}{
+		"parentId": "{{vars.id}}",
+		"count":    float64(2),
+		"tags":     []interface{}{"tag-{{vars.id}}", true},
+		"nested":   map[string]interface{}{"ref": "{{vars.id}}"},
+	}
+
+	expanded, err := store.ExpandBody(body)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if expanded["parentId"] != "abc-123" {
+		t.Errorf("Expected parentId abc-123, got %v", expanded["parentId"])
+	}
+	if expanded["count"] != float64(2) {
+		t.Errorf("Expected count 2, got %v", expanded["count"])
+	}
+	if tags := expanded["tags"].([]interface{}); tags[0] != "tag-abc-123" || tags[1] != true {
+		t.Errorf("Unexpected tags: %v", tags)
+	}
+	if nested := expanded["nested"].(map[string]interface{}); nested["ref"] != "abc-123" {
+		t.Errorf("Unexpected nested value: %v", nested)
+	}
+	if body["parentId"] != "{{vars.id}}" {
+		t.Error("Expected original body to be left untouched")
+	}
+
+	if _, err := store.ExpandBody(map[string]interface{}{"x": "{{vars.nope}}"}); err == nil {
+		t.Error("Expected error for unresolved variable, got nil")
+	}
+	if expanded, err := store.ExpandBody(nil); err != nil || expanded != nil {
+		t.Errorf("Expected nil body to stay nil, got %v (%v)", expanded, err)
+	}
+}
+
+func TestStore_Capture(t *testing.T) {
+	store := NewStore()
+
+	if err := store.CaptureFromBody("id", []byte(`{"data": {"id": 42}}`), "$.data.id"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if value, _ := store.Get("id"); value != "42" {
+		t.Errorf("Expected id 42, got %s", value)
+	}
+
+	headers := http.Header{}
+	headers.Set("ETag", `"v1"`)
+	if err := store.CaptureFromHeader("etag", headers, "etag"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if value, _ := store.Get("etag"); value != `"v1"` {
+		t.Errorf("Expected etag \"v1\", got %s", value)
+	}
+
+	if err := store.CaptureFromBody("missing", []byte(`{}`), "$.id"); err == nil {
+		t.Error("Expected error for missing JSON path, got nil")
+	}
+	if err := store.CaptureFromHeader("missing", headers, "Location"); err == nil {
+		t.Error("Expected error for missing header, got nil")
+	}
+	if _, ok := store.Get("missing"); ok {
+		t.Error("Expected failed capture not to set a variable")
+	}
+}
+
+func TestPlaceholders(t *testing.T) {
+	got := Placeholders("https://api/{{vars.id}}/items/{{ vars.item }}?q={{env.X}}")
+	expected := []string{"vars.id", "vars.item", "env.X"}
+	if !slices.Equal(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+	if Placeholders("no placeholders") != nil {
+		t.Error("Expected no placeholders")
+	}
+}
+
+func TestStore_WithRow(t *testing.T) {
+	store := NewStore()
+	store.Set("id", "abc-123")
+	row := store.WithRow(map[string]string{"name": "alice"})
+
+	got, err := row.Expand("/users/{{row.name}}/items/{{vars.id}}")
+	if err != nil || got != "/users/alice/items/abc-123" {
+		t.Errorf("Expected row and variable to expand, got %q (%v)", got, err)
+	}
+	if _, err := row.Expand("{{row.email}}"); err == nil {
+		t.Error("Expected error for a missing column, got nil")
+	}
+	if _, err := store.Expand("{{row.name}}"); err == nil {
+		t.Error("Expected error for a row placeholder without a row, got nil")
+	}
+
+	row.Set("created", "42")
+	if value, _ := store.Get("created"); value != "42" {
+		t.Errorf("Expected variables to be shared with the row store, got %q", value)
+	}
+}
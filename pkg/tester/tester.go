@@ -0,0 +1,248 @@
+// Package tester runs endpoint tests from Go code, so a project can check
+// its Entra ID protected API from its own integration tests instead of
+// shelling out to the api-tester binary.
+//
+// A run takes the same configuration as the CLI and returns the same report
+// it writes with -report-file:
+//
+//	cfg, err := tester.LoadConfig("api-tester.json")
+//	if err != nil {
+//		t.Fatal(err)
+//	}
+//	report, err := tester.Run(ctx, cfg, tester.Options{})
+//	if err != nil {
+//		t.Fatal(err)
+//	}
+//	if report.Summary.Failed > 0 {
+//		t.Errorf("%d endpoint(s) failed", report.Summary.Failed)
+//	}
+package tester
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/hutstep/entra-id-api-tester/internal/auth"
+	"github.com/hutstep/entra-id-api-tester/internal/client"
+	"github.com/hutstep/entra-id-api-tester/internal/config"
+	"github.com/hutstep/entra-id-api-tester/internal/redact"
+	"github.com/hutstep/entra-id-api-tester/internal/report"
+	"github.com/hutstep/entra-id-api-tester/internal/runner"
+	"github.com/hutstep/entra-id-api-tester/internal/vars"
+)
+
+// Config is a complete test configuration, as loaded by LoadConfig
+type Config = config.Config
+
+// Endpoint is a single endpoint test of a Config
+type Endpoint = config.Endpoint
+
+// Assertion is a check of an endpoint's response
+type Assertion = config.Assertion
+
+// Report is the outcome of a run, with one entry per tested endpoint
+type Report = report.Report
+
+// Result is the detailed outcome of a single endpoint test
+type Result = runner.Result
+
+// TokenProvider acquires the access tokens endpoints are called with
+type TokenProvider = auth.TokenProvider
+
+// ErrorCode is the cause of a failed Result; see Result.ErrorCode
+type ErrorCode = runner.ErrorCode
+
+// Rate is a maximum request rate; see ParseRate
+type Rate = runner.Rate
+
+// ParseRate parses a rate such as "5/s" or "100/m"
+func ParseRate(value string) (Rate, error) {
+	return runner.ParseRate(value)
+}
+
+// Failures a Result's Err wraps, for use with errors.Is
+var (
+	ErrAuth        = runner.ErrAuth
+	ErrPrepare     = runner.ErrPrepare
+	ErrConnect     = runner.ErrConnect
+	ErrResponse    = runner.ErrResponse
+	ErrContract    = runner.ErrContract
+	ErrPerformance = runner.ErrPerformance
+	ErrSecurity    = runner.ErrSecurity
+	ErrSkipped     = runner.ErrSkipped
+)
+
+// Options configures a run. The zero value runs every endpoint once with
+// tokens acquired from Entra ID, like the CLI with its default flags.
+type Options struct {
+	// TokenProvider acquires tokens for all endpoints; by default each
+	// distinct tokenProxyUrl gets its own Entra ID provider
+	TokenProvider TokenProvider
+	// TokenProviders are the providers by tokenProxyUrl, as created by
+	// TokenProviders; concurrent runs sharing them share token acquisitions.
+	// By default each run creates its own.
+	TokenProviders map[string]TokenProvider
+	// Logger receives the runner's progress; by default logs are discarded
+	Logger *slog.Logger
+	// OnResult, when set, is called after each endpoint finished
+	OnResult func(*Result)
+	// Variables seed the variable store, as if captured before the run
+	Variables map[string]string
+	// Version is recorded in the report
+	Version string
+	// MaxClockSkew is the longest wait for a token to become valid; zero
+	// keeps the default of five minutes
+	MaxClockSkew time.Duration
+	// SlowThreshold is how long a request may be in flight before it is
+	// logged as slow; zero keeps the default
+	SlowThreshold time.Duration
+	// MaxDuration fails endpoints whose requests take longer; zero is off
+	MaxDuration time.Duration
+	// MaxBodyBytes limits how much of a response body is read; zero keeps
+	// the default of 100 MiB
+	MaxBodyBytes int64
+	// Repeat runs each endpoint this many times, reporting its pass rate
+	Repeat int
+	// UpdateGolden records response bodies as the endpoints' golden files
+	// instead of comparing them
+	UpdateGolden bool
+	// KeepBodies keeps the body of each endpoint's first response in
+	// Result.Body
+	KeepBodies bool
+	// Rate paces all requests; the zero Rate is unlimited
+	Rate Rate
+	// PreAcquireTokens acquires every distinct token in parallel before the
+	// endpoints run, recording the outcome in Report.Tokens
+	PreAcquireTokens bool
+	// Trace sends a W3C traceparent header with every request
+	Trace bool
+	// VerifySecondarySecrets fails endpoints whose clientSecretSecondary
+	// cannot acquire a token, as well as their clientSecret
+	VerifySecondarySecrets bool
+	// MinTokenLifetime warns about tokens issued for less time; zero is off
+	MinTokenLifetime time.Duration
+}
+
+// TokenProviders creates the Entra ID token providers for the endpoints of
+// cfg, one per distinct tokenProxyUrl. Concurrent acquisitions of the same
+// token through them are coalesced into a single request.
+func TokenProviders(cfg *Config) (map[string]TokenProvider, error) {
+	return runner.TokenProviders(cfg.Endpoints)
+}
+
+// LoadConfig loads and validates a configuration file, resolving its
+// extends chain and credential references
+func LoadConfig(path string) (*Config, error) {
+	return config.LoadConfig(path)
+}
+
+// Run tests the endpoints of cfg in dependency order and reports their
+// outcome. Failing endpoints are reported, not returned as an error: the
+// error is for configurations that cannot run, and for runs cancelled
+// through ctx, which return the report of the endpoints that completed.
+func Run(ctx context.Context, cfg *Config, opts Options) (Report, error) {
+	if err := cfg.Validate(); err != nil {
+		return Report{}, fmt.Errorf("invalid configuration: %w", err)
+	}
+	order, err := cfg.ExecutionOrder()
+	if err != nil {
+		return Report{}, fmt.Errorf("failed to order endpoints: %w", err)
+	}
+	redact.Register(cfg.Secrets()...)
+
+	providers := opts.TokenProviders
+	if opts.TokenProvider == nil && providers == nil {
+		providers, err = runner.TokenProviders(cfg.Endpoints)
+		if err != nil {
+			return Report{}, fmt.Errorf("failed to configure token acquisition: %w", err)
+		}
+	}
+	if opts.TokenProvider == nil {
+		for i := range cfg.Endpoints {
+			if _, ok := providers[cfg.Endpoints[i].TokenProxyURL]; !ok {
+				return Report{}, fmt.Errorf("no token provider for tokenProxyUrl %q of endpoint %s", cfg.Endpoints[i].TokenProxyURL, cfg.Endpoints[i].Name)
+			}
+		}
+	}
+
+	variables := vars.NewStore()
+	for name, value := range opts.Variables {
+		variables.Set(name, value)
+	}
+	testRunner := runner.New(client.NewAPIClient(), variables)
+	testRunner.Personas = cfg.Personas
+	testRunner.MaxDuration = opts.MaxDuration
+	testRunner.UpdateGolden = opts.UpdateGolden
+	testRunner.KeepBodies = opts.KeepBodies
+	testRunner.Trace = opts.Trace
+	testRunner.VerifySecondarySecrets = opts.VerifySecondarySecrets
+	testRunner.MinTokenLifetime = opts.MinTokenLifetime
+	testRunner.Rate = opts.Rate
+	if opts.Logger != nil {
+		testRunner.Logger = opts.Logger
+	}
+	if opts.MaxClockSkew > 0 {
+		testRunner.MaxClockSkew = opts.MaxClockSkew
+	}
+	if opts.SlowThreshold > 0 {
+		testRunner.SlowThreshold = opts.SlowThreshold
+	}
+	if opts.MaxBodyBytes > 0 {
+		testRunner.MaxBodyBytes = opts.MaxBodyBytes
+	}
+
+	startedAt := time.Now()
+	var acquisitions []runner.TokenAcquisition
+	if opts.PreAcquireTokens {
+		if opts.TokenProvider != nil {
+			providers = map[string]TokenProvider{}
+			for i := range cfg.Endpoints {
+				providers[cfg.Endpoints[i].TokenProxyURL] = opts.TokenProvider
+			}
+			opts.TokenProvider = nil
+		}
+		providers, acquisitions = runner.PreAcquireTokens(ctx, cfg.Endpoints, cfg.Personas, providers)
+	}
+	tokensDuration := time.Since(startedAt)
+	results := make([]*runner.Result, 0, len(cfg.Endpoints))
+	passed := make(map[string]bool, len(cfg.Endpoints))
+	for _, i := range order {
+		if ctx.Err() != nil {
+			break
+		}
+
+		endpoint := &cfg.Endpoints[i]
+		var result *runner.Result
+		if failed := runner.FailedDependency(endpoint, passed); failed != "" {
+			result = runner.Skip(endpoint, failed)
+		} else {
+			tokenProvider := opts.TokenProvider
+			if tokenProvider == nil {
+				tokenProvider = providers[endpoint.TokenProxyURL]
+			}
+			result = testRunner.RunRepeated(ctx, endpoint, tokenProvider, opts.Repeat)
+		}
+		if ctx.Err() != nil && !result.Success() {
+			// Cancelled while in flight: the endpoint did not complete
+			break
+		}
+		passed[endpoint.Name] = result.Success()
+		results = append(results, result)
+		if opts.OnResult != nil {
+			opts.OnResult(result)
+		}
+	}
+
+	runReport := report.New(opts.Version, startedAt, time.Now(), results)
+	runReport.RecordPacing(opts.Rate.String(), testRunner.Requests())
+	if opts.PreAcquireTokens {
+		runReport.RecordTokens(tokensDuration, acquisitions)
+	}
+	if ctx.Err() != nil {
+		runReport.Aborted = context.Cause(ctx).Error()
+		return *runReport, fmt.Errorf("run aborted after %d of %d endpoint(s): %w", len(results), len(cfg.Endpoints), context.Cause(ctx))
+	}
+	return *runReport, nil
+}
@@ -0,0 +1,85 @@
+package tester
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// fakeTokenProvider returns a fixed token
+type fakeTokenProvider struct{}
+
+func (fakeTokenProvider) GetAccessToken(ctx context.Context, clientID, clientSecret, tenantID, scope string) (string, error) {
+	return "token", nil
+}
+
+// testConfig returns a configuration whose endpoints call server paths
+func testConfig(serverURL string, paths ...string) *Config {
+	cfg := &Config{}
+	for _, path := range paths {
+		cfg.Endpoints = append(cfg.Endpoints, Endpoint{
+			Name:         path,
+			URL:          serverURL + "/" + path,
+			Method:       "GET",
+			ClientID:     "client-id",
+			ClientSecret: "secret",
+			TenantID:     "tenant",
+			Scope:        "scope",
+		})
+	}
+	return cfg
+}
+
+func TestRun(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/broken" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	cfg := testConfig(server.URL, "orders", "broken", "invoices")
+	cfg.Endpoints[2].DependsOn = []string{"broken"}
+
+	var results []*Result
+	report, err := Run(context.Background(), cfg, Options{
+		TokenProvider: fakeTokenProvider{},
+		OnResult:      func(result *Result) { results = append(results, result) },
+		Version:       "test",
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if report.Version != "test" || report.Summary.Total != 3 || report.Summary.Passed != 1 || report.Summary.Failed != 1 || report.Summary.Skipped != 1 {
+		t.Errorf("Unexpected summary: %+v", report.Summary)
+	}
+	if len(results) != 3 || !errors.Is(results[1].Err, ErrResponse) || !errors.Is(results[2].Err, ErrSkipped) {
+		t.Errorf("Expected the dependent endpoint to be skipped, got %v", results)
+	}
+}
+
+func TestRun_InvalidConfig(t *testing.T) {
+	if _, err := Run(context.Background(), &Config{}, Options{}); err == nil {
+		t.Error("Expected an error for a configuration without endpoints")
+	}
+}
+
+func TestRun_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cancel()
+	}))
+	defer server.Close()
+
+	report, err := Run(ctx, testConfig(server.URL, "orders", "invoices"), Options{TokenProvider: fakeTokenProvider{}})
+
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected a cancellation error, got %v", err)
+	}
+	if report.Aborted == "" || report.Summary.Total > 1 {
+		t.Errorf("Expected a partial, aborted report, got %+v", report)
+	}
+}
@@ -0,0 +1,97 @@
+// Package testertest checks single endpoints from go test, for suites that
+// want an Entra ID protected API call next to their other tests:
+//
+//	func TestOrdersAPI(t *testing.T) {
+//		testertest.RequireEndpoint(t, &tester.Endpoint{
+//			Name:         "Orders",
+//			URL:          "https://api.contoso.com/orders",
+//			Method:       "GET",
+//			ClientID:     os.Getenv("CLIENT_ID"),
+//			ClientSecret: os.Getenv("CLIENT_SECRET"),
+//			TenantID:     os.Getenv("TENANT_ID"),
+//			Scope:        "api://orders/.default",
+//		})
+//	}
+package testertest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hutstep/entra-id-api-tester/internal/redact"
+	"github.com/hutstep/entra-id-api-tester/pkg/tester"
+)
+
+// RequireEndpoint checks endpoint like CheckEndpoint with default options
+// and stops the test when it fails
+func RequireEndpoint(t testing.TB, endpoint *tester.Endpoint) *tester.Result {
+	t.Helper()
+	result := CheckEndpoint(t, endpoint, tester.Options{})
+	if !result.Success() {
+		t.FailNow()
+	}
+	return result
+}
+
+// CheckEndpoint tests a single endpoint, including its assertions, and
+// marks the test failed with a description of what went wrong when it does
+// not pass. Dependencies are not run: values the endpoint expects from them
+// can be passed as opts.Variables. An endpoint that cannot run at all, e.g.
+// because it is invalid, stops the test.
+func CheckEndpoint(t testing.TB, endpoint *tester.Endpoint, opts tester.Options) *tester.Result {
+	t.Helper()
+
+	single := *endpoint
+	single.DependsOn = nil
+	var result *tester.Result
+	onResult := opts.OnResult
+	opts.OnResult = func(r *tester.Result) {
+		result = r
+		if onResult != nil {
+			onResult(r)
+		}
+	}
+
+	if _, err := tester.Run(context.Background(), &tester.Config{Endpoints: []tester.Endpoint{single}}, opts); err != nil {
+		t.Fatalf("endpoint %q could not run: %v", endpoint.Name, err)
+		return nil
+	}
+	if !result.Success() {
+		t.Error(Describe(&single, result))
+	}
+	return result
+}
+
+// Describe explains a failed result: the request, the failure and the
+// details behind it, such as failed assertions and token diagnoses
+func Describe(endpoint *tester.Endpoint, result *tester.Result) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "endpoint %q failed", result.EndpointName)
+	if phase := result.FailedPhase(); phase != "" {
+		fmt.Fprintf(&b, " in the %s phase", phase)
+	}
+	fmt.Fprintf(&b, ": %v\n", result.Err)
+	fmt.Fprintf(&b, "    request: %s %s\n", endpoint.Method, redact.String(endpoint.URL))
+	if result.StatusCode != 0 {
+		fmt.Fprintf(&b, "    status:  %d\n", result.StatusCode)
+	}
+	for _, assertion := range result.Assertions {
+		if !assertion.Passed() {
+			fmt.Fprintf(&b, "    assertion failed: %v\n", assertion.Err)
+		}
+	}
+	for _, cell := range result.Matrix {
+		if !cell.Passed() {
+			fmt.Fprintf(&b, "    %s failed: %v\n", cell.Label, cell.Err)
+		}
+	}
+	for _, diagnosis := range result.Diagnoses {
+		fmt.Fprintf(&b, "    diagnosis: %s\n", diagnosis)
+	}
+	for _, warning := range result.Warnings {
+		fmt.Fprintf(&b, "    warning: %s\n", warning)
+	}
+	return redact.String(strings.TrimSuffix(b.String(), "\n"))
+}
@@ -0,0 +1,117 @@
+package testertest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hutstep/entra-id-api-tester/pkg/tester"
+)
+
+// recordingT captures failures instead of failing the enclosing test
+type recordingT struct {
+	testing.TB
+	errors []string
+	failed bool
+}
+
+func (r *recordingT) Helper() {}
+
+func (r *recordingT) Error(args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprint(args...))
+	r.failed = true
+}
+
+func (r *recordingT) Fatalf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+	r.failed = true
+}
+
+// fakeTokenProvider returns a fixed token
+type fakeTokenProvider struct{}
+
+func (fakeTokenProvider) GetAccessToken(ctx context.Context, clientID, clientSecret, tenantID, scope string) (string, error) {
+	return "token", nil
+}
+
+func testEndpoint(url string) *tester.Endpoint {
+	return &tester.Endpoint{
+		Name:         "Orders",
+		URL:          url,
+		Method:       "GET",
+		ClientID:     "client-id",
+		ClientSecret: "secret",
+		TenantID:     "tenant",
+		Scope:        "scope",
+	}
+}
+
+func TestCheckEndpoint(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("order") != "42" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"status":"open"}`))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name         string
+		url          string
+		assertions   []tester.Assertion
+		expectErrors []string
+	}{
+		{
+			name: "passes with a dependency's variable",
+			url:  server.URL + "?order={{vars.orderId}}",
+		},
+		{
+			name:         "unexpected status",
+			url:          server.URL,
+			expectErrors: []string{`endpoint "Orders" failed in the response phase`, "status:  404"},
+		},
+		{
+			name:         "failed assertion",
+			url:          server.URL + "?order={{vars.orderId}}",
+			assertions:   []tester.Assertion{{JSONPath: "$.status", Equals: "closed"}},
+			expectErrors: []string{"assertion failed: $.status"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			endpoint := testEndpoint(tt.url)
+			endpoint.DependsOn = []string{"Create Order"}
+			endpoint.Assertions = tt.assertions
+			recorder := &recordingT{TB: t}
+
+			result := CheckEndpoint(recorder, endpoint, tester.Options{
+				TokenProvider: fakeTokenProvider{},
+				Variables:     map[string]string{"orderId": "42"},
+			})
+
+			if result == nil || recorder.failed != (len(tt.expectErrors) > 0) {
+				t.Fatalf("Expected failed=%v, got %q", len(tt.expectErrors) > 0, recorder.errors)
+			}
+			output := strings.Join(recorder.errors, "\n")
+			for _, expected := range tt.expectErrors {
+				if !strings.Contains(output, expected) {
+					t.Errorf("Expected %q in the failure output:\n%s", expected, output)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckEndpoint_InvalidEndpoint(t *testing.T) {
+	recorder := &recordingT{TB: t}
+	endpoint := testEndpoint("")
+
+	if result := CheckEndpoint(recorder, endpoint, tester.Options{TokenProvider: fakeTokenProvider{}}); result != nil || !recorder.failed {
+		t.Errorf("Expected an invalid endpoint to stop the test, got %+v %q", result, recorder.errors)
+	}
+}